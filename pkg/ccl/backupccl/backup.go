@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	"io/ioutil"
+	"net/url"
 	"sort"
 	"time"
 
@@ -51,18 +52,30 @@ const (
 	// BackupDescriptorCheckpointName is the file name used to store the
 	// serialized BackupDescriptor proto while the backup is in progress.
 	BackupDescriptorCheckpointName = "BACKUP-CHECKPOINT"
+	// BackupEncryptionInfoName is the file name used to store the cleartext
+	// EncryptionInfo of an encrypted backup.
+	BackupEncryptionInfoName = "ENCRYPTION-INFO"
 	// BackupFormatInitialVersion is the first version of backup and its files.
 	BackupFormatInitialVersion uint32 = 0
 	// BackupFormatDescriptorTrackingVersion added tracking of complete DBs.
 	BackupFormatDescriptorTrackingVersion uint32 = 1
+
+	// localityURLParam is the URI parameter naming the locality tier whose
+	// nodes write to (or, for RESTORE, whose files are read from) that URI.
+	localityURLParam = "COCKROACH_LOCALITY"
+	// defaultLocalityValue is the localityURLParam value of the URI that holds
+	// the backup descriptor and all files not written to a locality's URI.
+	defaultLocalityValue = "default"
 )
 
 const (
 	backupOptRevisionHistory = "revision_history"
+	backupOptEncPassphrase   = "encryption_passphrase"
 )
 
 var backupOptionExpectValues = map[string]sql.KVStringOptValidate{
 	backupOptRevisionHistory: sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:   sql.KVStringOptRequireValue,
 }
 
 // BackupCheckpointInterval is the interval at which backup progress is saved
@@ -71,16 +84,19 @@ var BackupCheckpointInterval = time.Minute
 
 // ReadBackupDescriptorFromURI creates an export store from the given URI, then
 // reads and unmarshals a BackupDescriptor at the standard location in the
-// export storage.
+// export storage, decrypting it with the given options if they are non-nil.
 func ReadBackupDescriptorFromURI(
-	ctx context.Context, uri string, settings *cluster.Settings,
+	ctx context.Context,
+	uri string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
 	defer exportStore.Close()
-	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName)
+	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName, encryption)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
@@ -91,9 +107,12 @@ func ReadBackupDescriptorFromURI(
 }
 
 // readBackupDescriptor reads and unmarshals a BackupDescriptor from filename in
-// the provided export store.
+// the provided export store, decrypting it first if encryption is non-nil.
 func readBackupDescriptor(
-	ctx context.Context, exportStore storageccl.ExportStorage, filename string,
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -104,6 +123,15 @@ func readBackupDescriptor(
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
+	if encryption != nil {
+		descBytes, err = storageccl.DecryptFile(descBytes, encryption.Key)
+		if err != nil {
+			return BackupDescriptor{}, err
+		}
+	} else if storageccl.AppearsEncrypted(descBytes) {
+		return BackupDescriptor{}, errors.Errorf(
+			"file appears encrypted -- try specifying %s", backupOptEncPassphrase)
+	}
 	var backupDesc BackupDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupDescriptor{}, err
@@ -111,6 +139,57 @@ func readBackupDescriptor(
 	return backupDesc, err
 }
 
+// readEncryptionInfo reads and unmarshals the cleartext EncryptionInfo stored
+// alongside an encrypted backup in the provided export store.
+func readEncryptionInfo(
+	ctx context.Context, exportStore storageccl.ExportStorage,
+) (EncryptionInfo, error) {
+	r, err := exportStore.ReadFile(ctx, BackupEncryptionInfoName)
+	if err != nil {
+		return EncryptionInfo{}, errors.Wrap(err, "could not find or read encryption information")
+	}
+	defer r.Close()
+	infoBytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return EncryptionInfo{}, err
+	}
+	var info EncryptionInfo
+	if err := protoutil.Unmarshal(infoBytes, &info); err != nil {
+		return EncryptionInfo{}, err
+	}
+	return info, nil
+}
+
+func writeEncryptionInfo(
+	ctx context.Context, exportStore storageccl.ExportStorage, info *EncryptionInfo,
+) error {
+	infoBytes, err := protoutil.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return exportStore.WriteFile(ctx, BackupEncryptionInfoName, bytes.NewReader(infoBytes))
+}
+
+// EncryptionFromPassphrase reads the EncryptionInfo of the encrypted backup at
+// the given URI and uses it to derive, from the passphrase, the options needed
+// to decrypt that backup's files.
+func EncryptionFromPassphrase(
+	ctx context.Context, uri string, settings *cluster.Settings, passphrase string,
+) (*roachpb.FileEncryptionOptions, error) {
+	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
+	if err != nil {
+		return nil, err
+	}
+	defer exportStore.Close()
+	info, err := readEncryptionInfo(ctx, exportStore)
+	if err != nil {
+		return nil, err
+	}
+	return &roachpb.FileEncryptionOptions{
+		Key: storageccl.GenerateKey([]byte(passphrase), info.Salt),
+	}, nil
+}
+
 // getRelevantDescChanges finds the changes between start and end time to the
 // SQL descriptors matching `descs` or `expandedDBs`, ordered by time. A
 // descriptor revision matches if it is an earlier revision of a descriptor in
@@ -445,6 +524,11 @@ func optsToKVOptions(opts map[string]string) tree.KVOptions {
 	for _, k := range sortedOpts {
 		opt := tree.KVOption{Key: tree.Name(k)}
 		if v := opts[k]; v != "" {
+			// Job descriptions are visible to anyone who can see the job, so the
+			// passphrase must not end up in them.
+			if k == backupOptEncPassphrase {
+				v = "redacted"
+			}
 			opt.Value = tree.NewDString(v)
 		}
 		kvopts = append(kvopts, opt)
@@ -453,19 +537,22 @@ func optsToKVOptions(opts map[string]string) tree.KVOptions {
 }
 
 func backupJobDescription(
-	backup *tree.Backup, to string, incrementalFrom []string, opts map[string]string,
+	backup *tree.Backup, to []string, incrementalFrom []string, opts map[string]string,
 ) (string, error) {
 	b := &tree.Backup{
-		AsOf:    backup.AsOf,
-		Options: optsToKVOptions(opts),
-		Targets: backup.Targets,
+		AsOf:               backup.AsOf,
+		Options:            optsToKVOptions(opts),
+		Targets:            backup.Targets,
+		DescriptorCoverage: backup.DescriptorCoverage,
 	}
 
-	to, err := storageccl.SanitizeExportStorageURI(to)
-	if err != nil {
-		return "", err
+	for _, t := range to {
+		sanitizedTo, err := sanitizeLocalityURI(t)
+		if err != nil {
+			return "", err
+		}
+		b.To = append(b.To, tree.NewDString(sanitizedTo))
 	}
-	b.To = tree.NewDString(to)
 
 	for _, from := range incrementalFrom {
 		sanitizedFrom, err := storageccl.SanitizeExportStorageURI(from)
@@ -477,6 +564,89 @@ func backupJobDescription(
 	return tree.AsStringWithFlags(b, tree.FmtAlwaysQualifyTableNames), nil
 }
 
+// localityAndBaseURI returns the COCKROACH_LOCALITY parameter of uri, if any,
+// along with uri with that parameter removed.
+func localityAndBaseURI(uri string) (string, string, error) {
+	parsedURI, err := url.Parse(uri)
+	if err != nil {
+		return "", "", err
+	}
+	q := parsedURI.Query()
+	if _, ok := q[localityURLParam]; !ok {
+		return "", uri, nil
+	}
+	localityKV := q.Get(localityURLParam)
+	q.Del(localityURLParam)
+	parsedURI.RawQuery = q.Encode()
+	return localityKV, parsedURI.String(), nil
+}
+
+// sanitizeLocalityURI is like storageccl.SanitizeExportStorageURI, but keeps
+// the COCKROACH_LOCALITY parameter so that job descriptions still tell apart
+// the URIs of a locality-aware backup.
+func sanitizeLocalityURI(uri string) (string, error) {
+	localityKV, baseURI, err := localityAndBaseURI(uri)
+	if err != nil {
+		return "", err
+	}
+	sanitized, err := storageccl.SanitizeExportStorageURI(baseURI)
+	if err != nil || localityKV == "" {
+		return sanitized, err
+	}
+	return sanitized + "?" + url.Values{localityURLParam: []string{localityKV}}.Encode(), nil
+}
+
+// getURIsByLocalityKV takes the URIs given for a single backup and returns the
+// default URI, to which the backup descriptor and all files not written to a
+// locality-specific URI go, along with the locality-specific URIs keyed by
+// their locality tier (e.g. "region=us-east"). A single URI is the default
+// URI; when several are given, each must specify its locality with a
+// COCKROACH_LOCALITY parameter and exactly one must be the default.
+func getURIsByLocalityKV(to []string) (string, map[string]string, error) {
+	if len(to) == 1 {
+		localityKV, baseURI, err := localityAndBaseURI(to[0])
+		if err != nil {
+			return "", nil, err
+		}
+		if localityKV != "" && localityKV != defaultLocalityValue {
+			return "", nil, errors.Errorf("%s %q is invalid for a single URI", localityURLParam, localityKV)
+		}
+		return baseURI, nil, nil
+	}
+
+	var defaultURI string
+	urisByLocalityKV := make(map[string]string)
+	for _, uri := range to {
+		localityKV, baseURI, err := localityAndBaseURI(uri)
+		if err != nil {
+			return "", nil, err
+		}
+		switch localityKV {
+		case "":
+			return "", nil, errors.Errorf(
+				"%s must be specified for each of the %d URIs given", localityURLParam, len(to))
+		case defaultLocalityValue:
+			if defaultURI != "" {
+				return "", nil, errors.Errorf("multiple URIs with %s=%s given", localityURLParam, defaultLocalityValue)
+			}
+			defaultURI = baseURI
+		default:
+			var tier roachpb.Tier
+			if err := tier.FromString(localityKV); err != nil {
+				return "", nil, errors.Wrapf(err, "invalid %s", localityURLParam)
+			}
+			if _, ok := urisByLocalityKV[tier.String()]; ok {
+				return "", nil, errors.Errorf("multiple URIs with %s=%s given", localityURLParam, tier)
+			}
+			urisByLocalityKV[tier.String()] = baseURI
+		}
+	}
+	if defaultURI == "" {
+		return "", nil, errors.Errorf("no URI with %s=%s given", localityURLParam, defaultLocalityValue)
+	}
+	return defaultURI, urisByLocalityKV, nil
+}
+
 // clusterNodeCount returns the approximate number of nodes in the cluster.
 func clusterNodeCount(g *gossip.Gossip) int {
 	var nodes int
@@ -504,6 +674,7 @@ func writeBackupDescriptor(
 	exportStore storageccl.ExportStorage,
 	filename string,
 	desc *BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	sort.Sort(BackupFileDescriptors(desc.Files))
 
@@ -511,6 +682,12 @@ func writeBackupDescriptor(
 	if err != nil {
 		return err
 	}
+	if encryption != nil {
+		descBuf, err = storageccl.EncryptFile(descBuf, encryption.Key)
+		if err != nil {
+			return err
+		}
+	}
 
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
@@ -572,10 +749,12 @@ func backup(
 	gossip *gossip.Gossip,
 	settings *cluster.Settings,
 	exportStore storageccl.ExportStorage,
+	storageByLocalityKV map[string]*roachpb.ExportStorage,
 	job *jobs.Job,
 	backupDesc *BackupDescriptor,
 	checkpointDesc *BackupDescriptor,
 	resultsCh chan<- tree.Datums,
+	encryption *roachpb.FileEncryptionOptions,
 ) (roachpb.BulkOpSummary, error) {
 	// TODO(dan): Figure out how permissions should work. #6713 is tracking this
 	// for grpc.
@@ -653,8 +832,22 @@ func backup(
 	// TODO(dan): Make this limiting per node.
 	//
 	// TODO(dan): See if there's some better solution than rate-limiting #14798.
+	//
+	// The job's resource limits, set with ALTER JOB, can lower the number of
+	// outstanding requests and the rate at which they write files.
+	limits := job.Payload().ResourceLimits
 	maxConcurrentExports := clusterNodeCount(gossip) * int(storage.ExportRequestsLimit.Get(&settings.SV))
+	maxConcurrentExports = limits.LimitConcurrency(maxConcurrentExports)
 	exportsSem := make(chan struct{}, maxConcurrentExports)
+	var exportBytesPerSec int64
+	if bytesPerSec := limits.BytesPerSec(); bytesPerSec > 0 {
+		// Each Export request writes its files at no more than its even share
+		// of the job's rate.
+		exportBytesPerSec = bytesPerSec / int64(maxConcurrentExports)
+		if exportBytesPerSec < 1 {
+			exportBytesPerSec = 1
+		}
+	}
 
 	g := ctxgroup.WithContext(ctx)
 
@@ -687,10 +880,13 @@ func backup(
 				defer func() { <-exportsSem }()
 				header := roachpb.Header{Timestamp: span.end}
 				req := &roachpb.ExportRequest{
-					RequestHeader: roachpb.RequestHeaderFromSpan(span.span),
-					Storage:       exportStore.Conf(),
-					StartTime:     span.start,
-					MVCCFilter:    roachpb.MVCCFilter(backupDesc.MVCCFilter),
+					RequestHeader:       roachpb.RequestHeaderFromSpan(span.span),
+					Storage:             exportStore.Conf(),
+					StorageByLocalityKV: storageByLocalityKV,
+					StartTime:           span.start,
+					MVCCFilter:          roachpb.MVCCFilter(backupDesc.MVCCFilter),
+					Encryption:          encryption,
+					MaxBytesPerSec:      exportBytesPerSec,
 				}
 				rawRes, pErr := client.SendWrappedWith(ctx, db.NonTransactionalSender(), header, req)
 				if pErr != nil {
@@ -708,6 +904,7 @@ func backup(
 						Path:        file.Path,
 						Sha512:      file.Sha512,
 						EntryCounts: file.Exported,
+						LocalityKV:  file.LocalityKV,
 					}
 					if span.start != backupDesc.StartTime {
 						f.StartTime = span.start
@@ -731,7 +928,7 @@ func backup(
 					checkpointMu.Lock()
 					backupDesc.Files = checkpointFiles
 					err := writeBackupDescriptor(
-						ctx, exportStore, BackupDescriptorCheckpointName, backupDesc,
+						ctx, exportStore, BackupDescriptorCheckpointName, backupDesc, encryption,
 					)
 					checkpointMu.Unlock()
 					if err != nil {
@@ -753,7 +950,9 @@ func backup(
 	backupDesc.Files = mu.files
 	backupDesc.EntryCounts = mu.exported
 
-	if err := writeBackupDescriptor(ctx, exportStore, BackupDescriptorName, backupDesc); err != nil {
+	if err := writeBackupDescriptor(
+		ctx, exportStore, BackupDescriptorName, backupDesc, encryption,
+	); err != nil {
 		return mu.exported, err
 	}
 
@@ -767,7 +966,10 @@ func backup(
 // clean up the written checkpoint file (BackupDescriptorCheckpointName) only
 // after writing to the backup file location (BackupDescriptorName).
 func VerifyUsableExportTarget(
-	ctx context.Context, exportStore storageccl.ExportStorage, readable string,
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	readable string,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	if r, err := exportStore.ReadFile(ctx, BackupDescriptorName); err == nil {
 		// TODO(dt): If we audit exactly what not-exists error each ExportStorage
@@ -782,7 +984,7 @@ func VerifyUsableExportTarget(
 			readable, BackupDescriptorCheckpointName)
 	}
 	if err := writeBackupDescriptor(
-		ctx, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{},
+		ctx, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{}, encryption,
 	); err != nil {
 		return errors.Wrapf(err, "cannot write to %s", readable)
 	}
@@ -798,7 +1000,7 @@ func backupPlanHook(
 		return nil, nil, nil, nil
 	}
 
-	toFn, err := p.TypeAsString(backupStmt.To, "BACKUP")
+	toFn, err := p.TypeAsStringArray(tree.Exprs(backupStmt.To), "BACKUP")
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -846,6 +1048,21 @@ func backupPlanHook(
 		if err != nil {
 			return err
 		}
+		defaultURI, urisByLocalityKV, err := getURIsByLocalityKV(to)
+		if err != nil {
+			return err
+		}
+		if len(urisByLocalityKV) > 0 {
+			if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionLocalityAwareBackup) {
+				return errors.Errorf("BACKUP to locality-specific URIs requires all nodes to be upgraded to %s",
+					cluster.VersionByKey(cluster.VersionLocalityAwareBackup))
+			}
+			for _, uri := range urisByLocalityKV {
+				if _, err := storageccl.ExportStorageConfFromURI(uri); err != nil {
+					return err
+				}
+			}
+		}
 		incrementalFrom, err := incrementalFromFn()
 		if err != nil {
 			return err
@@ -859,7 +1076,7 @@ func backupPlanHook(
 			}
 		}
 
-		exportStore, err := storageccl.ExportStorageFromURI(ctx, to, p.ExecCfg().Settings)
+		exportStore, err := storageccl.ExportStorageFromURI(ctx, defaultURI, p.ExecCfg().Settings)
 		if err != nil {
 			return err
 		}
@@ -876,9 +1093,56 @@ func backupPlanHook(
 			requireVersion2 = true
 		}
 
-		targetDescs, completeDBs, err := ResolveTargetsToDescriptors(ctx, p, endTime, backupStmt.Targets)
-		if err != nil {
-			return err
+		var encryption *roachpb.FileEncryptionOptions
+		var encryptionInfo *EncryptionInfo
+		if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+			if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionBackupEncryption) {
+				return errors.Errorf("%s requires all nodes to be upgraded to %s",
+					backupOptEncPassphrase, cluster.VersionByKey(cluster.VersionBackupEncryption))
+			}
+			var salt []byte
+			if len(incrementalFrom) > 0 {
+				// An incremental backup reuses the salt, and thus the key, of the
+				// full backup it builds on, so that RESTORE can decrypt the whole
+				// chain with the key derived from the first backup in it.
+				baseStore, err := storageccl.ExportStorageFromURI(ctx, incrementalFrom[0], p.ExecCfg().Settings)
+				if err != nil {
+					return err
+				}
+				info, err := readEncryptionInfo(ctx, baseStore)
+				baseStore.Close()
+				if err != nil {
+					return errors.Wrapf(err, "failed to read encryption information of %q", incrementalFrom[0])
+				}
+				salt = info.Salt
+			} else {
+				if salt, err = storageccl.GenerateSalt(); err != nil {
+					return err
+				}
+			}
+			encryptionInfo = &EncryptionInfo{Salt: salt}
+			encryption = &roachpb.FileEncryptionOptions{
+				Key: storageccl.GenerateKey([]byte(passphrase), salt),
+			}
+		}
+
+		var targetDescs []sqlbase.Descriptor
+		var completeDBs []sqlbase.ID
+		if backupStmt.DescriptorCoverage == tree.AllDescriptors {
+			if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionFullClusterBackup) {
+				return errors.Errorf("full cluster BACKUP requires all nodes to be upgraded to %s",
+					cluster.VersionByKey(cluster.VersionFullClusterBackup))
+			}
+			allDescs, err := loadAllDescs(ctx, p.ExecCfg().DB, endTime)
+			if err != nil {
+				return err
+			}
+			targetDescs, completeDBs = fullClusterTargets(allDescs)
+		} else {
+			targetDescs, completeDBs, err = ResolveTargetsToDescriptors(ctx, p, endTime, backupStmt.Targets)
+			if err != nil {
+				return err
+			}
 		}
 
 		var tables []*sqlbase.TableDescriptor
@@ -905,7 +1169,7 @@ func backupPlanHook(
 			clusterID := p.ExecCfg().ClusterID()
 			prevBackups = make([]BackupDescriptor, len(incrementalFrom))
 			for i, uri := range incrementalFrom {
-				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings)
+				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings, encryption)
 				if err != nil {
 					return errors.Wrapf(err, "failed to read backup from %q", uri)
 				}
@@ -915,6 +1179,10 @@ func backupPlanHook(
 				if !desc.ClusterID.Equal(clusterID) {
 					return errors.Errorf("previous BACKUP %q belongs to cluster %s", uri, desc.ClusterID.String())
 				}
+				if backupStmt.DescriptorCoverage == tree.AllDescriptors &&
+					desc.DescriptorCoverage != tree.AllDescriptors {
+					return errors.Errorf("previous BACKUP %q is not a full cluster backup", uri)
+				}
 				prevBackups[i] = desc
 			}
 		}
@@ -950,8 +1218,12 @@ func backupPlanHook(
 				dbsInPrev[d] = struct{}{}
 			}
 
+			// A full cluster backup covers every table, so tables created since the
+			// previous full cluster backup are expected to be missing from it and
+			// are covered from time zero by the introduced spans below.
+			fullCluster := backupStmt.DescriptorCoverage == tree.AllDescriptors
 			for _, d := range targetDescs {
-				if t := d.GetTable(); t != nil {
+				if t := d.GetTable(); t != nil && !fullCluster {
 					// If we're trying to use a previous backup for this table, ideally it
 					// actually contains this table.
 					if _, ok := tablesInPrev[t.ID]; ok {
@@ -988,7 +1260,7 @@ func backupPlanHook(
 			}
 
 			var err error
-			_, coveredTime, err := makeImportSpans(spans, prevBackups, keys.MinKey,
+			_, coveredTime, err := makeImportSpans(spans, prevBackups, nil, keys.MinKey,
 				func(span intervalccl.Range, start, end hlc.Timestamp) error {
 					if (start == hlc.Timestamp{}) {
 						newSpans = append(newSpans, roachpb.Span{Key: span.Start, EndKey: span.End})
@@ -1021,25 +1293,26 @@ func backupPlanHook(
 		// of requiring full backups after schema changes remains.
 
 		backupDesc := BackupDescriptor{
-			StartTime:         startTime,
-			EndTime:           endTime,
-			MVCCFilter:        mvccFilter,
-			Descriptors:       targetDescs,
-			DescriptorChanges: revs,
-			CompleteDbs:       completeDBs,
-			Spans:             spans,
-			IntroducedSpans:   newSpans,
-			FormatVersion:     BackupFormatDescriptorTrackingVersion,
-			BuildInfo:         build.GetInfo(),
-			NodeID:            p.ExecCfg().NodeID.Get(),
-			ClusterID:         p.ExecCfg().ClusterID(),
+			StartTime:          startTime,
+			EndTime:            endTime,
+			MVCCFilter:         mvccFilter,
+			Descriptors:        targetDescs,
+			DescriptorChanges:  revs,
+			CompleteDbs:        completeDBs,
+			DescriptorCoverage: backupStmt.DescriptorCoverage,
+			Spans:              spans,
+			IntroducedSpans:    newSpans,
+			FormatVersion:      BackupFormatDescriptorTrackingVersion,
+			BuildInfo:          build.GetInfo(),
+			NodeID:             p.ExecCfg().NodeID.Get(),
+			ClusterID:          p.ExecCfg().ClusterID(),
 		}
 
 		// Sanity check: re-run the validation that RESTORE will do, but this time
 		// including this backup, to ensure that the this backup plus any previous
 		// backups does cover the interval expected.
 		if _, coveredEnd, err := makeImportSpans(
-			spans, append(prevBackups, backupDesc), keys.MinKey, errOnMissingRange,
+			spans, append(prevBackups, backupDesc), nil, keys.MinKey, errOnMissingRange,
 		); err != nil {
 			return err
 		} else if coveredEnd != endTime {
@@ -1056,9 +1329,14 @@ func backupPlanHook(
 			return err
 		}
 
-		if err := VerifyUsableExportTarget(ctx, exportStore, to); err != nil {
+		if err := VerifyUsableExportTarget(ctx, exportStore, defaultURI, encryption); err != nil {
 			return err
 		}
+		if encryptionInfo != nil {
+			if err := writeEncryptionInfo(ctx, exportStore, encryptionInfo); err != nil {
+				return errors.Wrapf(err, "cannot write to %s", defaultURI)
+			}
+		}
 
 		_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, jobs.Record{
 			Description: description,
@@ -1072,8 +1350,10 @@ func backupPlanHook(
 			Details: jobspb.BackupDetails{
 				StartTime:        startTime,
 				EndTime:          endTime,
-				URI:              to,
+				URI:              defaultURI,
+				URIsByLocalityKV: urisByLocalityKV,
 				BackupDescriptor: descBytes,
+				Encryption:       encryption,
 			},
 			Progress: jobspb.BackupProgress{},
 		})
@@ -1112,8 +1392,21 @@ func (b *backupResumer) Resume(
 	if err != nil {
 		return err
 	}
+	var storageByLocalityKV map[string]*roachpb.ExportStorage
+	if len(details.URIsByLocalityKV) > 0 {
+		storageByLocalityKV = make(map[string]*roachpb.ExportStorage, len(details.URIsByLocalityKV))
+		for kv, uri := range details.URIsByLocalityKV {
+			conf, err := storageccl.ExportStorageConfFromURI(uri)
+			if err != nil {
+				return err
+			}
+			storageByLocalityKV[kv] = &conf
+		}
+	}
 	var checkpointDesc *BackupDescriptor
-	if desc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorCheckpointName); err == nil {
+	if desc, err := readBackupDescriptor(
+		ctx, exportStore, BackupDescriptorCheckpointName, details.Encryption,
+	); err == nil {
 		// If the checkpoint is from a different cluster, it's meaningless to us.
 		// More likely though are dummy/lock-out checkpoints with no ClusterID.
 		if desc.ClusterID.Equal(p.ExecCfg().ClusterID()) {
@@ -1133,10 +1426,12 @@ func (b *backupResumer) Resume(
 		p.ExecCfg().Gossip,
 		p.ExecCfg().Settings,
 		exportStore,
+		storageByLocalityKV,
 		job,
 		&backupDesc,
 		checkpointDesc,
 		resultsCh,
+		details.Encryption,
 	)
 	b.res = res
 	return err
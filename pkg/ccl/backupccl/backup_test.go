@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach-go/crdb"
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl/sampledataccl"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -85,6 +86,12 @@ func backupRestoreTestSetupWithParams(
 	dir, dirCleanupFn := testutils.TempDir(t)
 	params.ServerArgs.ExternalIODir = dir
 	params.ServerArgs.UseDatabase = "data"
+	for i := range params.ServerArgsPerNode {
+		param := params.ServerArgsPerNode[i]
+		param.ExternalIODir = dir
+		param.UseDatabase = "data"
+		params.ServerArgsPerNode[i] = param
+	}
 	tc = testcluster.StartTestCluster(t, clusterSize, params)
 	init(tc)
 
@@ -1535,6 +1542,274 @@ func TestBackupRestoreIncremental(t *testing.T) {
 	}
 }
 
+func TestBackupRestoreEncrypted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	const full, inc, plain = localFoo, "nodelocal:///inc", "nodelocal:///plain"
+	const withPassphrase = ` WITH encryption_passphrase = 'abcdefg'`
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`+withPassphrase, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`+withPassphrase, inc, full)
+	expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+
+	// Everything but the salt should be encrypted.
+	for _, subdir := range []string{"foo", "inc"} {
+		files, err := ioutil.ReadDir(filepath.Join(dir, subdir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if f.Name() == backupccl.BackupEncryptionInfoName {
+				continue
+			}
+			contents, err := ioutil.ReadFile(filepath.Join(dir, subdir, f.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !storageccl.AppearsEncrypted(contents) {
+				t.Fatalf("expected %s/%s to be encrypted", subdir, f.Name())
+			}
+		}
+	}
+
+	sqlDB.CheckQueryResults(t,
+		`SELECT description FROM [SHOW JOBS] WHERE job_type = 'BACKUP' ORDER BY description`,
+		[][]string{
+			{"BACKUP DATABASE data TO 'nodelocal:///foo' WITH encryption_passphrase = 'redacted'"},
+			{"BACKUP DATABASE data TO 'nodelocal:///inc' INCREMENTAL FROM 'nodelocal:///foo' WITH encryption_passphrase = 'redacted'"},
+		},
+	)
+
+	sqlDB.ExpectErr(t, "file appears encrypted", `SHOW BACKUP $1`, full)
+	sqlDB.ExpectErr(t, "file appears encrypted", `RESTORE DATABASE data FROM $1`, full)
+	sqlDB.ExpectErr(t, "file appears encrypted",
+		`BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`, "nodelocal:///inc2", full)
+	sqlDB.ExpectErr(t, "wrong key", `SHOW BACKUP $1 WITH encryption_passphrase = 'gfedcba'`, inc)
+	sqlDB.ExpectErr(t, "wrong key",
+		`RESTORE DATABASE data FROM $1 WITH encryption_passphrase = 'gfedcba'`, full)
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, plain)
+	sqlDB.ExpectErr(t, "could not find or read encryption information",
+		`RESTORE DATABASE data FROM $1`+withPassphrase, plain)
+
+	sqlDB.Exec(t, `SHOW BACKUP $1`+withPassphrase, inc)
+
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1, $2`+withPassphrase, full, inc)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank ORDER BY id`, expected)
+}
+
+func TestBackupRestoreLocalityAware(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	params := base.TestClusterArgs{}
+	params.ServerArgs.Locality = roachpb.Locality{
+		Tiers: []roachpb.Tier{{Key: "region", Value: "east"}},
+	}
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetupWithParams(
+		t, singleNode, numAccounts, initNone, params,
+	)
+	defer cleanupFn()
+
+	const defaultURI = localFoo + "?COCKROACH_LOCALITY=default"
+	const eastURI = "nodelocal:///east?COCKROACH_LOCALITY=region=east"
+	const westURI = "nodelocal:///west?COCKROACH_LOCALITY=region=west"
+	expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+
+	sqlDB.ExpectErr(t, "COCKROACH_LOCALITY must be specified",
+		`BACKUP DATABASE data TO ($1, $2)`, localFoo, eastURI)
+	sqlDB.ExpectErr(t, "multiple URIs with COCKROACH_LOCALITY=default",
+		`BACKUP DATABASE data TO ($1, $2)`, defaultURI, "nodelocal:///bar?COCKROACH_LOCALITY=default")
+	sqlDB.ExpectErr(t, "no URI with COCKROACH_LOCALITY=default",
+		`BACKUP DATABASE data TO ($1, $2)`, eastURI, westURI)
+	sqlDB.ExpectErr(t, "invalid for a single URI", `BACKUP DATABASE data TO $1`, eastURI)
+	sqlDB.ExpectErr(t, "invalid COCKROACH_LOCALITY",
+		`BACKUP DATABASE data TO ($1, $2)`, defaultURI, "nodelocal:///bar?COCKROACH_LOCALITY=east")
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO ($1, $2, $3)`, defaultURI, eastURI, westURI)
+	sqlDB.CheckQueryResults(t,
+		`SELECT description FROM [SHOW JOBS] WHERE job_type = 'BACKUP'`,
+		[][]string{{"BACKUP DATABASE data TO (" +
+			"'nodelocal:///foo?COCKROACH_LOCALITY=default', " +
+			"'nodelocal:///east?COCKROACH_LOCALITY=region%3Deast', " +
+			"'nodelocal:///west?COCKROACH_LOCALITY=region%3Dwest')"}},
+	)
+
+	// The only node is in region=east, so all the data should be there, and only
+	// the backup descriptor should be at the default URI.
+	for subdir, expectData := range map[string]bool{"foo": false, "east": true, "west": false} {
+		files, err := ioutil.ReadDir(filepath.Join(dir, subdir))
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		var found bool
+		for _, f := range files {
+			if strings.HasSuffix(f.Name(), ".sst") {
+				found = true
+			}
+		}
+		if found != expectData {
+			t.Errorf("expected data files in %s: %t, found: %t", subdir, expectData, found)
+		}
+	}
+
+	// Checking the files of the backup reads them from their locality's URI.
+	sqlDB.CheckQueryResults(t,
+		fmt.Sprintf(`SHOW BACKUP ('%s', '%s') WITH check_files`, defaultURI, eastURI), [][]string{})
+	problems := sqlDB.QueryStr(t,
+		fmt.Sprintf(`SELECT problem FROM [SHOW BACKUP '%s' WITH check_files]`, localFoo))
+	if len(problems) == 0 {
+		t.Error("expected problems checking a backup without its locality URIs")
+	}
+	for _, row := range problems {
+		if !strings.Contains(row[0], "written to locality region=east, for which no URI was given") {
+			t.Errorf("unexpected problem: %v", row)
+		}
+	}
+
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.ExpectErr(t, "no URI with COCKROACH_LOCALITY=region=east was given",
+		`RESTORE DATABASE data FROM $1`, localFoo)
+	sqlDB.ExpectErr(t, "no URI with COCKROACH_LOCALITY=region=east was given",
+		`RESTORE DATABASE data FROM ($1, $2)`, defaultURI, westURI)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM ($1, $2)`, defaultURI, eastURI)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank ORDER BY id`, expected)
+}
+
+func TestRestoreVerifyOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 100
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	const full, inc = localFoo, "nodelocal:///inc"
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`, inc, full)
+
+	// The database still exists, so an actual RESTORE would fail.
+	verify := fmt.Sprintf(`RESTORE DATABASE data FROM '%s', '%s' WITH verify_only`, full, inc)
+	sqlDB.CheckQueryResults(t, verify, [][]string{})
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM [SHOW JOBS] WHERE job_type = 'RESTORE'`, [][]string{{"0"}})
+
+	// Without the full backup, the incremental one does not cover the table.
+	rows := sqlDB.QueryStr(t,
+		`SELECT backup, path, problem FROM [RESTORE DATABASE data FROM $1 WITH verify_only]`, inc)
+	if len(rows) == 0 {
+		t.Fatal("expected problems restoring from only an incremental backup")
+	}
+	for _, row := range rows {
+		if row[0] != "NULL" || row[1] != "NULL" || !strings.Contains(row[2], "no backup covers time") {
+			t.Errorf("unexpected problem: %v", row)
+		}
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "inc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var corrupted string
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".sst") {
+			corrupted = f.Name()
+			break
+		}
+	}
+	if corrupted == "" {
+		t.Fatal("expected the incremental backup to have data files")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "inc", corrupted), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.CheckQueryResults(t,
+		fmt.Sprintf(`SELECT backup, path, problem FROM [%s]`, verify),
+		[][]string{{inc, corrupted, "checksum mismatch"}},
+	)
+}
+
+// TestBackupLocalityAwareLeaseholderMismatch checks that ranges whose lease
+// holder is not in a locality with its own URI, but which have a replica in
+// one, are exported to that replica's URI rather than the default one.
+func TestBackupLocalityAwareLeaseholderMismatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	params := base.TestClusterArgs{ServerArgsPerNode: make(map[int]base.TestServerArgs)}
+	for i, region := range []string{"east", "west", "central"} {
+		params.ServerArgsPerNode[i] = base.TestServerArgs{
+			Locality: roachpb.Locality{Tiers: []roachpb.Tier{{Key: "region", Value: region}}},
+		}
+	}
+	_, tc, sqlDB, dir, cleanupFn := backupRestoreTestSetupWithParams(
+		t, multiNode, numAccounts, initNone, params,
+	)
+	defer cleanupFn()
+
+	// moveLeases pins the leases of all of data.bank's ranges, each of which has
+	// a replica on every node, to the given node.
+	tableDesc := sqlbase.GetTableDescriptor(tc.Servers[0].DB(), "data", "bank")
+	moveLeases := func(serverIdx int, region string) {
+		sqlDB.Exec(t, fmt.Sprintf(
+			`ALTER TABLE data.bank CONFIGURE ZONE USING lease_preferences = '[[+region=%s]]'`, region))
+		span := tableDesc.TableSpan()
+		for key := span.Key; key.Compare(span.EndKey) < 0; {
+			desc, err := tc.LookupRange(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testutils.SucceedsSoon(t, func() error {
+				return tc.TransferRangeLease(desc, tc.Target(serverIdx))
+			})
+			key = desc.EndKey.AsRawKey()
+		}
+	}
+	dataFiles := func(subdir string) int {
+		files, err := ioutil.ReadDir(filepath.Join(dir, subdir))
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		var found int
+		for _, f := range files {
+			if strings.HasSuffix(f.Name(), ".sst") {
+				found++
+			}
+		}
+		return found
+	}
+
+	const defaultURI = localFoo + "?COCKROACH_LOCALITY=default"
+	const westURI = "nodelocal:///west?COCKROACH_LOCALITY=region=west"
+
+	// The lease holders are in region=east, which has no URI of its own, but
+	// every range has a replica in region=west, which does.
+	moveLeases(0, "east")
+	sqlDB.Exec(t, `BACKUP DATABASE data TO ($1, $2)`, defaultURI, westURI)
+	if n := dataFiles("foo"); n != 0 {
+		t.Fatalf("expected no data files at the default URI, found %d", n)
+	}
+	if n := dataFiles("west"); n == 0 {
+		t.Fatal("expected data files at the region=west URI")
+	}
+
+	moveLeases(1, "west")
+	sqlDB.Exec(t, `BACKUP DATABASE data TO ($1, $2)`,
+		localFoo+"/2?COCKROACH_LOCALITY=default", "nodelocal:///west/2?COCKROACH_LOCALITY=region=west")
+	if n := dataFiles("foo/2"); n != 0 {
+		t.Fatalf("expected no data files at the default URI, found %d", n)
+	}
+	if n := dataFiles("west/2"); n == 0 {
+		t.Fatal("expected data files at the region=west URI")
+	}
+}
+
 // a bg worker is intended to write to the bank table concurrent with other
 // operations (writes, backups, restores), mutating the payload on rows-maxID.
 // it notified the `wake` channel (to allow ensuring bg activity has occurred)
@@ -2364,6 +2639,101 @@ func TestRestoreDatabaseVersusTable(t *testing.T) {
 	})
 }
 
+func TestFullClusterBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, tc, origDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	args := base.TestServerArgs{ExternalIODir: tc.Servers[0].ClusterSettings().ExternalIODir}
+
+	const tablesBackup = "nodelocal:///tables"
+	for _, q := range []string{
+		`CREATE DATABASE d2`,
+		`CREATE TABLE d2.t (a INT PRIMARY KEY)`,
+		`INSERT INTO d2.t VALUES (1), (2), (3)`,
+		`CREATE TABLE defaultdb.u (a INT PRIMARY KEY)`,
+		`INSERT INTO defaultdb.u VALUES (4)`,
+		`CREATE USER someone`,
+		`GRANT SELECT ON data.bank TO someone`,
+		`ALTER TABLE data.bank CONFIGURE ZONE USING gc.ttlseconds = 3600`,
+		`COMMENT ON TABLE data.bank IS 'accounts'`,
+		`SET CLUSTER SETTING jobs.registry.leniency = '2m'`,
+	} {
+		origDB.Exec(t, q)
+	}
+	origDB.Exec(t, `BACKUP DATABASE data TO $1`, tablesBackup)
+	origDB.Exec(t, `BACKUP TO $1`, localFoo)
+
+	bankQuery := `SELECT * FROM data.bank ORDER BY id`
+	zoneQuery := `SHOW ZONE CONFIGURATION FOR TABLE data.bank`
+	settingQuery := `SELECT value FROM system.settings WHERE name = 'jobs.registry.leniency'`
+	commentQuery := `SELECT comment FROM system.comments WHERE object_id = (
+		SELECT table_id FROM crdb_internal.tables WHERE database_name = 'data' AND name = 'bank')`
+
+	t.Run("restore", func(t *testing.T) {
+		tcRestore := testcluster.StartTestCluster(t, singleNode, base.TestClusterArgs{ServerArgs: args})
+		defer tcRestore.Stopper().Stop(context.TODO())
+		sqlDB := sqlutils.MakeSQLRunner(tcRestore.Conns[0])
+
+		sqlDB.Exec(t, `RESTORE FROM $1`, localFoo)
+
+		sqlDB.CheckQueryResults(t, bankQuery, origDB.QueryStr(t, bankQuery))
+		sqlDB.CheckQueryResults(t, `SELECT * FROM d2.t`, [][]string{{"1"}, {"2"}, {"3"}})
+		sqlDB.CheckQueryResults(t, `SELECT * FROM defaultdb.u`, [][]string{{"4"}})
+		sqlDB.CheckQueryResults(t, `SELECT username FROM system.users WHERE username = 'someone'`,
+			[][]string{{"someone"}})
+		sqlDB.CheckQueryResults(t, settingQuery, origDB.QueryStr(t, settingQuery))
+		sqlDB.CheckQueryResults(t, zoneQuery, origDB.QueryStr(t, zoneQuery))
+		sqlDB.CheckQueryResults(t, commentQuery, [][]string{{"accounts"}})
+		sqlDB.CheckQueryResults(t,
+			`SELECT status FROM system.jobs WHERE description LIKE 'BACKUP DATABASE data TO %'`,
+			[][]string{{"succeeded"}})
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(*) FROM system.namespace WHERE name = 'crdb_temp_system'`, [][]string{{"0"}})
+	})
+
+	t.Run("non-empty", func(t *testing.T) {
+		tcRestore := testcluster.StartTestCluster(t, singleNode, base.TestClusterArgs{ServerArgs: args})
+		defer tcRestore.Stopper().Stop(context.TODO())
+		sqlDB := sqlutils.MakeSQLRunner(tcRestore.Conns[0])
+
+		sqlDB.Exec(t, `CREATE DATABASE other`)
+		sqlDB.ExpectErr(t, `found database "other"`, `RESTORE FROM $1`, localFoo)
+		sqlDB.Exec(t, `RESTORE FROM $1 WITH force_nonempty_cluster`, localFoo)
+		sqlDB.CheckQueryResults(t, bankQuery, origDB.QueryStr(t, bankQuery))
+	})
+
+	t.Run("not-full-cluster", func(t *testing.T) {
+		tcRestore := testcluster.StartTestCluster(t, singleNode, base.TestClusterArgs{ServerArgs: args})
+		defer tcRestore.Stopper().Stop(context.TODO())
+		sqlDB := sqlutils.MakeSQLRunner(tcRestore.Conns[0])
+
+		sqlDB.ExpectErr(t, "requires a full cluster backup", `RESTORE FROM $1`, tablesBackup)
+		sqlDB.ExpectErr(t, "only supported by full cluster RESTORE",
+			`RESTORE DATABASE data FROM $1 WITH force_nonempty_cluster`, tablesBackup)
+		sqlDB.Exec(t, `RESTORE DATABASE d2 FROM $1`, localFoo)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM d2.t`, [][]string{{"1"}, {"2"}, {"3"}})
+	})
+
+	t.Run("incremental", func(t *testing.T) {
+		const incremental = "nodelocal:///incremental"
+		origDB.Exec(t, `CREATE DATABASE d3`)
+		origDB.Exec(t, `CREATE TABLE d3.t (a INT PRIMARY KEY)`)
+		origDB.Exec(t, `INSERT INTO d3.t VALUES (5)`)
+		origDB.ExpectErr(t, "is not a full cluster backup",
+			`BACKUP TO $1 INCREMENTAL FROM $2`, incremental, tablesBackup)
+		origDB.Exec(t, `BACKUP TO $1 INCREMENTAL FROM $2`, incremental, localFoo)
+
+		tcRestore := testcluster.StartTestCluster(t, singleNode, base.TestClusterArgs{ServerArgs: args})
+		defer tcRestore.Stopper().Stop(context.TODO())
+		sqlDB := sqlutils.MakeSQLRunner(tcRestore.Conns[0])
+
+		sqlDB.Exec(t, `RESTORE FROM $1, $2`, localFoo, incremental)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM d3.t`, [][]string{{"5"}})
+	})
+}
+
 func TestBackupAzureAccountName(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
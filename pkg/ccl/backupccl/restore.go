@@ -50,21 +50,29 @@ const (
 	restoreOptIntoDB               = "into_db"
 	restoreOptSkipMissingFKs       = "skip_missing_foreign_keys"
 	restoreOptSkipMissingSequences = "skip_missing_sequences"
+	restoreOptForceNonEmptyCluster = "force_nonempty_cluster"
+	restoreOptVerifyOnly           = "verify_only"
 )
 
 var restoreOptionExpectValues = map[string]sql.KVStringOptValidate{
 	restoreOptIntoDB:               sql.KVStringOptRequireValue,
 	restoreOptSkipMissingFKs:       sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingSequences: sql.KVStringOptRequireNoValue,
+	restoreOptForceNonEmptyCluster: sql.KVStringOptRequireNoValue,
+	restoreOptVerifyOnly:           sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:         sql.KVStringOptRequireValue,
 }
 
 func loadBackupDescs(
-	ctx context.Context, uris []string, settings *cluster.Settings,
+	ctx context.Context,
+	uris []string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, error) {
 	backupDescs := make([]BackupDescriptor, len(uris))
 
 	for i, uri := range uris {
-		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings)
+		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings, encryption)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to read backup descriptor")
 		}
@@ -590,9 +598,13 @@ func errOnMissingRange(span intervalccl.Range, start, end hlc.Timestamp) error {
 //
 // If a span is not covered, the onMissing function is called with the span and
 // time missing to determine what error, if any, should be returned.
+//
+// If backupLocalityInfo is non-nil, it holds the locality-specific URIs of each
+// of the backups, from which the files written to those localities are read.
 func makeImportSpans(
 	tableSpans []roachpb.Span,
 	backups []BackupDescriptor,
+	backupLocalityInfo []jobspb.RestoreDetails_BackupLocalityInfo,
 	lowWaterMark roachpb.Key,
 	onMissing func(span intervalccl.Range, start, end hlc.Timestamp) error,
 ) ([]importEntry, hlc.Timestamp, error) {
@@ -632,11 +644,22 @@ func makeImportSpans(
 	// backup2 files) so they will retain that alternation in the output of
 	// OverlapCoveringMerge.
 	var maxEndTime hlc.Timestamp
-	for _, b := range backups {
+	for i, b := range backups {
 		if maxEndTime.Less(b.EndTime) {
 			maxEndTime = b.EndTime
 		}
 
+		storesByLocalityKV := make(map[string]roachpb.ExportStorage)
+		if i < len(backupLocalityInfo) {
+			for kv, uri := range backupLocalityInfo[i].URIsByLocalityKV {
+				conf, err := storageccl.ExportStorageConfFromURI(uri)
+				if err != nil {
+					return nil, hlc.Timestamp{}, err
+				}
+				storesByLocalityKV[kv] = conf
+			}
+		}
+
 		var backupNewSpanCovering intervalccl.Covering
 		for _, s := range b.IntroducedSpans {
 			backupNewSpanCovering = append(backupNewSpanCovering, intervalccl.Range{
@@ -658,13 +681,21 @@ func makeImportSpans(
 		backupCoverings = append(backupCoverings, backupSpanCovering)
 		var backupFileCovering intervalccl.Covering
 		for _, f := range b.Files {
+			dir := b.Dir
+			if f.LocalityKV != "" && backupLocalityInfo != nil {
+				var ok bool
+				if dir, ok = storesByLocalityKV[f.LocalityKV]; !ok {
+					return nil, hlc.Timestamp{}, errors.Errorf(
+						"no URI given for backup files written to locality %s", f.LocalityKV)
+				}
+			}
 			backupFileCovering = append(backupFileCovering, intervalccl.Range{
 				Start: f.Span.Key,
 				End:   f.Span.EndKey,
 				Payload: importEntry{
 					Span:      f.Span,
 					entryType: backupFile,
-					dir:       b.Dir,
+					dir:       dir,
 					file:      f,
 				},
 			})
@@ -932,21 +963,25 @@ func WriteTableDescs(
 }
 
 func restoreJobDescription(
-	restore *tree.Restore, from []string, opts map[string]string,
+	restore *tree.Restore, from [][]string, opts map[string]string,
 ) (string, error) {
 	r := &tree.Restore{
-		AsOf:    restore.AsOf,
-		Options: optsToKVOptions(opts),
-		Targets: restore.Targets,
-		From:    make(tree.Exprs, len(restore.From)),
+		AsOf:               restore.AsOf,
+		Options:            optsToKVOptions(opts),
+		Targets:            restore.Targets,
+		DescriptorCoverage: restore.DescriptorCoverage,
+		From:               make([]tree.StringOrPlaceholderOptList, len(from)),
 	}
 
-	for i, f := range from {
-		sf, err := storageccl.SanitizeExportStorageURI(f)
-		if err != nil {
-			return "", err
+	for i, uris := range from {
+		r.From[i] = make(tree.StringOrPlaceholderOptList, len(uris))
+		for j, uri := range uris {
+			sf, err := sanitizeLocalityURI(uri)
+			if err != nil {
+				return "", err
+			}
+			r.From[i][j] = tree.NewDString(sf)
 		}
-		r.From[i] = tree.NewDString(sf)
 	}
 
 	return tree.AsStringWithFlags(r, tree.FmtAlwaysQualifyTableNames), nil
@@ -959,12 +994,14 @@ func restore(
 	db *client.DB,
 	gossip *gossip.Gossip,
 	backupDescs []BackupDescriptor,
+	backupLocalityInfo []jobspb.RestoreDetails_BackupLocalityInfo,
 	endTime hlc.Timestamp,
 	sqlDescs []sqlbase.Descriptor,
 	tableRewrites TableRewriteMap,
 	overrideDB string,
 	job *jobs.Job,
 	resultsCh chan<- tree.Datums,
+	encryption *roachpb.FileEncryptionOptions,
 ) (roachpb.BulkOpSummary, []*sqlbase.DatabaseDescriptor, []*sqlbase.TableDescriptor, error) {
 	// A note about contexts and spans in this method: the top-level context
 	// `restoreCtx` is used for orchestration logging. All operations that carry
@@ -1039,7 +1076,9 @@ func restore(
 	// Pivot the backups, which are grouped by time, into requests for import,
 	// which are grouped by keyrange.
 	highWaterMark := job.Progress().Details.(*jobspb.Progress_Restore).Restore.HighWater
-	importSpans, _, err := makeImportSpans(spans, backupDescs, highWaterMark, errOnMissingRange)
+	importSpans, _, err := makeImportSpans(
+		spans, backupDescs, backupLocalityInfo, highWaterMark, errOnMissingRange,
+	)
 	if err != nil {
 		return mu.res, nil, nil, errors.Wrapf(err, "making import requests for %d backups", len(backupDescs))
 	}
@@ -1079,9 +1118,22 @@ func restore(
 	// that's wrong.
 	//
 	// TODO(dan): Make this limiting per node.
+	//
+	// The job's resource limits, set with ALTER JOB, can lower the number of
+	// outstanding requests and the rate at which they ingest data.
+	limits := job.Payload().ResourceLimits
 	numClusterNodes := clusterNodeCount(gossip)
-	maxConcurrentImports := numClusterNodes * runtime.NumCPU()
+	maxConcurrentImports := limits.LimitConcurrency(numClusterNodes * runtime.NumCPU())
 	importsSem := make(chan struct{}, maxConcurrentImports)
+	// The Import requests that may be outstanding at once split the job's
+	// max_bytes_per_sec evenly, each throttling the data it adds.
+	var importBytesPerSec int64
+	if bytesPerSec := limits.BytesPerSec(); bytesPerSec > 0 {
+		importBytesPerSec = bytesPerSec / int64(maxConcurrentImports)
+		if importBytesPerSec < 1 {
+			importBytesPerSec = 1
+		}
+	}
 
 	g := ctxgroup.WithContext(restoreCtx)
 
@@ -1122,11 +1174,13 @@ func restore(
 				// Import is a point request because we don't want DistSender to split
 				// it. Assume (but don't require) the entire post-rewrite span is on the
 				// same range.
-				RequestHeader: roachpb.RequestHeader{Key: newSpan.Key},
-				DataSpan:      readyForImportSpan.Span,
-				Files:         readyForImportSpan.files,
-				EndTime:       endTime,
-				Rekeys:        rekeys,
+				RequestHeader:  roachpb.RequestHeader{Key: newSpan.Key},
+				DataSpan:       readyForImportSpan.Span,
+				Files:          readyForImportSpan.files,
+				EndTime:        endTime,
+				Rekeys:         rekeys,
+				Encryption:     encryption,
+				MaxBytesPerSec: importBytesPerSec,
 			}
 
 			log.VEventf(restoreCtx, 1, "importing %d of %d", idx, len(importSpans))
@@ -1148,8 +1202,9 @@ func restore(
 					return pErr.GoError()
 				}
 
+				imported := importRes.(*roachpb.ImportResponse).Imported
 				mu.Lock()
-				mu.res.Add(importRes.(*roachpb.ImportResponse).Imported)
+				mu.res.Add(imported)
 
 				// Assert that we're actually marking the correct span done. See #23977.
 				if !importSpans[idx].Key.Equal(importRequest.DataSpan.Key) {
@@ -1203,9 +1258,13 @@ func restorePlanHook(
 		return nil, nil, nil, nil
 	}
 
-	fromFn, err := p.TypeAsStringArray(restoreStmt.From, "RESTORE")
-	if err != nil {
-		return nil, nil, nil, err
+	fromFns := make([]func() ([]string, error), len(restoreStmt.From))
+	for i := range restoreStmt.From {
+		fromFn, err := p.TypeAsStringArray(tree.Exprs(restoreStmt.From[i]), "RESTORE")
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		fromFns[i] = fromFn
 	}
 
 	optsFn, err := p.TypeAsStringOpts(restoreStmt.Options, restoreOptionExpectValues)
@@ -1213,6 +1272,14 @@ func restorePlanHook(
 		return nil, nil, nil, err
 	}
 
+	// With verify_only, a RESTORE only reports the problems with its backups.
+	header := RestoreHeader
+	for _, opt := range restoreStmt.Options {
+		if string(opt.Key) == restoreOptVerifyOnly {
+			header = backupVerifyHeader
+		}
+	}
+
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
@@ -1248,9 +1315,12 @@ func restorePlanHook(
 			)
 		}
 
-		from, err := fromFn()
-		if err != nil {
-			return err
+		from := make([][]string, len(fromFns))
+		for i := range fromFns {
+			var err error
+			if from[i], err = fromFns[i](); err != nil {
+				return err
+			}
 		}
 		var endTime hlc.Timestamp
 		if restoreStmt.AsOf.Expr != nil {
@@ -1269,23 +1339,80 @@ func restorePlanHook(
 		}
 		return doRestorePlan(ctx, restoreStmt, p, from, endTime, opts, resultsCh)
 	}
-	return fn, RestoreHeader, nil, nil
+	return fn, header, nil, nil
 }
 
 func doRestorePlan(
 	ctx context.Context,
 	restoreStmt *tree.Restore,
 	p sql.PlanHookState,
-	from []string,
+	from [][]string,
 	endTime hlc.Timestamp,
 	opts map[string]string,
 	resultsCh chan<- tree.Datums,
 ) error {
-	backupDescs, err := loadBackupDescs(ctx, from, p.ExecCfg().Settings)
+	// Each backup in the chain may have been written to several locality-specific
+	// URIs, with its backup descriptor at the default one.
+	defaultURIs := make([]string, len(from))
+	localityInfo := make([]jobspb.RestoreDetails_BackupLocalityInfo, len(from))
+	hasLocalityURIs := false
+	for i, uris := range from {
+		var err error
+		defaultURIs[i], localityInfo[i].URIsByLocalityKV, err = getURIsByLocalityKV(uris)
+		if err != nil {
+			return err
+		}
+		for _, uri := range localityInfo[i].URIsByLocalityKV {
+			if _, err := storageccl.ExportStorageConfFromURI(uri); err != nil {
+				return err
+			}
+			hasLocalityURIs = true
+		}
+	}
+	var backupLocalityInfo []jobspb.RestoreDetails_BackupLocalityInfo
+	if hasLocalityURIs {
+		if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionLocalityAwareBackup) {
+			return errors.Errorf("RESTORE from locality-specific URIs requires all nodes to be upgraded to %s",
+				cluster.VersionByKey(cluster.VersionLocalityAwareBackup))
+		}
+		backupLocalityInfo = localityInfo
+	}
+
+	var encryption *roachpb.FileEncryptionOptions
+	if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+		if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionBackupEncryption) {
+			return errors.Errorf("%s requires all nodes to be upgraded to %s",
+				backupOptEncPassphrase, cluster.VersionByKey(cluster.VersionBackupEncryption))
+		}
+		// Every backup in the chain shares the salt of the first, full, backup.
+		var err error
+		encryption, err = EncryptionFromPassphrase(ctx, defaultURIs[0], p.ExecCfg().Settings, passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	backupDescs, err := loadBackupDescs(ctx, defaultURIs, p.ExecCfg().Settings, encryption)
 	if err != nil {
 		return err
 	}
 
+	// Files written to a locality can only be read from that locality's URI, so
+	// check now that one was given rather than failing partway through. With
+	// verify_only, this is reported along with any other problems.
+	_, verifyOnly := opts[restoreOptVerifyOnly]
+	for i, b := range backupDescs {
+		for _, f := range b.Files {
+			if f.LocalityKV == "" {
+				continue
+			}
+			if _, ok := localityInfo[i].URIsByLocalityKV[f.LocalityKV]; !ok && !verifyOnly {
+				return errors.Errorf("backup has files written to locality %s, but no URI with %s=%s was given",
+					f.LocalityKV, localityURLParam, f.LocalityKV)
+			}
+		}
+	}
+
 	if !endTime.IsEmpty() {
 		ok := false
 		for _, b := range backupDescs {
@@ -1317,11 +1444,38 @@ func doRestorePlan(
 		}
 	}
 
-	sqlDescs, restoreDBs, err := selectTargets(ctx, p, backupDescs, restoreStmt.Targets, endTime)
+	var sqlDescs []sqlbase.Descriptor
+	var restoreDBs []*sqlbase.DatabaseDescriptor
+	if restoreStmt.DescriptorCoverage == tree.AllDescriptors {
+		if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionFullClusterBackup) {
+			return errors.Errorf("full cluster RESTORE requires all nodes to be upgraded to %s",
+				cluster.VersionByKey(cluster.VersionFullClusterBackup))
+		}
+		if _, ok := opts[restoreOptIntoDB]; ok {
+			return errors.Errorf("cannot use %q option with full cluster RESTORE", restoreOptIntoDB)
+		}
+		if _, ok := opts[restoreOptForceNonEmptyCluster]; !ok && !verifyOnly {
+			if err := checkClusterEmpty(ctx, p.ExecCfg().DB); err != nil {
+				return err
+			}
+		}
+		sqlDescs, restoreDBs, err = selectFullClusterTargets(ctx, p, backupDescs, endTime)
+	} else {
+		if _, ok := opts[restoreOptForceNonEmptyCluster]; ok {
+			return errors.Errorf("%q option is only supported by full cluster RESTORE", restoreOptForceNonEmptyCluster)
+		}
+		sqlDescs, restoreDBs, err = selectTargets(ctx, p, backupDescs, restoreStmt.Targets, endTime)
+	}
 	if err != nil {
 		return err
 	}
 
+	if verifyOnly {
+		return verifyRestore(
+			ctx, p.ExecCfg().Settings, defaultURIs, localityInfo, backupDescs, sqlDescs, encryption, resultsCh,
+		)
+	}
+
 	tableRewrites, err := allocateTableRewrites(ctx, p, sqlDescs, restoreDBs, opts)
 	if err != nil {
 		return err
@@ -1351,11 +1505,14 @@ func doRestorePlan(
 			return sqlDescIDs
 		}(),
 		Details: jobspb.RestoreDetails{
-			EndTime:       endTime,
-			TableRewrites: tableRewrites,
-			URIs:          from,
-			TableDescs:    tables,
-			OverrideDB:    opts[restoreOptIntoDB],
+			EndTime:            endTime,
+			TableRewrites:      tableRewrites,
+			URIs:               defaultURIs,
+			BackupLocalityInfo: backupLocalityInfo,
+			TableDescs:         tables,
+			OverrideDB:         opts[restoreOptIntoDB],
+			Encryption:         encryption,
+			DescriptorCoverage: restoreStmt.DescriptorCoverage,
 		},
 		Progress: jobspb.RestoreProgress{},
 	})
@@ -1368,12 +1525,15 @@ func doRestorePlan(
 func loadBackupSQLDescs(
 	ctx context.Context, details jobspb.RestoreDetails, settings *cluster.Settings,
 ) ([]BackupDescriptor, []sqlbase.Descriptor, error) {
-	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings)
+	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings, details.Encryption)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	allDescs, _ := loadSQLDescsFromBackupsAtTime(backupDescs, details.EndTime)
+	if details.DescriptorCoverage == tree.AllDescriptors {
+		allDescs = renameSystemDatabase(allDescs)
+	}
 
 	var sqlDescs []sqlbase.Descriptor
 	for _, desc := range allDescs {
@@ -1402,22 +1562,46 @@ func (r *restoreResumer) Resume(
 		return err
 	}
 
+	var idRewrites map[sqlbase.ID]sqlbase.ID
+	if details.DescriptorCoverage == tree.AllDescriptors {
+		// restore rewrites the descriptors in place, so the mapping from their
+		// old IDs needs to be determined first.
+		idRewrites, err = fullClusterDescIDRewrites(ctx, p.ExecCfg().DB, backupDescs, details)
+		if err != nil {
+			return err
+		}
+	}
+
 	res, databases, tables, err := restore(
 		ctx,
 		p.ExecCfg().DB,
 		p.ExecCfg().Gossip,
 		backupDescs,
+		details.BackupLocalityInfo,
 		details.EndTime,
 		sqlDescs,
 		details.TableRewrites,
 		details.OverrideDB,
 		job,
 		resultsCh,
+		details.Encryption,
 	)
 	r.res = res
+	if err != nil {
+		return err
+	}
+
+	if details.DescriptorCoverage == tree.AllDescriptors {
+		databases, tables, err = restoreSystemTables(
+			ctx, p.ExecCfg(), job.Payload().Username, r.settings, databases, tables, idRewrites,
+		)
+		if err != nil {
+			return err
+		}
+	}
 	r.databases = databases
 	r.tables = tables
-	return err
+	return nil
 }
 
 // OnFailOrCancel removes KV data that has been committed from a restore that
@@ -1431,11 +1615,37 @@ func (r *restoreResumer) OnFailOrCancel(ctx context.Context, txn *client.Txn, jo
 	if err := txn.SetSystemConfigTrigger(); err != nil {
 		return err
 	}
+	// The temporary copies of the system tables of a full cluster restore may
+	// already have been made live, along with their database.
+	tempSystemDBID := sqlbase.InvalidID
+	if details.DescriptorCoverage == tree.AllDescriptors {
+		if rewrite, ok := details.TableRewrites[keys.SystemDatabaseID]; ok {
+			tempSystemDBID = rewrite.TableID
+		}
+	}
 	b := txn.NewBatch()
 	for _, tableDesc := range details.TableDescs {
 		tableDesc.State = sqlbase.TableDescriptor_DROP
+		if tempSystemDBID != sqlbase.InvalidID && tableDesc.ParentID == tempSystemDBID {
+			b.Put(sqlbase.MakeDescMetadataKey(tableDesc.ID), sqlbase.WrapDescriptor(tableDesc))
+			b.Del(tableDesc.GetNameMetadataKey())
+			continue
+		}
 		b.CPut(sqlbase.MakeDescMetadataKey(tableDesc.ID), sqlbase.WrapDescriptor(tableDesc), nil)
 	}
+	if tempSystemDBID != sqlbase.InvalidID {
+		nameKey := sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, restoreTempSystemDB)
+		existing, err := txn.Get(ctx, nameKey)
+		if err != nil {
+			return err
+		}
+		if existing.Value != nil {
+			if id, err := existing.Value.GetInt(); err == nil && sqlbase.ID(id) == tempSystemDBID {
+				b.Del(nameKey)
+			}
+		}
+		b.Del(sqlbase.MakeDescMetadataKey(tempSystemDBID))
+	}
 	return txn.Run(ctx, b)
 }
 
@@ -0,0 +1,344 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// restoreTempSystemDB is the name of the database into which a full cluster
+// RESTORE restores the backed up system tables, under new IDs, before copying
+// their contents into the real system tables.
+const restoreTempSystemDB = "crdb_temp_system"
+
+// checkClusterEmpty returns an error if the cluster has any databases other
+// than the ones every cluster starts with, or any tables outside of the system
+// database, since a full cluster RESTORE expects to be the first thing to
+// populate the cluster.
+func checkClusterEmpty(ctx context.Context, db *client.DB) error {
+	return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		descs, err := allSQLDescriptors(ctx, txn)
+		if err != nil {
+			return err
+		}
+		for _, desc := range descs {
+			if dbDesc := desc.GetDatabase(); dbDesc != nil {
+				switch dbDesc.Name {
+				case sqlbase.SystemDB.Name, sessiondata.DefaultDatabaseName, sessiondata.PgDatabaseName:
+					continue
+				}
+				return errors.Errorf(
+					"full cluster RESTORE can only be run on a cluster with no user databases or tables, found database %q (or use the %q option)",
+					dbDesc.Name, restoreOptForceNonEmptyCluster,
+				)
+			}
+			if tableDesc := desc.GetTable(); tableDesc != nil {
+				if tableDesc.ParentID == keys.SystemDatabaseID || tableDesc.Dropped() {
+					continue
+				}
+				return errors.Errorf(
+					"full cluster RESTORE can only be run on a cluster with no user databases or tables, found table %q (or use the %q option)",
+					tableDesc.Name, restoreOptForceNonEmptyCluster,
+				)
+			}
+		}
+		return nil
+	})
+}
+
+// renameSystemDatabase replaces the system database descriptor in descs with
+// a copy named restoreTempSystemDB, so that the backed up system tables are
+// restored into that database like any other table.
+func renameSystemDatabase(descs []sqlbase.Descriptor) []sqlbase.Descriptor {
+	renamed := make([]sqlbase.Descriptor, len(descs))
+	for i, desc := range descs {
+		if dbDesc := desc.GetDatabase(); dbDesc != nil && dbDesc.ID == keys.SystemDatabaseID {
+			tempDB := *dbDesc
+			tempDB.Name = restoreTempSystemDB
+			desc = *sqlbase.WrapDescriptor(&tempDB)
+		}
+		renamed[i] = desc
+	}
+	return renamed
+}
+
+// selectFullClusterTargets returns the descriptors restored by a full cluster
+// RESTORE, with the system database renamed to restoreTempSystemDB, and the
+// databases among them that need to be created. Backed up databases that
+// already exist in the cluster, such as defaultdb, are not recreated; their
+// tables are restored into the existing database.
+func selectFullClusterTargets(
+	ctx context.Context, p sql.PlanHookState, backupDescs []BackupDescriptor, asOf hlc.Timestamp,
+) ([]sqlbase.Descriptor, []*sqlbase.DatabaseDescriptor, error) {
+	allDescs, lastBackupDesc := loadSQLDescsFromBackupsAtTime(backupDescs, asOf)
+	if lastBackupDesc.DescriptorCoverage != tree.AllDescriptors {
+		return nil, nil, errors.Errorf(
+			"RESTORE without targets requires a full cluster backup (use SHOW BACKUP to determine available tables)")
+	}
+
+	var descs []sqlbase.Descriptor
+	var restoreDBs []*sqlbase.DatabaseDescriptor
+	if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		descs, restoreDBs = nil, nil
+		for _, desc := range renameSystemDatabase(allDescs) {
+			if tableDesc := desc.GetTable(); tableDesc != nil && tableDesc.Dropped() {
+				continue
+			}
+			if dbDesc := desc.GetDatabase(); dbDesc != nil {
+				existing, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, dbDesc.Name))
+				if err != nil {
+					return err
+				}
+				if existing.Value == nil || dbDesc.Name == restoreTempSystemDB {
+					restoreDBs = append(restoreDBs, dbDesc)
+				}
+			}
+			descs = append(descs, desc)
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	return descs, restoreDBs, nil
+}
+
+// fullClusterDescIDRewrites maps the ID of every database and table in a full
+// cluster backup to its ID in the restoring cluster: the newly allocated ID
+// if it was restored, or the ID of the existing database of the same name
+// that its tables were restored into. It must be called before restore
+// rewrites the descriptors in place.
+func fullClusterDescIDRewrites(
+	ctx context.Context,
+	db *client.DB,
+	backupDescs []BackupDescriptor,
+	details jobspb.RestoreDetails,
+) (map[sqlbase.ID]sqlbase.ID, error) {
+	allDescs, _ := loadSQLDescsFromBackupsAtTime(backupDescs, details.EndTime)
+	rewrites := make(map[sqlbase.ID]sqlbase.ID, len(allDescs))
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		for _, desc := range allDescs {
+			if rewrite, ok := details.TableRewrites[desc.GetID()]; ok {
+				rewrites[desc.GetID()] = rewrite.TableID
+				continue
+			}
+			dbDesc := desc.GetDatabase()
+			if dbDesc == nil || dbDesc.ID == keys.SystemDatabaseID {
+				continue
+			}
+			existing, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, dbDesc.Name))
+			if err != nil {
+				return err
+			}
+			if existing.Value == nil {
+				continue
+			}
+			id, err := existing.Value.GetInt()
+			if err != nil {
+				return err
+			}
+			rewrites[dbDesc.ID] = sqlbase.ID(id)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return rewrites, nil
+}
+
+// rewriteDescID returns the ID that a descriptor ID stored in a backed up
+// system table refers to in the restoring cluster. Reserved IDs, which belong
+// to the system database, its tables and the pseudo-tables used for zone
+// configs, are the same in every cluster. IDs of descriptors that were not
+// restored, e.g. because they were dropped, are not mapped.
+func rewriteDescID(id sqlbase.ID, rewrites map[sqlbase.ID]sqlbase.ID) (sqlbase.ID, bool) {
+	if id <= keys.MaxReservedDescID {
+		return id, true
+	}
+	newID, ok := rewrites[id]
+	return newID, ok
+}
+
+// restoreSystemTables makes the temporary copies of the system tables restored
+// by a full cluster RESTORE live, copies their contents into the real system
+// tables and then drops them. It returns the remaining databases and tables,
+// which are made live when the job succeeds.
+func restoreSystemTables(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	username string,
+	settings *cluster.Settings,
+	databases []*sqlbase.DatabaseDescriptor,
+	tables []*sqlbase.TableDescriptor,
+	idRewrites map[sqlbase.ID]sqlbase.ID,
+) ([]*sqlbase.DatabaseDescriptor, []*sqlbase.TableDescriptor, error) {
+	var tempDB *sqlbase.DatabaseDescriptor
+	var userDBs []*sqlbase.DatabaseDescriptor
+	for _, dbDesc := range databases {
+		if dbDesc.Name == restoreTempSystemDB {
+			tempDB = dbDesc
+		} else {
+			userDBs = append(userDBs, dbDesc)
+		}
+	}
+	var tempTables, userTables []*sqlbase.TableDescriptor
+	restored := make(map[string]struct{})
+	for _, tableDesc := range tables {
+		if tempDB != nil && tableDesc.ParentID == tempDB.ID {
+			tempTables = append(tempTables, tableDesc)
+			restored[tableDesc.Name] = struct{}{}
+		} else {
+			userTables = append(userTables, tableDesc)
+		}
+	}
+	if len(tempTables) == 0 {
+		return nil, nil, errors.Errorf("full cluster RESTORE did not restore any system tables")
+	}
+
+	// A resumed job may already have made the temporary tables live, and even
+	// dropped them again once their contents were copied. Dropped tables keep
+	// their descriptors until they are garbage collected, unlike the database.
+	copied := false
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		existing, err := txn.Get(ctx, tempTables[0].GetDescMetadataKey())
+		if err != nil {
+			return err
+		}
+		if existing.Value != nil {
+			name, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, restoreTempSystemDB))
+			if err != nil {
+				return err
+			}
+			copied = name.Value == nil
+			return nil
+		}
+		if err := txn.SetSystemConfigTrigger(); err != nil {
+			return err
+		}
+		return WriteTableDescs(ctx, txn, []*sqlbase.DatabaseDescriptor{tempDB}, tempTables, username, settings, nil)
+	}); err != nil {
+		return nil, nil, errors.Wrap(err, "making temporary system tables live")
+	}
+	if copied {
+		return userDBs, userTables, nil
+	}
+
+	ie := execCfg.InternalExecutor
+	for _, name := range fullClusterSystemTables {
+		if _, ok := restored[name]; !ok {
+			// The backup was taken by a cluster that did not have this table yet.
+			continue
+		}
+		log.Eventf(ctx, "restoring system table %s", name)
+		// Each system table is copied in its own transaction since writes to the
+		// system config tables must come first in a transaction.
+		if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return restoreSystemTable(ctx, ie, txn, name, idRewrites)
+		}); err != nil {
+			return nil, nil, errors.Wrapf(err, "restoring system table %s", name)
+		}
+	}
+
+	if _, err := ie.Exec(
+		ctx, "restore-drop-temp-system-db", nil, /* txn */
+		fmt.Sprintf("DROP DATABASE %s CASCADE", restoreTempSystemDB),
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "dropping temporary system tables")
+	}
+	return userDBs, userTables, nil
+}
+
+// restoreSystemTable copies the contents of the temporary copy of the named
+// system table into the real one. Rows that already exist are overwritten,
+// except for the jobs table, of which only jobs that are no longer running
+// are copied and existing jobs take precedence. Descriptor IDs stored in the
+// zones and comments tables are rewritten to the restored IDs.
+func restoreSystemTable(
+	ctx context.Context,
+	ie *sql.InternalExecutor,
+	txn *client.Txn,
+	name string,
+	idRewrites map[sqlbase.ID]sqlbase.ID,
+) error {
+	opName := "restore-system-" + name
+	switch name {
+	case sqlbase.ZonesTable.Name:
+		rows, _, err := ie.Query(ctx, opName, txn,
+			fmt.Sprintf(`SELECT id, config FROM %s.zones`, restoreTempSystemDB))
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			id, ok := rewriteDescID(sqlbase.ID(tree.MustBeDInt(row[0])), idRewrites)
+			if !ok {
+				continue
+			}
+			if _, err := ie.Exec(ctx, opName, txn,
+				`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`, id, row[1],
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case sqlbase.CommentsTable.Name:
+		rows, _, err := ie.Query(ctx, opName, txn,
+			fmt.Sprintf(`SELECT type, object_id, sub_id, comment FROM %s.comments`, restoreTempSystemDB))
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			id, ok := rewriteDescID(sqlbase.ID(tree.MustBeDInt(row[1])), idRewrites)
+			if !ok {
+				continue
+			}
+			if _, err := ie.Exec(ctx, opName, txn,
+				`UPSERT INTO system.comments (type, object_id, sub_id, comment) VALUES ($1, $2, $3, $4)`,
+				row[0], id, row[2], row[3],
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case sqlbase.SettingsTable.Name:
+		// The cluster version is managed by the restoring cluster itself.
+		_, err := ie.Exec(ctx, opName, txn, fmt.Sprintf(
+			`UPSERT INTO system.settings SELECT * FROM %s.settings WHERE name != 'version'`,
+			restoreTempSystemDB))
+		return err
+
+	case sqlbase.JobsTable.Name:
+		// Jobs that were running when the backup was taken cannot be resumed,
+		// so only the history of finished jobs is restored.
+		_, err := ie.Exec(ctx, opName, txn, fmt.Sprintf(
+			`INSERT INTO system.jobs SELECT * FROM %s.jobs WHERE status IN ($1, $2, $3) ON CONFLICT (id) DO NOTHING`,
+			restoreTempSystemDB),
+			jobs.StatusSucceeded, jobs.StatusFailed, jobs.StatusCanceled)
+		return err
+
+	default:
+		_, err := ie.Exec(ctx, opName, txn, fmt.Sprintf(
+			`UPSERT INTO system.%s SELECT * FROM %s.%s`, name, restoreTempSystemDB, name))
+		return err
+	}
+}
@@ -19,10 +19,18 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
+const showBackupOptCheckFiles = "check_files"
+
+var showBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
+	backupOptEncPassphrase:  sql.KVStringOptRequireValue,
+	showBackupOptCheckFiles: sql.KVStringOptRequireNoValue,
+}
+
 // showBackupPlanHook implements PlanHookFn.
 func showBackupPlanHook(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
@@ -42,7 +50,15 @@ func showBackupPlanHook(
 		return nil, nil, nil, err
 	}
 
-	toFn, err := p.TypeAsString(backup.Path, "SHOW BACKUP")
+	fromFns := make([]func() ([]string, error), len(backup.Paths))
+	for i := range backup.Paths {
+		fromFn, err := p.TypeAsStringArray(tree.Exprs(backup.Paths[i]), "SHOW BACKUP")
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		fromFns[i] = fromFn
+	}
+	optsFn, err := p.TypeAsStringOpts(backup.Options, showBackupOptionExpectValues)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -53,40 +69,101 @@ func showBackupPlanHook(
 		shower = backupShowerRanges
 	case tree.BackupFileDetails:
 		shower = backupShowerFiles
+	case tree.BackupRevisionDetails:
+		shower = backupShowerRevisions
 	default:
 		shower = backupShowerDefault
 	}
+	// With check_files, only the problems found in the backup are shown.
+	header := shower.header
+	for _, opt := range backup.Options {
+		if string(opt.Key) == showBackupOptCheckFiles {
+			header = backupVerifyHeader
+		}
+	}
 
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
 		defer tracing.FinishSpan(span)
 
-		str, err := toFn()
+		// Each layer of the chain may have been written to several
+		// locality-specific URIs, with its backup descriptor at the default one.
+		uris := make([]string, len(fromFns))
+		urisByLocalityKV := make([]map[string]string, len(fromFns))
+		for i, fromFn := range fromFns {
+			from, err := fromFn()
+			if err != nil {
+				return err
+			}
+			uris[i], urisByLocalityKV[i], err = getURIsByLocalityKV(from)
+			if err != nil {
+				return err
+			}
+		}
+		opts, err := optsFn()
 		if err != nil {
 			return err
 		}
-		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().Settings)
+		var encryption *roachpb.FileEncryptionOptions
+		if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+			// Every backup in the chain shares the salt of the first, full, backup.
+			encryption, err = EncryptionFromPassphrase(ctx, uris[0], p.ExecCfg().Settings, passphrase)
+			if err != nil {
+				return err
+			}
+		}
+		descs, err := loadBackupDescs(ctx, uris, p.ExecCfg().Settings, encryption)
 		if err != nil {
 			return err
 		}
 
-		for _, row := range shower.fn(desc) {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case resultsCh <- row:
+		if _, ok := opts[showBackupOptCheckFiles]; ok {
+			for i, desc := range descs {
+				problems, err := verifyBackup(ctx, p.ExecCfg().Settings, desc, urisByLocalityKV[i], encryption)
+				if err != nil {
+					return err
+				}
+				if err := sendBackupProblems(ctx, resultsCh, uris[i], problems); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		for i, desc := range descs {
+			rows, err := shower.fn(ctx, desc, i)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case resultsCh <- row:
+				}
 			}
 		}
 		return nil
 	}
 
-	return fn, shower.header, nil, nil
+	return fn, header, nil, nil
 }
 
+// backupShower produces the rows of one mode of SHOW BACKUP. fn is called
+// once for each backup in the chain of locations given, with its position in
+// the chain, the full backup being layer 0.
 type backupShower struct {
 	header sqlbase.ResultColumns
-	fn     func(BackupDescriptor) []tree.Datums
+	fn     func(ctx context.Context, desc BackupDescriptor, layer int) ([]tree.Datums, error)
+}
+
+// timestampDatum returns ts as a TIMESTAMP, or NULL if it is empty.
+func timestampDatum(ts hlc.Timestamp) tree.Datum {
+	if ts.WallTime == 0 {
+		return tree.DNull
+	}
+	return tree.MakeDTimestamp(timeutil.Unix(0, ts.WallTime), time.Nanosecond)
 }
 
 var backupShowerDefault = backupShower{
@@ -97,9 +174,10 @@ var backupShowerDefault = backupShower{
 		{Name: "end_time", Typ: types.Timestamp},
 		{Name: "size_bytes", Typ: types.Int},
 		{Name: "rows", Typ: types.Int},
+		{Name: "layer", Typ: types.Int},
 	},
 
-	fn: func(desc BackupDescriptor) []tree.Datums {
+	fn: func(_ context.Context, desc BackupDescriptor, layer int) ([]tree.Datums, error) {
 		descs := make(map[sqlbase.ID]string)
 		for _, descriptor := range desc.Descriptors {
 			if database := descriptor.GetDatabase(); database != nil {
@@ -123,10 +201,7 @@ var backupShowerDefault = backupShower{
 			s.Add(file.EntryCounts)
 			descSizes[sqlbase.ID(tableID)] = s
 		}
-		start := tree.DNull
-		if desc.StartTime.WallTime != 0 {
-			start = tree.MakeDTimestamp(timeutil.Unix(0, desc.StartTime.WallTime), time.Nanosecond)
-		}
+		start := timestampDatum(desc.StartTime)
 		var rows []tree.Datums
 		for _, descriptor := range desc.Descriptors {
 			if table := descriptor.GetTable(); table != nil {
@@ -138,10 +213,11 @@ var backupShowerDefault = backupShower{
 					tree.MakeDTimestamp(timeutil.Unix(0, desc.EndTime.WallTime), time.Nanosecond),
 					tree.NewDInt(tree.DInt(descSizes[table.ID].DataSize)),
 					tree.NewDInt(tree.DInt(descSizes[table.ID].Rows)),
+					tree.NewDInt(tree.DInt(layer)),
 				})
 			}
 		}
-		return rows
+		return rows, nil
 	},
 }
 
@@ -153,7 +229,7 @@ var backupShowerRanges = backupShower{
 		{Name: "end_key", Typ: types.Bytes},
 	},
 
-	fn: func(desc BackupDescriptor) (rows []tree.Datums) {
+	fn: func(_ context.Context, desc BackupDescriptor, _ int) (rows []tree.Datums, _ error) {
 		for _, span := range desc.Spans {
 			rows = append(rows, tree.Datums{
 				tree.NewDString(span.Key.String()),
@@ -162,7 +238,7 @@ var backupShowerRanges = backupShower{
 				tree.NewDBytes(tree.DBytes(span.EndKey)),
 			})
 		}
-		return rows
+		return rows, nil
 	},
 }
 
@@ -175,10 +251,26 @@ var backupShowerFiles = backupShower{
 		{Name: "end_key", Typ: types.Bytes},
 		{Name: "size_bytes", Typ: types.Int},
 		{Name: "rows", Typ: types.Int},
+		{Name: "start_time", Typ: types.Timestamp},
+		{Name: "end_time", Typ: types.Timestamp},
+		{Name: "revision_history", Typ: types.Bool},
+		{Name: "layer", Typ: types.Int},
 	},
 
-	fn: func(desc BackupDescriptor) (rows []tree.Datums) {
+	fn: func(_ context.Context, desc BackupDescriptor, layer int) (rows []tree.Datums, _ error) {
+		revisionHistory := desc.MVCCFilter == MVCCFilter_All
 		for _, file := range desc.Files {
+			// Files of spans introduced by an incremental backup cover their own,
+			// earlier, time interval.
+			start, end := desc.StartTime, desc.EndTime
+			if !file.EndTime.IsEmpty() {
+				start, end = file.StartTime, file.EndTime
+			}
+			// Revisions older than RevisionStartTime were not captured, so the
+			// data of a file is only restorable as of times after it.
+			if revisionHistory && start.Less(desc.RevisionStartTime) {
+				start = desc.RevisionStartTime
+			}
 			rows = append(rows, tree.Datums{
 				tree.NewDString(file.Path),
 				tree.NewDString(file.Span.Key.String()),
@@ -187,12 +279,114 @@ var backupShowerFiles = backupShower{
 				tree.NewDBytes(tree.DBytes(file.Span.EndKey)),
 				tree.NewDInt(tree.DInt(file.EntryCounts.DataSize)),
 				tree.NewDInt(tree.DInt(file.EntryCounts.Rows)),
+				timestampDatum(start),
+				timestampDatum(end),
+				tree.MakeDBool(tree.DBool(revisionHistory)),
+				tree.NewDInt(tree.DInt(layer)),
 			})
 		}
-		return rows
+		return rows, nil
 	},
 }
 
+var backupShowerRevisions = backupShower{
+	header: sqlbase.ResultColumns{
+		{Name: "database_name", Typ: types.String},
+		{Name: "table_name", Typ: types.String},
+		{Name: "revision_time", Typ: types.Timestamp},
+		{Name: "dropped", Typ: types.Bool},
+		{Name: "create_statement", Typ: types.String},
+	},
+
+	fn: func(ctx context.Context, desc BackupDescriptor, _ int) ([]tree.Datums, error) {
+		// A backup without revision history has only the descriptors as of its
+		// end time.
+		revisions := desc.DescriptorChanges
+		if len(revisions) == 0 {
+			for i := range desc.Descriptors {
+				d := &desc.Descriptors[i]
+				revisions = append(revisions, BackupDescriptor_DescriptorRevision{
+					Time: desc.EndTime, ID: d.GetID(), Desc: d,
+				})
+			}
+		}
+
+		dbNames := make(map[sqlbase.ID]string)
+		for _, d := range desc.Descriptors {
+			if database := d.GetDatabase(); database != nil {
+				dbNames[database.ID] = database.Name
+			}
+		}
+		tableNames := make(map[sqlbase.ID]tableNameRevision)
+		for _, rev := range revisions {
+			if rev.Desc == nil {
+				continue
+			}
+			if database := rev.Desc.GetDatabase(); database != nil {
+				dbNames[database.ID] = database.Name
+			} else if table := rev.Desc.GetTable(); table != nil {
+				tableNames[table.ID] = tableNameRevision{parentID: table.ParentID, name: table.Name}
+			}
+		}
+
+		var rows []tree.Datums
+		for _, rev := range revisions {
+			var table *sqlbase.TableDescriptor
+			if rev.Desc != nil {
+				if table = rev.Desc.GetTable(); table == nil {
+					continue
+				}
+			}
+			// A revision without a descriptor records that the table was dropped
+			// and removed, so its name is that of its latest revision.
+			tn, ok := tableNames[rev.ID]
+			if table != nil {
+				tn = tableNameRevision{parentID: table.ParentID, name: table.Name}
+			} else if !ok {
+				continue
+			}
+			dropped := table == nil || table.Dropped()
+			createStmt := tree.DNull
+			if !dropped {
+				stmt, err := backupCreateStatement(ctx, table)
+				if err != nil {
+					return nil, err
+				}
+				createStmt = tree.NewDString(stmt)
+			}
+			rows = append(rows, tree.Datums{
+				tree.NewDString(dbNames[tn.parentID]),
+				tree.NewDString(tn.name),
+				timestampDatum(rev.Time),
+				tree.MakeDBool(tree.DBool(dropped)),
+				createStmt,
+			})
+		}
+		return rows, nil
+	},
+}
+
+// tableNameRevision is the name of a table as of one of its revisions.
+type tableNameRevision struct {
+	parentID sqlbase.ID
+	name     string
+}
+
+// backupCreateStatement returns the CREATE statement of the table, view or
+// sequence described by desc. Tables referenced by it are not in general in
+// the backup, so foreign keys name them by ID.
+func backupCreateStatement(ctx context.Context, desc *sqlbase.TableDescriptor) (string, error) {
+	name := tree.Name(desc.Name)
+	switch {
+	case desc.IsView():
+		return sql.ShowCreateView(ctx, &name, desc)
+	case desc.IsSequence():
+		return sql.ShowCreateSequence(ctx, &name, desc)
+	default:
+		return sql.ShowCreateTable(ctx, &name, "" /* dbPrefix */, desc, nil /* lCtx */, false /* ignoreFKs */)
+	}
+}
+
 func init() {
 	sql.AddPlanHook(showBackupPlanHook)
 }
@@ -11,6 +11,10 @@ package backupccl_test
 import (
 	"database/sql/driver"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
@@ -37,7 +41,7 @@ func TestShowBackup(t *testing.T) {
 	var start, end *time.Time
 	var dataSize, rows uint64
 	sqlDB.QueryRow(t, `SELECT * FROM [SHOW BACKUP $1] WHERE table_name = 'bank'`, full).Scan(
-		&unused, &unused, &start, &end, &dataSize, &rows,
+		&unused, &unused, &start, &end, &dataSize, &rows, &unused,
 	)
 	if start != nil {
 		t.Errorf("expected null start time on full backup, got %v", *start)
@@ -66,7 +70,7 @@ func TestShowBackup(t *testing.T) {
 	sqlDB.Exec(t, `BACKUP data.bank TO $1 INCREMENTAL FROM $2`, inc, full)
 
 	sqlDB.QueryRow(t, `SELECT * FROM [SHOW BACKUP $1] WHERE table_name = 'bank'`, inc).Scan(
-		&unused, &unused, &start, &end, &dataSize, &rows,
+		&unused, &unused, &start, &end, &dataSize, &rows, &unused,
 	)
 	if start == nil {
 		t.Errorf("expected start time on inc backup, got %v", *start)
@@ -117,3 +121,85 @@ func TestShowBackup(t *testing.T) {
 		t.Fatalf("expected 2 files, but got %d", len(pathRows))
 	}
 }
+
+func TestShowBackupCheckFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 100
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFoo)
+	checkFiles := fmt.Sprintf(`SHOW BACKUP '%s' WITH check_files`, localFoo)
+	sqlDB.CheckQueryResults(t, checkFiles, [][]string{})
+
+	paths := sqlDB.QueryStr(t, `SELECT path FROM [SHOW BACKUP FILES $1] ORDER BY path`, localFoo)
+	if len(paths) < 2 {
+		t.Fatalf("expected at least 2 files, got %d", len(paths))
+	}
+	corrupted, removed := paths[0][0], paths[1][0]
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "foo", corrupted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents[len(contents)/2]++
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo", corrupted), contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "foo", removed)); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB.CheckQueryResults(t,
+		fmt.Sprintf(`SELECT backup, path, problem ~ 'checksum mismatch|reading file'
+			FROM [%s] ORDER BY path`, checkFiles),
+		[][]string{
+			{localFoo, corrupted, "true"},
+			{localFoo, removed, "true"},
+		},
+	)
+}
+
+func TestShowBackupRevisions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, 0, initNone)
+	defer cleanupFn()
+
+	full, inc := localFoo+"/full", localFoo+"/inc"
+
+	sqlDB.Exec(t, `CREATE TABLE data.revs (a INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO data.revs VALUES (1), (2)`)
+	sqlDB.Exec(t, `BACKUP data.revs TO $1 WITH revision_history`, full)
+	sqlDB.Exec(t, `ALTER TABLE data.revs ADD COLUMN b INT`)
+	sqlDB.Exec(t, `INSERT INTO data.revs VALUES (3, 3)`)
+	sqlDB.Exec(t, `BACKUP data.revs TO $1 INCREMENTAL FROM $2 WITH revision_history`, inc, full)
+
+	// The latest revision in each layer has the schema as of its end time.
+	latest := `SELECT database_name, dropped, create_statement LIKE '%%b INT%%'
+		FROM [SHOW BACKUP REVISIONS '%s'] WHERE table_name = 'revs'
+		ORDER BY revision_time DESC LIMIT 1`
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(latest, full), [][]string{{"data", "false", "false"}})
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(latest, inc), [][]string{{"data", "false", "true"}})
+
+	// Each layer of the chain covers the time interval following the one
+	// before it.
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT count(DISTINCT end_time)
+		FROM [SHOW BACKUP '%s', '%s'] WHERE table_name = 'revs'`, full, inc),
+		[][]string{{"2"}},
+	)
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT DISTINCT revision_history
+		FROM [SHOW BACKUP FILES '%s', '%s']`, full, inc),
+		[][]string{{"true"}},
+	)
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT DISTINCT layer
+		FROM [SHOW BACKUP FILES '%s', '%s'] ORDER BY layer`, full, inc),
+		[][]string{{"0"}, {"1"}},
+	)
+	fullEnd := sqlDB.QueryStr(t, `SELECT DISTINCT end_time FROM [SHOW BACKUP FILES $1]`, full)
+	incStart := sqlDB.QueryStr(t, `SELECT DISTINCT start_time FROM [SHOW BACKUP FILES $1]`, inc)
+	if !reflect.DeepEqual(fullEnd, incStart) {
+		t.Fatalf("expected incremental files to start at %v, got %v", fullEnd, incStart)
+	}
+}
@@ -11,6 +11,7 @@ package backupccl
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -265,3 +266,49 @@ func descriptorsMatchingTargets(
 
 	return ret, nil
 }
+
+// fullClusterSystemTables are the system tables whose contents are captured
+// by a full cluster BACKUP and restored by a full cluster RESTORE.
+var fullClusterSystemTables = []string{
+	sqlbase.UsersTable.Name,
+	sqlbase.RoleMembersTable.Name,
+	sqlbase.ZonesTable.Name,
+	sqlbase.SettingsTable.Name,
+	sqlbase.CommentsTable.Name,
+	sqlbase.JobsTable.Name,
+}
+
+// fullClusterTargets returns the descriptors covered by a full cluster BACKUP:
+// every database and every table that is not dropped, except that only the
+// system tables in fullClusterSystemTables are included. The databases other
+// than system, all of whose tables are included, are also returned as the
+// expanded DBs.
+func fullClusterTargets(descriptors []sqlbase.Descriptor) ([]sqlbase.Descriptor, []sqlbase.ID) {
+	systemTables := make(map[string]struct{}, len(fullClusterSystemTables))
+	for _, name := range fullClusterSystemTables {
+		systemTables[name] = struct{}{}
+	}
+
+	var descs []sqlbase.Descriptor
+	var expandedDBs []sqlbase.ID
+	for _, desc := range descriptors {
+		if dbDesc := desc.GetDatabase(); dbDesc != nil {
+			descs = append(descs, desc)
+			if dbDesc.ID != keys.SystemDatabaseID {
+				expandedDBs = append(expandedDBs, dbDesc.ID)
+			}
+		}
+		if tableDesc := desc.GetTable(); tableDesc != nil {
+			if tableDesc.Dropped() {
+				continue
+			}
+			if tableDesc.ParentID == keys.SystemDatabaseID {
+				if _, ok := systemTables[tableDesc.Name]; !ok {
+					continue
+				}
+			}
+			descs = append(descs, desc)
+		}
+	}
+	return descs, expandedDBs
+}
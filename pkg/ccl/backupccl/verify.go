@@ -0,0 +1,320 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl/intervalccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/interval"
+	"github.com/pkg/errors"
+)
+
+// verifyKeySampleInterval is the number of keys read from a backup file for
+// each one that is decoded against the backup's table descriptors.
+const verifyKeySampleInterval = 1000
+
+// backupVerifyHeader is the header of the results of RESTORE with verify_only
+// and of SHOW BACKUP with check_files, which have a row for each problem found.
+var backupVerifyHeader = sqlbase.ResultColumns{
+	{Name: "backup", Typ: types.String},
+	{Name: "path", Typ: types.String},
+	{Name: "problem", Typ: types.String},
+}
+
+// backupProblem is an inconsistency found while verifying a backup.
+type backupProblem struct {
+	// path is the file with the problem, or empty if the problem is not with any
+	// one file.
+	path    string
+	problem string
+}
+
+// sendBackupProblems sends a row to resultsCh for each of the problems found
+// in the backup at uri, or in no one backup if uri is empty.
+func sendBackupProblems(
+	ctx context.Context, resultsCh chan<- tree.Datums, uri string, problems []backupProblem,
+) error {
+	backup := tree.DNull
+	if uri != "" {
+		sanitized, err := storageccl.SanitizeExportStorageURI(uri)
+		if err != nil {
+			return err
+		}
+		backup = tree.NewDString(sanitized)
+	}
+	for _, p := range problems {
+		path := tree.DNull
+		if p.path != "" {
+			path = tree.NewDString(p.path)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultsCh <- tree.Datums{backup, path, tree.NewDString(p.problem)}:
+		}
+	}
+	return nil
+}
+
+// verifyBackup checks the backup described by desc without writing anything:
+// that its spans cover every index of its tables, that its files lie within
+// those spans without overlapping, and that every file can be read and holds
+// what the descriptor says it does. Files written to a locality are read from
+// its URI in urisByLocalityKV. The problems found are returned; an error means
+// the backup could not be checked at all.
+func verifyBackup(
+	ctx context.Context,
+	settings *cluster.Settings,
+	desc BackupDescriptor,
+	urisByLocalityKV map[string]string,
+	encryption *roachpb.FileEncryptionOptions,
+) ([]backupProblem, error) {
+	problems := verifyBackupSpans(desc)
+
+	tables := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	for _, d := range desc.Descriptors {
+		if table := d.GetTable(); table != nil {
+			tables[table.ID] = table
+		}
+	}
+	kr, err := storageccl.MakeKeyRewriter(tables)
+	if err != nil {
+		return nil, err
+	}
+
+	stores := make(map[string]storageccl.ExportStorage)
+	defer func() {
+		for _, store := range stores {
+			store.Close()
+		}
+	}()
+	for _, f := range desc.Files {
+		store, ok := stores[f.LocalityKV]
+		if !ok {
+			conf := desc.Dir
+			if f.LocalityKV != "" {
+				uri, ok := urisByLocalityKV[f.LocalityKV]
+				if !ok {
+					problems = append(problems, backupProblem{
+						path:    f.Path,
+						problem: fmt.Sprintf("written to locality %s, for which no URI was given", f.LocalityKV),
+					})
+					continue
+				}
+				if conf, err = storageccl.ExportStorageConfFromURI(uri); err != nil {
+					return nil, err
+				}
+			}
+			if store, err = storageccl.MakeExportStorage(ctx, conf, settings); err != nil {
+				return nil, err
+			}
+			stores[f.LocalityKV] = store
+		}
+		if err := verifyBackupFile(ctx, store, &desc, f, encryption, kr); err != nil {
+			problems = append(problems, backupProblem{path: f.Path, problem: err.Error()})
+		}
+	}
+	return problems, nil
+}
+
+// verifyBackupSpans checks that the spans of desc cover every index of the
+// tables in it and that its files lie within those spans without overlapping.
+func verifyBackupSpans(desc BackupDescriptor) []backupProblem {
+	var problems []backupProblem
+
+	backupSpans := interval.NewRangeTree()
+	for _, s := range desc.Spans {
+		backupSpans.Add(s.AsRange())
+	}
+	var tables []*sqlbase.TableDescriptor
+	for _, d := range desc.Descriptors {
+		if table := d.GetTable(); table != nil {
+			tables = append(tables, table)
+		}
+	}
+	for _, s := range spansForAllTableIndexes(tables, nil) {
+		if !backupSpans.Encloses(s.AsRange()) {
+			problems = append(problems, backupProblem{
+				problem: fmt.Sprintf("span %s of the backed up tables is not covered by the backup", s),
+			})
+		}
+	}
+
+	// Files exported for the same time interval must not overlap. Those for
+	// spans introduced by an incremental backup cover an earlier interval than
+	// the rest, so they are checked separately.
+	type timeInterval struct{ start, end hlc.Timestamp }
+	filesByInterval := make(map[timeInterval]BackupFileDescriptors)
+	for _, f := range desc.Files {
+		if !backupSpans.Encloses(f.Span.AsRange()) {
+			problems = append(problems, backupProblem{
+				path:    f.Path,
+				problem: fmt.Sprintf("span %s is not within the spans of the backup", f.Span),
+			})
+		}
+		ti := timeInterval{start: desc.StartTime, end: desc.EndTime}
+		if !f.EndTime.IsEmpty() {
+			ti = timeInterval{start: f.StartTime, end: f.EndTime}
+		}
+		filesByInterval[ti] = append(filesByInterval[ti], f)
+	}
+	for _, files := range filesByInterval {
+		sort.Sort(files)
+		for i := 1; i < len(files); i++ {
+			if files[i].Span.Key.Compare(files[i-1].Span.EndKey) < 0 {
+				problems = append(problems, backupProblem{
+					path:    files[i].Path,
+					problem: fmt.Sprintf("span %s overlaps %s of %s", files[i].Span, files[i-1].Span, files[i-1].Path),
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// verifyBackupFile reads the file f of the backup desc from store and returns
+// an error describing the first problem found with it: that it does not match
+// its checksum, or has a key or value that is corrupt or outside of its span or
+// time interval. Every verifyKeySampleInterval-th key is also decoded with kr,
+// which must know the tables of the backup.
+func verifyBackupFile(
+	ctx context.Context,
+	store storageccl.ExportStorage,
+	desc *BackupDescriptor,
+	f BackupDescriptor_File,
+	encryption *roachpb.FileEncryptionOptions,
+	kr *storageccl.KeyRewriter,
+) error {
+	r, err := store.ReadFile(ctx, f.Path)
+	if err != nil {
+		return errors.Wrap(err, "reading file")
+	}
+	contents, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return errors.Wrap(err, "reading file")
+	}
+	if encryption != nil {
+		if contents, err = storageccl.DecryptFile(contents, encryption.Key); err != nil {
+			return errors.Wrap(err, "decrypting file")
+		}
+	}
+	if len(f.Sha512) > 0 {
+		checksum, err := storageccl.SHA512ChecksumData(contents)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(checksum, f.Sha512) {
+			return errors.New("checksum mismatch")
+		}
+	}
+
+	start, end := desc.StartTime, desc.EndTime
+	if !f.EndTime.IsEmpty() {
+		start, end = f.StartTime, f.EndTime
+	}
+
+	iter, err := engine.NewMemSSTIterator(contents, true /* verify */)
+	if err != nil {
+		return errors.Wrap(err, "opening file")
+	}
+	defer iter.Close()
+	var keyScratch []byte
+	var i int
+	for iter.Seek(engine.NilKey); ; iter.Next() {
+		ok, err := iter.Valid()
+		if err != nil {
+			return errors.Wrap(err, "reading key")
+		}
+		if !ok {
+			return nil
+		}
+		key := iter.UnsafeKey()
+		if !f.Span.ContainsKey(key.Key) {
+			return errors.Errorf("key %s is outside of span %s", key.Key, f.Span)
+		}
+		if end.Less(key.Timestamp) || (!start.IsEmpty() && !start.Less(key.Timestamp)) {
+			return errors.Errorf("key %s is outside of time interval (%s,%s]", key, start, end)
+		}
+		sample := i%verifyKeySampleInterval == 0
+		i++
+		if !sample {
+			continue
+		}
+		keyScratch = append(keyScratch[:0], key.Key...)
+		if _, ok, err := kr.RewriteKey(keyScratch); err != nil {
+			return errors.Wrapf(err, "decoding key %s", key.Key)
+		} else if !ok && desc.MVCCFilter == MVCCFilter_Latest {
+			// With revision history, keys may belong to indexes that have since
+			// been dropped and so are unknown to kr.
+			return errors.Errorf("key %s is not in any index of the backed up tables", key.Key)
+		}
+	}
+}
+
+// verifyRestore checks, without writing anything, each of the backups that a
+// RESTORE would read from at uris and that together they cover the spans of
+// the tables in sqlDescs, sending a row to resultsCh for each problem found.
+func verifyRestore(
+	ctx context.Context,
+	settings *cluster.Settings,
+	uris []string,
+	backupLocalityInfo []jobspb.RestoreDetails_BackupLocalityInfo,
+	backupDescs []BackupDescriptor,
+	sqlDescs []sqlbase.Descriptor,
+	encryption *roachpb.FileEncryptionOptions,
+	resultsCh chan<- tree.Datums,
+) error {
+	for i, desc := range backupDescs {
+		var urisByLocalityKV map[string]string
+		if i < len(backupLocalityInfo) {
+			urisByLocalityKV = backupLocalityInfo[i].URIsByLocalityKV
+		}
+		problems, err := verifyBackup(ctx, settings, desc, urisByLocalityKV, encryption)
+		if err != nil {
+			return err
+		}
+		if err := sendBackupProblems(ctx, resultsCh, uris[i], problems); err != nil {
+			return err
+		}
+	}
+
+	var tables []*sqlbase.TableDescriptor
+	for _, desc := range sqlDescs {
+		if table := desc.GetTable(); table != nil {
+			tables = append(tables, table)
+		}
+	}
+	var problems []backupProblem
+	if _, _, err := makeImportSpans(
+		spansForAllTableIndexes(tables, nil), backupDescs, nil, keys.MinKey,
+		func(span intervalccl.Range, start, end hlc.Timestamp) error {
+			problems = append(problems, backupProblem{problem: errOnMissingRange(span, start, end).Error()})
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	return sendBackupProblems(ctx, resultsCh, "", problems)
+}
@@ -79,6 +79,27 @@ type avroSchemaRecord struct {
 	alloc            sqlbase.DatumAlloc
 }
 
+// avroEnvelopeField is our representation of the schema of a field in an
+// avroEnvelopeRecord. Serializing it to JSON gives the standard schema
+// representation.
+type avroEnvelopeField struct {
+	SchemaType []avroSchemaType `json:"type"`
+	Name       string           `json:"name"`
+}
+
+// avroEnvelopeRecord is our representation of the schema of the avro record
+// used for values with envelope=diff. It has a `before` and an `after` field,
+// each of which is either null or a record of the row. Serializing it to JSON
+// gives the standard schema representation.
+type avroEnvelopeRecord struct {
+	SchemaType string              `json:"type"`
+	Name       string              `json:"name"`
+	Fields     []avroEnvelopeField `json:"fields"`
+
+	row   *avroSchemaRecord
+	codec *goavro.Codec
+}
+
 // columnDescToAvroSchema converts a column descriptor into its corresponding
 // avro field schema.
 func columnDescToAvroSchema(colDesc *sqlbase.ColumnDescriptor) (*avroSchemaField, error) {
@@ -272,6 +293,94 @@ func tableToAvroSchema(tableDesc *sqlbase.TableDescriptor) (*avroSchemaRecord, e
 	return schema, nil
 }
 
+// envelopeToAvroSchema wraps the given row record schema in the record schema
+// used for values with envelope=diff.
+func envelopeToAvroSchema(row *avroSchemaRecord) (*avroEnvelopeRecord, error) {
+	schema := &avroEnvelopeRecord{
+		Name:       row.Name + `_envelope`,
+		SchemaType: `record`,
+		Fields: []avroEnvelopeField{
+			// The row record is defined in the first field and referenced by
+			// name in the second.
+			{Name: `before`, SchemaType: []avroSchemaType{avroSchemaNull, row}},
+			{Name: `after`, SchemaType: []avroSchemaType{avroSchemaNull, row.Name}},
+		},
+		row: row,
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	schema.codec, err = goavro.NewCodec(string(schemaJSON))
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// BinaryFromRows encodes the given previous and new row data into avro's
+// defined binary format. Either row may be nil.
+func (r *avroEnvelopeRecord) BinaryFromRows(
+	buf []byte, before, after sqlbase.EncDatumRow,
+) ([]byte, error) {
+	native := make(map[string]interface{}, 2)
+	var err error
+	if native[`before`], err = r.unionFromRow(before); err != nil {
+		return nil, err
+	}
+	if native[`after`], err = r.unionFromRow(after); err != nil {
+		return nil, err
+	}
+	return r.codec.BinaryFromNative(buf, native)
+}
+
+// RowsFromBinary decodes the given previous and new row data from avro's
+// defined binary format. Either returned row may be nil.
+func (r *avroEnvelopeRecord) RowsFromBinary(
+	buf []byte,
+) (before, after sqlbase.EncDatumRow, err error) {
+	native, newBuf, err := r.codec.NativeFromBinary(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(newBuf) > 0 {
+		return nil, nil, errors.New(`only one envelope was expected`)
+	}
+	fields, ok := native.(map[string]interface{})
+	if !ok {
+		return nil, nil, errors.Errorf(`unknown avro native type: %T`, native)
+	}
+	if before, err = r.rowFromUnion(fields[`before`]); err != nil {
+		return nil, nil, err
+	}
+	if after, err = r.rowFromUnion(fields[`after`]); err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+func (r *avroEnvelopeRecord) unionFromRow(row sqlbase.EncDatumRow) (interface{}, error) {
+	if row == nil {
+		return goavro.Union(avroSchemaNull, nil), nil
+	}
+	native, err := r.row.nativeFromRow(row)
+	if err != nil {
+		return nil, err
+	}
+	return goavro.Union(r.row.Name, native), nil
+}
+
+func (r *avroEnvelopeRecord) rowFromUnion(native interface{}) (sqlbase.EncDatumRow, error) {
+	if native == nil {
+		return nil, nil
+	}
+	union, ok := native.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf(`unknown avro native type: %T`, native)
+	}
+	return r.row.rowFromNative(union[r.row.Name])
+}
+
 // textualFromRow encodes the given row data into avro's defined JSON format.
 func (r *avroSchemaRecord) textualFromRow(row sqlbase.EncDatumRow) ([]byte, error) {
 	native, err := r.nativeFromRow(row)
@@ -393,3 +502,60 @@ func ratToDecimal(rat big.Rat, scale int32) apd.Decimal {
 	dec := apd.NewWithBigInt(coeff, -scale)
 	return *dec
 }
+
+// avroSchemaChangeSchema is the schema of the avro records emitted to the
+// schema topic. Each one describes a schemaChangeEvent. The updated timestamp
+// is formatted as in resolved timestamps.
+const avroSchemaChangeSchema = `{
+	"type": "record",
+	"name": "schema_change",
+	"fields": [
+		{"name": "table", "type": "string"},
+		{"name": "table_id", "type": "long"},
+		{"name": "version", "type": "long"},
+		{"name": "updated", "type": "string"},
+		{"name": "columns_added", "type": {"type": "array", "items": {
+			"type": "record",
+			"name": "schema_change_column",
+			"fields": [
+				{"name": "name", "type": "string"},
+				{"name": "type", "type": "string"},
+				{"name": "previous_type", "type": ["null", "string"]}
+			]
+		}}},
+		{"name": "columns_dropped", "type": {"type": "array", "items": "schema_change_column"}},
+		{"name": "columns_altered", "type": {"type": "array", "items": "schema_change_column"}}
+	]
+}`
+
+// avroSchemaChangeName is the name of the avroSchemaChangeSchema record.
+const avroSchemaChangeName = `schema_change`
+
+// nativeFromSchemaChange converts a schema change event to the goavro native
+// form of an avroSchemaChangeSchema record.
+func nativeFromSchemaChange(e schemaChangeEvent) map[string]interface{} {
+	columns := func(cols []schemaChangeColumn) []interface{} {
+		native := make([]interface{}, len(cols))
+		for i, col := range cols {
+			prevTyp := goavro.Union(avroSchemaNull, nil)
+			if col.prevTyp != `` {
+				prevTyp = goavro.Union(avroSchemaString, col.prevTyp)
+			}
+			native[i] = map[string]interface{}{
+				`name`:          col.name,
+				`type`:          col.typ,
+				`previous_type`: prevTyp,
+			}
+		}
+		return native
+	}
+	return map[string]interface{}{
+		`table`:           e.tableDesc.Name,
+		`table_id`:        int64(e.tableDesc.ID),
+		`version`:         int64(e.tableDesc.Version),
+		`updated`:         tree.TimestampToDecimal(e.updated).Decimal.String(),
+		`columns_added`:   columns(e.added),
+		`columns_dropped`: columns(e.dropped),
+		`columns_altered`: columns(e.altered),
+	}
+}
@@ -292,6 +292,43 @@ func TestAvroSchema(t *testing.T) {
 	})
 }
 
+func TestAvroEnvelope(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tableDesc, err := parseTableDesc(`CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	require.NoError(t, err)
+	rowSchema, err := tableToAvroSchema(tableDesc)
+	require.NoError(t, err)
+	envelopeSchema, err := envelopeToAvroSchema(rowSchema)
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{"type":"record","name":"foo_envelope","fields":[`+
+			`{"type":["null",{"type":"record","name":"foo","fields":[`+
+			`{"type":"long","name":"a"},{"type":["null","string"],"name":"b"}]}],"name":"before"},`+
+			`{"type":["null","foo"],"name":"after"}]}`,
+		envelopeSchema.codec.Schema())
+
+	rows, err := parseValues(tableDesc, `VALUES (1, 'a'), (1, 'b')`)
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		name          string
+		before, after sqlbase.EncDatumRow
+	}{
+		{name: `insert`, after: rows[0]},
+		{name: `update`, before: rows[0], after: rows[1]},
+		{name: `delete`, before: rows[1]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			serialized, err := envelopeSchema.BinaryFromRows(nil, tc.before, tc.after)
+			require.NoError(t, err)
+			before, after, err := envelopeSchema.RowsFromBinary(serialized)
+			require.NoError(t, err)
+			require.Equal(t, tc.before, before)
+			require.Equal(t, tc.after, after)
+		})
+	}
+}
+
 func (f *avroSchemaField) defaultValueNative() (interface{}, bool) {
 	schemaType := f.SchemaType
 	if union, ok := schemaType.([]avroSchemaType); ok {
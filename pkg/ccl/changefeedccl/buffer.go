@@ -19,8 +19,14 @@ import (
 )
 
 type bufferEntry struct {
-	kv       roachpb.KeyValue
+	kv roachpb.KeyValue
+	// prevVal is the value of kv.Key just before kv was written. It is only
+	// populated for envelope=diff and is empty if the key had no value.
+	prevVal  roachpb.Value
 	resolved *jobspb.ResolvedSpan
+	// schemaChange, if non-nil, is a change to the columns of a watched table
+	// to be emitted to the sink's schema topic.
+	schemaChange *schemaChangeEvent
 	// Timestamp of the schema that should be used to read this KV.
 	// If unset (zero-valued), the value's timestamp will be used instead.
 	schemaTimestamp hlc.Timestamp
@@ -40,18 +46,33 @@ func makeBuffer() *buffer {
 	return &buffer{entriesCh: make(chan bufferEntry)}
 }
 
-// AddKV inserts a changed kv into the buffer.
+// AddKV inserts a changed kv into the buffer. prevVal is the value of the key
+// before the change, if it is known.
 //
 // TODO(dan): AddKV currently requires that each key is added in increasing mvcc
 // timestamp order. This will have to change when we add support for RangeFeed,
 // which starts out in a catchup state without this guarantee.
-func (b *buffer) AddKV(ctx context.Context, kv roachpb.KeyValue, minTimestamp hlc.Timestamp) error {
-	return b.addEntry(ctx, bufferEntry{kv: kv, schemaTimestamp: minTimestamp})
+func (b *buffer) AddKV(
+	ctx context.Context, kv roachpb.KeyValue, prevVal roachpb.Value, minTimestamp hlc.Timestamp,
+) error {
+	return b.addEntry(ctx, bufferEntry{kv: kv, prevVal: prevVal, schemaTimestamp: minTimestamp})
 }
 
-// AddResolved inserts a resolved timestamp notification in the buffer.
-func (b *buffer) AddResolved(ctx context.Context, span roachpb.Span, ts hlc.Timestamp) error {
-	return b.addEntry(ctx, bufferEntry{resolved: &jobspb.ResolvedSpan{Span: span, Timestamp: ts}})
+// AddResolved inserts a resolved timestamp notification in the buffer. If
+// schemaChangeBoundary is true, the changefeed stops at ts once every span has
+// been resolved up to it.
+func (b *buffer) AddResolved(
+	ctx context.Context, span roachpb.Span, ts hlc.Timestamp, schemaChangeBoundary bool,
+) error {
+	return b.addEntry(ctx, bufferEntry{resolved: &jobspb.ResolvedSpan{
+		Span: span, Timestamp: ts, SchemaChangeBoundary: schemaChangeBoundary,
+	}})
+}
+
+// AddSchemaChange inserts a schema change event in the buffer. It must be added
+// before any resolved timestamp at or above the event's timestamp.
+func (b *buffer) AddSchemaChange(ctx context.Context, e schemaChangeEvent) error {
+	return b.addEntry(ctx, bufferEntry{schemaChange: &e})
 }
 
 func (b *buffer) addEntry(ctx context.Context, e bufferEntry) error {
@@ -52,6 +52,17 @@ type emitRow struct {
 	// tableDesc is a TableDescriptor for the table containing `datums`.
 	// It's valid for interpreting the row at `timestamp`.
 	tableDesc *sqlbase.TableDescriptor
+	// prevDatums is the value of the table row before the change, decoded
+	// using `tableDesc`. It is only set for envelope=diff and is nil if the row
+	// did not exist before the change.
+	prevDatums sqlbase.EncDatumRow
+
+	// valueDesc, if non-nil, describes the output of the query of a `CREATE
+	// CHANGEFEED ... AS SELECT`. The value is then encoded from valueDatums and
+	// prevValueDatums, which are `datums` and `prevDatums` projected by the
+	// query, instead. The key is always encoded from `datums`.
+	valueDesc                    *sqlbase.TableDescriptor
+	valueDatums, prevValueDatums sqlbase.EncDatumRow
 }
 
 type emitEntry struct {
@@ -63,6 +74,10 @@ type emitEntry struct {
 	// timestamp will be emitted.
 	resolved *jobspb.ResolvedSpan
 
+	// schemaChange, if non-nil, is a change to the columns of a watched table
+	// to be emitted to the sink's schema topic.
+	schemaChange *schemaChangeEvent
+
 	// bufferGetTimestamp is the time this entry came out of the buffer.
 	bufferGetTimestamp time.Time
 }
@@ -79,8 +94,8 @@ func kvsToRows(
 
 	var kvs row.SpanKVFetcher
 	appendEmitEntryForKV := func(
-		ctx context.Context, output []emitEntry, kv roachpb.KeyValue, schemaTimestamp hlc.Timestamp,
-		bufferGetTimestamp time.Time,
+		ctx context.Context, output []emitEntry, kv roachpb.KeyValue, prevVal roachpb.Value,
+		schemaTimestamp hlc.Timestamp, bufferGetTimestamp time.Time,
 	) ([]emitEntry, error) {
 		// Reuse kvs to save allocations.
 		kvs.KVs = kvs.KVs[:0]
@@ -101,6 +116,21 @@ func kvsToRows(
 		if err != nil {
 			return nil, err
 		}
+
+		var prevDatums sqlbase.EncDatumRow
+		if prevVal.IsPresent() {
+			kvs.KVs = append(kvs.KVs, roachpb.KeyValue{Key: kv.Key, Value: prevVal})
+			if err := rf.StartScanFrom(ctx, &kvs); err != nil {
+				return nil, err
+			}
+			datums, _, _, err := rf.NextRow(ctx)
+			if err != nil {
+				return nil, err
+			}
+			prevDatums = append(sqlbase.EncDatumRow(nil), datums...)
+			kvs.KVs = kvs.KVs[:0]
+		}
+
 		// TODO(dan): Handle tables with multiple column families.
 		kvs.KVs = append(kvs.KVs, kv)
 		if err := rf.StartScanFrom(ctx, &kvs); err != nil {
@@ -119,6 +149,7 @@ func kvsToRows(
 			}
 			r.row.datums = append(sqlbase.EncDatumRow(nil), r.row.datums...)
 			r.row.deleted = rf.RowIsDeleted()
+			r.row.prevDatums = prevDatums
 			// TODO(mrtracy): This should likely be set to schemaTimestamp instead of
 			// the value timestamp, if schema timestamp is set. However, doing so
 			// seems to break some of the assumptions of our existing tests in subtle
@@ -147,7 +178,7 @@ func kvsToRows(
 					schemaTimestamp = input.schemaTimestamp
 				}
 				output, err = appendEmitEntryForKV(
-					ctx, output, input.kv, schemaTimestamp, input.bufferGetTimestamp)
+					ctx, output, input.kv, input.prevVal, schemaTimestamp, input.bufferGetTimestamp)
 				if err != nil {
 					return nil, err
 				}
@@ -158,6 +189,12 @@ func kvsToRows(
 					bufferGetTimestamp: input.bufferGetTimestamp,
 				})
 			}
+			if input.schemaChange != nil {
+				output = append(output, emitEntry{
+					schemaChange:       input.schemaChange,
+					bufferGetTimestamp: input.bufferGetTimestamp,
+				})
+			}
 			if output != nil {
 				return output, nil
 			}
@@ -188,12 +225,27 @@ func emitEntries(
 		}
 		scratch, keyCopy = scratch.Copy(encodedKey, 0 /* extraCap */)
 
-		if !row.deleted && envelopeType(details.Opts[optEnvelope]) == optEnvelopeRow {
-			var encodedValue []byte
-			encodedValue, err = encoder.EncodeValue(row.tableDesc, row.datums, row.timestamp)
-			if err != nil {
-				return err
+		valueDesc, datums, prevDatums := row.tableDesc, row.datums, row.prevDatums
+		if row.valueDesc != nil {
+			valueDesc, datums, prevDatums = row.valueDesc, row.valueDatums, row.prevValueDatums
+		}
+		var encodedValue []byte
+		switch envelopeType(details.Opts[optEnvelope]) {
+		case optEnvelopeRow:
+			if !row.deleted {
+				encodedValue, err = encoder.EncodeValue(
+					valueDesc, datums, nil /* prevRow */, row.timestamp)
+			}
+		case optEnvelopeDiff:
+			if row.deleted {
+				datums = nil
 			}
+			encodedValue, err = encoder.EncodeValue(valueDesc, datums, prevDatums, row.timestamp)
+		}
+		if err != nil {
+			return err
+		}
+		if encodedValue != nil {
 			scratch, valueCopy = scratch.Copy(encodedValue, 0 /* extraCap */)
 		}
 
@@ -202,7 +254,7 @@ func emitEntries(
 				return err
 			}
 		}
-		if err := sink.EmitRow(ctx, row.tableDesc.Name, keyCopy, valueCopy); err != nil {
+		if err := sink.EmitRow(ctx, row.tableDesc.Name, keyCopy, valueCopy, row.timestamp); err != nil {
 			return err
 		}
 		if log.V(3) {
@@ -211,9 +263,28 @@ func emitEntries(
 		return nil
 	}
 
+	emitSchemaChangeFn := func(ctx context.Context, sc *schemaChangeEvent) error {
+		payload, err := encoder.EncodeSchemaChange(*sc)
+		if err != nil {
+			return err
+		}
+		var payloadCopy []byte
+		scratch, payloadCopy = scratch.Copy(payload, 0 /* extraCap */)
+		if err := sink.EmitSchemaChange(ctx, sc.tableDesc.Name, payloadCopy, sc.updated); err != nil {
+			return err
+		}
+		if log.V(2) {
+			log.Infof(ctx, `schema change %s: %s`, sc.tableDesc.Name, payloadCopy)
+		}
+		return nil
+	}
+
 	var lastFlush time.Time
 	// TODO(dan): We could keep these in a spanFrontier to eliminate dups.
 	var resolvedSpans []jobspb.ResolvedSpan
+	// atSchemaChangeBoundary is set when a resolved span marks a schema change
+	// boundary, at which the changefeed is about to stop.
+	var atSchemaChangeBoundary bool
 
 	return func(ctx context.Context) ([]jobspb.ResolvedSpan, error) {
 		inputs, err := inputFn(ctx)
@@ -237,8 +308,16 @@ func emitEntries(
 					return nil, err
 				}
 			}
+			if input.schemaChange != nil {
+				if err := emitSchemaChangeFn(ctx, input.schemaChange); err != nil {
+					return nil, err
+				}
+			}
 			if input.resolved != nil {
 				resolvedSpans = append(resolvedSpans, *input.resolved)
+				if input.resolved.SchemaChangeBoundary {
+					atSchemaChangeBoundary = true
+				}
 			}
 		}
 
@@ -255,8 +334,12 @@ func emitEntries(
 		// from the poller (which should always happen, even if the watched data
 		// is not changing), then this is sufficient and we don't have to do
 		// anything fancy with timers.
+		//
+		// At a schema change boundary, no more resolved spans will follow, so
+		// flush right away.
 		timeBetweenFlushes := changefeedPollInterval.Get(&settings.SV) / 5
-		if len(resolvedSpans) == 0 || timeutil.Since(lastFlush) < timeBetweenFlushes {
+		if len(resolvedSpans) == 0 ||
+			(timeutil.Since(lastFlush) < timeBetweenFlushes && !atSchemaChangeBoundary) {
 			return nil, nil
 		}
 
@@ -275,6 +358,7 @@ func emitEntries(
 		}
 		ret := append([]jobspb.ResolvedSpan(nil), resolvedSpans...)
 		resolvedSpans = resolvedSpans[:0]
+		atSchemaChangeBoundary = false
 		return ret, nil
 	}
 }
@@ -329,7 +413,7 @@ func emitResolvedTimestamp(
 	payload = append([]byte(nil), payload...)
 	// TODO(dan): Emit more fine-grained (table level) resolved
 	// timestamps.
-	if err := sink.EmitResolvedTimestamp(ctx, payload); err != nil {
+	if err := sink.EmitResolvedTimestamp(ctx, payload, resolved); err != nil {
 		return err
 	}
 	if log.V(2) {
@@ -119,7 +119,8 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 
 	var err error
 	if ca.sink, err = getSink(
-		ca.spec.Feed.SinkURI, ca.spec.Feed.Opts, ca.spec.Feed.Targets,
+		ctx, ca.spec.Feed.SinkURI, ca.flowCtx.EvalCtx.NodeID, ca.spec.Feed.Opts, ca.spec.Feed.Targets,
+		ca.flowCtx.Settings,
 	); err != nil {
 		// Early abort in the case that there is an error creating the sink.
 		ca.MoveToDraining(err)
@@ -155,6 +156,13 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		spans, ca.spec.Feed, initialHighWater, buf, leaseMgr, metrics,
 	)
 	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, buf.Get)
+	if ca.spec.Feed.Select != `` {
+		if rowsFn, err = selectRows(ca.flowCtx.NewEvalCtx(), ca.spec.Feed, rowsFn); err != nil {
+			ca.MoveToDraining(err)
+			ca.cancel()
+			return ctx
+		}
+	}
 
 	var knobs TestingKnobs
 	if cfKnobs, ok := ca.flowCtx.TestingKnobs().Changefeed.(*TestingKnobs); ok {
@@ -304,6 +312,10 @@ type changeFrontier struct {
 	lastEmitResolved time.Time
 	// lastSlowSpanLog is the last time a slow span from `sf` was logged.
 	lastSlowSpanLog time.Time
+	// schemaChangeBoundary, if set, is the timestamp of a schema change at
+	// which the changefeed stops because of its schema_change_policy. It's
+	// set once any resolved span marks it.
+	schemaChangeBoundary hlc.Timestamp
 
 	// jobProgressedFn, if non-nil, is called to checkpoint the changefeed's
 	// progress in the corresponding system job entry.
@@ -393,7 +405,8 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 
 	var err error
 	if cf.sink, err = getSink(
-		cf.spec.Feed.SinkURI, cf.spec.Feed.Opts, cf.spec.Feed.Targets,
+		ctx, cf.spec.Feed.SinkURI, cf.flowCtx.EvalCtx.NodeID, cf.spec.Feed.Opts, cf.spec.Feed.Targets,
+		cf.flowCtx.Settings,
 	); err != nil {
 		cf.MoveToDraining(err)
 		return ctx
@@ -501,6 +514,9 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 	if err := protoutil.Unmarshal([]byte(*raw), &resolved); err != nil {
 		return errors.Wrapf(err, `unmarshalling resolved span: %x`, raw)
 	}
+	if resolved.SchemaChangeBoundary {
+		cf.schemaChangeBoundary = resolved.Timestamp
+	}
 
 	frontierChanged := cf.sf.Forward(resolved.Span, resolved.Timestamp)
 	if frontierChanged {
@@ -522,6 +538,11 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 			}
 			cf.lastEmitResolved = newResolved.GoTime()
 		}
+		if cf.schemaChangeBoundary != (hlc.Timestamp{}) && !newResolved.Less(cf.schemaChangeBoundary) {
+			// Everything up to the schema change has been emitted and
+			// checkpointed, so the changefeed can stop.
+			return &schemaChangeBoundaryError{ts: cf.schemaChangeBoundary}
+		}
 	}
 
 	// Potentially log the most behind span in the frontier for debugging.
@@ -0,0 +1,387 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/pkg/errors"
+)
+
+// changefeedSelectContext is used in the errors for expressions that are not
+// allowed in the query of a CREATE CHANGEFEED ... AS SELECT.
+const changefeedSelectContext = `CHANGEFEED`
+
+// validateChangefeedSelect checks that the query of a `CREATE CHANGEFEED ...
+// AS SELECT` can be evaluated on each changed row in isolation: it must be a
+// plain SELECT from a single table, optionally with a WHERE clause, and without
+// anything that needs state across rows. It returns the SELECT clause and the
+// table it selects from. The expressions are checked separately, by
+// makeChangefeedProjection, once the table has been resolved.
+func validateChangefeedSelect(sel *tree.Select) (*tree.SelectClause, *tree.TableName, error) {
+	if sel.With != nil {
+		return nil, nil, errors.New(`CHANGEFEED does not support WITH in its query`)
+	}
+	if sel.OrderBy != nil {
+		return nil, nil, errors.New(`CHANGEFEED does not support ORDER BY in its query`)
+	}
+	if sel.Limit != nil {
+		return nil, nil, errors.New(`CHANGEFEED does not support LIMIT in its query`)
+	}
+	stmt := sel.Select
+	if paren, ok := stmt.(*tree.ParenSelect); ok {
+		return validateChangefeedSelect(paren.Select)
+	}
+	sc, ok := stmt.(*tree.SelectClause)
+	if !ok || sc.TableSelect {
+		return nil, nil, errors.Errorf(`CHANGEFEED query must be a simple SELECT: %s`,
+			tree.AsString(stmt))
+	}
+	if sc.Distinct || sc.DistinctOn != nil {
+		return nil, nil, errors.New(`CHANGEFEED does not support DISTINCT in its query`)
+	}
+	if sc.GroupBy != nil || sc.Having != nil {
+		return nil, nil, errors.New(`CHANGEFEED does not support GROUP BY or HAVING in its query`)
+	}
+	if sc.Window != nil {
+		return nil, nil, errors.New(`CHANGEFEED does not support WINDOW in its query`)
+	}
+	if sc.From == nil || len(sc.From.Tables) != 1 {
+		return nil, nil, errors.New(`CHANGEFEED query must select from exactly one table`)
+	}
+	if sc.From.AsOf.Expr != nil {
+		return nil, nil, errors.Errorf(
+			`CHANGEFEED does not support AS OF SYSTEM TIME in its query, use the %s option`, optCursor)
+	}
+	aliased, ok := sc.From.Tables[0].(*tree.AliasedTableExpr)
+	if !ok {
+		return nil, nil, errors.Errorf(`CHANGEFEED query must select from a table: %s`,
+			tree.AsString(sc.From.Tables[0]))
+	}
+	name, ok := aliased.Expr.(*tree.TableName)
+	if !ok {
+		return nil, nil, errors.Errorf(`CHANGEFEED query must select from a table: %s`,
+			tree.AsString(aliased.Expr))
+	}
+	if aliased.Ordinality || aliased.IndexFlags != nil || len(aliased.As.Cols) > 0 {
+		return nil, nil, errors.Errorf(`CHANGEFEED query must select from a table: %s`,
+			tree.AsString(aliased))
+	}
+	return sc, name, nil
+}
+
+// parseChangefeedSelect parses and validates the query stored in the Select
+// field of ChangefeedDetails.
+func parseChangefeedSelect(query string) (*tree.SelectClause, error) {
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(*tree.Select)
+	if !ok {
+		return nil, errors.Errorf(`expected a SELECT: %s`, query)
+	}
+	sc, _, err := validateChangefeedSelect(sel)
+	return sc, err
+}
+
+// changefeedSelectHasFilter returns whether the query of a `CREATE CHANGEFEED
+// ... AS SELECT`, if any, has a WHERE clause. Deletions are filtered on the
+// previous value of the row, so such a changefeed needs it for every change. A
+// query that doesn't parse is reported by selectRows.
+func changefeedSelectHasFilter(details jobspb.ChangefeedDetails) bool {
+	if details.Select == `` {
+		return false
+	}
+	sc, err := parseChangefeedSelect(details.Select)
+	return err == nil && sc.Where != nil
+}
+
+// changefeedProjection is the query of a `CREATE CHANGEFEED ... AS SELECT`
+// compiled for one version of the watched table. It implements
+// tree.IndexedVarContainer to evaluate the query on a row of the table.
+type changefeedProjection struct {
+	// cols are the columns of the table, which are the columns of the rows the
+	// projection is evaluated on.
+	cols []sqlbase.ColumnDescriptor
+	// filter is the WHERE clause, if any.
+	filter tree.TypedExpr
+	// exprs are the expressions of the SELECT list, with stars expanded.
+	exprs []tree.TypedExpr
+	// valueDesc describes the output of the projection. It has one column for
+	// each of exprs and the ID, name and version of the table, so that it can
+	// be given to an Encoder in place of the table's descriptor.
+	valueDesc *sqlbase.TableDescriptor
+
+	sourceInfo   *sqlbase.DataSourceInfo
+	curSourceRow tree.Datums
+	alloc        sqlbase.DatumAlloc
+}
+
+var _ tree.IndexedVarContainer = &changefeedProjection{}
+
+// makeChangefeedProjection resolves and type checks the query of a `CREATE
+// CHANGEFEED ... AS SELECT` against a version of the table it selects from. An
+// error is returned if it uses columns that don't exist in this version of the
+// table or any expression that could evaluate differently on the same row,
+// such as an impure function, a subquery or an aggregate.
+func makeChangefeedProjection(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	sc *tree.SelectClause,
+	tableDesc *sqlbase.TableDescriptor,
+) (*changefeedProjection, error) {
+	p := &changefeedProjection{
+		cols:         tableDesc.Columns,
+		curSourceRow: make(tree.Datums, len(tableDesc.Columns)),
+	}
+
+	// Qualified column names are resolved against the table name (or alias) as
+	// it was written in the query, so that they keep working if the table is
+	// renamed.
+	aliased := sc.From.Tables[0].(*tree.AliasedTableExpr)
+	tn := tree.MakeUnqualifiedTableName(aliased.As.Alias)
+	if aliased.As.Alias == `` {
+		tn = *aliased.Expr.(*tree.TableName)
+	}
+	p.sourceInfo = sqlbase.NewSourceInfoForSingleTable(
+		tn, sqlbase.ResultColumnsFromColDescs(tableDesc.Columns),
+	)
+	sources := sqlbase.MakeMultiSourceInfo(p.sourceInfo)
+	ivarHelper := tree.MakeIndexedVarHelper(p, len(tableDesc.Columns))
+	searchPath := evalCtx.SessionData.SearchPath
+
+	analyzeExpr := func(
+		_ context.Context,
+		raw tree.Expr,
+		sources sqlbase.MultiSourceInfo,
+		ivarHelper tree.IndexedVarHelper,
+		expectedType types.T,
+		requireType bool,
+		typingContext string,
+	) (tree.TypedExpr, error) {
+		expr, _, _, err := sqlbase.ResolveNames(raw, sources, ivarHelper, searchPath)
+		if err != nil {
+			return nil, err
+		}
+		semaCtx := tree.MakeSemaContext(false /* privileged */)
+		semaCtx.IVarContainer = p
+		semaCtx.Properties.Require(typingContext,
+			tree.RejectSpecial|tree.RejectImpureFunctions|tree.RejectSubqueries)
+		if requireType {
+			return tree.TypeCheckAndRequire(expr, &semaCtx, expectedType, typingContext)
+		}
+		return tree.TypeCheck(expr, &semaCtx, expectedType)
+	}
+
+	if sc.Where != nil {
+		var err error
+		p.filter, err = analyzeExpr(
+			ctx, sc.Where.Expr, sources, ivarHelper, types.Bool, true, /* requireType */
+			changefeedSelectContext,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var resultCols sqlbase.ResultColumns
+	for _, target := range sc.Exprs {
+		isStar, cols, exprs, err := sqlbase.CheckRenderStar(ctx, analyzeExpr, target, sources, ivarHelper)
+		if err != nil {
+			return nil, err
+		}
+		if isStar {
+			resultCols = append(resultCols, cols...)
+			p.exprs = append(p.exprs, exprs...)
+			continue
+		}
+		name, err := tree.GetRenderColName(searchPath, target)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := analyzeExpr(
+			ctx, target.Expr, sources, ivarHelper, types.Any, false, /* requireType */
+			changefeedSelectContext,
+		)
+		if err != nil {
+			return nil, err
+		}
+		resultCols = append(resultCols, sqlbase.ResultColumn{Name: name, Typ: expr.ResolvedType()})
+		p.exprs = append(p.exprs, expr)
+	}
+
+	p.valueDesc = &sqlbase.TableDescriptor{
+		ID:      tableDesc.ID,
+		Name:    tableDesc.Name,
+		Version: tableDesc.Version,
+		Columns: make([]sqlbase.ColumnDescriptor, len(resultCols)),
+	}
+	for i, col := range resultCols {
+		colType, err := sqlbase.DatumTypeToColumnType(col.Typ)
+		if err != nil {
+			return nil, err
+		}
+		p.valueDesc.Columns[i] = sqlbase.ColumnDescriptor{
+			Name:     col.Name,
+			ID:       sqlbase.ColumnID(i + 1),
+			Type:     colType,
+			Nullable: true,
+		}
+	}
+	return p, nil
+}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (p *changefeedProjection) IndexedVarEval(idx int, ctx *tree.EvalContext) (tree.Datum, error) {
+	return p.curSourceRow[idx].Eval(ctx)
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (p *changefeedProjection) IndexedVarResolvedType(idx int) types.T {
+	return p.sourceInfo.SourceColumns[idx].Typ
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (p *changefeedProjection) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	return p.sourceInfo.NodeFormatter(idx)
+}
+
+// project evaluates the query on a row of the table. It returns whether the
+// row matches the WHERE clause and, if it does, the projected row. The SELECT
+// list isn't evaluated on rows that don't match, since it may only be valid on
+// those that do.
+func (p *changefeedProjection) project(
+	evalCtx *tree.EvalContext, row sqlbase.EncDatumRow,
+) (sqlbase.EncDatumRow, bool, error) {
+	for i := range p.cols {
+		if err := row[i].EnsureDecoded(&p.cols[i].Type, &p.alloc); err != nil {
+			return nil, false, err
+		}
+		p.curSourceRow[i] = row[i].Datum
+	}
+
+	evalCtx.PushIVarContainer(p)
+	defer evalCtx.PopIVarContainer()
+	// Memory allocated while evaluating a row isn't retained after it has been
+	// projected, so it doesn't have to stay accounted for.
+	if evalCtx.ActiveMemAcc != nil {
+		defer evalCtx.ActiveMemAcc.Clear(evalCtx.Ctx())
+	}
+
+	if p.filter != nil {
+		d, err := p.filter.Eval(evalCtx)
+		if err != nil {
+			return nil, false, err
+		}
+		if d != tree.DBoolTrue {
+			return nil, false, nil
+		}
+	}
+	projected := make(sqlbase.EncDatumRow, len(p.exprs))
+	for i, expr := range p.exprs {
+		d, err := expr.Eval(evalCtx)
+		if err != nil {
+			return nil, false, err
+		}
+		projected[i] = sqlbase.DatumToEncDatum(p.valueDesc.Columns[i].Type, d)
+	}
+	return projected, true, nil
+}
+
+// selectRows filters and projects the rows returned by inputFn with the query
+// of a `CREATE CHANGEFEED ... AS SELECT`. Rows that don't match the WHERE
+// clause are dropped. The projected rows keep the table's descriptor and
+// datums, which are used to encode the key, and carry the projection in
+// valueDesc and valueDatums, which are used to encode the value.
+//
+// A deletion is emitted if the previous value of the row matches, which the
+// poller fetches for every change when the query has a WHERE clause. With
+// envelope=diff, any change is emitted if either the previous or the new value
+// of the row matches, and only the values that match are projected; the other
+// is emitted as null.
+//
+// The returned closure is not threadsafe.
+func selectRows(
+	evalCtx *tree.EvalContext,
+	details jobspb.ChangefeedDetails,
+	inputFn func(context.Context) ([]emitEntry, error),
+) (func(context.Context) ([]emitEntry, error), error) {
+	sc, err := parseChangefeedSelect(details.Select)
+	if err != nil {
+		return nil, err
+	}
+	diff := envelopeType(details.Opts[optEnvelope]) == optEnvelopeDiff
+
+	// Projections only change with the table's schema, so this cache stays
+	// small.
+	projections := make(map[tableIDAndVersion]*changefeedProjection)
+	projectionFor := func(
+		ctx context.Context, tableDesc *sqlbase.TableDescriptor,
+	) (*changefeedProjection, error) {
+		cacheKey := makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)
+		if p, ok := projections[cacheKey]; ok {
+			return p, nil
+		}
+		p, err := makeChangefeedProjection(ctx, evalCtx, sc, tableDesc)
+		if err != nil {
+			return nil, err
+		}
+		projections[cacheKey] = p
+		return p, nil
+	}
+
+	var output []emitEntry
+	return func(ctx context.Context) ([]emitEntry, error) {
+		inputs, err := inputFn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Reuse output to save allocations.
+		output = output[:0]
+		for _, input := range inputs {
+			row := &input.row
+			if row.datums == nil {
+				output = append(output, input)
+				continue
+			}
+			p, err := projectionFor(ctx, row.tableDesc)
+			if err != nil {
+				return nil, err
+			}
+			row.valueDesc = p.valueDesc
+			var matches bool
+			if !row.deleted {
+				if row.valueDatums, matches, err = p.project(evalCtx, row.datums); err != nil {
+					return nil, err
+				}
+			}
+			if row.prevDatums != nil && (diff || row.deleted) {
+				var prevMatches bool
+				if row.prevValueDatums, prevMatches, err = p.project(evalCtx, row.prevDatums); err != nil {
+					return nil, err
+				}
+				matches = matches || prevMatches
+			}
+			if row.deleted && row.prevDatums == nil && p.filter == nil {
+				// The deleted row had no previous value, but every row matches.
+				matches = true
+			}
+			if matches {
+				output = append(output, input)
+			}
+		}
+		return output, nil
+	}, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateChangefeedSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tests := []struct {
+		query string
+		table string
+		err   string
+	}{
+		{query: `SELECT * FROM foo`, table: `foo`},
+		{query: `SELECT a, b + 1 AS c FROM d.foo WHERE a > 1`, table: `d.foo`},
+		{query: `(SELECT f.a FROM foo AS f)`, table: `foo`},
+		{query: `WITH x AS (SELECT 1) SELECT * FROM foo`, err: `does not support WITH`},
+		{query: `SELECT * FROM foo ORDER BY a`, err: `does not support ORDER BY`},
+		{query: `SELECT * FROM foo LIMIT 1`, err: `does not support LIMIT`},
+		{query: `SELECT DISTINCT a FROM foo`, err: `does not support DISTINCT`},
+		{query: `SELECT a FROM foo GROUP BY a`, err: `does not support GROUP BY or HAVING`},
+		{query: `SELECT a FROM foo UNION SELECT a FROM bar`, err: `must be a simple SELECT`},
+		{query: `TABLE foo`, err: `must be a simple SELECT`},
+		{query: `SELECT 1`, err: `must select from exactly one table`},
+		{query: `SELECT * FROM foo, bar`, err: `must select from exactly one table`},
+		{query: `SELECT * FROM foo JOIN bar USING (a)`, err: `must select from a table`},
+		{query: `SELECT * FROM (SELECT * FROM foo)`, err: `must select from a table`},
+		{query: `SELECT * FROM foo WITH ORDINALITY`, err: `must select from a table`},
+		{query: `SELECT * FROM foo AS OF SYSTEM TIME '-1s'`, err: `use the cursor option`},
+	}
+	for _, test := range tests {
+		stmt, err := parser.ParseOne(test.query)
+		require.NoError(t, err)
+		_, table, err := validateChangefeedSelect(stmt.(*tree.Select))
+		if test.err != `` {
+			require.Error(t, err, test.query)
+			require.Contains(t, err.Error(), test.err, test.query)
+			continue
+		}
+		require.NoError(t, err, test.query)
+		require.Equal(t, test.table, table.String(), test.query)
+	}
+}
+
+func TestChangefeedProjection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(ctx)
+
+	intType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+	stringType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_STRING}
+	tableDesc := &sqlbase.TableDescriptor{
+		ID:      52,
+		Name:    `foo`,
+		Version: 1,
+		Columns: []sqlbase.ColumnDescriptor{
+			{ID: 1, Name: `a`, Type: intType},
+			{ID: 2, Name: `b`, Type: stringType, Nullable: true},
+		},
+	}
+	row := func(a int, b string) sqlbase.EncDatumRow {
+		return sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(a))),
+			sqlbase.DatumToEncDatum(stringType, tree.NewDString(b)),
+		}
+	}
+	makeProjection := func(query string) (*changefeedProjection, error) {
+		sc, err := parseChangefeedSelect(query)
+		require.NoError(t, err)
+		return makeChangefeedProjection(ctx, evalCtx, sc, tableDesc)
+	}
+	project := func(p *changefeedProjection, r sqlbase.EncDatumRow) (string, bool) {
+		projected, matches, err := p.project(evalCtx, r)
+		require.NoError(t, err)
+		return projected.String(p.valueDesc.ColumnTypes()), matches
+	}
+
+	t.Run(`star`, func(t *testing.T) {
+		p, err := makeProjection(`SELECT * FROM foo`)
+		require.NoError(t, err)
+		require.Equal(t, []sqlbase.ColumnDescriptor{
+			{ID: 1, Name: `a`, Type: intType, Nullable: true},
+			{ID: 2, Name: `b`, Type: stringType, Nullable: true},
+		}, p.valueDesc.Columns)
+		projected, matches := project(p, row(1, `x`))
+		require.Equal(t, `[1 'x']`, projected)
+		require.True(t, matches)
+	})
+
+	t.Run(`filter and project`, func(t *testing.T) {
+		p, err := makeProjection(`SELECT f.a * 2, upper(b) AS c FROM foo AS f WHERE a > 1`)
+		require.NoError(t, err)
+		require.Equal(t, []sqlbase.ColumnDescriptor{
+			{ID: 1, Name: `?column?`, Type: intType, Nullable: true},
+			{ID: 2, Name: `c`, Type: stringType, Nullable: true},
+		}, p.valueDesc.Columns)
+		require.Equal(t, tableDesc.ID, p.valueDesc.ID)
+		require.Equal(t, tableDesc.Name, p.valueDesc.Name)
+
+		projected, matches := project(p, row(2, `x`))
+		require.Equal(t, `[4 'X']`, projected)
+		require.True(t, matches)
+		_, matches = project(p, row(1, `y`))
+		require.False(t, matches)
+	})
+
+	t.Run(`filter guards projection`, func(t *testing.T) {
+		// The SELECT list is only evaluated on rows that match.
+		p, err := makeProjection(`SELECT 10 // a AS q FROM foo WHERE a <> 0`)
+		require.NoError(t, err)
+		projected, matches := project(p, row(5, `x`))
+		require.Equal(t, `[2]`, projected)
+		require.True(t, matches)
+		_, matches = project(p, row(0, `y`))
+		require.False(t, matches)
+	})
+
+	t.Run(`errors`, func(t *testing.T) {
+		for query, expected := range map[string]string{
+			`SELECT c FROM foo`:                       `column "c" does not exist`,
+			`SELECT a FROM foo WHERE b`:               `argument of CHANGEFEED must be type bool, not type string`,
+			`SELECT now() FROM foo`:                   `impure functions are not allowed in CHANGEFEED`,
+			`SELECT random() FROM foo`:                `impure functions are not allowed in CHANGEFEED`,
+			`SELECT sum(a) FROM foo`:                  `aggregate functions are not allowed in CHANGEFEED`,
+			`SELECT a FROM foo WHERE a IN (SELECT 1)`: `subqueries are not allowed in CHANGEFEED`,
+		} {
+			_, err := makeProjection(query)
+			require.Error(t, err, query)
+			require.Contains(t, err.Error(), expected, query)
+		}
+	})
+}
@@ -10,11 +10,13 @@ package changefeedccl
 
 import (
 	"context"
+	"net/url"
 	"regexp"
 	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -39,6 +41,7 @@ func init() {
 
 type envelopeType string
 type formatType string
+type schemaChangePolicy string
 
 const (
 	optConfluentSchemaRegistry = `confluent_schema_registry`
@@ -46,6 +49,7 @@ const (
 	optEnvelope                = `envelope`
 	optFormat                  = `format`
 	optResolvedTimestamps      = `resolved`
+	optSchemaChangePolicy      = `schema_change_policy`
 	optUpdatedTimestamps       = `updated`
 
 	optEnvelopeKeyOnly envelopeType = `key_only`
@@ -55,11 +59,39 @@ const (
 	optFormatJSON formatType = `json`
 	optFormatAvro formatType = `experimental_avro`
 
+	// optSchemaChangePolicyBackfill re-emits every row of a table after a
+	// schema change that required a backfill.
+	optSchemaChangePolicyBackfill schemaChangePolicy = `backfill`
+	// optSchemaChangePolicyNoBackfill continues past such schema changes
+	// without re-emitting any rows.
+	optSchemaChangePolicyNoBackfill schemaChangePolicy = `nobackfill`
+	// optSchemaChangePolicyStop fails the changefeed once everything before
+	// such a schema change has been emitted.
+	optSchemaChangePolicyStop schemaChangePolicy = `stop`
+	// optSchemaChangePolicyPause pauses the changefeed job once everything
+	// before such a schema change has been emitted. When resumed, it continues
+	// without re-emitting any rows.
+	optSchemaChangePolicyPause schemaChangePolicy = `pause`
+
+	sinkParamBatchSize        = `batch_size`
+	sinkParamCACert           = `ca_cert`
+	sinkParamClientCert       = `client_cert`
+	sinkParamClientKey        = `client_key`
+	sinkParamFileSize         = `file_size`
+	sinkParamFlushInterval    = `flush_interval`
+	sinkParamMaxRetries       = `max_retries`
 	sinkParamTopicPrefix      = `topic_prefix`
 	sinkParamSchemaTopic      = `schema_topic`
+	sinkSchemeAzure           = `azure`
 	sinkSchemeBuffer          = ``
 	sinkSchemeExperimentalSQL = `experimental-sql`
+	sinkSchemeGCS             = `gs`
+	sinkSchemeHTTP            = `http`
+	sinkSchemeHTTPS           = `https`
 	sinkSchemeKafka           = `kafka`
+	sinkSchemeNodelocal       = `nodelocal`
+	sinkSchemeS3              = `s3`
+	sinkSchemeWebhookHTTPS    = `webhook-https`
 )
 
 var changefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
@@ -68,6 +100,7 @@ var changefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	optEnvelope:                sql.KVStringOptRequireValue,
 	optFormat:                  sql.KVStringOptRequireValue,
 	optResolvedTimestamps:      sql.KVStringOptAny,
+	optSchemaChangePolicy:      sql.KVStringOptRequireValue,
 	optUpdatedTimestamps:       sql.KVStringOptRequireNoValue,
 }
 
@@ -97,6 +130,10 @@ func changefeedPlanHook(
 			{Name: "key", Typ: types.Bytes},
 			{Name: "value", Typ: types.Bytes},
 		}
+		// The rows have to reach the client as they're emitted, not when some
+		// buffer fills up, and a client that isn't reading them should hold up
+		// the changefeed.
+		p.StreamResults()
 	} else {
 		var err error
 		sinkURIFn, err = p.TypeAsString(changefeedStmt.SinkURI, `CREATE CHANGEFEED`)
@@ -153,14 +190,25 @@ func changefeedPlanHook(
 			statementTime = initialHighWater
 		}
 
+		targetList := changefeedStmt.Targets
+		var selectClause *tree.SelectClause
+		if changefeedStmt.Select != nil {
+			var tableName *tree.TableName
+			selectClause, tableName, err = validateChangefeedSelect(changefeedStmt.Select)
+			if err != nil {
+				return err
+			}
+			targetList = tree.TargetList{Tables: tree.TablePatterns{tableName}}
+		}
+
 		// For now, disallow targeting a database or wildcard table selection.
 		// Getting it right as tables enter and leave the set over time is
 		// tricky.
-		if len(changefeedStmt.Targets.Databases) > 0 {
+		if len(targetList.Databases) > 0 {
 			return errors.Errorf(`CHANGEFEED cannot target %s`,
-				tree.AsString(&changefeedStmt.Targets))
+				tree.AsString(&targetList))
 		}
-		for _, t := range changefeedStmt.Targets.Tables {
+		for _, t := range targetList.Tables {
 			p, err := t.NormalizeTablePattern()
 			if err != nil {
 				return err
@@ -172,7 +220,7 @@ func changefeedPlanHook(
 
 		// This grabs table descriptors once to get their ids.
 		targetDescs, _, err := backupccl.ResolveTargetsToDescriptors(
-			ctx, p, statementTime, changefeedStmt.Targets)
+			ctx, p, statementTime, targetList)
 		if err != nil {
 			return err
 		}
@@ -185,6 +233,15 @@ func changefeedPlanHook(
 				if err := validateChangefeedTable(targets, tableDesc); err != nil {
 					return err
 				}
+				if selectClause != nil {
+					// Check the expressions of the query now, rather than
+					// failing the changefeed when it starts.
+					if _, err := makeChangefeedProjection(
+						ctx, &p.ExtendedEvalContext().EvalContext, selectClause, tableDesc,
+					); err != nil {
+						return err
+					}
+				}
 			}
 		}
 
@@ -194,6 +251,9 @@ func changefeedPlanHook(
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
 		}
+		if changefeedStmt.Select != nil {
+			details.Select = tree.AsString(changefeedStmt.Select)
+		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{HighWater: &initialHighWater},
 			Details: &jobspb.Progress_Changefeed{
@@ -217,7 +277,9 @@ func changefeedPlanHook(
 		// the CREATE CHANGEFEED statement. To do this, we create a "canary" sink,
 		// which will be immediately closed, only to check for errors.
 		{
-			canarySink, err := getSink(sinkURI, opts, targets)
+			canarySink, err := getSink(
+				ctx, sinkURI, p.ExecCfg().NodeID.Get(), opts, targets, p.ExecCfg().Settings,
+			)
 			if err != nil {
 				// In this context, we don't want to retry even retryable errors from the
 				// sync. Unwrap any retryable errors encountered.
@@ -234,9 +296,13 @@ func changefeedPlanHook(
 		// Make a channel for runChangefeedFlow to signal once everything has
 		// been setup okay. This intentionally abuses what would normally be
 		// hooked up to resultsCh to avoid a bunch of extra plumbing.
+		description, err := changefeedJobDescription(changefeedStmt, sinkURI, opts)
+		if err != nil {
+			return err
+		}
 		startedCh := make(chan tree.Datums)
 		job, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, startedCh, jobs.Record{
-			Description: changefeedJobDescription(changefeedStmt, sinkURI, opts),
+			Description: description,
 			Username:    p.User(),
 			DescriptorIDs: func() (sqlDescIDs []sqlbase.ID) {
 				for _, desc := range targetDescs {
@@ -269,12 +335,22 @@ func changefeedPlanHook(
 
 func changefeedJobDescription(
 	changefeed *tree.CreateChangefeed, sinkURI string, opts map[string]string,
-) string {
+) (string, error) {
+	// Export storage URIs may contain secrets in their query parameters.
+	if u, err := url.Parse(sinkURI); err == nil && isCloudStorageSinkScheme(u.Scheme) {
+		if sinkURI, err = storageccl.SanitizeExportStorageURI(sinkURI); err != nil {
+			return "", err
+		}
+	}
+	if u, err := url.Parse(sinkURI); err == nil && u.Scheme == sinkSchemeWebhookHTTPS {
+		if sinkURI, err = sanitizeWebhookSinkURI(sinkURI); err != nil {
+			return "", err
+		}
+	}
 	c := &tree.CreateChangefeed{
 		Targets: changefeed.Targets,
-		// If/when we start accepting export storage uris (or ones with
-		// secrets), we'll need to sanitize sinkURI.
 		SinkURI: tree.NewDString(sinkURI),
+		Select:  changefeed.Select,
 	}
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
@@ -284,7 +360,7 @@ func changefeedJobDescription(
 		c.Options = append(c.Options, opt)
 	}
 	sort.Slice(c.Options, func(i, j int) bool { return c.Options[i].Key < c.Options[j].Key })
-	return tree.AsStringWithFlags(c, tree.FmtAlwaysQualifyTableNames)
+	return tree.AsStringWithFlags(c, tree.FmtAlwaysQualifyTableNames), nil
 }
 
 func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails, error) {
@@ -311,8 +387,7 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	case optEnvelopeKeyOnly:
 		details.Opts[optEnvelope] = string(optEnvelopeKeyOnly)
 	case optEnvelopeDiff:
-		return jobspb.ChangefeedDetails{}, errors.Errorf(
-			`%s=%s is not yet supported`, optEnvelope, optEnvelopeDiff)
+		details.Opts[optEnvelope] = string(optEnvelopeDiff)
 	default:
 		return jobspb.ChangefeedDetails{}, errors.Errorf(
 			`unknown %s: %s`, optEnvelope, details.Opts[optEnvelope])
@@ -328,6 +403,21 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 			`unknown %s: %s`, optFormat, details.Opts[optFormat])
 	}
 
+	switch schemaChangePolicy(details.Opts[optSchemaChangePolicy]) {
+	case ``, optSchemaChangePolicyBackfill:
+		details.Opts[optSchemaChangePolicy] = string(optSchemaChangePolicyBackfill)
+	case optSchemaChangePolicyNoBackfill, optSchemaChangePolicyStop:
+		// No-op.
+	case optSchemaChangePolicyPause:
+		if details.SinkURI == `` {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`%s=%s requires a sink`, optSchemaChangePolicy, optSchemaChangePolicyPause)
+		}
+	default:
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`unknown %s: %s`, optSchemaChangePolicy, details.Opts[optSchemaChangePolicy])
+	}
+
 	return details, nil
 }
 
@@ -429,6 +519,18 @@ func (b *changefeedResumer) Resume(
 		}
 		continue
 	}
+	if isSchemaChangeBoundaryError(err) {
+		policy := schemaChangePolicy(details.Opts[optSchemaChangePolicy])
+		log.Infof(ctx, `CHANGEFEED job %d stopping due to %s=%s: %v`,
+			*job.ID(), optSchemaChangePolicy, policy, err)
+		err = errors.Wrapf(err, `%s=%s`, optSchemaChangePolicy, policy)
+		if policy == optSchemaChangePolicyPause {
+			// The high-water has been checkpointed at the schema change, so the
+			// job picks up from there (without a backfill) when it's resumed.
+			return jobs.NewPauseJobError(err.Error())
+		}
+		return err
+	}
 	if err != nil {
 		log.Infof(ctx, `CHANGEFEED job %d returning with error: %v`, *job.ID(), err)
 	}
@@ -11,6 +11,7 @@ package changefeedccl
 import (
 	"context"
 	gosql "database/sql"
+	"database/sql/driver"
 	gojson "encoding/json"
 	"fmt"
 	"net/url"
@@ -38,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
@@ -101,6 +103,35 @@ func TestChangefeedEnvelope(t *testing.T) {
 			defer foo.Close(t)
 			assertPayloads(t, foo, []string{`foo: [1]->`})
 		})
+		t.Run(`filter guards projection`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE bar (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO bar VALUES (0), (2)`)
+			bar := f.Feed(t, `CREATE CHANGEFEED AS SELECT 10 // a AS q FROM bar WHERE a <> 0`)
+			defer bar.Close(t)
+			assertPayloads(t, bar, []string{`bar: [2]->{"q": 5}`})
+			sqlDB.Exec(t, `UPSERT INTO bar VALUES (0), (5)`)
+			assertPayloads(t, bar, []string{`bar: [5]->{"q": 2}`})
+		})
+		t.Run(`envelope=diff`, func(t *testing.T) {
+			foo := f.Feed(t, `CREATE CHANGEFEED FOR foo WITH envelope='diff'`)
+			defer foo.Close(t)
+			// The initial scan has no previous values.
+			assertPayloads(t, foo, []string{
+				`foo: [1]->{"after": {"a": 1, "b": "a"}, "before": null}`,
+			})
+			sqlDB.Exec(t, `UPDATE foo SET b = 'b' WHERE a = 1`)
+			assertPayloads(t, foo, []string{
+				`foo: [1]->{"after": {"a": 1, "b": "b"}, "before": {"a": 1, "b": "a"}}`,
+			})
+			sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'c')`)
+			assertPayloads(t, foo, []string{
+				`foo: [2]->{"after": {"a": 2, "b": "c"}, "before": null}`,
+			})
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+			assertPayloads(t, foo, []string{
+				`foo: [1]->{"after": null, "before": {"a": 1, "b": "b"}}`,
+			})
+		})
 	}
 
 	t.Run(`sinkless`, sinklessTest(testFn))
@@ -108,6 +139,106 @@ func TestChangefeedEnvelope(t *testing.T) {
 	t.Run(`rangefeed`, rangefeedTest(sinklessTest, testFn))
 }
 
+func TestChangefeedSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f testfeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a', 10), (2, 'b', 20)`)
+
+		t.Run(`filter and project`, func(t *testing.T) {
+			foo := f.Feed(t, `CREATE CHANGEFEED AS SELECT b, c * 2 AS d FROM foo WHERE c > 10`)
+			defer foo.Close(t)
+			assertPayloads(t, foo, []string{`foo: [2]->{"b": "b", "d": 40}`})
+			sqlDB.Exec(t, `UPSERT INTO foo VALUES (1, 'c', 5), (3, 'd', 30)`)
+			assertPayloads(t, foo, []string{`foo: [3]->{"b": "d", "d": 60}`})
+			// Deletions are filtered on the previous value of the row.
+			sqlDB.Exec(t, `INSERT INTO foo VALUES (4, 'e', 1)`)
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 4`)
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 3`)
+			assertPayloads(t, foo, []string{`foo: [3]->`})
+		})
+		t.Run(`envelope=diff`, func(t *testing.T) {
+			foo := f.Feed(t,
+				`CREATE CHANGEFEED WITH envelope='diff' AS SELECT f.b FROM foo AS f WHERE c > 10`)
+			defer foo.Close(t)
+			assertPayloads(t, foo, []string{`foo: [2]->{"after": {"b": "b"}, "before": null}`})
+			// A row that stops or starts matching is emitted, with only the
+			// matching side projected.
+			sqlDB.Exec(t, `UPDATE foo SET c = 5 WHERE a = 2`)
+			assertPayloads(t, foo, []string{`foo: [2]->{"after": null, "before": {"b": "b"}}`})
+			sqlDB.Exec(t, `UPDATE foo SET b = 'e' WHERE a = 2`)
+			sqlDB.Exec(t, `UPDATE foo SET c = 50 WHERE a = 1`)
+			assertPayloads(t, foo, []string{`foo: [1]->{"after": {"b": "c"}, "before": null}`})
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+			assertPayloads(t, foo, []string{`foo: [1]->{"after": null, "before": {"b": "c"}}`})
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
+// TestChangefeedCore covers changefeeds without a sink, which stream their
+// changes back over the SQL connection that started them.
+func TestChangefeedCore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f testfeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'initial')`)
+
+		t.Run(`cursor`, func(t *testing.T) {
+			foo := f.Feed(t, `EXPERIMENTAL CHANGEFEED FOR foo WITH resolved`)
+			assertPayloads(t, foo, []string{`foo: [0]->{"a": 0, "b": "initial"}`})
+			resolved := expectResolvedTimestamp(t, foo)
+			foo.Close(t)
+
+			// Changes made while the changefeed wasn't running are emitted once it
+			// is resumed from a resolved timestamp.
+			sqlDB.Exec(t, `UPSERT INTO foo VALUES (0, 'updated'), (1, 'a')`)
+			fooResumed := f.Feed(t,
+				`EXPERIMENTAL CHANGEFEED FOR foo WITH cursor=$1`, resolved.AsOfSystemTime())
+			defer fooResumed.Close(t)
+			assertPayloads(t, fooResumed, []string{
+				`foo: [0]->{"a": 0, "b": "updated"}`,
+				`foo: [1]->{"a": 1, "b": "a"}`,
+			})
+		})
+
+		t.Run(`connection closed`, func(t *testing.T) {
+			pgURL, cleanup := sqlutils.PGUrl(
+				t, f.Server().ServingAddr(), t.Name(), url.User(security.RootUser))
+			defer cleanup()
+			pgURL.Path = `d`
+			conn, err := pq.Open(pgURL.String())
+			require.NoError(t, err)
+			rows, err := conn.(driver.Queryer).Query(`EXPERIMENTAL CHANGEFEED FOR foo`, nil)
+			require.NoError(t, err)
+			require.Equal(t, []string{`table`, `key`, `value`}, rows.Columns())
+			require.NoError(t, rows.Next(make([]driver.Value, len(rows.Columns()))))
+
+			// The changefeed never ends on its own, but it stops when the client
+			// goes away.
+			require.NoError(t, conn.Close())
+			testutils.SucceedsSoon(t, func() error {
+				var running int
+				sqlDB.QueryRow(t,
+					`SELECT count(*) FROM [SHOW QUERIES] WHERE query LIKE 'CREATE CHANGEFEED%'`,
+				).Scan(&running)
+				if running != 0 {
+					return errors.Errorf(`%d changefeeds still running`, running)
+				}
+				return nil
+			})
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+}
+
 func TestChangefeedMultiTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -707,6 +838,101 @@ func TestChangefeedSchemaChangeAllowBackfill(t *testing.T) {
 	t.Run(`rangefeed`, rangefeedTest(sinklessTest, testFn))
 }
 
+// Test schema changes that require a backfill with each schema_change_policy
+// that avoids the backfill.
+func TestChangefeedSchemaChangePolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f testfeedFactory) {
+		// HACK: remove this once #32495 is fixed.
+		maybeWaitForEpochLeases(t, f.Server())
+
+		sqlDB := sqlutils.MakeSQLRunner(db)
+
+		t.Run(`nobackfill`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE nobackfill (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO nobackfill VALUES (1)`)
+			nobackfill := f.Feed(t,
+				`CREATE CHANGEFEED FOR nobackfill WITH schema_change_policy=nobackfill`)
+			defer nobackfill.Close(t)
+			assertPayloads(t, nobackfill, []string{
+				`nobackfill: [1]->{"a": 1}`,
+			})
+			sqlDB.Exec(t, `ALTER TABLE nobackfill ADD COLUMN b STRING DEFAULT 'd'`)
+			sqlDB.Exec(t, `INSERT INTO nobackfill VALUES (2, '2')`)
+			// Row 1 is not re-emitted with the new column.
+			assertPayloads(t, nobackfill, []string{
+				`nobackfill: [2]->{"a": 2, "b": "2"}`,
+			})
+		})
+
+		t.Run(`stop`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE stop_policy (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO stop_policy VALUES (1)`)
+			stopPolicy := f.Feed(t,
+				`CREATE CHANGEFEED FOR stop_policy WITH schema_change_policy=stop`)
+			defer stopPolicy.Close(t)
+			assertPayloads(t, stopPolicy, []string{
+				`stop_policy: [1]->{"a": 1}`,
+			})
+			sqlDB.Exec(t, `ALTER TABLE stop_policy ADD COLUMN b STRING DEFAULT 'd'`)
+			sqlDB.Exec(t, `INSERT INTO stop_policy VALUES (2, '2')`)
+			// The changefeed stops at the schema change, before row 2.
+			skipResolvedTimestamps(t, stopPolicy)
+			if err := stopPolicy.Err(); !testutils.IsError(err, `schema change boundary`) {
+				t.Fatalf(`expected "schema change boundary" error got: %+v`, err)
+			}
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`rangefeed`, rangefeedTest(sinklessTest, testFn))
+}
+
+func TestChangefeedSchemaChangePolicyPause(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(i time.Duration) { jobs.DefaultAdoptInterval = i }(jobs.DefaultAdoptInterval)
+	jobs.DefaultAdoptInterval = 10 * time.Millisecond
+
+	testFn := func(t *testing.T, db *gosql.DB, f testfeedFactory) {
+		// HACK: remove this once #32495 is fixed.
+		maybeWaitForEpochLeases(t, f.Server())
+
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+		foo := f.Feed(t, `CREATE CHANGEFEED FOR foo WITH schema_change_policy=pause`).(*tableFeed)
+		defer foo.Close(t)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"a": 1}`,
+		})
+
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN b STRING DEFAULT 'd'`)
+		testutils.SucceedsSoon(t, func() error {
+			var status string
+			sqlDB.QueryRow(t, `SELECT status FROM [SHOW JOBS] WHERE job_id = $1`, foo.jobID).Scan(&status)
+			if jobs.Status(status) != jobs.StatusPaused {
+				return errors.Errorf(`expected job to be paused got %s`, status)
+			}
+			return nil
+		})
+
+		// When resumed, the changefeed continues from the schema change without
+		// a backfill.
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, '2')`)
+		sqlDB.Exec(t, `RESUME JOB $1`, foo.jobID)
+		assertPayloads(t, foo, []string{
+			`foo: [2]->{"a": 2, "b": "2"}`,
+		})
+	}
+
+	// Only the enterprise version uses jobs.
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`rangefeed`, rangefeedTest(enterpriseTest, testFn))
+}
+
 func TestChangefeedInterleaved(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1297,10 +1523,6 @@ func TestChangefeedErrors(t *testing.T) {
 		`CREATE CHANGEFEED FOR foo WITH format=nope`,
 	)
 
-	sqlDB.ExpectErr(
-		t, `envelope=diff is not yet supported`,
-		`CREATE CHANGEFEED FOR foo WITH envelope=diff`,
-	)
 	sqlDB.ExpectErr(
 		t, `unknown envelope: nope`,
 		`CREATE CHANGEFEED FOR foo WITH envelope=nope`,
@@ -1392,11 +1614,52 @@ func TestChangefeedErrors(t *testing.T) {
 		`CREATE CHANGEFEED FOR foo INTO $1`, `kafka://nope/?kafka_topic_prefix=foo`,
 	)
 
-	// schema_topic will be implemented but isn't yet.
+	// schema_topic is accepted by the kafka sink, but no other.
 	sqlDB.ExpectErr(
-		t, `schema_topic is not yet supported`,
+		t, `client has run out of available brokers`,
 		`CREATE CHANGEFEED FOR foo INTO $1`, `kafka://nope/?schema_topic=foo`,
 	)
+	sqlDB.ExpectErr(
+		t, `unknown sink query parameter: schema_topic`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `experimental-sql://d/?schema_topic=foo`,
+	)
+
+	// Check webhook sink parameters.
+	sqlDB.ExpectErr(
+		t, `batch_size must be positive: 0`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `webhook-https://nope/?batch_size=0`,
+	)
+	sqlDB.ExpectErr(
+		t, `unknown sink query parameter: topic_prefix`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `webhook-https://nope/?topic_prefix=foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `format=experimental_avro is not yet supported by the webhook-https sink`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format=$2`, `webhook-https://nope/`, optFormatAvro,
+	)
+
+	// Check changefeed queries.
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED query must select from exactly one table`,
+		`CREATE CHANGEFEED AS SELECT * FROM foo, foo AS bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `column "nope" does not exist`,
+		`CREATE CHANGEFEED AS SELECT nope FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `impure functions are not allowed in CHANGEFEED`,
+		`CREATE CHANGEFEED AS SELECT a, now() FROM foo`,
+	)
+
+	sqlDB.ExpectErr(
+		t, `unknown schema_change_policy: nope`,
+		`CREATE CHANGEFEED FOR foo WITH schema_change_policy=nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `schema_change_policy=pause requires a sink`,
+		`CREATE CHANGEFEED FOR foo WITH schema_change_policy=pause`,
+	)
 }
 
 func TestChangefeedPermissions(t *testing.T) {
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/linkedin/goavro"
 	"github.com/pkg/errors"
 )
 
@@ -39,14 +40,21 @@ type Encoder interface {
 	// `TableDescriptor`, but only the primary key fields will be used. The
 	// returned bytes are only valid until the next call to Encode*.
 	EncodeKey(*sqlbase.TableDescriptor, sqlbase.EncDatumRow) ([]byte, error)
-	// EncodeKey encodes the primary key of the given row. The columns of the
-	// row are expected to match 1:1 with the `Columns` field of the
-	// `TableDescriptor`. The returned bytes are only valid until the next call
-	// to Encode*.
-	EncodeValue(*sqlbase.TableDescriptor, sqlbase.EncDatumRow, hlc.Timestamp) ([]byte, error)
+	// EncodeValue encodes the given row. The columns of the row are expected to
+	// match 1:1 with the `Columns` field of the `TableDescriptor`. With
+	// envelope=diff, the encoded value also contains the previous value of the
+	// row, which is passed as prevRow; either row may be nil if the row didn't
+	// exist on that side of the change. Otherwise, prevRow is ignored. The
+	// returned bytes are only valid until the next call to Encode*.
+	EncodeValue(
+		tableDesc *sqlbase.TableDescriptor, row, prevRow sqlbase.EncDatumRow, updated hlc.Timestamp,
+	) ([]byte, error)
 	// EncodeKey encodes a resolved timestamp payload. The returned bytes are
 	// only valid until the next call to Encode*.
 	EncodeResolvedTimestamp(hlc.Timestamp) ([]byte, error)
+	// EncodeSchemaChange encodes a schema change event for the schema topic.
+	// The returned bytes are only valid until the next call to Encode*.
+	EncodeSchemaChange(schemaChangeEvent) ([]byte, error)
 }
 
 func getEncoder(opts map[string]string) (Encoder, error) {
@@ -62,8 +70,10 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 
 // jsonEncoder encodes changefeed entries as JSON. Keys are the primary key
 // columns in a JSON array. Values are a JSON object mapping every column name
-// to its value. Updated timestamps in rows and resolved timestamp payloads are
-// stored in a sub-object under the `__crdb__` key in the top-level JSON object.
+// to its value or, with envelope=diff, a JSON object with such an object (or
+// null) under each of the `before` and `after` keys. Updated timestamps in rows
+// and resolved timestamp payloads are stored in a sub-object under the
+// `__crdb__` key in the top-level JSON object.
 type jsonEncoder struct {
 	opts map[string]string
 
@@ -109,25 +119,30 @@ func (e *jsonEncoder) EncodeKey(
 
 // EncodeValue implements the Encoder interface.
 func (e *jsonEncoder) EncodeValue(
-	tableDesc *sqlbase.TableDescriptor, row sqlbase.EncDatumRow, updated hlc.Timestamp,
+	tableDesc *sqlbase.TableDescriptor, row, prevRow sqlbase.EncDatumRow, updated hlc.Timestamp,
 ) ([]byte, error) {
-	columns := tableDesc.Columns
-	jsonEntries := make(map[string]interface{}, len(columns))
-	if _, ok := e.opts[optUpdatedTimestamps]; ok {
-		jsonEntries[jsonMetaSentinel] = map[string]interface{}{
-			`updated`: tree.TimestampToDecimal(updated).Decimal.String(),
-		}
-	}
-	for i, col := range columns {
-		datum := row[i]
-		if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
+	var jsonEntries map[string]interface{}
+	if envelopeType(e.opts[optEnvelope]) == optEnvelopeDiff {
+		before, err := e.rowAsGoNative(tableDesc, prevRow)
+		if err != nil {
 			return nil, err
 		}
-		var err error
-		jsonEntries[col.Name], err = tree.AsJSON(datum.Datum)
+		after, err := e.rowAsGoNative(tableDesc, row)
 		if err != nil {
 			return nil, err
 		}
+		jsonEntries = map[string]interface{}{`before`: before, `after`: after}
+	} else {
+		columns := tableDesc.Columns
+		jsonEntries = make(map[string]interface{}, len(columns)+1)
+		if err := e.addRowColumns(jsonEntries, tableDesc, row); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := e.opts[optUpdatedTimestamps]; ok {
+		jsonEntries[jsonMetaSentinel] = map[string]interface{}{
+			`updated`: tree.TimestampToDecimal(updated).Decimal.String(),
+		}
 	}
 	j, err := json.MakeJSON(jsonEntries)
 	if err != nil {
@@ -138,6 +153,40 @@ func (e *jsonEncoder) EncodeValue(
 	return e.buf.Bytes(), nil
 }
 
+// rowAsGoNative returns the row as a map from column name to JSON value, or nil
+// if the row is nil.
+func (e *jsonEncoder) rowAsGoNative(
+	tableDesc *sqlbase.TableDescriptor, row sqlbase.EncDatumRow,
+) (interface{}, error) {
+	if row == nil {
+		return nil, nil
+	}
+	jsonEntries := make(map[string]interface{}, len(tableDesc.Columns))
+	if err := e.addRowColumns(jsonEntries, tableDesc, row); err != nil {
+		return nil, err
+	}
+	return jsonEntries, nil
+}
+
+// addRowColumns adds the JSON value of every column of the row to jsonEntries,
+// keyed by column name.
+func (e *jsonEncoder) addRowColumns(
+	jsonEntries map[string]interface{}, tableDesc *sqlbase.TableDescriptor, row sqlbase.EncDatumRow,
+) error {
+	for i, col := range tableDesc.Columns {
+		datum := row[i]
+		if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
+			return err
+		}
+		var err error
+		jsonEntries[col.Name], err = tree.AsJSON(datum.Datum)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // EncodeResolvedTimestamp implements the Encoder interface.
 func (e *jsonEncoder) EncodeResolvedTimestamp(resolved hlc.Timestamp) ([]byte, error) {
 	resolvedMetaRaw := map[string]interface{}{
@@ -148,14 +197,58 @@ func (e *jsonEncoder) EncodeResolvedTimestamp(resolved hlc.Timestamp) ([]byte, e
 	return gojson.Marshal(resolvedMetaRaw)
 }
 
+// EncodeSchemaChange implements the Encoder interface. The event is a JSON
+// object with the table's name, ID and new descriptor version, and the columns
+// that were added, dropped and altered (each with its name and SQL type, plus
+// the previous type for altered columns). The timestamp at which the change
+// took effect is stored under the `__crdb__` key, as in row values.
+func (e *jsonEncoder) EncodeSchemaChange(sc schemaChangeEvent) ([]byte, error) {
+	columns := func(cols []schemaChangeColumn) []interface{} {
+		jsonCols := make([]interface{}, len(cols))
+		for i, col := range cols {
+			jsonCol := map[string]interface{}{`name`: col.name, `type`: col.typ}
+			if col.prevTyp != `` {
+				jsonCol[`previous_type`] = col.prevTyp
+			}
+			jsonCols[i] = jsonCol
+		}
+		return jsonCols
+	}
+	jsonEntries := map[string]interface{}{
+		`table`:           sc.tableDesc.Name,
+		`table_id`:        int64(sc.tableDesc.ID),
+		`version`:         int64(sc.tableDesc.Version),
+		`columns_added`:   columns(sc.added),
+		`columns_dropped`: columns(sc.dropped),
+		`columns_altered`: columns(sc.altered),
+		jsonMetaSentinel: map[string]interface{}{
+			`updated`: tree.TimestampToDecimal(sc.updated).Decimal.String(),
+		},
+	}
+	j, err := json.MakeJSON(jsonEntries)
+	if err != nil {
+		return nil, err
+	}
+	e.buf.Reset()
+	j.Format(&e.buf)
+	return e.buf.Bytes(), nil
+}
+
 // confluentAvroEncoder encodes changefeed entries as Avro's binary or textual
 // JSON format. Keys are the primary key columns in a record. Values are all
-// columns in a record.
+// columns in a record or, with envelope=diff, a record with such a record (or
+// null) in each of the `before` and `after` fields.
 type confluentAvroEncoder struct {
 	registryURL string
+	envelope    envelopeType
 
 	keyCache   map[tableIDAndVersion]confluentRegisteredSchema
 	valueCache map[tableIDAndVersion]confluentRegisteredSchema
+
+	// schemaChangeCodec and schemaChangeRegistryID are set once the schema of
+	// schema change events has been registered.
+	schemaChangeCodec      *goavro.Codec
+	schemaChangeRegistryID int32
 }
 
 type tableIDAndVersion uint64
@@ -165,7 +258,9 @@ func makeTableIDAndVersion(id sqlbase.ID, version sqlbase.DescriptorVersion) tab
 }
 
 type confluentRegisteredSchema struct {
-	schema     *avroSchemaRecord
+	schema *avroSchemaRecord
+	// envelope, if non-nil, wraps schema and is the registered schema.
+	envelope   *avroEnvelopeRecord
 	registryID int32
 }
 
@@ -187,6 +282,7 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 	}
 	e := &confluentAvroEncoder{
 		registryURL: registryURL,
+		envelope:    envelopeType(opts[optEnvelope]),
 		keyCache:    make(map[tableIDAndVersion]confluentRegisteredSchema),
 		valueCache:  make(map[tableIDAndVersion]confluentRegisteredSchema),
 	}
@@ -207,7 +303,8 @@ func (e *confluentAvroEncoder) EncodeKey(
 			return nil, err
 		}
 
-		registered.registryID, err = e.register(registered.schema, confluentSubjectSuffixKey)
+		registered.registryID, err = e.register(
+			registered.schema.codec.Schema(), registered.schema.Name+confluentSubjectSuffixKey)
 		if err != nil {
 			return nil, err
 		}
@@ -226,7 +323,7 @@ func (e *confluentAvroEncoder) EncodeKey(
 
 // EncodeValue implements the Encoder interface.
 func (e *confluentAvroEncoder) EncodeValue(
-	tableDesc *sqlbase.TableDescriptor, row sqlbase.EncDatumRow, _ hlc.Timestamp,
+	tableDesc *sqlbase.TableDescriptor, row, prevRow sqlbase.EncDatumRow, _ hlc.Timestamp,
 ) ([]byte, error) {
 	cacheKey := makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)
 	registered, ok := e.valueCache[cacheKey]
@@ -236,8 +333,17 @@ func (e *confluentAvroEncoder) EncodeValue(
 		if err != nil {
 			return nil, err
 		}
+		schemaJSON := registered.schema.codec.Schema()
+		if e.envelope == optEnvelopeDiff {
+			registered.envelope, err = envelopeToAvroSchema(registered.schema)
+			if err != nil {
+				return nil, err
+			}
+			schemaJSON = registered.envelope.codec.Schema()
+		}
 
-		registered.registryID, err = e.register(registered.schema, confluentSubjectSuffixValue)
+		registered.registryID, err = e.register(
+			schemaJSON, registered.schema.Name+confluentSubjectSuffixValue)
 		if err != nil {
 			return nil, err
 		}
@@ -250,6 +356,9 @@ func (e *confluentAvroEncoder) EncodeValue(
 		0, 0, 0, 0, // Placeholder for the ID.
 	}
 	binary.BigEndian.PutUint32(header[1:5], uint32(registered.registryID))
+	if registered.envelope != nil {
+		return registered.envelope.BinaryFromRows(header, prevRow, row)
+	}
 	return registered.schema.BinaryFromRow(header, row)
 }
 
@@ -258,9 +367,34 @@ func (e *confluentAvroEncoder) EncodeResolvedTimestamp(resolved hlc.Timestamp) (
 	panic(`unimplemented`)
 }
 
-func (e *confluentAvroEncoder) register(
-	schema *avroSchemaRecord, subjectSuffix string,
-) (int32, error) {
+// EncodeSchemaChange implements the Encoder interface. The event is encoded
+// with avroSchemaChangeSchema, which is registered under the
+// `schema_change-value` subject.
+func (e *confluentAvroEncoder) EncodeSchemaChange(sc schemaChangeEvent) ([]byte, error) {
+	if e.schemaChangeCodec == nil {
+		codec, err := goavro.NewCodec(avroSchemaChangeSchema)
+		if err != nil {
+			return nil, err
+		}
+		registryID, err := e.register(
+			codec.Schema(), avroSchemaChangeName+confluentSubjectSuffixValue)
+		if err != nil {
+			return nil, err
+		}
+		e.schemaChangeCodec, e.schemaChangeRegistryID = codec, registryID
+	}
+	// https://docs.confluent.io/current/schema-registry/docs/serializer-formatter.html#wire-format
+	header := []byte{
+		confluentAvroWireFormatMagic,
+		0, 0, 0, 0, // Placeholder for the ID.
+	}
+	binary.BigEndian.PutUint32(header[1:5], uint32(e.schemaChangeRegistryID))
+	return e.schemaChangeCodec.BinaryFromNative(header, nativeFromSchemaChange(sc))
+}
+
+// register registers the given JSON avro schema under the given subject and
+// returns its id in the schema registry.
+func (e *confluentAvroEncoder) register(schema string, subject string) (int32, error) {
 	type confluentSchemaVersionRequest struct {
 		Schema string `json:"schema"`
 	}
@@ -272,10 +406,9 @@ func (e *confluentAvroEncoder) register(
 	if err != nil {
 		return 0, err
 	}
-	subject := schema.Name + subjectSuffix
 	url.Path = filepath.Join(url.EscapedPath(), `subjects`, subject, `versions`)
 
-	req := confluentSchemaVersionRequest{Schema: schema.codec.Schema()}
+	req := confluentSchemaVersionRequest{Schema: schema}
 	var buf bytes.Buffer
 	if err := gojson.NewEncoder(&buf).Encode(req); err != nil {
 		return 0, err
@@ -60,10 +60,13 @@ func makeBenchSink() *benchSink {
 	return s
 }
 
-func (s *benchSink) EmitRow(ctx context.Context, _ string, k, v []byte) error {
+func (s *benchSink) EmitRow(ctx context.Context, _ string, k, v []byte, _ hlc.Timestamp) error {
 	return s.emit(int64(len(k) + len(v)))
 }
-func (s *benchSink) EmitResolvedTimestamp(_ context.Context, p []byte) error {
+func (s *benchSink) EmitResolvedTimestamp(_ context.Context, p []byte, _ hlc.Timestamp) error {
+	return s.emit(int64(len(p)))
+}
+func (s *benchSink) EmitSchemaChange(_ context.Context, _ string, p []byte, _ hlc.Timestamp) error {
 	return s.emit(int64(len(p)))
 }
 func (s *benchSink) Flush(_ context.Context) error { return nil }
@@ -731,30 +734,16 @@ func sinklessTest(testFn func(*testing.T, *gosql.DB, testfeedFactory)) func(*tes
 		ctx := context.Background()
 		knobs := base.TestingKnobs{DistSQL: &distsqlrun.TestingKnobs{Changefeed: &TestingKnobs{}}}
 		s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
-			Knobs: knobs,
+			UseDatabase: "d",
+			Knobs:       knobs,
 		})
 		defer s.Stopper().Stop(ctx)
 		sqlDB := sqlutils.MakeSQLRunner(db)
 		sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.experimental_poll_interval = '10ms'`)
-		// TODO(dan): HACK until the changefeed can control pgwire flushing.
-		sqlDB.Exec(t, `SET CLUSTER SETTING sql.defaults.results_buffer.size = '0'`)
 		sqlDB.Exec(t, `CREATE DATABASE d`)
+		f := makeSinkless(s, db)
 
-		// Now that we've updated sql.defaults.results_buffer.size, open a new
-		// conn pool so that connections use the new setting.
-		pgURL, cleanupFunc := sqlutils.PGUrl(
-			t, s.ServingAddr(), "sinklessTest" /* prefix */, url.User(security.RootUser),
-		)
-		defer cleanupFunc()
-		pgURL.Path = "d"
-		noBufferDB, err := gosql.Open("postgres", pgURL.String())
-		if err != nil {
-			t.Fatal(err)
-		}
-		defer noBufferDB.Close()
-
-		f := makeSinkless(s, noBufferDB)
-		testFn(t, noBufferDB, f)
+		testFn(t, db, f)
 	}
 }
 
@@ -33,9 +33,11 @@ func makeMetricsSink(metrics *Metrics, s Sink) *metricsSink {
 	return m
 }
 
-func (s *metricsSink) EmitRow(ctx context.Context, topic string, key, value []byte) error {
+func (s *metricsSink) EmitRow(
+	ctx context.Context, topic string, key, value []byte, updated hlc.Timestamp,
+) error {
 	start := timeutil.Now()
-	err := s.wrapped.EmitRow(ctx, topic, key, value)
+	err := s.wrapped.EmitRow(ctx, topic, key, value, updated)
 	if err == nil {
 		s.metrics.EmittedMessages.Inc(1)
 		s.metrics.EmittedBytes.Inc(int64(len(key) + len(value)))
@@ -44,9 +46,24 @@ func (s *metricsSink) EmitRow(ctx context.Context, topic string, key, value []by
 	return err
 }
 
-func (s *metricsSink) EmitResolvedTimestamp(ctx context.Context, payload []byte) error {
+func (s *metricsSink) EmitResolvedTimestamp(
+	ctx context.Context, payload []byte, resolved hlc.Timestamp,
+) error {
 	start := timeutil.Now()
-	err := s.wrapped.EmitResolvedTimestamp(ctx, payload)
+	err := s.wrapped.EmitResolvedTimestamp(ctx, payload, resolved)
+	if err == nil {
+		s.metrics.EmittedMessages.Inc(1)
+		s.metrics.EmittedBytes.Inc(int64(len(payload)))
+		s.metrics.EmitNanos.Inc(timeutil.Since(start).Nanoseconds())
+	}
+	return err
+}
+
+func (s *metricsSink) EmitSchemaChange(
+	ctx context.Context, table string, payload []byte, updated hlc.Timestamp,
+) error {
+	start := timeutil.Now()
+	err := s.wrapped.EmitSchemaChange(ctx, table, payload, updated)
 	if err == nil {
 		s.metrics.EmittedMessages.Inc(1)
 		s.metrics.EmittedBytes.Inc(int64(len(payload)))
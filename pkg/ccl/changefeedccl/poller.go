@@ -52,6 +52,16 @@ type poller struct {
 	tableHist *tableHistory
 	leaseMgr  *sql.LeaseManager
 	metrics   *Metrics
+	// withDiff is set for envelope=diff, or when the changefeed's query has a
+	// WHERE clause, in which case every changed kv is accompanied by the value
+	// of its key just before the change.
+	withDiff bool
+	// schemaChangePolicy determines what happens when a schema change that
+	// requires a backfill completes.
+	schemaChangePolicy schemaChangePolicy
+	// emitSchemaChanges is set if the sink has a schema topic, in which case
+	// changes to the columns of the watched tables are added to the buffer.
+	emitSchemaChanges bool
 
 	mu struct {
 		syncutil.Mutex
@@ -64,6 +74,11 @@ type poller struct {
 		// a backfilling schema change is marked as completed. This collection must
 		// be kept in sorted order (by timestamp ascending).
 		scanBoundaries []hlc.Timestamp
+		// exitBoundary, if set, is the scan boundary of the first schema change
+		// that requires a backfill when the schema_change_policy is stop or
+		// pause. Instead of performing a scan when it is reached, the poller
+		// marks it in the resolved spans it emits and stops.
+		exitBoundary hlc.Timestamp
 		// previousTableVersion is a map from tableID to the most recent version
 		// of the table descriptor seen by the poller. This is needed to determine
 		// when a backilling mutation has successfully completed - this can only
@@ -95,6 +110,11 @@ func makePoller(
 		buf:      buf,
 		leaseMgr: leaseMgr,
 		metrics:  metrics,
+		withDiff: envelopeType(details.Opts[optEnvelope]) == optEnvelopeDiff ||
+			changefeedSelectHasFilter(details),
+
+		schemaChangePolicy: schemaChangePolicy(details.Opts[optSchemaChangePolicy]),
+		emitSchemaChanges:  sinkSchemaTopic(details.SinkURI) != ``,
 	}
 	p.mu.previousTableVersion = make(map[sqlbase.ID]*sqlbase.TableDescriptor)
 	// If no highWater is specified, set the highwater to the statement time
@@ -124,6 +144,12 @@ func (p *poller) Run(ctx context.Context) error {
 		// Wait for polling interval
 		p.mu.Lock()
 		lastHighwater := p.mu.highWater
+		if p.isExitBoundaryLocked(lastHighwater) {
+			// Everything up to the exit boundary has been buffered and the
+			// resolved spans marked; nothing is emitted past it.
+			p.mu.Unlock()
+			return p.waitAtExitBoundary(ctx)
+		}
 		p.mu.Unlock()
 
 		pollDuration := changefeedPollInterval.Get(&p.settings.SV)
@@ -219,6 +245,10 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 		// Full scans are still performed using an Export operation..
 		var scanTime hlc.Timestamp
 		p.mu.Lock()
+		if p.isExitBoundaryLocked(p.mu.highWater) {
+			p.mu.Unlock()
+			return p.waitAtExitBoundary(ctx)
+		}
 		if len(p.mu.scanBoundaries) > 0 && p.mu.scanBoundaries[0].Equal(p.mu.highWater) {
 			// Perform a full scan of the latest value of all keys as of the
 			// boundary timestamp and consume the boundary.
@@ -289,11 +319,24 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 						if pastBoundary {
 							continue
 						}
+						var prevVal roachpb.Value
+						if p.withDiff {
+							var err error
+							if prevVal, err = p.getPrevValue(ctx, t.Key, t.Value.Timestamp); err != nil {
+								return err
+							}
+						}
 						kv := roachpb.KeyValue{Key: t.Key, Value: t.Value}
-						if err := p.buf.AddKV(ctx, kv, hlc.Timestamp{}); err != nil {
+						if err := p.buf.AddKV(ctx, kv, prevVal, hlc.Timestamp{}); err != nil {
 							return err
 						}
 					case *roachpb.RangeFeedCheckpoint:
+						// Schema change events are added to the buffer as table
+						// descriptors are ingested, which must happen before they
+						// are resolved.
+						if err := p.tableHist.WaitForTS(ctx, t.ResolvedTS); err != nil {
+							return err
+						}
 						resolvedTS := t.ResolvedTS
 						boundaryBreak := false
 						p.mu.Lock()
@@ -301,8 +344,9 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 							boundaryBreak = true
 							resolvedTS = p.mu.scanBoundaries[0]
 						}
+						atExitBoundary := p.isExitBoundaryLocked(resolvedTS)
 						p.mu.Unlock()
-						if err := p.buf.AddResolved(ctx, t.Span, resolvedTS); err != nil {
+						if err := p.buf.AddResolved(ctx, t.Span, resolvedTS, atExitBoundary); err != nil {
 							return err
 						}
 						if boundaryBreak {
@@ -465,18 +509,23 @@ func (p *poller) exportSpan(
 	p.metrics.PollRequestNanosHist.RecordValue(exportDuration.Nanoseconds())
 
 	// When outputting a full scan, we want to use the schema at the scan
-	// timestamp, not the schema at the value timestamp.
+	// timestamp, not the schema at the value timestamp. Rows output by a full
+	// scan are not changes, so they have no previous value.
 	var schemaTimestamp hlc.Timestamp
 	if isFullScan {
 		schemaTimestamp = end
 	}
+	withDiff := p.withDiff && !isFullScan
 	stopwatchStart = timeutil.Now()
 	for _, file := range exported.(*roachpb.ExportResponse).Files {
-		if err := p.slurpSST(ctx, file.SST, schemaTimestamp); err != nil {
+		if err := p.slurpSST(ctx, file.SST, schemaTimestamp, withDiff); err != nil {
 			return err
 		}
 	}
-	if err := p.buf.AddResolved(ctx, span, end); err != nil {
+	p.mu.Lock()
+	atExitBoundary := !isFullScan && p.isExitBoundaryLocked(end)
+	p.mu.Unlock()
+	if err := p.buf.AddResolved(ctx, span, end, atExitBoundary); err != nil {
 		return err
 	}
 
@@ -487,6 +536,21 @@ func (p *poller) exportSpan(
 	return nil
 }
 
+// isExitBoundaryLocked returns whether ts is the exit boundary. p.mu must be
+// held.
+func (p *poller) isExitBoundaryLocked(ts hlc.Timestamp) bool {
+	return p.mu.exitBoundary != (hlc.Timestamp{}) && p.mu.exitBoundary.Equal(ts)
+}
+
+// waitAtExitBoundary blocks until the changefeed is shut down, which the
+// changeFrontier does once every watched span has been resolved up to the exit
+// boundary.
+func (p *poller) waitAtExitBoundary(ctx context.Context) error {
+	log.VEventf(ctx, 1, `changefeed reached schema change boundary`)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
 func (p *poller) updateTableHistory(ctx context.Context, endTS hlc.Timestamp) error {
 	startTS := p.tableHist.HighWater()
 	if !startTS.Less(endTS) {
@@ -514,14 +578,30 @@ func (p *poller) pollTableHistory(ctx context.Context) error {
 }
 
 // slurpSST iterates an encoded sst and inserts the contained kvs into the
-// buffer.
-func (p *poller) slurpSST(ctx context.Context, sst []byte, schemaTimestamp hlc.Timestamp) error {
+// buffer. If withDiff is set, each kv is inserted along with the previous value
+// of its key.
+func (p *poller) slurpSST(
+	ctx context.Context, sst []byte, schemaTimestamp hlc.Timestamp, withDiff bool,
+) error {
 	var previousKey roachpb.Key
 	var kvs []roachpb.KeyValue
 	slurpKVs := func() error {
 		sort.Sort(byValueTimestamp(kvs))
-		for _, kv := range kvs {
-			if err := p.buf.AddKV(ctx, kv, schemaTimestamp); err != nil {
+		for i, kv := range kvs {
+			var prevVal roachpb.Value
+			if withDiff {
+				// The sst contains every revision of the key in the polled
+				// interval, so only the first one needs a lookup.
+				if i > 0 {
+					prevVal = kvs[i-1].Value
+				} else {
+					var err error
+					if prevVal, err = p.getPrevValue(ctx, kv.Key, kv.Value.Timestamp); err != nil {
+						return err
+					}
+				}
+			}
+			if err := p.buf.AddKV(ctx, kv, prevVal, schemaTimestamp); err != nil {
 				return err
 			}
 		}
@@ -569,6 +649,25 @@ func (p *poller) slurpSST(ctx context.Context, sst []byte, schemaTimestamp hlc.T
 	return slurpKVs()
 }
 
+// getPrevValue returns the value of the given key just before the given
+// timestamp. The returned value is empty if the key had no value or was
+// deleted.
+func (p *poller) getPrevValue(
+	ctx context.Context, key roachpb.Key, ts hlc.Timestamp,
+) (roachpb.Value, error) {
+	header := roachpb.Header{Timestamp: ts.Prev()}
+	req := &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	resp, pErr := client.SendWrappedWith(ctx, p.db.NonTransactionalSender(), header, req)
+	if pErr != nil {
+		return roachpb.Value{}, errors.Wrapf(
+			pErr.GoError(), `fetching previous value of %s at %s`, key, header.Timestamp)
+	}
+	if v := resp.(*roachpb.GetResponse).Value; v != nil {
+		return *v, nil
+	}
+	return roachpb.Value{}, nil
+}
+
 type byValueTimestamp []roachpb.KeyValue
 
 func (b byValueTimestamp) Len() int      { return len(b) }
@@ -606,26 +705,64 @@ func (p *poller) validateTable(ctx context.Context, desc *sqlbase.TableDescripto
 	if err := validateChangefeedTable(p.details.Targets, desc); err != nil {
 		return err
 	}
+	schemaChange, err := p.updateTableVersion(ctx, desc)
+	if err != nil || schemaChange == nil {
+		return err
+	}
+	// This is called while the table history is ingesting descriptors and
+	// before its high-water advances past the event, so the event is buffered
+	// before any resolved timestamp at or above it.
+	return p.buf.AddSchemaChange(ctx, *schemaChange)
+}
+
+// updateTableVersion records a new version of a watched table and updates the
+// scan boundaries accordingly. It returns the schema change event to emit for
+// the new version, if any.
+func (p *poller) updateTableVersion(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) (*schemaChangeEvent, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	var schemaChange *schemaChangeEvent
 	if lastVersion, ok := p.mu.previousTableVersion[desc.ID]; ok {
 		if desc.ModificationTime.Less(lastVersion.ModificationTime) {
-			return nil
+			return nil, nil
+		}
+		// Only one of the aggregators emits the schema change events for a
+		// table: the one watching the start of its primary index.
+		//
+		// Changes after the exit boundary are not emitted, since the changefeed
+		// stops before them.
+		pastExitBoundary := p.mu.exitBoundary != (hlc.Timestamp{}) &&
+			p.mu.exitBoundary.Less(desc.ModificationTime)
+		if p.emitSchemaChanges && p.mu.highWater.Less(desc.ModificationTime) && !pastExitBoundary &&
+			p.watchesKey(desc.PrimaryIndexSpan().Key) {
+			if e, ok := makeSchemaChangeEvent(lastVersion, desc); ok {
+				schemaChange = &e
+			}
 		}
 		if lastVersion.HasColumnBackfillMutation() && !desc.HasColumnBackfillMutation() {
 			boundaryTime := desc.GetModificationTime()
 			if boundaryTime.Less(p.mu.highWater) {
-				return fmt.Errorf(
+				return nil, fmt.Errorf(
 					"error: detected table ID %d backfill completed at %s earlier than highwater timestamp %s",
 					desc.ID,
 					boundaryTime,
 					p.mu.highWater,
 				)
 			}
-			p.mu.scanBoundaries = append(p.mu.scanBoundaries, boundaryTime)
-			sort.Slice(p.mu.scanBoundaries, func(i, j int) bool {
-				return p.mu.scanBoundaries[i].Less(p.mu.scanBoundaries[j])
-			})
+			switch p.schemaChangePolicy {
+			case optSchemaChangePolicyNoBackfill:
+				log.Infof(ctx, `skipping backfill of table %s at %s due to %s=%s`,
+					desc.Name, boundaryTime, optSchemaChangePolicy, p.schemaChangePolicy)
+			case optSchemaChangePolicyStop, optSchemaChangePolicyPause:
+				if p.mu.exitBoundary == (hlc.Timestamp{}) || boundaryTime.Less(p.mu.exitBoundary) {
+					p.mu.exitBoundary = boundaryTime
+				}
+				p.addScanBoundaryLocked(boundaryTime)
+			default:
+				p.addScanBoundaryLocked(boundaryTime)
+			}
 			// To avoid race conditions with the lease manager, at this point we force
 			// the manager to acquire the freshest descriptor of this table from the
 			// store. In normal operation, the lease manager returns the newest
@@ -634,12 +771,32 @@ func (p *poller) validateTable(ctx context.Context, desc *sqlbase.TableDescripto
 			// return the previous version of the table, which is still technically
 			// allowed by the schema change system.
 			if err := p.leaseMgr.AcquireFreshestFromStore(ctx, desc.ID); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
 	p.mu.previousTableVersion[desc.ID] = desc
-	return nil
+	return schemaChange, nil
+}
+
+// addScanBoundaryLocked adds a scan boundary, keeping them sorted. p.mu must be
+// held.
+func (p *poller) addScanBoundaryLocked(ts hlc.Timestamp) {
+	p.mu.scanBoundaries = append(p.mu.scanBoundaries, ts)
+	sort.Slice(p.mu.scanBoundaries, func(i, j int) bool {
+		return p.mu.scanBoundaries[i].Less(p.mu.scanBoundaries[j])
+	})
+}
+
+// watchesKey returns whether the key is in one of the spans watched by the
+// poller.
+func (p *poller) watchesKey(key roachpb.Key) bool {
+	for _, sp := range p.spans {
+		if sp.ContainsKey(key) {
+			return true
+		}
+	}
+	return false
 }
 
 func fetchSpansForTargets(
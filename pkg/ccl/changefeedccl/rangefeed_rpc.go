@@ -0,0 +1,81 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func init() {
+	server.RangeFeedHook = serveRangeFeed
+}
+
+// rangeFeedBufferSize is the number of rangefeed events buffered between the
+// DistSender and the client stream of the RangeFeed RPC.
+const rangeFeedBufferSize = 128
+
+// serveRangeFeed implements server.RangeFeedHook. Checkpoints from the
+// individual ranges are combined into a span-wide resolved cursor using a
+// spanFrontier, which is only sent when it advances.
+func serveRangeFeed(
+	ctx context.Context,
+	ds *kv.DistSender,
+	span roachpb.Span,
+	cursor hlc.Timestamp,
+	send func(*serverpb.RangeFeedResponse) error,
+) error {
+	frontier := makeSpanFrontier(span)
+	frontier.Forward(span, cursor)
+
+	eventC := make(chan *roachpb.RangeFeedEvent, rangeFeedBufferSize)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		args := &roachpb.RangeFeedRequest{
+			Header: roachpb.Header{Timestamp: cursor},
+			Span:   span,
+		}
+		return ds.RangeFeed(ctx, args, eventC).GoError()
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		for {
+			select {
+			case e := <-eventC:
+				var resp serverpb.RangeFeedResponse
+				switch t := e.GetValue().(type) {
+				case *roachpb.RangeFeedValue:
+					resp.Value = t
+				case *roachpb.RangeFeedCheckpoint:
+					if !frontier.Forward(t.Span, t.ResolvedTS) {
+						continue
+					}
+					resolved := frontier.Frontier()
+					resp.Resolved = &resolved
+				case *roachpb.RangeFeedError:
+					return t.Error.GoError()
+				default:
+					log.Fatalf(ctx, "unexpected RangeFeedEvent variant %v", t)
+				}
+				if err := send(&resp); err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+	return g.Wait()
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// schemaChangeColumn describes a column in a schemaChangeEvent.
+type schemaChangeColumn struct {
+	name string
+	// typ is the SQL type of the column.
+	typ string
+	// prevTyp is the SQL type of the column before the schema change. It is
+	// only set for altered columns.
+	prevTyp string
+}
+
+// schemaChangeEvent is a change to the public columns of a watched table. It is
+// emitted to the schema topic of sinks that have one.
+type schemaChangeEvent struct {
+	tableDesc *sqlbase.TableDescriptor
+	// updated is the timestamp at which the new table descriptor version became
+	// active.
+	updated hlc.Timestamp
+
+	added, dropped, altered []schemaChangeColumn
+}
+
+// makeSchemaChangeEvent compares two versions of a table descriptor and
+// returns the changes to its public columns. The boolean is false if there
+// were none, such as when a mutation is started but not yet finished.
+func makeSchemaChangeEvent(prev, desc *sqlbase.TableDescriptor) (schemaChangeEvent, bool) {
+	e := schemaChangeEvent{tableDesc: desc, updated: desc.ModificationTime}
+	prevCols := make(map[sqlbase.ColumnID]*sqlbase.ColumnDescriptor, len(prev.Columns))
+	for i := range prev.Columns {
+		prevCols[prev.Columns[i].ID] = &prev.Columns[i]
+	}
+	for i := range desc.Columns {
+		col := &desc.Columns[i]
+		prevCol, ok := prevCols[col.ID]
+		if !ok {
+			e.added = append(e.added, schemaChangeColumn{name: col.Name, typ: col.Type.SQLString()})
+			continue
+		}
+		delete(prevCols, col.ID)
+		if !col.Type.Equal(prevCol.Type) {
+			e.altered = append(e.altered, schemaChangeColumn{
+				name: col.Name, typ: col.Type.SQLString(), prevTyp: prevCol.Type.SQLString(),
+			})
+		}
+	}
+	// Iterate prev.Columns rather than the map to keep the order deterministic.
+	for i := range prev.Columns {
+		if _, ok := prevCols[prev.Columns[i].ID]; ok {
+			col := &prev.Columns[i]
+			e.dropped = append(e.dropped, schemaChangeColumn{name: col.Name, typ: col.Type.SQLString()})
+		}
+	}
+	return e, len(e.added) > 0 || len(e.dropped) > 0 || len(e.altered) > 0
+}
+
+// String used to match schemaChangeBoundaryErrors when they have been
+// "flattened" into a pgerror.
+const schemaChangeBoundaryErrorString = "schema change boundary"
+
+// schemaChangeBoundaryError is returned by the changeFrontier when every
+// watched span has been resolved up to a schema change that requires a
+// backfill and the schema_change_policy is stop or pause.
+type schemaChangeBoundaryError struct {
+	ts hlc.Timestamp
+}
+
+func (e *schemaChangeBoundaryError) Error() string {
+	return fmt.Sprintf(schemaChangeBoundaryErrorString+
+		": schema change requiring a backfill occurred at %s", e.ts)
+}
+
+// isSchemaChangeBoundaryError returns true if the supplied error, or any of its
+// parent causes, is a schemaChangeBoundaryError.
+func isSchemaChangeBoundaryError(err error) bool {
+	for {
+		if _, ok := err.(*schemaChangeBoundaryError); ok {
+			return true
+		}
+		if _, ok := err.(*pgerror.Error); ok {
+			return strings.Contains(err.Error(), schemaChangeBoundaryErrorString)
+		}
+		if e, ok := err.(causer); ok {
+			err = e.Cause()
+			continue
+		}
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/linkedin/goavro"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeSchemaChangeEvent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	intType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+	int4Type := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT, Width: 32}
+	stringType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_STRING}
+	makeDesc := func(version sqlbase.DescriptorVersion, cols ...sqlbase.ColumnDescriptor) *sqlbase.TableDescriptor {
+		return &sqlbase.TableDescriptor{
+			ID:               52,
+			Name:             `foo`,
+			Version:          version,
+			ModificationTime: hlc.Timestamp{WallTime: int64(version)},
+			Columns:          cols,
+		}
+	}
+	a := sqlbase.ColumnDescriptor{ID: 1, Name: `a`, Type: intType}
+	b := sqlbase.ColumnDescriptor{ID: 2, Name: `b`, Type: int4Type}
+	bAltered := sqlbase.ColumnDescriptor{ID: 2, Name: `b`, Type: intType}
+	c := sqlbase.ColumnDescriptor{ID: 3, Name: `c`, Type: stringType}
+
+	t.Run(`no change`, func(t *testing.T) {
+		// A new version with a mutation in progress doesn't change the public
+		// columns.
+		_, ok := makeSchemaChangeEvent(makeDesc(1, a, b), makeDesc(2, a, b))
+		require.False(t, ok)
+	})
+
+	t.Run(`add and drop`, func(t *testing.T) {
+		e, ok := makeSchemaChangeEvent(makeDesc(1, a, b), makeDesc(2, a, c))
+		require.True(t, ok)
+		require.Equal(t, hlc.Timestamp{WallTime: 2}, e.updated)
+		require.Equal(t, []schemaChangeColumn{{name: `c`, typ: `STRING`}}, e.added)
+		require.Equal(t, []schemaChangeColumn{{name: `b`, typ: `INT4`}}, e.dropped)
+		require.Empty(t, e.altered)
+	})
+
+	t.Run(`alter`, func(t *testing.T) {
+		e, ok := makeSchemaChangeEvent(makeDesc(1, a, b), makeDesc(2, a, bAltered))
+		require.True(t, ok)
+		require.Empty(t, e.added)
+		require.Empty(t, e.dropped)
+		require.Equal(t, []schemaChangeColumn{{name: `b`, typ: `INT`, prevTyp: `INT4`}}, e.altered)
+	})
+}
+
+func TestEncodeSchemaChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	e := schemaChangeEvent{
+		tableDesc: &sqlbase.TableDescriptor{ID: 52, Name: `foo`, Version: 3},
+		updated:   hlc.Timestamp{WallTime: 1, Logical: 2},
+		added:     []schemaChangeColumn{{name: `c`, typ: `STRING`}},
+		altered:   []schemaChangeColumn{{name: `b`, typ: `INT`, prevTyp: `INT4`}},
+	}
+
+	t.Run(`json`, func(t *testing.T) {
+		encoded, err := makeJSONEncoder(nil).EncodeSchemaChange(e)
+		require.NoError(t, err)
+		require.Equal(t, `{"__crdb__": {"updated": "1.0000000002"}, `+
+			`"columns_added": [{"name": "c", "type": "STRING"}], `+
+			`"columns_altered": [{"name": "b", "previous_type": "INT4", "type": "INT"}], `+
+			`"columns_dropped": [], "table": "foo", "table_id": 52, "version": 3}`,
+			string(encoded))
+	})
+
+	t.Run(`avro`, func(t *testing.T) {
+		reg := makeTestSchemaRegistry()
+		defer reg.Close()
+		opts := map[string]string{optConfluentSchemaRegistry: reg.server.URL}
+		encoder, err := newConfluentAvroEncoder(opts)
+		require.NoError(t, err)
+
+		encoded, err := encoder.EncodeSchemaChange(e)
+		require.NoError(t, err)
+		// The schema is registered once.
+		_, err = encoder.EncodeSchemaChange(e)
+		require.NoError(t, err)
+		reg.mu.Lock()
+		schemas := reg.mu.schemas
+		reg.mu.Unlock()
+		require.Len(t, schemas, 1)
+
+		require.Equal(t, confluentAvroWireFormatMagic, encoded[0])
+		id := int32(binary.BigEndian.Uint32(encoded[1:5]))
+		codec, err := goavro.NewCodec(schemas[id])
+		require.NoError(t, err)
+		native, _, err := codec.NativeFromBinary(encoded[5:])
+		require.NoError(t, err)
+		textual, err := codec.TextualFromNative(nil, native)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"table": "foo", "table_id": 52, "version": 3, "updated": "1.0000000002",
+			"columns_added": [{"name": "c", "type": "STRING", "previous_type": null}],
+			"columns_dropped": [],
+			"columns_altered": [{"name": "b", "type": "INT", "previous_type": {"string": "INT4"}}]
+		}`, string(textual))
+	})
+}
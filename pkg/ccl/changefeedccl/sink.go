@@ -22,10 +22,13 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -34,25 +37,38 @@ import (
 
 // Sink is an abstraction for anything that a changefeed may emit into.
 type Sink interface {
-	// EmitRow enqueues a row message for asynchronous delivery on the sink. An
-	// error may be returned if a previously enqueued message has failed.
-	EmitRow(ctx context.Context, topic string, key, value []byte) error
+	// EmitRow enqueues a row message for asynchronous delivery on the sink. The
+	// updated timestamp is the MVCC timestamp of the change. An error may be
+	// returned if a previously enqueued message has failed.
+	EmitRow(ctx context.Context, topic string, key, value []byte, updated hlc.Timestamp) error
 	// EmitResolvedTimestamp enqueues a resolved timestamp message for
 	// asynchronous delivery on every partition of every topic that has been
-	// seen by EmitRow. The list of partitions used may be stale. An error may
-	// be returned if a previously enqueued message has failed.
-	EmitResolvedTimestamp(ctx context.Context, payload []byte) error
-	// Flush blocks until every message enqueued by EmitRow and
-	// EmitResolvedTimestamp has been acknowledged by the sink. If an error is
-	// returned, no guarantees are given about which messages have been
-	// delivered or not delivered.
+	// seen by EmitRow. The list of partitions used may be stale. The payload is
+	// the encoded form of the resolved timestamp. An error may be returned if
+	// a previously enqueued message has failed.
+	EmitResolvedTimestamp(ctx context.Context, payload []byte, resolved hlc.Timestamp) error
+	// EmitSchemaChange enqueues a message describing a change to the columns
+	// of the given table for asynchronous delivery on the sink's schema topic.
+	// The updated timestamp is the one at which the change took effect. Sinks
+	// without a schema topic ignore it. An error may be returned if a
+	// previously enqueued message has failed.
+	EmitSchemaChange(ctx context.Context, table string, payload []byte, updated hlc.Timestamp) error
+	// Flush blocks until every message enqueued by EmitRow,
+	// EmitResolvedTimestamp and EmitSchemaChange has been acknowledged by the
+	// sink. If an error is returned, no guarantees are given about which
+	// messages have been delivered or not delivered.
 	Flush(ctx context.Context) error
 	// Close does not guarantee delivery of outstanding messages.
 	Close() error
 }
 
 func getSink(
-	sinkURI string, opts map[string]string, targets jobspb.ChangefeedTargets,
+	ctx context.Context,
+	sinkURI string,
+	nodeID roachpb.NodeID,
+	opts map[string]string,
+	targets jobspb.ChangefeedTargets,
+	settings *cluster.Settings,
 ) (Sink, error) {
 	u, err := url.Parse(sinkURI)
 	if err != nil {
@@ -71,11 +87,8 @@ func getSink(
 		q.Del(sinkParamTopicPrefix)
 		schemaTopic := q.Get(sinkParamSchemaTopic)
 		q.Del(sinkParamSchemaTopic)
-		if schemaTopic != `` {
-			return nil, errors.Errorf(`%s is not yet supported`, sinkParamSchemaTopic)
-		}
 		makeSink = func() (Sink, error) {
-			return getKafkaSink(kafkaTopicPrefix, u.Host, targets)
+			return getKafkaSink(kafkaTopicPrefix, schemaTopic, u.Host, targets)
 		}
 	case sinkSchemeExperimentalSQL:
 		// Swap the changefeed prefix for the sql connection one that sqlSink
@@ -92,6 +105,49 @@ func getSink(
 		q.Del(`sslkey`)
 		q.Del(`sslmode`)
 		q.Del(`sslrootcert`)
+	case sinkSchemeS3, sinkSchemeGCS, sinkSchemeAzure, sinkSchemeHTTP, sinkSchemeHTTPS,
+		sinkSchemeNodelocal:
+		if formatType(opts[optFormat]) == optFormatAvro {
+			return nil, errors.Errorf(`%s=%s is not yet supported by the %s sink`,
+				optFormat, optFormatAvro, u.Scheme)
+		}
+		fileSize := int64(defaultCloudStorageFileSize)
+		if s := q.Get(sinkParamFileSize); s != `` {
+			if fileSize, err = humanizeutil.ParseBytes(s); err != nil {
+				return nil, errors.Wrapf(err, `parsing %s`, sinkParamFileSize)
+			}
+			if fileSize <= 0 {
+				return nil, errors.Errorf(`%s must be positive: %s`, sinkParamFileSize, s)
+			}
+		}
+		q.Del(sinkParamFileSize)
+		var flushInterval time.Duration
+		if s := q.Get(sinkParamFlushInterval); s != `` {
+			if flushInterval, err = time.ParseDuration(s); err != nil {
+				return nil, errors.Wrapf(err, `parsing %s`, sinkParamFlushInterval)
+			}
+			if flushInterval < 0 {
+				return nil, errors.Errorf(`%s must not be negative: %s`, sinkParamFlushInterval, s)
+			}
+		}
+		q.Del(sinkParamFlushInterval)
+		// The remaining query parameters are interpreted by the ExportStorage.
+		u.RawQuery = q.Encode()
+		q = url.Values{}
+		makeSink = func() (Sink, error) {
+			return makeCloudStorageSink(
+				ctx, u.String(), nodeID, fileSize, flushInterval, settings, targets)
+		}
+	case sinkSchemeWebhookHTTPS:
+		if formatType(opts[optFormat]) == optFormatAvro {
+			return nil, errors.Errorf(`%s=%s is not yet supported by the %s sink`,
+				optFormat, optFormatAvro, u.Scheme)
+		}
+		cfg, err := makeWebhookSinkConfig(u, q)
+		if err != nil {
+			return nil, err
+		}
+		makeSink = func() (Sink, error) { return makeWebhookSink(cfg, targets), nil }
 	default:
 		return nil, errors.Errorf(`unsupported sink: %s`, u.Scheme)
 	}
@@ -107,6 +163,15 @@ func getSink(
 	return s, nil
 }
 
+// sinkSchemaTopic returns the schema topic configured in the sink URI, if any.
+func sinkSchemaTopic(sinkURI string) string {
+	u, err := url.Parse(sinkURI)
+	if err != nil || u.Scheme != sinkSchemeKafka {
+		return ``
+	}
+	return u.Query().Get(sinkParamSchemaTopic)
+}
+
 // kafkaSink emits to Kafka asynchronously. It is not concurrency-safe; all
 // calls to Emit and Flush should be from the same goroutine.
 type kafkaSink struct {
@@ -115,9 +180,12 @@ type kafkaSink struct {
 	// to add a new c dep for the prototype. Revisit before 2.1 and check
 	// stability, performance, etc.
 	kafkaTopicPrefix string
-	client           sarama.Client
-	producer         sarama.AsyncProducer
-	topics           map[string]struct{}
+	// schemaTopic, if set, is the topic that schema change events are emitted
+	// to. It also receives resolved timestamps.
+	schemaTopic string
+	client      sarama.Client
+	producer    sarama.AsyncProducer
+	topics      map[string]struct{}
 
 	lastMetadataRefresh time.Time
 
@@ -134,15 +202,22 @@ type kafkaSink struct {
 }
 
 func getKafkaSink(
-	kafkaTopicPrefix string, bootstrapServers string, targets jobspb.ChangefeedTargets,
+	kafkaTopicPrefix, schemaTopic string, bootstrapServers string, targets jobspb.ChangefeedTargets,
 ) (Sink, error) {
 	sink := &kafkaSink{
 		kafkaTopicPrefix: kafkaTopicPrefix,
+		schemaTopic:      schemaTopic,
 	}
 	sink.topics = make(map[string]struct{})
 	for _, t := range targets {
 		sink.topics[kafkaTopicPrefix+SQLNameToKafkaName(t.StatementTimeName)] = struct{}{}
 	}
+	if schemaTopic != `` {
+		// Resolved timestamps are emitted to every topic, including the schema
+		// topic, so that consumers of it know when they have seen every schema
+		// change up to a given timestamp.
+		sink.topics[schemaTopic] = struct{}{}
+	}
 
 	config := sarama.NewConfig()
 	config.Producer.Return.Successes = true
@@ -217,7 +292,9 @@ func (s *kafkaSink) Close() error {
 }
 
 // EmitRow implements the Sink interface.
-func (s *kafkaSink) EmitRow(ctx context.Context, tableName string, key, value []byte) error {
+func (s *kafkaSink) EmitRow(
+	ctx context.Context, tableName string, key, value []byte, _ hlc.Timestamp,
+) error {
 	topic := s.kafkaTopicPrefix + SQLNameToKafkaName(tableName)
 	if _, ok := s.topics[topic]; !ok {
 		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
@@ -231,8 +308,26 @@ func (s *kafkaSink) EmitRow(ctx context.Context, tableName string, key, value []
 	return s.emitMessage(ctx, msg)
 }
 
+// EmitSchemaChange implements the Sink interface.
+func (s *kafkaSink) EmitSchemaChange(
+	ctx context.Context, tableName string, payload []byte, _ hlc.Timestamp,
+) error {
+	if s.schemaTopic == `` {
+		return nil
+	}
+	// Keying by table keeps the events for a table in order on one partition.
+	msg := &sarama.ProducerMessage{
+		Topic: s.schemaTopic,
+		Key:   sarama.StringEncoder(tableName),
+		Value: sarama.ByteEncoder(payload),
+	}
+	return s.emitMessage(ctx, msg)
+}
+
 // EmitResolvedTimestamp implements the Sink interface.
-func (s *kafkaSink) EmitResolvedTimestamp(ctx context.Context, payload []byte) error {
+func (s *kafkaSink) EmitResolvedTimestamp(
+	ctx context.Context, payload []byte, _ hlc.Timestamp,
+) error {
 	// Periodically ping sarama to refresh its metadata. This means talking to
 	// zookeeper, so it shouldn't be done too often, but beyond that this
 	// constant was picked pretty arbitrarily.
@@ -446,7 +541,9 @@ func makeSQLSink(uri, tableName string, targets jobspb.ChangefeedTargets) (*sqlS
 }
 
 // EmitRow implements the Sink interface.
-func (s *sqlSink) EmitRow(ctx context.Context, topic string, key, value []byte) error {
+func (s *sqlSink) EmitRow(
+	ctx context.Context, topic string, key, value []byte, _ hlc.Timestamp,
+) error {
 	if _, ok := s.topics[topic]; !ok {
 		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
 	}
@@ -466,7 +563,9 @@ func (s *sqlSink) EmitRow(ctx context.Context, topic string, key, value []byte)
 }
 
 // EmitResolvedTimestamp implements the Sink interface.
-func (s *sqlSink) EmitResolvedTimestamp(ctx context.Context, payload []byte) error {
+func (s *sqlSink) EmitResolvedTimestamp(
+	ctx context.Context, payload []byte, _ hlc.Timestamp,
+) error {
 	var noKey, noValue []byte
 	for topic := range s.topics {
 		for partition := int32(0); partition < sqlSinkNumPartitions; partition++ {
@@ -478,6 +577,11 @@ func (s *sqlSink) EmitResolvedTimestamp(ctx context.Context, payload []byte) err
 	return nil
 }
 
+// EmitSchemaChange implements the Sink interface. sqlSink has no schema topic.
+func (s *sqlSink) EmitSchemaChange(context.Context, string, []byte, hlc.Timestamp) error {
+	return nil
+}
+
 func (s *sqlSink) emit(
 	ctx context.Context, topic string, partition int32, key, value, resolved []byte,
 ) error {
@@ -549,7 +653,9 @@ type bufferSink struct {
 }
 
 // EmitRow implements the Sink interface.
-func (s *bufferSink) EmitRow(_ context.Context, topic string, key, value []byte) error {
+func (s *bufferSink) EmitRow(
+	_ context.Context, topic string, key, value []byte, _ hlc.Timestamp,
+) error {
 	if s.closed {
 		return errors.New(`cannot EmitRow on a closed sink`)
 	}
@@ -563,7 +669,9 @@ func (s *bufferSink) EmitRow(_ context.Context, topic string, key, value []byte)
 }
 
 // EmitResolvedTimestamp implements the Sink interface.
-func (s *bufferSink) EmitResolvedTimestamp(_ context.Context, payload []byte) error {
+func (s *bufferSink) EmitResolvedTimestamp(
+	_ context.Context, payload []byte, _ hlc.Timestamp,
+) error {
 	if s.closed {
 		return errors.New(`cannot EmitRow on a closed sink`)
 	}
@@ -576,6 +684,12 @@ func (s *bufferSink) EmitResolvedTimestamp(_ context.Context, payload []byte) er
 	return nil
 }
 
+// EmitSchemaChange implements the Sink interface. bufferSink has no schema
+// topic.
+func (s *bufferSink) EmitSchemaChange(context.Context, string, []byte, hlc.Timestamp) error {
+	return nil
+}
+
 // Flush implements the Sink interface.
 func (s *bufferSink) Flush(_ context.Context) error {
 	return nil
@@ -0,0 +1,261 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultCloudStorageFileSize is the size at which a data file is written
+	// out without waiting for the next Flush, unless overridden by the
+	// file_size sink parameter.
+	defaultCloudStorageFileSize = 16 << 20 // 16 MiB
+
+	cloudStorageDataFileExt     = `.ndjson`
+	cloudStorageResolvedFileExt = `.RESOLVED`
+)
+
+// isCloudStorageSinkScheme returns whether the sink URI scheme is handled by
+// the cloudStorageSink.
+func isCloudStorageSinkScheme(scheme string) bool {
+	switch scheme {
+	case sinkSchemeS3, sinkSchemeGCS, sinkSchemeAzure, sinkSchemeHTTP, sinkSchemeHTTPS,
+		sinkSchemeNodelocal:
+		return true
+	}
+	return false
+}
+
+// cloudStorageFormatTime formats a timestamp such that the lexical order of
+// the formatted timestamps matches their order as timestamps.
+func cloudStorageFormatTime(ts hlc.Timestamp) string {
+	// This is a long filename prefix, but it's exactly what is needed to
+	// represent every hlc.Timestamp.
+	const f = `20060102150405`
+	t := timeutil.Unix(0, ts.WallTime)
+	return fmt.Sprintf(`%s%09d%010d`, t.Format(f), t.Nanosecond(), ts.Logical)
+}
+
+// cloudStorageSinkFile is a data file that is being buffered by the
+// cloudStorageSink before being written out.
+type cloudStorageSinkFile struct {
+	// minUpdated is the lowest updated timestamp of the rows in the file. It
+	// determines the name of the file.
+	minUpdated hlc.Timestamp
+	// created is when the first row was buffered in the file.
+	created time.Time
+	buf     bytes.Buffer
+}
+
+// cloudStorageSink emits to files in any storageccl.ExportStorage (s3, gs,
+// azure, nodelocal, http).
+//
+// Rows are buffered in memory, one file per topic, and each row is written as
+// a line of JSON of the form `{"key":<key>,"value":<value>}`, where value is
+// null for deletions. A file is written out once its size reaches the
+// file_size sink parameter, once its first row has been buffered for longer
+// than the flush_interval sink parameter (if set), and on every Flush, which
+// the changefeed performs before it forwards resolved timestamps. The flush
+// interval is enforced whenever a row or resolved timestamp is emitted, so it
+// bounds how long rows are buffered as long as the changefeed keeps emitting.
+// Each file is written with a single
+// call to the ExportStorage, so a file is either entirely present or absent.
+//
+// Data files are named `<timestamp>-<node>-<sink>-<file>-<topic>.ndjson`,
+// where `<timestamp>` is the lowest updated timestamp of the rows in the file
+// and `<node>`, `<sink>` and `<file>` make the name unique. Resolved timestamps
+// are written to marker files named `<timestamp>.RESOLVED`. Timestamps are
+// formatted such that lexical and chronological order agree, and the
+// separators are chosen such that a data file sorts before a marker with the
+// same timestamp. Because all rows at or below a resolved timestamp are
+// flushed before it is emitted, this offers the following guarantee: at any
+// given time, if the files are listed in lexical order, all rows at or below
+// the timestamp of a RESOLVED marker are contained in the (complete) data
+// files listed before it. Consumers can thus process everything before the
+// latest marker.
+//
+// Like the other sinks, delivery is at-least-once: rows may be repeated in
+// later files, e.g. after the changefeed restarts, and such duplicates may be
+// named with timestamps at or below those of existing markers.
+type cloudStorageSink struct {
+	es            storageccl.ExportStorage
+	nodeID        roachpb.NodeID
+	sinkID        int64
+	fileSize      int64
+	flushInterval time.Duration
+
+	topics map[string]struct{}
+	files  map[string]*cloudStorageSinkFile
+	fileID int64
+}
+
+func makeCloudStorageSink(
+	ctx context.Context,
+	baseURI string,
+	nodeID roachpb.NodeID,
+	fileSize int64,
+	flushInterval time.Duration,
+	settings *cluster.Settings,
+	targets jobspb.ChangefeedTargets,
+) (*cloudStorageSink, error) {
+	es, err := storageccl.ExportStorageFromURI(ctx, baseURI, settings)
+	if err != nil {
+		return nil, err
+	}
+	s := &cloudStorageSink{
+		es:            es,
+		nodeID:        nodeID,
+		sinkID:        int64(builtins.GenerateUniqueInt(nodeID)),
+		fileSize:      fileSize,
+		flushInterval: flushInterval,
+		topics:        make(map[string]struct{}, len(targets)),
+		files:         make(map[string]*cloudStorageSinkFile),
+	}
+	for _, t := range targets {
+		s.topics[t.StatementTimeName] = struct{}{}
+	}
+	return s, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *cloudStorageSink) EmitRow(
+	ctx context.Context, topic string, key, value []byte, updated hlc.Timestamp,
+) error {
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+	file, ok := s.files[topic]
+	if !ok {
+		file = &cloudStorageSinkFile{minUpdated: updated, created: timeutil.Now()}
+		s.files[topic] = file
+	} else if updated.Less(file.minUpdated) {
+		file.minUpdated = updated
+	}
+
+	file.buf.WriteString(`{"key":`)
+	file.buf.Write(key)
+	file.buf.WriteString(`,"value":`)
+	if value == nil {
+		file.buf.WriteString(`null`)
+	} else {
+		file.buf.Write(value)
+	}
+	file.buf.WriteString("}\n")
+
+	if int64(file.buf.Len()) >= s.fileSize {
+		if err := s.flushFile(ctx, topic, file); err != nil {
+			return err
+		}
+	}
+	return s.flushExpiredFiles(ctx)
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *cloudStorageSink) EmitResolvedTimestamp(
+	ctx context.Context, payload []byte, resolved hlc.Timestamp,
+) error {
+	if err := s.flushExpiredFiles(ctx); err != nil {
+		return err
+	}
+	// Resolved timestamps are only emitted after all rows at or below them
+	// have been flushed, so the marker can be written immediately.
+	name := cloudStorageFormatTime(resolved) + cloudStorageResolvedFileExt
+	if log.V(1) {
+		log.Infof(ctx, `writing resolved timestamp file %s`, name)
+	}
+	if err := s.es.WriteFile(ctx, name, bytes.NewReader(payload)); err != nil {
+		return &retryableSinkError{cause: errors.Wrapf(err, `writing file %s`, name)}
+	}
+	return nil
+}
+
+// EmitSchemaChange implements the Sink interface. cloudStorageSink has no
+// schema topic.
+func (s *cloudStorageSink) EmitSchemaChange(
+	context.Context, string, []byte, hlc.Timestamp,
+) error {
+	return nil
+}
+
+// Flush implements the Sink interface.
+func (s *cloudStorageSink) Flush(ctx context.Context) error {
+	return s.flushFiles(ctx, func(*cloudStorageSinkFile) bool { return true })
+}
+
+// flushExpiredFiles writes out the buffered data files whose first row was
+// buffered more than flushInterval ago, if a flush interval is set.
+func (s *cloudStorageSink) flushExpiredFiles(ctx context.Context) error {
+	if s.flushInterval <= 0 {
+		return nil
+	}
+	now := timeutil.Now()
+	return s.flushFiles(ctx, func(file *cloudStorageSinkFile) bool {
+		return now.Sub(file.created) >= s.flushInterval
+	})
+}
+
+// flushFiles writes out the buffered data files for which shouldFlush returns
+// true.
+func (s *cloudStorageSink) flushFiles(
+	ctx context.Context, shouldFlush func(*cloudStorageSinkFile) bool,
+) error {
+	// Flush in a deterministic order to make the sink easier to test.
+	topics := make([]string, 0, len(s.files))
+	for topic, file := range s.files {
+		if shouldFlush(file) {
+			topics = append(topics, topic)
+		}
+	}
+	sort.Strings(topics)
+	for _, topic := range topics {
+		if err := s.flushFile(ctx, topic, s.files[topic]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushFile writes out the buffered data file for the topic.
+func (s *cloudStorageSink) flushFile(
+	ctx context.Context, topic string, file *cloudStorageSinkFile,
+) error {
+	name := fmt.Sprintf(`%s-%d-%d-%d-%s%s`,
+		cloudStorageFormatTime(file.minUpdated), s.nodeID, s.sinkID, s.fileID,
+		SQLNameToKafkaName(topic), cloudStorageDataFileExt)
+	s.fileID++
+	if log.V(1) {
+		log.Infof(ctx, `writing %d bytes to file %s`, file.buf.Len(), name)
+	}
+	if err := s.es.WriteFile(ctx, name, bytes.NewReader(file.buf.Bytes())); err != nil {
+		return &retryableSinkError{cause: errors.Wrapf(err, `writing file %s`, name)}
+	}
+	delete(s.files, topic)
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *cloudStorageSink) Close() error {
+	s.files = nil
+	return s.es.Close()
+}
@@ -0,0 +1,233 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudStorageFormatTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tss := []hlc.Timestamp{
+		{WallTime: 1},
+		{WallTime: 1, Logical: 1},
+		{WallTime: 1, Logical: 10},
+		{WallTime: 999999999},
+		{WallTime: 1000000000},
+		{WallTime: 1544000000000000000},
+		{WallTime: 1544000000000000001},
+	}
+	var formatted []string
+	for _, ts := range tss {
+		formatted = append(formatted, cloudStorageFormatTime(ts))
+	}
+	require.True(t, sort.StringsAreSorted(formatted), `%v`, formatted)
+	require.Equal(t, `201812050853200000000000000000000`, formatted[5])
+}
+
+func TestCloudStorageSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	settings := cluster.MakeTestingClusterSettings()
+	settings.ExternalIODir = dir
+	targets := jobspb.ChangefeedTargets{
+		0: jobspb.ChangefeedTarget{StatementTimeName: `foo`},
+		1: jobspb.ChangefeedTarget{StatementTimeName: `bar`},
+	}
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+
+	listFiles := func(t *testing.T, subdir string) []string {
+		t.Helper()
+		infos, err := ioutil.ReadDir(filepath.Join(dir, subdir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name())
+		}
+		sort.Strings(names)
+		return names
+	}
+	readFile := func(t *testing.T, subdir, name string) string {
+		t.Helper()
+		buf, err := ioutil.ReadFile(filepath.Join(dir, subdir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf)
+	}
+
+	t.Run(`flush`, func(t *testing.T) {
+		s, err := makeCloudStorageSink(ctx, `nodelocal:///flush`, 1,
+			defaultCloudStorageFileSize, 0 /* flushInterval */, settings, targets)
+		require.NoError(t, err)
+		s.sinkID = 7 // Force a deterministic sinkID.
+
+		require.NoError(t, s.EmitRow(ctx, `foo`, []byte(`[1]`), []byte(`{"a":1}`), ts(2)))
+		require.NoError(t, s.EmitRow(ctx, `foo`, []byte(`[2]`), nil, ts(1)))
+		require.NoError(t, s.EmitRow(ctx, `bar`, []byte(`[3]`), []byte(`{"b":3}`), ts(3)))
+		require.EqualError(t,
+			s.EmitRow(ctx, `baz`, nil, nil, ts(3)), `cannot emit to undeclared topic: baz`)
+
+		// Nothing is written until the sink is flushed.
+		_, err = ioutil.ReadDir(filepath.Join(dir, `flush`))
+		require.Error(t, err)
+
+		require.NoError(t, s.Flush(ctx))
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, []byte(`{"resolved":"3"}`), ts(3)))
+		// Flushing with nothing buffered writes nothing.
+		require.NoError(t, s.Flush(ctx))
+
+		barFile := cloudStorageFormatTime(ts(3)) + `-1-7-0-bar.ndjson`
+		fooFile := cloudStorageFormatTime(ts(1)) + `-1-7-1-foo.ndjson`
+		resolvedFile := cloudStorageFormatTime(ts(3)) + `.RESOLVED`
+		require.Equal(t, []string{fooFile, barFile, resolvedFile}, listFiles(t, `flush`))
+		require.Equal(t,
+			"{\"key\":[1],\"value\":{\"a\":1}}\n{\"key\":[2],\"value\":null}\n",
+			readFile(t, `flush`, fooFile))
+		require.Equal(t, "{\"key\":[3],\"value\":{\"b\":3}}\n", readFile(t, `flush`, barFile))
+		require.Equal(t, `{"resolved":"3"}`, readFile(t, `flush`, resolvedFile))
+		require.NoError(t, s.Close())
+	})
+
+	t.Run(`file_size`, func(t *testing.T) {
+		s, err := makeCloudStorageSink(
+			ctx, `nodelocal:///file_size`, 1, 30, 0 /* flushInterval */, settings, targets)
+		require.NoError(t, err)
+		s.sinkID = 7 // Force a deterministic sinkID.
+
+		// Each row is 28 bytes, so every second row fills up a file.
+		for i := int64(1); i <= 5; i++ {
+			require.NoError(t, s.EmitRow(ctx, `foo`, []byte(`[1]`), []byte(`{"a":1}`), ts(i)))
+		}
+		require.Equal(t, []string{
+			cloudStorageFormatTime(ts(1)) + `-1-7-0-foo.ndjson`,
+			cloudStorageFormatTime(ts(3)) + `-1-7-1-foo.ndjson`,
+		}, listFiles(t, `file_size`))
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			cloudStorageFormatTime(ts(1)) + `-1-7-0-foo.ndjson`,
+			cloudStorageFormatTime(ts(3)) + `-1-7-1-foo.ndjson`,
+			cloudStorageFormatTime(ts(5)) + `-1-7-2-foo.ndjson`,
+		}, listFiles(t, `file_size`))
+		require.NoError(t, s.Close())
+	})
+
+	t.Run(`flush_interval`, func(t *testing.T) {
+		// Serve the files over http, as an ExportStorage for an http:// URI.
+		var mu syncutil.Mutex
+		files := make(map[string]string)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != `PUT` {
+				http.Error(w, `unsupported method `+r.Method, http.StatusMethodNotAllowed)
+				return
+			}
+			buf, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			mu.Lock()
+			files[path.Base(r.URL.Path)] = string(buf)
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer srv.Close()
+		listHTTPFiles := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			var names []string
+			for name := range files {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return names
+		}
+		readHTTPFile := func(name string) string {
+			mu.Lock()
+			defer mu.Unlock()
+			return files[name]
+		}
+
+		s, err := makeCloudStorageSink(ctx, srv.URL+`/flush_interval`, 1,
+			defaultCloudStorageFileSize, time.Hour, settings, targets)
+		require.NoError(t, err)
+		s.sinkID = 7 // Force a deterministic sinkID.
+
+		require.NoError(t, s.EmitRow(ctx, `foo`, []byte(`[1]`), []byte(`{"a":1}`), ts(1)))
+		require.NoError(t, s.EmitRow(ctx, `bar`, []byte(`[2]`), []byte(`{"b":2}`), ts(2)))
+		require.Empty(t, listHTTPFiles())
+
+		// Once the first row of a file has been buffered for longer than the
+		// flush interval, the next emitted row writes the file out.
+		s.files[`foo`].created = timeutil.Now().Add(-2 * time.Hour)
+		require.NoError(t, s.EmitRow(ctx, `bar`, []byte(`[3]`), []byte(`{"b":3}`), ts(3)))
+		fooFile := cloudStorageFormatTime(ts(1)) + `-1-7-0-foo.ndjson`
+		require.Equal(t, []string{fooFile}, listHTTPFiles())
+		require.Equal(t, "{\"key\":[1],\"value\":{\"a\":1}}\n", readHTTPFile(fooFile))
+
+		// The flush interval is also enforced when emitting resolved timestamps.
+		s.files[`bar`].created = timeutil.Now().Add(-2 * time.Hour)
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, []byte(`{"resolved":"1"}`), ts(1)))
+		barFile := cloudStorageFormatTime(ts(2)) + `-1-7-1-bar.ndjson`
+		resolvedFile := cloudStorageFormatTime(ts(1)) + `.RESOLVED`
+		require.Equal(t, []string{fooFile, resolvedFile, barFile}, listHTTPFiles())
+		require.Equal(t,
+			"{\"key\":[2],\"value\":{\"b\":2}}\n{\"key\":[3],\"value\":{\"b\":3}}\n",
+			readHTTPFile(barFile))
+		require.NoError(t, s.Close())
+	})
+
+	t.Run(`getSink`, func(t *testing.T) {
+		opts := map[string]string{optFormat: string(optFormatJSON)}
+		_, err := getSink(ctx, `nodelocal:///x?file_size=foo`, 1, opts, targets, settings)
+		require.True(t, testutils.IsError(err, `parsing file_size`), `%v`, err)
+		_, err = getSink(ctx, `nodelocal:///x?file_size=0`, 1, opts, targets, settings)
+		require.EqualError(t, err, `file_size must be positive: 0`)
+		_, err = getSink(ctx, `nodelocal:///x?flush_interval=foo`, 1, opts, targets, settings)
+		require.True(t, testutils.IsError(err, `parsing flush_interval`), `%v`, err)
+		_, err = getSink(ctx, `nodelocal:///x?flush_interval=-1s`, 1, opts, targets, settings)
+		require.EqualError(t, err, `flush_interval must not be negative: -1s`)
+
+		opts[optFormat] = string(optFormatAvro)
+		_, err = getSink(ctx, `nodelocal:///x`, 1, opts, targets, settings)
+		require.EqualError(t, err, `format=experimental_avro is not yet supported by the nodelocal sink`)
+
+		opts[optFormat] = string(optFormatJSON)
+		sink, err := getSink(
+			ctx, `nodelocal:///x?file_size=1KiB&flush_interval=10s`, 1, opts, targets, settings)
+		require.NoError(t, err)
+		require.Equal(t, int64(1<<10), sink.(*cloudStorageSink).fileSize)
+		require.Equal(t, 10*time.Second, sink.(*cloudStorageSink).flushInterval)
+		require.NoError(t, sink.Close())
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -70,7 +71,7 @@ func TestKafkaSink(t *testing.T) {
 	}
 
 	// Timeout
-	if err := sink.EmitRow(ctx, `t`, []byte(`1`), nil); err != nil {
+	if err := sink.EmitRow(ctx, `t`, []byte(`1`), nil, hlc.Timestamp{}); err != nil {
 		t.Fatal(err)
 	}
 	m1 := <-p.inputCh
@@ -92,15 +93,15 @@ func TestKafkaSink(t *testing.T) {
 	}
 
 	// Mixed success and error.
-	if err := sink.EmitRow(ctx, `t`, []byte(`2`), nil); err != nil {
+	if err := sink.EmitRow(ctx, `t`, []byte(`2`), nil, hlc.Timestamp{}); err != nil {
 		t.Fatal(err)
 	}
 	m2 := <-p.inputCh
-	if err := sink.EmitRow(ctx, `t`, []byte(`3`), nil); err != nil {
+	if err := sink.EmitRow(ctx, `t`, []byte(`3`), nil, hlc.Timestamp{}); err != nil {
 		t.Fatal(err)
 	}
 	m3 := <-p.inputCh
-	if err := sink.EmitRow(ctx, `t`, []byte(`4`), nil); err != nil {
+	if err := sink.EmitRow(ctx, `t`, []byte(`4`), nil, hlc.Timestamp{}); err != nil {
 		t.Fatal(err)
 	}
 	m4 := <-p.inputCh
@@ -117,7 +118,7 @@ func TestKafkaSink(t *testing.T) {
 	}
 
 	// Check simple success again after error
-	if err := sink.EmitRow(ctx, `t`, []byte(`5`), nil); err != nil {
+	if err := sink.EmitRow(ctx, `t`, []byte(`5`), nil, hlc.Timestamp{}); err != nil {
 		t.Fatal(err)
 	}
 	m5 := <-p.inputCh
@@ -142,7 +143,7 @@ func TestKafkaSinkEscaping(t *testing.T) {
 	}
 	sink.start()
 	defer func() { require.NoError(t, sink.Close()) }()
-	if err := sink.EmitRow(ctx, `☃`, []byte(`k☃`), []byte(`v☃`)); err != nil {
+	if err := sink.EmitRow(ctx, `☃`, []byte(`k☃`), []byte(`v☃`), hlc.Timestamp{}); err != nil {
 		t.Fatal(err)
 	}
 	m := <-p.inputCh
@@ -151,6 +152,40 @@ func TestKafkaSinkEscaping(t *testing.T) {
 	require.Equal(t, sarama.ByteEncoder(`v☃`), m.Value)
 }
 
+func TestKafkaSinkSchemaTopic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	p := asyncProducerMock{
+		inputCh:     make(chan *sarama.ProducerMessage, 1),
+		successesCh: make(chan *sarama.ProducerMessage, 1),
+		errorsCh:    make(chan *sarama.ProducerError, 1),
+	}
+	sink := &kafkaSink{
+		producer: p,
+		topics:   map[string]struct{}{`t`: {}},
+	}
+	sink.start()
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	// Without a schema topic, schema changes are dropped.
+	require.NoError(t, sink.EmitSchemaChange(ctx, `t`, []byte(`{}`), hlc.Timestamp{}))
+	require.NoError(t, sink.Flush(ctx))
+
+	sink.schemaTopic = `schema`
+	require.NoError(t, sink.EmitSchemaChange(ctx, `t`, []byte(`{"table":"t"}`), hlc.Timestamp{}))
+	m := <-p.inputCh
+	require.Equal(t, `schema`, m.Topic)
+	require.Equal(t, sarama.StringEncoder(`t`), m.Key)
+	require.Equal(t, sarama.ByteEncoder(`{"table":"t"}`), m.Value)
+	go func() { p.successesCh <- m }()
+	require.NoError(t, sink.Flush(ctx))
+
+	require.Equal(t, `schema`, sinkSchemaTopic(`kafka://host/?schema_topic=schema`))
+	require.Equal(t, ``, sinkSchemaTopic(`kafka://host/`))
+	require.Equal(t, ``, sinkSchemaTopic(`experimental-sql://host/?schema_topic=schema`))
+}
+
 func TestSQLSink(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -176,10 +211,10 @@ func TestSQLSink(t *testing.T) {
 	require.NoError(t, sink.Flush(ctx))
 
 	// Undeclared topic
-	require.EqualError(t, sink.EmitRow(ctx, `nope`, nil, nil), `cannot emit to undeclared topic: nope`)
+	require.EqualError(t, sink.EmitRow(ctx, `nope`, nil, nil, hlc.Timestamp{}), `cannot emit to undeclared topic: nope`)
 
 	// With one row, nothing flushes until Flush is called.
-	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`k1`), []byte(`v0`)))
+	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`k1`), []byte(`v0`), hlc.Timestamp{}))
 	sqlDB.CheckQueryResults(t, `SELECT key, value FROM sink ORDER BY PRIMARY KEY sink`,
 		[][]string{},
 	)
@@ -192,7 +227,7 @@ func TestSQLSink(t *testing.T) {
 	// Verify the implicit flushing
 	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM sink`, [][]string{{`0`}})
 	for i := 0; i < sqlSinkRowBatchSize+1; i++ {
-		require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`k1`), []byte(`v`+strconv.Itoa(i))))
+		require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`k1`), []byte(`v`+strconv.Itoa(i)), hlc.Timestamp{}))
 	}
 	// Should have auto flushed after sqlSinkRowBatchSize
 	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM sink`, [][]string{{`3`}})
@@ -201,9 +236,9 @@ func TestSQLSink(t *testing.T) {
 	sqlDB.Exec(t, `TRUNCATE sink`)
 
 	// Two tables interleaved in time
-	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`kfoo`), []byte(`v0`)))
-	require.NoError(t, sink.EmitRow(ctx, `bar`, []byte(`kbar`), []byte(`v0`)))
-	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`kfoo`), []byte(`v1`)))
+	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`kfoo`), []byte(`v0`), hlc.Timestamp{}))
+	require.NoError(t, sink.EmitRow(ctx, `bar`, []byte(`kbar`), []byte(`v0`), hlc.Timestamp{}))
+	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`kfoo`), []byte(`v1`), hlc.Timestamp{}))
 	require.NoError(t, sink.Flush(ctx))
 	sqlDB.CheckQueryResults(t, `SELECT topic, key, value FROM sink ORDER BY PRIMARY KEY sink`,
 		[][]string{{`bar`, `kbar`, `v0`}, {`foo`, `kfoo`, `v0`}, {`foo`, `kfoo`, `v1`}},
@@ -213,10 +248,10 @@ func TestSQLSink(t *testing.T) {
 	// Multiple keys interleaved in time. Use sqlSinkNumPartitions+1 keys to
 	// guarantee that at lease two of them end up in the same partition.
 	for i := 0; i < sqlSinkNumPartitions+1; i++ {
-		require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`v`+strconv.Itoa(i)), []byte(`v0`)))
+		require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`v`+strconv.Itoa(i)), []byte(`v0`), hlc.Timestamp{}))
 	}
 	for i := 0; i < sqlSinkNumPartitions+1; i++ {
-		require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`v`+strconv.Itoa(i)), []byte(`v1`)))
+		require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`v`+strconv.Itoa(i)), []byte(`v1`), hlc.Timestamp{}))
 	}
 	require.NoError(t, sink.Flush(ctx))
 	sqlDB.CheckQueryResults(t, `SELECT partition, key, value FROM sink ORDER BY PRIMARY KEY sink`,
@@ -234,9 +269,9 @@ func TestSQLSink(t *testing.T) {
 	sqlDB.Exec(t, `TRUNCATE sink`)
 
 	// Emit resolved
-	require.NoError(t, sink.EmitResolvedTimestamp(ctx, []byte(`r0`)))
-	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`foo0`), []byte(`v0`)))
-	require.NoError(t, sink.EmitResolvedTimestamp(ctx, []byte(`r1`)))
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, []byte(`r0`), hlc.Timestamp{}))
+	require.NoError(t, sink.EmitRow(ctx, `foo`, []byte(`foo0`), []byte(`v0`), hlc.Timestamp{}))
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, []byte(`r1`), hlc.Timestamp{}))
 	require.NoError(t, sink.Flush(ctx))
 	sqlDB.CheckQueryResults(t,
 		`SELECT topic, partition, key, value, resolved FROM sink ORDER BY PRIMARY KEY sink`,
@@ -0,0 +1,513 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultWebhookBatchSize is the number of rows sent in one request,
+	// unless overridden by the batch_size sink parameter.
+	defaultWebhookBatchSize = 100
+	// defaultWebhookMaxRetries is the number of times a request that failed
+	// with a retryable error is retried before the error is returned to the
+	// changefeed, unless overridden by the max_retries sink parameter.
+	defaultWebhookMaxRetries = 3
+	// webhookClientTimeout bounds each request made by the webhookSink.
+	webhookClientTimeout = 30 * time.Second
+)
+
+// webhookSinkConfig is the configuration of a webhookSink, parsed from the sink
+// URI.
+type webhookSinkConfig struct {
+	// url is the https endpoint that requests are sent to.
+	url string
+	// batchSize is the maximum number of rows sent in one request.
+	batchSize int
+	// flushInterval, if non-zero, is the longest a row is buffered before it is
+	// sent in a partial batch. Otherwise partial batches are only sent by
+	// Flush.
+	flushInterval time.Duration
+	// maxRetries is the number of times a request that failed with a
+	// retryable error is retried.
+	maxRetries int
+	// retryOpts configures the backoff between attempts of a request.
+	retryOpts retry.Options
+	tlsConfig *tls.Config
+}
+
+// makeWebhookSinkConfig parses the parameters of a `webhook-https://` sink
+// URI. Every parameter that is understood is removed from q.
+func makeWebhookSinkConfig(u *url.URL, q url.Values) (webhookSinkConfig, error) {
+	cfg := webhookSinkConfig{
+		batchSize:  defaultWebhookBatchSize,
+		maxRetries: defaultWebhookMaxRetries,
+		retryOpts: retry.Options{
+			InitialBackoff: 500 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+			Multiplier:     2,
+		},
+		tlsConfig: &tls.Config{},
+	}
+
+	if s := q.Get(sinkParamBatchSize); s != `` {
+		batchSize, err := strconv.Atoi(s)
+		if err != nil {
+			return cfg, errors.Wrapf(err, `parsing %s`, sinkParamBatchSize)
+		}
+		if batchSize <= 0 {
+			return cfg, errors.Errorf(`%s must be positive: %s`, sinkParamBatchSize, s)
+		}
+		cfg.batchSize = batchSize
+	}
+	q.Del(sinkParamBatchSize)
+
+	if s := q.Get(sinkParamFlushInterval); s != `` {
+		flushInterval, err := time.ParseDuration(s)
+		if err != nil {
+			return cfg, errors.Wrapf(err, `parsing %s`, sinkParamFlushInterval)
+		}
+		if flushInterval < 0 {
+			return cfg, errors.Errorf(`%s must not be negative: %s`, sinkParamFlushInterval, s)
+		}
+		cfg.flushInterval = flushInterval
+	}
+	q.Del(sinkParamFlushInterval)
+
+	if s := q.Get(sinkParamMaxRetries); s != `` {
+		maxRetries, err := strconv.Atoi(s)
+		if err != nil {
+			return cfg, errors.Wrapf(err, `parsing %s`, sinkParamMaxRetries)
+		}
+		if maxRetries < 0 {
+			return cfg, errors.Errorf(`%s must not be negative: %s`, sinkParamMaxRetries, s)
+		}
+		cfg.maxRetries = maxRetries
+	}
+	q.Del(sinkParamMaxRetries)
+
+	decodePEM := func(param string) ([]byte, error) {
+		s := q.Get(param)
+		q.Del(param)
+		if s == `` {
+			return nil, nil
+		}
+		pem, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, `decoding %s`, param)
+		}
+		return pem, nil
+	}
+	caCert, err := decodePEM(sinkParamCACert)
+	if err != nil {
+		return cfg, err
+	}
+	if caCert != nil {
+		cfg.tlsConfig.RootCAs = x509.NewCertPool()
+		if !cfg.tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return cfg, errors.Errorf(`%s does not contain a PEM encoded certificate`, sinkParamCACert)
+		}
+	}
+	clientCert, err := decodePEM(sinkParamClientCert)
+	if err != nil {
+		return cfg, err
+	}
+	clientKey, err := decodePEM(sinkParamClientKey)
+	if err != nil {
+		return cfg, err
+	}
+	if (clientCert == nil) != (clientKey == nil) {
+		return cfg, errors.Errorf(`%s and %s must be specified together`,
+			sinkParamClientCert, sinkParamClientKey)
+	}
+	if clientCert != nil {
+		cert, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return cfg, errors.Wrapf(err, `parsing %s and %s`, sinkParamClientCert, sinkParamClientKey)
+		}
+		cfg.tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// None of the sink parameters are meant for the endpoint.
+	endpoint := *u
+	endpoint.Scheme = `https`
+	endpoint.RawQuery = ``
+	cfg.url = endpoint.String()
+	return cfg, nil
+}
+
+// sanitizeWebhookSinkURI redacts the client key from a `webhook-https://`
+// sink URI so that it can be shown in the job description.
+func sanitizeWebhookSinkURI(sinkURI string) (string, error) {
+	u, err := url.Parse(sinkURI)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if q.Get(sinkParamClientKey) != `` {
+		q.Set(sinkParamClientKey, `redacted`)
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+// webhookSink emits to an HTTPS endpoint with POST requests.
+//
+// Rows are buffered and sent in batches of up to batch_size rows as JSON of
+// the form `{"payload":[{"topic":<topic>,"key":<key>,"value":<value>},...],
+// "length":<n>}`, where value is null for deletions. A partial batch is sent on
+// every Flush and, if flush_interval is set, once its oldest row has been
+// buffered that long. Each resolved timestamp is sent as a request of its own
+// with the encoded resolved timestamp as the body.
+//
+// Requests are sent one at a time and in order by a worker goroutine. A
+// request that fails with a network error, a 5xx or a 429 status is retried
+// with backoff up to max_retries times, after which the changefeed is
+// restarted with a retryableSinkError. Any other status fails the changefeed.
+// Once a request has failed, no later requests are sent, so a resolved
+// timestamp is only ever received after every row at or below it. Like the
+// other sinks, delivery is at-least-once: rows may be repeated in later
+// requests, e.g. after the changefeed restarts.
+//
+// It is not concurrency-safe; all calls to Emit and Flush should be from the
+// same goroutine.
+type webhookSink struct {
+	cfg    webhookSinkConfig
+	client *http.Client
+	topics map[string]struct{}
+
+	// workCh hands request bodies to the worker. It is unbuffered so that a
+	// request is always taken by the worker before the partial batch that
+	// follows it can be sent by the flush_interval timer.
+	workCh       chan []byte
+	stopWorkerCh chan struct{}
+	worker       sync.WaitGroup
+	// workerCtx is canceled by Close to abandon the request in flight.
+	workerCtx    context.Context
+	cancelWorker func()
+
+	// Only synchronized between the client goroutine and the worker goroutine.
+	mu struct {
+		syncutil.Mutex
+		// batch is the partial batch of encoded messages that have not been
+		// handed to the worker.
+		batch      [][]byte
+		batchStart time.Time
+		inflight   int64
+		flushErr   error
+		flushCh    chan struct{}
+	}
+}
+
+func makeWebhookSink(cfg webhookSinkConfig, targets jobspb.ChangefeedTargets) *webhookSink {
+	s := &webhookSink{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   webhookClientTimeout,
+			Transport: &http.Transport{TLSClientConfig: cfg.tlsConfig},
+		},
+		topics: make(map[string]struct{}, len(targets)),
+		workCh: make(chan []byte),
+	}
+	for _, t := range targets {
+		s.topics[t.StatementTimeName] = struct{}{}
+	}
+	s.start()
+	return s
+}
+
+func (s *webhookSink) start() {
+	s.workerCtx, s.cancelWorker = context.WithCancel(context.Background())
+	s.stopWorkerCh = make(chan struct{})
+	s.worker.Add(1)
+	go s.workerLoop()
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	// If we're shutting down, we don't care what happens to the outstanding
+	// requests.
+	s.cancelWorker()
+	close(s.stopWorkerCh)
+	s.worker.Wait()
+	return nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, topic string, key, value []byte, _ hlc.Timestamp,
+) error {
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"topic":`)
+	buf.WriteString(strconv.Quote(topic))
+	buf.WriteString(`,"key":`)
+	buf.Write(key)
+	buf.WriteString(`,"value":`)
+	if value == nil {
+		buf.WriteString(`null`)
+	} else {
+		buf.Write(value)
+	}
+	buf.WriteString(`}`)
+
+	s.mu.Lock()
+	if err := s.mu.flushErr; err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if len(s.mu.batch) == 0 {
+		s.mu.batchStart = timeutil.Now()
+	}
+	s.mu.batch = append(s.mu.batch, buf.Bytes())
+	full := len(s.mu.batch) >= s.cfg.batchSize
+	s.mu.Unlock()
+
+	if full {
+		return s.sendBatch(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, payload []byte, _ hlc.Timestamp,
+) error {
+	// The changefeed flushes before it emits a resolved timestamp, so there
+	// are normally no buffered rows, but send any that there are first to keep
+	// the requests in order.
+	if err := s.sendBatch(ctx); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	err := s.mu.flushErr
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, payload)
+}
+
+// EmitSchemaChange implements the Sink interface. webhookSink has no schema
+// topic.
+func (s *webhookSink) EmitSchemaChange(context.Context, string, []byte, hlc.Timestamp) error {
+	return nil
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	if err := s.sendBatch(ctx); err != nil {
+		return err
+	}
+
+	flushCh := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	inflight := s.mu.inflight
+	flushErr := s.mu.flushErr
+	s.mu.flushErr = nil
+	immediateFlush := inflight == 0 || flushErr != nil
+	if !immediateFlush {
+		s.mu.flushCh = flushCh
+	}
+	s.mu.Unlock()
+
+	if immediateFlush {
+		return flushErr
+	}
+
+	if log.V(1) {
+		log.Infof(ctx, "flush waiting for %d inflight webhook requests", inflight)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-flushCh:
+		s.mu.Lock()
+		flushErr := s.mu.flushErr
+		s.mu.flushErr = nil
+		s.mu.Unlock()
+		return flushErr
+	}
+}
+
+// sendBatch hands the partial batch, if any, to the worker.
+func (s *webhookSink) sendBatch(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.mu.batch
+	s.mu.batch = nil
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.enqueue(ctx, encodeWebhookBatch(batch))
+}
+
+// enqueue hands a request body to the worker, blocking until the worker has
+// taken it.
+func (s *webhookSink) enqueue(ctx context.Context, body []byte) error {
+	s.mu.Lock()
+	s.mu.inflight++
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		s.finishRequest(ctx.Err())
+		return ctx.Err()
+	case s.workCh <- body:
+	}
+	return nil
+}
+
+func encodeWebhookBatch(batch [][]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"payload":[`)
+	for i, msg := range batch {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(msg)
+	}
+	fmt.Fprintf(&buf, `],"length":%d}`, len(batch))
+	return buf.Bytes()
+}
+
+func (s *webhookSink) workerLoop() {
+	defer s.worker.Done()
+
+	var timerCh <-chan time.Time
+	if s.cfg.flushInterval > 0 {
+		tick := s.cfg.flushInterval / 2
+		if tick <= 0 {
+			tick = s.cfg.flushInterval
+		}
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		timerCh = ticker.C
+	}
+
+	for {
+		select {
+		case <-s.stopWorkerCh:
+			return
+		case body := <-s.workCh:
+			s.finishRequest(s.send(body))
+		case <-timerCh:
+			// Send the partial batch if its oldest row has been waiting for
+			// flush_interval. The ticker runs at half the interval so that no
+			// row waits much longer than that.
+			s.mu.Lock()
+			var batch [][]byte
+			if len(s.mu.batch) > 0 && timeutil.Since(s.mu.batchStart) >= s.cfg.flushInterval {
+				batch = s.mu.batch
+				s.mu.batch = nil
+				s.mu.inflight++
+			}
+			s.mu.Unlock()
+			if batch != nil {
+				s.finishRequest(s.send(encodeWebhookBatch(batch)))
+			}
+		}
+	}
+}
+
+// finishRequest records the outcome of a request handed to the worker.
+func (s *webhookSink) finishRequest(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil && s.mu.flushErr == nil {
+		s.mu.flushErr = err
+	}
+	s.mu.inflight--
+	if s.mu.inflight == 0 && s.mu.flushCh != nil {
+		s.mu.flushCh <- struct{}{}
+		s.mu.flushCh = nil
+	}
+}
+
+// send POSTs the body to the endpoint, retrying retryable failures with
+// backoff. Nothing is sent if an earlier request has failed and the error
+// hasn't yet been returned by Flush, which preserves the ordering guarantees
+// of the sink.
+func (s *webhookSink) send(body []byte) error {
+	s.mu.Lock()
+	failed := s.mu.flushErr != nil
+	s.mu.Unlock()
+	if failed {
+		return nil
+	}
+
+	ctx := s.workerCtx
+	var err error
+	attempts := 0
+	for r := retry.StartWithCtx(ctx, s.cfg.retryOpts); r.Next(); {
+		var retryable bool
+		if retryable, err = s.post(ctx, body); err == nil || !retryable {
+			return err
+		}
+		attempts++
+		if attempts > s.cfg.maxRetries {
+			break
+		}
+		log.Infof(ctx, `retrying webhook request: %v`, err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return &retryableSinkError{cause: err}
+}
+
+// post makes one attempt at sending the body to the endpoint. The returned
+// boolean is true if a failure may succeed when retried.
+func (s *webhookSink) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.cfg.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(`Content-Type`, `application/json`)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, errors.Wrapf(err, `sending to webhook`)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		// Drain the body so the connection can be reused.
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return false, nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = errors.Errorf(`webhook responded with %s: %s`, resp.Status, bytes.TrimSpace(msg))
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, err
+}
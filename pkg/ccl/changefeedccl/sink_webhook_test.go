@@ -0,0 +1,285 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/security/securitytest"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookTestServer is an HTTPS server that requires client certificates and
+// records the body of every request it accepts.
+type webhookTestServer struct {
+	*httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		bodies []string
+		// statuses, if not empty, are responded with, in order, instead of
+		// accepting requests.
+		statuses []int
+	}
+}
+
+func makeWebhookTestServer(t *testing.T) *webhookTestServer {
+	s := &webhookTestServer{}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if len(s.mu.statuses) > 0 {
+				status := s.mu.statuses[0]
+				s.mu.statuses = s.mu.statuses[1:]
+				if status != http.StatusOK {
+					http.Error(w, http.StatusText(status), status)
+					return
+				}
+			}
+			s.mu.bodies = append(s.mu.bodies, string(body))
+		}))
+	certsDir := security.EmbeddedCertsDir
+	tlsConfig, err := security.LoadServerTLSConfig(
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedNodeCert),
+		filepath.Join(certsDir, security.EmbeddedNodeKey),
+	)
+	require.NoError(t, err)
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	s.TLS = tlsConfig
+	s.StartTLS()
+	return s
+}
+
+func (s *webhookTestServer) setStatuses(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.statuses = statuses
+}
+
+func (s *webhookTestServer) bodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.mu.bodies...)
+}
+
+// sinkURI returns a `webhook-https://` sink URI for the server with the given
+// additional parameters and the embedded client certificates.
+func (s *webhookTestServer) sinkURI(t *testing.T, params url.Values) string {
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = sinkSchemeWebhookHTTPS
+	u.Path = `/changefeed`
+	if params == nil {
+		params = url.Values{}
+	}
+	asset := func(name string) string {
+		pem, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, name))
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(pem)
+	}
+	params.Set(sinkParamCACert, asset(security.EmbeddedCACert))
+	params.Set(sinkParamClientCert, asset(security.EmbeddedRootCert))
+	params.Set(sinkParamClientKey, asset(security.EmbeddedRootKey))
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+func makeTestWebhookSink(t *testing.T, sinkURI string) *webhookSink {
+	u, err := url.Parse(sinkURI)
+	require.NoError(t, err)
+	q := u.Query()
+	cfg, err := makeWebhookSinkConfig(u, q)
+	require.NoError(t, err)
+	require.Empty(t, q)
+	// Keep the tests fast.
+	cfg.retryOpts.InitialBackoff = time.Millisecond
+	cfg.retryOpts.MaxBackoff = time.Millisecond
+	targets := jobspb.ChangefeedTargets{0: jobspb.ChangefeedTarget{StatementTimeName: `t`}}
+	return makeWebhookSink(cfg, targets)
+}
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	server := makeWebhookTestServer(t)
+	defer server.Close()
+
+	sink := makeTestWebhookSink(t, server.sinkURI(t, url.Values{sinkParamBatchSize: {`2`}}))
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	// Timestamps are ignored by the sink.
+	var ts hlc.Timestamp
+
+	// Undeclared topic
+	require.EqualError(t, sink.EmitRow(ctx, `nope`, nil, nil, ts),
+		`cannot emit to undeclared topic: nope`)
+
+	// Nothing to flush.
+	require.NoError(t, sink.Flush(ctx))
+	require.Empty(t, server.bodies())
+
+	// A full batch is sent without waiting for Flush.
+	require.NoError(t, sink.EmitRow(ctx, `t`, []byte(`[1]`), []byte(`{"a": 1}`), ts))
+	require.NoError(t, sink.EmitRow(ctx, `t`, []byte(`[2]`), nil, ts))
+	require.NoError(t, sink.EmitRow(ctx, `t`, []byte(`[3]`), []byte(`{"a": 3}`), ts))
+	testutils.SucceedsSoon(t, func() error {
+		if len(server.bodies()) == 0 {
+			return errors.New(`no requests`)
+		}
+		return nil
+	})
+	require.Equal(t, []string{
+		`{"payload":[{"topic":"t","key":[1],"value":{"a": 1}},{"topic":"t","key":[2],"value":null}],"length":2}`,
+	}, server.bodies())
+
+	// Flush sends the partial batch and waits for it.
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{
+		`{"payload":[{"topic":"t","key":[1],"value":{"a": 1}},{"topic":"t","key":[2],"value":null}],"length":2}`,
+		`{"payload":[{"topic":"t","key":[3],"value":{"a": 3}}],"length":1}`,
+	}, server.bodies())
+
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, []byte(`{"resolved":"1.0000000000"}`), ts))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, `{"resolved":"1.0000000000"}`, server.bodies()[2])
+}
+
+func TestWebhookSinkFlushInterval(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	server := makeWebhookTestServer(t)
+	defer server.Close()
+
+	sink := makeTestWebhookSink(t, server.sinkURI(t, url.Values{
+		sinkParamFlushInterval: {`10ms`},
+	}))
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	require.NoError(t, sink.EmitRow(ctx, `t`, []byte(`[1]`), []byte(`{"a": 1}`), hlc.Timestamp{}))
+	testutils.SucceedsSoon(t, func() error {
+		if len(server.bodies()) == 0 {
+			return errors.New(`no requests`)
+		}
+		return nil
+	})
+	require.Equal(t, []string{
+		`{"payload":[{"topic":"t","key":[1],"value":{"a": 1}}],"length":1}`,
+	}, server.bodies())
+	require.NoError(t, sink.Flush(ctx))
+	require.Len(t, server.bodies(), 1)
+}
+
+func TestWebhookSinkRetries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	server := makeWebhookTestServer(t)
+	defer server.Close()
+
+	sink := makeTestWebhookSink(t, server.sinkURI(t, url.Values{sinkParamMaxRetries: {`2`}}))
+	defer func() { require.NoError(t, sink.Close()) }()
+	emit := func(key string) {
+		t.Helper()
+		require.NoError(t, sink.EmitRow(ctx, `t`, []byte(key), []byte(`{}`), hlc.Timestamp{}))
+	}
+
+	// Retryable failures that eventually succeed are invisible.
+	server.setStatuses(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	emit(`[1]`)
+	require.NoError(t, sink.Flush(ctx))
+	require.Len(t, server.bodies(), 1)
+
+	// Running out of retries is a retryable sink error.
+	server.setStatuses(http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+		http.StatusServiceUnavailable)
+	emit(`[2]`)
+	err := sink.Flush(ctx)
+	require.True(t, isRetryableSinkError(err), `%+v`, err)
+	require.Len(t, server.bodies(), 1)
+
+	// Other failures are not retried.
+	server.setStatuses(http.StatusBadRequest)
+	emit(`[3]`)
+	err = sink.Flush(ctx)
+	require.Error(t, err)
+	require.False(t, isRetryableSinkError(err), `%+v`, err)
+	require.Contains(t, err.Error(), `400 Bad Request`)
+
+	// Once a request has failed, later requests are not sent until the error
+	// has been returned.
+	server.setStatuses(http.StatusBadRequest)
+	emit(`[4]`)
+	require.NoError(t, sink.sendBatch(ctx))
+	require.NoError(t, sink.enqueue(ctx, []byte(`{"resolved":"1.0000000000"}`)))
+	require.Error(t, sink.Flush(ctx))
+	require.Len(t, server.bodies(), 1)
+}
+
+func TestWebhookSinkConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	parse := func(sinkURI string) (webhookSinkConfig, error) {
+		u, err := url.Parse(sinkURI)
+		require.NoError(t, err)
+		return makeWebhookSinkConfig(u, u.Query())
+	}
+
+	cfg, err := parse(`webhook-https://example.com:8080/a/b?batch_size=5&flush_interval=1s&max_retries=0`)
+	require.NoError(t, err)
+	require.Equal(t, `https://example.com:8080/a/b`, cfg.url)
+	require.Equal(t, 5, cfg.batchSize)
+	require.Equal(t, time.Second, cfg.flushInterval)
+	require.Equal(t, 0, cfg.maxRetries)
+
+	for _, tc := range []struct {
+		params string
+		err    string
+	}{
+		{`batch_size=0`, `batch_size must be positive: 0`},
+		{`batch_size=x`, `parsing batch_size: strconv.Atoi: parsing "x": invalid syntax`},
+		{`flush_interval=-1s`, `flush_interval must not be negative: -1s`},
+		{`max_retries=-1`, `max_retries must not be negative: -1`},
+		{`ca_cert=%21`, `decoding ca_cert: illegal base64 data at input byte 0`},
+		{`ca_cert=Zm9v`, `ca_cert does not contain a PEM encoded certificate`},
+		{`client_cert=Zm9v`, `client_cert and client_key must be specified together`},
+	} {
+		_, err := parse(`webhook-https://example.com?` + tc.params)
+		require.EqualError(t, err, tc.err, tc.params)
+	}
+
+	sanitized, err := sanitizeWebhookSinkURI(`webhook-https://example.com?batch_size=5&client_key=c2VjcmV0`)
+	require.NoError(t, err)
+	require.Equal(t, `webhook-https://example.com?batch_size=5&client_key=redacted`, sanitized)
+}
@@ -46,4 +46,9 @@ example:
 </PRE>
 `,
 	}
+
+	EncryptionPassphrase = cliflags.FlagInfo{
+		Name:        "encryption-passphrase",
+		Description: `Passphrase used to decrypt an encrypted backup.`,
+	}
 )
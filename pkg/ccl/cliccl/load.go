@@ -15,8 +15,10 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/cliccl/cliflagsccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/cli"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -24,6 +26,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var loadShowOpts struct {
+	encryptionPassphrase string
+}
+
 func init() {
 	loadShowCmd := &cobra.Command{
 		Use:   "show <basepath>",
@@ -31,6 +37,9 @@ func init() {
 		Long:  "Shows information about a SQL backup.",
 		RunE:  cli.MaybeDecorateGRPCError(runLoadShow),
 	}
+	cli.StringFlag(
+		loadShowCmd.Flags(), &loadShowOpts.encryptionPassphrase, cliflagsccl.EncryptionPassphrase, "",
+	)
 
 	loadCmds := &cobra.Command{
 		Use:   "load [command]",
@@ -58,7 +67,17 @@ func runLoadShow(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
-	desc, err := backupccl.ReadBackupDescriptorFromURI(ctx, basepath, cluster.NoSettings)
+	var encryption *roachpb.FileEncryptionOptions
+	if loadShowOpts.encryptionPassphrase != "" {
+		var err error
+		encryption, err = backupccl.EncryptionFromPassphrase(
+			ctx, basepath, cluster.NoSettings, loadShowOpts.encryptionPassphrase,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	desc, err := backupccl.ReadBackupDescriptorFromURI(ctx, basepath, cluster.NoSettings, encryption)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,281 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/linkedin/goavro"
+	"github.com/pkg/errors"
+)
+
+// The avro schemas used by EXPORT follow those used by changefeeds (see
+// changefeedccl/avro.go), but every field is nullable, more types are
+// supported and the decimal precision and scale are derived from the exported
+// data instead of the column's declared type.
+
+const (
+	exportAvroBoolean = `boolean`
+	exportAvroBytes   = `bytes`
+	exportAvroDouble  = `double`
+	exportAvroInt     = `int`
+	exportAvroLong    = `long`
+	exportAvroNull    = `null`
+	exportAvroString  = `string`
+)
+
+type exportAvroLogicalType struct {
+	SchemaType  string `json:"type"`
+	LogicalType string `json:"logicalType"`
+	Precision   int    `json:"precision,omitempty"`
+	Scale       int    `json:"scale,omitempty"`
+}
+
+type exportAvroArrayType struct {
+	SchemaType string        `json:"type"`
+	Items      []interface{} `json:"items"`
+}
+
+// exportAvroField is the schema of a single field in an exported record.
+// Serializing it to JSON gives the standard schema representation.
+type exportAvroField struct {
+	SchemaType []interface{} `json:"type"`
+	Name       string        `json:"name"`
+
+	encodeFn func(tree.Datum) (interface{}, error)
+}
+
+// exportAvroRecord is the schema of the records in an exported file.
+// Serializing it to JSON gives the standard schema representation.
+type exportAvroRecord struct {
+	SchemaType string             `json:"type"`
+	Name       string             `json:"name"`
+	Fields     []*exportAvroField `json:"fields"`
+}
+
+// exportAvroType returns the avro type used for values of the given
+// (non-array) type, the key used to refer to that type in a union, and the
+// function converting datums to goavro's native representation. Types without
+// a natural avro representation, including DECIMAL without a precision, are
+// exported as strings in the same format used by EXPORT INTO CSV.
+func exportAvroType(
+	typ sqlbase.ColumnType,
+) (interface{}, string, func(tree.Datum) (interface{}, error)) {
+	switch typ.SemanticType {
+	case sqlbase.ColumnType_BOOL:
+		return exportAvroBoolean, exportAvroBoolean, func(d tree.Datum) (interface{}, error) {
+			return bool(*d.(*tree.DBool)), nil
+		}
+	case sqlbase.ColumnType_INT:
+		return exportAvroLong, exportAvroLong, func(d tree.Datum) (interface{}, error) {
+			return int64(*d.(*tree.DInt)), nil
+		}
+	case sqlbase.ColumnType_FLOAT:
+		return exportAvroDouble, exportAvroDouble, func(d tree.Datum) (interface{}, error) {
+			return float64(*d.(*tree.DFloat)), nil
+		}
+	case sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME:
+		return exportAvroString, exportAvroString, func(d tree.Datum) (interface{}, error) {
+			return string(tree.MustBeDString(tree.UnwrapDatum(nil, d))), nil
+		}
+	case sqlbase.ColumnType_COLLATEDSTRING:
+		return exportAvroString, exportAvroString, func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DCollatedString).Contents, nil
+		}
+	case sqlbase.ColumnType_BYTES:
+		return exportAvroBytes, exportAvroBytes, func(d tree.Datum) (interface{}, error) {
+			return []byte(*d.(*tree.DBytes)), nil
+		}
+	case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		t := exportAvroLogicalType{SchemaType: exportAvroLong, LogicalType: `timestamp-micros`}
+		return t, exportAvroLong + `.` + t.LogicalType, func(d tree.Datum) (interface{}, error) {
+			switch d := d.(type) {
+			case *tree.DTimestamp:
+				return d.Time, nil
+			case *tree.DTimestampTZ:
+				return d.Time, nil
+			}
+			return nil, errors.Errorf("unexpected timestamp %T", d)
+		}
+	case sqlbase.ColumnType_DATE:
+		t := exportAvroLogicalType{SchemaType: exportAvroInt, LogicalType: `date`}
+		return t, exportAvroInt + `.` + t.LogicalType, func(d tree.Datum) (interface{}, error) {
+			return time.Unix(int64(*d.(*tree.DDate))*secondsPerDay, 0).UTC(), nil
+		}
+	case sqlbase.ColumnType_DECIMAL:
+		scale, precision, ok := exportDecimalScale(typ)
+		if !ok {
+			return exportAvroStringType()
+		}
+		t := exportAvroLogicalType{
+			SchemaType:  exportAvroBytes,
+			LogicalType: `decimal`,
+			Precision:   int(precision),
+			Scale:       int(scale),
+		}
+		denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+		return t, exportAvroBytes + `.` + t.LogicalType, func(d tree.Datum) (interface{}, error) {
+			unscaled, err := exportUnscaledDecimal(&d.(*tree.DDecimal).Decimal, scale, precision)
+			if err != nil {
+				return nil, err
+			}
+			return new(big.Rat).SetFrac(unscaled, denom), nil
+		}
+	case sqlbase.ColumnType_UUID:
+		// The avro uuid logical type is a string in the canonical format, which
+		// is how UUIDs are exported, but our avro library does not yet accept
+		// the annotation.
+		return exportAvroString, exportAvroString, func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DUuid).UUID.String(), nil
+		}
+	case sqlbase.ColumnType_JSONB:
+		// Avro has no JSON type, so JSON is exported as its string encoding.
+		return exportAvroString, exportAvroString, func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DJSON).JSON.String(), nil
+		}
+	default:
+		return exportAvroStringType()
+	}
+}
+
+// exportAvroStringType is like exportAvroType for types that are exported as
+// strings in the same format used by EXPORT INTO CSV.
+func exportAvroStringType() (interface{}, string, func(tree.Datum) (interface{}, error)) {
+	return exportAvroString, exportAvroString, func(d tree.Datum) (interface{}, error) {
+		return tree.AsStringWithFlags(d, tree.FmtParseDatums), nil
+	}
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+// exportAvroNullable wraps the given encode function, for a type with the
+// given union key, to produce values for a ["null", T] union.
+func exportAvroNullable(
+	unionKey string, encodeFn func(tree.Datum) (interface{}, error),
+) func(tree.Datum) (interface{}, error) {
+	return func(d tree.Datum) (interface{}, error) {
+		if d == tree.DNull {
+			return goavro.Union(exportAvroNull, nil), nil
+		}
+		encoded, err := encodeFn(d)
+		if err != nil {
+			return nil, err
+		}
+		return goavro.Union(unionKey, encoded), nil
+	}
+}
+
+// exportAvroFieldForColumn returns the schema of the field used for a column
+// with the given name and type.
+func exportAvroFieldForColumn(name string, typ sqlbase.ColumnType) *exportAvroField {
+	array := typ.SemanticType == sqlbase.ColumnType_ARRAY
+	if array {
+		// The precision and width of an array are those of its elements.
+		typ.SemanticType, typ.ArrayContents = *typ.ArrayContents, nil
+	}
+	avroType, unionKey, encodeFn := exportAvroType(typ)
+	encodeFn = exportAvroNullable(unionKey, encodeFn)
+	if array {
+		avroType = exportAvroArrayType{
+			SchemaType: `array`,
+			Items:      []interface{}{exportAvroNull, avroType},
+		}
+		unionKey = `array`
+		elemEncodeFn := encodeFn
+		encodeFn = func(d tree.Datum) (interface{}, error) {
+			arr := tree.UnwrapDatum(nil, d).(*tree.DArray)
+			elems := make([]interface{}, len(arr.Array))
+			for i, e := range arr.Array {
+				var err error
+				if elems[i], err = elemEncodeFn(e); err != nil {
+					return nil, err
+				}
+			}
+			return elems, nil
+		}
+		encodeFn = exportAvroNullable(unionKey, encodeFn)
+	}
+	return &exportAvroField{
+		Name: exportAvroName(name),
+		// The default for a union type is the default for the first element of
+		// the union, which for exported fields should be null.
+		SchemaType: []interface{}{exportAvroNull, avroType},
+		encodeFn:   encodeFn,
+	}
+}
+
+// exportAvroName escapes a sql column name into a valid avro field name, using
+// the same _u<hex>_ escaping as changefeedccl.SQLNameToAvroName.
+//
+// Avro allows names matching `[a-zA-Z_][a-zA-Z0-9_]*`.
+func exportAvroName(s string) string {
+	var ret strings.Builder
+	for i, r := range s {
+		allowed := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(i > 0 && r >= '0' && r <= '9')
+		if allowed {
+			ret.WriteRune(r)
+		} else if r <= 1<<16 {
+			fmt.Fprintf(&ret, `_u%04x_`, r)
+		} else {
+			fmt.Fprintf(&ret, `_u%08x_`, r)
+		}
+	}
+	return ret.String()
+}
+
+// encodeAvroOCF implements exportFileEncoder for avro object container files.
+func encodeAvroOCF(names []string, types []sqlbase.ColumnType, rows []tree.Datums) ([]byte, error) {
+	schema := &exportAvroRecord{
+		SchemaType: `record`,
+		Name:       `row`,
+	}
+	seen := make(map[string]struct{}, len(names))
+	for i, typ := range types {
+		field := exportAvroFieldForColumn(names[i], typ)
+		if _, ok := seen[field.Name]; ok {
+			return nil, errors.Errorf("duplicate avro field name %q for column %s", field.Name, names[i])
+		}
+		seen[field.Name] = struct{}{}
+		schema.Fields = append(schema.Fields, field)
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]interface{}, len(rows))
+	for i, row := range rows {
+		record := make(map[string]interface{}, len(row))
+		for j, d := range row {
+			field := schema.Fields[j]
+			if record[field.Name], err = field.encodeFn(d); err != nil {
+				return nil, errors.Wrapf(err, "column %s", names[j])
+			}
+		}
+		records[i] = record
+	}
+
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: string(schemaJSON)})
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Append(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
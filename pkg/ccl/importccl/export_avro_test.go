@@ -0,0 +1,173 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/linkedin/goavro"
+)
+
+func TestEncodeAvroOCF(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stringArrayContents := sqlbase.ColumnType_STRING
+	names := []string{`i`, `s`, `d`, `ts`, `date`, `u`, `?column?`, `a`, `ud`}
+	colTypes := []sqlbase.ColumnType{
+		{SemanticType: sqlbase.ColumnType_INT},
+		{SemanticType: sqlbase.ColumnType_STRING},
+		{SemanticType: sqlbase.ColumnType_DECIMAL, Precision: 3, Width: 2},
+		{SemanticType: sqlbase.ColumnType_TIMESTAMP},
+		{SemanticType: sqlbase.ColumnType_DATE},
+		{SemanticType: sqlbase.ColumnType_UUID},
+		{SemanticType: sqlbase.ColumnType_INTERVAL},
+		{SemanticType: sqlbase.ColumnType_ARRAY, ArrayContents: &stringArrayContents},
+		{SemanticType: sqlbase.ColumnType_DECIMAL},
+	}
+	u := uuid.MakeV4()
+	ts := time.Date(2018, 12, 1, 2, 3, 4, 5000, time.UTC)
+	d, err := tree.ParseDDecimal(`-1.25`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interval, err := tree.ParseDInterval(`1h`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := tree.NewDArray(types.String)
+	for _, e := range []tree.Datum{tree.NewDString(`x`), tree.DNull} {
+		if err := arr.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows := []tree.Datums{
+		{
+			tree.NewDInt(1), tree.NewDString(`a`), d, tree.MakeDTimestamp(ts, time.Microsecond),
+			tree.NewDDate(17866), tree.NewDUuid(tree.DUuid{UUID: u}), interval, arr, d,
+		},
+		{
+			tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+			tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+		},
+	}
+
+	file, err := encodeAvroOCF(names, colTypes, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := goavro.NewOCFReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Fields []struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(r.Codec().Schema()), &schema); err != nil {
+		t.Fatal(err)
+	}
+	// Normalize the JSON so that the comparison ignores formatting and key
+	// order.
+	normalize := func(s string) string {
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	fieldTypes := make(map[string]string)
+	for _, f := range schema.Fields {
+		fieldTypes[f.Name] = normalize(string(f.Type))
+	}
+	expectedTypes := map[string]string{
+		`i`:    `["null","long"]`,
+		`s`:    `["null","string"]`,
+		`d`:    `["null",{"type":"bytes","logicalType":"decimal","precision":3,"scale":2}]`,
+		`ts`:   `["null",{"type":"long","logicalType":"timestamp-micros"}]`,
+		`date`: `["null",{"type":"int","logicalType":"date"}]`,
+		`u`:    `["null","string"]`,
+		`a`:    `["null",{"type":"array","items":["null","string"]}]`,
+		// A DECIMAL without a precision is exported as a string.
+		`ud`: `["null","string"]`,
+		// Names are escaped to be valid avro names.
+		`_u003f_column_u003f_`: `["null","string"]`,
+	}
+	for name, typ := range expectedTypes {
+		expectedTypes[name] = normalize(typ)
+	}
+	if !reflect.DeepEqual(expectedTypes, fieldTypes) {
+		t.Errorf("expected %v got %v", expectedTypes, fieldTypes)
+	}
+
+	var records []map[string]interface{}
+	for r.Scan() {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record.(map[string]interface{}))
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records got %d", len(records))
+	}
+	value := func(name, unionKey string) interface{} {
+		return records[0][name].(map[string]interface{})[unionKey]
+	}
+	if v := value(`i`, `long`); v != int64(1) {
+		t.Errorf("expected 1 got %v", v)
+	}
+	if v := value(`s`, `string`); v != `a` {
+		t.Errorf("expected a got %v", v)
+	}
+	if v := value(`d`, `bytes.decimal`).(*big.Rat); v.Cmp(big.NewRat(-125, 100)) != 0 {
+		t.Errorf("expected -1.25 got %v", v)
+	}
+	if v := value(`ud`, `string`); v != `-1.25` {
+		t.Errorf("expected -1.25 got %v", v)
+	}
+	if v := value(`ts`, `long.timestamp-micros`).(time.Time); !v.Equal(ts) {
+		t.Errorf("expected %v got %v", ts, v)
+	}
+	date := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC)
+	if v := value(`date`, `int.date`).(time.Time); !v.Equal(date) {
+		t.Errorf("expected %v got %v", date, v)
+	}
+	if v := value(`u`, `string`); v != u.String() {
+		t.Errorf("expected %s got %v", u, v)
+	}
+	if v := value(`_u003f_column_u003f_`, `string`); v != `01:00:00` {
+		t.Errorf("expected 01:00:00 got %v", v)
+	}
+	expectedArray := []interface{}{map[string]interface{}{`string`: `x`}, nil}
+	if v := value(`a`, `array`); !reflect.DeepEqual(expectedArray, v) {
+		t.Errorf("expected %v got %v", expectedArray, v)
+	}
+	for name, value := range records[1] {
+		if value != nil {
+			t.Errorf("%s: expected nil got %v", name, value)
+		}
+	}
+}
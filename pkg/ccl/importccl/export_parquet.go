@@ -0,0 +1,603 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/bits"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/pkg/errors"
+)
+
+// This file contains a minimal writer for the Apache Parquet file format
+// (https://github.com/apache/parquet-format). Each file has a single row group
+// with a single uncompressed, PLAIN encoded data page per column, which keeps
+// the writer simple while still producing files that any Parquet reader can
+// consume. The file metadata is serialized with the thrift compact protocol, as
+// required by the format.
+
+const parquetMagic = "PAR1"
+
+// Values of the enums defined in parquet.thrift.
+const (
+	parquetTypeBoolean           = 0
+	parquetTypeInt32             = 1
+	parquetTypeInt64             = 2
+	parquetTypeDouble            = 5
+	parquetTypeByteArray         = 6
+	parquetTypeFixedLenByteArray = 7
+
+	parquetConvertedNone            = -1
+	parquetConvertedUTF8            = 0
+	parquetConvertedList            = 3
+	parquetConvertedDecimal         = 5
+	parquetConvertedDate            = 6
+	parquetConvertedTimestampMicros = 10
+	parquetConvertedJSON            = 19
+
+	parquetRepetitionOptional = 1
+	parquetRepetitionRepeated = 2
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+
+	parquetPageTypeData = 0
+
+	// parquetLogicalTypeUUID is the field id of UUID in the LogicalType union.
+	// UUIDs have no ConvertedType, so this is the only way to annotate them.
+	parquetLogicalTypeUUID = 14
+)
+
+// parquetLeaf describes how values of a sql type are stored in a Parquet
+// column.
+type parquetLeaf struct {
+	physical   int32
+	typeLength int32
+	converted  int32
+	logical    int16
+	scale      int32
+	precision  int32
+	// encode appends the PLAIN encoding of the non-NULL datum to buf. Booleans
+	// are appended as one byte per value and bit-packed when the page is built.
+	encode func(buf *bytes.Buffer, d tree.Datum) error
+}
+
+// parquetColumn accumulates the levels and values of one leaf column of a
+// Parquet file.
+type parquetColumn struct {
+	name  string
+	leaf  parquetLeaf
+	array bool
+
+	defLevels []int32
+	repLevels []int32
+	values    bytes.Buffer
+}
+
+func (c *parquetColumn) maxDefinitionLevel() int32 {
+	if c.array {
+		// optional group (LIST) -> repeated group -> optional element.
+		return 3
+	}
+	return 1
+}
+
+func (c *parquetColumn) maxRepetitionLevel() int32 {
+	if c.array {
+		return 1
+	}
+	return 0
+}
+
+func (c *parquetColumn) path() []string {
+	if c.array {
+		return []string{c.name, "list", "element"}
+	}
+	return []string{c.name}
+}
+
+// add records the given datum, which may be NULL, as the value of this column
+// in the next row.
+func (c *parquetColumn) add(d tree.Datum) error {
+	if !c.array {
+		if d == tree.DNull {
+			c.defLevels = append(c.defLevels, 0)
+			return nil
+		}
+		c.defLevels = append(c.defLevels, 1)
+		return c.leaf.encode(&c.values, d)
+	}
+
+	if d == tree.DNull {
+		c.defLevels = append(c.defLevels, 0)
+		c.repLevels = append(c.repLevels, 0)
+		return nil
+	}
+	arr := tree.UnwrapDatum(nil, d).(*tree.DArray)
+	if len(arr.Array) == 0 {
+		c.defLevels = append(c.defLevels, 1)
+		c.repLevels = append(c.repLevels, 0)
+		return nil
+	}
+	for i, e := range arr.Array {
+		rep := int32(1)
+		if i == 0 {
+			rep = 0
+		}
+		c.repLevels = append(c.repLevels, rep)
+		if e == tree.DNull {
+			c.defLevels = append(c.defLevels, 2)
+			continue
+		}
+		c.defLevels = append(c.defLevels, 3)
+		if err := c.leaf.encode(&c.values, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// page returns the contents of the column's data page: the repetition levels,
+// the definition levels and then the values.
+func (c *parquetColumn) page() []byte {
+	var buf bytes.Buffer
+	if max := c.maxRepetitionLevel(); max > 0 {
+		writeParquetLevels(&buf, c.repLevels, max)
+	}
+	writeParquetLevels(&buf, c.defLevels, c.maxDefinitionLevel())
+	if c.leaf.physical == parquetTypeBoolean {
+		// PLAIN encoded booleans are bit-packed, least significant bit first.
+		bools := c.values.Bytes()
+		packed := make([]byte, (len(bools)+7)/8)
+		for i, b := range bools {
+			packed[i/8] |= b << uint(i%8)
+		}
+		buf.Write(packed)
+	} else {
+		buf.Write(c.values.Bytes())
+	}
+	return buf.Bytes()
+}
+
+// writeParquetLevels appends the levels in the RLE/bit-packing hybrid
+// encoding, prefixed by its length. Only RLE runs are used.
+func writeParquetLevels(buf *bytes.Buffer, levels []int32, max int32) {
+	byteWidth := (bits.Len32(uint32(max)) + 7) / 8
+
+	var runs bytes.Buffer
+	var scratch [binary.MaxVarintLen64]byte
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		n := binary.PutUvarint(scratch[:], uint64(j-i)<<1)
+		runs.Write(scratch[:n])
+		for b := 0; b < byteWidth; b++ {
+			runs.WriteByte(byte(levels[i] >> uint(8*b)))
+		}
+		i = j
+	}
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(runs.Len()))
+	buf.Write(length[:])
+	buf.Write(runs.Bytes())
+}
+
+func writeParquetByteArray(buf *bytes.Buffer, b []byte) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(b)))
+	buf.Write(length[:])
+	buf.Write(b)
+}
+
+// parquetLeafForType returns how values of the given (non-array) type are
+// stored. Types without a natural Parquet representation, including DECIMAL
+// without a precision, are exported as UTF8 strings in the same format used
+// by EXPORT INTO CSV.
+func parquetLeafForType(typ sqlbase.ColumnType) parquetLeaf {
+	var scratch [8]byte
+	switch typ.SemanticType {
+	case sqlbase.ColumnType_BOOL:
+		return parquetLeaf{
+			physical:  parquetTypeBoolean,
+			converted: parquetConvertedNone,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				if *d.(*tree.DBool) {
+					return buf.WriteByte(1)
+				}
+				return buf.WriteByte(0)
+			},
+		}
+	case sqlbase.ColumnType_INT:
+		return parquetLeaf{
+			physical:  parquetTypeInt64,
+			converted: parquetConvertedNone,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				binary.LittleEndian.PutUint64(scratch[:], uint64(*d.(*tree.DInt)))
+				_, err := buf.Write(scratch[:8])
+				return err
+			},
+		}
+	case sqlbase.ColumnType_FLOAT:
+		return parquetLeaf{
+			physical:  parquetTypeDouble,
+			converted: parquetConvertedNone,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(float64(*d.(*tree.DFloat))))
+				_, err := buf.Write(scratch[:8])
+				return err
+			},
+		}
+	case sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME:
+		return parquetLeaf{
+			physical:  parquetTypeByteArray,
+			converted: parquetConvertedUTF8,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				writeParquetByteArray(buf, []byte(tree.MustBeDString(tree.UnwrapDatum(nil, d))))
+				return nil
+			},
+		}
+	case sqlbase.ColumnType_COLLATEDSTRING:
+		return parquetLeaf{
+			physical:  parquetTypeByteArray,
+			converted: parquetConvertedUTF8,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				writeParquetByteArray(buf, []byte(d.(*tree.DCollatedString).Contents))
+				return nil
+			},
+		}
+	case sqlbase.ColumnType_BYTES:
+		return parquetLeaf{
+			physical:  parquetTypeByteArray,
+			converted: parquetConvertedNone,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				writeParquetByteArray(buf, []byte(*d.(*tree.DBytes)))
+				return nil
+			},
+		}
+	case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		return parquetLeaf{
+			physical:  parquetTypeInt64,
+			converted: parquetConvertedTimestampMicros,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				var t time.Time
+				switch d := d.(type) {
+				case *tree.DTimestamp:
+					t = d.Time
+				case *tree.DTimestampTZ:
+					t = d.Time
+				}
+				micros := t.Unix()*1000000 + int64(t.Nanosecond()/1000)
+				binary.LittleEndian.PutUint64(scratch[:], uint64(micros))
+				_, err := buf.Write(scratch[:8])
+				return err
+			},
+		}
+	case sqlbase.ColumnType_DATE:
+		return parquetLeaf{
+			physical:  parquetTypeInt32,
+			converted: parquetConvertedDate,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				binary.LittleEndian.PutUint32(scratch[:], uint32(int32(*d.(*tree.DDate))))
+				_, err := buf.Write(scratch[:4])
+				return err
+			},
+		}
+	case sqlbase.ColumnType_DECIMAL:
+		scale, precision, ok := exportDecimalScale(typ)
+		if !ok {
+			return parquetStringLeaf()
+		}
+		return parquetLeaf{
+			physical:  parquetTypeByteArray,
+			converted: parquetConvertedDecimal,
+			scale:     scale,
+			precision: precision,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				unscaled, err := exportUnscaledDecimal(&d.(*tree.DDecimal).Decimal, scale, precision)
+				if err != nil {
+					return err
+				}
+				writeParquetByteArray(buf, bigIntToTwosComplement(unscaled))
+				return nil
+			},
+		}
+	case sqlbase.ColumnType_UUID:
+		return parquetLeaf{
+			physical:   parquetTypeFixedLenByteArray,
+			typeLength: 16,
+			converted:  parquetConvertedNone,
+			logical:    parquetLogicalTypeUUID,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				_, err := buf.Write(d.(*tree.DUuid).GetBytes())
+				return err
+			},
+		}
+	case sqlbase.ColumnType_JSONB:
+		return parquetLeaf{
+			physical:  parquetTypeByteArray,
+			converted: parquetConvertedJSON,
+			encode: func(buf *bytes.Buffer, d tree.Datum) error {
+				writeParquetByteArray(buf, []byte(d.(*tree.DJSON).JSON.String()))
+				return nil
+			},
+		}
+	default:
+		return parquetStringLeaf()
+	}
+}
+
+// parquetStringLeaf returns the leaf used for types that are exported as UTF8
+// strings in the same format used by EXPORT INTO CSV.
+func parquetStringLeaf() parquetLeaf {
+	return parquetLeaf{
+		physical:  parquetTypeByteArray,
+		converted: parquetConvertedUTF8,
+		encode: func(buf *bytes.Buffer, d tree.Datum) error {
+			writeParquetByteArray(buf, []byte(tree.AsStringWithFlags(d, tree.FmtParseDatums)))
+			return nil
+		},
+	}
+}
+
+// bigIntToTwosComplement returns the minimal big-endian two's complement
+// representation of i, which is how Parquet (and Avro) store unscaled
+// decimals.
+func bigIntToTwosComplement(i *big.Int) []byte {
+	if i.Sign() >= 0 {
+		b := i.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			// Make room for the sign bit.
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// For negative i, the two's complement is 2^(8*n) + i for the smallest n
+	// with -2^(8*n-1) <= i, which always has the sign bit set.
+	n := (new(big.Int).Not(i).BitLen() + 8) / 8
+	mod := new(big.Int).Lsh(big.NewInt(1), uint(8*n))
+	return mod.Add(mod, i).Bytes()
+}
+
+// encodeParquet implements exportFileEncoder for Parquet.
+func encodeParquet(names []string, types []sqlbase.ColumnType, rows []tree.Datums) ([]byte, error) {
+	cols := make([]*parquetColumn, len(types))
+	for i, typ := range types {
+		c := &parquetColumn{name: names[i]}
+		if typ.SemanticType == sqlbase.ColumnType_ARRAY {
+			c.array = true
+			// The precision and width of an array are those of its elements.
+			typ.SemanticType, typ.ArrayContents = *typ.ArrayContents, nil
+		}
+		c.leaf = parquetLeafForType(typ)
+		cols[i] = c
+	}
+	for _, row := range rows {
+		for i, d := range row {
+			if err := cols[i].add(d); err != nil {
+				return nil, errors.Wrapf(err, "column %s", names[i])
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+
+	type chunkMeta struct {
+		offset, size int64
+	}
+	chunks := make([]chunkMeta, len(cols))
+	for i, c := range cols {
+		page := c.page()
+		header, err := parquetThrift(func(w *parquetThriftWriter) {
+			w.i32(1, parquetPageTypeData)
+			w.i32(2, int32(len(page)))
+			w.i32(3, int32(len(page)))
+			w.structField(5, func() {
+				w.i32(1, int32(len(c.defLevels)))
+				w.i32(2, parquetEncodingPlain)
+				w.i32(3, parquetEncodingRLE)
+				w.i32(4, parquetEncodingRLE)
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		chunks[i] = chunkMeta{offset: int64(out.Len()), size: int64(len(header) + len(page))}
+		out.Write(header)
+		out.Write(page)
+	}
+
+	var totalSize int64
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+	footer, err := parquetThrift(func(w *parquetThriftWriter) {
+		w.i32(1, 1 /* version */)
+		w.listField(2, thrift.STRUCT, 1+len(cols)+2*numArrays(cols), func() {
+			w.structElem(func() {
+				w.str(4, "schema")
+				w.i32(5, int32(len(cols)))
+			})
+			for _, c := range cols {
+				if c.array {
+					w.structElem(func() {
+						w.i32(3, parquetRepetitionOptional)
+						w.str(4, c.name)
+						w.i32(5, 1)
+						w.i32(6, parquetConvertedList)
+					})
+					w.structElem(func() {
+						w.i32(3, parquetRepetitionRepeated)
+						w.str(4, "list")
+						w.i32(5, 1)
+					})
+				}
+				w.structElem(func() {
+					w.i32(1, c.leaf.physical)
+					if c.leaf.typeLength > 0 {
+						w.i32(2, c.leaf.typeLength)
+					}
+					w.i32(3, parquetRepetitionOptional)
+					if c.array {
+						w.str(4, "element")
+					} else {
+						w.str(4, c.name)
+					}
+					if c.leaf.converted != parquetConvertedNone {
+						w.i32(6, c.leaf.converted)
+					}
+					if c.leaf.converted == parquetConvertedDecimal {
+						w.i32(7, c.leaf.scale)
+						w.i32(8, c.leaf.precision)
+					}
+					if c.leaf.logical != 0 {
+						w.structField(10, func() {
+							w.structField(c.leaf.logical, func() {})
+						})
+					}
+				})
+			}
+		})
+		w.i64(3, int64(len(rows)))
+		w.listField(4, thrift.STRUCT, 1, func() {
+			w.structElem(func() {
+				w.listField(1, thrift.STRUCT, len(cols), func() {
+					for i, c := range cols {
+						chunk := chunks[i]
+						w.structElem(func() {
+							w.i64(2, chunk.offset)
+							w.structField(3, func() {
+								w.i32(1, c.leaf.physical)
+								w.listField(2, thrift.I32, 2, func() {
+									w.do(func() error { return w.p.WriteI32(parquetEncodingPlain) })
+									w.do(func() error { return w.p.WriteI32(parquetEncodingRLE) })
+								})
+								path := c.path()
+								w.listField(3, thrift.STRING, len(path), func() {
+									for _, p := range path {
+										p := p
+										w.do(func() error { return w.p.WriteString(p) })
+									}
+								})
+								w.i32(4, parquetCodecUncompressed)
+								w.i64(5, int64(len(c.defLevels)))
+								w.i64(6, chunk.size)
+								w.i64(7, chunk.size)
+								w.i64(9, chunk.offset)
+							})
+						})
+					}
+				})
+				w.i64(2, totalSize)
+				w.i64(3, int64(len(rows)))
+			})
+		})
+		w.str(6, "CockroachDB "+build.GetInfo().Tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Write(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	out.Write(length[:])
+	out.WriteString(parquetMagic)
+	return out.Bytes(), nil
+}
+
+func numArrays(cols []*parquetColumn) int {
+	var n int
+	for _, c := range cols {
+		if c.array {
+			n++
+		}
+	}
+	return n
+}
+
+// parquetThriftWriter is a small helper for writing thrift structs with the
+// compact protocol. The first error encountered is remembered and all later
+// writes are skipped.
+type parquetThriftWriter struct {
+	p   *thrift.TCompactProtocol
+	err error
+}
+
+// parquetThrift returns the serialization of the struct whose fields are
+// written by fn.
+func parquetThrift(fn func(w *parquetThriftWriter)) ([]byte, error) {
+	buf := thrift.NewTMemoryBuffer()
+	w := &parquetThriftWriter{p: thrift.NewTCompactProtocol(buf)}
+	w.structElem(func() { fn(w) })
+	w.do(func() error { return w.p.Flush(context.Background()) })
+	if w.err != nil {
+		return nil, errors.Wrap(w.err, "writing parquet metadata")
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *parquetThriftWriter) do(fn func() error) {
+	if w.err == nil {
+		w.err = fn()
+	}
+}
+
+func (w *parquetThriftWriter) field(id int16, typ thrift.TType) {
+	w.do(func() error { return w.p.WriteFieldBegin("", typ, id) })
+}
+
+func (w *parquetThriftWriter) i32(id int16, v int32) {
+	w.field(id, thrift.I32)
+	w.do(func() error { return w.p.WriteI32(v) })
+}
+
+func (w *parquetThriftWriter) i64(id int16, v int64) {
+	w.field(id, thrift.I64)
+	w.do(func() error { return w.p.WriteI64(v) })
+}
+
+func (w *parquetThriftWriter) str(id int16, v string) {
+	w.field(id, thrift.STRING)
+	w.do(func() error { return w.p.WriteString(v) })
+}
+
+// structElem writes a struct, with fields written by fn, as a list element or
+// as the top-level value.
+func (w *parquetThriftWriter) structElem(fn func()) {
+	w.do(func() error { return w.p.WriteStructBegin("") })
+	fn()
+	w.do(w.p.WriteFieldStop)
+	w.do(w.p.WriteStructEnd)
+}
+
+func (w *parquetThriftWriter) structField(id int16, fn func()) {
+	w.field(id, thrift.STRUCT)
+	w.structElem(fn)
+}
+
+func (w *parquetThriftWriter) listField(id int16, elemType thrift.TType, size int, fn func()) {
+	w.field(id, thrift.LIST)
+	w.do(func() error { return w.p.WriteListBegin(elemType, size) })
+	fn()
+	w.do(w.p.WriteListEnd)
+}
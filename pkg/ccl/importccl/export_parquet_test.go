@@ -0,0 +1,362 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// readThrift decodes a thrift compact protocol struct into a map from field id
+// to value, with nested structs as maps and lists as slices.
+func readThrift(t *testing.T, p *thrift.TCompactProtocol) map[int16]interface{} {
+	t.Helper()
+	fields := make(map[int16]interface{})
+	if _, err := p.ReadStructBegin(); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, typ, id, err := p.ReadFieldBegin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ == thrift.STOP {
+			break
+		}
+		fields[id] = readThriftValue(t, p, typ)
+	}
+	if err := p.ReadStructEnd(); err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+func readThriftValue(t *testing.T, p *thrift.TCompactProtocol, typ thrift.TType) interface{} {
+	t.Helper()
+	var v interface{}
+	var err error
+	switch typ {
+	case thrift.I32:
+		v, err = p.ReadI32()
+	case thrift.I64:
+		v, err = p.ReadI64()
+	case thrift.STRING:
+		v, err = p.ReadString()
+	case thrift.STRUCT:
+		v = readThrift(t, p)
+	case thrift.LIST:
+		var elemType thrift.TType
+		var size int
+		elemType, size, err = p.ReadListBegin()
+		var elems []interface{}
+		for i := 0; err == nil && i < size; i++ {
+			elems = append(elems, readThriftValue(t, p, elemType))
+		}
+		v = elems
+	default:
+		t.Fatalf("unexpected thrift type %s", typ)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func readThriftBytes(t *testing.T, b []byte) (map[int16]interface{}, int) {
+	t.Helper()
+	buf := thrift.NewTMemoryBuffer()
+	buf.Write(b)
+	fields := readThrift(t, thrift.NewTCompactProtocol(buf))
+	return fields, len(b) - buf.Len()
+}
+
+// readParquetLevels decodes levels written by writeParquetLevels, returning
+// them and the rest of the page.
+func readParquetLevels(t *testing.T, page []byte, byteWidth int) ([]int32, []byte) {
+	t.Helper()
+	length := int(binary.LittleEndian.Uint32(page))
+	runs, rest := page[4:4+length], page[4+length:]
+	var levels []int32
+	for len(runs) > 0 {
+		header, n := binary.Uvarint(runs)
+		if header&1 != 0 {
+			t.Fatalf("unexpected bit-packed run")
+		}
+		var level int32
+		for b := 0; b < byteWidth; b++ {
+			level |= int32(runs[n+b]) << uint(8*b)
+		}
+		for i := uint64(0); i < header>>1; i++ {
+			levels = append(levels, level)
+		}
+		runs = runs[n+byteWidth:]
+	}
+	return levels, rest
+}
+
+func TestEncodeParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	intType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+	intArrayContents := sqlbase.ColumnType_INT
+	names := []string{`i`, `s`, `d`, `u`, `ts`, `b`, `j`, `a`}
+	colTypes := []sqlbase.ColumnType{
+		intType,
+		{SemanticType: sqlbase.ColumnType_STRING},
+		{SemanticType: sqlbase.ColumnType_DECIMAL, Precision: 4, Width: 1},
+		{SemanticType: sqlbase.ColumnType_UUID},
+		{SemanticType: sqlbase.ColumnType_TIMESTAMP},
+		{SemanticType: sqlbase.ColumnType_BOOL},
+		{SemanticType: sqlbase.ColumnType_JSONB},
+		{SemanticType: sqlbase.ColumnType_ARRAY, ArrayContents: &intArrayContents},
+	}
+	u := uuid.MakeV4()
+	ts := time.Date(2018, 12, 1, 2, 3, 4, 5000, time.UTC)
+	j, err := json.ParseJSON(`{"a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := func(s string) tree.Datum {
+		d, err := tree.ParseDDecimal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	arr := func(elems ...tree.Datum) tree.Datum {
+		a := tree.NewDArray(types.Int)
+		for _, e := range elems {
+			if err := a.Append(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return a
+	}
+	rows := []tree.Datums{
+		{
+			tree.NewDInt(1), tree.NewDString(`a`), dec(`1.5`), tree.NewDUuid(tree.DUuid{UUID: u}),
+			tree.MakeDTimestamp(ts, time.Microsecond), tree.DBoolTrue, tree.NewDJSON(j),
+			arr(tree.NewDInt(1), tree.DNull, tree.NewDInt(3)),
+		},
+		{
+			tree.DNull, tree.DNull, dec(`-200`), tree.DNull,
+			tree.DNull, tree.DBoolFalse, tree.DNull,
+			tree.DNull,
+		},
+		{
+			tree.NewDInt(3), tree.NewDString(`c`), tree.DNull, tree.DNull,
+			tree.DNull, tree.DNull, tree.DNull,
+			arr(),
+		},
+	}
+
+	file, err := encodeParquet(names, colTypes, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(file, []byte(parquetMagic)) || !bytes.HasSuffix(file, []byte(parquetMagic)) {
+		t.Fatalf("missing magic: %q", file)
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer, _ := readThriftBytes(t, file[len(file)-8-footerLen:len(file)-8])
+
+	if numRows := footer[3].(int64); numRows != 3 {
+		t.Errorf("expected 3 rows got %d", numRows)
+	}
+
+	type schemaElement struct {
+		name                 string
+		physical, converted  interface{}
+		scale, precision     interface{}
+		repetition, children interface{}
+		hasLogical           bool
+	}
+	var schema []schemaElement
+	for _, e := range footer[2].([]interface{}) {
+		e := e.(map[int16]interface{})
+		_, hasLogical := e[10]
+		schema = append(schema, schemaElement{
+			name: e[4].(string), physical: e[1], converted: e[6], scale: e[7], precision: e[8],
+			repetition: e[3], children: e[5], hasLogical: hasLogical,
+		})
+	}
+	optional := int32(parquetRepetitionOptional)
+	expectedSchema := []schemaElement{
+		{name: `schema`, children: int32(8)},
+		{name: `i`, physical: int32(parquetTypeInt64), repetition: optional},
+		{name: `s`, physical: int32(parquetTypeByteArray), converted: int32(parquetConvertedUTF8), repetition: optional},
+		{
+			name: `d`, physical: int32(parquetTypeByteArray), converted: int32(parquetConvertedDecimal),
+			scale: int32(1), precision: int32(4), repetition: optional,
+		},
+		{name: `u`, physical: int32(parquetTypeFixedLenByteArray), repetition: optional, hasLogical: true},
+		{
+			name: `ts`, physical: int32(parquetTypeInt64), converted: int32(parquetConvertedTimestampMicros),
+			repetition: optional,
+		},
+		{name: `b`, physical: int32(parquetTypeBoolean), repetition: optional},
+		{name: `j`, physical: int32(parquetTypeByteArray), converted: int32(parquetConvertedJSON), repetition: optional},
+		{name: `a`, converted: int32(parquetConvertedList), repetition: optional, children: int32(1)},
+		{name: `list`, repetition: int32(parquetRepetitionRepeated), children: int32(1)},
+		{name: `element`, physical: int32(parquetTypeInt64), repetition: optional},
+	}
+	if !reflect.DeepEqual(expectedSchema, schema) {
+		t.Errorf("expected schema\n%+v\ngot\n%+v", expectedSchema, schema)
+	}
+
+	rowGroup := footer[4].([]interface{})[0].(map[int16]interface{})
+	chunks := rowGroup[1].([]interface{})
+	if len(chunks) != len(names) {
+		t.Fatalf("expected %d column chunks got %d", len(names), len(chunks))
+	}
+	// readPage returns the definition levels, repetition levels and values of
+	// the page in the given column chunk.
+	readPage := func(col int) (def []int32, rep []int32, values []byte) {
+		meta := chunks[col].(map[int16]interface{})[3].(map[int16]interface{})
+		offset := meta[9].(int64)
+		header, n := readThriftBytes(t, file[offset:])
+		size := int(header[3].(int32))
+		page := file[int(offset)+n : int(offset)+n+size]
+		if numValues := header[5].(map[int16]interface{})[1].(int32); int64(numValues) != meta[5].(int64) {
+			t.Errorf("num_values mismatch %d vs %d", numValues, meta[5])
+		}
+		if names[col] == `a` {
+			rep, page = readParquetLevels(t, page, 1)
+		}
+		def, values = readParquetLevels(t, page, 1)
+		return def, rep, values
+	}
+	byteArrays := func(values []byte) []string {
+		var ret []string
+		for len(values) > 0 {
+			l := binary.LittleEndian.Uint32(values)
+			ret = append(ret, string(values[4:4+l]))
+			values = values[4+l:]
+		}
+		return ret
+	}
+	le64 := func(vs ...int64) []byte {
+		var ret []byte
+		for _, v := range vs {
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], uint64(v))
+			ret = append(ret, b[:]...)
+		}
+		return ret
+	}
+
+	t.Run(`int`, func(t *testing.T) {
+		def, _, values := readPage(0)
+		if expected := []int32{1, 0, 1}; !reflect.DeepEqual(expected, def) {
+			t.Errorf("expected %v got %v", expected, def)
+		}
+		if expected := le64(1, 3); !bytes.Equal(expected, values) {
+			t.Errorf("expected %v got %v", expected, values)
+		}
+	})
+	t.Run(`string`, func(t *testing.T) {
+		_, _, values := readPage(1)
+		if expected, actual := []string{`a`, `c`}, byteArrays(values); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %v got %v", expected, actual)
+		}
+	})
+	t.Run(`decimal`, func(t *testing.T) {
+		def, _, values := readPage(2)
+		if expected := []int32{1, 1, 0}; !reflect.DeepEqual(expected, def) {
+			t.Errorf("expected %v got %v", expected, def)
+		}
+		var actual []string
+		for _, b := range byteArrays(values) {
+			i := new(big.Int).SetBytes([]byte(b))
+			if b[0]&0x80 != 0 {
+				i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+			}
+			actual = append(actual, i.String())
+		}
+		// With a scale of 1, 1.5 is 15 and -200 is -2000.
+		if expected := []string{`15`, `-2000`}; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %v got %v", expected, actual)
+		}
+	})
+	t.Run(`uuid`, func(t *testing.T) {
+		_, _, values := readPage(3)
+		if !bytes.Equal(u.GetBytes(), values) {
+			t.Errorf("expected %v got %v", u.GetBytes(), values)
+		}
+	})
+	t.Run(`timestamp`, func(t *testing.T) {
+		_, _, values := readPage(4)
+		if expected := le64(ts.UnixNano() / 1000); !bytes.Equal(expected, values) {
+			t.Errorf("expected %v got %v", expected, values)
+		}
+	})
+	t.Run(`bool`, func(t *testing.T) {
+		def, _, values := readPage(5)
+		if expected := []int32{1, 1, 0}; !reflect.DeepEqual(expected, def) {
+			t.Errorf("expected %v got %v", expected, def)
+		}
+		// true, false bit-packed.
+		if expected := []byte{0x01}; !bytes.Equal(expected, values) {
+			t.Errorf("expected %v got %v", expected, values)
+		}
+	})
+	t.Run(`json`, func(t *testing.T) {
+		_, _, values := readPage(6)
+		if expected, actual := []string{`{"a": 1}`}, byteArrays(values); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %v got %v", expected, actual)
+		}
+	})
+	t.Run(`array`, func(t *testing.T) {
+		def, rep, values := readPage(7)
+		// [1, NULL, 3], NULL, [].
+		if expected := []int32{3, 2, 3, 0, 1}; !reflect.DeepEqual(expected, def) {
+			t.Errorf("expected %v got %v", expected, def)
+		}
+		if expected := []int32{0, 1, 1, 0, 0}; !reflect.DeepEqual(expected, rep) {
+			t.Errorf("expected %v got %v", expected, rep)
+		}
+		if expected := le64(1, 3); !bytes.Equal(expected, values) {
+			t.Errorf("expected %v got %v", expected, values)
+		}
+	})
+}
+
+func TestBigIntToTwosComplement(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, test := range []struct {
+		i        int64
+		expected []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x00, 0x80}},
+		{-1, []byte{0xff}},
+		{-128, []byte{0x80}},
+		{-129, []byte{0xff, 0x7f}},
+		{-2000, []byte{0xf8, 0x30}},
+	} {
+		if actual := bigIntToTwosComplement(big.NewInt(test.i)); !bytes.Equal(test.expected, actual) {
+			t.Errorf("%d: expected %x got %x", test.i, test.expected, actual)
+		}
+	}
+}
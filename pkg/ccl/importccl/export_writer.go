@@ -0,0 +1,257 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/pkg/errors"
+)
+
+// exportFileEncoder encodes a chunk of rows, with the given column names and
+// types, into the contents of a single exported file.
+type exportFileEncoder func(
+	names []string, types []sqlbase.ColumnType, rows []tree.Datums,
+) ([]byte, error)
+
+func exportFileEncoderForFormat(format roachpb.IOFileFormat) (exportFileEncoder, string, error) {
+	switch format.Format {
+	case roachpb.IOFileFormat_Parquet:
+		return encodeParquet, exportFilePatternPart + ".parquet", nil
+	case roachpb.IOFileFormat_Avro:
+		return encodeAvroOCF, exportFilePatternPart + ".avro", nil
+	default:
+		return nil, "", errors.Errorf("unsupported export format: %s", format.Format)
+	}
+}
+
+func newExportWriterProcessor(
+	flowCtx *distsqlrun.FlowCtx,
+	processorID int32,
+	spec distsqlpb.ExportWriterSpec,
+	input distsqlrun.RowSource,
+	output distsqlrun.RowReceiver,
+) (distsqlrun.Processor, error) {
+	if len(spec.ColumnNames) != len(input.OutputTypes()) {
+		return nil, errors.Errorf("expected %d column names, got %d",
+			len(input.OutputTypes()), len(spec.ColumnNames))
+	}
+	encodeFn, defaultPattern, err := exportFileEncoderForFormat(spec.Format)
+	if err != nil {
+		return nil, err
+	}
+	w := &exportWriter{
+		flowCtx:        flowCtx,
+		processorID:    processorID,
+		spec:           spec,
+		input:          input,
+		output:         output,
+		encodeFn:       encodeFn,
+		defaultPattern: defaultPattern,
+	}
+	if err := w.out.Init(&distsqlpb.PostProcessSpec{}, sql.ExportPlanResultTypes, flowCtx.NewEvalCtx(), output); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// exportWriter is the processor for EXPORT to the columnar file formats. Unlike
+// csvWriter, which streams each row out as it is read, these formats are
+// written a whole file at a time, so each chunk of rows is buffered in memory,
+// and accounted for, before it is encoded.
+type exportWriter struct {
+	flowCtx     *distsqlrun.FlowCtx
+	processorID int32
+	spec        distsqlpb.ExportWriterSpec
+	input       distsqlrun.RowSource
+	out         distsqlrun.ProcOutputHelper
+	output      distsqlrun.RowReceiver
+
+	encodeFn       exportFileEncoder
+	defaultPattern string
+}
+
+var _ distsqlrun.Processor = &exportWriter{}
+
+func (sp *exportWriter) OutputTypes() []sqlbase.ColumnType {
+	return sql.ExportPlanResultTypes
+}
+
+func (sp *exportWriter) Run(ctx context.Context, wg *sync.WaitGroup) {
+	ctx, span := tracing.ChildSpan(ctx, "exportWriter")
+	defer tracing.FinishSpan(span)
+
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	err := func() error {
+		pattern := sp.defaultPattern
+		if sp.spec.NamePattern != "" {
+			pattern = sp.spec.NamePattern
+		}
+
+		types := sp.input.OutputTypes()
+		sp.input.Start(ctx)
+		input := distsqlrun.MakeNoMetadataRowSource(sp.input, sp.output)
+
+		alloc := &sqlbase.DatumAlloc{}
+
+		memMonitor := distsqlrun.NewMonitor(ctx, sp.flowCtx.EvalCtx.Mon, "exportwriter-mem")
+		defer memMonitor.Stop(ctx)
+		memAcc := memMonitor.MakeBoundAccount()
+		defer memAcc.Close(ctx)
+
+		var rows []tree.Datums
+		chunk := 0
+		done := false
+		for {
+			rows = rows[:0]
+			memAcc.Clear(ctx)
+			for {
+				if sp.spec.ChunkRows > 0 && int64(len(rows)) >= sp.spec.ChunkRows {
+					break
+				}
+				row, err := input.NextRow()
+				if err != nil {
+					return err
+				}
+				if row == nil {
+					done = true
+					break
+				}
+				// The EncDatumRow is reused by the input, so the decoded datums
+				// are copied out of it.
+				datums := make(tree.Datums, len(row))
+				size := sqlbase.SizeOfDatums
+				for i, ed := range row {
+					if err := ed.EnsureDecoded(&types[i], alloc); err != nil {
+						return err
+					}
+					datums[i] = ed.Datum
+					size += sqlbase.SizeOfDatum + int64(ed.Datum.Size())
+				}
+				if err := memAcc.Grow(ctx, size); err != nil {
+					return err
+				}
+				rows = append(rows, datums)
+			}
+			if len(rows) < 1 {
+				break
+			}
+
+			contents, err := sp.encodeFn(sp.spec.ColumnNames, types, rows)
+			if err != nil {
+				return err
+			}
+			if err := memAcc.Grow(ctx, int64(cap(contents))); err != nil {
+				return err
+			}
+
+			conf, err := storageccl.ExportStorageConfFromURI(sp.spec.Destination)
+			if err != nil {
+				return err
+			}
+			es, err := storageccl.MakeExportStorage(ctx, conf, sp.flowCtx.Settings)
+			if err != nil {
+				return err
+			}
+
+			part := fmt.Sprintf("n%d.%d", sp.flowCtx.EvalCtx.NodeID, chunk)
+			chunk++
+			filename := strings.Replace(pattern, exportFilePatternPart, part, -1)
+			err = es.WriteFile(ctx, filename, bytes.NewReader(contents))
+			es.Close()
+			if err != nil {
+				return err
+			}
+
+			cs, err := sp.out.EmitRow(ctx, exportResultRow(filename, len(rows), len(contents)))
+			if err != nil {
+				return err
+			}
+			if cs != distsqlrun.NeedMoreRows {
+				return errors.New("unexpected closure of consumer")
+			}
+			if done {
+				break
+			}
+		}
+
+		return nil
+	}()
+
+	distsqlrun.DrainAndClose(
+		ctx, sp.output, err, func(context.Context) {} /* pushTrailingMeta */, sp.input)
+}
+
+// exportResultRow returns the row describing a single written file, matching
+// sql.ExportPlanResultTypes.
+func exportResultRow(filename string, rows int, size int) sqlbase.EncDatumRow {
+	return sqlbase.EncDatumRow{
+		sqlbase.DatumToEncDatum(
+			sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_STRING},
+			tree.NewDString(filename),
+		),
+		sqlbase.DatumToEncDatum(
+			sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT},
+			tree.NewDInt(tree.DInt(rows)),
+		),
+		sqlbase.DatumToEncDatum(
+			sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT},
+			tree.NewDInt(tree.DInt(size)),
+		),
+	}
+}
+
+// exportDecimalScale returns the scale and precision of a DECIMAL column of
+// the given type, which both of the columnar formats require to be fixed. As
+// with changefeeds, these are those of the column. A DECIMAL without a
+// precision holds values of any scale, so ok is false for it; such columns
+// are exported as strings instead, in the same format used by EXPORT INTO
+// CSV, which represents every value exactly.
+func exportDecimalScale(typ sqlbase.ColumnType) (scale int32, precision int32, ok bool) {
+	if typ.Precision == 0 {
+		return 0, 0, false
+	}
+	return typ.Width, typ.Precision, true
+}
+
+// exportUnscaledDecimal returns d * 10^scale, which must be an integer of no
+// more than precision digits.
+func exportUnscaledDecimal(d *apd.Decimal, scale, precision int32) (*big.Int, error) {
+	if d.Form != apd.Finite {
+		return nil, errors.Errorf("cannot export non-finite decimal %s", d)
+	}
+	c := tree.DecimalCtx.WithPrecision(uint32(precision))
+	c.Traps = apd.InvalidOperation
+	var q apd.Decimal
+	if cond, err := c.Quantize(&q, d, -scale); err != nil || cond.Inexact() {
+		return nil, errors.Errorf("decimal %s does not fit DECIMAL(%d,%d)", d, precision, scale)
+	}
+	unscaled := new(big.Int).Set(&q.Coeff)
+	if q.Negative {
+		unscaled.Neg(unscaled)
+	}
+	return unscaled, nil
+}
@@ -71,7 +71,15 @@ func exportPlanHook(
 		return nil, nil, nil, err
 	}
 
-	if exportStmt.FileFormat != "CSV" {
+	var format roachpb.IOFileFormat
+	switch exportStmt.FileFormat {
+	case "CSV":
+		format.Format = roachpb.IOFileFormat_CSV
+	case "PARQUET":
+		format.Format = roachpb.IOFileFormat_Parquet
+	case "AVRO":
+		format.Format = roachpb.IOFileFormat_Avro
+	default:
 		return nil, nil, nil, errors.Errorf("unsupported export format: %q", exportStmt.FileFormat)
 	}
 
@@ -85,6 +93,21 @@ func exportPlanHook(
 		return nil, nil, nil, err
 	}
 
+	// The columnar formats name their columns, so the names need to be unique.
+	var colNames []string
+	if format.Format != roachpb.IOFileFormat_CSV {
+		seen := make(map[string]struct{})
+		for _, col := range sql.PlanColumns(sel) {
+			if _, ok := seen[col.Name]; ok {
+				return nil, nil, nil, pgerror.NewErrorf(pgerror.CodeDuplicateColumnError,
+					"duplicate column name %q in %s export; use AS to rename it",
+					col.Name, exportStmt.FileFormat)
+			}
+			seen[col.Name] = struct{}{}
+			colNames = append(colNames, col.Name)
+		}
+	}
+
 	fn := func(ctx context.Context, plans []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		ctx, span := tracing.ChildSpan(ctx, exportStmt.StatementTag())
 		defer tracing.FinishSpan(span)
@@ -113,6 +136,15 @@ func exportPlanHook(
 			return err
 		}
 
+		if format.Format != roachpb.IOFileFormat_CSV {
+			for _, opt := range []string{exportOptionDelimiter, exportOptionNullAs} {
+				if _, ok := opts[opt]; ok {
+					return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+						"%s option is only supported for CSV", opt)
+				}
+			}
+		}
+
 		csvOpts := roachpb.CSVOptions{}
 
 		if override, ok := opts[exportOptionDelimiter]; ok {
@@ -133,16 +165,26 @@ func exportPlanHook(
 				return pgerror.NewError(pgerror.CodeInvalidParameterValueError, err.Error())
 			}
 			if chunk < 1 {
-				return pgerror.NewError(pgerror.CodeInvalidParameterValueError, "invalid csv chunk size")
+				return pgerror.NewError(pgerror.CodeInvalidParameterValueError, "invalid chunk size")
 			}
 		}
 
-		out := distsqlpb.ProcessorCoreUnion{CSVWriter: &distsqlpb.CSVWriterSpec{
-			Destination: file,
-			NamePattern: exportFilePatternDefault,
-			Options:     csvOpts,
-			ChunkRows:   int64(chunk),
-		}}
+		var out distsqlpb.ProcessorCoreUnion
+		if format.Format == roachpb.IOFileFormat_CSV {
+			out.CSVWriter = &distsqlpb.CSVWriterSpec{
+				Destination: file,
+				NamePattern: exportFilePatternDefault,
+				Options:     csvOpts,
+				ChunkRows:   int64(chunk),
+			}
+		} else {
+			out.ExportWriter = &distsqlpb.ExportWriterSpec{
+				Destination: file,
+				Format:      format,
+				ColumnNames: colNames,
+				ChunkRows:   int64(chunk),
+			}
+		}
 
 		rows := sqlbase.NewRowContainer(
 			p.ExtendedEvalContext().Mon.MakeBoundAccount(), sqlbase.ColTypeInfoFromColTypes(sql.ExportPlanResultTypes), 0,
@@ -291,22 +333,7 @@ func (sp *csvWriter) Run(ctx context.Context, wg *sync.WaitGroup) {
 			if err := es.WriteFile(ctx, filename, bytes.NewReader(buf.Bytes())); err != nil {
 				return err
 			}
-			res := sqlbase.EncDatumRow{
-				sqlbase.DatumToEncDatum(
-					sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_STRING},
-					tree.NewDString(filename),
-				),
-				sqlbase.DatumToEncDatum(
-					sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT},
-					tree.NewDInt(tree.DInt(rows)),
-				),
-				sqlbase.DatumToEncDatum(
-					sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT},
-					tree.NewDInt(tree.DInt(size)),
-				),
-			}
-
-			cs, err := sp.out.EmitRow(ctx, res)
+			cs, err := sp.out.EmitRow(ctx, exportResultRow(filename, int(rows), size))
 			if err != nil {
 				return err
 			}
@@ -331,4 +358,5 @@ func (sp *csvWriter) Run(ctx context.Context, wg *sync.WaitGroup) {
 func init() {
 	sql.AddPlanHook(exportPlanHook)
 	distsqlrun.NewCSVWriterProcessor = newCSVWriterProcessor
+	distsqlrun.NewExportWriterProcessor = newExportWriterProcessor
 }
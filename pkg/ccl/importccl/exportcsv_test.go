@@ -219,3 +219,71 @@ func TestExportShow(t *testing.T) {
 		t.Fatalf("expected %q, got %q", expected, got)
 	}
 }
+
+func TestExportParquetAndAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (
+		i INT PRIMARY KEY, s STRING, d DECIMAL(5, 1), ts TIMESTAMP, u UUID, j JSONB, a INT[]
+	)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 'a', 1.5, '2018-12-01 01:02:03', gen_random_uuid(), '{"a": 1}', ARRAY[1, NULL]),
+		(2, NULL, NULL, NULL, NULL, NULL, NULL),
+		(3, 'c', -200, now(), gen_random_uuid(), '[]', ARRAY[]:::INT[])`)
+
+	for _, test := range []struct {
+		format string
+		ext    string
+		magic  string
+	}{
+		{format: `PARQUET`, ext: `parquet`, magic: `PAR1`},
+		{format: `AVRO`, ext: `avro`, magic: "Obj\x01"},
+	} {
+		t.Run(test.format, func(t *testing.T) {
+			rows := sqlDB.QueryStr(t, fmt.Sprintf(
+				`EXPORT INTO %s 'nodelocal:///%s' WITH chunk_rows = 2 FROM SELECT * FROM foo ORDER BY i LIMIT 3`,
+				test.format, test.ext,
+			))
+			if len(rows) != 2 {
+				t.Fatalf("expected 2 files, got %v", rows)
+			}
+			for i, expectedRows := range []string{`2`, `1`} {
+				expectedName := fmt.Sprintf(`n1.%d.%s`, i, test.ext)
+				if rows[i][0] != expectedName || rows[i][1] != expectedRows {
+					t.Errorf("expected %s with %s rows, got %v", expectedName, expectedRows, rows[i])
+				}
+				content, err := ioutil.ReadFile(filepath.Join(dir, test.ext, expectedName))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(string(content), test.magic) {
+					t.Errorf("%s: expected magic %q, got %q", expectedName, test.magic, content[:4])
+				}
+				if rows[i][2] != fmt.Sprint(len(content)) {
+					t.Errorf("%s: expected %s bytes, got %d", expectedName, rows[i][2], len(content))
+				}
+			}
+		})
+	}
+
+	sqlDB.ExpectErr(t, `delimiter option is only supported for CSV`,
+		`EXPORT INTO PARQUET 'nodelocal:///x' WITH delimiter = '|' FROM SELECT * FROM foo`)
+	sqlDB.ExpectErr(t, `duplicate column name "i" in AVRO export`,
+		`EXPORT INTO AVRO 'nodelocal:///x' FROM SELECT i, i FROM foo`)
+	// A DECIMAL without a precision is exported as a string.
+	for _, format := range []string{`PARQUET`, `AVRO`} {
+		rows := sqlDB.QueryStr(t, fmt.Sprintf(
+			`EXPORT INTO %s 'nodelocal:///decimal-%[1]s' FROM SELECT d::DECIMAL AS d FROM foo`, format))
+		if len(rows) != 1 || rows[0][1] != `3` {
+			t.Errorf("%s: expected one file with 3 rows, got %v", format, rows)
+		}
+	}
+	sqlDB.ExpectErr(t, `unsupported export format: "ORC"`,
+		`EXPORT INTO ORC 'nodelocal:///x' FROM SELECT * FROM foo`)
+}
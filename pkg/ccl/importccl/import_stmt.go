@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/gossipccl"
@@ -484,6 +485,16 @@ func importPlanHook(
 		table := importStmt.Table
 		transform := opts[importOptionTransform]
 
+		if importStmt.Into {
+			if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionImportInto) {
+				return errors.Errorf("IMPORT INTO requires all nodes to be upgraded to %s",
+					cluster.VersionByKey(cluster.VersionImportInto))
+			}
+			if transform != "" {
+				return errors.Errorf("%s is not supported by IMPORT INTO", importOptionTransform)
+			}
+		}
+
 		var parentID sqlbase.ID
 		if transform != "" {
 			// If we're not ingesting the data, we don't care what DB we pick.
@@ -640,6 +651,27 @@ func importPlanHook(
 				maxRowSize = int32(sz)
 			}
 			format.PgDump.MaxRowSize = maxRowSize
+		case "AVRO":
+			telemetry.Count("import.format.avro")
+			format.Format = roachpb.IOFileFormat_Avro
+		case "PARQUET":
+			telemetry.Count("import.format.parquet")
+			format.Format = roachpb.IOFileFormat_Parquet
+		case "NDJSON":
+			telemetry.Count("import.format.ndjson")
+			format.Format = roachpb.IOFileFormat_NDJSON
+			maxRowSize := int32(defaultScanBuffer)
+			if override, ok := opts[pgMaxRowSize]; ok {
+				sz, err := humanizeutil.ParseBytes(override)
+				if err != nil {
+					return err
+				}
+				if sz < 1 || sz > math.MaxInt32 {
+					return errors.Errorf("%s out of range: %d", pgMaxRowSize, sz)
+				}
+				maxRowSize = int32(sz)
+			}
+			format.Ndjson.MaxRowSize = maxRowSize
 		default:
 			return pgerror.Unimplemented("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -650,6 +682,16 @@ func importPlanHook(
 					csvSkip, cluster.VersionByKey(cluster.VersionImportFormats))
 			}
 		}
+		if importStmt.Into && isMultiTableFormat(format.Format) {
+			return errors.Errorf("IMPORT INTO does not support %s", importStmt.FileFormat)
+		}
+		switch format.Format {
+		case roachpb.IOFileFormat_Avro, roachpb.IOFileFormat_Parquet, roachpb.IOFileFormat_NDJSON:
+			if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionImportAvroParquetJSON) {
+				return errors.Errorf("Using %s requires all nodes to be upgraded to %s",
+					importStmt.FileFormat, cluster.VersionByKey(cluster.VersionImportAvroParquetJSON))
+			}
+		}
 
 		// sstSize, if 0, will be set to an appropriate default by the specific
 		// implementation (local or distributed) since each has different optimal
@@ -693,6 +735,7 @@ func importPlanHook(
 		var tableDescs []*sqlbase.TableDescriptor
 		var jobDesc string
 		var names []string
+		var intoCols []string
 		seqVals := make(map[sqlbase.ID]int64)
 		if importStmt.Bundle {
 			store, err := storageccl.ExportStorageFromURI(ctx, files[0], p.ExecCfg().Settings)
@@ -733,6 +776,47 @@ func importPlanHook(
 				names = []string{table.TableName.String()}
 			}
 
+			descStr, err := importJobDescription(importStmt, nil, files, opts)
+			if err != nil {
+				return err
+			}
+			jobDesc = descStr
+		} else if importStmt.Into {
+			found, err := sql.ResolveMutableExistingObject(ctx, p, table, true /* required */, sql.ResolveRequireTableDesc)
+			if err != nil {
+				return err
+			}
+			// The table is taken offline, and its data reverted on failure, by
+			// the job. Neither can safely race a schema change.
+			if len(found.Mutations) > 0 {
+				return errors.Errorf(
+					"cannot IMPORT INTO %s while a schema change is in progress", table)
+			}
+			if found.IsInterleaved() {
+				return errors.Errorf("IMPORT INTO does not support interleaved table %s", table)
+			}
+			for _, idx := range found.AllNonDropIndexes() {
+				if idx.ForeignKey.IsSet() {
+					return errors.Errorf("IMPORT INTO does not support table %s with foreign keys", table)
+				}
+			}
+			for _, col := range found.Columns {
+				if col.IsComputed() {
+					return errors.Errorf("IMPORT INTO does not support table %s with computed columns", table)
+				}
+			}
+			seen := make(map[tree.Name]bool, len(importStmt.IntoCols))
+			for _, name := range importStmt.IntoCols {
+				if seen[name] {
+					return errors.Errorf("multiple values specified for column %q", name)
+				}
+				seen[name] = true
+				if _, err := found.FindActiveColumnByName(string(name)); err != nil {
+					return err
+				}
+				intoCols = append(intoCols, string(name))
+			}
+			tableDescs = []*sqlbase.TableDescriptor{found.TableDesc()}
 			descStr, err := importJobDescription(importStmt, nil, files, opts)
 			if err != nil {
 				return err
@@ -785,12 +869,16 @@ func importPlanHook(
 				return err
 			}
 			// Delay writing the BACKUP-CHECKPOINT file until as late as possible.
-			err = backupccl.VerifyUsableExportTarget(ctx, transformStorage, transform)
+			err = backupccl.VerifyUsableExportTarget(ctx, transformStorage, transform, nil /* encryption */)
 			transformStorage.Close()
 			if err != nil {
 				return err
 			}
 			telemetry.Count("import.transform")
+		} else if importStmt.Into {
+			telemetry.Count("import.into")
+			// The data is written at a walltime chosen once the table is offline.
+			walltime = 0
 		} else {
 			for _, tableDesc := range tableDescs {
 				if err := backupccl.CheckTableExists(ctx, p.Txn(), parentID, tableDesc.Name); err != nil {
@@ -822,7 +910,9 @@ func importPlanHook(
 
 		tableDetails := make([]jobspb.ImportDetails_Table, 0, len(tableDescs))
 		for _, tbl := range tableDescs {
-			tableDetails = append(tableDetails, jobspb.ImportDetails_Table{Desc: tbl, SeqVal: seqVals[tbl.ID]})
+			tableDetails = append(tableDetails, jobspb.ImportDetails_Table{
+				Desc: tbl, SeqVal: seqVals[tbl.ID], Existing: importStmt.Into, TargetCols: intoCols,
+			})
 		}
 		for _, name := range names {
 			tableDetails = append(tableDetails, jobspb.ImportDetails_Table{Name: name})
@@ -992,6 +1082,109 @@ func doDistributedCSVTransform(
 	return backupDesc.EntryCounts, finalizeCSVBackup(ctx, &backupDesc, parentID, tables, es, p.ExecCfg())
 }
 
+// prepareExistingTablesForIngestion takes any existing tables being imported
+// into offline and waits until no leases remain on their online versions,
+// after which nothing else can write to them. Only then is the walltime at
+// which the imported data is written chosen, so that all of the table's prior
+// data is older than it and can be reverted to if the IMPORT fails.
+func prepareExistingTablesForIngestion(
+	ctx context.Context, p sql.PlanHookState, job *jobs.Job, details jobspb.ImportDetails,
+) (jobspb.ImportDetails, error) {
+	for _, tbl := range details.Tables {
+		if !tbl.Existing {
+			continue
+		}
+		if _, err := p.LeaseMgr().Publish(ctx, tbl.Desc.ID, func(desc *sqlbase.MutableTableDescriptor) error {
+			// The table may have already been taken offline if this job is
+			// being resumed.
+			if !desc.Offline() {
+				if desc.Version != tbl.Desc.Version || len(desc.Mutations) > 0 || desc.Dropped() {
+					return errors.Errorf("table %s was modified while preparing to IMPORT INTO it", desc.Name)
+				}
+				desc.State = sqlbase.TableDescriptor_OFFLINE
+				desc.OfflineReason = "importing"
+			}
+			return nil
+		}, nil /* logEvent */); err != nil {
+			return details, err
+		}
+		if _, err := p.LeaseMgr().WaitForOneVersion(ctx, tbl.Desc.ID, base.DefaultRetryOptions()); err != nil {
+			return details, err
+		}
+	}
+
+	details.Walltime = p.ExecCfg().Clock.Now().WallTime
+	details.PrepareComplete = true
+	if err := job.SetDetails(ctx, details); err != nil {
+		return details, err
+	}
+	return details, nil
+}
+
+// bringExistingTablesOnline makes any existing tables that were taken offline
+// to be imported into public again.
+func bringExistingTablesOnline(
+	ctx context.Context, txn *client.Txn, details jobspb.ImportDetails,
+) error {
+	if err := txn.SetSystemConfigTrigger(); err != nil {
+		return err
+	}
+	b := txn.NewBatch()
+	for _, tbl := range details.Tables {
+		if !tbl.Existing {
+			continue
+		}
+		desc, err := sqlbase.GetMutableTableDescFromID(ctx, txn, tbl.Desc.ID)
+		if err != nil {
+			return err
+		}
+		if !desc.Offline() {
+			continue
+		}
+		// No leases can be held on the offline version of the table, so a new
+		// version can be written without waiting for them to be released.
+		desc.State = sqlbase.TableDescriptor_PUBLIC
+		desc.OfflineReason = ""
+		desc.Version++
+		desc.ModificationTime = txn.CommitTimestamp()
+		b.Put(sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc))
+	}
+	return txn.Run(ctx, b)
+}
+
+// revertExistingTables removes any data imported into existing tables by
+// reverting them to the time before the IMPORT wrote to them.
+func revertExistingTables(
+	ctx context.Context, db *client.DB, details jobspb.ImportDetails,
+) error {
+	if !details.PrepareComplete {
+		// Nothing was written to the tables before their walltime was chosen.
+		return nil
+	}
+	target := hlc.Timestamp{WallTime: details.Walltime}.Prev()
+	for _, tbl := range details.Tables {
+		if !tbl.Existing {
+			continue
+		}
+		span := tbl.Desc.TableSpan()
+		// RevertRange cannot be run in a transaction, so create a
+		// non-transactional batch to send the request.
+		var b client.Batch
+		b.AddRawRequest(&roachpb.RevertRangeRequest{
+			RequestHeader: roachpb.RequestHeader{
+				Key:    span.Key,
+				EndKey: span.EndKey,
+			},
+			TargetTime: target,
+		})
+		log.VEventf(ctx, 2, "RevertRange %s to %s", span, target)
+		if err := db.Run(ctx, &b); err != nil {
+			return errors.Wrapf(err, "reverting table %s", tbl.Desc.Name)
+		}
+	}
+	return nil
+}
+
 type importResumer struct {
 	settings *cluster.Settings
 	res      roachpb.BulkOpSummary
@@ -1005,6 +1198,13 @@ func (r *importResumer) Resume(
 
 	// TODO(dt): consider looking at the legacy fields used in 2.0.
 
+	if !details.PrepareComplete {
+		var err error
+		if details, err = prepareExistingTablesForIngestion(ctx, p, job, details); err != nil {
+			return err
+		}
+	}
+
 	walltime := details.Walltime
 	transform := details.BackupPath
 	files := details.URIs
@@ -1063,13 +1263,21 @@ func (r *importResumer) Resume(
 // OnFailOrCancel removes KV data that has been committed from a import that
 // has failed or been canceled. It does this by adding the table descriptors
 // in DROP state, which causes the schema change stuff to delete the keys
-// in the background.
+// in the background. Existing tables are instead reverted to their state
+// before the IMPORT and brought back online.
 func (r *importResumer) OnFailOrCancel(ctx context.Context, txn *client.Txn, job *jobs.Job) error {
 	details := job.Details().(jobspb.ImportDetails)
 	if details.BackupPath != "" {
 		return nil
 	}
 
+	if len(details.Tables) > 0 && details.Tables[0].Existing {
+		if err := revertExistingTables(ctx, txn.DB(), details); err != nil {
+			return err
+		}
+		return bringExistingTablesOnline(ctx, txn, details)
+	}
+
 	// Needed to trigger the schema change manager.
 	if err := txn.SetSystemConfigTrigger(); err != nil {
 		return err
@@ -1098,6 +1306,10 @@ func (r *importResumer) OnSuccess(ctx context.Context, txn *client.Txn, job *job
 		return nil
 	}
 
+	if len(details.Tables) > 0 && details.Tables[0].Existing {
+		return bringExistingTablesOnline(ctx, txn, details)
+	}
+
 	toWrite := make([]*sqlbase.TableDescriptor, len(details.Tables))
 	var seqs []roachpb.KeyValue
 	for i := range details.Tables {
@@ -615,6 +615,51 @@ COPY t (a, b, c) FROM stdin;
 			}(),
 		},
 
+		// NDJSON
+		{
+			name:   "normal",
+			create: `i int8, s string, j jsonb, a int8[], d decimal`,
+			typ:    "NDJSON",
+			data: `{"i": 1, "s": "a", "j": {"x": [1]}, "a": [1, null], "d": 1.50}
+
+{"i": 2, "extra": true, "s": null}`,
+			query: map[string][][]string{
+				`SELECT * from t`: {
+					{"1", "a", `{"x": [1]}`, "{1,NULL}", "1.50"},
+					{"2", "NULL", "NULL", "NULL", "NULL"},
+				},
+			},
+		},
+		{
+			name:   "not an object",
+			create: `i int8`,
+			typ:    "NDJSON",
+			data:   `[1]`,
+			err:    `row 1: json: cannot unmarshal array`,
+		},
+		{
+			name:   "trailing data",
+			create: `i int8`,
+			typ:    "NDJSON",
+			data:   `{"i": 1} {"i": 2}`,
+			err:    `row 1: unexpected data after JSON object`,
+		},
+		{
+			name:   "bad value",
+			create: `i int8`,
+			typ:    "NDJSON",
+			data:   "{\"i\": 1}\n{\"i\": \"x\"}",
+			err:    `row 2: parse "i" as INT8`,
+		},
+		{
+			name:   "line too long",
+			create: `s string`,
+			typ:    "NDJSON",
+			with:   `WITH max_row_size = '5B'`,
+			data:   `{"s": "abcdef"}`,
+			err:    `line too long`,
+		},
+
 		// Error
 		{
 			name:   "unsupported import format",
@@ -2410,3 +2455,117 @@ func TestImportCockroachDump(t *testing.T) {
 )`},
 	})
 }
+
+func TestImportParquetAndAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const cols = `i INT8 PRIMARY KEY, s STRING, d DECIMAL, ts TIMESTAMP, u UUID, j JSONB, a INT8[]`
+	sqlDB.Exec(t, fmt.Sprintf(`CREATE TABLE foo (%s)`, cols))
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 'a', 1.5, '2018-12-01 01:02:03.000004', gen_random_uuid(), '{"a": 1}', ARRAY[1, NULL]),
+		(2, NULL, NULL, NULL, NULL, NULL, NULL),
+		(3, 'c', -200, now(), gen_random_uuid(), '[]', ARRAY[]:::INT8[])`)
+	expected := sqlDB.QueryStr(t, `SELECT * FROM foo ORDER BY i`)
+
+	for _, format := range []string{`PARQUET`, `AVRO`} {
+		t.Run(format, func(t *testing.T) {
+			ext := strings.ToLower(format)
+			sqlDB.Exec(t, fmt.Sprintf(`EXPORT INTO %s 'nodelocal:///%s' FROM TABLE foo`, format, ext))
+			// Columns are matched by name, so the order of the table's columns
+			// doesn't need to match the file's, and columns missing from the
+			// file are NULL.
+			sqlDB.Exec(t, fmt.Sprintf(
+				`IMPORT TABLE %[1]s (a INT8[], j JSONB, u UUID, ts TIMESTAMP, d DECIMAL, s STRING, i INT8 PRIMARY KEY, x INT8)
+				%[2]s DATA ('nodelocal:///%[1]s/n1.0.%[1]s')`, ext, format,
+			))
+			sqlDB.CheckQueryResults(t,
+				fmt.Sprintf(`SELECT i, s, d, ts, u, j, a FROM %s ORDER BY i`, ext), expected)
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT count(*) FROM %s WHERE x IS NULL`, ext),
+				[][]string{{`3`}})
+		})
+	}
+
+	sqlDB.ExpectErr(t, `not a parquet file`,
+		`IMPORT TABLE bad (i INT8) PARQUET DATA ('nodelocal:///avro/n1.0.avro')`)
+	sqlDB.ExpectErr(t, `reading avro object container file header`,
+		`IMPORT TABLE bad (i INT8) AVRO DATA ('nodelocal:///parquet/n1.0.parquet')`)
+}
+
+func TestImportInto(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	writeCSV := func(name, data string) string {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("nodelocal:///%s", name)
+	}
+
+	sqlDB.Exec(t, `CREATE DATABASE d; SET DATABASE = d`)
+	sqlDB.Exec(t, `CREATE TABLE t (a INT8 PRIMARY KEY, b STRING, c INT8 DEFAULT 7, INDEX (b))`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, 'one', 1), (2, 'two', 2)`)
+
+	t.Run("all-columns", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT INTO t CSV DATA ($1)`, writeCSV("all.csv", "3,three,3\n4,four,4\n"))
+		sqlDB.CheckQueryResults(t, `SELECT * FROM t ORDER BY a`, [][]string{
+			{"1", "one", "1"}, {"2", "two", "2"}, {"3", "three", "3"}, {"4", "four", "4"},
+		})
+		// The secondary index includes both the existing and imported rows.
+		sqlDB.CheckQueryResults(t, `SELECT a FROM t@t_b_idx WHERE b IN ('one', 'four') ORDER BY a`,
+			[][]string{{"1"}, {"4"}})
+	})
+
+	t.Run("target-columns", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT INTO t (b, a) CSV DATA ($1)`, writeCSV("target.csv", "five,5\n"))
+		sqlDB.CheckQueryResults(t, `SELECT * FROM t WHERE a = 5`, [][]string{{"5", "five", "7"}})
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `ingested key collides with an existing one`,
+			`IMPORT INTO t CSV DATA ($1)`, writeCSV("collide.csv", "6,six,6\n1,uno,1\n"))
+		// The table is back online and the partially imported data is gone.
+		sqlDB.CheckQueryResults(t, `SELECT a, b FROM t ORDER BY a`, [][]string{
+			{"1", "one"}, {"2", "two"}, {"3", "three"}, {"4", "four"}, {"5", "five"},
+		})
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM t@t_b_idx WHERE b IN ('six', 'uno')`,
+			[][]string{{"0"}})
+	})
+
+	t.Run("hidden-rowid", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE u (x INT8)`)
+		sqlDB.Exec(t, `INSERT INTO u VALUES (1)`)
+		// Importing the same file twice must not generate colliding row IDs.
+		file := writeCSV("rowid.csv", "2\n3\n")
+		sqlDB.Exec(t, `IMPORT INTO u CSV DATA ($1)`, file)
+		sqlDB.Exec(t, `IMPORT INTO u CSV DATA ($1)`, file)
+		sqlDB.CheckQueryResults(t, `SELECT x, count(*) FROM u GROUP BY x ORDER BY x`,
+			[][]string{{"1", "1"}, {"2", "2"}, {"3", "2"}})
+		// The imported rows get negative row IDs, which unique_rowid never
+		// returns, so rows inserted afterwards cannot collide with them.
+		sqlDB.CheckQueryResults(t, `SELECT x, rowid < 0 FROM u ORDER BY x, rowid`, [][]string{
+			{"1", "false"}, {"2", "true"}, {"2", "true"}, {"3", "true"}, {"3", "true"},
+		})
+		sqlDB.Exec(t, `INSERT INTO u VALUES (4)`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		file := writeCSV("errors.csv", "9\n")
+		sqlDB.ExpectErr(t, `column "nope" does not exist`, `IMPORT INTO t (nope) CSV DATA ($1)`, file)
+		sqlDB.ExpectErr(t, `multiple values specified for column "a"`,
+			`IMPORT INTO t (a, a) CSV DATA ($1)`, file)
+		sqlDB.ExpectErr(t, `relation "missing" does not exist`, `IMPORT INTO missing CSV DATA ($1)`, file)
+		sqlDB.ExpectErr(t, `IMPORT INTO does not support PGDUMP`, `IMPORT INTO t PGDUMP DATA ($1)`, file)
+	})
+}
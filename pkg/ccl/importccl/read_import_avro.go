@@ -0,0 +1,335 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	gojson "encoding/json"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/linkedin/goavro"
+	"github.com/pkg/errors"
+)
+
+// avroOCFReader reads avro object container files whose records are imported
+// as rows. The value of each record field is imported into the column whose
+// name, escaped as in exportAvroName, matches the field's name. Columns
+// without a field are NULL and fields without a column are ignored.
+type avroOCFReader struct {
+	conv rowConverter
+}
+
+var _ inputConverter = &avroOCFReader{}
+
+func newAvroOCFReader(
+	kvCh chan kvBatch,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols []string,
+	maxRowID int64,
+	evalCtx *tree.EvalContext,
+) (*avroOCFReader, error) {
+	conv, err := newRowConverter(tableDesc, targetCols, maxRowID, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &avroOCFReader{conv: *conv}, nil
+}
+
+func (d *avroOCFReader) start(ctx ctxgroup.Group) {
+}
+
+func (d *avroOCFReader) inputFinished(ctx context.Context) {
+	close(d.conv.kvCh)
+}
+
+func (d *avroOCFReader) readFile(
+	ctx context.Context, input io.Reader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	r, err := goavro.NewOCFReader(input)
+	if err != nil {
+		return errors.Wrap(err, "reading avro object container file header")
+	}
+	schema, err := parseImportAvroSchema(r.Codec().Schema())
+	if err != nil {
+		return err
+	}
+	if schema.record == nil {
+		return errors.Errorf("expected avro records, got %s", r.Codec().Schema())
+	}
+	// fields[i] is the field imported into the i-th visible column, if any.
+	fields := make([]*importAvroField, len(d.conv.visibleCols))
+	for i, col := range d.conv.visibleCols {
+		name := exportAvroName(col.Name)
+		for j := range schema.record {
+			if schema.record[j].name == name {
+				fields[i] = &schema.record[j]
+			}
+		}
+	}
+
+	var count int64
+	for r.Scan() {
+		count++
+		native, err := r.Read()
+		if err != nil {
+			return makeRowErr(inputName, count, "%s", err)
+		}
+		record, ok := native.(map[string]interface{})
+		if !ok {
+			return makeRowErr(inputName, count, "unexpected avro record %T", native)
+		}
+		for i, field := range fields {
+			if field == nil {
+				d.conv.datums[i] = tree.DNull
+				continue
+			}
+			v, err := field.schema.unwrap(record[field.name])
+			if err == nil {
+				d.conv.datums[i], err = nativeValueToDatum(v, d.conv.visibleColTypes[i], d.conv.evalCtx)
+			}
+			if err != nil {
+				col := d.conv.visibleCols[i]
+				return makeRowErr(inputName, count, "parse %q as %s: %s", col.Name, col.Type.SQLString(), err)
+			}
+		}
+		if err := d.conv.row(ctx, inputIdx, count); err != nil {
+			return makeRowErr(inputName, count, "%s", err)
+		}
+		if err := progressFn(false /* finished */); err != nil {
+			return err
+		}
+	}
+	if err := r.Err(); err != nil {
+		return makeRowErr(inputName, count+1, "%s", err)
+	}
+	if err := progressFn(true /* finished */); err != nil {
+		return err
+	}
+	return d.conv.sendBatch(ctx)
+}
+
+// importAvroSchema is the part of an avro schema needed to turn the values
+// decoded by goavro into those accepted by nativeValueToDatum, which mostly
+// means finding the unions, whose values goavro wraps in a single entry map
+// keyed by the type of the value.
+type importAvroSchema struct {
+	// unionKey is the key used for values of this type in a union.
+	unionKey string
+	// union is set for union types.
+	union []*importAvroSchema
+	// items is set for arrays and maps, to the type of their elements.
+	items *importAvroSchema
+	// record is set for records. It's also non-nil for records without fields.
+	record []importAvroField
+	// ref is set for references to named types, which are resolved after the
+	// whole schema has been parsed.
+	ref string
+}
+
+type importAvroField struct {
+	name   string
+	schema *importAvroSchema
+}
+
+// parseImportAvroSchema parses the JSON encoding of an avro schema.
+func parseImportAvroSchema(schemaJSON string) (*importAvroSchema, error) {
+	var raw interface{}
+	if err := gojson.Unmarshal([]byte(schemaJSON), &raw); err != nil {
+		return nil, errors.Wrap(err, "parsing avro schema")
+	}
+	named := make(map[string]*importAvroSchema)
+	schema, err := parseImportAvroType(raw, "" /* namespace */, named)
+	if err != nil {
+		return nil, err
+	}
+	// resolve replaces the references in s, and the types within it, with the
+	// named types they refer to.
+	var resolve func(s *importAvroSchema) error
+	resolving := make(map[*importAvroSchema]bool)
+	resolveRef := func(s **importAvroSchema) error {
+		if ref := (*s).ref; ref != "" {
+			if *s = named[ref]; *s == nil {
+				return errors.Errorf("unknown avro type %s", ref)
+			}
+		}
+		return resolve(*s)
+	}
+	resolve = func(s *importAvroSchema) error {
+		if resolving[s] {
+			return nil
+		}
+		resolving[s] = true
+		for i := range s.union {
+			if err := resolveRef(&s.union[i]); err != nil {
+				return err
+			}
+		}
+		if s.items != nil {
+			if err := resolveRef(&s.items); err != nil {
+				return err
+			}
+		}
+		for i := range s.record {
+			if err := resolveRef(&s.record[i].schema); err != nil {
+				return errors.Wrapf(err, "field %s", s.record[i].name)
+			}
+		}
+		return nil
+	}
+	if err := resolve(schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func parseImportAvroType(
+	raw interface{}, namespace string, named map[string]*importAvroSchema,
+) (*importAvroSchema, error) {
+	switch t := raw.(type) {
+	case string:
+		switch t {
+		case `null`, `boolean`, `int`, `long`, `float`, `double`, `bytes`, `string`:
+			return &importAvroSchema{unionKey: t}, nil
+		}
+		fullName := t
+		if !strings.Contains(t, ".") && namespace != "" {
+			fullName = namespace + "." + t
+		}
+		if _, ok := named[fullName]; !ok {
+			fullName = t
+		}
+		return &importAvroSchema{unionKey: fullName, ref: fullName}, nil
+	case []interface{}:
+		s := &importAvroSchema{}
+		for _, branch := range t {
+			b, err := parseImportAvroType(branch, namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			s.union = append(s.union, b)
+		}
+		return s, nil
+	case map[string]interface{}:
+		typ, _ := t[`type`].(string)
+		s := &importAvroSchema{unionKey: typ}
+		switch typ {
+		case `array`:
+			items, err := parseImportAvroType(t[`items`], namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			s.items = items
+			return s, nil
+		case `map`:
+			values, err := parseImportAvroType(t[`values`], namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			s.items = values
+			return s, nil
+		case `record`, `error`, `enum`, `fixed`:
+			// Named types are keyed by their full name, which also sets the
+			// namespace of the names used within them.
+			name, _ := t[`name`].(string)
+			if ns, ok := t[`namespace`].(string); ok {
+				namespace = ns
+			}
+			if idx := strings.LastIndex(name, "."); idx >= 0 {
+				namespace = name[:idx]
+			} else if namespace != "" {
+				name = namespace + "." + name
+			}
+			s.unionKey = name
+			named[name] = s
+			if typ == `enum` || typ == `fixed` {
+				return s, nil
+			}
+			s.record = []importAvroField{}
+			fields, _ := t[`fields`].([]interface{})
+			for _, f := range fields {
+				f, _ := f.(map[string]interface{})
+				fieldName, _ := f[`name`].(string)
+				fieldType, err := parseImportAvroType(f[`type`], namespace, named)
+				if err != nil {
+					return nil, errors.Wrapf(err, "field %s", fieldName)
+				}
+				s.record = append(s.record, importAvroField{name: fieldName, schema: fieldType})
+			}
+			return s, nil
+		default:
+			if logical, ok := t[`logicalType`].(string); ok {
+				// goavro keys values with a logical type by both types.
+				s.unionKey = typ + "." + logical
+			}
+			return s, nil
+		}
+	default:
+		return nil, errors.Errorf("unexpected avro schema %v", raw)
+	}
+}
+
+// unwrap returns the given value, as decoded by goavro for this schema, with
+// all union values replaced by the value of their single branch.
+func (s *importAvroSchema) unwrap(v interface{}) (interface{}, error) {
+	switch {
+	case s.union != nil:
+		if v == nil {
+			return nil, nil
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return nil, errors.Errorf("unexpected avro union value %v", v)
+		}
+		for key, value := range m {
+			for _, branch := range s.union {
+				if branch.unionKey == key {
+					return branch.unwrap(value)
+				}
+			}
+			return nil, errors.Errorf("unexpected avro union type %s", key)
+		}
+	case s.items != nil:
+		switch v := v.(type) {
+		case []interface{}:
+			elems := make([]interface{}, len(v))
+			for i, e := range v {
+				var err error
+				if elems[i], err = s.items.unwrap(e); err != nil {
+					return nil, err
+				}
+			}
+			return elems, nil
+		case map[string]interface{}:
+			values := make(map[string]interface{}, len(v))
+			for k, e := range v {
+				var err error
+				if values[k], err = s.items.unwrap(e); err != nil {
+					return nil, err
+				}
+			}
+			return values, nil
+		}
+	case s.record != nil:
+		if m, ok := v.(map[string]interface{}); ok {
+			values := make(map[string]interface{}, len(m))
+			for _, f := range s.record {
+				var err error
+				if values[f.name], err = f.schema.unwrap(m[f.name]); err != nil {
+					return nil, err
+				}
+			}
+			return values, nil
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/linkedin/goavro"
+)
+
+func TestImportAvroSchemaUnwrap(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const schemaJSON = `{"type": "record", "name": "r", "namespace": "ns", "fields": [
+		{"name": "a", "type": ["null", "long"]},
+		{"name": "b", "type": {"type": "array", "items": ["null", "string"]}},
+		{"name": "c", "type": ["null", {"type": "record", "name": "inner", "fields": [
+			{"name": "x", "type": ["null", "int"]}
+		]}]},
+		{"name": "d", "type": ["null", "inner"]},
+		{"name": "e", "type": {"type": "map", "values": ["null", "double"]}}
+	]}`
+	codec, err := goavro.NewCodec(schemaJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	native, _, err := codec.NativeFromTextual([]byte(`{
+		"a": {"long": 1},
+		"b": [{"string": "x"}, null],
+		"c": {"ns.inner": {"x": {"int": 2}}},
+		"d": null,
+		"e": {"k": {"double": 1.5}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := parseImportAvroSchema(codec.Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := native.(map[string]interface{})
+	expected := map[string]interface{}{
+		`a`: int64(1),
+		`b`: []interface{}{`x`, nil},
+		`c`: map[string]interface{}{`x`: int32(2)},
+		`d`: nil,
+		`e`: map[string]interface{}{`k`: 1.5},
+	}
+	for _, field := range schema.record {
+		v, err := field.schema.unwrap(record[field.name])
+		if err != nil {
+			t.Fatalf("%s: %v", field.name, err)
+		}
+		if !reflect.DeepEqual(expected[field.name], v) {
+			t.Errorf("%s: expected %#v, got %#v", field.name, expected[field.name], v)
+		}
+	}
+
+	// Records are imported into JSONB columns as objects.
+	v, err := schema.record[2].schema.unwrap(record[`c`])
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := nativeValueToDatum(v, types.JSON, testEvalCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `'{"x": 2}'`; d.String() != expected {
+		t.Errorf("expected %s, got %s", expected, d)
+	}
+
+	if _, err := parseImportAvroSchema(`{"type": "array", "items": "missing"}`); !testutils.IsError(
+		err, `unknown avro type missing`,
+	) {
+		t.Errorf("expected unknown type error, got %v", err)
+	}
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stringArrayContents := sqlbase.ColumnType_STRING
+	names := []string{`i`, `s`, `d`, `ts`, `date`, `u`, `?column?`, `j`, `a`}
+	colTypes := []sqlbase.ColumnType{
+		{SemanticType: sqlbase.ColumnType_INT},
+		{SemanticType: sqlbase.ColumnType_STRING},
+		{SemanticType: sqlbase.ColumnType_DECIMAL, Precision: 3, Width: 2},
+		{SemanticType: sqlbase.ColumnType_TIMESTAMPTZ},
+		{SemanticType: sqlbase.ColumnType_DATE},
+		{SemanticType: sqlbase.ColumnType_UUID},
+		{SemanticType: sqlbase.ColumnType_INTERVAL},
+		{SemanticType: sqlbase.ColumnType_JSONB},
+		{SemanticType: sqlbase.ColumnType_ARRAY, ArrayContents: &stringArrayContents},
+	}
+	d, err := tree.ParseDDecimal(`-1.25`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interval, err := tree.ParseDInterval(`1h`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := tree.ParseDJSON(`{"a": [1, null]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := tree.NewDArray(types.String)
+	for _, e := range []tree.Datum{tree.NewDString(`x`), tree.DNull} {
+		if err := arr.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows := []tree.Datums{
+		{
+			tree.NewDInt(1), tree.NewDString(`a`), d,
+			tree.MakeDTimestampTZ(time.Date(2018, 12, 1, 2, 3, 4, 5000, time.UTC), time.Microsecond),
+			tree.NewDDate(17866), tree.NewDUuid(tree.DUuid{UUID: uuid.MakeV4()}), interval, j, arr,
+		},
+		{
+			tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+			tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+		},
+	}
+
+	file, err := encodeAvroOCF(names, colTypes, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := goavro.NewOCFReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := parseImportAvroSchema(r.Codec().Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]importAvroField)
+	for _, f := range schema.record {
+		fields[f.name] = f
+	}
+	for row := 0; r.Scan(); row++ {
+		native, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		record := native.(map[string]interface{})
+		for i, name := range names {
+			field, ok := fields[exportAvroName(name)]
+			if !ok {
+				t.Fatalf("no field for column %s", name)
+			}
+			v, err := field.schema.unwrap(record[field.name])
+			if err != nil {
+				t.Fatal(err)
+			}
+			datum, err := nativeValueToDatum(v, colTypes[i].ToDatumType(), testEvalCtx)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if datum.Compare(testEvalCtx, rows[row][i]) != 0 {
+				t.Errorf("%s row %d: expected %s, got %s", name, row, rows[row][i], datum)
+			}
+		}
+	}
+}
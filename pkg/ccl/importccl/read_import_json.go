@@ -0,0 +1,135 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/pkg/errors"
+)
+
+// ndjsonReader reads newline-delimited JSON, in which each non-empty line is
+// a JSON object. The value of each object field is imported into the column
+// of the same name. Columns without a field, or whose field is null, are NULL
+// and fields without a column are ignored.
+type ndjsonReader struct {
+	conv rowConverter
+	opts roachpb.NDJSONOptions
+}
+
+var _ inputConverter = &ndjsonReader{}
+
+func newNDJSONReader(
+	kvCh chan kvBatch,
+	opts roachpb.NDJSONOptions,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols []string,
+	maxRowID int64,
+	evalCtx *tree.EvalContext,
+) (*ndjsonReader, error) {
+	conv, err := newRowConverter(tableDesc, targetCols, maxRowID, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &ndjsonReader{
+		conv: *conv,
+		opts: opts,
+	}, nil
+}
+
+func (d *ndjsonReader) start(ctx ctxgroup.Group) {
+}
+
+func (d *ndjsonReader) inputFinished(ctx context.Context) {
+	close(d.conv.kvCh)
+}
+
+func (d *ndjsonReader) readFile(
+	ctx context.Context, input io.Reader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	s := bufio.NewScanner(input)
+	s.Buffer(nil, int(d.opts.MaxRowSize))
+
+	var count int64
+	for s.Scan() {
+		count++
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := d.convertLine(line); err != nil {
+			return makeRowErr(inputName, count, "%s", err)
+		}
+		if err := d.conv.row(ctx, inputIdx, count); err != nil {
+			return makeRowErr(inputName, count, "%s", err)
+		}
+		if err := progressFn(false /* finished */); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			err = errors.New("line too long")
+		}
+		return makeRowErr(inputName, count+1, "%s", err)
+	}
+	if err := progressFn(true /* finished */); err != nil {
+		return err
+	}
+	return d.conv.sendBatch(ctx)
+}
+
+// convertLine decodes the JSON object on a single line into d.conv.datums.
+func (d *ndjsonReader) convertLine(line []byte) error {
+	dec := gojson.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return err
+	}
+	if fields == nil {
+		return errors.New("expected a JSON object")
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON object")
+	}
+
+	for i, col := range d.conv.visibleCols {
+		v := fields[col.Name]
+		if v == nil {
+			d.conv.datums[i] = tree.DNull
+			continue
+		}
+		var err error
+		if d.conv.visibleColTypes[i] == types.JSON {
+			// Unlike the other formats, which encode JSON as a string, the value
+			// is the JSON to import.
+			var j json.JSON
+			if j, err = json.MakeJSON(v); err == nil {
+				d.conv.datums[i] = tree.NewDJSON(j)
+			}
+		} else {
+			d.conv.datums[i], err = nativeValueToDatum(v, d.conv.visibleColTypes[i], d.conv.evalCtx)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "parse %q as %s", col.Name, col.Type.SQLString())
+		}
+	}
+	return nil
+}
@@ -59,7 +59,7 @@ func newMysqldumpReader(
 			converters[name] = nil
 			continue
 		}
-		conv, err := newRowConverter(table, evalCtx, kvCh)
+		conv, err := newRowConverter(table, nil /* targetCols */, 0 /* maxRowID */, evalCtx, kvCh)
 		if err != nil {
 			return nil, err
 		}
@@ -31,9 +31,11 @@ func newMysqloutfileReader(
 	kvCh chan kvBatch,
 	opts roachpb.MySQLOutfileOptions,
 	tableDesc *sqlbase.TableDescriptor,
+	targetCols []string,
+	maxRowID int64,
 	evalCtx *tree.EvalContext,
 ) (*mysqloutfileReader, error) {
-	conv, err := newRowConverter(tableDesc, evalCtx, kvCh)
+	conv, err := newRowConverter(tableDesc, targetCols, maxRowID, evalCtx, kvCh)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,1092 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"math/bits"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+)
+
+// This file contains a reader for the Apache Parquet file format
+// (https://github.com/apache/parquet-format), supporting the subset of the
+// format written by EXPORT and by most other writers: columns that are
+// primitive values or lists of them, PLAIN and dictionary encoded data pages
+// (both versions) and uncompressed, snappy or gzip compressed column chunks.
+// The file metadata is at the end of the file, so files are read out of order,
+// one row group at a time.
+
+// More values of the enums defined in parquet.thrift. The rest are in
+// export_parquet.go.
+const (
+	parquetTypeInt96 = 3
+	parquetTypeFloat = 4
+
+	parquetConvertedEnum            = 4
+	parquetConvertedTimeMillis      = 7
+	parquetConvertedTimeMicros      = 8
+	parquetConvertedTimestampMillis = 9
+	parquetConvertedUint8           = 11
+	parquetConvertedUint32          = 13
+	parquetConvertedUint64          = 14
+
+	parquetEncodingPlainDictionary = 2
+	parquetEncodingRLEDictionary   = 8
+
+	parquetCodecSnappy = 1
+	parquetCodecGzip   = 2
+
+	parquetPageTypeDictionary = 2
+	parquetPageTypeDataV2     = 3
+
+	// Field ids in the LogicalType union.
+	parquetLogicalTypeString    = 1
+	parquetLogicalTypeEnum      = 4
+	parquetLogicalTypeDecimal   = 5
+	parquetLogicalTypeDate      = 6
+	parquetLogicalTypeTime      = 7
+	parquetLogicalTypeTimestamp = 8
+	parquetLogicalTypeJSON      = 12
+)
+
+// parquetReader reads Parquet files whose rows are imported as rows. Each
+// top-level column of the file is imported into the table column of the same
+// name. Table columns without a Parquet column are NULL and Parquet columns
+// without a table column are ignored.
+type parquetReader struct {
+	conv rowConverter
+}
+
+var _ inputConverter = &parquetReader{}
+
+func newParquetReader(
+	kvCh chan kvBatch,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols []string,
+	maxRowID int64,
+	evalCtx *tree.EvalContext,
+) (*parquetReader, error) {
+	conv, err := newRowConverter(tableDesc, targetCols, maxRowID, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetReader{conv: *conv}, nil
+}
+
+func (d *parquetReader) start(ctx ctxgroup.Group) {
+}
+
+func (d *parquetReader) inputFinished(ctx context.Context) {
+	close(d.conv.kvCh)
+}
+
+func (d *parquetReader) readFile(
+	ctx context.Context, input io.Reader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	r, size, cleanup, err := parquetReaderAt(input, progressFn)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	f, err := openParquetFile(r, size)
+	if err != nil {
+		return err
+	}
+	// cols[i] is the Parquet column imported into the i-th visible column, if
+	// any.
+	cols := make([]*parquetLeafColumn, len(d.conv.visibleCols))
+	for i, col := range d.conv.visibleCols {
+		if cols[i], err = f.column(col.Name); err != nil {
+			return err
+		}
+	}
+
+	var count int64
+	for _, rg := range f.rowGroups {
+		numRows, _ := rg.i64(3)
+		values := make([][]interface{}, len(cols))
+		for i, c := range cols {
+			if c == nil {
+				continue
+			}
+			if values[i], err = f.readColumnChunk(rg, c); err != nil {
+				return errors.Wrapf(err, "reading column %s", c.name)
+			}
+			if int64(len(values[i])) != numRows {
+				return errors.Errorf("reading column %s: expected %d rows, got %d", c.name, numRows, len(values[i]))
+			}
+		}
+		for r := 0; r < int(numRows); r++ {
+			count++
+			for i := range cols {
+				if cols[i] == nil {
+					d.conv.datums[i] = tree.DNull
+					continue
+				}
+				d.conv.datums[i], err = nativeValueToDatum(values[i][r], d.conv.visibleColTypes[i], d.conv.evalCtx)
+				if err != nil {
+					col := d.conv.visibleCols[i]
+					return makeRowErr(inputName, count, "parse %q as %s: %s", col.Name, col.Type.SQLString(), err)
+				}
+			}
+			if err := d.conv.row(ctx, inputIdx, count); err != nil {
+				return makeRowErr(inputName, count, "%s", err)
+			}
+		}
+		if err := progressFn(false /* finished */); err != nil {
+			return err
+		}
+	}
+	if err := progressFn(true /* finished */); err != nil {
+		return err
+	}
+	return d.conv.sendBatch(ctx)
+}
+
+// sizedReaderAt is input, such as a local file, that can be read at any
+// offset.
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// parquetCopyChunkSize is the number of bytes of a file copied to a temporary
+// file between reports of progress.
+const parquetCopyChunkSize = 64 << 20
+
+// parquetReaderAt returns input as an io.ReaderAt, along with its size and a
+// function releasing it. Input that cannot be read at any offset is first
+// copied to a temporary file, reporting progress as it is.
+func parquetReaderAt(
+	input io.Reader, progressFn progressFn,
+) (io.ReaderAt, int64, func(), error) {
+	if r, ok := input.(sizedReaderAt); ok {
+		return r, r.Size(), func() {}, nil
+	}
+	tmp, err := ioutil.TempFile("", "import-parquet")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	var size int64
+	for {
+		n, err := io.CopyN(tmp, input, parquetCopyChunkSize)
+		size += n
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = progressFn(false /* finished */)
+		}
+		if err != nil {
+			cleanup()
+			return nil, 0, nil, err
+		}
+	}
+	return tmp, size, cleanup, nil
+}
+
+// readParquetAt reads len(p) bytes of the file at off.
+func readParquetAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// parquetFile is a Parquet file whose metadata has been decoded.
+type parquetFile struct {
+	r         io.ReaderAt
+	size      int64
+	schema    *parquetSchemaNode
+	rowGroups []parquetThriftStruct
+}
+
+// parquetSchemaNode is an element of the schema tree of a Parquet file.
+type parquetSchemaNode struct {
+	elem       parquetThriftStruct
+	name       string
+	repetition int32
+	children   []*parquetSchemaNode
+}
+
+// parquetLeafColumn describes how the values of a top-level column are
+// stored in a leaf column of the file.
+type parquetLeafColumn struct {
+	name       string
+	path       []string
+	physical   int32
+	typeLength int32
+	maxDef     int32
+	maxRep     int32
+	// array is set for list columns, for which listDef is the smallest
+	// definition level at which the list is not NULL, and elemDef the smallest
+	// at which it has an element.
+	array   bool
+	listDef int32
+	elemDef int32
+	// convert turns the PLAIN decoded physical value into the value of its
+	// logical type.
+	convert func(interface{}) (interface{}, error)
+}
+
+// openParquetFile reads the metadata of the Parquet file of the given size
+// read from r.
+func openParquetFile(r io.ReaderAt, size int64) (*parquetFile, error) {
+	// The file ends with the length of the footer and the magic number.
+	const trailerLen = 8
+	if size < int64(len(parquetMagic)+trailerLen) {
+		return nil, errors.New("not a parquet file")
+	}
+	var head [len(parquetMagic)]byte
+	var trailer [trailerLen]byte
+	if err := readParquetAt(r, head[:], 0); err != nil {
+		return nil, err
+	}
+	if err := readParquetAt(r, trailer[:], size-trailerLen); err != nil {
+		return nil, err
+	}
+	if string(head[:]) != parquetMagic || string(trailer[trailerLen-len(parquetMagic):]) != parquetMagic {
+		return nil, errors.New("not a parquet file")
+	}
+	footerLen := int64(binary.LittleEndian.Uint32(trailer[:]))
+	footerStart := size - trailerLen - footerLen
+	if footerStart < int64(len(parquetMagic)) {
+		return nil, errors.New("invalid parquet footer length")
+	}
+	footer := make([]byte, footerLen)
+	if err := readParquetAt(r, footer, footerStart); err != nil {
+		return nil, errors.Wrap(err, "reading parquet metadata")
+	}
+	meta, _, err := readParquetThriftBytes(footer)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading parquet metadata")
+	}
+
+	var elems []parquetThriftStruct
+	for _, e := range meta.list(2) {
+		e, ok := e.(parquetThriftStruct)
+		if !ok {
+			return nil, errors.New("invalid parquet schema")
+		}
+		elems = append(elems, e)
+	}
+	schema, n, err := buildParquetSchema(elems)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(elems) {
+		return nil, errors.New("invalid parquet schema")
+	}
+
+	f := &parquetFile{r: r, size: size, schema: schema}
+	for _, rg := range meta.list(4) {
+		rg, ok := rg.(parquetThriftStruct)
+		if !ok {
+			return nil, errors.New("invalid parquet row group")
+		}
+		f.rowGroups = append(f.rowGroups, rg)
+	}
+	return f, nil
+}
+
+// buildParquetSchema returns the tree of the flattened, depth-first schema
+// elements, and the number of elements it used.
+func buildParquetSchema(elems []parquetThriftStruct) (*parquetSchemaNode, int, error) {
+	if len(elems) == 0 {
+		return nil, 0, errors.New("invalid parquet schema")
+	}
+	repetition, _ := elems[0].i32(3)
+	node := &parquetSchemaNode{
+		elem:       elems[0],
+		name:       elems[0].str(4),
+		repetition: repetition,
+	}
+	used := 1
+	numChildren, _ := elems[0].i32(5)
+	for i := int32(0); i < numChildren; i++ {
+		child, n, err := buildParquetSchema(elems[used:])
+		if err != nil {
+			return nil, 0, err
+		}
+		node.children = append(node.children, child)
+		used += n
+	}
+	return node, used, nil
+}
+
+// column returns the leaf column storing the values of the top-level column
+// with the given name, or nil if there is no such column.
+func (f *parquetFile) column(name string) (*parquetLeafColumn, error) {
+	var field *parquetSchemaNode
+	for _, child := range f.schema.children {
+		if child.name == name {
+			field = child
+		}
+	}
+	if field == nil {
+		return nil, nil
+	}
+
+	c := &parquetLeafColumn{name: name, path: []string{field.name}}
+	leaf := field
+	if len(field.children) == 0 {
+		switch field.repetition {
+		case parquetRepetitionOptional:
+			c.maxDef = 1
+		case parquetRepetitionRepeated:
+			// A repeated primitive is a list that can't be NULL and has no
+			// NULL elements.
+			c.array = true
+			c.elemDef, c.maxDef, c.maxRep = 1, 1, 1
+		}
+	} else {
+		// Lists are either a group with a repeated group of a single element
+		// (the standard three level representation) or a group with a repeated
+		// primitive (the legacy two level representation).
+		if len(field.children) != 1 || field.repetition == parquetRepetitionRepeated ||
+			field.children[0].repetition != parquetRepetitionRepeated {
+			return nil, errors.Errorf("unsupported parquet column %s: only primitives and lists are supported", name)
+		}
+		c.array = true
+		c.maxRep = 1
+		if field.repetition == parquetRepetitionOptional {
+			c.listDef = 1
+		}
+		c.elemDef = c.listDef + 1
+		c.maxDef = c.elemDef
+		repeated := field.children[0]
+		c.path = append(c.path, repeated.name)
+		leaf = repeated
+		if len(repeated.children) > 0 {
+			if len(repeated.children) != 1 || len(repeated.children[0].children) > 0 ||
+				repeated.children[0].repetition == parquetRepetitionRepeated {
+				return nil, errors.Errorf("unsupported parquet column %s: only lists of primitives are supported", name)
+			}
+			leaf = repeated.children[0]
+			c.path = append(c.path, leaf.name)
+			if leaf.repetition == parquetRepetitionOptional {
+				c.maxDef++
+			}
+		}
+	}
+
+	physical, ok := leaf.elem.i32(1)
+	if !ok {
+		return nil, errors.Errorf("parquet column %s has no type", name)
+	}
+	c.physical = physical
+	c.typeLength, _ = leaf.elem.i32(2)
+	var err error
+	if c.convert, err = parquetLogicalConversion(leaf.elem); err != nil {
+		return nil, errors.Wrapf(err, "parquet column %s", name)
+	}
+	return c, nil
+}
+
+// parquetLogicalConversion returns the function converting the physical values
+// of the given leaf schema element into values of its logical type, as
+// accepted by nativeValueToDatum.
+func parquetLogicalConversion(
+	elem parquetThriftStruct,
+) (func(interface{}) (interface{}, error), error) {
+	identity := func(v interface{}) (interface{}, error) { return v, nil }
+	converted, hasConverted := elem.i32(6)
+	if !hasConverted {
+		converted = parquetConvertedNone
+	}
+	logical := elem.structField(10)
+	logicalIs := func(id int16) bool {
+		_, ok := logical[id]
+		return ok
+	}
+	timeUnit := func(id int16, defaultUnit time.Duration) time.Duration {
+		unit := logical.structField(id).structField(2)
+		switch {
+		case unit.has(1):
+			return time.Millisecond
+		case unit.has(2):
+			return time.Microsecond
+		case unit.has(3):
+			return time.Nanosecond
+		}
+		return defaultUnit
+	}
+
+	switch {
+	case converted == parquetConvertedUTF8 || converted == parquetConvertedEnum ||
+		converted == parquetConvertedJSON || logicalIs(parquetLogicalTypeString) ||
+		logicalIs(parquetLogicalTypeEnum) || logicalIs(parquetLogicalTypeJSON):
+		return func(v interface{}) (interface{}, error) {
+			if b, ok := v.([]byte); ok {
+				return string(b), nil
+			}
+			return v, nil
+		}, nil
+
+	case converted == parquetConvertedDecimal || logicalIs(parquetLogicalTypeDecimal):
+		scale, ok := elem.i32(7)
+		if !ok {
+			scale, _ = logical.structField(parquetLogicalTypeDecimal).i32(1)
+		}
+		denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+		return func(v interface{}) (interface{}, error) {
+			var unscaled *big.Int
+			switch v := v.(type) {
+			case int32:
+				unscaled = big.NewInt(int64(v))
+			case int64:
+				unscaled = big.NewInt(v)
+			case []byte:
+				unscaled = twosComplementToBigInt(v)
+			default:
+				return nil, errors.Errorf("unexpected decimal value %T", v)
+			}
+			return new(big.Rat).SetFrac(unscaled, denom), nil
+		}, nil
+
+	case converted == parquetConvertedDate || logicalIs(parquetLogicalTypeDate):
+		return func(v interface{}) (interface{}, error) {
+			days, ok := v.(int32)
+			if !ok {
+				return nil, errors.Errorf("unexpected date value %T", v)
+			}
+			return time.Unix(int64(days)*secondsPerDay, 0).UTC(), nil
+		}, nil
+
+	case converted == parquetConvertedTimeMillis || converted == parquetConvertedTimeMicros ||
+		logicalIs(parquetLogicalTypeTime):
+		unit := time.Microsecond
+		if converted == parquetConvertedTimeMillis {
+			unit = time.Millisecond
+		}
+		unit = timeUnit(parquetLogicalTypeTime, unit)
+		return func(v interface{}) (interface{}, error) {
+			switch v := v.(type) {
+			case int32:
+				return time.Duration(v) * unit, nil
+			case int64:
+				return time.Duration(v) * unit, nil
+			}
+			return nil, errors.Errorf("unexpected time value %T", v)
+		}, nil
+
+	case converted == parquetConvertedTimestampMillis || converted == parquetConvertedTimestampMicros ||
+		logicalIs(parquetLogicalTypeTimestamp):
+		unit := time.Microsecond
+		if converted == parquetConvertedTimestampMillis {
+			unit = time.Millisecond
+		}
+		unit = timeUnit(parquetLogicalTypeTimestamp, unit)
+		return func(v interface{}) (interface{}, error) {
+			i, ok := v.(int64)
+			if !ok {
+				return nil, errors.Errorf("unexpected timestamp value %T", v)
+			}
+			perSecond := int64(time.Second / unit)
+			return time.Unix(i/perSecond, (i%perSecond)*int64(unit)).UTC(), nil
+		}, nil
+
+	case converted >= parquetConvertedUint8 && converted <= parquetConvertedUint32:
+		return func(v interface{}) (interface{}, error) {
+			if i, ok := v.(int32); ok {
+				return int64(uint32(i)), nil
+			}
+			return v, nil
+		}, nil
+
+	case converted == parquetConvertedUint64:
+		return func(v interface{}) (interface{}, error) {
+			if i, ok := v.(int64); ok && i < 0 {
+				// Too large for an INT, but it might still be imported into a
+				// DECIMAL or STRING column.
+				return strconv.FormatUint(uint64(i), 10), nil
+			}
+			return v, nil
+		}, nil
+	}
+	return identity, nil
+}
+
+// twosComplementToBigInt is the inverse of bigIntToTwosComplement.
+func twosComplementToBigInt(b []byte) *big.Int {
+	i := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return i
+}
+
+// readColumnChunk returns the value of the given column in each row of the
+// given row group.
+func (f *parquetFile) readColumnChunk(
+	rg parquetThriftStruct, c *parquetLeafColumn,
+) ([]interface{}, error) {
+	var meta parquetThriftStruct
+	for _, chunk := range rg.list(1) {
+		chunk, ok := chunk.(parquetThriftStruct)
+		if !ok {
+			return nil, errors.New("invalid column chunk")
+		}
+		m := chunk.structField(3)
+		path := m.list(3)
+		if len(path) != len(c.path) {
+			continue
+		}
+		match := true
+		for i := range path {
+			if p, ok := path[i].([]byte); !ok || string(p) != c.path[i] {
+				match = false
+			}
+		}
+		if match {
+			if chunk.has(1) {
+				return nil, errors.New("column chunks in other files are not supported")
+			}
+			meta = m
+		}
+	}
+	if meta == nil {
+		return nil, errors.New("column chunk not found")
+	}
+
+	start, _ := meta.i64(9)
+	if dictStart, ok := meta.i64(11); ok && dictStart > 0 && dictStart < start {
+		start = dictStart
+	}
+	size, _ := meta.i64(7)
+	if start < 0 || size < 0 || start+size > f.size {
+		return nil, errors.New("invalid column chunk offsets")
+	}
+	chunk := make([]byte, size)
+	if err := readParquetAt(f.r, chunk, start); err != nil {
+		return nil, errors.Wrap(err, "reading column chunk")
+	}
+	numValues, _ := meta.i64(5)
+	codec, _ := meta.i32(4)
+
+	var dict []interface{}
+	var defLevels, repLevels []int32
+	var values []interface{}
+	for int64(len(defLevels)) < numValues {
+		header, n, err := readParquetThriftBytes(chunk)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading page header")
+		}
+		chunk = chunk[n:]
+		pageSize, _ := header.i32(3)
+		if pageSize < 0 || int(pageSize) > len(chunk) {
+			return nil, errors.New("invalid page size")
+		}
+		page := chunk[:pageSize]
+		chunk = chunk[pageSize:]
+
+		pageType, _ := header.i32(1)
+		var num int32
+		var encoding int32
+		var defs, reps []int32
+		switch pageType {
+		case parquetPageTypeDictionary:
+			dh := header.structField(7)
+			num, _ = dh.i32(1)
+			if page, err = parquetDecompress(codec, page); err != nil {
+				return nil, err
+			}
+			if dict, _, err = decodeParquetPlain(c, page, int(num)); err != nil {
+				return nil, errors.Wrap(err, "reading dictionary")
+			}
+			continue
+
+		case parquetPageTypeData:
+			dh := header.structField(5)
+			num, _ = dh.i32(1)
+			encoding, _ = dh.i32(2)
+			if page, err = parquetDecompress(codec, page); err != nil {
+				return nil, err
+			}
+			if c.maxRep > 0 {
+				if reps, page, err = decodeParquetLevels(page, c.maxRep, int(num)); err != nil {
+					return nil, errors.Wrap(err, "reading repetition levels")
+				}
+			}
+			if c.maxDef > 0 {
+				if defs, page, err = decodeParquetLevels(page, c.maxDef, int(num)); err != nil {
+					return nil, errors.Wrap(err, "reading definition levels")
+				}
+			}
+
+		case parquetPageTypeDataV2:
+			dh := header.structField(8)
+			num, _ = dh.i32(1)
+			encoding, _ = dh.i32(4)
+			defLen, _ := dh.i32(5)
+			repLen, _ := dh.i32(6)
+			if defLen < 0 || repLen < 0 || int64(defLen)+int64(repLen) > int64(len(page)) {
+				return nil, errors.New("invalid level lengths")
+			}
+			if c.maxRep > 0 {
+				if reps, err = decodeParquetHybrid(page[:repLen], parquetBitWidth(c.maxRep), int(num)); err != nil {
+					return nil, errors.Wrap(err, "reading repetition levels")
+				}
+			}
+			if c.maxDef > 0 {
+				if defs, err = decodeParquetHybrid(page[repLen:repLen+defLen], parquetBitWidth(c.maxDef), int(num)); err != nil {
+					return nil, errors.Wrap(err, "reading definition levels")
+				}
+			}
+			page = page[repLen+defLen:]
+			if compressed, ok := dh[7].(bool); !ok || compressed {
+				if page, err = parquetDecompress(codec, page); err != nil {
+					return nil, err
+				}
+			}
+
+		default:
+			// Index pages are not needed.
+			continue
+		}
+		if num < 0 {
+			return nil, errors.New("invalid page value count")
+		}
+
+		if defs == nil {
+			defs = make([]int32, num)
+			for i := range defs {
+				defs[i] = c.maxDef
+			}
+		}
+		if reps == nil {
+			reps = make([]int32, num)
+		}
+		nonNull := 0
+		for _, d := range defs {
+			if d == c.maxDef {
+				nonNull++
+			}
+		}
+		pageValues, err := decodeParquetValues(c, encoding, page, nonNull, dict)
+		if err != nil {
+			return nil, err
+		}
+		defLevels = append(defLevels, defs...)
+		repLevels = append(repLevels, reps...)
+		values = append(values, pageValues...)
+	}
+	return assembleParquetRows(c, defLevels, repLevels, values)
+}
+
+// assembleParquetRows returns the value in each row given the levels and
+// non-NULL values of a column chunk.
+func assembleParquetRows(
+	c *parquetLeafColumn, defLevels, repLevels []int32, values []interface{},
+) ([]interface{}, error) {
+	var rows []interface{}
+	next := 0
+	for i, def := range defLevels {
+		var v interface{}
+		if def == c.maxDef {
+			if next >= len(values) {
+				return nil, errors.New("too few values")
+			}
+			v = values[next]
+			next++
+		}
+		if !c.array {
+			rows = append(rows, v)
+			continue
+		}
+		if repLevels[i] > 0 {
+			if len(rows) == 0 {
+				return nil, errors.New("invalid repetition level")
+			}
+			elems, ok := rows[len(rows)-1].([]interface{})
+			if !ok || def < c.elemDef {
+				return nil, errors.New("invalid definition level")
+			}
+			rows[len(rows)-1] = append(elems, v)
+			continue
+		}
+		switch {
+		case def < c.listDef:
+			rows = append(rows, nil)
+		case def < c.elemDef:
+			rows = append(rows, []interface{}{})
+		default:
+			rows = append(rows, []interface{}{v})
+		}
+	}
+	return rows, nil
+}
+
+func parquetBitWidth(max int32) int {
+	return bits.Len32(uint32(max))
+}
+
+func parquetDecompress(codec int32, b []byte) ([]byte, error) {
+	switch codec {
+	case parquetCodecUncompressed:
+		return b, nil
+	case parquetCodecSnappy:
+		return snappy.Decode(nil, b)
+	case parquetCodecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(r)
+	default:
+		return nil, errors.Errorf("unsupported parquet compression codec %d", codec)
+	}
+}
+
+// decodeParquetLevels decodes n levels in the RLE/bit-packing hybrid encoding,
+// prefixed by its length, and returns them with the rest of the page.
+func decodeParquetLevels(page []byte, max int32, n int) ([]int32, []byte, error) {
+	if len(page) < 4 {
+		return nil, nil, errors.New("unexpected end of page")
+	}
+	length := binary.LittleEndian.Uint32(page)
+	page = page[4:]
+	if uint64(length) > uint64(len(page)) {
+		return nil, nil, errors.New("unexpected end of page")
+	}
+	levels, err := decodeParquetHybrid(page[:length], parquetBitWidth(max), n)
+	return levels, page[length:], err
+}
+
+// decodeParquetHybrid decodes n values of the given bit width in the
+// RLE/bit-packing hybrid encoding.
+func decodeParquetHybrid(b []byte, width int, n int) ([]int32, error) {
+	if width > 32 {
+		return nil, errors.Errorf("invalid bit width %d", width)
+	}
+	if n < 0 {
+		return nil, errors.New("invalid value count")
+	}
+	byteWidth := (width + 7) / 8
+	// n comes from the file, so don't trust it for more than the values that
+	// b could hold bit-packed; RLE runs grow out as they are decoded.
+	capacity := n
+	if max := len(b) * 8; capacity > max {
+		capacity = max
+	}
+	out := make([]int32, 0, capacity)
+	for len(out) < n {
+		header, k := binary.Uvarint(b)
+		if k <= 0 {
+			return nil, errors.New("unexpected end of encoded values")
+		}
+		b = b[k:]
+		if header&1 == 0 {
+			// An RLE run of a single repeated value.
+			count := header >> 1
+			if len(b) < byteWidth {
+				return nil, errors.New("unexpected end of encoded values")
+			}
+			var v int32
+			for i := 0; i < byteWidth; i++ {
+				v |= int32(b[i]) << uint(8*i)
+			}
+			b = b[byteWidth:]
+			for i := uint64(0); i < count && len(out) < n; i++ {
+				out = append(out, v)
+			}
+			continue
+		}
+		// Bit-packed groups of 8 values, least significant bit first.
+		groups := header >> 1
+		// Check groups on its own first so that the product can't overflow.
+		if width > 0 && groups > uint64(len(b)) || groups*uint64(width) > uint64(len(b)) {
+			return nil, errors.New("unexpected end of encoded values")
+		}
+		packed := b[:groups*uint64(width)]
+		b = b[len(packed):]
+		for i := 0; i < int(groups)*8 && len(out) < n; i++ {
+			var v int32
+			for j := 0; j < width; j++ {
+				bit := i*width + j
+				v |= int32(packed[bit/8]>>uint(bit%8)&1) << uint(j)
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// decodeParquetValues decodes the n non-NULL values of a data page.
+func decodeParquetValues(
+	c *parquetLeafColumn, encoding int32, page []byte, n int, dict []interface{},
+) ([]interface{}, error) {
+	switch encoding {
+	case parquetEncodingPlain:
+		values, _, err := decodeParquetPlain(c, page, n)
+		return values, err
+
+	case parquetEncodingPlainDictionary, parquetEncodingRLEDictionary:
+		if dict == nil {
+			return nil, errors.New("dictionary encoded page without a dictionary")
+		}
+		if len(page) < 1 {
+			return nil, errors.New("unexpected end of page")
+		}
+		indexes, err := decodeParquetHybrid(page[1:], int(page[0]), n)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, n)
+		for i, idx := range indexes {
+			if idx < 0 || int(idx) >= len(dict) {
+				return nil, errors.Errorf("invalid dictionary index %d", idx)
+			}
+			values[i] = dict[idx]
+		}
+		return values, nil
+
+	case parquetEncodingRLE:
+		if c.physical != parquetTypeBoolean {
+			break
+		}
+		bools, _, err := decodeParquetLevels(page, 1, n)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, n)
+		for i, b := range bools {
+			values[i] = b == 1
+		}
+		return values, nil
+	}
+	return nil, errors.Errorf("unsupported parquet encoding %d", encoding)
+}
+
+// decodeParquetPlain decodes n PLAIN encoded values, converted to their
+// logical type, and returns the rest of the page.
+func decodeParquetPlain(
+	c *parquetLeafColumn, page []byte, n int,
+) ([]interface{}, []byte, error) {
+	if n < 0 {
+		return nil, nil, errors.New("invalid value count")
+	}
+	// Every PLAIN encoded value takes at least a bit.
+	if n > len(page)*8 {
+		return nil, nil, errors.New("unexpected end of page")
+	}
+	next := func(size int) ([]byte, error) {
+		if size < 0 || size > len(page) {
+			return nil, errors.New("unexpected end of page")
+		}
+		b := page[:size]
+		page = page[size:]
+		return b, nil
+	}
+
+	values := make([]interface{}, n)
+	if c.physical == parquetTypeBoolean {
+		packed, err := next((n + 7) / 8)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range values {
+			values[i] = packed[i/8]>>uint(i%8)&1 == 1
+		}
+		return values, page, nil
+	}
+
+	for i := range values {
+		var v interface{}
+		switch c.physical {
+		case parquetTypeInt32, parquetTypeFloat:
+			b, err := next(4)
+			if err != nil {
+				return nil, nil, err
+			}
+			if i := binary.LittleEndian.Uint32(b); c.physical == parquetTypeInt32 {
+				v = int32(i)
+			} else {
+				v = math.Float32frombits(i)
+			}
+		case parquetTypeInt64, parquetTypeDouble:
+			b, err := next(8)
+			if err != nil {
+				return nil, nil, err
+			}
+			if i := binary.LittleEndian.Uint64(b); c.physical == parquetTypeInt64 {
+				v = int64(i)
+			} else {
+				v = math.Float64frombits(i)
+			}
+		case parquetTypeInt96:
+			// Legacy timestamps: nanoseconds within the day followed by the
+			// julian day.
+			b, err := next(12)
+			if err != nil {
+				return nil, nil, err
+			}
+			const julianUnixEpoch = 2440588
+			nanos := int64(binary.LittleEndian.Uint64(b))
+			days := int64(binary.LittleEndian.Uint32(b[8:])) - julianUnixEpoch
+			v = time.Unix(days*secondsPerDay, nanos).UTC()
+		case parquetTypeByteArray:
+			length, err := next(4)
+			if err != nil {
+				return nil, nil, err
+			}
+			if v, err = next(int(binary.LittleEndian.Uint32(length))); err != nil {
+				return nil, nil, err
+			}
+		case parquetTypeFixedLenByteArray:
+			var err error
+			if v, err = next(int(c.typeLength)); err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, errors.Errorf("unsupported parquet type %d", c.physical)
+		}
+		var err error
+		if values[i], err = c.convert(v); err != nil {
+			return nil, nil, err
+		}
+	}
+	return values, page, nil
+}
+
+// parquetThriftStruct is a decoded thrift struct, mapping field ids to values.
+// Nested structs are parquetThriftStructs, lists are []interface{} and
+// strings are []byte.
+type parquetThriftStruct map[int16]interface{}
+
+func (s parquetThriftStruct) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s parquetThriftStruct) i32(id int16) (int32, bool) {
+	v, ok := s[id].(int32)
+	return v, ok
+}
+
+func (s parquetThriftStruct) i64(id int16) (int64, bool) {
+	v, ok := s[id].(int64)
+	return v, ok
+}
+
+func (s parquetThriftStruct) str(id int16) string {
+	v, _ := s[id].([]byte)
+	return string(v)
+}
+
+// structField returns the struct with the given id, or nil if there is none.
+// Since nil has no fields, the result can always be used.
+func (s parquetThriftStruct) structField(id int16) parquetThriftStruct {
+	v, _ := s[id].(parquetThriftStruct)
+	return v
+}
+
+func (s parquetThriftStruct) list(id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
+
+// readParquetThriftBytes decodes the thrift compact protocol struct at the
+// start of b, and returns it and the number of bytes it used.
+func readParquetThriftBytes(b []byte) (parquetThriftStruct, int, error) {
+	buf := &thrift.TMemoryBuffer{Buffer: bytes.NewBuffer(b)}
+	s, err := readParquetThrift(thrift.NewTCompactProtocol(buf))
+	if err != nil {
+		return nil, 0, err
+	}
+	return s, len(b) - buf.Len(), nil
+}
+
+func readParquetThrift(p *thrift.TCompactProtocol) (parquetThriftStruct, error) {
+	s := make(parquetThriftStruct)
+	if _, err := p.ReadStructBegin(); err != nil {
+		return nil, err
+	}
+	for {
+		_, typ, id, err := p.ReadFieldBegin()
+		if err != nil {
+			return nil, err
+		}
+		if typ == thrift.STOP {
+			break
+		}
+		if s[id], err = readParquetThriftValue(p, typ); err != nil {
+			return nil, err
+		}
+		if err := p.ReadFieldEnd(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.ReadStructEnd(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func readParquetThriftValue(p *thrift.TCompactProtocol, typ thrift.TType) (interface{}, error) {
+	switch typ {
+	case thrift.BOOL:
+		return p.ReadBool()
+	case thrift.BYTE:
+		return p.ReadByte()
+	case thrift.I16:
+		return p.ReadI16()
+	case thrift.I32:
+		return p.ReadI32()
+	case thrift.I64:
+		return p.ReadI64()
+	case thrift.DOUBLE:
+		return p.ReadDouble()
+	case thrift.STRING:
+		return p.ReadBinary()
+	case thrift.STRUCT:
+		return readParquetThrift(p)
+	case thrift.LIST, thrift.SET:
+		elemType, size, err := p.ReadListBegin()
+		if err != nil {
+			return nil, err
+		}
+		var elems []interface{}
+		for i := 0; i < size; i++ {
+			e, err := readParquetThriftValue(p, elemType)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, e)
+		}
+		return elems, p.ReadListEnd()
+	default:
+		// Maps aren't used by any of the metadata that is read.
+		return nil, p.Skip(typ)
+	}
+}
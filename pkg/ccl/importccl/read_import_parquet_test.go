@@ -0,0 +1,311 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/golang/snappy"
+)
+
+func TestParquetRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stringArrayContents := sqlbase.ColumnType_STRING
+	names := []string{`i`, `s`, `d`, `ts`, `date`, `u`, `j`, `b`, `f`, `a`}
+	colTypes := []sqlbase.ColumnType{
+		{SemanticType: sqlbase.ColumnType_INT},
+		{SemanticType: sqlbase.ColumnType_STRING},
+		{SemanticType: sqlbase.ColumnType_DECIMAL, Precision: 3, Width: 2},
+		{SemanticType: sqlbase.ColumnType_TIMESTAMP},
+		{SemanticType: sqlbase.ColumnType_DATE},
+		{SemanticType: sqlbase.ColumnType_UUID},
+		{SemanticType: sqlbase.ColumnType_JSONB},
+		{SemanticType: sqlbase.ColumnType_BOOL},
+		{SemanticType: sqlbase.ColumnType_FLOAT},
+		{SemanticType: sqlbase.ColumnType_ARRAY, ArrayContents: &stringArrayContents},
+	}
+	d, err := tree.ParseDDecimal(`-1.25`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := tree.ParseDJSON(`{"a": [1, null]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := tree.NewDArray(types.String)
+	for _, e := range []tree.Datum{tree.NewDString(`x`), tree.DNull} {
+		if err := arr.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts := time.Date(2018, 12, 1, 2, 3, 4, 5000, time.UTC)
+	rows := []tree.Datums{
+		{
+			tree.NewDInt(1), tree.NewDString(`a`), d, tree.MakeDTimestamp(ts, time.Microsecond),
+			tree.NewDDate(17866), tree.NewDUuid(tree.DUuid{UUID: uuid.MakeV4()}), j,
+			tree.DBoolTrue, tree.NewDFloat(1.5), arr,
+		},
+		{
+			tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+			tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+		},
+		{
+			tree.NewDInt(-3), tree.NewDString(``), &tree.DDecimal{}, tree.MakeDTimestamp(ts, time.Microsecond),
+			tree.NewDDate(0), tree.NewDUuid(tree.DUuid{UUID: uuid.MakeV4()}), j,
+			tree.DBoolFalse, tree.NewDFloat(-2), tree.NewDArray(types.String),
+		},
+	}
+
+	file, err := encodeParquet(names, colTypes, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := openParquetFile(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.rowGroups) != 1 {
+		t.Fatalf("expected 1 row group, got %d", len(f.rowGroups))
+	}
+	for i, name := range names {
+		c, err := f.column(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, err := f.readColumnChunk(f.rowGroups[0], c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != len(rows) {
+			t.Fatalf("%s: expected %d values, got %d", name, len(rows), len(values))
+		}
+		for r, row := range rows {
+			datum, err := nativeValueToDatum(values[r], colTypes[i].ToDatumType(), testEvalCtx)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if datum.Compare(testEvalCtx, row[i]) != 0 {
+				t.Errorf("%s row %d: expected %s, got %s", name, r, row[i], datum)
+			}
+		}
+	}
+
+	if c, err := f.column(`missing`); err != nil || c != nil {
+		t.Errorf("expected no column, got %v, %v", c, err)
+	}
+	truncated := file[:len(file)-1]
+	if _, err := openParquetFile(bytes.NewReader(truncated), int64(len(truncated))); !testutils.IsError(err, `not a parquet file`) {
+		t.Errorf("expected not a parquet file, got %v", err)
+	}
+
+	// Input that cannot be read at any offset is copied aside first.
+	r, size, cleanup, err := parquetReaderAt(ioutil.NopCloser(bytes.NewReader(file)), func(bool) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	if size != int64(len(file)) {
+		t.Fatalf("expected size %d, got %d", len(file), size)
+	}
+	if f, err := openParquetFile(r, size); err != nil {
+		t.Fatal(err)
+	} else if len(f.rowGroups) != 1 {
+		t.Fatalf("expected 1 row group, got %d", len(f.rowGroups))
+	}
+}
+
+func TestDecodeParquetHybrid(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The example from the Parquet encoding documentation: the values 0 to 7
+	// bit-packed with a width of 3, followed by an RLE run of four 5s.
+	encoded := []byte{1<<1 | 1, 0x88, 0xc6, 0xfa, 4 << 1, 5}
+	levels, err := decodeParquetHybrid(encoded, 3, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int32{0, 1, 2, 3, 4, 5, 6, 7, 5, 5, 5, 5}; !reflect.DeepEqual(expected, levels) {
+		t.Errorf("expected %v, got %v", expected, levels)
+	}
+	// Bit-packed runs are padded to a multiple of 8 values, which are ignored.
+	if levels, err = decodeParquetHybrid(encoded, 3, 2); err != nil {
+		t.Fatal(err)
+	} else if expected := []int32{0, 1}; !reflect.DeepEqual(expected, levels) {
+		t.Errorf("expected %v, got %v", expected, levels)
+	}
+	if _, err := decodeParquetHybrid(encoded[:2], 3, 12); !testutils.IsError(err, `unexpected end`) {
+		t.Errorf("expected unexpected end, got %v", err)
+	}
+	// A bit-packed run whose byte length overflows to 32 when multiplied by
+	// the width.
+	overflow := make([]byte, binary.MaxVarintLen64+32)
+	n := binary.PutUvarint(overflow, (1<<59+1)<<1|1)
+	if _, err := decodeParquetHybrid(overflow[:n+32], 32, 100); !testutils.IsError(err, `unexpected end`) {
+		t.Errorf("expected unexpected end, got %v", err)
+	}
+	if _, err := decodeParquetHybrid(encoded, 3, -1); !testutils.IsError(err, `invalid value count`) {
+		t.Errorf("expected invalid value count, got %v", err)
+	}
+}
+
+// TestParquetDictionaryPage reads a column chunk written the way most other
+// writers do: a dictionary page followed by a snappy compressed, dictionary
+// encoded version 2 data page.
+func TestParquetDictionaryPage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	file := parquetDictionaryTestFile(t, -1 /* defLen */, 0 /* repLen */)
+	f, err := openParquetFile(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := f.column("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := f.readColumnChunk(f.rowGroups[0], c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []interface{}{int64(20), nil, int64(10), int64(20)}; !reflect.DeepEqual(expected, values) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	// Level lengths whose sum overflows an int32 are rejected.
+	file = parquetDictionaryTestFile(t, math.MaxInt32, math.MaxInt32)
+	if f, err = openParquetFile(bytes.NewReader(file), int64(len(file))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.readColumnChunk(f.rowGroups[0], c); !testutils.IsError(err, `invalid level lengths`) {
+		t.Errorf("expected invalid level lengths, got %v", err)
+	}
+}
+
+// parquetDictionaryTestFile returns a file with a column chunk written the
+// way most other writers do. Its data page header claims the given level
+// lengths, or the actual length of its definition levels if defLen is
+// negative.
+func parquetDictionaryTestFile(t *testing.T, defLen, repLen int32) []byte {
+	t.Helper()
+
+	var dictValues bytes.Buffer
+	for _, v := range []int64{10, 20} {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(v))
+		dictValues.Write(b[:])
+	}
+	dictPage := snappy.Encode(nil, dictValues.Bytes())
+	// Definition levels 1, 0, 1, 1 bit-packed with a width of 1.
+	defLevels := []byte{1<<1 | 1, 0x0d}
+	// Dictionary indexes 1, 0, 1 bit-packed with a width of 1.
+	dataValues := snappy.Encode(nil, []byte{1, 1<<1 | 1, 0x05})
+
+	dictHeader, err := parquetThrift(func(w *parquetThriftWriter) {
+		w.i32(1, parquetPageTypeDictionary)
+		w.i32(2, int32(dictValues.Len()))
+		w.i32(3, int32(len(dictPage)))
+		w.structField(7, func() {
+			w.i32(1, 2)
+			w.i32(2, parquetEncodingPlain)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defLen < 0 {
+		defLen = int32(len(defLevels))
+	}
+	dataHeader, err := parquetThrift(func(w *parquetThriftWriter) {
+		w.i32(1, parquetPageTypeDataV2)
+		w.i32(2, int32(len(defLevels)+3))
+		w.i32(3, int32(len(defLevels)+len(dataValues)))
+		w.structField(8, func() {
+			w.i32(1, 4)
+			w.i32(2, 1)
+			w.i32(3, 4)
+			w.i32(4, parquetEncodingRLEDictionary)
+			w.i32(5, defLen)
+			w.i32(6, repLen)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+	dictOffset := int64(file.Len())
+	file.Write(dictHeader)
+	file.Write(dictPage)
+	dataOffset := int64(file.Len())
+	file.Write(dataHeader)
+	file.Write(defLevels)
+	file.Write(dataValues)
+	chunkSize := int64(file.Len()) - dictOffset
+
+	footer, err := parquetThrift(func(w *parquetThriftWriter) {
+		w.i32(1, 1)
+		w.listField(2, thrift.STRUCT, 2, func() {
+			w.structElem(func() {
+				w.str(4, "schema")
+				w.i32(5, 1)
+			})
+			w.structElem(func() {
+				w.i32(1, parquetTypeInt64)
+				w.i32(3, parquetRepetitionOptional)
+				w.str(4, "x")
+			})
+		})
+		w.i64(3, 4)
+		w.listField(4, thrift.STRUCT, 1, func() {
+			w.structElem(func() {
+				w.listField(1, thrift.STRUCT, 1, func() {
+					w.structElem(func() {
+						w.i64(2, dataOffset)
+						w.structField(3, func() {
+							w.i32(1, parquetTypeInt64)
+							w.listField(3, thrift.STRING, 1, func() {
+								w.do(func() error { return w.p.WriteString("x") })
+							})
+							w.i32(4, parquetCodecSnappy)
+							w.i64(5, 4)
+							w.i64(7, chunkSize)
+							w.i64(9, dataOffset)
+							w.i64(11, dictOffset)
+						})
+					})
+				})
+				w.i64(3, 4)
+			})
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	file.Write(length[:])
+	file.WriteString(parquetMagic)
+	return file.Bytes()
+}
@@ -39,9 +39,11 @@ func newPgCopyReader(
 	kvCh chan kvBatch,
 	opts roachpb.PgCopyOptions,
 	tableDesc *sqlbase.TableDescriptor,
+	targetCols []string,
+	maxRowID int64,
 	evalCtx *tree.EvalContext,
 ) (*pgCopyReader, error) {
-	conv, err := newRowConverter(tableDesc, evalCtx, kvCh)
+	conv, err := newRowConverter(tableDesc, targetCols, maxRowID, evalCtx, kvCh)
 	if err != nil {
 		return nil, err
 	}
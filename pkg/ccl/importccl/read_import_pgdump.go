@@ -401,7 +401,7 @@ func newPgDumpReader(
 	converters := make(map[string]*rowConverter, len(descs))
 	for name, desc := range descs {
 		if desc.IsTable() {
-			conv, err := newRowConverter(desc, evalCtx, kvCh)
+			conv, err := newRowConverter(desc, nil /* targetCols */, 0 /* maxRowID */, evalCtx, kvCh)
 			if err != nil {
 				return nil, err
 			}
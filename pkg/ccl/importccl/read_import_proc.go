@@ -12,13 +12,20 @@ import (
 	"compress/bzip2"
 	"compress/gzip"
 	"context"
+	gojson "encoding/json"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"math/rand"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/cockroachdb/apd"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -33,8 +40,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -111,6 +124,15 @@ func readInputFiles(
 				return err
 			}
 			defer src.Close()
+			var input io.Reader = src
+			// Uncompressed files that can be read at any offset, like local ones,
+			// are passed on as such for the formats that read them out of order.
+			if ra, ok := f.(io.ReaderAt); ok &&
+				guessCompressionFromName(dataFile, format.Compression) == roachpb.IOFileFormat_None {
+				if sz, err := es.Size(ctx, ""); err == nil {
+					input = &byteCounterAt{byteCounter: bc, ra: ra, size: sz}
+				}
+			}
 
 			wrappedProgressFn := func(finished bool) error { return nil }
 			if updateFromBytes {
@@ -130,7 +152,7 @@ func readInputFiles(
 				}
 			}
 
-			if err := fileFunc(ctx, src, dataFileIndex, dataFile, wrappedProgressFn); err != nil {
+			if err := fileFunc(ctx, input, dataFileIndex, dataFile, wrappedProgressFn); err != nil {
 				return errors.Wrap(err, dataFile)
 			}
 			if updateFromFiles {
@@ -186,6 +208,25 @@ func (b *byteCounter) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// byteCounterAt is a byteCounter of a file of the given size that can also be
+// read at any offset.
+type byteCounterAt struct {
+	*byteCounter
+	ra   io.ReaderAt
+	size int64
+}
+
+func (b *byteCounterAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := b.ra.ReadAt(p, off)
+	b.n += int64(n)
+	return n, err
+}
+
+// Size returns the size of the file.
+func (b *byteCounterAt) Size() int64 {
+	return b.size
+}
+
 type kvBatch []roachpb.KeyValue
 
 type rowConverter struct {
@@ -201,6 +242,7 @@ type rowConverter struct {
 
 	// The rest of these are derived from tableDesc, just cached here.
 	hidden                int
+	maxRowID              int64
 	ri                    row.Inserter
 	evalCtx               *tree.EvalContext
 	cols                  []sqlbase.ColumnDescriptor
@@ -212,18 +254,62 @@ type rowConverter struct {
 
 const kvBatchSize = 1000
 
+// newRowConverter returns a converter for the rows of tableDesc. If
+// targetCols is non-empty, the input rows contain only the named columns, in
+// that order, and the table's other columns are filled with their defaults.
+// maxRowID, if non-zero, is the largest row ID that may be generated for an
+// IMPORT INTO an existing table; see importIntoMaxRowID.
 func newRowConverter(
-	tableDesc *sqlbase.TableDescriptor, evalCtx *tree.EvalContext, kvCh chan<- kvBatch,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols []string,
+	maxRowID int64,
+	evalCtx *tree.EvalContext,
+	kvCh chan<- kvBatch,
 ) (*rowConverter, error) {
 	immutDesc := sqlbase.NewImmutableTableDescriptor(*tableDesc)
 	c := &rowConverter{
 		tableDesc: immutDesc,
 		kvCh:      kvCh,
 		evalCtx:   evalCtx,
+		maxRowID:  maxRowID,
+	}
+
+	c.visibleCols = immutDesc.VisibleColumns()
+	if len(targetCols) > 0 {
+		c.visibleCols = make([]sqlbase.ColumnDescriptor, len(targetCols))
+		for i, name := range targetCols {
+			col, dropped, err := immutDesc.FindColumnByName(tree.Name(name))
+			if err != nil {
+				return nil, err
+			}
+			if dropped {
+				return nil, errors.Errorf("column %q is being dropped", name)
+			}
+			c.visibleCols[i] = col
+		}
+	}
+	c.visibleColTypes = make([]types.T, len(c.visibleCols))
+	for i := range c.visibleCols {
+		c.visibleColTypes[i] = c.visibleCols[i].DatumType()
+	}
+
+	// The columns read from the input come first, followed by any others.
+	insertCols := append([]sqlbase.ColumnDescriptor(nil), c.visibleCols...)
+	for _, col := range immutDesc.Columns {
+		found := false
+		for _, visible := range c.visibleCols {
+			if visible.ID == col.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			insertCols = append(insertCols, col)
+		}
 	}
 
 	ri, err := row.MakeInserter(nil /* txn */, immutDesc, nil, /* fkTables */
-		immutDesc.Columns, false /* checkFKs */, &sqlbase.DatumAlloc{})
+		insertCols, false /* checkFKs */, &sqlbase.DatumAlloc{})
 	if err != nil {
 		return nil, errors.Wrap(err, "make row inserter")
 	}
@@ -231,32 +317,31 @@ func newRowConverter(
 
 	var txCtx transform.ExprTransformContext
 	// Although we don't yet support DEFAULT expressions on visible columns,
-	// we do on hidden columns (which is only the default _rowid one). This
-	// allows those expressions to run.
-	cols, defaultExprs, err := sqlbase.ProcessDefaultColumns(immutDesc.Columns, immutDesc, &txCtx, c.evalCtx)
+	// we do on hidden columns (which is only the default _rowid one) and on
+	// columns omitted from the target columns. This allows those expressions
+	// to run.
+	cols, defaultExprs, err := sqlbase.ProcessDefaultColumns(insertCols, immutDesc, &txCtx, c.evalCtx)
 	if err != nil {
 		return nil, errors.Wrap(err, "process default columns")
 	}
 	c.cols = cols
 	c.defaultExprs = defaultExprs
 
-	c.visibleCols = immutDesc.VisibleColumns()
-	c.visibleColTypes = make([]types.T, len(c.visibleCols))
-	for i := range c.visibleCols {
-		c.visibleColTypes[i] = c.visibleCols[i].DatumType()
-	}
 	c.datums = make([]tree.Datum, len(c.visibleCols), len(cols))
 
 	// Check for a hidden column. This should be the unique_rowid PK if present.
+	// Any other columns not read from the input are omitted target columns.
 	c.hidden = -1
-	for i, col := range cols {
-		if col.Hidden {
+	for i := len(c.visibleCols); i < len(cols); i++ {
+		if col := cols[i]; col.Hidden {
 			if col.DefaultExpr == nil || *col.DefaultExpr != "unique_rowid()" || c.hidden != -1 {
 				return nil, errors.New("unexpected hidden column")
 			}
 			c.hidden = i
-			c.datums = append(c.datums, nil)
+		} else if len(targetCols) == 0 {
+			return nil, errors.New("unexpected hidden column")
 		}
+		c.datums = append(c.datums, nil)
 	}
 	if len(c.datums) != len(cols) {
 		return nil, errors.New("unexpected hidden column")
@@ -288,7 +373,32 @@ func (c *rowConverter) row(ctx context.Context, fileIndex int32, rowIndex int64)
 		// number in the node id portion. The 15 bits in that portion should account
 		// for up to 32k CSV files in a single IMPORT. In the case of > 32k files,
 		// the data is xor'd so the final bits are flipped instead of set.
-		c.datums[c.hidden] = tree.NewDInt(builtins.GenerateUniqueID(fileIndex, uint64(rowIndex)))
+		//
+		// When importing into an existing table, the table may already hold
+		// rows with such IDs, as well as IDs produced by unique_rowid, which
+		// will keep producing more of them once the IMPORT is done. Instead, the
+		// line numbers count down from below maxRowID, which is negative and
+		// smaller than all of the table's existing IDs. unique_rowid never
+		// returns negative numbers, so neither can collide with them.
+		timestamp := uint64(rowIndex)
+		if c.maxRowID != 0 {
+			timestamp = uint64(c.maxRowID>>builtins.NodeIDBits - 1 - rowIndex)
+		}
+		c.datums[c.hidden] = tree.NewDInt(builtins.GenerateUniqueID(fileIndex, timestamp))
+	}
+	for i := len(c.visibleCols); i < len(c.datums); i++ {
+		if i == c.hidden {
+			continue
+		}
+		if c.defaultExprs == nil {
+			c.datums[i] = tree.DNull
+			continue
+		}
+		d, err := c.defaultExprs[i].Eval(c.evalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "default value for column %s", c.cols[i].Name)
+		}
+		c.datums[i] = d
 	}
 
 	// TODO(justin): we currently disallow computed columns in import statements.
@@ -335,6 +445,182 @@ func (c *rowConverter) sendBatch(ctx context.Context) error {
 	return nil
 }
 
+// nativeValueToDatum converts a value decoded from one of the
+// self-describing import formats (Avro, Parquet and JSON) into a datum of the
+// desired type. The values are those produced by the decoders of those formats:
+// nil, bool, int32, int64, float32, float64, json.Number, string, []byte,
+// time.Time, time.Duration, *big.Rat, []interface{} and, for JSONB only,
+// map[string]interface{}. Values without a more direct conversion are
+// formatted as strings and parsed as they would be in a CSV file.
+func nativeValueToDatum(
+	v interface{}, desired types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	if v == nil {
+		return tree.DNull, nil
+	}
+	if t, ok := desired.(types.TArray); ok {
+		if elems, ok := v.([]interface{}); ok {
+			arr := tree.NewDArray(t.Typ)
+			for _, e := range elems {
+				d, err := nativeValueToDatum(e, t.Typ, evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				if err := arr.Append(d); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}
+	}
+	if desired == types.JSON {
+		switch v := v.(type) {
+		case string:
+			// JSON is exported as its string encoding by the formats that have no
+			// JSON type.
+			return tree.ParseDJSON(v)
+		case []byte:
+			return tree.ParseDJSON(string(v))
+		}
+		j, err := json.MakeJSON(nativeValueToJSON(v))
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDJSON(j), nil
+	}
+
+	var s string
+	switch v := v.(type) {
+	case bool:
+		if desired == types.Bool {
+			return tree.MakeDBool(tree.DBool(v)), nil
+		}
+		s = strconv.FormatBool(v)
+	case int32:
+		return nativeValueToDatum(int64(v), desired, evalCtx)
+	case int64:
+		switch desired {
+		case types.Int:
+			return tree.NewDInt(tree.DInt(v)), nil
+		case types.Decimal:
+			return &tree.DDecimal{Decimal: *apd.New(v, 0)}, nil
+		}
+		s = strconv.FormatInt(v, 10)
+	case float32:
+		// Use the shortest representation of the 32-bit value, instead of the
+		// float64 it would be widened to.
+		s = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		if desired == types.Float {
+			return tree.NewDFloat(tree.DFloat(v)), nil
+		}
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	case gojson.Number:
+		s = string(v)
+	case string:
+		s = v
+	case []byte:
+		switch desired {
+		case types.Bytes:
+			return tree.NewDBytes(tree.DBytes(v)), nil
+		case types.UUID:
+			if len(v) == uuid.Size {
+				u, err := uuid.FromBytes(v)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDUuid(tree.DUuid{UUID: u}), nil
+			}
+		}
+		s = string(v)
+	case time.Time:
+		switch desired {
+		case types.Timestamp:
+			return tree.MakeDTimestamp(v, time.Microsecond), nil
+		case types.TimestampTZ:
+			return tree.MakeDTimestampTZ(v, time.Microsecond), nil
+		case types.Date:
+			return tree.NewDDateFromTime(v, time.UTC), nil
+		}
+		s = v.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		switch desired {
+		case types.Interval:
+			return &tree.DInterval{Duration: duration.Duration{Nanos: v.Nanoseconds()}}, nil
+		case types.Time:
+			return tree.MakeDTime(timeofday.FromInt(int64(v / time.Microsecond))), nil
+		}
+		s = v.String()
+	case *big.Rat:
+		dec, err := ratToDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		if desired == types.Decimal {
+			return &tree.DDecimal{Decimal: *dec}, nil
+		}
+		s = dec.String()
+	default:
+		return nil, errors.Errorf("cannot convert %T to %s", v, desired)
+	}
+	return tree.ParseDatumStringAs(desired, s, evalCtx)
+}
+
+// nativeValueToJSON converts the values accepted by nativeValueToDatum into
+// those accepted by json.MakeJSON.
+func nativeValueToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int32:
+		return int64(v)
+	case float32:
+		return gojson.Number(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case *big.Rat:
+		if dec, err := ratToDecimal(v); err == nil {
+			return gojson.Number(dec.String())
+		}
+		return v.FloatString(int(tree.DecimalCtx.Precision))
+	case []interface{}:
+		elems := make([]interface{}, len(v))
+		for i, e := range v {
+			elems[i] = nativeValueToJSON(e)
+		}
+		return elems
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = nativeValueToJSON(e)
+		}
+		return m
+	}
+	return v
+}
+
+// ratToDecimal returns the decimal exactly equal to r, which is how decimals
+// are represented by the Avro library. An error is returned if r has no finite
+// decimal representation.
+func ratToDecimal(r *big.Rat) (*apd.Decimal, error) {
+	denom := r.Denom()
+	pow := big.NewInt(1)
+	ten := big.NewInt(10)
+	// If the denominator divides a power of ten, it is 2^a * 5^b and the
+	// smallest such power is 10^max(a, b), which is bounded by its bit length.
+	for scale := 0; scale <= denom.BitLen(); scale++ {
+		if new(big.Int).Mod(pow, denom).Sign() == 0 {
+			coeff := new(big.Int).Mul(r.Num(), pow)
+			coeff.Quo(coeff, denom)
+			return apd.NewWithBigInt(coeff, int32(-scale)), nil
+		}
+		pow.Mul(pow, ten)
+	}
+	return nil, errors.Errorf("%s has no exact decimal representation", r.RatString())
+}
+
 var csvOutputTypes = []sqlbase.ColumnType{
 	{SemanticType: sqlbase.ColumnType_BYTES},
 	{SemanticType: sqlbase.ColumnType_BYTES},
@@ -390,6 +676,50 @@ func isMultiTableFormat(format roachpb.IOFileFormat_FileFormat) bool {
 	return false
 }
 
+// importIntoMaxRowID returns the largest row ID that an IMPORT INTO tableDesc
+// at walltime may generate for its hidden rowid column: one less than the
+// smallest of zero and the table's existing row IDs. The table is offline
+// during the IMPORT, so its rows as of just before walltime are all it has,
+// and every processor of the IMPORT, including those of a resumed job,
+// computes the same value. Zero is returned if the table's primary key is not
+// a hidden rowid column, since no row IDs are generated for it then.
+func importIntoMaxRowID(
+	ctx context.Context, db *client.DB, tableDesc *sqlbase.TableDescriptor, walltime int64,
+) (int64, error) {
+	if len(tableDesc.PrimaryIndex.ColumnIDs) != 1 {
+		return 0, nil
+	}
+	col, err := tableDesc.FindColumnByID(tableDesc.PrimaryIndex.ColumnIDs[0])
+	if err != nil {
+		return 0, err
+	}
+	if !col.Hidden {
+		return 0, nil
+	}
+
+	prefix := roachpb.Key(sqlbase.MakeIndexKeyPrefix(tableDesc, tableDesc.PrimaryIndex.ID))
+	var kvs []client.KeyValue
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, hlc.Timestamp{WallTime: walltime}.Prev())
+		var err error
+		kvs, err = txn.Scan(ctx, prefix, prefix.PrefixEnd(), 1 /* maxRows */)
+		return err
+	}); err != nil {
+		return 0, errors.Wrapf(err, "finding smallest row ID of %s", tableDesc.Name)
+	}
+	var minRowID int64
+	if len(kvs) > 0 {
+		_, rowID, err := encoding.DecodeVarintAscending(kvs[0].Key[len(prefix):])
+		if err != nil {
+			return 0, errors.Wrapf(err, "decoding row ID of %s", kvs[0].Key)
+		}
+		if rowID < minRowID {
+			minRowID = rowID
+		}
+	}
+	return minRowID - 1, nil
+}
+
 func (cp *readImportDataProcessor) Run(ctx context.Context, wg *sync.WaitGroup) {
 	ctx, span := tracing.ChildSpan(ctx, "readImportDataProcessor")
 	defer tracing.FinishSpan(span)
@@ -424,19 +754,37 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context, wg *sync.WaitGroup
 		return errors.Errorf("%s only supports reading a single, pre-specified table", format.String())
 	}
 
+	// Target columns and the walltime only apply to IMPORT INTO a single,
+	// existing table.
+	targetCols := cp.spec.TargetCols
+	var maxRowID int64
+	if cp.spec.WalltimeNanos != 0 {
+		var err error
+		maxRowID, err = importIntoMaxRowID(ctx, cp.flowCtx.ClientDB, singleTable, cp.spec.WalltimeNanos)
+		if err != nil {
+			return err
+		}
+	}
+
 	var conv inputConverter
 	var err error
 	switch cp.spec.Format.Format {
 	case roachpb.IOFileFormat_CSV:
-		conv = newCSVInputReader(kvCh, cp.spec.Format.Csv, singleTable, cp.flowCtx)
+		conv = newCSVInputReader(kvCh, cp.spec.Format.Csv, singleTable, targetCols, maxRowID, cp.flowCtx)
 	case roachpb.IOFileFormat_MysqlOutfile:
-		conv, err = newMysqloutfileReader(kvCh, cp.spec.Format.MysqlOut, singleTable, evalCtx)
+		conv, err = newMysqloutfileReader(kvCh, cp.spec.Format.MysqlOut, singleTable, targetCols, maxRowID, evalCtx)
 	case roachpb.IOFileFormat_Mysqldump:
 		conv, err = newMysqldumpReader(kvCh, cp.spec.Tables, evalCtx)
 	case roachpb.IOFileFormat_PgCopy:
-		conv, err = newPgCopyReader(kvCh, cp.spec.Format.PgCopy, singleTable, evalCtx)
+		conv, err = newPgCopyReader(kvCh, cp.spec.Format.PgCopy, singleTable, targetCols, maxRowID, evalCtx)
 	case roachpb.IOFileFormat_PgDump:
 		conv, err = newPgDumpReader(kvCh, cp.spec.Format.PgDump, cp.spec.Tables, evalCtx)
+	case roachpb.IOFileFormat_Avro:
+		conv, err = newAvroOCFReader(kvCh, singleTable, targetCols, maxRowID, evalCtx)
+	case roachpb.IOFileFormat_Parquet:
+		conv, err = newParquetReader(kvCh, singleTable, targetCols, maxRowID, evalCtx)
+	case roachpb.IOFileFormat_NDJSON:
+		conv, err = newNDJSONReader(kvCh, cp.spec.Format.Ndjson, singleTable, targetCols, maxRowID, evalCtx)
 	default:
 		err = errors.Errorf("Requested IMPORT format (%d) not supported by this node", cp.spec.Format.Format)
 	}
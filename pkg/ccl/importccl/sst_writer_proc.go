@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
 var sstOutputTypes = []sqlbase.ColumnType{
@@ -106,6 +107,17 @@ func (sp *sstWriter) Run(ctx context.Context, wg *sync.WaitGroup) {
 		}
 		samples := job.Details().(jobspb.ImportDetails).Samples
 
+		// Each writer is allowed the share of the job's max_bytes_per_sec, set
+		// with ALTER JOB, that its spans are of all those of the job.
+		var limiter *rate.Limiter
+		if bytesPerSec := job.Payload().ResourceLimits.BytesPerSec(); bytesPerSec > 0 {
+			share := int64(float64(bytesPerSec) * float64(sp.progress.Contribution))
+			if share < 1 {
+				share = 1
+			}
+			limiter = bulk.NewBytesLimiter(share)
+		}
+
 		// Sort incoming KVs, which will be from multiple spans, into a single
 		// RocksDB instance.
 		types := sp.input.OutputTypes()
@@ -177,6 +189,9 @@ func (sp *sstWriter) Run(ctx context.Context, wg *sync.WaitGroup) {
 						end = sst.span.EndKey
 					}
 
+					if err := bulk.WaitForBytes(ctx, limiter, int64(len(sst.data))); err != nil {
+						return err
+					}
 					if sp.spec.Destination == "" {
 						if err := sp.db.AdminSplit(ctx, end, end); err != nil {
 							return err
@@ -194,7 +209,7 @@ func (sp *sstWriter) Run(ctx context.Context, wg *sync.WaitGroup) {
 							// throughput.
 							log.Errorf(ctx, "failed to scatter span %s: %s", roachpb.PrettyPrintKey(nil, end), pErr)
 						}
-						if err := bulk.AddSSTable(ctx, sp.db, sst.span.Key, sst.span.EndKey, sst.data); err != nil {
+						if err := bulk.AddSSTable(ctx, sp.db, sst.span.Key, sst.span.EndKey, sst.data, sp.spec.DisallowShadowing); err != nil {
 							return err
 						}
 					} else {
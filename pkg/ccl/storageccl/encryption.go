@@ -0,0 +1,129 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// The following helpers are used to encrypt and decrypt files written to and
+// read from an ExportStorage, e.g. the SSTs and descriptors of an encrypted
+// BACKUP.
+//
+// An encrypted file consists of a cleartext preamble that identifies it as
+// such, a single version byte, a random nonce and finally the AES-256-GCM
+// sealed contents of the plaintext file, which includes its authentication
+// tag. The preamble and version byte are passed as additional authenticated
+// data, so any modification of them is detected on decryption.
+
+// encryptionPreamble is a constant string prepended in cleartext to files that
+// are encrypted, so they can be identified as such without a key.
+const encryptionPreamble = "encrypt"
+
+const encryptionVersionIVPrefix = 1
+
+const nonceSize = 12 // GCM standard nonce size.
+const headerSize = len(encryptionPreamble) + 1 + nonceSize
+
+// EncryptionKeySize is the size, in bytes, of the keys used to encrypt files.
+// Keys of this size select AES-256.
+const EncryptionKeySize = 32
+
+// encryptionSaltSize is the size, in bytes, of the random salt passed to the
+// key derivation function.
+const encryptionSaltSize = 16
+
+// kdfIterations is the number of PBKDF2 rounds used to derive a key from a
+// passphrase, chosen to make brute-forcing a passphrase expensive while
+// keeping a single derivation well under a second.
+const kdfIterations = 64000
+
+// GenerateSalt returns a new random salt for use with GenerateKey.
+func GenerateSalt() ([]byte, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// GenerateKey derives an encryption key from a user-supplied passphrase and a
+// salt using PBKDF2-SHA256.
+func GenerateKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, kdfIterations, EncryptionKeySize, sha256.New)
+}
+
+// AppearsEncrypted checks if passed bytes begin with the encryption preamble.
+func AppearsEncrypted(text []byte) bool {
+	return bytes.HasPrefix(text, []byte(encryptionPreamble))
+}
+
+// EncryptFile encrypts a file with the supplied key and a randomly chosen
+// nonce, returning the encrypted file including its cleartext header.
+func EncryptFile(plaintext, key []byte) ([]byte, error) {
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize, headerSize+len(plaintext)+gcm.Overhead())
+	copy(header, encryptionPreamble)
+	header[len(encryptionPreamble)] = encryptionVersionIVPrefix
+	nonce := header[len(encryptionPreamble)+1:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	// Seal appends the ciphertext to header, authenticating the header (minus
+	// the nonce, which is authenticated implicitly) as additional data.
+	return gcm.Seal(header, nonce, plaintext, header[:len(encryptionPreamble)+1]), nil
+}
+
+// DecryptFile decrypts a file encrypted by EncryptFile, using the supplied key.
+func DecryptFile(ciphertext, key []byte) ([]byte, error) {
+	if !AppearsEncrypted(ciphertext) {
+		return nil, errors.New("file does not appear to be encrypted")
+	}
+	if len(ciphertext) < headerSize {
+		return nil, errors.New("invalid encryption header")
+	}
+	version := ciphertext[len(encryptionPreamble)]
+	if version != encryptionVersionIVPrefix {
+		return nil, errors.Errorf("unexpected encryption scheme/config version %d", version)
+	}
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := ciphertext[len(encryptionPreamble)+1 : headerSize]
+	plaintext, err := gcm.Open(
+		nil, nonce, ciphertext[headerSize:], ciphertext[:len(encryptionPreamble)+1],
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt -- perhaps you are using the wrong key")
+	}
+	return plaintext, nil
+}
+
+func aesgcm(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, errors.Errorf("invalid encryption key length %d, expected %d", len(key), EncryptionKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
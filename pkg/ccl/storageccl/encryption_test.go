@@ -0,0 +1,84 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := GenerateSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := GenerateKey([]byte("hunter2"), salt)
+	if len(key) != EncryptionKeySize {
+		t.Fatalf("expected %d byte key, got %d", EncryptionKeySize, len(key))
+	}
+	otherKey := GenerateKey([]byte("hunter3"), salt)
+
+	rng, _ := randutil.NewPseudoRand()
+	for _, size := range []int{0, 1, 12, 100, 1 << 16} {
+		plaintext := randutil.RandBytes(rng, size)
+
+		ciphertext, err := EncryptFile(plaintext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !AppearsEncrypted(ciphertext) {
+			t.Fatalf("%d: expected ciphertext to appear encrypted", size)
+		}
+		if size > 0 && bytes.Contains(ciphertext, plaintext) {
+			t.Fatalf("%d: ciphertext contains plaintext", size)
+		}
+
+		// Encrypting the same plaintext twice should use a different nonce.
+		if again, err := EncryptFile(plaintext, key); err != nil {
+			t.Fatal(err)
+		} else if bytes.Equal(again, ciphertext) {
+			t.Fatalf("%d: expected distinct ciphertexts", size)
+		}
+
+		decrypted, err := DecryptFile(ciphertext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("%d: decrypted contents do not match plaintext", size)
+		}
+
+		if _, err := DecryptFile(ciphertext, otherKey); !testutils.IsError(err, "wrong key") {
+			t.Fatalf("%d: expected wrong key error, got %v", size, err)
+		}
+
+		// Flipping any bit, including in the header, should cause decryption to
+		// fail.
+		for _, i := range []int{len(encryptionPreamble), headerSize - 1, len(ciphertext) - 1} {
+			corrupt := append([]byte(nil), ciphertext...)
+			corrupt[i] ^= 1
+			if _, err := DecryptFile(corrupt, key); err == nil {
+				t.Fatalf("%d: expected error decrypting file corrupted at byte %d", size, i)
+			}
+		}
+	}
+
+	if _, err := DecryptFile([]byte("plaintext"), key); !testutils.IsError(err, "does not appear to be encrypted") {
+		t.Fatalf("expected unencrypted file error, got %v", err)
+	}
+	if _, err := EncryptFile([]byte("plaintext"), key[:16]); !testutils.IsError(err, "invalid encryption key length") {
+		t.Fatalf("expected key length error, got %v", err)
+	}
+}
@@ -39,6 +39,41 @@ func declareKeysExport(
 	spans.Add(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeLastGCKey(header.RangeID)})
 }
 
+// matchingLocalityKV returns the most specific tier of locality that has an
+// entry in storageByLocalityKV, or "" if none does.
+func matchingLocalityKV(
+	locality roachpb.Locality, storageByLocalityKV map[string]*roachpb.ExportStorage,
+) string {
+	for i := len(locality.Tiers) - 1; i >= 0; i-- {
+		if _, ok := storageByLocalityKV[locality.Tiers[i].String()]; ok {
+			return locality.Tiers[i].String()
+		}
+	}
+	return ""
+}
+
+// exportLocalityKV returns the entry of storageByLocalityKV that an export
+// evaluated on this replica is written to, or "" to write it to the default
+// storage. That is the most specific tier of this node's locality with an
+// entry, which keeps data in the region it lives in. If this node's locality
+// has none, e.g. because the lease moved out of the range's region, the
+// destination is chosen from the localities of the range's other replicas
+// instead, so that the range's data doesn't end up in the default storage.
+func exportLocalityKV(
+	evalCtx batcheval.EvalContext, storageByLocalityKV map[string]*roachpb.ExportStorage,
+) string {
+	if localityKV := matchingLocalityKV(evalCtx.NodeLocality(), storageByLocalityKV); localityKV != "" {
+		return localityKV
+	}
+	for _, repl := range evalCtx.Desc().Replicas {
+		locality := evalCtx.GetNodeLocality(repl.NodeID)
+		if localityKV := matchingLocalityKV(locality, storageByLocalityKV); localityKV != "" {
+			return localityKV
+		}
+	}
+	return ""
+}
+
 // evalExport dumps the requested keys into files of non-overlapping key ranges
 // in a format suitable for bulk ingest.
 func evalExport(
@@ -82,9 +117,16 @@ func evalExport(
 	}
 
 	var exportStore ExportStorage
+	var localityKV string
 	if makeExportStorage {
+		conf := args.Storage
+		if len(args.StorageByLocalityKV) > 0 {
+			if localityKV = exportLocalityKV(cArgs.EvalCtx, args.StorageByLocalityKV); localityKV != "" {
+				conf = *args.StorageByLocalityKV[localityKV]
+			}
+		}
 		var err error
-		exportStore, err = MakeExportStorage(ctx, args.Storage, cArgs.EvalCtx.ClusterSettings())
+		exportStore, err = MakeExportStorage(ctx, conf, cArgs.EvalCtx.ClusterSettings())
 		if err != nil {
 			return result.Result{}, err
 		}
@@ -182,7 +224,19 @@ func evalExport(
 
 	if exportStore != nil {
 		exported.Path = fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(cArgs.EvalCtx.NodeID()))
-		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(sstContents)); err != nil {
+		exported.LocalityKV = localityKV
+		data := sstContents
+		if args.Encryption != nil {
+			data, err = EncryptFile(sstContents, args.Encryption.Key)
+			if err != nil {
+				return result.Result{}, err
+			}
+		}
+		limiter := bulk.NewBytesLimiter(args.MaxBytesPerSec)
+		if err := bulk.WaitForBytes(ctx, limiter, int64(len(data))); err != nil {
+			return result.Result{}, err
+		}
+		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(data)); err != nil {
 			return result.Result{}, err
 		}
 	}
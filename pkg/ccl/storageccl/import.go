@@ -110,6 +110,13 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		dataSize := int64(len(fileContents))
 		log.Eventf(ctx, "fetched file (%s)", humanizeutil.IBytes(dataSize))
 
+		if args.Encryption != nil {
+			fileContents, err = DecryptFile(fileContents, args.Encryption.Key)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decrypting %q", file.Path)
+			}
+		}
+
 		if len(file.Sha512) > 0 {
 			checksum, err := SHA512ChecksumData(fileContents)
 			if err != nil {
@@ -129,7 +136,9 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		iters = append(iters, iter)
 	}
 
-	batcher, err := bulk.MakeSSTBatcher(ctx, db, MaxImportBatchSize(cArgs.EvalCtx.ClusterSettings()))
+	batcher, err := bulk.MakeSSTBatcher(
+		ctx, db, MaxImportBatchSize(cArgs.EvalCtx.ClusterSettings()), args.MaxBytesPerSec,
+	)
 	if err != nil {
 		return nil, err
 	}
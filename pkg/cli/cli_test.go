@@ -2334,6 +2334,7 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/18
   debug/nodes/1/ranges/19
   debug/nodes/1/ranges/20
+  debug/nodes/1/ranges/21
   debug/reports/problemranges
   debug/schema/defaultdb@details
   debug/schema/postgres@details
@@ -2347,6 +2348,7 @@ writing ` + os.DevNull + `
   debug/schema/system/namespace
   debug/schema/system/rangelog
   debug/schema/system/role_members
+  debug/schema/system/scheduled_jobs
   debug/schema/system/settings
   debug/schema/system/table_statistics
   debug/schema/system/ui
@@ -717,6 +717,14 @@ If specified, print the system config contents. Beware that the output will be
 long and not particularly human-readable.`,
 	}
 
+	RangeFeedCursor = FlagInfo{
+		Name: "cursor",
+		Description: `
+Exclusive timestamp after which changes are streamed, in the decimal format
+printed for resolved events (e.g. "1544121125.0000000000"). Defaults to the
+current time.`,
+	}
+
 	Decommission = FlagInfo{
 		Name: "decommission",
 		Description: `
@@ -102,6 +102,7 @@ func initCLIDefaults() {
 	debugCtx.inputFile = ""
 	debugCtx.printSystemConfig = false
 	debugCtx.maxResults = 1000
+	debugCtx.rangeFeedCursor = ""
 	debugCtx.ballastSize = base.SizeSpec{}
 
 	zoneCtx.zoneConfig = ""
@@ -252,6 +253,7 @@ var debugCtx struct {
 	ballastSize       base.SizeSpec
 	printSystemConfig bool
 	maxResults        int64
+	rangeFeedCursor   string
 }
 
 // zoneCtx captures the command-line parameters of the `zone` command.
@@ -0,0 +1,64 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRangeFeedEventJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := hlc.Timestamp{WallTime: 1544121120, Logical: 1}
+	val := roachpb.Value{Timestamp: ts}
+	val.SetString("v")
+
+	testCases := []struct {
+		resp     serverpb.RangeFeedResponse
+		expected string
+	}{
+		{
+			resp: serverpb.RangeFeedResponse{
+				Value: &roachpb.RangeFeedValue{Key: roachpb.Key("a"), Value: val},
+			},
+			expected: `{"key":"\"a\"","value":"AAAAAAN2","timestamp":"1544121120.0000000001"}`,
+		},
+		{
+			resp: serverpb.RangeFeedResponse{
+				Value: &roachpb.RangeFeedValue{Key: roachpb.Key("a"), Value: roachpb.Value{Timestamp: ts}},
+			},
+			expected: `{"key":"\"a\"","timestamp":"1544121120.0000000001"}`,
+		},
+		{
+			resp:     serverpb.RangeFeedResponse{Resolved: &ts},
+			expected: `{"resolved":"1544121120.0000000001"}`,
+		},
+	}
+	for _, tc := range testCases {
+		b, err := json.Marshal(makeRangeFeedEventJSON(&tc.resp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, b)
+		}
+	}
+}
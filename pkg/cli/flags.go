@@ -396,6 +396,7 @@ func init() {
 	clientCmds := []*cobra.Command{
 		debugGossipValuesCmd,
 		debugTimeSeriesDumpCmd,
+		debugRangeFeedCmd,
 		debugZipCmd,
 		dumpCmd,
 		genHAProxyCmd,
@@ -560,6 +561,12 @@ func init() {
 		StringFlag(f, &debugCtx.inputFile, cliflags.GossipInputFile, debugCtx.inputFile)
 		BoolFlag(f, &debugCtx.printSystemConfig, cliflags.PrintSystemConfig, debugCtx.printSystemConfig)
 	}
+	{
+		f := debugRangeFeedCmd.Flags()
+		VarFlag(f, (*mvccKey)(&debugCtx.startKey), cliflags.From)
+		VarFlag(f, (*mvccKey)(&debugCtx.endKey), cliflags.To)
+		StringFlag(f, &debugCtx.rangeFeedCursor, cliflags.RangeFeedCursor, debugCtx.rangeFeedCursor)
+	}
 	{
 		f := debugBallastCmd.Flags()
 		VarFlag(f, &debugCtx.ballastSize, cliflags.Size)
@@ -353,6 +353,13 @@ var specs = []stmtSpec{
 		},
 		unlink: []string{"table_name"},
 	},
+	{
+		name:    "alter_job",
+		stmt:    "alter_job_stmt",
+		inline:  []string{"kv_option_list", "kv_option"},
+		replace: map[string]string{"a_expr": "job_id"},
+		unlink:  []string{"job_id"},
+	},
 	{
 		name:    "alter_user_password_stmt",
 		replace: map[string]string{"string_or_placeholder 'WITH'": "name 'WITH'", "'PASSWORD' string_or_placeholder": "'PASSWORD' password"},
@@ -547,6 +554,12 @@ var specs = []stmtSpec{
 		replace: map[string]string{"name_list": "column_name"},
 		unlink:  []string{"statistics_name", "column_name"},
 	},
+	{
+		name:    "create_schedule_stmt",
+		inline:  []string{"opt_schedule_label", "opt_with_schedule_options", "kv_option_list", "kv_option"},
+		replace: map[string]string{"'RECURRING' string_or_placeholder": "'RECURRING' cron_expr"},
+		unlink:  []string{"cron_expr"},
+	},
 	{
 		name:   "create_table_as_stmt",
 		inline: []string{"opt_column_list", "name_list"},
@@ -652,6 +665,12 @@ var specs = []stmtSpec{
 		name:    "drop_role_stmt",
 		replace: map[string]string{"string_or_placeholder_list": "name"},
 	},
+	{
+		name:    "drop_schedules",
+		stmt:    "drop_schedule_stmt",
+		replace: map[string]string{"a_expr": "schedule_id"},
+		unlink:  []string{"schedule_id"},
+	},
 	{
 		name:   "drop_sequence_stmt",
 		inline: []string{"table_name_list", "opt_drop_behavior"},
@@ -819,10 +838,16 @@ var specs = []stmtSpec{
 	},
 	{
 		name:    "pause_job",
-		stmt:    "pause_stmt",
+		stmt:    "pause_jobs_stmt",
 		replace: map[string]string{"a_expr": "job_id"},
 		unlink:  []string{"job_id"},
 	},
+	{
+		name:    "pause_schedules",
+		stmt:    "pause_schedules_stmt",
+		replace: map[string]string{"a_expr": "schedule_id"},
+		unlink:  []string{"schedule_id"},
+	},
 	{
 		name: "primary_key_column_level",
 		stmt: "stmt_block",
@@ -876,7 +901,7 @@ var specs = []stmtSpec{
 		inline: []string{"as_of_clause", "opt_with_options"},
 		replace: map[string]string{
 			"a_expr":                                  "timestamp",
-			"string_or_placeholder_list":              "full_backup_location ( | incremental_backup_location ( ',' incremental_backup_location )*)",
+			"list_of_string_or_placeholder_opt_list":  "full_backup_location ( | incremental_backup_location ( ',' incremental_backup_location )*)",
 			"'WITH' 'OPTIONS' '(' kv_option_list ')'": "",
 			"targets": "( ( 'TABLE' | ) table_pattern ( ( ',' table_pattern ) )* | 'DATABASE' database_name ( ( ',' database_name ) )* )",
 		},
@@ -884,10 +909,16 @@ var specs = []stmtSpec{
 	},
 	{
 		name:    "resume_job",
-		stmt:    "resume_stmt",
+		stmt:    "resume_jobs_stmt",
 		replace: map[string]string{"a_expr": "job_id"},
 		unlink:  []string{"job_id"},
 	},
+	{
+		name:    "resume_schedules",
+		stmt:    "resume_schedules_stmt",
+		replace: map[string]string{"a_expr": "schedule_id"},
+		unlink:  []string{"schedule_id"},
+	},
 	{
 		name:   "revoke_privileges",
 		stmt:   "revoke_stmt",
@@ -1077,7 +1108,7 @@ var specs = []stmtSpec{
 		name:    "show_backup",
 		stmt:    "show_backup_stmt",
 		match:   []*regexp.Regexp{regexp.MustCompile("'SHOW' 'BACKUP'")},
-		replace: map[string]string{"string_or_placeholder": "location"},
+		replace: map[string]string{"list_of_string_or_placeholder_opt_list": "location ( ( ',' location ) )*"},
 		unlink:  []string{"location"},
 	},
 	{
@@ -1117,6 +1148,10 @@ var specs = []stmtSpec{
 		inline:  []string{"ranges_kw"},
 		exclude: []*regexp.Regexp{regexp.MustCompile("'TESTING_RANGES'")},
 	},
+	{
+		name: "show_schedules",
+		stmt: "show_schedules_stmt",
+	},
 	{
 		name: "show_schemas",
 		stmt: "show_schemas_stmt",
@@ -398,6 +398,12 @@ func (z *ZoneConfig) ValidateTandemFields() error {
 	if !z.InheritedLeasePreferences && z.InheritedConstraints {
 		return fmt.Errorf("lease preferences can not be set unless the constraints are explicitly set as well")
 	}
+	if z.NumVoters != nil && z.NumReplicas == nil {
+		return fmt.Errorf("when num_voters is set, num_replicas must be set as well")
+	}
+	if len(z.VoterConstraints) > 0 && z.NumVoters == nil {
+		return fmt.Errorf("when voter_constraints are set, num_voters must be set as well")
+	}
 	return nil
 }
 
@@ -426,6 +432,18 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if z.NumVoters != nil {
+		switch {
+		case *z.NumVoters <= 0:
+			return fmt.Errorf("at least one voting replica is required")
+		case *z.NumVoters == 2:
+			return fmt.Errorf("at least 3 voting replicas are required for multi-replica configurations")
+		case z.NumReplicas != nil && *z.NumVoters > *z.NumReplicas:
+			return fmt.Errorf("num_voters (%d) cannot be greater than num_replicas (%d)",
+				*z.NumVoters, *z.NumReplicas)
+		}
+	}
+
 	if z.RangeMaxBytes != nil && *z.RangeMaxBytes < minRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			*z.RangeMaxBytes, minRangeMaxBytes)
@@ -480,6 +498,31 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if len(z.VoterConstraints) > 0 {
+		var numConstrainedVoters int64
+		for _, constraints := range z.VoterConstraints {
+			if constraints.NumReplicas < 0 {
+				return fmt.Errorf("voter_constraints must apply to at least one voting replica")
+			}
+			numConstrainedVoters += int64(constraints.NumReplicas)
+			for _, constraint := range constraints.Constraints {
+				if constraint.Type == Constraint_DEPRECATED_POSITIVE {
+					return fmt.Errorf("voter_constraints must either be required (prefixed with a '+') or " +
+						"prohibited (prefixed with a '-')")
+				}
+				if constraint.Type != Constraint_REQUIRED && constraints.NumReplicas != 0 &&
+					z.NumVoters != nil && constraints.NumReplicas != *z.NumVoters {
+					return fmt.Errorf(
+						"only required constraints (prefixed with a '+') can be applied to a subset of voting replicas")
+				}
+			}
+		}
+		if z.NumVoters != nil && numConstrainedVoters > int64(*z.NumVoters) {
+			return fmt.Errorf("the number of voting replicas specified in voter_constraints (%d) cannot "+
+				"be greater than num_voters (%d)", numConstrainedVoters, *z.NumVoters)
+		}
+	}
+
 	for _, leasePref := range z.LeasePreferences {
 		if len(leasePref.Constraints) == 0 {
 			return fmt.Errorf("every lease preference must include at least one constraint")
@@ -530,6 +573,42 @@ func (z *ZoneConfig) InheritFromParent(parent ZoneConfig) {
 			z.InheritedLeasePreferences = false
 		}
 	}
+	if z.NumVoters == nil {
+		if parent.NumVoters != nil {
+			z.NumVoters = proto.Int32(*parent.NumVoters)
+		}
+	}
+	if z.InheritedVoterConstraints() {
+		z.VoterConstraints = parent.VoterConstraints
+		z.NullVoterConstraintsIsEmpty = parent.NullVoterConstraintsIsEmpty
+	}
+}
+
+// InheritedVoterConstraints returns whether the VoterConstraints field is
+// inherited from the zone's parent rather than explicitly set.
+func (z *ZoneConfig) InheritedVoterConstraints() bool {
+	return len(z.VoterConstraints) == 0 && !z.NullVoterConstraintsIsEmpty
+}
+
+// GetNumVoters returns the number of voting replicas configured for the zone.
+// All replicas are voters unless NumVoters is set.
+func (z *ZoneConfig) GetNumVoters() int32 {
+	if z.NumVoters != nil {
+		return *z.NumVoters
+	}
+	if z.NumReplicas != nil {
+		return *z.NumReplicas
+	}
+	return 0
+}
+
+// GetNumNonVoters returns the number of non-voting replicas configured for the
+// zone.
+func (z *ZoneConfig) GetNumNonVoters() int32 {
+	if z.NumVoters == nil || z.NumReplicas == nil || *z.NumVoters >= *z.NumReplicas {
+		return 0
+	}
+	return *z.NumReplicas - *z.NumVoters
 }
 
 // CopyFromZone copies over the specified fields from the other zone.
@@ -568,6 +647,16 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 			z.LeasePreferences = other.LeasePreferences
 			z.InheritedLeasePreferences = other.InheritedLeasePreferences
 		}
+		if fieldName == "num_voters" {
+			z.NumVoters = nil
+			if other.NumVoters != nil {
+				z.NumVoters = proto.Int32(*other.NumVoters)
+			}
+		}
+		if fieldName == "voter_constraints" {
+			z.VoterConstraints = other.VoterConstraints
+			z.NullVoterConstraintsIsEmpty = other.NullVoterConstraintsIsEmpty
+		}
 	}
 }
 
@@ -235,6 +235,71 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"",
 		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(0),
+			},
+			"at least one voting replica is required",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(2),
+			},
+			"at least 3 voting replicas are required for multi-replica configurations",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(3),
+				NumVoters:   proto.Int32(5),
+			},
+			`num_voters \(5\) cannot be greater than num_replicas \(3\)`,
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(3),
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+						NumReplicas: 4,
+					},
+				},
+			},
+			`the number of voting replicas specified in voter_constraints \(4\) cannot be greater than num_voters \(3\)`,
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(3),
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_PROHIBITED}},
+						NumReplicas: 1,
+					},
+				},
+			},
+			"only required constraints .+ can be applied to a subset of voting replicas",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(3),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+						NumReplicas: 2,
+					},
+					{
+						Constraints: []Constraint{{Value: "b", Type: Constraint_PROHIBITED}},
+					},
+				},
+			},
+			"",
+		},
 	}
 
 	for i, c := range testCases {
@@ -287,6 +352,23 @@ func TestZoneConfigValidateTandemFields(t *testing.T) {
 			},
 			"lease preferences can not be set unless the constraints are explicitly set as well",
 		},
+		{
+			ZoneConfig{
+				NumVoters: proto.Int32(3),
+			},
+			"when num_voters is set, num_replicas must be set as well",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+					},
+				},
+			},
+			"when voter_constraints are set, num_voters must be set as well",
+		},
 	}
 
 	for i, c := range testCases {
@@ -657,6 +739,103 @@ lease_preferences: [[+duck=bar1, +duck=bar2], [-duck=foo]]
 	}
 }
 
+// TestZoneConfigNonVotersYAML verifies that num_voters and voter_constraints
+// round-trip through YAML and are only emitted when they have been set.
+func TestZoneConfigNonVotersYAML(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	original := ZoneConfig{
+		RangeMinBytes: proto.Int64(1),
+		RangeMaxBytes: proto.Int64(1),
+		GC: &GCPolicy{
+			TTLSeconds: 1,
+		},
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+		VoterConstraints: []Constraints{
+			{
+				Constraints: []Constraint{{Key: "region", Value: "us", Type: Constraint_REQUIRED}},
+				NumReplicas: 3,
+			},
+		},
+	}
+	expected := `range_min_bytes: 1
+range_max_bytes: 1
+gc:
+  ttlseconds: 1
+num_replicas: 5
+constraints: []
+lease_preferences: []
+num_voters: 3
+voter_constraints: {+region=us: 3}
+`
+	body, err := yaml.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != expected {
+		t.Fatalf("yaml.Marshal(%+v)\ngot:\n%s\nwant:\n%s", original, body, expected)
+	}
+
+	var unmarshaled ZoneConfig
+	if err := yaml.UnmarshalStrict(body, &unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(&unmarshaled, &original) {
+		t.Errorf("yaml.UnmarshalStrict(%q)\ngot:\n%+v\nwant:\n%+v", body, unmarshaled, original)
+	}
+
+	// Voter constraints that are explicitly empty are emitted and preserved.
+	original.VoterConstraints = nil
+	original.NullVoterConstraintsIsEmpty = true
+	body, err = yaml.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmarshaled = ZoneConfig{}
+	if err := yaml.UnmarshalStrict(body, &unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if unmarshaled.InheritedVoterConstraints() {
+		t.Errorf("expected explicitly empty voter constraints to survive a round-trip, got:\n%s", body)
+	}
+}
+
+func TestZoneConfigInheritVoterFields(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	parent := ZoneConfig{
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+		VoterConstraints: []Constraints{
+			{Constraints: []Constraint{{Key: "region", Value: "us", Type: Constraint_REQUIRED}}},
+		},
+	}
+
+	child := *NewZoneConfig()
+	child.InheritFromParent(parent)
+	if child.GetNumVoters() != 3 || child.GetNumNonVoters() != 2 {
+		t.Errorf("expected 3 voters and 2 non-voters, got %d and %d",
+			child.GetNumVoters(), child.GetNumNonVoters())
+	}
+	if len(child.VoterConstraints) != 1 {
+		t.Errorf("expected voter constraints to be inherited, got %v", child.VoterConstraints)
+	}
+
+	explicit := *NewZoneConfig()
+	explicit.NullVoterConstraintsIsEmpty = true
+	explicit.InheritFromParent(parent)
+	if len(explicit.VoterConstraints) != 0 {
+		t.Errorf("expected explicitly empty voter constraints to be kept, got %v", explicit.VoterConstraints)
+	}
+
+	allVoters := ZoneConfig{NumReplicas: proto.Int32(3)}
+	if allVoters.GetNumVoters() != 3 || allVoters.GetNumNonVoters() != 0 {
+		t.Errorf("expected all replicas to be voters, got %d voters and %d non-voters",
+			allVoters.GetNumVoters(), allVoters.GetNumNonVoters())
+	}
+}
+
 // TestExperimentalLeasePreferencesYAML makes sure that we accept the
 // lease_preferences YAML field both with and without the "experimental_"
 // prefix.
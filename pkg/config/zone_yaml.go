@@ -208,6 +208,8 @@ type marshalableZoneConfig struct {
 	Constraints                  ConstraintsList   `json:"constraints" yaml:"constraints,flow"`
 	LeasePreferences             []LeasePreference `json:"lease_preferences" yaml:"lease_preferences,flow"`
 	ExperimentalLeasePreferences []LeasePreference `json:"experimental_lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
+	NumVoters                    *int32            `json:"num_voters,omitempty" yaml:"num_voters,omitempty"`
+	VoterConstraints             *ConstraintsList  `json:"voter_constraints,omitempty" yaml:"voter_constraints,flow,omitempty"`
 	Subzones                     []Subzone         `json:"subzones" yaml:"-"`
 	SubzoneSpans                 []SubzoneSpan     `json:"subzone_spans" yaml:"-"`
 }
@@ -233,6 +235,14 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 	}
 	// We intentionally do not round-trip ExperimentalLeasePreferences. We never
 	// want to return yaml containing it.
+	if c.NumVoters != nil {
+		m.NumVoters = proto.Int32(*c.NumVoters)
+	}
+	// Voter constraints are omitted entirely unless they have been set, so that
+	// the output for zones that don't use non-voting replicas is unchanged.
+	if !c.InheritedVoterConstraints() {
+		m.VoterConstraints = &ConstraintsList{Constraints: c.VoterConstraints}
+	}
 	m.Subzones = c.Subzones
 	m.SubzoneSpans = c.SubzoneSpans
 	return m
@@ -273,6 +283,13 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig, c ZoneConfig) ZoneConfig
 	if m.LeasePreferences != nil || m.ExperimentalLeasePreferences != nil {
 		c.InheritedLeasePreferences = false
 	}
+	if m.NumVoters != nil {
+		c.NumVoters = proto.Int32(*m.NumVoters)
+	}
+	if m.VoterConstraints != nil {
+		c.VoterConstraints = m.VoterConstraints.Constraints
+		c.NullVoterConstraintsIsEmpty = len(c.VoterConstraints) == 0
+	}
 	c.Subzones = m.Subzones
 	c.SubzoneSpans = m.SubzoneSpans
 	return c
@@ -256,6 +256,7 @@ func (b *Batch) fillResults(ctx context.Context) {
 			case *roachpb.LeaseInfoRequest:
 			case *roachpb.PushTxnRequest:
 			case *roachpb.QueryTxnRequest:
+			case *roachpb.RecoverTxnRequest:
 			case *roachpb.QueryIntentRequest:
 			case *roachpb.ResolveIntentRequest:
 			case *roachpb.ResolveIntentRangeRequest:
@@ -677,7 +678,7 @@ func (b *Batch) writeBatch(s, e interface{}, data []byte) {
 }
 
 // addSSTable is only exported on DB.
-func (b *Batch) addSSTable(s, e interface{}, data []byte) {
+func (b *Batch) addSSTable(s, e interface{}, data []byte, disallowShadowing bool) {
 	begin, err := marshalKey(s)
 	if err != nil {
 		b.initResult(0, 0, notRaw, err)
@@ -693,7 +694,8 @@ func (b *Batch) addSSTable(s, e interface{}, data []byte) {
 			Key:    begin,
 			EndKey: end,
 		},
-		Data: data,
+		Data:              data,
+		DisallowShadowing: disallowShadowing,
 	}
 	b.appendReqs(req)
 	b.initResult(1, 0, notRaw, nil)
@@ -50,6 +50,9 @@ type Txn struct {
 	// systemConfigTrigger is set to true when modifying keys from the SystemConfig
 	// span. This sets the SystemConfigTrigger on EndTransactionRequest.
 	systemConfigTrigger bool
+	// boundedStaleness is set for the transaction of a bounded staleness read,
+	// and attached to all requests sent through this transaction.
+	boundedStaleness bool
 
 	// mu holds fields that need to be synchronized for concurrent request execution.
 	mu struct {
@@ -197,6 +200,14 @@ func (txn *Txn) SetUserPriority(userPriority roachpb.UserPriority) error {
 	return txn.mu.sender.SetUserPriority(userPriority)
 }
 
+// SetBoundedStaleness marks the transaction as a bounded staleness read, whose
+// timestamp was chosen such that its reads can be served by the nearest replica
+// of each range rather than the lease holder. It must be set before any
+// operations are performed on the transaction.
+func (txn *Txn) SetBoundedStaleness() {
+	txn.boundedStaleness = true
+}
+
 // InternalSetPriority sets the transaction priority. It is intended for
 // internal (testing) use only.
 func (txn *Txn) InternalSetPriority(priority int32) {
@@ -773,6 +784,9 @@ func (txn *Txn) Send(
 	if txn.gatewayNodeID != 0 {
 		ba.Header.GatewayNodeID = txn.gatewayNodeID
 	}
+	if txn.boundedStaleness {
+		ba.Header.BoundedStaleness = true
+	}
 
 	txn.mu.Lock()
 	requestTxnID := txn.mu.ID
@@ -0,0 +1,213 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package jobs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CronExpr is a parsed cron expression. All times are interpreted in UTC.
+type CronExpr struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the day-of-month or day-of-week field
+	// was unrestricted. Following the usual cron semantics, a day matches if
+	// it matches either day field when both are restricted, and if it matches
+	// both of them otherwise.
+	domStar, dowStar bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cronSearchYears bounds how far into the future Next looks for a matching
+// time. Expressions such as "0 0 30 2 *" never match.
+const cronSearchYears = 5
+
+// ParseCronExpr parses a five-field cron expression ("minute hour
+// day-of-month month day-of-week") or one of the @yearly, @annually,
+// @monthly, @weekly, @daily, @midnight and @hourly shorthands. Each field is
+// a comma-separated list of '*', single values or ranges, optionally followed
+// by a /step. Months and days of the week may also be given by their
+// three-letter English names; day-of-week 7 is an alias for Sunday.
+func ParseCronExpr(expr string) (*CronExpr, error) {
+	s := strings.TrimSpace(expr)
+	if m, ok := cronMacros[strings.ToLower(s)]; ok {
+		s = m
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, errors.Errorf(
+			"invalid cron expression %q: expected 5 fields, found %d", expr, len(fields))
+	}
+
+	var c CronExpr
+	var err error
+	if c.minute, _, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q: minute", expr)
+	}
+	if c.hour, _, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q: hour", expr)
+	}
+	if c.dom, c.domStar, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q: day of month", expr)
+	}
+	if c.month, _, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q: month", expr)
+	}
+	if c.dow, c.dowStar, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q: day of week", expr)
+	}
+	// Fold 7 into 0 so that both spellings of Sunday match.
+	if c.dow&(1<<7) != 0 {
+		c.dow = (c.dow | 1) &^ (1 << 7)
+	}
+
+	if c.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, errors.Errorf("invalid cron expression %q: never matches", expr)
+	}
+	return &c, nil
+}
+
+// parseCronField parses a single cron field into a bitset of the values it
+// matches. The returned bool is true if the field starts with '*'.
+func parseCronField(
+	field string, min, max int, names map[string]int,
+) (bits uint64, star bool, _ error) {
+	parseValue := func(s string) (int, error) {
+		if v, ok := names[strings.ToLower(s)]; ok {
+			return v, nil
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, errors.Errorf("invalid value %q", s)
+		}
+		if v < min || v > max {
+			return 0, errors.Errorf("value %d out of range [%d, %d]", v, min, max)
+		}
+		return v, nil
+	}
+
+	// As in other cron implementations, a field starting with '*' (including
+	// "*/step") counts as unrestricted for the purpose of matching days.
+	star = strings.HasPrefix(field, "*")
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, false, errors.Errorf("invalid step in %q", part)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = min, max
+		case strings.IndexByte(rng, '-') > 0:
+			i := strings.IndexByte(rng, '-')
+			var err error
+			if lo, err = parseValue(rng[:i]); err != nil {
+				return 0, false, err
+			}
+			if hi, err = parseValue(rng[i+1:]); err != nil {
+				return 0, false, err
+			}
+			if lo > hi {
+				return 0, false, errors.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := parseValue(rng)
+			if err != nil {
+				return 0, false, err
+			}
+			lo, hi = v, v
+			if step != 1 {
+				// "5/15" means every 15 starting at 5.
+				hi = max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+func (c *CronExpr) matchesDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first time strictly after t that matches the expression,
+// or the zero time if there is no such time in the next few years.
+func (c *CronExpr) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + cronSearchYears
+
+outer:
+	for t.Year() <= yearLimit {
+		for c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			if t.Month() == time.January {
+				continue outer
+			}
+		}
+		for !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			if t.Day() == 1 {
+				continue outer
+			}
+		}
+		for c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			if t.Hour() == 0 {
+				continue outer
+			}
+		}
+		for c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue outer
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCronExprNext(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// 2019-01-15 is a Tuesday.
+	from := time.Date(2019, 1, 15, 10, 30, 45, 0, time.UTC)
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{"* * * * *", "2019-01-15 10:31"},
+		{"@hourly", "2019-01-15 11:00"},
+		{"@daily", "2019-01-16 00:00"},
+		{"@midnight", "2019-01-16 00:00"},
+		{"@weekly", "2019-01-20 00:00"},
+		{"@monthly", "2019-02-01 00:00"},
+		{"@yearly", "2020-01-01 00:00"},
+		{"@annually", "2020-01-01 00:00"},
+		{"*/15 * * * *", "2019-01-15 10:45"},
+		{"5/20 * * * *", "2019-01-15 10:45"},
+		{"30 10 * * *", "2019-01-16 10:30"},
+		{"0,45 9-11 * * *", "2019-01-15 10:45"},
+		{"0 2 * * mon-fri", "2019-01-16 02:00"},
+		{"0 2 * * 7", "2019-01-20 02:00"},
+		{"0 0 1 mar *", "2019-03-01 00:00"},
+		{"0 0 29 2 *", "2020-02-29 00:00"},
+		{"0 0 31 * *", "2019-01-31 00:00"},
+		// Both day fields are restricted, so either one matching is enough.
+		{"0 0 20 * 3", "2019-01-16 00:00"},
+		// The day-of-week field starts with '*', so both must match.
+		{"0 0 1 * */2", "2019-06-01 00:00"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			c, err := ParseCronExpr(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := c.Next(from).Format("2006-01-02 15:04"); next != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
+
+func TestCronExprErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{"", "expected 5 fields, found 0"},
+		{"@sometimes", "expected 5 fields, found 1"},
+		{"* * * *", "expected 5 fields, found 4"},
+		{"60 * * * *", "minute: value 60 out of range"},
+		{"* 24 * * *", "hour: value 24 out of range"},
+		{"* * 0 * *", "day of month: value 0 out of range"},
+		{"* * * 13 *", "month: value 13 out of range"},
+		{"* * * * 8", "day of week: value 8 out of range"},
+		{"* * * foo *", `month: invalid value "foo"`},
+		{"*/0 * * * *", `minute: invalid step in "\*/0"`},
+		{"5-1 * * * *", `minute: invalid range "5-1"`},
+		{"0 0 30 2 *", "never matches"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := ParseCronExpr(tc.expr)
+			if !testutils.IsError(err, tc.expected) {
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
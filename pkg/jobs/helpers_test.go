@@ -26,6 +26,18 @@ func ResetResumeHooks() func() {
 	return func() { resumeHooks = oldResumeHooks }
 }
 
+// PrependResumeHook adds a resume hook that takes precedence over the ones
+// added by AddResumeHook, e.g. to replace the resumer of a job type registered
+// by another package.
+func PrependResumeHook(fn ResumeHookFn) {
+	resumeHooks = append([]ResumeHookFn{fn}, resumeHooks...)
+}
+
+// RunSchedules runs a single pass of the scheduler loop.
+func (r *Registry) RunSchedules(ctx context.Context) error {
+	return r.maybeRunSchedules(ctx)
+}
+
 // FakeResumer calls optional callbacks during the job lifecycle.
 type FakeResumer struct {
 	OnResume func(job *Job) error
@@ -115,6 +115,14 @@ func (e *InvalidStatusError) Error() string {
 	return fmt.Sprintf("cannot %s %s job (id %d)", e.op, e.status, e.id)
 }
 
+// IsPausedError returns whether err is an *InvalidStatusError for a paused
+// job, e.g. the error returned by FractionProgressed once the job has been
+// paused.
+func IsPausedError(err error) bool {
+	ierr, ok := errors.Cause(err).(*InvalidStatusError)
+	return ok && ierr.status == StatusPaused
+}
+
 // SimplifyInvalidStatusError unwraps an *InvalidStatusError into an error
 // message suitable for users. Other errors are returned as passed.
 func SimplifyInvalidStatusError(err error) error {
@@ -140,17 +148,32 @@ func (j *Job) Created(ctx context.Context) error {
 
 // Started marks the tracked job as started.
 func (j *Job) Started(ctx context.Context) error {
-	return j.update(ctx, func(_ *client.Txn, status *Status, payload *jobspb.Payload, _ *jobspb.Progress) (bool, error) {
+	return j.update(ctx, func(_ *client.Txn, status *Status, payload *jobspb.Payload, progress *jobspb.Progress) (bool, error) {
 		if *status != StatusPending {
 			// Already started - do nothing.
 			return false, nil
 		}
 		*status = StatusRunning
 		payload.StartedMicros = timeutil.ToUnixMicros(timeutil.Now())
+		startRun(progress)
 		return true, nil
 	})
 }
 
+// CurrentStatus returns the status of the tracked job as stored in
+// system.jobs, which may have been changed since the job was loaded, e.g. by
+// a PAUSE JOB statement.
+func (j *Job) CurrentStatus(ctx context.Context) (Status, error) {
+	var current Status
+	if err := j.update(ctx, func(_ *client.Txn, status *Status, _ *jobspb.Payload, _ *jobspb.Progress) (bool, error) {
+		current = *status
+		return false, nil
+	}); err != nil {
+		return "", err
+	}
+	return current, nil
+}
+
 // RunningStatus updates the detailed status of a job currently in progress.
 // It sets the job's RunningStatus field to the value returned by runningStatusFn
 // and persists runningStatusFn's modifications to the job's details, if any.
@@ -185,6 +208,28 @@ func (j *Job) SetDescription(ctx context.Context, updateFn DescriptionUpdateFn)
 	)
 }
 
+// SetResourceLimits updates the resource limits of a job that has not yet
+// finished. Changes to the limits take effect when the job is next adopted,
+// e.g. after it is paused and resumed, except that a node that the locality
+// limit excludes stops running the job right away.
+func (j *Job) SetResourceLimits(ctx context.Context, updateFn ResourceLimitsUpdateFn) error {
+	return j.update(ctx, func(_ *client.Txn, status *Status, payload *jobspb.Payload, _ *jobspb.Progress) (bool, error) {
+		if status.Terminal() {
+			return false, &InvalidStatusError{*j.id, *status, "set resource limits of", payload.Error}
+		}
+		var limits jobspb.ResourceLimits
+		if payload.ResourceLimits != nil {
+			limits = *payload.ResourceLimits
+		}
+		limits, err := updateFn(ctx, limits)
+		if err != nil {
+			return false, err
+		}
+		payload.ResourceLimits = &limits
+		return true, nil
+	})
+}
+
 // RunningStatusFn is a callback that computes a job's running status
 // given its details. It is safe to modify details in the callback; those
 // modifications will be automatically persisted to the database record.
@@ -194,6 +239,10 @@ type RunningStatusFn func(ctx context.Context, details jobspb.Details) (RunningS
 // given its current one.
 type DescriptionUpdateFn func(ctx context.Context, description string) (string, error)
 
+// ResourceLimitsUpdateFn is a callback that computes a job's resource limits
+// given its current ones.
+type ResourceLimitsUpdateFn func(ctx context.Context, limits jobspb.ResourceLimits) (jobspb.ResourceLimits, error)
+
 // FractionProgressedFn is a callback that computes a job's completion fraction
 // given its details. It is safe to modify details in the callback; those
 // modifications will be automatically persisted to the database record.
@@ -310,7 +359,7 @@ func (j *Job) paused(ctx context.Context) error {
 // currently paused. It does not directly resume the job; rather, it expires the
 // job's lease so that a Registry adoption loop detects it and resumes it.
 func (j *Job) resumed(ctx context.Context) error {
-	return j.update(ctx, func(_ *client.Txn, status *Status, payload *jobspb.Payload, _ *jobspb.Progress) (bool, error) {
+	return j.update(ctx, func(_ *client.Txn, status *Status, payload *jobspb.Payload, progress *jobspb.Progress) (bool, error) {
 		if *status == StatusRunning {
 			// Already resumed - do nothing.
 			return false, nil
@@ -322,9 +371,13 @@ func (j *Job) resumed(ctx context.Context) error {
 			return false, fmt.Errorf("job with status %s cannot be resumed", *status)
 		}
 		*status = StatusRunning
+		startRun(progress)
 		// NB: A nil lease indicates the job is not resumable, whereas an empty
-		// lease is always considered expired.
-		payload.Lease = &jobspb.Lease{}
+		// lease is always considered expired. Schema changes are not run by the
+		// Registry, so they are left without one.
+		if payload.Type() != jobspb.TypeSchemaChange {
+			payload.Lease = &jobspb.Lease{}
+		}
 		return true, nil
 	})
 }
@@ -639,6 +692,21 @@ func (j *Job) adopt(ctx context.Context, oldLease *jobspb.Lease) error {
 				payload.Lease, oldLease)
 		}
 		payload.Lease = j.registry.newLease()
+		startRun(progress)
+		return true, nil
+	})
+}
+
+// released expires the job's lease, if it is still oldLease, so that the
+// Registry adoption loop of another node adopts the job.
+func (j *Job) released(ctx context.Context, oldLease *jobspb.Lease) error {
+	return j.update(ctx, func(_ *client.Txn, _ *Status, payload *jobspb.Payload, _ *jobspb.Progress) (bool, error) {
+		if !payload.Lease.Equal(oldLease) {
+			return false, nil
+		}
+		// NB: A nil lease indicates the job is not resumable, whereas an empty
+		// lease is always considered expired.
+		payload.Lease = &jobspb.Lease{}
 		return true, nil
 	})
 }
@@ -289,6 +289,37 @@ func TestRegistryLifecycle(t *testing.T) {
 		check(t)
 	})
 
+	t.Run("pause from resumer", func(t *testing.T) {
+		clear()
+		job, _, err := registry.StartJob(ctx, nil, mockJob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.resume++
+		check(t)
+		resumeCheckCh <- struct{}{}
+		resumeCh <- jobs.NewPauseJobError("pausing")
+		e.resumeExit++
+		check(t)
+		testutils.SucceedsSoon(t, func() error {
+			var status string
+			sqlDB.QueryRow(t, `SELECT status FROM system.jobs WHERE id = $1`, *job.ID()).Scan(&status)
+			if jobs.Status(status) != jobs.StatusPaused {
+				return errors.Errorf("expected job to be paused, got %s", status)
+			}
+			return nil
+		})
+		sqlDB.Exec(t, "RESUME JOB $1", *job.ID())
+		resumeCheckCh <- struct{}{}
+		resumeCh <- nil
+		e.resume++
+		e.resumeExit++
+		e.success = true
+		e.terminal++
+		<-termCh
+		check(t)
+	})
+
 	t.Run("cancel", func(t *testing.T) {
 		clear()
 		job, _, err := registry.StartJob(ctx, nil, mockJob)
@@ -1103,18 +1134,115 @@ func TestJobLifecycle(t *testing.T) {
 		}
 	})
 
-	t.Run("cannot pause or resume schema changes", func(t *testing.T) {
-		job, _ := createJob(jobs.Record{
+	t.Run("alter job sets resource limits", func(t *testing.T) {
+		job, _ := createDefaultJob()
+		limits := func() jobspb.ResourceLimits {
+			loaded, err := registry.LoadJob(ctx, *job.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l := loaded.Payload().ResourceLimits; l != nil {
+				return *l
+			}
+			return jobspb.ResourceLimits{}
+		}
+
+		if _, err := sqlDB.Exec(
+			`ALTER JOB $1 SET max_concurrency = '2', max_bytes_per_sec = '1KiB', locality = 'region=east'`,
+			*job.ID(),
+		); err != nil {
+			t.Fatal(err)
+		}
+		expected := jobspb.ResourceLimits{MaxConcurrency: 2, MaxBytesPerSec: 1024, Locality: "region=east"}
+		if l := limits(); l != expected {
+			t.Fatalf("expected %+v, got %+v", expected, l)
+		}
+
+		// Options that are not given are left alone, and empty values remove
+		// limits.
+		if _, err := sqlDB.Exec(`ALTER JOB $1 SET max_concurrency = ''`, *job.ID()); err != nil {
+			t.Fatal(err)
+		}
+		expected.MaxConcurrency = 0
+		if l := limits(); l != expected {
+			t.Fatalf("expected %+v, got %+v", expected, l)
+		}
+
+		for stmt, errRE := range map[string]string{
+			`ALTER JOB $1 SET max_concurrency = '-1'`:  "max_concurrency must not be negative",
+			`ALTER JOB $1 SET max_bytes_per_sec = 'x'`: "invalid max_bytes_per_sec",
+			`ALTER JOB $1 SET locality = 'region'`:     "invalid locality",
+			`ALTER JOB $1 SET priority = 'high'`:       `invalid option "priority"`,
+		} {
+			if _, err := sqlDB.Exec(stmt, *job.ID()); !testutils.IsError(err, errRE) {
+				t.Fatalf("%s: expected %q, got %v", stmt, errRE, err)
+			}
+		}
+
+		if err := job.Succeeded(ctx, jobs.NoopFn); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sqlDB.Exec(
+			`ALTER JOB $1 SET max_concurrency = '1'`, *job.ID(),
+		); !testutils.IsError(err, "cannot set resource limits of") {
+			t.Fatalf("unexpected %v", err)
+		}
+	})
+
+	t.Run("pause and resume schema changes", func(t *testing.T) {
+		job, exp := createJob(jobs.Record{
 			Details:  jobspb.SchemaChangeDetails{},
 			Progress: jobspb.SchemaChangeProgress{},
 		})
+		if err := job.Started(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.Pause(ctx, nil, *job.ID()); err != nil {
+			t.Fatal(err)
+		}
+		if err := exp.verify(job.ID(), jobs.StatusPaused); err != nil {
+			t.Fatal(err)
+		}
+		if status, err := job.CurrentStatus(ctx); err != nil {
+			t.Fatal(err)
+		} else if status != jobs.StatusPaused {
+			t.Fatalf("expected status %s, got %s", jobs.StatusPaused, status)
+		}
+		if err := job.FractionProgressed(ctx, jobs.FractionUpdater(0.5)); !jobs.IsPausedError(err) {
+			t.Fatalf("expected paused error, got %v", err)
+		}
+		if err := registry.Resume(ctx, nil, *job.ID()); err != nil {
+			t.Fatal(err)
+		}
+		if err := exp.verify(job.ID(), jobs.StatusRunning); err != nil {
+			t.Fatal(err)
+		}
+		// Schema changes are not run by the registry, so resuming one must not
+		// make it adoptable.
+		if err := job.FractionProgressed(ctx, jobs.FractionUpdater(0.5)); err != nil {
+			t.Fatal(err)
+		}
+		if payload := job.Payload(); payload.Lease != nil {
+			t.Fatalf("expected no lease, got %v", payload.Lease)
+		}
+		if err := registry.Cancel(ctx, nil, *job.ID()); err != nil {
+			t.Fatalf("unexpected %v", err)
+		}
+	})
+
+	t.Run("cannot pause or resume schema change rollbacks", func(t *testing.T) {
+		job, _ := createJob(jobs.Record{
+			Description: "ROLL BACK JOB 1: ALTER TABLE t ADD COLUMN a INT",
+			Details:     jobspb.SchemaChangeDetails{},
+			Progress:    jobspb.SchemaChangeProgress{},
+		})
 		if err := registry.Pause(ctx, nil, *job.ID()); !testutils.IsError(err, "is not controllable") {
 			t.Fatalf("unexpected %v", err)
 		}
 		if err := registry.Resume(ctx, nil, *job.ID()); !testutils.IsError(err, "is not controllable") {
 			t.Fatalf("unexpected %v", err)
 		}
-		if err := registry.Cancel(ctx, nil, *job.ID()); err != nil {
+		if err := registry.Cancel(ctx, nil, *job.ID()); !testutils.IsError(err, "is not controllable") {
 			t.Fatalf("unexpected %v", err)
 		}
 	})
@@ -1218,3 +1346,93 @@ func TestRunAndWaitForTerminalState(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var nilLimits *jobspb.ResourceLimits
+	if c := nilLimits.LimitConcurrency(5); c != 5 {
+		t.Fatalf("expected nil limits not to limit concurrency, got %d", c)
+	}
+	limits := &jobspb.ResourceLimits{MaxConcurrency: 2, Locality: "region=east,zone=a"}
+	for concurrency, expected := range map[int]int{1: 1, 2: 2, 5: 2} {
+		if c := limits.LimitConcurrency(concurrency); c != expected {
+			t.Errorf("LimitConcurrency(%d): expected %d, got %d", concurrency, expected, c)
+		}
+	}
+
+	for locality, expected := range map[string]bool{
+		"region=east,zone=a":           true,
+		"cloud=gce,region=east,zone=a": true,
+		"region=east":                  false,
+		"region=west,zone=a":           false,
+	} {
+		var l roachpb.Locality
+		if err := l.Set(locality); err != nil {
+			t.Fatal(err)
+		}
+		if allowed, err := limits.AllowsLocality(l); err != nil {
+			t.Fatal(err)
+		} else if allowed != expected {
+			t.Errorf("AllowsLocality(%s): expected %t, got %t", locality, expected, allowed)
+		}
+		if allowed, err := nilLimits.AllowsLocality(l); err != nil || !allowed {
+			t.Errorf("expected nil limits to allow %s, got %t, %v", locality, allowed, err)
+		}
+	}
+}
+
+func TestEstimateFinish(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const second = int64(time.Second / time.Microsecond)
+	start := timeutil.ToUnixMicros(timeutil.Now())
+	fraction := func(f float32) jobspb.Progress_FractionCompleted {
+		return jobspb.Progress_FractionCompleted{FractionCompleted: f}
+	}
+
+	for i, tc := range []struct {
+		payload  jobspb.Payload
+		progress jobspb.Progress
+		fraction float32
+		// finish is the expected estimate, in seconds after start, or -1 if no
+		// estimate is expected.
+		finish int64
+	}{
+		// No progress yet.
+		{jobspb.Payload{StartedMicros: start}, jobspb.Progress{ModifiedMicros: start + 10*second}, 0, -1},
+		// Not yet started.
+		{jobspb.Payload{}, jobspb.Progress{ModifiedMicros: start + 10*second}, 0.5, -1},
+		// Without a run start, the rate is measured from when the job started.
+		{jobspb.Payload{StartedMicros: start}, jobspb.Progress{ModifiedMicros: start + 10*second}, 0.25, 40},
+		// The rate is measured from the start of the current run.
+		{
+			jobspb.Payload{StartedMicros: start - 100*second},
+			jobspb.Progress{ModifiedMicros: start + 10*second, RunStartedMicros: start, RunStartFraction: 0.5},
+			0.75, 20,
+		},
+		// No progress since the run started.
+		{
+			jobspb.Payload{StartedMicros: start - 100*second},
+			jobspb.Progress{ModifiedMicros: start + 10*second, RunStartedMicros: start, RunStartFraction: 0.5},
+			0.5, -1,
+		},
+	} {
+		f := fraction(tc.fraction)
+		tc.progress.Progress = &f
+		finish, ok := jobs.EstimateFinish(&tc.payload, &tc.progress)
+		if tc.finish == -1 {
+			if ok {
+				t.Errorf("%d: expected no estimate, got %s", i, finish)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%d: expected an estimate", i)
+			continue
+		}
+		if expected := timeutil.FromUnixMicros(start + tc.finish*second); !finish.Equal(expected) {
+			t.Errorf("%d: expected %s, got %s", i, expected, finish)
+		}
+	}
+}
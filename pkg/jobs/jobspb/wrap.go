@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -28,6 +29,8 @@ var _ Details = BackupDetails{}
 var _ Details = RestoreDetails{}
 var _ Details = SchemaChangeDetails{}
 var _ Details = ChangefeedDetails{}
+var _ Details = CreateStatsDetails{}
+var _ Details = ScheduledExecutionDetails{}
 
 // ProgressDetails is a marker interface for job progress details proto structs.
 type ProgressDetails interface{}
@@ -36,6 +39,8 @@ var _ ProgressDetails = BackupProgress{}
 var _ ProgressDetails = RestoreProgress{}
 var _ ProgressDetails = SchemaChangeProgress{}
 var _ ProgressDetails = ChangefeedProgress{}
+var _ ProgressDetails = CreateStatsProgress{}
+var _ ProgressDetails = ScheduledExecutionProgress{}
 
 // Type returns the payload's job type.
 func (p *Payload) Type() Type {
@@ -55,6 +60,10 @@ func DetailsType(d isPayload_Details) Type {
 		return TypeImport
 	case *Payload_Changefeed:
 		return TypeChangefeed
+	case *Payload_CreateStats:
+		return TypeCreateStats
+	case *Payload_ScheduledExecution:
+		return TypeScheduledExecution
 	default:
 		panic(fmt.Sprintf("Payload.Type called on a payload with an unknown details type: %T", d))
 	}
@@ -79,6 +88,10 @@ func WrapProgressDetails(details ProgressDetails) interface {
 		return &Progress_Import{Import: &d}
 	case ChangefeedProgress:
 		return &Progress_Changefeed{Changefeed: &d}
+	case CreateStatsProgress:
+		return &Progress_CreateStats{CreateStats: &d}
+	case ScheduledExecutionProgress:
+		return &Progress_ScheduledExecution{ScheduledExecution: &d}
 	default:
 		panic(fmt.Sprintf("WrapProgressDetails: unknown details type %T", d))
 	}
@@ -98,6 +111,10 @@ func (p *Payload) UnwrapDetails() Details {
 		return *d.Import
 	case *Payload_Changefeed:
 		return *d.Changefeed
+	case *Payload_CreateStats:
+		return *d.CreateStats
+	case *Payload_ScheduledExecution:
+		return *d.ScheduledExecution
 	default:
 		return nil
 	}
@@ -117,6 +134,10 @@ func (p *Progress) UnwrapDetails() ProgressDetails {
 		return *d.Import
 	case *Progress_Changefeed:
 		return *d.Changefeed
+	case *Progress_CreateStats:
+		return *d.CreateStats
+	case *Progress_ScheduledExecution:
+		return *d.ScheduledExecution
 	default:
 		return nil
 	}
@@ -149,6 +170,10 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_Import{Import: &d}
 	case ChangefeedDetails:
 		return &Payload_Changefeed{Changefeed: &d}
+	case CreateStatsDetails:
+		return &Payload_CreateStats{CreateStats: &d}
+	case ScheduledExecutionDetails:
+		return &Payload_ScheduledExecution{ScheduledExecution: &d}
 	default:
 		panic(fmt.Sprintf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -197,3 +222,46 @@ func (d ImportProgress) Completed() float32 {
 
 // ChangefeedTargets is a set of id targets with metadata.
 type ChangefeedTargets map[sqlbase.ID]ChangefeedTarget
+
+// LimitConcurrency returns concurrency, lowered to the job's max_concurrency if
+// that is set. A nil ResourceLimits sets no limits.
+func (l *ResourceLimits) LimitConcurrency(concurrency int) int {
+	if l != nil && l.MaxConcurrency > 0 && int64(concurrency) > l.MaxConcurrency {
+		return int(l.MaxConcurrency)
+	}
+	return concurrency
+}
+
+// BytesPerSec returns the job's max_bytes_per_sec, or zero if the job may
+// write at any rate.
+func (l *ResourceLimits) BytesPerSec() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.MaxBytesPerSec
+}
+
+// AllowsLocality returns whether a node with the given locality may adopt the
+// job: it must have every tier of the job's locality.
+func (l *ResourceLimits) AllowsLocality(locality roachpb.Locality) (bool, error) {
+	if l == nil || l.Locality == "" {
+		return true, nil
+	}
+	var required roachpb.Locality
+	if err := required.Set(l.Locality); err != nil {
+		return false, err
+	}
+	for _, tier := range required.Tiers {
+		found := false
+		for _, t := range locality.Tiers {
+			if t == tier {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -94,3 +94,29 @@ func (jpl *ProgressLogger) Loop(ctx context.Context, chunkCh <-chan struct{}) er
 		}
 	}
 }
+
+// startRun records in progress that a run of its job, i.e. the work done since
+// the job was last started, resumed or adopted, begins now.
+func startRun(progress *jobspb.Progress) {
+	progress.RunStartedMicros = timeutil.ToUnixMicros(timeutil.Now())
+	progress.RunStartFraction = progress.GetFractionCompleted()
+}
+
+// EstimateFinish estimates when a running job will finish by extrapolating the
+// rate at which its fraction completed has grown since its current run started
+// to when its progress was last updated. Jobs that predate the tracking of runs
+// are assumed to have run since they started. It returns false if there is not
+// enough progress to make an estimate.
+func EstimateFinish(payload *jobspb.Payload, progress *jobspb.Progress) (time.Time, bool) {
+	fraction := progress.GetFractionCompleted()
+	startMicros, startFraction := progress.RunStartedMicros, progress.RunStartFraction
+	if startMicros == 0 {
+		startMicros, startFraction = payload.StartedMicros, 0
+	}
+	if startMicros == 0 || fraction <= startFraction || progress.ModifiedMicros <= startMicros {
+		return time.Time{}, false
+	}
+	elapsed := float64(progress.ModifiedMicros - startMicros)
+	remaining := elapsed * float64(1-fraction) / float64(fraction-startFraction)
+	return timeutil.FromUnixMicros(progress.ModifiedMicros + int64(remaining)), true
+}
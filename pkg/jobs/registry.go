@@ -90,6 +90,7 @@ type Registry struct {
 	ex       sqlutil.InternalExecutor
 	clock    *hlc.Clock
 	nodeID   *base.NodeIDContainer
+	locality roachpb.Locality
 	settings *cluster.Settings
 	planFn   planHookMaker
 	metrics  Metrics
@@ -136,6 +137,7 @@ func MakeRegistry(
 	db *client.DB,
 	ex sqlutil.InternalExecutor,
 	nodeID *base.NodeIDContainer,
+	locality roachpb.Locality,
 	settings *cluster.Settings,
 	histogramWindowInterval time.Duration,
 	planFn planHookMaker,
@@ -147,6 +149,7 @@ func MakeRegistry(
 		db:       db,
 		ex:       ex,
 		nodeID:   nodeID,
+		locality: locality,
 		settings: settings,
 		planFn:   planFn,
 	}
@@ -273,6 +276,7 @@ var DefaultAdoptInterval = 30 * time.Second
 
 // Start polls the current node for liveness failures and cancels all registered
 // jobs if it observes a failure.
+// It also runs the scheduler that starts jobs for system.scheduled_jobs.
 func (r *Registry) Start(
 	ctx context.Context,
 	stopper *stop.Stopper,
@@ -306,6 +310,8 @@ func (r *Registry) Start(
 			}
 		}
 	})
+
+	r.startScheduler(stopper)
 	return nil
 }
 
@@ -360,20 +366,8 @@ func (r *Registry) Cancel(ctx context.Context, txn *client.Txn, id int64) error
 	job, resumer, err := r.getJobFn(ctx, txn, id)
 	if err != nil {
 		// Special case schema change jobs to mark the job as canceled.
-		if job != nil {
-			payload := job.Payload()
-			// TODO(mjibson): Use an unfortunate workaround to enable canceling of
-			// schema change jobs by comparing the string description. When a schema
-			// change job fails or is canceled, a new job is created with the ROLL BACK
-			// prefix. These rollback jobs cannot be canceled. We could add a field to
-			// the payload proto to indicate if this job is cancelable or not, but in
-			// a split version cluster an older node could pick up the schema change
-			// and fail to clear/set that field appropriately. Thus it seems that the
-			// safest way for now (i.e., without a larger jobs/schema change refactor)
-			// is to hack this up with a string comparison.
-			if payload.Type() == jobspb.TypeSchemaChange && !strings.HasPrefix(payload.Description, "ROLL BACK") {
-				return job.WithTxn(txn).canceled(ctx, NoopFn)
-			}
+		if job != nil && isControllableSchemaChange(job) {
+			return job.WithTxn(txn).canceled(ctx, NoopFn)
 		}
 		return err
 	}
@@ -383,7 +377,7 @@ func (r *Registry) Cancel(ctx context.Context, txn *client.Txn, id int64) error
 // Pause marks the job with id as paused using the specified txn (may be nil).
 func (r *Registry) Pause(ctx context.Context, txn *client.Txn, id int64) error {
 	job, _, err := r.getJobFn(ctx, txn, id)
-	if err != nil {
+	if err != nil && !r.canPauseSchemaChange(job) {
 		return err
 	}
 	return job.WithTxn(txn).paused(ctx)
@@ -392,12 +386,38 @@ func (r *Registry) Pause(ctx context.Context, txn *client.Txn, id int64) error {
 // Resume resumes the paused job with id using the specified txn (may be nil).
 func (r *Registry) Resume(ctx context.Context, txn *client.Txn, id int64) error {
 	job, _, err := r.getJobFn(ctx, txn, id)
-	if err != nil {
+	if err != nil && !r.canPauseSchemaChange(job) {
 		return err
 	}
 	return job.WithTxn(txn).resumed(ctx)
 }
 
+// isControllableSchemaChange returns whether job is a schema change job that
+// can be canceled, which is also required for it to be paused and resumed.
+//
+// TODO(mjibson): Use an unfortunate workaround to enable canceling of
+// schema change jobs by comparing the string description. When a schema
+// change job fails or is canceled, a new job is created with the ROLL BACK
+// prefix. These rollback jobs cannot be canceled. We could add a field to
+// the payload proto to indicate if this job is cancelable or not, but in
+// a split version cluster an older node could pick up the schema change
+// and fail to clear/set that field appropriately. Thus it seems that the
+// safest way for now (i.e., without a larger jobs/schema change refactor)
+// is to hack this up with a string comparison.
+func isControllableSchemaChange(job *Job) bool {
+	payload := job.Payload()
+	return payload.Type() == jobspb.TypeSchemaChange && !strings.HasPrefix(payload.Description, "ROLL BACK")
+}
+
+// canPauseSchemaChange returns whether job, which may be nil, is a schema
+// change job that can be paused and resumed. Older nodes do not stop running
+// a schema change when its job is paused, so this requires the cluster
+// version at which all nodes do.
+func (r *Registry) canPauseSchemaChange(job *Job) bool {
+	return job != nil && isControllableSchemaChange(job) &&
+		r.settings.Version.IsActive(cluster.VersionSchemaChangeProgress)
+}
+
 // Resumer is a resumable Job. Jobs can be paused or canceled at any time. Jobs
 // should call their .Progressed method, which will return an error if the
 // job has been paused or canceled. Functions that take a client.Txn argument
@@ -463,6 +483,19 @@ func (r retryJobError) Error() string {
 	return string(r)
 }
 
+type pauseJobError string
+
+// NewPauseJobError creates a new error that, if returned by a Resumer,
+// indicates to the jobs registry that the job should be paused. It continues
+// from its last checkpoint once it is resumed.
+func NewPauseJobError(s string) error {
+	return pauseJobError(s)
+}
+
+func (e pauseJobError) Error() string {
+	return string(e)
+}
+
 // resume starts or resumes a job. If no error is returned then the job was
 // asynchronously executed. The job is executed with the ctx, so ctx must
 // only by canceled if the job should also be canceled. resultsCh is passed
@@ -495,6 +528,15 @@ func (r *Registry) resume(
 		terminal := true
 		var status Status
 		defer r.unregister(*job.id)
+		if e, ok := errors.Cause(resumeErr).(pauseJobError); ok {
+			if err := job.paused(ctx); err != nil {
+				// The job may have been canceled in the meantime, which is
+				// handled below.
+				resumeErr = errors.Wrapf(err, "could not pause job %d: %s", *job.id, e)
+			} else {
+				resumeErr = &InvalidStatusError{*job.id, StatusPaused, "resume", string(e)}
+			}
+		}
 		if err, ok := errors.Cause(resumeErr).(*InvalidStatusError); ok &&
 			(err.status == StatusPaused || err.status == StatusCanceled) {
 			if err.status == StatusPaused {
@@ -633,6 +675,32 @@ func (r *Registry) maybeAdoptJob(ctx context.Context, nl NodeLiveness) error {
 		_, running := r.mu.jobs[*id]
 		r.mu.Unlock()
 
+		allowed, err := payload.ResourceLimits.AllowsLocality(r.locality)
+		if err != nil {
+			log.Warningf(ctx, "job %d: ignoring invalid locality limit: %s", *id, err)
+			allowed = true
+		}
+		if !allowed {
+			// The job's locality limit, which may have been set since this node
+			// adopted it, excludes this node. If we hold the lease, stop running
+			// the job and release the lease so that a node the limit allows adopts
+			// it.
+			if payload.Lease.NodeID == r.nodeID.Get() {
+				if running {
+					log.Warningf(ctx, "job %d: locality limit excludes this node; releasing", *id)
+					r.unregister(*id)
+				}
+				job := Job{id: id, registry: r}
+				if err := job.released(ctx, payload.Lease); err != nil {
+					log.Warningf(ctx, "job %d: unable to release lease: %s", *id, err)
+				}
+			}
+			if log.V(2) {
+				log.Infof(ctx, "job %d: skipping: locality limit excludes this node", *id)
+			}
+			continue
+		}
+
 		var needsResume bool
 		if payload.Lease.NodeID == r.nodeID.Get() {
 			// If we hold the lease for a job, check to see if we're actually running
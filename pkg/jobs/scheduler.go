@@ -0,0 +1,310 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/pkg/errors"
+)
+
+// Values of the on_previous_running column of system.scheduled_jobs, which
+// control what happens when a schedule is due while the job started by its
+// previous run has not finished yet.
+const (
+	// OnPreviousRunningStart starts a new job regardless.
+	OnPreviousRunningStart = "start"
+	// OnPreviousRunningSkip skips this run and waits for the next one.
+	OnPreviousRunningSkip = "skip"
+	// OnPreviousRunningWait delays this run until the previous job finishes.
+	OnPreviousRunningWait = "wait"
+)
+
+// Values of the on_execution_failure column of system.scheduled_jobs, which
+// control what happens when a job started by a schedule fails.
+const (
+	// OnExecutionFailureRetry runs the schedule again as soon as possible.
+	OnExecutionFailureRetry = "retry"
+	// OnExecutionFailureReschedule waits for the next scheduled run.
+	OnExecutionFailureReschedule = "reschedule"
+	// OnExecutionFailurePause pauses the schedule.
+	OnExecutionFailurePause = "pause"
+)
+
+var (
+	schedulerEnabledSetting = settings.RegisterBoolSetting(
+		"jobs.scheduler.enabled",
+		"if set, jobs are started for the schedules in system.scheduled_jobs",
+		true,
+	)
+
+	schedulerPaceSetting = settings.RegisterValidatedDurationSetting(
+		"jobs.scheduler.pace",
+		"how often to check system.scheduled_jobs for schedules that are due",
+		time.Minute,
+		func(v time.Duration) error {
+			if v < time.Second {
+				return errors.Errorf("cannot set jobs.scheduler.pace to less than 1s: %s", v)
+			}
+			return nil
+		},
+	)
+)
+
+// startScheduler runs the loop that starts jobs for due schedules. Every
+// node runs the loop; each run of a schedule is claimed transactionally by
+// advancing its next_run, so only one node starts a job for it.
+func (r *Registry) startScheduler(stopper *stop.Stopper) {
+	if r.settings == cluster.NoSettings {
+		return
+	}
+	stopper.RunWorker(context.Background(), func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(schedulerPaceSetting.Get(&r.settings.SV)):
+				if err := r.maybeRunSchedules(ctx); err != nil {
+					log.Errorf(ctx, "error while running schedules: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+func (r *Registry) maybeRunSchedules(ctx context.Context) error {
+	if !schedulerEnabledSetting.Get(&r.settings.SV) ||
+		!r.settings.Version.IsActive(cluster.VersionScheduledJobs) {
+		return nil
+	}
+	const stmt = `SELECT schedule_id FROM system.scheduled_jobs WHERE next_run <= $1 ORDER BY next_run`
+	rows, _ /* cols */, err := r.ex.Query(
+		ctx, "find-due-schedules", nil /* txn */, stmt, r.clock.PhysicalTime(),
+	)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		scheduleID := int64(tree.MustBeDInt(row[0]))
+		if err := r.maybeRunSchedule(ctx, scheduleID); err != nil {
+			log.Errorf(ctx, "schedule %d: %s", scheduleID, err)
+		}
+	}
+	return nil
+}
+
+// maybeRunSchedule claims the current run of the schedule and starts its job,
+// unless another node got to it first or the schedule's on_previous_running
+// policy says otherwise.
+func (r *Registry) maybeRunSchedule(ctx context.Context, scheduleID int64) error {
+	// As in StartJob, the job ID is chosen and registered up front so that
+	// the adoption loop does not resume the job behind our back.
+	jobID := r.makeJobID()
+	resumeCtx, cancel := r.makeCtx()
+	r.register(jobID, cancel)
+
+	var job *Job
+	if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		job = nil
+		const loadStmt = `
+SELECT owner, next_run, schedule_expr, execution_stmt, on_previous_running, last_job_id, description
+  FROM system.scheduled_jobs WHERE schedule_id = $1`
+		row, err := r.ex.QueryRow(ctx, "load-schedule", txn, loadStmt, scheduleID)
+		if err != nil {
+			return err
+		}
+		now := r.clock.PhysicalTime()
+		if row == nil || row[1] == tree.DNull || row[1].(*tree.DTimestamp).After(now) {
+			// The schedule was dropped or paused, or another node already
+			// claimed this run.
+			return nil
+		}
+		owner := string(tree.MustBeDString(row[0]))
+		stmt := string(tree.MustBeDString(row[3]))
+		onPreviousRunning := string(tree.MustBeDString(row[4]))
+		// The job description is shown by SHOW JOBS, so it uses the schedule's
+		// description, which unlike its statement has no credentials in it.
+		description := string(tree.MustBeDString(row[6]))
+
+		expr, err := ParseCronExpr(string(tree.MustBeDString(row[2])))
+		if err != nil {
+			return err
+		}
+		nextRun := expr.Next(now)
+
+		if row[5] != tree.DNull && onPreviousRunning != OnPreviousRunningStart {
+			prevID := int64(tree.MustBeDInt(row[5]))
+			running, err := r.isJobRunning(ctx, txn, prevID)
+			if err != nil {
+				return err
+			}
+			if running {
+				if onPreviousRunning == OnPreviousRunningWait {
+					// Leave next_run alone so that the next pass of the scheduler
+					// tries again.
+					return r.updateScheduleState(ctx, txn, scheduleID,
+						fmt.Sprintf("waiting for job %d to finish", prevID))
+				}
+				const skipStmt = `UPDATE system.scheduled_jobs SET next_run = $2, state = $3 WHERE schedule_id = $1`
+				_, err := r.ex.Exec(ctx, "skip-schedule", txn, skipStmt, scheduleID, nextRun,
+					fmt.Sprintf("skipped run at %s: job %d still running", now.Format(time.RFC3339), prevID))
+				return err
+			}
+		}
+
+		job = r.NewJob(Record{
+			Description: description,
+			Username:    owner,
+			Details:     jobspb.ScheduledExecutionDetails{ScheduleID: scheduleID, Statement: stmt},
+			Progress:    jobspb.ScheduledExecutionProgress{},
+		})
+		if err := job.WithTxn(txn).insert(ctx, jobID, r.newLease()); err != nil {
+			return err
+		}
+		const runStmt = `
+UPDATE system.scheduled_jobs SET next_run = $2, last_job_id = $3, state = $4 WHERE schedule_id = $1`
+		_, err = r.ex.Exec(ctx, "run-schedule", txn, runStmt, scheduleID, nextRun, jobID,
+			fmt.Sprintf("started job %d", jobID))
+		return err
+	}); err != nil || job == nil {
+		r.unregister(jobID)
+		return err
+	}
+
+	resumer, err := getResumeHook(jobspb.TypeScheduledExecution, r.settings)
+	if err != nil {
+		r.unregister(jobID)
+		return err
+	}
+	if err := job.Started(ctx); err != nil {
+		r.unregister(jobID)
+		return err
+	}
+	// Nobody waits for the job, and its errCh is buffered, so it is fine to
+	// drop it. The outcome is recorded on the schedule by FinishScheduledRun.
+	_, err = r.resume(resumeCtx, resumer, nil /* resultsCh */, job)
+	return err
+}
+
+func (r *Registry) isJobRunning(ctx context.Context, txn *client.Txn, jobID int64) (bool, error) {
+	row, err := r.ex.QueryRow(
+		ctx, "load-job-status", txn, `SELECT status FROM system.jobs WHERE id = $1`, jobID,
+	)
+	if err != nil || row == nil {
+		return false, err
+	}
+	return !Status(tree.MustBeDString(row[0])).Terminal(), nil
+}
+
+func (r *Registry) updateScheduleState(
+	ctx context.Context, txn *client.Txn, scheduleID int64, state string,
+) error {
+	const stmt = `UPDATE system.scheduled_jobs SET state = $2 WHERE schedule_id = $1`
+	_, err := r.ex.Exec(ctx, "update-schedule-state", txn, stmt, scheduleID, state)
+	return err
+}
+
+// FinishScheduledRun records the outcome of a job started by a schedule on
+// the schedule's row and applies its on_execution_failure policy if the job
+// did not succeed. It must be called with the txn that moves the job into
+// the given terminal status. Canceled jobs never trigger the failure policy.
+func FinishScheduledRun(ctx context.Context, txn *client.Txn, job *Job, status Status) error {
+	details, ok := job.Details().(jobspb.ScheduledExecutionDetails)
+	if !ok {
+		return errors.Errorf("job %d was not started by a schedule", *job.ID())
+	}
+	r := job.registry
+	row, err := r.ex.QueryRow(ctx, "load-schedule", txn,
+		`SELECT on_execution_failure FROM system.scheduled_jobs WHERE schedule_id = $1`,
+		details.ScheduleID)
+	if err != nil || row == nil {
+		// The schedule has been dropped in the meantime.
+		return err
+	}
+	state := fmt.Sprintf("job %d %s", *job.ID(), status)
+	if status != StatusFailed {
+		return r.updateScheduleState(ctx, txn, details.ScheduleID, state)
+	}
+
+	var stmt string
+	switch onFailure := string(tree.MustBeDString(row[0])); onFailure {
+	case OnExecutionFailureRetry:
+		stmt = `UPDATE system.scheduled_jobs SET next_run = now(), state = $2 WHERE schedule_id = $1`
+		state += ", retrying"
+	case OnExecutionFailurePause:
+		stmt = `UPDATE system.scheduled_jobs SET next_run = NULL, state = $2 WHERE schedule_id = $1`
+		state += ", schedule paused"
+	default:
+		stmt = `UPDATE system.scheduled_jobs SET state = $2 WHERE schedule_id = $1`
+	}
+	_, err = r.ex.Exec(ctx, "finish-scheduled-run", txn, stmt, details.ScheduleID, state)
+	return err
+}
+
+// PauseSchedule pauses the schedule with id using the specified txn (may be
+// nil). A paused schedule does not start jobs until it is resumed.
+func (r *Registry) PauseSchedule(ctx context.Context, txn *client.Txn, id int64) error {
+	const stmt = `UPDATE system.scheduled_jobs SET next_run = NULL, state = 'paused' WHERE schedule_id = $1`
+	n, err := r.ex.Exec(ctx, "pause-schedule", txn, stmt, id)
+	if err == nil && n == 0 {
+		err = errors.Errorf("schedule %d does not exist", id)
+	}
+	return err
+}
+
+// ResumeSchedule resumes the paused schedule with id using the specified txn
+// (may be nil). Its next run is the next time that matches its cron
+// expression; resuming a schedule that is not paused does nothing.
+func (r *Registry) ResumeSchedule(ctx context.Context, txn *client.Txn, id int64) error {
+	row, err := r.ex.QueryRow(ctx, "load-schedule", txn,
+		`SELECT schedule_expr, next_run FROM system.scheduled_jobs WHERE schedule_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return errors.Errorf("schedule %d does not exist", id)
+	}
+	if row[1] != tree.DNull {
+		// Already active - do nothing.
+		return nil
+	}
+	expr, err := ParseCronExpr(string(tree.MustBeDString(row[0])))
+	if err != nil {
+		return err
+	}
+	const stmt = `UPDATE system.scheduled_jobs SET next_run = $2, state = 'resumed' WHERE schedule_id = $1`
+	_, err = r.ex.Exec(ctx, "resume-schedule", txn, stmt, id, expr.Next(r.clock.PhysicalTime()))
+	return err
+}
+
+// DropSchedule removes the schedule with id using the specified txn (may be
+// nil). Jobs already started by the schedule are not affected.
+func (r *Registry) DropSchedule(ctx context.Context, txn *client.Txn, id int64) error {
+	const stmt = `DELETE FROM system.scheduled_jobs WHERE schedule_id = $1`
+	n, err := r.ex.Exec(ctx, "drop-schedule", txn, stmt, id)
+	if err == nil && n == 0 {
+		err = errors.Errorf("schedule %d does not exist", id)
+	}
+	return err
+}
@@ -0,0 +1,350 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package jobs_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/pkg/errors"
+)
+
+// fakeScheduledResumer stands in for the SQL resumer of SCHEDULED_EXECUTION
+// jobs. Each run announces its job ID on started and then blocks until its
+// outcome is sent on results.
+type fakeScheduledResumer struct {
+	started chan<- int64
+	results <-chan error
+}
+
+var _ jobs.Resumer = fakeScheduledResumer{}
+
+func (r fakeScheduledResumer) Resume(
+	ctx context.Context, job *jobs.Job, _ interface{}, _ chan<- tree.Datums,
+) error {
+	select {
+	case r.started <- *job.ID():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-r.results:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r fakeScheduledResumer) OnFailOrCancel(
+	ctx context.Context, txn *client.Txn, job *jobs.Job,
+) error {
+	return jobs.FinishScheduledRun(ctx, txn, job, jobs.StatusFailed)
+}
+
+func (r fakeScheduledResumer) OnSuccess(ctx context.Context, txn *client.Txn, job *jobs.Job) error {
+	return jobs.FinishScheduledRun(ctx, txn, job, jobs.StatusSucceeded)
+}
+
+func (r fakeScheduledResumer) OnTerminal(context.Context, *jobs.Job, jobs.Status, chan<- tree.Datums) {
+}
+
+func TestScheduler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer jobs.ResetResumeHooks()()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	started := make(chan int64)
+	results := make(chan error)
+	jobs.PrependResumeHook(func(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+		if typ != jobspb.TypeScheduledExecution {
+			return nil
+		}
+		return fakeScheduledResumer{started: started, results: results}
+	})
+
+	// Two registries, standing in for two nodes, share a manual clock, which
+	// the test advances to make schedules due.
+	mClock := hlc.NewManualClock(hlc.UnixNano())
+	clock := hlc.NewClock(mClock.UnixNano, time.Nanosecond)
+	now := func() time.Time { return timeutil.Unix(0, mClock.UnixNano()) }
+	nodeLiveness := jobs.NewFakeNodeLiveness(2)
+	var registries []*jobs.Registry
+	for _, id := range []roachpb.NodeID{1, 2} {
+		const cancelInterval = time.Duration(math.MaxInt64)
+		const adoptInterval = time.Duration(math.MaxInt64)
+
+		ac := log.AmbientContext{Tracer: tracing.NewTracer()}
+		nodeID := &base.NodeIDContainer{}
+		nodeID.Reset(id)
+		r := jobs.MakeRegistry(
+			ac, s.Stopper(), clock, s.DB(), s.InternalExecutor().(sqlutil.InternalExecutor),
+			nodeID, roachpb.Locality{}, s.ClusterSettings(), server.DefaultHistogramWindowInterval, jobs.FakePHS,
+		)
+		if err := r.Start(ctx, s.Stopper(), nodeLiveness, cancelInterval, adoptInterval); err != nil {
+			t.Fatal(err)
+		}
+		registries = append(registries, r)
+	}
+
+	// runScheduler runs a pass of the scheduler on every registry. Jobs for due
+	// schedules are created by the time it returns.
+	runScheduler := func() {
+		t.Helper()
+		for _, r := range registries {
+			if err := r.RunSchedules(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	numRuns := func() int {
+		t.Helper()
+		var n int
+		sqlDB.QueryRow(t,
+			`SELECT count(*) FROM crdb_internal.jobs WHERE job_type = 'SCHEDULED_EXECUTION'`,
+		).Scan(&n)
+		return n
+	}
+	expectRuns := func(expected int) {
+		t.Helper()
+		if n := numRuns(); n != expected {
+			t.Fatalf("expected %d scheduled runs, found %d", expected, n)
+		}
+	}
+	waitForStatus := func(jobID int64, expectedStatus jobs.Status) {
+		t.Helper()
+		testutils.SucceedsSoon(t, func() error {
+			var status string
+			sqlDB.QueryRow(t, `SELECT status FROM system.jobs WHERE id = $1`, jobID).Scan(&status)
+			if jobs.Status(status) != expectedStatus {
+				return errors.Errorf("expected job %d to be %s, but it is %s", jobID, expectedStatus, status)
+			}
+			return nil
+		})
+	}
+	// finishRun finishes the only running job with the given outcome.
+	finishRun := func(jobID int64, err error, expectedStatus jobs.Status) {
+		t.Helper()
+		results <- err
+		waitForStatus(jobID, expectedStatus)
+	}
+	type schedule struct {
+		paused, due bool
+		lastJobID   gosql.NullInt64
+		state       gosql.NullString
+	}
+	var scheduleID int64
+	loadSchedule := func() schedule {
+		t.Helper()
+		var sc schedule
+		sqlDB.QueryRow(t, `
+SELECT next_run IS NULL, COALESCE(next_run <= $2, false), last_job_id, state
+  FROM system.scheduled_jobs WHERE schedule_id = $1`,
+			scheduleID, now(),
+		).Scan(&sc.paused, &sc.due, &sc.lastJobID, &sc.state)
+		return sc
+	}
+	// createSchedule creates an hourly schedule with the given policies that
+	// is due in a minute.
+	createSchedule := func(onPreviousRunning, onExecutionFailure string) {
+		t.Helper()
+		sqlDB.QueryRow(t, `
+INSERT INTO system.scheduled_jobs
+  (schedule_name, owner, next_run, schedule_expr, execution_stmt, description,
+   on_previous_running, on_execution_failure)
+VALUES ('test', 'root', $1, '@hourly', 'SELECT 1', 'SELECT 1', $2, $3)
+RETURNING schedule_id`,
+			now().Add(time.Minute), onPreviousRunning, onExecutionFailure,
+		).Scan(&scheduleID)
+	}
+	dropSchedule := func() {
+		sqlDB.Exec(t, `DELETE FROM system.scheduled_jobs WHERE schedule_id = $1`, scheduleID)
+	}
+
+	// A due schedule starts exactly one job, even if several nodes find it due.
+	func() {
+		createSchedule(jobs.OnPreviousRunningWait, jobs.OnExecutionFailureReschedule)
+		defer dropSchedule()
+		runs := numRuns()
+
+		runScheduler()
+		expectRuns(runs)
+
+		// Both registries find the schedule due, but only one starts a job.
+		mClock.Increment(time.Minute.Nanoseconds())
+		runScheduler()
+		jobID := <-started
+		expectRuns(runs + 1)
+		if sc := loadSchedule(); sc.lastJobID.Int64 != jobID || sc.due {
+			t.Fatalf("expected schedule to have run job %d and not be due, got %+v", jobID, sc)
+		}
+
+		finishRun(jobID, nil, jobs.StatusSucceeded)
+		if e, a := fmt.Sprintf("job %d succeeded", jobID), loadSchedule().state.String; e != a {
+			t.Fatalf("expected state %q, got %q", e, a)
+		}
+	}()
+
+	// on_previous_running = 'wait' delays a run until the previous job finishes.
+	func() {
+		createSchedule(jobs.OnPreviousRunningWait, jobs.OnExecutionFailureReschedule)
+		defer dropSchedule()
+		runs := numRuns()
+
+		mClock.Increment(time.Minute.Nanoseconds())
+		runScheduler()
+		firstID := <-started
+
+		mClock.Increment(time.Hour.Nanoseconds())
+		runScheduler()
+		expectRuns(runs + 1)
+		sc := loadSchedule()
+		if e := fmt.Sprintf("waiting for job %d to finish", firstID); sc.state.String != e || !sc.due {
+			t.Fatalf("expected schedule to be due and %q, got %+v", e, sc)
+		}
+
+		finishRun(firstID, nil, jobs.StatusSucceeded)
+		runScheduler()
+		secondID := <-started
+		expectRuns(runs + 2)
+		finishRun(secondID, nil, jobs.StatusSucceeded)
+	}()
+
+	// on_previous_running = 'skip' skips a run while the previous job runs.
+	func() {
+		createSchedule(jobs.OnPreviousRunningSkip, jobs.OnExecutionFailureReschedule)
+		defer dropSchedule()
+		runs := numRuns()
+
+		mClock.Increment(time.Minute.Nanoseconds())
+		runScheduler()
+		firstID := <-started
+
+		mClock.Increment(time.Hour.Nanoseconds())
+		runScheduler()
+		expectRuns(runs + 1)
+		sc := loadSchedule()
+		if !strings.HasPrefix(sc.state.String, "skipped run") || sc.due {
+			t.Fatalf("expected run to be skipped until the next one, got %+v", sc)
+		}
+		finishRun(firstID, nil, jobs.StatusSucceeded)
+	}()
+
+	// on_previous_running = 'start' starts a run while the previous job runs.
+	func() {
+		createSchedule(jobs.OnPreviousRunningStart, jobs.OnExecutionFailureReschedule)
+		defer dropSchedule()
+		runs := numRuns()
+
+		mClock.Increment(time.Minute.Nanoseconds())
+		runScheduler()
+		firstID := <-started
+
+		mClock.Increment(time.Hour.Nanoseconds())
+		runScheduler()
+		secondID := <-started
+		expectRuns(runs + 2)
+		results <- nil
+		results <- nil
+		waitForStatus(firstID, jobs.StatusSucceeded)
+		waitForStatus(secondID, jobs.StatusSucceeded)
+	}()
+
+	// on_execution_failure = 'retry' runs a failed schedule again right away.
+	func() {
+		createSchedule(jobs.OnPreviousRunningWait, jobs.OnExecutionFailureRetry)
+		defer dropSchedule()
+		runs := numRuns()
+
+		mClock.Increment(time.Minute.Nanoseconds())
+		runScheduler()
+		firstID := <-started
+		finishRun(firstID, errors.New("boom"), jobs.StatusFailed)
+		sc := loadSchedule()
+		if e := fmt.Sprintf("job %d failed, retrying", firstID); sc.state.String != e || !sc.due {
+			t.Fatalf("expected schedule to be due and %q, got %+v", e, sc)
+		}
+
+		// The retry does not wait for the next hour.
+		runScheduler()
+		secondID := <-started
+		expectRuns(runs + 2)
+		finishRun(secondID, nil, jobs.StatusSucceeded)
+	}()
+
+	// on_execution_failure = 'reschedule' waits for the next run.
+	func() {
+		createSchedule(jobs.OnPreviousRunningWait, jobs.OnExecutionFailureReschedule)
+		defer dropSchedule()
+		runs := numRuns()
+
+		mClock.Increment(time.Minute.Nanoseconds())
+		runScheduler()
+		jobID := <-started
+		finishRun(jobID, errors.New("boom"), jobs.StatusFailed)
+		sc := loadSchedule()
+		if e := fmt.Sprintf("job %d failed", jobID); sc.state.String != e || sc.due {
+			t.Fatalf("expected schedule not to be due and %q, got %+v", e, sc)
+		}
+
+		runScheduler()
+		expectRuns(runs + 1)
+	}()
+
+	// on_execution_failure = 'pause' pauses the schedule.
+	func() {
+		createSchedule(jobs.OnPreviousRunningWait, jobs.OnExecutionFailurePause)
+		defer dropSchedule()
+		runs := numRuns()
+
+		mClock.Increment(time.Minute.Nanoseconds())
+		runScheduler()
+		jobID := <-started
+		finishRun(jobID, errors.New("boom"), jobs.StatusFailed)
+		sc := loadSchedule()
+		if e := fmt.Sprintf("job %d failed, schedule paused", jobID); sc.state.String != e || !sc.paused {
+			t.Fatalf("expected schedule to be paused and %q, got %+v", e, sc)
+		}
+
+		mClock.Increment(time.Hour.Nanoseconds())
+		runScheduler()
+		expectRuns(runs + 1)
+	}()
+}
@@ -329,6 +329,7 @@ const (
 	LivenessRangesID       = 22
 	RoleMembersTableID     = 23
 	CommentsTableID        = 24
+	ScheduledJobsTableID   = 25
 
 	// CommentType is type for system.comments
 	// DatabaseCommentType = 0
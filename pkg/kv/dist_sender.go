@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -455,9 +456,11 @@ func (ds *DistSender) sendSingleRange(
 	replicas := NewReplicaSlice(ds.gossip, desc)
 
 	// If this request needs to go to a lease holder and we know who that is, move
-	// it to the front.
+	// it to the front. Requests that can be served by a follower are instead
+	// sent to the nearest replica first.
 	var cachedLeaseHolder roachpb.ReplicaDescriptor
-	if ba.RequiresLeaseHolder() {
+	if ba.RequiresLeaseHolder() && !ds.canSendToFollower(ba) {
+		replicas = replicas.Voters()
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -494,6 +497,19 @@ func (ds *DistSender) sendSingleRange(
 	return br, pErr
 }
 
+// canSendToFollower returns whether the batch is a consistent, transactional,
+// read-only batch of a bounded staleness read, whose timestamp was chosen to be
+// servable by the nearest replica of each range, in which case that replica is
+// tried first. If it turns out to be unable to serve the batch, it redirects
+// the batch to the lease holder. All other batches go to the lease holder.
+func (ds *DistSender) canSendToFollower(ba roachpb.BatchRequest) bool {
+	if !ba.BoundedStaleness || ba.Txn == nil || !ba.IsReadOnly() ||
+		ba.ReadConsistency != roachpb.CONSISTENT {
+		return false
+	}
+	return closedts.FollowerReadsEnabled.Get(&ds.st.SV)
+}
+
 // initAndVerifyBatch initializes timestamp-related information and
 // verifies batch constraints before splitting.
 func (ds *DistSender) initAndVerifyBatch(
@@ -899,10 +915,15 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 			}
 			// If the request is more than but ends with EndTransaction, we
 			// want the caller to come again with the EndTransaction in an
-			// extra call.
+			// extra call. The exception is an EndTransaction performing a
+			// parallel commit, which is meant to be sent in parallel with the
+			// writes it depends on.
 			if l := len(ba.Requests) - 1; l > 0 && ba.Requests[l].GetInner().Method() == roachpb.EndTransaction {
-				responseCh <- response{pErr: errNo1PCTxn}
-				return
+				et := ba.Requests[l].GetInner().(*roachpb.EndTransactionRequest)
+				if len(et.InFlightWrites) == 0 {
+					responseCh <- response{pErr: errNo1PCTxn}
+					return
+				}
 			}
 		}
 
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -2579,3 +2580,62 @@ func TestMixedSuccessErrorWrapped(t *testing.T) {
 		t.Errorf("expected error index to be %d, instead got %d", 3, wrapped.Index.Index)
 	}
 }
+
+// TestDistSenderCanSendToFollower tests which batches DistSender considers
+// eligible for follower reads.
+func TestDistSenderCanSendToFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	manual := hlc.NewManualClock(100 * time.Second.Nanoseconds())
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+	st := cluster.MakeTestingClusterSettings()
+	g, _ := makeGossip(t, stopper)
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:      clock,
+		Settings:   st,
+	}, g)
+
+	ts := hlc.Timestamp{WallTime: 80 * time.Second.Nanoseconds()}
+	testCases := []struct {
+		name    string
+		enabled bool
+		bounded bool
+		txn     bool
+		write   bool
+		incons  bool
+		exp     bool
+	}{
+		{name: "disabled", enabled: false, bounded: true, txn: true},
+		{name: "bounded staleness read", enabled: true, bounded: true, txn: true, exp: true},
+		{name: "historical read", enabled: true, txn: true},
+		{name: "non-transactional", enabled: true, bounded: true},
+		{name: "write", enabled: true, bounded: true, txn: true, write: true},
+		{name: "inconsistent", enabled: true, bounded: true, txn: true, incons: true},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			closedts.FollowerReadsEnabled.Override(&st.SV, c.enabled)
+			var ba roachpb.BatchRequest
+			ba.Timestamp = ts
+			ba.BoundedStaleness = c.bounded
+			if c.txn {
+				txn := roachpb.MakeTransaction("test", roachpb.Key("a"), roachpb.NormalUserPriority, ts, 0)
+				ba.Txn = &txn
+			}
+			if c.incons {
+				ba.ReadConsistency = roachpb.INCONSISTENT
+			}
+			if c.write {
+				ba.Add(roachpb.NewPut(roachpb.Key("a"), roachpb.MakeValueFromString("v")))
+			} else {
+				ba.Add(roachpb.NewGet(roachpb.Key("a")))
+			}
+			if act := ds.canSendToFollower(ba); act != c.exp {
+				t.Errorf("expected %t, got %t", c.exp, act)
+			}
+		})
+	}
+}
@@ -87,6 +87,19 @@ func (rs ReplicaSlice) FindReplica(storeID roachpb.StoreID) int {
 	return -1
 }
 
+// Voters returns the replicas in the slice that participate in quorum, keeping
+// their order. Non-voting replicas can never hold the lease, so requests that
+// must be served by the lease holder are only sent to voters.
+func (rs ReplicaSlice) Voters() ReplicaSlice {
+	voters := rs[:0:0]
+	for _, r := range rs {
+		if r.IsVoter() {
+			voters = append(voters, r)
+		}
+	}
+	return voters
+}
+
 // MoveToFront moves the replica at the given index to the front
 // of the slice, keeping the order of the remaining elements stable.
 // The function will panic when invoked with an invalid index.
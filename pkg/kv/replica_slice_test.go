@@ -101,6 +101,22 @@ func info(t *testing.T, nid roachpb.NodeID, sid roachpb.StoreID, locStrs []strin
 	}
 }
 
+func TestReplicaSliceVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rs := createReplicaSlice()
+	nonVoter := roachpb.NON_VOTER
+	rs[1].Type = &nonVoter
+	rs[3].Type = &nonVoter
+	exp := []roachpb.StoreID{1, 3, 5}
+	if stores := getStores(rs.Voters()); !reflect.DeepEqual(stores, exp) {
+		t.Errorf("expected voters %s, got %s", exp, stores)
+	}
+	exp = []roachpb.StoreID{1, 2, 3, 4, 5}
+	if stores := getStores(rs); !reflect.DeepEqual(stores, exp) {
+		t.Errorf("expected original slice %s to be unchanged, got %s", exp, stores)
+	}
+}
+
 func TestReplicaSliceOptimizeReplicaOrder(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	testCases := []struct {
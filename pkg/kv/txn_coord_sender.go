@@ -141,12 +141,13 @@ type TxnCoordSender struct {
 	// is embedded in the interceptorAlloc struct, so the entire stack is
 	// allocated together with TxnCoordSender without any additional heap
 	// allocations necessary.
-	interceptorStack [6]txnInterceptor
+	interceptorStack [7]txnInterceptor
 	interceptorAlloc struct {
 		txnHeartbeat
 		txnIntentCollector
 		txnPipeliner
 		txnSpanRefresher
+		txnCommitter
 		txnSeqNumAllocator
 		txnMetrics
 		txnLockGatekeeper // not in interceptorStack array.
@@ -234,11 +235,12 @@ func (gs *txnLockGatekeeper) SendLocked(
 
 // TxnMetrics holds all metrics relating to KV transactions.
 type TxnMetrics struct {
-	Aborts      *metric.Counter
-	Commits     *metric.Counter
-	Commits1PC  *metric.Counter // Commits which finished in a single phase
-	AutoRetries *metric.Counter // Auto retries which avoid client-side restarts
-	Durations   *metric.Histogram
+	Aborts          *metric.Counter
+	Commits         *metric.Counter
+	Commits1PC      *metric.Counter // Commits which finished in a single phase
+	ParallelCommits *metric.Counter // Commits which entered the STAGING state
+	AutoRetries     *metric.Counter // Auto retries which avoid client-side restarts
+	Durations       *metric.Histogram
 
 	// Restarts is the number of times we had to restart the transaction.
 	Restarts *metric.Histogram
@@ -272,6 +274,12 @@ var (
 		Measurement: "KV Transactions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaParallelCommitsRates = metric.Metadata{
+		Name:        "txn.parallelcommits",
+		Help:        "Number of KV transaction parallel commit attempts",
+		Measurement: "KV Transactions",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaAutoRetriesRates = metric.Metadata{
 		Name:        "txn.autoretries",
 		Help:        "Number of automatic retries to avoid serializable restarts",
@@ -323,6 +331,7 @@ func MakeTxnMetrics(histogramWindow time.Duration) TxnMetrics {
 		Aborts:                    metric.NewCounter(metaAbortsRates),
 		Commits:                   metric.NewCounter(metaCommitsRates),
 		Commits1PC:                metric.NewCounter(metaCommits1PCRates),
+		ParallelCommits:           metric.NewCounter(metaParallelCommitsRates),
 		AutoRetries:               metric.NewCounter(metaAutoRetriesRates),
 		Durations:                 metric.NewLatency(metaDurationsHistograms, histogramWindow),
 		Restarts:                  metric.NewHistogram(metaRestartsHistogram, histogramWindow, 100, 3),
@@ -442,6 +451,12 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		canAutoRetry:     typ == client.RootTxn,
 		autoRetryCounter: tcs.metrics.AutoRetries,
 	}
+	tcs.interceptorAlloc.txnCommitter = txnCommitter{
+		st:      tcf.st,
+		stopper: tcs.stopper,
+		metrics: &tcs.metrics,
+		mu:      &tcs.mu.Mutex,
+	}
 	tcs.interceptorAlloc.txnLockGatekeeper = txnLockGatekeeper{
 		wrapped: tcs.wrapped,
 		mu:      &tcs.mu,
@@ -456,6 +471,10 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		&tcs.interceptorAlloc.txnIntentCollector,
 		&tcs.interceptorAlloc.txnPipeliner,
 		&tcs.interceptorAlloc.txnSpanRefresher,
+		// The committer is below the txnSpanRefresher so that a parallel commit
+		// that fails because its in-flight writes were pushed can be refreshed
+		// and retried by the txnSpanRefresher.
+		&tcs.interceptorAlloc.txnCommitter,
 		&tcs.interceptorAlloc.txnMetrics,
 	}
 	for i, reqInt := range tcs.interceptorStack {
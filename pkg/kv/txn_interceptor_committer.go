@@ -0,0 +1,273 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logtags"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+var parallelCommitsEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.parallel_commits_enabled",
+	"if enabled, transactional commits will be parallelized with transactional writes",
+	true,
+)
+
+// txnCommitter is a txnInterceptor that concerns itself with committing and
+// rolling back transactions. It intercepts EndTransaction requests and
+// coordinates their execution. This is accomplished either by issuing them
+// directly with proper addressing or by performing a parallel commit.
+//
+// A parallel commit allows a transaction to commit in a single round-trip of
+// distributed consensus, even when its final batch of writes spans multiple
+// ranges. To do so, the EndTransaction request is augmented with the set of
+// writes that must succeed for the transaction to be considered committed.
+// These writes are referred to as the transaction's "in-flight writes" and
+// consist of the transaction's outstanding pipelined writes, which the
+// txnPipeliner proves using QueryIntent requests, as well as the point writes
+// in the committing batch. The EndTransaction request then moves the
+// transaction record to a new STAGING status instead of the COMMITTED status,
+// and the DistSender is free to send it in parallel with the in-flight writes.
+//
+// A transaction whose record is STAGING is "implicitly committed" if all of
+// its in-flight writes succeeded at or below the staging timestamp. When the
+// txnCommitter observes that this is the case, it returns a COMMITTED
+// transaction to the client immediately and then moves the record to the
+// COMMITTED status asynchronously, which is referred to as "explicitly
+// committing" the transaction. If the coordinator fails before doing so,
+// other transactions that encounter the STAGING record recover it by querying
+// its in-flight writes (see txnwait.Queue.RecoverIndeterminateCommit).
+type txnCommitter struct {
+	st      *cluster.Settings
+	stopper *stop.Stopper
+	metrics *TxnMetrics
+	wrapped lockedSender
+	mu      sync.Locker
+}
+
+// SendLocked implements the lockedSender interface.
+func (tc *txnCommitter) SendLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	// If the batch does not include an EndTransaction request that can be
+	// performed as a parallel commit, pass it through.
+	rArgs, hasET := ba.GetArg(roachpb.EndTransaction)
+	if !hasET || !tc.canCommitInParallel(ba, rArgs.(*roachpb.EndTransactionRequest)) {
+		return tc.wrapped.SendLocked(ctx, ba)
+	}
+	et := rArgs.(*roachpb.EndTransactionRequest)
+
+	// Determine the set of in-flight writes that the parallel commit will
+	// depend on. If there are none then there's nothing to parallelize with.
+	inFlightWrites := collectInFlightWrites(ba)
+	if len(inFlightWrites) == 0 {
+		return tc.wrapped.SendLocked(ctx, ba)
+	}
+
+	// Clone the EndTransaction request before modifying it. We don't want to
+	// modify the batch's request slice directly, so fork it as well.
+	etCpy := *et
+	etCpy.InFlightWrites = inFlightWrites
+	ba.Requests = append([]roachpb.RequestUnion(nil), ba.Requests...)
+	ba.Requests[len(ba.Requests)-1].MustSetInner(&etCpy)
+
+	// Send the adjusted batch through the wrapped lockedSender. Unlocks while
+	// sending then re-locks.
+	br, pErr := tc.wrapped.SendLocked(ctx, ba)
+	if pErr != nil {
+		// If the batch resulted in an error but the EndTransaction request
+		// succeeded, staging the transaction record in the process, downgrade
+		// the status back to PENDING. Even though the transaction record may
+		// have a status of STAGING, we know that the transaction failed to
+		// implicitly commit, so interceptors above the txnCommitter in the
+		// stack don't need to be made aware that the record is staging.
+		if txn := pErr.GetTxn(); txn != nil && txn.Status == roachpb.STAGING {
+			pErr.SetTxn(downgradeStagingTxn(txn))
+		}
+		return nil, pErr
+	}
+
+	// Determine next steps based on the status of the transaction.
+	switch br.Txn.Status {
+	case roachpb.STAGING:
+		// Continue with STAGING-specific validation and cleanup.
+		tc.metrics.ParallelCommits.Inc(1)
+	default:
+		// The transaction has been finalized or was not staged. Either way,
+		// there's nothing left to do.
+		return br, nil
+	}
+
+	// If the transaction's timestamp was pushed by any of its in-flight writes
+	// then it is not implicitly committed at the staging timestamp. Send an
+	// EndTransaction request without any in-flight writes to move the record
+	// out of the STAGING status. This will either commit the transaction or
+	// return a retry error, which the txnSpanRefresher may be able to handle.
+	if ba.Txn.Timestamp.Less(br.Txn.Timestamp) {
+		log.VEventf(ctx, 2, "parallel commit failed; txn timestamp pushed to %s", br.Txn.Timestamp)
+		br.Txn = downgradeStagingTxn(br.Txn)
+		return tc.sendExplicitCommitLocked(ctx, br.Txn, et)
+	}
+
+	// The transaction is implicitly committed. Report it to the client as
+	// committed and asynchronously make the commit explicit.
+	tc.makeTxnCommitExplicitAsync(ctx, downgradeStagingTxn(br.Txn), et)
+	br.Txn.Status = roachpb.COMMITTED
+	br.Txn.InFlightWrites = nil
+	return br, nil
+}
+
+// canCommitInParallel determines whether the batch can issue its committing
+// EndTransaction in parallel with its in-flight writes.
+func (tc *txnCommitter) canCommitInParallel(
+	ba roachpb.BatchRequest, et *roachpb.EndTransactionRequest,
+) bool {
+	if !parallelCommitsEnabled.Get(&tc.st.SV) ||
+		!tc.st.Version.IsActive(cluster.VersionParallelCommits) {
+		return false
+	}
+	// Only committing EndTransaction requests can be performed in parallel.
+	if !et.Commit {
+		return false
+	}
+	// Transactions with commit triggers perform additional work during their
+	// commit, so they can't be committed implicitly.
+	if et.InternalCommitTrigger != nil {
+		return false
+	}
+	// Transactions that require a one-phase commit or that are attempting one
+	// don't need a parallel commit.
+	if et.Require1PC {
+		return false
+	}
+	for _, ru := range ba.Requests[:len(ba.Requests)-1] {
+		req := ru.GetInner()
+		switch {
+		case req.Method() == roachpb.BeginTransaction:
+			// The transaction record must be written before the transaction
+			// can stage, so the batch is a candidate for the 1PC fast-path
+			// instead.
+			return false
+		case req.Method() == roachpb.QueryIntent:
+			// QueryIntent requests are compatible with parallel commits. The
+			// intents being queried are also attached to the EndTransaction
+			// request's InFlightWrites set and are visible to the status
+			// resolution process for STAGING transactions.
+		case roachpb.IsTransactionWrite(req) && !roachpb.IsRange(req):
+			// Similarly, point writes are compatible with parallel commits.
+		default:
+			// Reads and ranged writes are not compatible, because their
+			// results can't be verified by a recovery process.
+			return false
+		}
+	}
+	return true
+}
+
+// collectInFlightWrites returns the set of writes that a parallel commit of
+// the provided batch will depend on.
+func collectInFlightWrites(ba roachpb.BatchRequest) []roachpb.SequencedWrite {
+	var writes []roachpb.SequencedWrite
+	for _, ru := range ba.Requests[:len(ba.Requests)-1] {
+		req := ru.GetInner()
+		h := req.Header()
+		if qi, ok := req.(*roachpb.QueryIntentRequest); ok {
+			writes = append(writes, roachpb.SequencedWrite{Key: h.Key, Sequence: qi.Txn.Sequence})
+		} else {
+			writes = append(writes, roachpb.SequencedWrite{Key: h.Key, Sequence: h.Sequence})
+		}
+	}
+	return writes
+}
+
+// sendExplicitCommitLocked sends an EndTransaction request that moves the
+// transaction record out of the STAGING status.
+func (tc *txnCommitter) sendExplicitCommitLocked(
+	ctx context.Context, txn *roachpb.Transaction, et *roachpb.EndTransactionRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	return tc.wrapped.SendLocked(ctx, makeExplicitCommitBatch(txn, et))
+}
+
+// makeTxnCommitExplicitAsync launches an async task that sends an
+// EndTransaction request to move the transaction record from the STAGING
+// status to the COMMITTED status and resolve the transaction's intents.
+func (tc *txnCommitter) makeTxnCommitExplicitAsync(
+	ctx context.Context, txn *roachpb.Transaction, et *roachpb.EndTransactionRequest,
+) {
+	log.VEventf(ctx, 2, "making txn commit explicit: %s", txn)
+	ba := makeExplicitCommitBatch(txn, et)
+
+	// NB: We use a fresh context here because we don't want a canceled
+	// context to interrupt the commit.
+	asyncCtx := logtags.WithTags(context.Background(), logtags.FromContext(ctx))
+	if err := tc.stopper.RunAsyncTask(
+		asyncCtx, "txnCommitter: making txn commit explicit", func(ctx context.Context) {
+			tc.mu.Lock()
+			defer tc.mu.Unlock()
+			if _, pErr := tc.wrapped.SendLocked(ctx, ba); pErr != nil {
+				log.VErrEventf(ctx, 1, "making txn commit explicit failed for %s: %v", txn, pErr)
+			}
+		},
+	); err != nil {
+		log.VErrEventf(ctx, 1, "failed to make txn commit explicit: %v", err)
+	}
+}
+
+// makeExplicitCommitBatch returns a batch with a committing EndTransaction
+// request that carries no in-flight writes.
+func makeExplicitCommitBatch(
+	txn *roachpb.Transaction, et *roachpb.EndTransactionRequest,
+) roachpb.BatchRequest {
+	ba := roachpb.BatchRequest{}
+	ba.Txn = txn
+	ba.Add(&roachpb.EndTransactionRequest{
+		RequestHeader: roachpb.RequestHeader{Key: txn.Key},
+		Commit:        true,
+		Deadline:      et.Deadline,
+		IntentSpans:   et.IntentSpans,
+	})
+	return ba
+}
+
+// downgradeStagingTxn returns a clone of the provided STAGING transaction with
+// its status reset to PENDING and its in-flight writes removed.
+func downgradeStagingTxn(txn *roachpb.Transaction) *roachpb.Transaction {
+	txnCpy := txn.Clone()
+	txnCpy.Status = roachpb.PENDING
+	txnCpy.InFlightWrites = nil
+	return &txnCpy
+}
+
+// setWrapped implements the txnInterceptor interface.
+func (tc *txnCommitter) setWrapped(wrapped lockedSender) { tc.wrapped = wrapped }
+
+// populateMetaLocked implements the txnReqInterceptor interface.
+func (tc *txnCommitter) populateMetaLocked(meta *roachpb.TxnCoordMeta) {}
+
+// augmentMetaLocked implements the txnReqInterceptor interface.
+func (tc *txnCommitter) augmentMetaLocked(meta roachpb.TxnCoordMeta) {}
+
+// epochBumpedLocked implements the txnReqInterceptor interface.
+func (tc *txnCommitter) epochBumpedLocked() {}
+
+// closeLocked implements the txnReqInterceptor interface.
+func (tc *txnCommitter) closeLocked() {}
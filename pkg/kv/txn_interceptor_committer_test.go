@@ -0,0 +1,188 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+func makeMockTxnCommitter() (txnCommitter, *mockLockedSender, *stop.Stopper) {
+	mockSender := &mockLockedSender{}
+	metrics := MakeTxnMetrics(metric.TestSampleInterval)
+	stopper := stop.NewStopper()
+	return txnCommitter{
+		st:      cluster.MakeTestingClusterSettings(),
+		stopper: stopper,
+		metrics: &metrics,
+		wrapped: mockSender,
+		mu:      new(syncutil.Mutex),
+	}, mockSender, stopper
+}
+
+// TestTxnCommitterAttachesInFlightWrites tests that the txnCommitter attaches
+// the batch's point writes and queried intents to a committing EndTransaction
+// request, and that it makes an implicitly committed transaction's commit
+// explicit asynchronously.
+func TestTxnCommitterAttachesInFlightWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc, mockSender, stopper := makeMockTxnCommitter()
+	defer stopper.Stop(ctx)
+
+	txn := makeTxnProto()
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	qiArgs := roachpb.QueryIntentRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}}
+	qiArgs.Txn.Sequence = 1
+	putArgs := roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyB}}
+	putArgs.Sequence = 2
+	etArgs := roachpb.EndTransactionRequest{Commit: true}
+	etArgs.IntentSpans = []roachpb.Span{{Key: keyA}, {Key: keyB}}
+	ba.Add(&qiArgs, &putArgs, &etArgs)
+
+	explicitCommitC := make(chan struct{})
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Equal(t, 3, len(ba.Requests))
+		require.IsType(t, &roachpb.EndTransactionRequest{}, ba.Requests[2].GetInner())
+
+		et := ba.Requests[2].GetInner().(*roachpb.EndTransactionRequest)
+		require.True(t, et.Commit)
+		expInFlight := []roachpb.SequencedWrite{
+			{Key: keyA, Sequence: 1},
+			{Key: keyB, Sequence: 2},
+		}
+		require.Equal(t, expInFlight, et.InFlightWrites)
+
+		br := ba.CreateReply()
+		clonedTxn := ba.Txn.Clone()
+		br.Txn = &clonedTxn
+		br.Txn.Status = roachpb.STAGING
+		return br, nil
+	})
+
+	// The TxnCoordSender lock is held while sending, which prevents the async
+	// explicit commit from being sent until it is released.
+	tc.mu.Lock()
+	br, pErr := tc.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, roachpb.COMMITTED, br.Txn.Status)
+	require.Equal(t, int64(1), tc.metrics.ParallelCommits.Count())
+
+	// The original request must not have been modified.
+	require.Len(t, etArgs.InFlightWrites, 0)
+
+	// Verify that the explicit commit is sent asynchronously.
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		defer close(explicitCommitC)
+		require.Equal(t, 1, len(ba.Requests))
+		require.IsType(t, &roachpb.EndTransactionRequest{}, ba.Requests[0].GetInner())
+
+		et := ba.Requests[0].GetInner().(*roachpb.EndTransactionRequest)
+		require.True(t, et.Commit)
+		require.Len(t, et.InFlightWrites, 0)
+		require.Equal(t, etArgs.IntentSpans, et.IntentSpans)
+		require.Equal(t, roachpb.PENDING, ba.Txn.Status)
+
+		br := ba.CreateReply()
+		clonedTxn := ba.Txn.Clone()
+		br.Txn = &clonedTxn
+		br.Txn.Status = roachpb.COMMITTED
+		return br, nil
+	})
+	tc.mu.Unlock()
+	<-explicitCommitC
+}
+
+// TestTxnCommitterPassesThrough tests that batches which can't be committed in
+// parallel are passed through the txnCommitter untouched.
+func TestTxnCommitterPassesThrough(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc, mockSender, stopper := makeMockTxnCommitter()
+	defer stopper.Stop(ctx)
+
+	txn := makeTxnProto()
+	keyA := roachpb.Key("a")
+
+	testCases := []struct {
+		name string
+		reqs []roachpb.Request
+	}{
+		{
+			name: "1PC",
+			reqs: []roachpb.Request{
+				&roachpb.BeginTransactionRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}},
+				&roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}},
+				&roachpb.EndTransactionRequest{Commit: true},
+			},
+		},
+		{
+			name: "rollback",
+			reqs: []roachpb.Request{
+				&roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}},
+				&roachpb.EndTransactionRequest{Commit: false},
+			},
+		},
+		{
+			name: "ranged write",
+			reqs: []roachpb.Request{
+				&roachpb.DeleteRangeRequest{RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyA.Next()}},
+				&roachpb.EndTransactionRequest{Commit: true},
+			},
+		},
+		{
+			name: "no in-flight writes",
+			reqs: []roachpb.Request{
+				&roachpb.EndTransactionRequest{Commit: true},
+			},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			var ba roachpb.BatchRequest
+			ba.Header = roachpb.Header{Txn: &txn}
+			ba.Add(c.reqs...)
+
+			mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+				require.Equal(t, len(c.reqs), len(ba.Requests))
+				et := ba.Requests[len(ba.Requests)-1].GetInner().(*roachpb.EndTransactionRequest)
+				require.Len(t, et.InFlightWrites, 0)
+
+				br := ba.CreateReply()
+				clonedTxn := ba.Txn.Clone()
+				br.Txn = &clonedTxn
+				br.Txn.Status = roachpb.COMMITTED
+				return br, nil
+			})
+
+			br, pErr := tc.SendLocked(ctx, ba)
+			require.Nil(t, pErr)
+			require.NotNil(t, br)
+		})
+	}
+	require.Equal(t, int64(0), tc.metrics.ParallelCommits.Count())
+}
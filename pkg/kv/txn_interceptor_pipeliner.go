@@ -121,11 +121,11 @@ var pipelinedWritesMaxBatchSize = settings.RegisterNonNegativeIntSetting(
 //    they finish consensus without any extra RPCs.
 // So far, none of these approaches have been integrated.
 //
-// [1] A proposal called "parallel commits" (#24194) exists that would allow all
-//     QueryIntent requests and the EndTransaction request that they are prepended
-//     to to be sent by the DistSender in parallel. This would help with this
-//     issue by hiding the cost of the QueryIntent requests behind the cost of the
-//     "staging" EndTransaction request.
+// [1] The txnCommitter can perform a "parallel commit" (#24194), which allows
+//     all QueryIntent requests and the EndTransaction request that they are
+//     prepended to to be sent by the DistSender in parallel. This helps with
+//     this issue by hiding the cost of the QueryIntent requests behind the cost
+//     of the "staging" EndTransaction request.
 //
 type txnPipeliner struct {
 	st       *cluster.Settings
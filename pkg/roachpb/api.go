@@ -320,6 +320,22 @@ func (cc *CheckConsistencyResponse) combine(c combinable) error {
 
 var _ combinable = &CheckConsistencyResponse{}
 
+// Combine implements the combinable interface.
+func (r *QueryResolvedTimestampResponse) combine(c combinable) error {
+	if r != nil {
+		otherR := c.(*QueryResolvedTimestampResponse)
+		if err := r.ResponseHeader.combine(otherR.Header()); err != nil {
+			return err
+		}
+		if otherR.ResolvedTS.Less(r.ResolvedTS) {
+			r.ResolvedTS = otherR.ResolvedTS
+		}
+	}
+	return nil
+}
+
+var _ combinable = &QueryResolvedTimestampResponse{}
+
 // Combine implements the combinable interface.
 func (er *ExportResponse) combine(c combinable) error {
 	if er != nil {
@@ -482,6 +498,9 @@ func (*DeleteRangeRequest) Method() Method { return DeleteRange }
 // Method implements the Request interface.
 func (*ClearRangeRequest) Method() Method { return ClearRange }
 
+// Method implements the Request interface.
+func (*RevertRangeRequest) Method() Method { return RevertRange }
+
 // Method implements the Request interface.
 func (*ScanRequest) Method() Method { return Scan }
 
@@ -524,6 +543,9 @@ func (*PushTxnRequest) Method() Method { return PushTxn }
 // Method implements the Request interface.
 func (*QueryTxnRequest) Method() Method { return QueryTxn }
 
+// Method implements the Request interface.
+func (*RecoverTxnRequest) Method() Method { return RecoverTxn }
+
 // Method implements the Request interface.
 func (*QueryIntentRequest) Method() Method { return QueryIntent }
 
@@ -581,6 +603,9 @@ func (*SubsumeRequest) Method() Method { return Subsume }
 // Method implements the Request interface.
 func (*RangeStatsRequest) Method() Method { return RangeStats }
 
+// Method implements the Request interface.
+func (*QueryResolvedTimestampRequest) Method() Method { return QueryResolvedTimestamp }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -629,6 +654,12 @@ func (crr *ClearRangeRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (crr *RevertRangeRequest) ShallowCopy() Request {
+	shallowCopy := *crr
+	return &shallowCopy
+}
+
 // ShallowCopy implements the Request interface.
 func (sr *ScanRequest) ShallowCopy() Request {
 	shallowCopy := *sr
@@ -713,6 +744,12 @@ func (qtr *QueryTxnRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (rtr *RecoverTxnRequest) ShallowCopy() Request {
+	shallowCopy := *rtr
+	return &shallowCopy
+}
+
 // ShallowCopy implements the Request interface.
 func (pir *QueryIntentRequest) ShallowCopy() Request {
 	shallowCopy := *pir
@@ -827,6 +864,12 @@ func (r *RangeStatsRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *QueryResolvedTimestampRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key.
 func NewGet(key Key) Request {
 	return &GetRequest{
@@ -1030,6 +1073,7 @@ func (*HeartbeatTxnRequest) flags() int        { return isWrite | isTxn }
 func (*GCRequest) flags() int                  { return isWrite | isRange }
 func (*PushTxnRequest) flags() int             { return isWrite | isAlone }
 func (*QueryTxnRequest) flags() int            { return isRead | isAlone }
+func (*RecoverTxnRequest) flags() int          { return isWrite | isAlone }
 
 // QueryIntent only updates the read timestamp cache when attempting
 // to prevent an intent that is found missing from ever being written
@@ -1081,6 +1125,12 @@ func (*SubsumeRequest) flags() int { return isRead | isAlone | updatesReadTSCach
 
 func (*RangeStatsRequest) flags() int { return isRead }
 
+func (*QueryResolvedTimestampRequest) flags() int { return isRead | isRange }
+
+// Note that RevertRange commands cannot be part of a transaction as
+// they clear MVCC versions.
+func (*RevertRangeRequest) flags() int { return isWrite | isRange | isAlone }
+
 // Keys returns credentials in an aws.Config.
 func (b *ExportStorage_S3) Keys() *aws.Config {
 	return &aws.Config{
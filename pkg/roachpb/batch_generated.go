@@ -70,6 +70,8 @@ func (ru ErrorDetail) GetInner() error {
 		return t.MergeInProgress
 	case *ErrorDetail_RangefeedRetry:
 		return t.RangefeedRetry
+	case *ErrorDetail_IndeterminateCommit:
+		return t.IndeterminateCommit
 	default:
 		return nil
 	}
@@ -160,6 +162,12 @@ func (ru RequestUnion) GetInner() Request {
 		return t.Subsume
 	case *RequestUnion_RangeStats:
 		return t.RangeStats
+	case *RequestUnion_RecoverTxn:
+		return t.RecoverTxn
+	case *RequestUnion_QueryResolvedTimestamp:
+		return t.QueryResolvedTimestamp
+	case *RequestUnion_RevertRange:
+		return t.RevertRange
 	default:
 		return nil
 	}
@@ -248,6 +256,12 @@ func (ru ResponseUnion) GetInner() Response {
 		return t.Subsume
 	case *ResponseUnion_RangeStats:
 		return t.RangeStats
+	case *ResponseUnion_RecoverTxn:
+		return t.RecoverTxn
+	case *ResponseUnion_QueryResolvedTimestamp:
+		return t.QueryResolvedTimestamp
+	case *ResponseUnion_RevertRange:
+		return t.RevertRange
 	default:
 		return nil
 	}
@@ -315,6 +329,8 @@ func (ru *ErrorDetail) SetInner(r error) bool {
 		union = &ErrorDetail_MergeInProgress{t}
 	case *RangeFeedRetryError:
 		union = &ErrorDetail_RangefeedRetry{t}
+	case *IndeterminateCommitError:
+		union = &ErrorDetail_IndeterminateCommit{t}
 	default:
 		return false
 	}
@@ -408,6 +424,12 @@ func (ru *RequestUnion) SetInner(r Request) bool {
 		union = &RequestUnion_Subsume{t}
 	case *RangeStatsRequest:
 		union = &RequestUnion_RangeStats{t}
+	case *RecoverTxnRequest:
+		union = &RequestUnion_RecoverTxn{t}
+	case *QueryResolvedTimestampRequest:
+		union = &RequestUnion_QueryResolvedTimestamp{t}
+	case *RevertRangeRequest:
+		union = &RequestUnion_RevertRange{t}
 	default:
 		return false
 	}
@@ -499,6 +521,12 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 		union = &ResponseUnion_Subsume{t}
 	case *RangeStatsResponse:
 		union = &ResponseUnion_RangeStats{t}
+	case *RecoverTxnResponse:
+		union = &ResponseUnion_RecoverTxn{t}
+	case *QueryResolvedTimestampResponse:
+		union = &ResponseUnion_QueryResolvedTimestamp{t}
+	case *RevertRangeResponse:
+		union = &ResponseUnion_RevertRange{t}
 	default:
 		return false
 	}
@@ -506,7 +534,7 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 	return true
 }
 
-type reqCounts [41]int32
+type reqCounts [44]int32
 
 // getReqCounts returns the number of times each
 // request type appears in the batch.
@@ -596,6 +624,12 @@ func (ba *BatchRequest) getReqCounts() reqCounts {
 			counts[39]++
 		case *RequestUnion_RangeStats:
 			counts[40]++
+		case *RequestUnion_RecoverTxn:
+			counts[41]++
+		case *RequestUnion_QueryResolvedTimestamp:
+			counts[42]++
+		case *RequestUnion_RevertRange:
+			counts[43]++
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", ru))
 		}
@@ -645,6 +679,9 @@ var requestNames = []string{
 	"RefreshRng",
 	"Subsume",
 	"RngStats",
+	"RecoverTxn",
+	"QueryResolvedTimestamp",
+	"RevertRng",
 }
 
 // Summary prints a short summary of the requests in a batch.
@@ -840,6 +877,18 @@ type rangeStatsResponseAlloc struct {
 	union ResponseUnion_RangeStats
 	resp  RangeStatsResponse
 }
+type recoverTxnResponseAlloc struct {
+	union ResponseUnion_RecoverTxn
+	resp  RecoverTxnResponse
+}
+type queryResolvedTimestampResponseAlloc struct {
+	union ResponseUnion_QueryResolvedTimestamp
+	resp  QueryResolvedTimestampResponse
+}
+type revertRangeResponseAlloc struct {
+	union ResponseUnion_RevertRange
+	resp  RevertRangeResponse
+}
 
 // CreateReply creates replies for each of the contained requests, wrapped in a
 // BatchResponse. The response objects are batch allocated to minimize
@@ -891,6 +940,9 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 	var buf38 []refreshRangeResponseAlloc
 	var buf39 []subsumeResponseAlloc
 	var buf40 []rangeStatsResponseAlloc
+	var buf41 []recoverTxnResponseAlloc
+	var buf42 []queryResolvedTimestampResponseAlloc
+	var buf43 []revertRangeResponseAlloc
 
 	for i, r := range ba.Requests {
 		switch r.GetValue().(type) {
@@ -1181,6 +1233,27 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 			buf40[0].union.RangeStats = &buf40[0].resp
 			br.Responses[i].Value = &buf40[0].union
 			buf40 = buf40[1:]
+		case *RequestUnion_RecoverTxn:
+			if buf41 == nil {
+				buf41 = make([]recoverTxnResponseAlloc, counts[41])
+			}
+			buf41[0].union.RecoverTxn = &buf41[0].resp
+			br.Responses[i].Value = &buf41[0].union
+			buf41 = buf41[1:]
+		case *RequestUnion_QueryResolvedTimestamp:
+			if buf42 == nil {
+				buf42 = make([]queryResolvedTimestampResponseAlloc, counts[42])
+			}
+			buf42[0].union.QueryResolvedTimestamp = &buf42[0].resp
+			br.Responses[i].Value = &buf42[0].union
+			buf42 = buf42[1:]
+		case *RequestUnion_RevertRange:
+			if buf43 == nil {
+				buf43 = make([]revertRangeResponseAlloc, counts[43])
+			}
+			buf43[0].union.RevertRange = &buf43[0].resp
+			br.Responses[i].Value = &buf43[0].union
+			buf43 = buf43[1:]
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", r))
 		}
@@ -808,6 +808,7 @@ func (t Transaction) Clone() Transaction {
 	// Note that we're not cloning the span keys under the assumption that the
 	// keys themselves are not mutable.
 	t.Intents = append([]Span(nil), t.Intents...)
+	t.InFlightWrites = append([]SequencedWrite(nil), t.InFlightWrites...)
 	return t
 }
 
@@ -955,6 +956,14 @@ func (t *Transaction) InclusiveTimeBounds() (hlc.Timestamp, hlc.Timestamp) {
 	return min, max
 }
 
+// IsFinalized determines whether the transaction status is in a terminal
+// state. A transaction in a terminal state will never transition to another
+// state. Note that a STAGING transaction is not finalized, even if it is
+// implicitly committed.
+func (ts TransactionStatus) IsFinalized() bool {
+	return ts == COMMITTED || ts == ABORTED
+}
+
 // Update ratchets priority, timestamp and original timestamp values (among
 // others) for the transaction. If t.ID is empty, then the transaction is
 // copied from o.
@@ -970,7 +979,15 @@ func (t *Transaction) Update(o *Transaction) {
 	if len(t.Key) == 0 {
 		t.Key = o.Key
 	}
-	if o.Status != PENDING {
+	switch o.Status {
+	case PENDING:
+		// Nothing to do.
+	case STAGING:
+		// A STAGING status must not regress a finalized transaction.
+		if !t.Status.IsFinalized() {
+			t.Status = o.Status
+		}
+	default:
 		t.Status = o.Status
 	}
 
@@ -1010,6 +1027,9 @@ func (t *Transaction) Update(o *Transaction) {
 	if len(o.Intents) > 0 {
 		t.Intents = o.Intents
 	}
+	if len(o.InFlightWrites) > 0 {
+		t.InFlightWrites = o.InFlightWrites
+	}
 	// On update, set epoch zero timestamp to the minimum seen by either txn.
 	if o.EpochZeroTimestamp != (hlc.Timestamp{}) {
 		if t.EpochZeroTimestamp == (hlc.Timestamp{}) || o.EpochZeroTimestamp.Less(t.EpochZeroTimestamp) {
@@ -1043,6 +1063,9 @@ func (t Transaction) String() string {
 	if ni := len(t.Intents); t.Status != PENDING && ni > 0 {
 		fmt.Fprintf(&buf, " int=%d", ni)
 	}
+	if nw := len(t.InFlightWrites); t.Status == STAGING && nw > 0 {
+		fmt.Fprintf(&buf, " ifw=%d", nw)
+	}
 	return buf.String()
 }
 
@@ -494,6 +494,7 @@ var nonZeroTxn = Transaction{
 	Intents:                  []Span{{Key: []byte("a"), EndKey: []byte("b")}},
 	EpochZeroTimestamp:       makeTS(1, 1),
 	OrigTimestampWasObserved: true,
+	InFlightWrites:           []SequencedWrite{{Key: []byte("c"), Sequence: 1}},
 }
 
 func TestTransactionUpdate(t *testing.T) {
@@ -537,6 +538,30 @@ func TestTransactionUpdateEpochZero(t *testing.T) {
 	}
 }
 
+func TestTransactionUpdateStaging(t *testing.T) {
+	testCases := []struct {
+		cur, upd, exp TransactionStatus
+	}{
+		{PENDING, PENDING, PENDING},
+		{PENDING, STAGING, STAGING},
+		{STAGING, PENDING, STAGING},
+		{STAGING, COMMITTED, COMMITTED},
+		{STAGING, ABORTED, ABORTED},
+		{COMMITTED, STAGING, COMMITTED},
+		{ABORTED, STAGING, ABORTED},
+	}
+	for _, c := range testCases {
+		txn := nonZeroTxn.Clone()
+		txn.Status = c.cur
+		upd := nonZeroTxn.Clone()
+		upd.Status = c.upd
+		txn.Update(&upd)
+		if txn.Status != c.exp {
+			t.Errorf("%s updated with %s: expected %s; got %s", c.cur, c.upd, c.exp, txn.Status)
+		}
+	}
+}
+
 func TestTransactionClone(t *testing.T) {
 	txn := nonZeroTxn.Clone()
 
@@ -547,6 +572,7 @@ func TestTransactionClone(t *testing.T) {
 	// listed below. If this test fails, please update the list below and/or
 	// Transaction.Clone().
 	expFields := []string{
+		"InFlightWrites.Key",
 		"Intents.EndKey",
 		"Intents.Key",
 		"TxnMeta.Key",
@@ -133,6 +133,28 @@ func (r RangeDescriptor) GetReplicaDescriptorByID(replicaID ReplicaID) (ReplicaD
 	return ReplicaDescriptor{}, false
 }
 
+// Voters returns the replicas of this RangeDescriptor that participate in
+// quorum.
+func (r RangeDescriptor) Voters() []ReplicaDescriptor {
+	return r.filterReplicas(ReplicaDescriptor.IsVoter)
+}
+
+// NonVoters returns the replicas of this RangeDescriptor that receive the
+// raft log but do not participate in quorum.
+func (r RangeDescriptor) NonVoters() []ReplicaDescriptor {
+	return r.filterReplicas(func(rep ReplicaDescriptor) bool { return !rep.IsVoter() })
+}
+
+func (r RangeDescriptor) filterReplicas(pred func(ReplicaDescriptor) bool) []ReplicaDescriptor {
+	var reps []ReplicaDescriptor
+	for _, rep := range r.Replicas {
+		if pred(rep) {
+			reps = append(reps, rep)
+		}
+	}
+	return reps
+}
+
 // IsInitialized returns false if this descriptor represents an
 // uninitialized range.
 // TODO(bdarnell): unify this with Validate().
@@ -216,9 +238,25 @@ func (r ReplicaDescriptor) String() string {
 	} else {
 		fmt.Fprintf(&buf, "%d", r.ReplicaID)
 	}
+	if !r.IsVoter() {
+		fmt.Fprintf(&buf, ",%s", r.GetType())
+	}
 	return buf.String()
 }
 
+// GetType returns the type of the replica, defaulting to VOTER when unset.
+func (r ReplicaDescriptor) GetType() ReplicaType {
+	if r.Type == nil {
+		return VOTER
+	}
+	return *r.Type
+}
+
+// IsVoter returns whether the replica participates in quorum.
+func (r ReplicaDescriptor) IsVoter() bool {
+	return r.GetType() == VOTER
+}
+
 // Validate performs some basic validation of the contents of a replica descriptor.
 func (r ReplicaDescriptor) Validate() error {
 	if r.NodeID == 0 {
@@ -92,6 +92,26 @@ func TestRangeDescriptorMissingReplica(t *testing.T) {
 	}
 }
 
+func TestRangeDescriptorVotersAndNonVoters(t *testing.T) {
+	nonVoter := NON_VOTER
+	desc := RangeDescriptor{
+		Replicas: []ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2, Type: &nonVoter},
+			{NodeID: 3, StoreID: 3, ReplicaID: 3},
+		},
+	}
+	if voters := desc.Voters(); len(voters) != 2 || voters[0].ReplicaID != 1 || voters[1].ReplicaID != 3 {
+		t.Errorf("unexpected voters %v", voters)
+	}
+	if nonVoters := desc.NonVoters(); len(nonVoters) != 1 || nonVoters[0].ReplicaID != 2 {
+		t.Errorf("unexpected non-voters %v", nonVoters)
+	}
+	if s := desc.Replicas[1].String(); s != "(n2,s2):2,NON_VOTER" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
+
 // TestLocalityConversions verifies that setting the value from the CLI short
 // hand format works correctly.
 func TestLocalityConversions(t *testing.T) {
@@ -94,6 +94,10 @@ const (
 	PushTxn
 	// QueryTxn fetches the current state of the designated transaction.
 	QueryTxn
+	// RecoverTxn attempts to finalize a transaction whose record was
+	// abandoned in the STAGING state, either by committing or by aborting
+	// it depending on whether all of its in-flight writes succeeded.
+	RecoverTxn
 	// QueryIntent checks whether the specified intent exists.
 	QueryIntent
 	// ResolveIntent resolves existing write intents for a key.
@@ -150,4 +154,11 @@ const (
 	Subsume
 	// RangeStats returns the MVCC statistics for a range.
 	RangeStats
+	// QueryResolvedTimestamp returns the timestamp at or below which a span of
+	// keys can be read by the evaluating replica without blocking.
+	QueryResolvedTimestamp
+	// RevertRange removes all versions of values more recent than the
+	// TargetTime for keys which fall between args.RequestHeader.Key and
+	// args.RequestHeader.EndKey, with the latter endpoint excluded.
+	RevertRange
 )
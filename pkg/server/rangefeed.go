@@ -0,0 +1,74 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// RangeFeedHook streams the changes to span from cursor on to send. It follows
+// the span across splits and merges and combines the checkpoints of the
+// individual ranges into a resolved timestamp for the whole span, which it
+// sends whenever it advances. It is provided by CCL code and is nil otherwise.
+var RangeFeedHook func(
+	ctx context.Context,
+	ds *kv.DistSender,
+	span roachpb.Span,
+	cursor hlc.Timestamp,
+	send func(*serverpb.RangeFeedResponse) error,
+) error
+
+// RangeFeed implements the serverpb.StatusServer interface. It multiplexes the
+// rangefeeds of all ranges overlapping the requested span into a single
+// stream using RangeFeedHook.
+func (s *statusServer) RangeFeed(
+	req *serverpb.RangeFeedRequest, stream serverpb.Status_RangeFeedServer,
+) error {
+	ctx := propagateGatewayMetadata(stream.Context())
+	ctx = s.AnnotateCtx(ctx)
+
+	sessionUser, err := userFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !s.isSuperUser(ctx, sessionUser) {
+		return grpcstatus.Errorf(
+			codes.PermissionDenied, "client user %q does not have permission to use rangefeeds", sessionUser)
+	}
+	if RangeFeedHook == nil {
+		return grpcstatus.Errorf(codes.Unimplemented, "rangefeeds require a CCL binary")
+	}
+	if !storage.RangefeedEnabled.Get(&s.st.SV) {
+		return grpcstatus.Errorf(
+			codes.FailedPrecondition, "rangefeeds require the kv.rangefeed.enabled setting")
+	}
+	if !req.Span.Valid() || len(req.Span.EndKey) == 0 {
+		return grpcstatus.Errorf(codes.InvalidArgument, "invalid span %s", req.Span)
+	}
+
+	cursor := req.Cursor
+	if cursor == (hlc.Timestamp{}) {
+		cursor = s.db.Clock().Now()
+	}
+	return RangeFeedHook(ctx, s.distSender, req.Span, cursor, stream.Send)
+}
@@ -492,6 +492,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		s.db,
 		internalExecutor,
 		&s.nodeIDContainer,
+		s.cfg.Locality,
 		st,
 		s.cfg.HistogramWindowInterval(),
 		func(opName, user string) (interface{}, func()) {
@@ -543,6 +544,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		s.cfg.Config,
 		s.admin,
 		s.db,
+		s.distSender,
 		s.gossip,
 		s.recorder,
 		s.nodeLiveness,
@@ -689,6 +691,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	)
 	s.internalExecutor = internalExecutor
 	execCfg.InternalExecutor = internalExecutor
+	execCfg.StatsRefresher = stats.MakeRefresher(s.st, execCfg.TableStatsCache, s.jobRegistry)
 
 	s.execCfg = &execCfg
 
@@ -1562,6 +1565,10 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}
 
+	// Start the background thread that refreshes table statistics as tables
+	// are modified.
+	s.execCfg.StatsRefresher.Start(ctx, s.stopper, stats.DefaultRefreshInterval)
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -126,6 +127,7 @@ type statusServer struct {
 	cfg             *base.Config
 	admin           *adminServer
 	db              *client.DB
+	distSender      *kv.DistSender
 	gossip          *gossip.Gossip
 	metricSource    metricMarshaler
 	nodeLiveness    *storage.NodeLiveness
@@ -143,6 +145,7 @@ func newStatusServer(
 	cfg *base.Config,
 	adminServer *adminServer,
 	db *client.DB,
+	distSender *kv.DistSender,
 	gossip *gossip.Gossip,
 	metricSource metricMarshaler,
 	nodeLiveness *storage.NodeLiveness,
@@ -159,6 +162,7 @@ func newStatusServer(
 		cfg:             cfg,
 		admin:           adminServer,
 		db:              db,
+		distSender:      distSender,
 		gossip:          gossip,
 		metricSource:    metricSource,
 		nodeLiveness:    nodeLiveness,
@@ -73,6 +73,18 @@ const (
 	VersionCascadingZoneConfigs
 	VersionLoadSplits
 	VersionExportStorageWorkload
+	VersionNonVotingReplicas
+	VersionParallelCommits
+	VersionImportAvroParquetJSON
+	VersionImportInto
+	VersionBackupEncryption
+	VersionCreateStatsJob
+	VersionScheduledJobs
+	VersionFullClusterBackup
+	VersionLocalityAwareBackup
+	VersionJobResourceLimits
+	VersionSchemaChangeProgress
+	VersionMultiColumnStats
 
 	// Add new versions here (step one of two).
 
@@ -305,6 +317,86 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionExportStorageWorkload,
 		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 3},
 	},
+	{
+		// VersionNonVotingReplicas enables replicas that receive the raft log but
+		// do not participate in quorum, configured through the num_voters and
+		// voter_constraints zone config fields.
+		Key:     VersionNonVotingReplicas,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 4},
+	},
+	{
+		// VersionParallelCommits enables the STAGING transaction status and the
+		// RecoverTxn request, allowing transactions to commit in parallel with
+		// their final writes.
+		Key:     VersionParallelCommits,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 5},
+	},
+	{
+		// VersionImportAvroParquetJSON enables IMPORT from Avro, Parquet and
+		// newline-delimited JSON files.
+		Key:     VersionImportAvroParquetJSON,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 6},
+	},
+	{
+		// VersionImportInto enables IMPORT INTO existing tables, along with the
+		// RevertRange request and the OFFLINE table state it relies on.
+		Key:     VersionImportInto,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 7},
+	},
+	{
+		// VersionBackupEncryption is required for encrypted BACKUP and RESTORE,
+		// as older nodes ignore the encryption options on Export and Import
+		// requests.
+		Key:     VersionBackupEncryption,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 8},
+	},
+	{
+		// VersionCreateStatsJob is required for automatic statistics collection,
+		// which runs as a CREATE_STATS job that older nodes cannot resume.
+		Key:     VersionCreateStatsJob,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 9},
+	},
+	{
+		// VersionScheduledJobs is required for schedules, which are stored in
+		// system.scheduled_jobs and start SCHEDULED_EXECUTION jobs that older
+		// nodes cannot resume.
+		Key:     VersionScheduledJobs,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 10},
+	},
+	{
+		// VersionFullClusterBackup is required for full cluster BACKUP and
+		// RESTORE, as older nodes do not know to restore the system tables
+		// when resuming a full cluster RESTORE job.
+		Key:     VersionFullClusterBackup,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 11},
+	},
+	{
+		// VersionLocalityAwareBackup is required for BACKUP and RESTORE with
+		// locality-specific URIs, as older nodes ignore the per-locality
+		// destinations in Export requests and in backup and restore jobs.
+		Key:     VersionLocalityAwareBackup,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 12},
+	},
+	{
+		// VersionJobResourceLimits is required for ALTER JOB ... SET, as older
+		// nodes ignore the resource limits of jobs.
+		Key:     VersionJobResourceLimits,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 13},
+	},
+	{
+		// VersionSchemaChangeProgress is required for pausing schema change jobs
+		// and for checkpointing their progress in the job's progress, which older
+		// nodes do not read.
+		Key:     VersionSchemaChangeProgress,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 14},
+	},
+	{
+		// VersionMultiColumnStats is required for collecting statistics on
+		// multiple columns, as the samplers on older nodes reject multi-column
+		// sketches.
+		Key:     VersionMultiColumnStats,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 15},
+	},
 
 	// Add new versions here (step two of two).
 
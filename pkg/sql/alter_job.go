@@ -0,0 +1,144 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	alterJobOptMaxConcurrency = "max_concurrency"
+	alterJobOptMaxBytesPerSec = "max_bytes_per_sec"
+	alterJobOptLocality       = "locality"
+)
+
+var alterJobOptionExpectValues = map[string]KVStringOptValidate{
+	alterJobOptMaxConcurrency: KVStringOptRequireValue,
+	alterJobOptMaxBytesPerSec: KVStringOptRequireValue,
+	alterJobOptLocality:       KVStringOptRequireValue,
+}
+
+type alterJobNode struct {
+	jobID   tree.TypedExpr
+	options func() (map[string]string, error)
+}
+
+// AlterJob changes the resource limits of a job.
+// Privileges: superuser.
+func (p *planner) AlterJob(ctx context.Context, n *tree.AlterJob) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "ALTER JOB"); err != nil {
+		return nil, err
+	}
+	if !p.ExecCfg().Settings.Version.IsActive(cluster.VersionJobResourceLimits) {
+		return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"cluster version does not support job resource limits")
+	}
+	jobID, err := tree.TypeCheckAndRequire(n.Job, &p.semaCtx, types.Int, "ALTER JOB")
+	if err != nil {
+		return nil, err
+	}
+	options, err := p.TypeAsStringOpts(n.Options, alterJobOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+	return &alterJobNode{jobID: jobID, options: options}, nil
+}
+
+// startExec implements the execStartable interface.
+func (n *alterJobNode) startExec(params runParams) error {
+	jobIDDatum, err := n.jobID.Eval(params.EvalContext())
+	if err != nil {
+		return err
+	}
+	if jobIDDatum == tree.DNull {
+		return errors.New("ALTER JOB requires a job ID")
+	}
+	jobID, ok := tree.AsDInt(jobIDDatum)
+	if !ok {
+		return pgerror.NewAssertionErrorf("%q: expected *DInt, found %T", jobIDDatum, jobIDDatum)
+	}
+	options, err := n.options()
+	if err != nil {
+		return err
+	}
+
+	job, err := params.p.ExecCfg().JobRegistry.LoadJobWithTxn(params.ctx, int64(jobID), params.p.txn)
+	if err != nil {
+		return err
+	}
+	return job.WithTxn(params.p.txn).SetResourceLimits(params.ctx,
+		func(_ context.Context, limits jobspb.ResourceLimits) (jobspb.ResourceLimits, error) {
+			return applyJobResourceLimitOptions(limits, options)
+		},
+	)
+}
+
+// applyJobResourceLimitOptions returns limits with the changes requested by
+// the options of an ALTER JOB statement. An empty value removes a limit.
+func applyJobResourceLimitOptions(
+	limits jobspb.ResourceLimits, options map[string]string,
+) (jobspb.ResourceLimits, error) {
+	if s, ok := options[alterJobOptMaxConcurrency]; ok {
+		var concurrency int64
+		if s != "" {
+			var err error
+			if concurrency, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return limits, errors.Wrapf(err, "invalid %s", alterJobOptMaxConcurrency)
+			}
+			if concurrency < 0 {
+				return limits, errors.Errorf("%s must not be negative", alterJobOptMaxConcurrency)
+			}
+		}
+		limits.MaxConcurrency = concurrency
+	}
+	if s, ok := options[alterJobOptMaxBytesPerSec]; ok {
+		var bytesPerSec int64
+		if s != "" {
+			var err error
+			if bytesPerSec, err = humanizeutil.ParseBytes(s); err != nil {
+				return limits, errors.Wrapf(err, "invalid %s", alterJobOptMaxBytesPerSec)
+			}
+			if bytesPerSec < 0 {
+				return limits, errors.Errorf("%s must not be negative", alterJobOptMaxBytesPerSec)
+			}
+		}
+		limits.MaxBytesPerSec = bytesPerSec
+	}
+	if s, ok := options[alterJobOptLocality]; ok {
+		limits.Locality = ""
+		if s != "" {
+			var locality roachpb.Locality
+			if err := locality.Set(s); err != nil {
+				return limits, errors.Wrapf(err, "invalid %s", alterJobOptLocality)
+			}
+			limits.Locality = locality.String()
+		}
+	}
+	return limits, nil
+}
+
+func (*alterJobNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterJobNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterJobNode) Close(context.Context)        {}
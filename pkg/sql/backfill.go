@@ -367,6 +367,9 @@ func (sc *SchemaChanger) distBackfill(
 		if err := sc.ExtendLease(ctx, lease); err != nil {
 			return err
 		}
+		if err := sc.checkJobPaused(ctx); err != nil {
+			return err
+		}
 		log.VEventf(ctx, 2, "backfill: process %+v spans", spans)
 		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 			// Report schema change progress. We define progress at this point
@@ -388,6 +391,9 @@ func (sc *SchemaChanger) distBackfill(
 				fractionRangesFinished := float32(origNRanges-nRanges) / float32(origNRanges)
 				fractionCompleted := origFractionCompleted + fractionLeft*fractionRangesFinished
 				if err := sc.job.FractionProgressed(ctx, jobs.FractionUpdater(fractionCompleted)); err != nil {
+					if jobs.IsPausedError(err) {
+						return errSchemaChangePaused
+					}
 					return jobs.SimplifyInvalidStatusError(err)
 				}
 			}
@@ -440,6 +446,7 @@ func (sc *SchemaChanger) distBackfill(
 			planCtx := sc.distSQLPlanner.NewPlanningCtx(ctx, evalCtx, txn)
 			plan, err := sc.distSQLPlanner.createBackfiller(
 				planCtx, backfillType, *tableDesc.TableDesc(), duration, chunkSize, spans, otherTableDescs, readAsOf,
+				sc.job.Payload().ResourceLimits,
 			)
 			if err != nil {
 				return err
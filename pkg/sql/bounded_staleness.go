@@ -0,0 +1,236 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// boundedStalenessTimestamp determines the timestamp at which a SELECT with a
+// bounded staleness AS OF SYSTEM TIME clause (with_max_staleness or
+// with_min_timestamp) is executed.
+//
+// The timestamp is negotiated with the KV layer: the nearest replica of each
+// range touched by the tables in the FROM clause is asked for its resolved
+// timestamp, i.e. the highest timestamp it can serve without blocking on
+// intents or redirecting to the leaseholder. The minimum of these is used if
+// it satisfies the staleness bound. Otherwise, the read falls back to the
+// present time (max), or fails if the clause specified nearest_only. Since
+// the read is only served by the nearest replicas if follower reads are
+// enabled, bounded staleness reads fail when they aren't. If the negotiated
+// timestamp is used, the transaction is marked so that its reads are sent to
+// the nearest replicas.
+//
+// Tables referenced only from subqueries outside of the FROM clause are not
+// taken into account. Reads of their ranges remain correct, but may have to
+// be served by the leaseholder.
+func (p *planner) boundedStalenessTimestamp(
+	ctx context.Context, sc *tree.SelectClause, max hlc.Timestamp,
+) (hlc.Timestamp, error) {
+	asOf := sc.From.AsOf
+	bs, err := tree.EvalBoundedStaleness(asOf, max, &p.semaCtx, p.EvalContext())
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	if p.EvalContext().PrepareOnly {
+		// The statement is only being prepared; the timestamp is negotiated
+		// when it is executed.
+		return max, nil
+	}
+	if !p.EvalContext().TxnImplicit {
+		return hlc.Timestamp{}, pgerror.NewErrorf(pgerror.CodeActiveSQLTransactionError,
+			"AS OF SYSTEM TIME: bounded staleness reads cannot be used inside an explicit transaction")
+	}
+	// Without follower reads, every read is redirected to the leaseholder, so
+	// negotiating a timestamp that the nearest replicas can serve is
+	// pointless.
+	if !closedts.FollowerReadsEnabled.Get(&p.ExecCfg().Settings.SV) {
+		return hlc.Timestamp{}, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"AS OF SYSTEM TIME: bounded staleness reads require the "+
+				"kv.closed_timestamp.follower_reads_enabled cluster setting")
+	}
+
+	spans, err := p.boundedStalenessSpans(ctx, sc.From.Tables)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	if len(spans) == 0 {
+		return max, nil
+	}
+
+	var b client.Batch
+	b.Header.ReadConsistency = roachpb.INCONSISTENT
+	for _, sp := range spans {
+		b.AddRawRequest(&roachpb.QueryResolvedTimestampRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(sp),
+		})
+	}
+	if err := p.ExecCfg().DB.Run(ctx, &b); err != nil {
+		return hlc.Timestamp{}, err
+	}
+	resolved := max
+	for _, ru := range b.RawResponse().Responses {
+		if ts := ru.GetInner().(*roachpb.QueryResolvedTimestampResponse).ResolvedTS; ts.Less(resolved) {
+			resolved = ts
+		}
+	}
+
+	if resolved.Less(bs.MinTimestamp) {
+		if bs.NearestOnly {
+			return hlc.Timestamp{}, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+				"AS OF SYSTEM TIME: nearest replicas can only serve reads at or below %s, "+
+					"which is before the minimum timestamp %s", resolved, bs.MinTimestamp)
+		}
+		log.VEventf(ctx, 2, "resolved timestamp %s below bound %s; reading at %s",
+			resolved, bs.MinTimestamp, max)
+		return max, nil
+	}
+	log.VEventf(ctx, 2, "bounded staleness read at %s", resolved)
+	// Only the reads of this statement are sent to the nearest replicas; any
+	// other batch keeps going to the lease holder.
+	p.txn.SetBoundedStaleness()
+	return resolved, nil
+}
+
+// boundedStalenessSpans returns the spans of the tables referenced by the
+// given table expressions, including the tables underlying views. Names that
+// do not refer to a table (such as CTEs) and virtual tables are ignored.
+//
+// The descriptors are looked up in a separate transaction since the
+// statement's transaction must not be used before its timestamp is fixed.
+func (p *planner) boundedStalenessSpans(
+	ctx context.Context, exprs tree.TableExprs,
+) ([]roachpb.Span, error) {
+	var names []tree.TableName
+	var ids []sqlbase.ID
+	for _, expr := range exprs {
+		collectTableExprSources(expr, &names, &ids)
+	}
+	if len(names) == 0 && len(ids) == 0 {
+		return nil, nil
+	}
+
+	var spans []roachpb.Span
+	err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		spans = spans[:0]
+		ip, cleanup := newInternalPlanner(
+			"bounded-staleness", txn, p.User(), p.extendedEvalCtx.MemMetrics, p.ExecCfg(),
+		)
+		defer cleanup()
+		ip.SessionData().Database = p.SessionData().Database
+		ip.SessionData().SearchPath = p.SessionData().SearchPath
+
+		seen := make(map[sqlbase.ID]struct{})
+		var addDesc func(desc *sqlbase.TableDescriptor) error
+		addDesc = func(desc *sqlbase.TableDescriptor) error {
+			if _, ok := seen[desc.ID]; ok {
+				return nil
+			}
+			seen[desc.ID] = struct{}{}
+			if desc.IsVirtualTable() {
+				return nil
+			}
+			if !desc.IsView() {
+				spans = append(spans, desc.TableSpan())
+				return nil
+			}
+			for _, id := range desc.DependsOn {
+				dep, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+				if err != nil {
+					return err
+				}
+				if err := addDesc(dep); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		var err error
+		for i := range names {
+			var desc *ImmutableTableDescriptor
+			ip.runWithOptions(resolveFlags{skipCache: true}, func() {
+				desc, err = ResolveExistingObject(ctx, ip, &names[i], false /* required */, anyDescType)
+			})
+			if err != nil {
+				return err
+			}
+			if desc == nil {
+				continue
+			}
+			if err := addDesc(desc.TableDesc()); err != nil {
+				return err
+			}
+		}
+		for _, id := range ids {
+			desc, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+			if err != nil {
+				return err
+			}
+			if err := addDesc(desc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return spans, err
+}
+
+// collectTableExprSources appends the names and IDs of the tables referenced
+// by a table expression, descending into joins and subqueries.
+func collectTableExprSources(expr tree.TableExpr, names *[]tree.TableName, ids *[]sqlbase.ID) {
+	switch t := expr.(type) {
+	case *tree.TableName:
+		*names = append(*names, *t)
+	case *tree.TableRef:
+		*ids = append(*ids, sqlbase.ID(t.TableID))
+	case *tree.AliasedTableExpr:
+		collectTableExprSources(t.Expr, names, ids)
+	case *tree.ParenTableExpr:
+		collectTableExprSources(t.Expr, names, ids)
+	case *tree.JoinTableExpr:
+		collectTableExprSources(t.Left, names, ids)
+		collectTableExprSources(t.Right, names, ids)
+	case *tree.Subquery:
+		collectSelectSources(t.Select, names, ids)
+	}
+}
+
+// collectSelectSources appends the names and IDs of the tables referenced by
+// the FROM clauses of a select statement.
+func collectSelectSources(stmt tree.SelectStatement, names *[]tree.TableName, ids *[]sqlbase.ID) {
+	switch t := stmt.(type) {
+	case *tree.SelectClause:
+		if t.From != nil {
+			for _, expr := range t.From.Tables {
+				collectTableExprSources(expr, names, ids)
+			}
+		}
+	case *tree.ParenSelect:
+		collectSelectSources(t.Select.Select, names, ids)
+	case *tree.UnionClause:
+		collectSelectSources(t.Left.Select, names, ids)
+		collectSelectSources(t.Right.Select, names, ids)
+	}
+}
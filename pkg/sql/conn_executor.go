@@ -801,6 +801,11 @@ type connExecutor struct {
 		// is done if the statement was executed in an implicit txn).
 		schemaChangers schemaChangerCollection
 
+		// mutationCounts accumulate the number of rows modified in each table.
+		// They are reported to the automatic statistics Refresher once the
+		// transaction commits.
+		mutationCounts mutationCountCollection
+
 		// autoRetryCounter keeps track of the which iteration of a transaction
 		// auto-retry we're currently in. It's 0 whenever the transaction state is not
 		// stateOpen.
@@ -990,6 +995,7 @@ func (ex *connExecutor) resetExtraTxnState(
 	ctx context.Context, dbCacheHolder *databaseCacheHolder,
 ) error {
 	ex.extraTxnState.schemaChangers.reset()
+	ex.extraTxnState.mutationCounts.reset()
 
 	ex.extraTxnState.tables.releaseTables(ctx)
 
@@ -1796,6 +1802,7 @@ func (ex *connExecutor) evalCtx(
 		DistSQLPlanner:  ex.server.cfg.DistSQLPlanner,
 		TxnModesSetter:  ex,
 		SchemaChangers:  &ex.extraTxnState.schemaChangers,
+		MutationCounts:  &ex.extraTxnState.mutationCounts,
 		schemaAccessors: scInterface,
 	}
 }
@@ -1909,6 +1916,8 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 			err.(errorutil.UnexpectedWithIssueErr).SendReport(ex.Ctx(), &ex.server.cfg.Settings.SV)
 			return advanceInfo{}, err
 		}
+		ex.extraTxnState.mutationCounts.notifyRefresher(ex.server.cfg.StatsRefresher)
+
 		scc := &ex.extraTxnState.schemaChangers
 		if len(scc.schemaChangers) != 0 {
 			ieFactory := func(ctx context.Context, sd *sessiondata.SessionData) sqlutil.InternalExecutor {
@@ -353,7 +353,7 @@ func (ex *connExecutor) execStmtInOpenState(
 	}
 
 	if os.ImplicitTxn.Get() {
-		ts, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now())
+		ts, err := p.isAsOf(ctx, stmt.AST, ex.server.cfg.Clock.Now())
 		if err != nil {
 			return makeErrEvent(err)
 		}
@@ -366,7 +366,7 @@ func (ex *connExecutor) execStmtInOpenState(
 		// the transaction's timestamp. This is useful for running AOST statements
 		// using the InternalExecutor inside an external transaction; one might want
 		// to do that to force p.avoidCachedDescriptors to be set below.
-		ts, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now())
+		ts, err := p.isAsOf(ctx, stmt.AST, ex.server.cfg.Clock.Now())
 		if err != nil {
 			return makeErrEvent(err)
 		}
@@ -807,6 +807,9 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		res.SetError(err)
 		return nil
 	}
+	if planner.curPlan.streamResults {
+		res.SetStreaming()
+	}
 
 	ex.sessionTracing.TracePlanCheckStart(ctx)
 	distributePlan := false
@@ -714,6 +714,11 @@ type RestrictedCommandResult interface {
 	// RowsAffected returns either the number of times AddRow was called, or the
 	// sum of all n passed into IncrementRowsAffected.
 	RowsAffected() int
+
+	// SetStreaming marks the result as a stream of rows that might never end.
+	// Every row added to a streaming result is delivered to the client before
+	// AddRow returns, so AddRow blocks while the client isn't keeping up.
+	SetStreaming()
 }
 
 // DescribeResult represents the result of a Describe command (for either
@@ -902,6 +907,12 @@ func (r *bufferedCommandResult) IncrementRowsAffected(n int) {
 	r.rowsAffected += n
 }
 
+// SetStreaming is part of the RestrictedCommandResult interface.
+//
+// The rows are only handed over once the statement finishes, so a never-ending
+// stream can't be consumed through a bufferedCommandResult.
+func (r *bufferedCommandResult) SetStreaming() {}
+
 // RowsAffected is part of the RestrictedCommandResult interface.
 func (r *bufferedCommandResult) RowsAffected() int {
 	return r.rowsAffected
@@ -0,0 +1,112 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/pkg/errors"
+)
+
+type controlSchedulesNode struct {
+	rows    planNode
+	command tree.ScheduleCommand
+	numRows int
+}
+
+// ControlSchedules pauses, resumes or drops schedules.
+// Privileges: superuser.
+func (p *planner) ControlSchedules(
+	ctx context.Context, n *tree.ControlSchedules,
+) (planNode, error) {
+	if err := p.RequireSuperUser(ctx,
+		tree.ScheduleCommandToStatement[n.Command]+" SCHEDULES"); err != nil {
+		return nil, err
+	}
+	rows, err := p.newPlan(ctx, n.Schedules, []types.T{types.Int})
+	if err != nil {
+		return nil, err
+	}
+	cols := planColumns(rows)
+	if len(cols) != 1 {
+		return nil, errors.Errorf("%s SCHEDULES expects a single column source, got %d columns",
+			tree.ScheduleCommandToStatement[n.Command], len(cols))
+	}
+	if !cols[0].Typ.Equivalent(types.Int) {
+		return nil, errors.Errorf("%s SCHEDULES requires int values, not type %s",
+			tree.ScheduleCommandToStatement[n.Command], cols[0].Typ)
+	}
+
+	return &controlSchedulesNode{
+		rows:    rows,
+		command: n.Command,
+	}, nil
+}
+
+// FastPathResults implements the planNodeFastPath inteface.
+func (n *controlSchedulesNode) FastPathResults() (int, bool) {
+	return n.numRows, true
+}
+
+// startExec implements the execStartable interface.
+func (n *controlSchedulesNode) startExec(params runParams) error {
+	reg := params.p.ExecCfg().JobRegistry
+	for {
+		ok, err := n.rows.Next(params)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		scheduleIDDatum := n.rows.Values()[0]
+		if scheduleIDDatum == tree.DNull {
+			continue
+		}
+
+		scheduleID, ok := tree.AsDInt(scheduleIDDatum)
+		if !ok {
+			return pgerror.NewAssertionErrorf("%q: expected *DInt, found %T", scheduleIDDatum, scheduleIDDatum)
+		}
+
+		switch n.command {
+		case tree.PauseSchedule:
+			err = reg.PauseSchedule(params.ctx, params.p.txn, int64(scheduleID))
+		case tree.ResumeSchedule:
+			err = reg.ResumeSchedule(params.ctx, params.p.txn, int64(scheduleID))
+		case tree.DropSchedule:
+			err = reg.DropSchedule(params.ctx, params.p.txn, int64(scheduleID))
+		default:
+			err = pgerror.NewAssertionErrorf("unhandled command %v", n.command)
+		}
+		if err != nil {
+			return err
+		}
+		n.numRows++
+	}
+	return nil
+}
+
+func (*controlSchedulesNode) Next(runParams) (bool, error) { return false, nil }
+
+func (*controlSchedulesNode) Values() tree.Datums { return nil }
+
+func (n *controlSchedulesNode) Close(ctx context.Context) {
+	n.rows.Close(ctx)
+}
@@ -402,6 +402,7 @@ CREATE TABLE crdb_internal.jobs (
 	finished           		TIMESTAMP,
 	modified           		TIMESTAMP,
 	fraction_completed 		FLOAT,
+	estimated_finish   		TIMESTAMP,
 	high_water_timestamp	DECIMAL,
 	error              		STRING,
 	coordinator_id     		INT
@@ -420,9 +421,9 @@ CREATE TABLE crdb_internal.jobs (
 			id, status, created, payloadBytes, progressBytes := r[0], r[1], r[2], r[3], r[4]
 
 			var jobType, description, username, descriptorIDs, started, runningStatus,
-				finished, modified, fractionCompleted, highWaterTimestamp, errorStr, leaseNode = tree.DNull,
-				tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull,
-				tree.DNull, tree.DNull, tree.DNull, tree.DNull
+				finished, modified, fractionCompleted, estimatedFinish, highWaterTimestamp, errorStr,
+				leaseNode = tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+				tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull
 
 			// Extract data from the payload.
 			payload, err := jobs.UnmarshalPayload(payloadBytes)
@@ -469,10 +470,13 @@ CREATE TABLE crdb_internal.jobs (
 					}
 					modified = tsOrNull(progress.ModifiedMicros)
 
-					if len(progress.RunningStatus) > 0 {
-						if s, ok := status.(*tree.DString); ok {
-							if jobs.Status(string(*s)) == jobs.StatusRunning {
-								runningStatus = tree.NewDString(progress.RunningStatus)
+					if s, ok := status.(*tree.DString); ok && jobs.Status(string(*s)) == jobs.StatusRunning {
+						if len(progress.RunningStatus) > 0 {
+							runningStatus = tree.NewDString(progress.RunningStatus)
+						}
+						if payload != nil && progress.GetHighWater() == nil {
+							if finish, ok := jobs.EstimateFinish(payload, progress); ok {
+								estimatedFinish = tree.MakeDTimestamp(finish, time.Microsecond)
 							}
 						}
 					}
@@ -493,6 +497,7 @@ CREATE TABLE crdb_internal.jobs (
 				finished,
 				modified,
 				fractionCompleted,
+				estimatedFinish,
 				highWaterTimestamp,
 				errorStr,
 				leaseNode,
@@ -0,0 +1,389 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	scheduleOptOnPreviousRunning  = "on_previous_running"
+	scheduleOptOnExecutionFailure = "on_execution_failure"
+
+	// These mirror the BACKUP option and URI parameter defined in backupccl.
+	scheduleBackupOptEncPassphrase = "encryption_passphrase"
+	scheduleBackupLocalityParam    = "COCKROACH_LOCALITY"
+)
+
+var scheduleOptionExpectValues = map[string]KVStringOptValidate{
+	scheduleOptOnPreviousRunning:  KVStringOptRequireValue,
+	scheduleOptOnExecutionFailure: KVStringOptRequireValue,
+}
+
+var createScheduleColumns = sqlbase.ResultColumns{
+	{Name: "schedule_id", Typ: types.Int},
+	{Name: "label", Typ: types.String},
+	{Name: "next_run", Typ: types.Timestamp},
+	{Name: "recurrence", Typ: types.String},
+}
+
+type createScheduleNode struct {
+	optColumnsSlot
+
+	n               *tree.CreateSchedule
+	label           func() (string, error)
+	recurrence      func() (string, error)
+	to              func() ([]string, error)
+	incrementalFrom func() ([]string, error)
+	backupOpts      []func() (string, error)
+	scheduleOpts    func() (map[string]string, error)
+
+	run struct {
+		row  tree.Datums
+		done bool
+	}
+}
+
+// CreateSchedule creates a schedule that periodically runs a BACKUP.
+// Privileges: superuser.
+func (p *planner) CreateSchedule(ctx context.Context, n *tree.CreateSchedule) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "CREATE SCHEDULE"); err != nil {
+		return nil, err
+	}
+	if !p.ExecCfg().Settings.Version.IsActive(cluster.VersionScheduledJobs) {
+		return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"cluster version does not support schedules")
+	}
+	if n.Backup.AsOf.Expr != nil {
+		return nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"AS OF SYSTEM TIME is not supported for scheduled backups")
+	}
+
+	node := &createScheduleNode{n: n}
+	var err error
+	if n.Label != nil {
+		if node.label, err = p.TypeAsString(n.Label, "CREATE SCHEDULE"); err != nil {
+			return nil, err
+		}
+	}
+	if node.recurrence, err = p.TypeAsString(n.Recurrence, "CREATE SCHEDULE"); err != nil {
+		return nil, err
+	}
+	if node.to, err = p.TypeAsStringArray(tree.Exprs(n.Backup.To), "BACKUP"); err != nil {
+		return nil, err
+	}
+	if node.incrementalFrom, err = p.TypeAsStringArray(n.Backup.IncrementalFrom, "BACKUP"); err != nil {
+		return nil, err
+	}
+	node.backupOpts = make([]func() (string, error), len(n.Backup.Options))
+	for i, opt := range n.Backup.Options {
+		if opt.Value == nil {
+			continue
+		}
+		if node.backupOpts[i], err = p.TypeAsString(opt.Value, string(opt.Key)); err != nil {
+			return nil, err
+		}
+	}
+	if node.scheduleOpts, err = p.TypeAsStringOpts(n.Options, scheduleOptionExpectValues); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (n *createScheduleNode) startExec(params runParams) error {
+	recurrence, err := n.recurrence()
+	if err != nil {
+		return err
+	}
+	expr, err := jobs.ParseCronExpr(recurrence)
+	if err != nil {
+		return pgerror.NewError(pgerror.CodeInvalidParameterValueError, err.Error())
+	}
+
+	opts, err := n.scheduleOpts()
+	if err != nil {
+		return err
+	}
+	onPreviousRunning := jobs.OnPreviousRunningWait
+	if v, ok := opts[scheduleOptOnPreviousRunning]; ok {
+		switch v {
+		case jobs.OnPreviousRunningStart, jobs.OnPreviousRunningSkip, jobs.OnPreviousRunningWait:
+			onPreviousRunning = v
+		default:
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"invalid value for %s: %q, expected one of %q, %q, %q", scheduleOptOnPreviousRunning, v,
+				jobs.OnPreviousRunningStart, jobs.OnPreviousRunningSkip, jobs.OnPreviousRunningWait)
+		}
+	}
+	onExecutionFailure := jobs.OnExecutionFailureReschedule
+	if v, ok := opts[scheduleOptOnExecutionFailure]; ok {
+		switch v {
+		case jobs.OnExecutionFailureRetry, jobs.OnExecutionFailureReschedule, jobs.OnExecutionFailurePause:
+			onExecutionFailure = v
+		default:
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"invalid value for %s: %q, expected one of %q, %q, %q", scheduleOptOnExecutionFailure, v,
+				jobs.OnExecutionFailureRetry, jobs.OnExecutionFailureReschedule, jobs.OnExecutionFailurePause)
+		}
+	}
+
+	// The schedule stores the BACKUP with any placeholders replaced by their
+	// values, since it runs outside of this session.
+	backup := *n.n.Backup
+	to, err := n.to()
+	if err != nil {
+		return err
+	}
+	backup.To = make(tree.StringOrPlaceholderOptList, len(to))
+	for i := range to {
+		backup.To[i] = tree.NewDString(to[i])
+	}
+	if backup.IncrementalFrom != nil {
+		from, err := n.incrementalFrom()
+		if err != nil {
+			return err
+		}
+		backup.IncrementalFrom = make(tree.Exprs, len(from))
+		for i := range from {
+			backup.IncrementalFrom[i] = tree.NewDString(from[i])
+		}
+	}
+	if backup.Options != nil {
+		backup.Options = append(tree.KVOptions(nil), backup.Options...)
+		for i := range backup.Options {
+			if n.backupOpts[i] == nil {
+				continue
+			}
+			v, err := n.backupOpts[i]()
+			if err != nil {
+				return err
+			}
+			backup.Options[i].Value = tree.NewDString(v)
+		}
+	}
+	stmt := tree.AsString(&backup)
+	description, err := scheduledBackupDescription(backup)
+	if err != nil {
+		return err
+	}
+
+	label := "BACKUP " + tree.AsString(&backup.Targets)
+	if backup.DescriptorCoverage == tree.AllDescriptors {
+		label = "BACKUP CLUSTER"
+	}
+	if n.label != nil {
+		if label, err = n.label(); err != nil {
+			return err
+		}
+	}
+
+	nextRun := expr.Next(params.p.ExecCfg().Clock.PhysicalTime())
+	const insertStmt = `
+INSERT INTO system.scheduled_jobs
+  (schedule_name, owner, next_run, schedule_expr, execution_stmt, description,
+   on_previous_running, on_execution_failure)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING schedule_id`
+	row, err := params.p.ExecCfg().InternalExecutor.QueryRow(
+		params.ctx, "create-schedule", params.p.txn, insertStmt,
+		label, params.p.User(), nextRun, recurrence, stmt, description,
+		onPreviousRunning, onExecutionFailure,
+	)
+	if err != nil {
+		return err
+	}
+	n.run.row = tree.Datums{
+		row[0],
+		tree.NewDString(label),
+		tree.MakeDTimestamp(nextRun, time.Microsecond),
+		tree.NewDString(recurrence),
+	}
+	return nil
+}
+
+// scheduledBackupDescription returns the given BACKUP as shown by SHOW
+// SCHEDULES and in the descriptions of the jobs the schedule starts. As in the
+// descriptions of BACKUP jobs, the query strings of the URIs, which hold the
+// storage credentials, and the encryption passphrase are removed.
+func scheduledBackupDescription(backup tree.Backup) (string, error) {
+	to := make(tree.StringOrPlaceholderOptList, len(backup.To))
+	for i, expr := range backup.To {
+		uri, err := sanitizeScheduledURI(expr)
+		if err != nil {
+			return "", err
+		}
+		to[i] = uri
+	}
+	backup.To = to
+	if backup.IncrementalFrom != nil {
+		from := make(tree.Exprs, len(backup.IncrementalFrom))
+		for i, expr := range backup.IncrementalFrom {
+			uri, err := sanitizeScheduledURI(expr)
+			if err != nil {
+				return "", err
+			}
+			from[i] = uri
+		}
+		backup.IncrementalFrom = from
+	}
+	if backup.Options != nil {
+		backup.Options = append(tree.KVOptions(nil), backup.Options...)
+		for i := range backup.Options {
+			if backup.Options[i].Key == scheduleBackupOptEncPassphrase {
+				backup.Options[i].Value = tree.NewDString("redacted")
+			}
+		}
+	}
+	return tree.AsString(&backup), nil
+}
+
+// sanitizeScheduledURI removes the query string of a BACKUP URI, except for
+// its COCKROACH_LOCALITY parameter, which tells apart the URIs of a
+// locality-aware backup.
+func sanitizeScheduledURI(expr tree.Expr) (*tree.DString, error) {
+	s, ok := expr.(*tree.DString)
+	if !ok {
+		return nil, errors.Errorf("unexpected BACKUP URI %s", expr)
+	}
+	uri, err := url.Parse(string(*s))
+	if err != nil {
+		return nil, err
+	}
+	locality, ok := uri.Query()[scheduleBackupLocalityParam]
+	uri.RawQuery = ""
+	if ok {
+		uri.RawQuery = url.Values{scheduleBackupLocalityParam: locality}.Encode()
+	}
+	return tree.NewDString(uri.String()), nil
+}
+
+func (n *createScheduleNode) Next(runParams) (bool, error) {
+	if n.run.done {
+		return false, nil
+	}
+	n.run.done = true
+	return true, nil
+}
+
+func (n *createScheduleNode) Values() tree.Datums { return n.run.row }
+func (*createScheduleNode) Close(context.Context) {}
+
+// scheduledExecutionResumer runs the statement of a schedule, in the name of
+// the schedule's owner, for each SCHEDULED_EXECUTION job started by the
+// jobs.Registry scheduler.
+type scheduledExecutionResumer struct {
+	// resumed is set once Resume has been called. Since OnFailOrCancel is
+	// only ever called on the Resumer that ran the job when the job fails,
+	// and on a fresh Resumer when it is canceled, this tells the two apart.
+	resumed bool
+}
+
+var _ jobs.Resumer = &scheduledExecutionResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *scheduledExecutionResumer) Resume(
+	ctx context.Context, job *jobs.Job, phs interface{}, _ chan<- tree.Datums,
+) error {
+	r.resumed = true
+	p := phs.(*planner)
+	payload := job.Payload()
+	details := job.Details().(jobspb.ScheduledExecutionDetails)
+
+	stmt, err := parser.ParseOne(details.Statement)
+	if err != nil {
+		return err
+	}
+	if backup, ok := stmt.(*tree.Backup); ok {
+		// Each run of a recurring BACKUP writes to its own subdirectory of the
+		// destination, named after the time the run started, so that runs
+		// don't collide and the resulting backups are easy to tell apart.
+		if err := appendBackupRunSubdir(backup, timeutil.FromUnixMicros(payload.StartedMicros)); err != nil {
+			return err
+		}
+	}
+
+	_, err = p.ExecCfg().InternalExecutor.ExecWithUser(
+		ctx, "scheduled-execution", nil /* txn */, payload.Username, tree.AsString(stmt),
+	)
+	return err
+}
+
+// appendBackupRunSubdir rewrites the destinations of a BACKUP to a
+// subdirectory named after the given time.
+func appendBackupRunSubdir(backup *tree.Backup, t time.Time) error {
+	for i, expr := range backup.To {
+		to, ok := expr.(*tree.StrVal)
+		if !ok {
+			return errors.Errorf("unexpected BACKUP destination %s", expr)
+		}
+		uri, err := url.Parse(to.RawString())
+		if err != nil {
+			return err
+		}
+		uri.Path = path.Join(uri.Path, t.UTC().Format("2006-01-02-150405"))
+		backup.To[i] = tree.NewDString(uri.String())
+	}
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *scheduledExecutionResumer) OnFailOrCancel(
+	ctx context.Context, txn *client.Txn, job *jobs.Job,
+) error {
+	status := jobs.StatusCanceled
+	if r.resumed {
+		status = jobs.StatusFailed
+	}
+	return jobs.FinishScheduledRun(ctx, txn, job, status)
+}
+
+// OnSuccess is part of the jobs.Resumer interface.
+func (r *scheduledExecutionResumer) OnSuccess(
+	ctx context.Context, txn *client.Txn, job *jobs.Job,
+) error {
+	return jobs.FinishScheduledRun(ctx, txn, job, jobs.StatusSucceeded)
+}
+
+// OnTerminal is part of the jobs.Resumer interface.
+func (r *scheduledExecutionResumer) OnTerminal(
+	context.Context, *jobs.Job, jobs.Status, chan<- tree.Datums,
+) {
+}
+
+func scheduledExecutionResumeHook(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+	if typ != jobspb.TypeScheduledExecution {
+		return nil
+	}
+	return &scheduledExecutionResumer{}
+}
+
+func init() {
+	jobs.AddResumeHook(scheduledExecutionResumeHook)
+}
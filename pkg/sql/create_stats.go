@@ -16,7 +16,13 @@ package sql
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -62,8 +68,13 @@ func (p *planner) CreateStatistics(ctx context.Context, n *tree.CreateStats) (pl
 		return nil, err
 	}
 
+	multiColEnabled := p.ExecCfg().Settings.Version.IsActive(cluster.VersionMultiColumnStats)
 	if len(n.ColumnNames) == 0 {
-		return createStatsDefaultColumns(n, tableDesc)
+		return createStatsDefaultColumns(n, tableDesc, multiColEnabled)
+	}
+	if len(n.ColumnNames) > 1 && !multiColEnabled {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"multi-column statistics are not supported until the cluster upgrade is finalized")
 	}
 
 	columns, err := tableDesc.FindActiveColumnsByNames(n.ColumnNames)
@@ -92,10 +103,15 @@ func (p *planner) CreateStatistics(ctx context.Context, n *tree.CreateStats) (pl
 // useful to have statistics on prefixes of those columns. For example, if a
 // table abc contains indexes on (a ASC, b ASC) and (b ASC, c ASC), we will
 // collect statistics on a, {a, b}, b, and {b, c}.
-// TODO(rytaft): This currently only generates one single-column stat per
-// index. Add code to collect multi-column stats once they are supported.
+//
+// Statistics on all the columns of a multi-column unique index are not
+// collected, since the optimizer can already infer that the distinct count of
+// those columns is the number of (non-NULL) rows in the table.
+//
+// If multiColEnabled is false (i.e., the cluster is not yet fully upgraded),
+// only statistics on the first column of each index are collected.
 func createStatsDefaultColumns(
-	n *tree.CreateStats, desc *ImmutableTableDescriptor,
+	n *tree.CreateStats, desc *ImmutableTableDescriptor, multiColEnabled bool,
 ) (planNode, error) {
 	pn := &createStatsNode{
 		CreateStats: *n,
@@ -103,22 +119,36 @@ func createStatsDefaultColumns(
 		columns:     make([][]sqlbase.ColumnID, 0, len(desc.Indexes)+1),
 	}
 
-	var requestedCols util.FastIntSet
+	// requestedStats contains the sets of columns for which statistics have
+	// already been requested, so that each set is only collected once.
+	requestedStats := make(map[string]struct{})
+
+	addIndexColumnStats := func(idx *sqlbase.IndexDescriptor) {
+		var colSet util.FastIntSet
+		for i, colID := range idx.ColumnIDs {
+			if i > 0 && (!multiColEnabled || (idx.Unique && i == len(idx.ColumnIDs)-1)) {
+				break
+			}
+			colSet.Add(int(colID))
+			key := colSet.String()
+			if _, ok := requestedStats[key]; ok {
+				continue
+			}
+			requestedStats[key] = struct{}{}
+			columnIDs := make([]sqlbase.ColumnID, i+1)
+			copy(columnIDs, idx.ColumnIDs[:i+1])
+			pn.columns = append(pn.columns, columnIDs)
+		}
+	}
 
 	// If the primary key is not the hidden rowid column, collect stats on it.
-	pkCol := desc.PrimaryIndex.ColumnIDs[0]
-	if !isHidden(desc, pkCol) {
-		pn.columns = append(pn.columns, []sqlbase.ColumnID{pkCol})
-		requestedCols.Add(int(pkCol))
+	if !isHidden(desc, desc.PrimaryIndex.ColumnIDs[0]) {
+		addIndexColumnStats(&desc.PrimaryIndex)
 	}
 
 	// Add columns for each secondary index.
 	for i := range desc.Indexes {
-		idxCol := desc.Indexes[i].ColumnIDs[0]
-		if !requestedCols.Contains(int(idxCol)) {
-			pn.columns = append(pn.columns, []sqlbase.ColumnID{idxCol})
-			requestedCols.Add(int(idxCol))
-		}
+		addIndexColumnStats(&desc.Indexes[i])
 	}
 
 	// If there are no non-hidden index columns, collect stats on the first
@@ -154,3 +184,62 @@ func (*createStatsNode) Next(runParams) (bool, error) {
 }
 func (*createStatsNode) Close(context.Context) {}
 func (*createStatsNode) Values() tree.Datums   { return nil }
+
+// createStatsResumer runs the CREATE_STATS jobs scheduled by the automatic
+// statistics Refresher.
+type createStatsResumer struct{}
+
+var _ jobs.Resumer = &createStatsResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *createStatsResumer) Resume(
+	ctx context.Context, job *jobs.Job, phs interface{}, _ chan<- tree.Datums,
+) error {
+	p := phs.(*planner)
+	details := job.Details().(jobspb.CreateStatsDetails)
+	stmt := fmt.Sprintf(
+		"CREATE STATISTICS %s FROM [%d]", tree.NameString(details.Name), details.TableID,
+	)
+
+	// Collect the statistics as of a fixed timestamp, chosen before the scan
+	// starts. The transaction only reads the table (the statistics are written
+	// in a separate transaction), so it is never restarted or pushed by
+	// concurrent writes. It also runs at low priority so that the table scan
+	// yields to foreground traffic.
+	asOf := p.ExecCfg().Clock.Now()
+	return p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, asOf)
+		if err := txn.SetUserPriority(roachpb.MinUserPriority); err != nil {
+			return err
+		}
+		_, err := p.ExecCfg().InternalExecutor.Exec(ctx, "create-stats", txn, stmt)
+		return err
+	})
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *createStatsResumer) OnFailOrCancel(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+// OnSuccess is part of the jobs.Resumer interface.
+func (r *createStatsResumer) OnSuccess(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+// OnTerminal is part of the jobs.Resumer interface.
+func (r *createStatsResumer) OnTerminal(
+	context.Context, *jobs.Job, jobs.Status, chan<- tree.Datums,
+) {
+}
+
+func createStatsResumeHook(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+	if typ != jobspb.TypeCreateStats {
+		return nil
+	}
+	return &createStatsResumer{}
+}
+
+func init() {
+	jobs.AddResumeHook(createStatsResumeHook)
+}
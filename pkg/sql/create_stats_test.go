@@ -0,0 +1,63 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCreateStatsDefaultColumns(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The table has a primary key on (a, b) and a secondary index on (c, a).
+	desc := NewImmutableTableDescriptor(sqlbase.TableDescriptor{
+		Name: "t",
+		Columns: []sqlbase.ColumnDescriptor{
+			{Name: "a", ID: 1},
+			{Name: "b", ID: 2},
+			{Name: "c", ID: 3},
+		},
+		PrimaryIndex: sqlbase.IndexDescriptor{
+			Name: "primary", Unique: true, ColumnIDs: []sqlbase.ColumnID{1, 2},
+		},
+		Indexes: []sqlbase.IndexDescriptor{
+			{Name: "c_a", ColumnIDs: []sqlbase.ColumnID{3, 1}},
+		},
+	})
+
+	testCases := []struct {
+		multiColEnabled bool
+		expected        [][]sqlbase.ColumnID
+	}{
+		// Before the cluster version allows multi-column statistics, only the
+		// first column of each index is requested.
+		{false, [][]sqlbase.ColumnID{{1}, {3}}},
+		{true, [][]sqlbase.ColumnID{{1}, {3}, {3, 1}}},
+	}
+	for _, tc := range testCases {
+		pn, err := createStatsDefaultColumns(&tree.CreateStats{}, desc, tc.multiColEnabled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if columns := pn.(*createStatsNode).columns; !reflect.DeepEqual(columns, tc.expected) {
+			t.Errorf("multiColEnabled=%t: expected %v, got %v", tc.multiColEnabled, tc.expected, columns)
+		}
+	}
+}
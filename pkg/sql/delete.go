@@ -311,6 +311,11 @@ func (d *deleteNode) BatchedNext(params runParams) (bool, error) {
 		}
 		// Remember we're done for the next call to BatchedNext().
 		d.run.done = true
+
+		// Let the automatic statistics refresher know about the mutation.
+		params.p.notifyMutation(
+			d.run.td.tableDesc().ID, d.run.td.totalRowsWritten(),
+		)
 	}
 
 	return d.run.rowCount > 0, nil
@@ -502,7 +507,13 @@ func (d *deleteNode) fastDelete(params runParams, scan *scanNode, interleavedFas
 	var err error
 	d.run.rowCount, err = d.run.td.fastDelete(
 		params.ctx, scan, d.run.autoCommit, d.run.traceKV)
-	return err
+	if err != nil {
+		return err
+	}
+	params.p.notifyMutation(
+		d.run.td.tableDesc().ID, d.run.td.totalRowsWritten(),
+	)
+	return nil
 }
 
 // enableAutoCommit is part of the autoCommitNode interface.
@@ -17,6 +17,7 @@ package sql
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan"
@@ -52,8 +53,9 @@ func initBackfillerSpec(
 }
 
 // createBackfiller generates a plan consisting of index/column backfiller
-// processors, one for each node that has spans that we are reading. The plan is
-// finalized.
+// processors, one for each node that has spans that we are reading, or fewer
+// if limits caps the concurrency of the job. The bytes per second limit of the
+// job is split evenly between the processors. The plan is finalized.
 func (dsp *DistSQLPlanner) createBackfiller(
 	planCtx *PlanningCtx,
 	backfillType backfillType,
@@ -63,6 +65,7 @@ func (dsp *DistSQLPlanner) createBackfiller(
 	spans []roachpb.Span,
 	otherTables []sqlbase.TableDescriptor,
 	readAsOf hlc.Timestamp,
+	limits *jobspb.ResourceLimits,
 ) (PhysicalPlan, error) {
 	spec, err := initBackfillerSpec(backfillType, desc, duration, chunkSize, otherTables, readAsOf)
 	if err != nil {
@@ -73,6 +76,20 @@ func (dsp *DistSQLPlanner) createBackfiller(
 	if err != nil {
 		return PhysicalPlan{}, err
 	}
+	if n := limits.LimitConcurrency(len(spanPartitions)); n < len(spanPartitions) {
+		// Hand the spans of the partitions beyond the limit to the remaining
+		// processors, which read them remotely.
+		for i, sp := range spanPartitions[n:] {
+			spanPartitions[i%n].Spans = append(spanPartitions[i%n].Spans, sp.Spans...)
+		}
+		spanPartitions = spanPartitions[:n]
+	}
+	if bytesPerSec := limits.BytesPerSec(); bytesPerSec > 0 && len(spanPartitions) > 0 {
+		spec.MaxBytesPerSec = bytesPerSec / int64(len(spanPartitions))
+		if spec.MaxBytesPerSec < 1 {
+			spec.MaxBytesPerSec = 1
+		}
+	}
 
 	var p PhysicalPlan
 	p.ResultRouters = make([]distsqlplan.ProcessorIdx, len(spanPartitions))
@@ -211,9 +211,24 @@ func LoadCSV(
 	rand.Shuffle(len(nodes), func(i, j int) {
 		nodes[i], nodes[j] = nodes[j], nodes[i]
 	})
+	// The job's max_concurrency, set with ALTER JOB, limits the number of nodes
+	// that read and write its data.
+	nodes = nodes[:job.Payload().ResourceLimits.LimitConcurrency(len(nodes))]
 
 	// Setup common to both stages.
 
+	// An IMPORT INTO an existing table must not overwrite its rows and may
+	// only be given some of its columns.
+	details := job.Details().(jobspb.ImportDetails)
+	var existing bool
+	var targetCols []string
+	var existingWalltime int64
+	if len(details.Tables) == 1 && details.Tables[0].Existing {
+		existing = true
+		targetCols = details.Tables[0].TargetCols
+		existingWalltime = walltime
+	}
+
 	// For each input file, assign it to a node.
 	inputSpecs := make([]*distsqlpb.ReadImportDataSpec, 0, len(nodes))
 	for i, input := range from {
@@ -221,8 +236,10 @@ func LoadCSV(
 		// creates the spec. Future files just add themselves to the Uris.
 		if i < len(nodes) {
 			spec := &distsqlpb.ReadImportDataSpec{
-				Tables: tables,
-				Format: format,
+				Tables:        tables,
+				Format:        format,
+				TargetCols:    targetCols,
+				WalltimeNanos: existingWalltime,
 				Progress: distsqlpb.JobProgress{
 					JobID: *job.ID(),
 					Slot:  int32(i),
@@ -244,8 +261,9 @@ func LoadCSV(
 	sstSpecs := make([]distsqlpb.SSTWriterSpec, len(nodes))
 	for i := range nodes {
 		sstSpecs[i] = distsqlpb.SSTWriterSpec{
-			Destination:   to,
-			WalltimeNanos: walltime,
+			Destination:       to,
+			WalltimeNanos:     walltime,
+			DisallowShadowing: existing,
 		}
 	}
 
@@ -254,7 +272,6 @@ func LoadCSV(
 
 	// Determine if we need to run the sampling plan or not.
 
-	details := job.Details().(jobspb.ImportDetails)
 	samples := details.Samples
 	if samples == nil {
 		var err error
@@ -47,19 +47,21 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 
 	// Calculate the relevant columns.
 	scan.valNeededForCol = util.FastIntSet{}
-	var sampledColumnIDs []sqlbase.ColumnID
 	for _, s := range stats {
 		for _, c := range s.columns {
 			colIdx, ok := scan.colIdxMap[c]
 			if !ok {
 				return PhysicalPlan{}, errors.Errorf("unknown column ID %d", c)
 			}
-			if !scan.valNeededForCol.Contains(colIdx) {
-				scan.valNeededForCol.Add(colIdx)
-				sampledColumnIDs = append(sampledColumnIDs, c)
-			}
+			scan.valNeededForCol.Add(colIdx)
 		}
 	}
+	// The table readers output the needed columns in the order of their index
+	// in the table, so the sampled column IDs must follow the same order.
+	sampledColumnIDs := make([]sqlbase.ColumnID, 0, scan.valNeededForCol.Len())
+	scan.valNeededForCol.ForEach(func(colIdx int) {
+		sampledColumnIDs = append(sampledColumnIDs, scan.cols[colIdx].ID)
+	})
 
 	p, err := dsp.createTableReaders(planCtx, &scan, nil /* overrideResultColumns */)
 	if err != nil {
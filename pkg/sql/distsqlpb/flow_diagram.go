@@ -436,6 +436,11 @@ func (s *CSVWriterSpec) summary() (string, []string) {
 	return "CSVWriter", []string{s.Destination}
 }
 
+// summary implements the diagramCellType interface.
+func (s *ExportWriterSpec) summary() (string, []string) {
+	return "ExportWriter", []string{s.Format.Format.String(), s.Destination}
+}
+
 // summary implements the diagramCellType interface.
 func (w *WindowerSpec) summary() (string, []string) {
 	details := make([]string, 0, len(w.WindowFns))
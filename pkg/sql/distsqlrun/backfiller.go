@@ -18,11 +18,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/backfill"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -34,6 +36,11 @@ import (
 	"github.com/pkg/errors"
 )
 
+// backfillerCheckpointInterval is the interval after which a backfiller
+// checkpoints the work it has done so far, so that not all of it is redone if
+// the backfill is interrupted before it finishes its span.
+var backfillerCheckpointInterval = 30 * time.Second
+
 type chunkBackfiller interface {
 	// runChunk returns the next-key and an error. next-key is nil
 	// once the backfill is complete.
@@ -120,6 +127,7 @@ func (b *backfiller) mainLoop(ctx context.Context) error {
 	// Backfill the mutations for all the rows.
 	chunkSize := b.spec.ChunkSize
 	start := timeutil.Now()
+	lastCheckpoint := start
 	var resume roachpb.Span
 	sp := work
 	var nChunks, row = 0, int64(0)
@@ -137,14 +145,30 @@ func (b *backfiller) mainLoop(ctx context.Context) error {
 			resume = sp
 			break
 		}
+		if timeutil.Since(lastCheckpoint) > backfillerCheckpointInterval && sp.Key != nil {
+			// Checkpoint the work done so far; the rest of the span is
+			// checkpointed relative to what remains.
+			if err := b.writeResumeSpan(ctx, mutationID, work, sp); err != nil {
+				return err
+			}
+			work = sp
+			lastCheckpoint = timeutil.Now()
+		}
 	}
 	log.VEventf(ctx, 2, "processed %d rows in %d chunks", row, nChunks)
+	return b.writeResumeSpan(ctx, mutationID, work, resume)
+}
+
+func (b *backfiller) writeResumeSpan(
+	ctx context.Context, mutationID sqlbase.MutationID, origSpan, resume roachpb.Span,
+) error {
 	return WriteResumeSpan(ctx,
 		b.flowCtx.ClientDB,
+		b.flowCtx.Settings,
 		b.spec.Table.ID,
 		mutationID,
 		b.filter,
-		work,
+		origSpan,
 		resume,
 		b.flowCtx.JobRegistry,
 	)
@@ -201,24 +225,56 @@ func GetResumeSpans(
 	if err != nil {
 		return nil, nil, 0, errors.Wrapf(err, "can't find job %d", jobID)
 	}
+	spanList, err := resumeSpanList(job)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	// Return the resume spans from the job using the mutation idx.
+	return spanList[mutationIdx].ResumeSpans, job, mutationIdx, nil
+}
+
+// resumeSpanList returns the resume spans of each mutation of a schema change
+// job: those checkpointed in its progress, if any, and otherwise those its
+// details were created with.
+func resumeSpanList(job *jobs.Job) ([]jobspb.ResumeSpanList, error) {
 	details, ok := job.Details().(jobspb.SchemaChangeDetails)
 	if !ok {
-		return nil, nil, 0, errors.Errorf("expected SchemaChangeDetails job type, got %T", job.Details())
+		return nil, errors.Errorf("expected SchemaChangeDetails job type, got %T", job.Details())
 	}
-	// Return the resume spans from the job using the mutation idx.
-	return details.ResumeSpanList[mutationIdx].ResumeSpans, job, mutationIdx, nil
+	jobProgress := job.Progress()
+	if progress, ok := jobProgress.UnwrapDetails().(jobspb.SchemaChangeProgress); ok &&
+		len(progress.ResumeSpanList) > 0 {
+		return progress.ResumeSpanList, nil
+	}
+	return details.ResumeSpanList, nil
 }
 
-// SetResumeSpansInJob addeds a list of resume spans into a job details field.
+// SetResumeSpansInJob addeds a list of resume spans into a job's progress or,
+// if the cluster version does not support that yet, its details.
 func SetResumeSpansInJob(
-	ctx context.Context, spans []roachpb.Span, mutationIdx int, txn *client.Txn, job *jobs.Job,
+	ctx context.Context,
+	settings *cluster.Settings,
+	spans []roachpb.Span,
+	mutationIdx int,
+	txn *client.Txn,
+	job *jobs.Job,
 ) error {
-	details, ok := job.Details().(jobspb.SchemaChangeDetails)
-	if !ok {
-		return errors.Errorf("expected SchemaChangeDetails job type, got %T", job.Details())
+	if !settings.Version.IsActive(cluster.VersionSchemaChangeProgress) {
+		details, ok := job.Details().(jobspb.SchemaChangeDetails)
+		if !ok {
+			return errors.Errorf("expected SchemaChangeDetails job type, got %T", job.Details())
+		}
+		details.ResumeSpanList[mutationIdx].ResumeSpans = spans
+		return job.WithTxn(txn).SetDetails(ctx, details)
+	}
+	spanList, err := resumeSpanList(job)
+	if err != nil {
+		return err
 	}
-	details.ResumeSpanList[mutationIdx].ResumeSpans = spans
-	return job.WithTxn(txn).SetDetails(ctx, details)
+	// Copy the list, which may still be the one in the job's details.
+	spanList = append([]jobspb.ResumeSpanList(nil), spanList...)
+	spanList[mutationIdx].ResumeSpans = spans
+	return job.WithTxn(txn).SetProgress(ctx, jobspb.SchemaChangeProgress{ResumeSpanList: spanList})
 }
 
 // WriteResumeSpan writes a checkpoint for the backfill work on origSpan.
@@ -227,6 +283,7 @@ func SetResumeSpansInJob(
 func WriteResumeSpan(
 	ctx context.Context,
 	db *client.DB,
+	settings *cluster.Settings,
 	id sqlbase.ID,
 	mutationID sqlbase.MutationID,
 	filter backfill.MutationFilter,
@@ -283,7 +340,7 @@ func WriteResumeSpan(
 
 				log.VEventf(ctx, 2, "ckpt %+v", resumeSpans)
 
-				return SetResumeSpansInJob(ctx, resumeSpans, mutationIdx, txn, job)
+				return SetResumeSpansInJob(ctx, settings, resumeSpans, mutationIdx, txn, job)
 			}
 		}
 		// Unable to find a span containing origSpan.
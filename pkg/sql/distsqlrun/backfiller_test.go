@@ -145,7 +145,8 @@ func TestWriteResumeSpan(t *testing.T) {
 	}
 	for _, test := range testData {
 		if err := distsqlrun.WriteResumeSpan(
-			ctx, kvDB, tableDesc.ID, mutationID, backfill.IndexMutationFilter, test.orig, test.resume, registry,
+			ctx, kvDB, server.ClusterSettings(), tableDesc.ID, mutationID, backfill.IndexMutationFilter,
+			test.orig, test.resume, registry,
 		); err != nil {
 			t.Error(err)
 		}
@@ -190,4 +191,19 @@ func TestWriteResumeSpan(t *testing.T) {
 			t.Fatalf("expected = %+v, got = %+v", e, got[i])
 		}
 	}
+
+	// The checkpoints are written to the job's progress, leaving its details
+	// as they were.
+	job, err = registry.LoadJob(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := job.Details().(jobspb.SchemaChangeDetails); len(d.ResumeSpanList[0].ResumeSpans) != len(resumeSpans) {
+		t.Fatalf("expected details to be unchanged, got %+v", d.ResumeSpanList)
+	}
+	progress := job.Progress()
+	p, ok := progress.UnwrapDetails().(jobspb.SchemaChangeProgress)
+	if !ok || len(p.ResumeSpanList) != 1 || len(p.ResumeSpanList[0].ResumeSpans) != len(expected) {
+		t.Fatalf("expected progress to hold %+v, got %+v", expected, progress.Details)
+	}
 }
@@ -23,9 +23,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"golang.org/x/time/rate"
 )
 
 // indexBackfiller is a processor that backfills new indexes.
@@ -35,6 +37,10 @@ type indexBackfiller struct {
 	backfill.IndexBackfiller
 
 	desc *sqlbase.ImmutableTableDescriptor
+
+	// limiter limits the rate at which index entries are written, or is nil if
+	// the spec sets no limit.
+	limiter *rate.Limiter
 }
 
 var _ Processor = &indexBackfiller{}
@@ -48,7 +54,8 @@ func newIndexBackfiller(
 	output RowReceiver,
 ) (*indexBackfiller, error) {
 	ib := &indexBackfiller{
-		desc: sqlbase.NewImmutableTableDescriptor(spec.Table),
+		desc:    sqlbase.NewImmutableTableDescriptor(spec.Table),
+		limiter: bulk.NewBytesLimiter(spec.MaxBytesPerSec),
 		backfiller: backfiller{
 			name:        "Index",
 			filter:      backfill.IndexMutationFilter,
@@ -121,6 +128,14 @@ func (ib *indexBackfiller) runChunk(
 		return nil, err
 	}
 
+	var entriesBytes int64
+	for i := range entries {
+		entriesBytes += int64(len(entries[i].Key) + len(entries[i].Value.RawBytes))
+	}
+	if err := bulk.WaitForBytes(ctx, ib.limiter, entriesBytes); err != nil {
+		return nil, err
+	}
+
 	retried := false
 	// Write the new index values.
 	if err := ib.flowCtx.ClientDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -1119,6 +1119,15 @@ func newProcessor(
 		}
 		return NewCSVWriterProcessor(flowCtx, processorID, *core.CSVWriter, inputs[0], outputs[0])
 	}
+	if core.ExportWriter != nil {
+		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
+			return nil, err
+		}
+		if NewExportWriterProcessor == nil {
+			return nil, errors.New("ExportWriter processor unimplemented")
+		}
+		return NewExportWriterProcessor(flowCtx, processorID, *core.ExportWriter, inputs[0], outputs[0])
+	}
 	if core.MetadataTestSender != nil {
 		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
 			return nil, err
@@ -1211,6 +1220,9 @@ var NewSSTWriterProcessor func(*FlowCtx, int32, distsqlpb.SSTWriterSpec, RowSour
 // NewCSVWriterProcessor is externally implemented.
 var NewCSVWriterProcessor func(*FlowCtx, int32, distsqlpb.CSVWriterSpec, RowSource, RowReceiver) (Processor, error)
 
+// NewExportWriterProcessor is externally implemented.
+var NewExportWriterProcessor func(*FlowCtx, int32, distsqlpb.ExportWriterSpec, RowSource, RowReceiver) (Processor, error)
+
 // NewChangeAggregatorProcessor is externally implemented.
 var NewChangeAggregatorProcessor func(*FlowCtx, int32, distsqlpb.ChangeAggregatorSpec, RowReceiver) (Processor, error)
 
@@ -252,6 +252,22 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 			); err != nil {
 				return err
 			}
+
+			// Automatic statistics are created periodically, so only a bounded
+			// number of them is retained for each set of columns.
+			if si.spec.StatName == stats.AutoStatsName {
+				if err := stats.DeleteOldStatsForColumns(
+					ctx,
+					s.flowCtx.executor,
+					txn,
+					s.tableID,
+					si.spec.StatName,
+					columnIDs,
+					stats.AutomaticStatisticsMaxRetained.Get(&s.flowCtx.Settings.SV),
+				); err != nil {
+					return err
+				}
+			}
 		}
 		return nil
 	})
@@ -77,8 +77,8 @@ func newSamplerProcessor(
 		if _, ok := supportedSketchTypes[s.SketchType]; !ok {
 			return nil, errors.Errorf("unsupported sketch type %s", s.SketchType)
 		}
-		if len(s.Columns) != 1 {
-			return nil, errors.Errorf("multi-column sketches not supported yet")
+		if len(s.Columns) == 0 {
+			return nil, errors.Errorf("no columns")
 		}
 	}
 
@@ -178,21 +178,32 @@ func (s *samplerProcessor) mainLoop(ctx context.Context) (earlyExit bool, _ erro
 		}
 
 		for i := range s.sketches {
-			// TODO(radu): for multi-column sketches, we will need to do this for all
-			// columns.
-			col := s.sketches[i].spec.Columns[0]
 			s.sketches[i].numRows++
-			if row[col].IsNull() {
+			// For multi-column sketches, a row is counted as NULL if any of the
+			// columns is NULL; such rows don't contribute to the distinct count.
+			isNull := false
+			for _, col := range s.sketches[i].spec.Columns {
+				if row[col].IsNull() {
+					isNull = true
+					break
+				}
+			}
+			if isNull {
 				s.sketches[i].numNulls++
 				continue
 			}
 			// We need to use a KEY encoding because equal values should have the same
-			// encoding.
+			// encoding. The encodings of multiple columns are concatenated; key
+			// encodings are self-delimiting, so distinct tuples of values have
+			// distinct encodings.
 			// TODO(radu): a fast path for simple columns (like integer)?
-			var err error
-			buf, err = row[col].Encode(&s.outTypes[col], &da, sqlbase.DatumEncoding_ASCENDING_KEY, buf[:0])
-			if err != nil {
-				return false, err
+			buf = buf[:0]
+			for _, col := range s.sketches[i].spec.Columns {
+				var err error
+				buf, err = row[col].Encode(&s.outTypes[col], &da, sqlbase.DatumEncoding_ASCENDING_KEY, buf)
+				if err != nil {
+					return false, err
+				}
 			}
 			s.sketches[i].sketch.Insert(buf)
 		}
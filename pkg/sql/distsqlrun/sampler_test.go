@@ -145,8 +145,8 @@ func TestSamplerSketch(t *testing.T) {
 		{-1, 3},
 		{1, -1},
 	}
-	cardinalities := []int{2, 8}
-	numNulls := []int{2, 1}
+	cardinalities := []int{2, 8, 8}
+	numNulls := []int{2, 1, 3}
 
 	rows := genEncDatumRowsInt(inputRows)
 	in := NewRowBuffer(twoIntCols, rows, RowBufferArgs{})
@@ -181,6 +181,10 @@ func TestSamplerSketch(t *testing.T) {
 				SketchType: distsqlpb.SketchType_HLL_PLUS_PLUS_V1,
 				Columns:    []uint32{1},
 			},
+			{
+				SketchType: distsqlpb.SketchType_HLL_PLUS_PLUS_V1,
+				Columns:    []uint32{0, 1},
+			},
 		},
 	}
 	p, err := newSamplerProcessor(&flowCtx, 0 /* processorID */, spec, in, &distsqlpb.PostProcessSpec{}, out)
@@ -190,9 +194,9 @@ func TestSamplerSketch(t *testing.T) {
 	p.Run(context.Background(), nil /* wg */)
 
 	rows = out.GetRowsNoMeta(t)
-	// We expect one sampled row and two sketch rows.
-	if len(rows) != 3 {
-		t.Fatalf("expected 3 rows, got %v\n", rows.String(outTypes))
+	// We expect one sampled row and three sketch rows.
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %v\n", rows.String(outTypes))
 	}
 	rows = rows[1:]
 
@@ -341,6 +341,7 @@ type ExecutorConfig struct {
 	VirtualSchemas   *VirtualSchemaHolder
 	DistSQLPlanner   *DistSQLPlanner
 	TableStatsCache  *stats.TableStatisticsCache
+	StatsRefresher   *stats.Refresher
 	ExecLogger       *log.SecondaryLogger
 	AuditLogger      *log.SecondaryLogger
 	InternalExecutor *InternalExecutor
@@ -699,7 +700,13 @@ func ParseHLC(s string) (hlc.Timestamp, error) {
 //
 // max is a lower bound on what the transaction's timestamp will be.
 // Used to check that the user didn't specify a timestamp in the future.
-func (p *planner) isAsOf(stmt tree.Statement, max hlc.Timestamp) (*hlc.Timestamp, error) {
+//
+// A Select with a bounded staleness clause (with_max_staleness or
+// with_min_timestamp) has its timestamp negotiated with the KV layer; see
+// boundedStalenessTimestamp.
+func (p *planner) isAsOf(
+	ctx context.Context, stmt tree.Statement, max hlc.Timestamp,
+) (*hlc.Timestamp, error) {
 	var asOf tree.AsOfClause
 	switch s := stmt.(type) {
 	case *tree.Select:
@@ -717,6 +724,10 @@ func (p *planner) isAsOf(stmt tree.Statement, max hlc.Timestamp) (*hlc.Timestamp
 		if sc.From == nil || sc.From.AsOf.Expr == nil {
 			return nil, nil
 		}
+		if tree.IsBoundedStalenessAsOf(sc.From.AsOf) {
+			ts, err := p.boundedStalenessTimestamp(ctx, sc, max)
+			return &ts, err
+		}
 
 		asOf = sc.From.AsOf
 	case *tree.Scrub:
@@ -725,7 +736,7 @@ func (p *planner) isAsOf(stmt tree.Statement, max hlc.Timestamp) (*hlc.Timestamp
 		}
 		asOf = s.AsOf
 	case *tree.Export:
-		return p.isAsOf(s.Query, max)
+		return p.isAsOf(ctx, s.Query, max)
 	case *tree.CreateStats:
 		if s.AsOf.Expr == nil {
 			return nil, nil
@@ -938,6 +949,34 @@ func (scc *schemaChangerCollection) reset() {
 	scc.schemaChangers = nil
 }
 
+// mutationCountCollection accumulates the number of rows modified in each
+// table by a transaction. The counts are reported to the automatic statistics
+// Refresher once the transaction commits, so that rows written by transactions
+// which are rolled back or retried are not counted.
+type mutationCountCollection struct {
+	counts map[sqlbase.ID]int64
+}
+
+func (mcc *mutationCountCollection) add(tableID sqlbase.ID, rowsAffected int64) {
+	if mcc.counts == nil {
+		mcc.counts = make(map[sqlbase.ID]int64)
+	}
+	mcc.counts[tableID] += rowsAffected
+}
+
+func (mcc *mutationCountCollection) reset() {
+	mcc.counts = nil
+}
+
+// notifyRefresher reports the accumulated mutation counts to the Refresher.
+// This needs to be run after the transaction that modified the tables has
+// committed.
+func (mcc *mutationCountCollection) notifyRefresher(r *stats.Refresher) {
+	for tableID, rowsAffected := range mcc.counts {
+		r.NotifyMutation(tableID, rowsAffected)
+	}
+}
+
 // execSchemaChanges releases schema leases and runs the queued
 // schema changers. This needs to be run after the transaction
 // scheduling the schema change has finished.
@@ -979,6 +1018,13 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 					// 2. If the context is canceled the schema changer quits here
 					// letting the asynchronous code path complete the schema
 					// change.
+				} else if errors.Cause(err) == errSchemaChangePaused {
+					// The schema change's job was paused. Rather than wait for it
+					// to be resumed, report that to the client; the asynchronous
+					// code path completes the schema change once it is.
+					if firstError == nil {
+						firstError = errSchemaChangePaused
+					}
 				} else if isPermanentSchemaChangeError(err) {
 					// All constraint violations can be reported; we report it as the result
 					// corresponding to the statement that enqueued this changer.
@@ -341,12 +341,16 @@ func doExpandPlan(
 	case *controlJobsNode:
 		n.rows, err = doExpandPlan(ctx, p, noParams, n.rows)
 
+	case *controlSchedulesNode:
+		n.rows, err = doExpandPlan(ctx, p, noParams, n.rows)
+
 	case *projectSetNode:
 		n.source, err = doExpandPlan(ctx, p, noParams, n.source)
 
 	case *valuesNode:
 	case *virtualTableNode:
 	case *alterIndexNode:
+	case *alterJobNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
@@ -362,6 +366,7 @@ func doExpandPlan(
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
@@ -855,9 +860,13 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *controlJobsNode:
 		n.rows = p.simplifyOrderings(n.rows, nil)
 
+	case *controlSchedulesNode:
+		n.rows = p.simplifyOrderings(n.rows, nil)
+
 	case *valuesNode:
 	case *virtualTableNode:
 	case *alterIndexNode:
+	case *alterJobNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
@@ -873,6 +882,7 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
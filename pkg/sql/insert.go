@@ -499,6 +499,11 @@ func (n *insertNode) BatchedNext(params runParams) (bool, error) {
 		}
 		// Remember we're done for the next call to BatchedNext().
 		n.run.done = true
+
+		// Let the automatic statistics refresher know about the mutation.
+		params.p.notifyMutation(
+			n.run.ti.tableDesc().ID, n.run.ti.totalRowsWritten(),
+		)
 	}
 
 	return n.run.rowCount > 0, nil
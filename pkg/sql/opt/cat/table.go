@@ -94,7 +94,27 @@ type TableStatistic interface {
 	// any column in the statistic.
 	NullCount() uint64
 
-	// TODO(radu): add Histogram().
+	// Histogram returns the buckets of the histogram on the column of the
+	// statistic, ordered by upper bound, or nil if there is no histogram.
+	// Histograms are only collected for single-column statistics, and do not
+	// include NULL values.
+	Histogram() []HistogramBucket
+}
+
+// HistogramBucket contains the data for a single bucket in a histogram. The
+// bucket covers the values greater than the upper bound of the previous bucket
+// and less than or equal to its own upper bound.
+type HistogramBucket struct {
+	// NumEq is the estimated number of values equal to UpperBound.
+	NumEq float64
+
+	// NumRange is the estimated number of values strictly between the upper
+	// bound of the previous bucket and UpperBound. The range of the first
+	// bucket has no lower bound.
+	NumRange float64
+
+	// UpperBound is the upper boundary of the bucket. It is never NULL.
+	UpperBound tree.Datum
 }
 
 // ForeignKeyReference is a struct representing an outbound foreign key reference.
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
@@ -443,6 +444,10 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 			if colStat, ok := stats.ColStats.Add(cols); ok {
 				colStat.DistinctCount = float64(stat.DistinctCount())
 				colStat.NullCount = float64(stat.NullCount())
+				if cols.Len() == 1 && stat.Histogram() != nil {
+					colStat.Histogram = &props.Histogram{}
+					colStat.Histogram.Init(sb.evalCtx, stat.Histogram(), colStat.DistinctCount)
+				}
 			}
 		}
 	}
@@ -477,7 +482,7 @@ func (sb *statisticsBuilder) buildScan(scan *ScanExpr, relProps *props.Relationa
 		// Calculate distinct counts for constrained columns
 		// -------------------------------------------------
 		var numUnappliedConjuncts float64
-		var cols opt.ColSet
+		var cols, histCols opt.ColSet
 		// Inverted indexes are a special case; a constraint like:
 		// /1: [/'{"a": "b"}' - /'{"a": "b"}']
 		// does not necessarily mean there is only going to be one distinct
@@ -491,7 +496,7 @@ func (sb *statisticsBuilder) buildScan(scan *ScanExpr, relProps *props.Relationa
 				numUnappliedConjuncts += sb.numConjunctsInConstraint(scan.Constraint, i)
 			}
 		} else {
-			numUnappliedConjuncts = sb.applyIndexConstraint(scan.Constraint, scan, relProps)
+			numUnappliedConjuncts, histCols = sb.applyIndexConstraint(scan.Constraint, scan, relProps)
 			for i, n := 0, scan.Constraint.ConstrainedColumns(sb.evalCtx); i < n; i++ {
 				cols.Add(int(scan.Constraint.Columns.Get(i).ID()))
 			}
@@ -500,7 +505,13 @@ func (sb *statisticsBuilder) buildScan(scan *ScanExpr, relProps *props.Relationa
 		// Calculate row count and selectivity
 		// -----------------------------------
 		inputRowCount := s.RowCount
-		s.ApplySelectivity(sb.selectivityFromDistinctCounts(cols, scan, s))
+		multiColSelectivity, multiCols := sb.selectivityFromMultiColDistinctCounts(cols, scan, relProps)
+		s.ApplySelectivity(multiColSelectivity)
+		histCols = histCols.Difference(multiCols)
+		s.ApplySelectivity(sb.selectivityFromHistograms(histCols, scan, s))
+		s.ApplySelectivity(sb.selectivityFromDistinctCounts(
+			cols.Difference(histCols).Difference(multiCols), scan, s,
+		))
 		s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 
 		// Set null counts to 0 for non-nullable columns
@@ -584,20 +595,29 @@ func (sb *statisticsBuilder) buildSelect(sel *SelectExpr, relProps *props.Relati
 
 	// Calculate distinct counts for constrained columns
 	// -------------------------------------------------
-	numUnappliedConjuncts, constrainedCols := sb.applyFilter(sel.Filters, sel, relProps)
+	numUnappliedConjuncts, constrainedCols, histCols := sb.applyFilter(sel.Filters, sel, relProps)
 
 	// Try to reduce the number of columns used for selectivity
 	// calculation based on functional dependencies.
 	inputFD := &sel.Input.Relational().FuncDeps
 	nonReducedCols := constrainedCols
 	constrainedCols = sb.tryReduceCols(constrainedCols, s, inputFD)
+	histCols = histCols.Intersection(constrainedCols)
 
 	// Calculate selectivity and row count
 	// -----------------------------------
 	inputStats := &sel.Input.Relational().Stats
 	s.RowCount = inputStats.RowCount
 	inputRowCount := s.RowCount
-	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, sel, s))
+	multiColSelectivity, multiCols := sb.selectivityFromMultiColDistinctCounts(
+		constrainedCols, sel, relProps,
+	)
+	s.ApplySelectivity(multiColSelectivity)
+	histCols = histCols.Difference(multiCols)
+	s.ApplySelectivity(sb.selectivityFromHistograms(histCols, sel, s))
+	s.ApplySelectivity(sb.selectivityFromDistinctCounts(
+		constrainedCols.Difference(histCols).Difference(multiCols), sel, s,
+	))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, sel, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 
@@ -793,7 +813,7 @@ func (sb *statisticsBuilder) buildJoin(
 
 	// Calculate distinct counts for constrained columns in the ON conditions
 	// ----------------------------------------------------------------------
-	numUnappliedConjuncts, constrainedCols := sb.applyFilter(h.filters, join, relProps)
+	numUnappliedConjuncts, constrainedCols, histCols := sb.applyFilter(h.filters, join, relProps)
 
 	// Try to reduce the number of columns used for selectivity
 	// calculation based on functional dependencies.
@@ -805,12 +825,21 @@ func (sb *statisticsBuilder) buildJoin(
 		&h.leftProps.FuncDeps,
 		&h.rightProps.FuncDeps,
 	)
+	histCols = histCols.Intersection(constrainedCols)
 
 	// Calculate selectivity and row count
 	// -----------------------------------
 	s.RowCount = leftStats.RowCount * rightStats.RowCount
 	inputRowCount := s.RowCount
-	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, join, s))
+	multiColSelectivity, multiCols := sb.selectivityFromMultiColDistinctCounts(
+		constrainedCols, join, relProps,
+	)
+	s.ApplySelectivity(multiColSelectivity)
+	histCols = histCols.Difference(multiCols)
+	s.ApplySelectivity(sb.selectivityFromHistograms(histCols, join, s))
+	s.ApplySelectivity(sb.selectivityFromDistinctCounts(
+		constrainedCols.Difference(histCols).Difference(multiCols), join, s,
+	))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &h.filtersFD, join, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 
@@ -1274,7 +1303,7 @@ func (sb *statisticsBuilder) buildZigzagJoin(
 	// still have corresponding filters in zigzag.On. So we don't need
 	// to iterate through FixedCols here if we are already processing the ON
 	// clause.
-	numUnappliedConjuncts, constrainedCols := sb.applyFilter(zigzag.On, zigzag, relProps)
+	numUnappliedConjuncts, constrainedCols, histCols := sb.applyFilter(zigzag.On, zigzag, relProps)
 
 	// Application of constraints on inverted indexes needs to be handled a
 	// little differently since a constraint on an inverted index key column
@@ -1306,10 +1335,19 @@ func (sb *statisticsBuilder) buildZigzagJoin(
 	inputFD := &zigzag.Relational().FuncDeps
 	nonReducedCols := constrainedCols
 	constrainedCols = sb.tryReduceCols(constrainedCols, s, inputFD)
+	histCols = histCols.Intersection(constrainedCols)
 
 	// Calculate selectivity and row count.
 	inputRowCount := s.RowCount
-	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, zigzag, s))
+	multiColSelectivity, multiCols := sb.selectivityFromMultiColDistinctCounts(
+		constrainedCols, zigzag, relProps,
+	)
+	s.ApplySelectivity(multiColSelectivity)
+	histCols = histCols.Difference(multiCols)
+	s.ApplySelectivity(sb.selectivityFromHistograms(histCols, zigzag, s))
+	s.ApplySelectivity(sb.selectivityFromDistinctCounts(
+		constrainedCols.Difference(histCols).Difference(multiCols), zigzag, s,
+	))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, zigzag, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 
@@ -1955,6 +1993,9 @@ func (sb *statisticsBuilder) copyColStat(
 	colStat, _ := s.ColStats.Add(colSet)
 	colStat.DistinctCount = inputColStat.DistinctCount
 	colStat.NullCount = inputColStat.NullCount
+	if colSet.Len() == 1 {
+		colStat.Histogram = inputColStat.Histogram
+	}
 	return colStat
 }
 
@@ -2103,12 +2144,17 @@ func countJSONPaths(conjunct *FiltersItem) int {
 // applyConstraintSet and updateDistinctCountsFromConstraint for more details
 // about how distinct counts are calculated from constraints.
 //
+// If a constrained column has a histogram, the histogram is filtered using the
+// constraint, and the column is added to histCols. The selectivity of the
+// filter on these columns is later determined from the histograms rather than
+// the distinct counts; see selectivityFromHistograms.
+//
 // Equalities between two variables (e.g., var1=var2) are handled separately.
 // See applyEquivalencies and selectivityFromEquivalencies for details.
 //
 func (sb *statisticsBuilder) applyFilter(
 	filters FiltersExpr, e RelExpr, relProps *props.Relational,
-) (numUnappliedConjuncts float64, constrainedCols opt.ColSet) {
+) (numUnappliedConjuncts float64, constrainedCols, histCols opt.ColSet) {
 	applyConjunct := func(conjunct *FiltersItem) {
 		if isEqualityWithTwoVars(conjunct.Condition) {
 			// We'll handle equalities later.
@@ -2144,7 +2190,8 @@ func (sb *statisticsBuilder) applyFilter(
 		scalarProps := conjunct.ScalarProps(e.Memo())
 		constrainedCols.UnionWith(scalarProps.OuterCols)
 		if scalarProps.Constraints != nil {
-			n := sb.applyConstraintSet(scalarProps.Constraints, e, relProps)
+			n, cols := sb.applyConstraintSet(scalarProps.Constraints, e, relProps)
+			histCols.UnionWith(cols)
 			if !scalarProps.TightConstraints && n < 1 {
 				numUnappliedConjuncts++
 			} else {
@@ -2159,21 +2206,29 @@ func (sb *statisticsBuilder) applyFilter(
 		applyConjunct(&filters[i])
 	}
 
-	return numUnappliedConjuncts, constrainedCols
+	return numUnappliedConjuncts, constrainedCols, histCols
 }
 
 func (sb *statisticsBuilder) applyIndexConstraint(
 	c *constraint.Constraint, e RelExpr, relProps *props.Relational,
-) (numUnappliedConjuncts float64) {
+) (numUnappliedConjuncts float64, histCols opt.ColSet) {
 	// If unconstrained, then no constraint could be derived from the expression,
 	// so fall back to estimate.
 	// If a contradiction, then optimizations must not be enabled (say for
 	// testing), or else this would have been reduced.
 	if c.IsUnconstrained() || c.IsContradiction() {
-		return 0 /* numUnappliedConjuncts */
+		return 0 /* numUnappliedConjuncts */, opt.ColSet{}
 	}
 
 	applied := sb.updateDistinctCountsFromConstraint(c, e, relProps)
+	if sb.updateHistogram(c, e, relProps) {
+		// The histogram accounts for the constraint on the first column, even if
+		// its distinct count could not be determined.
+		histCols.Add(int(c.Columns.Get(0).ID()))
+		if applied == 0 {
+			applied = 1
+		}
+	}
 	for i, n := applied, c.ConstrainedColumns(sb.evalCtx); i < n; i++ {
 		// Unlike the constraints found in Select and Join filters, an index
 		// constraint may represent multiple conjuncts. Therefore, we need to
@@ -2181,34 +2236,89 @@ func (sb *statisticsBuilder) applyIndexConstraint(
 		numUnappliedConjuncts += sb.numConjunctsInConstraint(c, i)
 	}
 
-	return numUnappliedConjuncts
+	return numUnappliedConjuncts, histCols
 }
 
 func (sb *statisticsBuilder) applyConstraintSet(
 	cs *constraint.Set, e RelExpr, relProps *props.Relational,
-) (numUnappliedConjuncts float64) {
+) (numUnappliedConjuncts float64, histCols opt.ColSet) {
 	// If unconstrained, then no constraint could be derived from the expression,
 	// so fall back to estimate.
 	// If a contradiction, then optimizations must not be enabled (say for
 	// testing), or else this would have been reduced.
 	if cs.IsUnconstrained() || cs == constraint.Contradiction {
-		return 0 /* numUnappliedConjuncts */
+		return 0 /* numUnappliedConjuncts */, opt.ColSet{}
 	}
 
 	numUnappliedConjuncts = 0
 	for i := 0; i < cs.Length(); i++ {
-		applied := sb.updateDistinctCountsFromConstraint(cs.Constraint(i), e, relProps)
+		c := cs.Constraint(i)
+		applied := sb.updateDistinctCountsFromConstraint(c, e, relProps)
+		if sb.updateHistogram(c, e, relProps) {
+			// The histogram accounts for the constraint on the first column, even
+			// if it is an inequality like x < 1.
+			histCols.Add(int(c.Columns.Get(0).ID()))
+			continue
+		}
 		if applied == 0 {
 			// If a constraint cannot be applied, it may represent an
 			// inequality like x < 1. As a result, distinctCounts does not fully
 			// represent the selectivity of the constraint set.
 			// We return an estimate of the number of unapplied conjuncts to the
 			// caller function to be used for selectivity calculation.
-			numUnappliedConjuncts += sb.numConjunctsInConstraint(cs.Constraint(i), 0 /* nth */)
+			numUnappliedConjuncts += sb.numConjunctsInConstraint(c, 0 /* nth */)
 		}
 	}
 
-	return numUnappliedConjuncts
+	return numUnappliedConjuncts, histCols
+}
+
+// updateHistogram filters the histogram of the first column of the given
+// constraint so that it only contains the values which satisfy the constraint.
+// It returns false if the column has no histogram, or if the constraint cannot
+// be applied to it.
+//
+// For example, consider a histogram on column a with the following buckets:
+//
+//   {NumEq: 100, NumRange: 0, UpperBound: 'pending'}
+//   {NumEq: 900, NumRange: 0, UpperBound: 'shipped'}
+//
+// The constraint /a: [/'shipped' - /'shipped'] results in a histogram with
+// 900 values, so the selectivity of the constraint is 0.9 rather than the 0.5
+// estimated from the distinct count of a.
+func (sb *statisticsBuilder) updateHistogram(
+	c *constraint.Constraint, e RelExpr, relProps *props.Relational,
+) bool {
+	col := c.Columns.Get(0).ID()
+	if !sb.tableHasHistogram(col) {
+		// Avoid adding column statistics for columns which can't have a histogram.
+		return false
+	}
+
+	s := &relProps.Stats
+	colSet := util.MakeFastIntSet(int(col))
+	colStat, ok := s.ColStats.Lookup(colSet)
+	if !ok {
+		colStat = sb.copyColStat(colSet, s, sb.colStatFromInput(colSet, e))
+	}
+	if colStat.Histogram == nil || !colStat.Histogram.CanFilter(c) {
+		return false
+	}
+	colStat.Histogram = colStat.Histogram.Filter(c)
+	colStat.DistinctCount = min(colStat.DistinctCount, colStat.Histogram.DistinctValuesCount())
+	return true
+}
+
+// tableHasHistogram returns true if the given column belongs to a base table
+// with a histogram on the column.
+func (sb *statisticsBuilder) tableHasHistogram(col opt.ColumnID) bool {
+	tabMeta := sb.md.ColumnMeta(col).TableMeta
+	if tabMeta == nil {
+		return false
+	}
+	tableStats := sb.makeTableStatistics(tabMeta.MetaID)
+	colStat, ok := tableStats.ColStats.Lookup(util.MakeFastIntSet(int(col)))
+	return ok && colStat.Histogram != nil
 }
 
 // updateNullCountsFromProps zeroes null counts for columns that cannot
@@ -2433,6 +2543,174 @@ func (sb *statisticsBuilder) selectivityFromDistinctCounts(
 	return selectivity
 }
 
+// selectivityFromMultiColDistinctCounts calculates the selectivity of a filter
+// using multi-column statistics collected on the base tables. Unlike
+// selectivityFromDistinctCounts, it doesn't assume that the constrained
+// columns are independent. For each set of constrained columns M with a
+// multi-column table statistic, the selectivity is:
+//
+//                  ⎛ new distinct(M) ⎞
+//   selectivity =  ⎜ --------------- ⎟
+//                  ⎝ old distinct(M) ⎠
+//
+// where new distinct(M) is the product of the new distinct counts of the
+// individual columns in M (capped at old distinct(M)), and old distinct(M) is
+// the distinct count of M in the input. For example, consider a table with
+// columns country and city, where the distinct count of country is 100, the
+// distinct count of city is 1000, and the distinct count of (country, city) is
+// also 1000 because each city belongs to a single country. The selectivity of
+// country = 'US' AND city = 'New York' is 1/1000 rather than the 1/100000
+// estimated by assuming the columns are independent.
+//
+// Only columns whose distinct counts were reduced by the filter are
+// considered. If several statistics apply, the ones with the most columns are
+// used first, and each column is used at most once. The columns covered by the
+// chosen statistics are returned as multiCols; they should be excluded from
+// selectivityFromHistograms and selectivityFromDistinctCounts so that their
+// selectivity isn't applied twice.
+func (sb *statisticsBuilder) selectivityFromMultiColDistinctCounts(
+	cols opt.ColSet, e RelExpr, relProps *props.Relational,
+) (selectivity float64, multiCols opt.ColSet) {
+	selectivity = 1.0
+	s := &relProps.Stats
+
+	var reducedCols opt.ColSet
+	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+		colStat, ok := s.ColStats.Lookup(util.MakeFastIntSet(col))
+		if !ok {
+			continue
+		}
+		inputStat := sb.colStatFromInput(colStat.Cols, e)
+		if colStat.DistinctCount < inputStat.DistinctCount {
+			reducedCols.Add(col)
+		}
+	}
+	if reducedCols.Len() < 2 {
+		return selectivity, multiCols
+	}
+
+	for _, statCols := range sb.multiColTableStats(reducedCols) {
+		if statCols.Intersects(multiCols) {
+			continue
+		}
+		newDistinct := 1.0
+		statCols.ForEach(func(col int) {
+			colStat, _ := s.ColStats.Lookup(util.MakeFastIntSet(col))
+			newDistinct *= colStat.DistinctCount
+		})
+		colStat := sb.ensureColStat(statCols, newDistinct, e, relProps)
+		oldDistinct := sb.colStatFromInput(statCols, e).DistinctCount
+		if oldDistinct != 0 && colStat.DistinctCount < oldDistinct {
+			selectivity *= colStat.DistinctCount / oldDistinct
+		}
+		multiCols.UnionWith(statCols)
+	}
+
+	return selectivity, multiCols
+}
+
+// multiColTableStats returns the column sets of the multi-column table
+// statistics that only include columns in cols. The sets are ordered by
+// decreasing number of columns.
+func (sb *statisticsBuilder) multiColTableStats(cols opt.ColSet) []opt.ColSet {
+	var tables []opt.TableID
+	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+		tabMeta := sb.md.ColumnMeta(opt.ColumnID(col)).TableMeta
+		if tabMeta == nil {
+			continue
+		}
+		found := false
+		for _, tabID := range tables {
+			if tabID == tabMeta.MetaID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tables = append(tables, tabMeta.MetaID)
+		}
+	}
+
+	var statCols []opt.ColSet
+	for _, tabID := range tables {
+		tab := sb.md.Table(tabID)
+		for i := 0; i < tab.StatisticCount(); i++ {
+			stat := tab.Statistic(i)
+			if stat.ColumnCount() < 2 {
+				continue
+			}
+			var set opt.ColSet
+			for j := 0; j < stat.ColumnCount(); j++ {
+				set.Add(int(tabID.ColumnID(stat.ColumnOrdinal(j))))
+			}
+			if !set.SubsetOf(cols) {
+				continue
+			}
+			duplicate := false
+			for j := range statCols {
+				if statCols[j].Equals(set) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				statCols = append(statCols, set)
+			}
+		}
+	}
+	sort.SliceStable(statCols, func(i, j int) bool {
+		return statCols[i].Len() > statCols[j].Len()
+	})
+	return statCols
+}
+
+// selectivityFromHistograms is similar to selectivityFromDistinctCounts, in
+// that it calculates the selectivity of a filter by taking the product of
+// selectivities of each constrained column. However, the selectivity of each
+// column is determined by the number of values remaining in its histogram
+// after the histogram was filtered by applyFilter or applyIndexConstraint:
+//
+//                  ┬-┬ ⎛ new values(i) ⎞
+//   selectivity =  │ │ ⎜ ------------- ⎟
+//                  ┴ ┴ ⎝ old values(i) ⎠
+//                 i in
+//              {constrained
+//             columns with
+//              histograms}
+//
+// Unlike distinct counts, histograms capture skew in the data distribution, so
+// this produces more accurate estimates for predicates on frequent or
+// infrequent values and for range predicates.
+//
+// This algorithm assumes the columns are completely independent.
+//
+func (sb *statisticsBuilder) selectivityFromHistograms(
+	cols opt.ColSet, e RelExpr, s *props.Statistics,
+) (selectivity float64) {
+	selectivity = 1.0
+	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+		colStat, ok := s.ColStats.Lookup(util.MakeFastIntSet(col))
+		if !ok || colStat.Histogram == nil {
+			continue
+		}
+
+		inputStat := sb.colStatFromInput(colStat.Cols, e)
+		if inputStat.Histogram == nil {
+			continue
+		}
+		oldCount := inputStat.Histogram.ValuesCount()
+		// Don't estimate zero rows, since the histogram may be stale or may not
+		// include rare values.
+		newCount := max(colStat.Histogram.ValuesCount(), min(1, oldCount))
+
+		if oldCount != 0 && newCount < oldCount {
+			selectivity *= newCount / oldCount
+		}
+	}
+
+	return selectivity
+}
+
 // selectivityFromNullCounts calculates the selectivity of a filter from the number
 // of null values removed. This can be represented by this formula:
 //
@@ -121,7 +121,7 @@ func TestGetStatsFromConstraint(t *testing.T) {
 		s.Init(relProps)
 
 		// Calculate distinct counts.
-		numUnappliedConjuncts := sb.applyConstraintSet(cs, sel, relProps)
+		numUnappliedConjuncts, _ := sb.applyConstraintSet(cs, sel, relProps)
 
 		// Calculate row count and selectivity.
 		s.RowCount = scan.Relational().Stats.RowCount
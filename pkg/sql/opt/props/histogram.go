@@ -0,0 +1,368 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package props
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// unknownRangeFraction is the fraction of the values in the range of a
+// histogram bucket that are assumed to satisfy a span which partially
+// overlaps the range, when the overlap cannot be determined by interpolating
+// between the boundaries of the bucket.
+const unknownRangeFraction = 0.5
+
+// Histogram captures the distribution of values for a particular column within
+// a relational expression. It is initialized from the histogram in the table
+// statistics, and then filtered as constraints are applied to the column, or
+// scaled as the selectivity of other predicates is applied to the expression.
+//
+// A Histogram is immutable once initialized; Filter and ApplySelectivity
+// return new histograms. This allows the same Histogram to be shared between
+// the column statistics of several expressions.
+type Histogram struct {
+	evalCtx *tree.EvalContext
+	buckets []histogramBucket
+}
+
+// histogramBucket is a cat.HistogramBucket annotated with the estimated number
+// of distinct values in the range of the bucket.
+type histogramBucket struct {
+	cat.HistogramBucket
+
+	// DistinctRange is the estimated number of distinct values strictly between
+	// the upper bound of the previous bucket and UpperBound.
+	DistinctRange float64
+}
+
+// Init initializes the histogram with the given buckets, which must be sorted
+// by upper bound. distinctCount is the estimated number of distinct non-NULL
+// values in the column, and is used to estimate the number of distinct values
+// in the range of each bucket.
+func (h *Histogram) Init(
+	evalCtx *tree.EvalContext, buckets []cat.HistogramBucket, distinctCount float64,
+) {
+	h.evalCtx = evalCtx
+	h.buckets = make([]histogramBucket, len(buckets))
+
+	// Each upper bound with a non-zero NumEq is one of the distinct values. The
+	// remaining distinct values are distributed among the ranges of the buckets
+	// in proportion to the number of values in each range.
+	var numRange float64
+	distinctRange := distinctCount
+	for i := range buckets {
+		h.buckets[i].HistogramBucket = buckets[i]
+		numRange += buckets[i].NumRange
+		if buckets[i].NumEq > 0 {
+			distinctRange--
+		}
+	}
+	if distinctRange <= 0 || numRange == 0 {
+		return
+	}
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		b.DistinctRange = math.Min(distinctRange*b.NumRange/numRange, b.NumRange)
+	}
+}
+
+// BucketCount returns the number of buckets in the histogram.
+func (h *Histogram) BucketCount() int {
+	return len(h.buckets)
+}
+
+// Bucket returns the ith bucket of the histogram, where 0 <= i < BucketCount.
+func (h *Histogram) Bucket(i int) *cat.HistogramBucket {
+	return &h.buckets[i].HistogramBucket
+}
+
+// ValuesCount returns the estimated number of values in the histogram. Since
+// histograms do not include NULL values, this is the number of rows with a
+// non-NULL value in the column.
+func (h *Histogram) ValuesCount() float64 {
+	var count float64
+	for i := range h.buckets {
+		count += h.buckets[i].NumEq + h.buckets[i].NumRange
+	}
+	return count
+}
+
+// DistinctValuesCount returns the estimated number of distinct values in the
+// histogram.
+func (h *Histogram) DistinctValuesCount() float64 {
+	var count float64
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		count += b.DistinctRange
+		if b.NumEq > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// ApplySelectivity returns a new histogram in which the number of values in
+// each bucket is reduced according to the given selectivity, on the assumption
+// that the predicate which produced the selectivity is independent of the
+// column. The distinct counts are reduced with the same formula used by
+// ColumnStatistic.ApplySelectivity.
+func (h *Histogram) ApplySelectivity(selectivity float64) *Histogram {
+	res := &Histogram{evalCtx: h.evalCtx, buckets: make([]histogramBucket, len(h.buckets))}
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		nb := &res.buckets[i]
+		nb.UpperBound = b.UpperBound
+		nb.NumEq = b.NumEq * selectivity
+		nb.NumRange = b.NumRange * selectivity
+		if b.DistinctRange > 0 {
+			nb.DistinctRange = b.DistinctRange - b.DistinctRange*math.Pow(
+				1-selectivity, b.NumRange/b.DistinctRange,
+			)
+		}
+	}
+	return res
+}
+
+// CanFilter returns true if the given constraint can be used to filter the
+// histogram. The caller must ensure that the first column of the constraint
+// is the column of the histogram. Since histograms do not contain NULL values,
+// constraints with spans that explicitly include NULL values (for example,
+// the span produced by "x IS NULL") cannot be applied to the histogram.
+func (h *Histogram) CanFilter(c *constraint.Constraint) bool {
+	if c.IsUnconstrained() || c.IsContradiction() {
+		return false
+	}
+	desc := c.Columns.Get(0).Descending()
+	for i := 0; i < c.Spans.Count(); i++ {
+		bounds := makeSpanBounds(c.Spans.Get(i), desc)
+		if (bounds.low == tree.DNull && bounds.lowInclusive) ||
+			(bounds.high == tree.DNull && bounds.highInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns a new histogram containing only the values that satisfy the
+// spans of the given constraint on its first column. The values in the range
+// of a bucket that partially overlaps a span are estimated by interpolating
+// between the boundaries of the bucket if they are numeric or temporal
+// values, and are otherwise assumed to satisfy the span with a fixed
+// probability. CanFilter must be called before calling Filter.
+//
+// For example, consider this histogram, where the first bucket has no range:
+//
+//   {NumEq: 5, NumRange: 0, UpperBound: 1}
+//   {NumEq: 10, NumRange: 40, UpperBound: 10}
+//
+// Filtering with the constraint /1: [/1 - /5] results in this histogram:
+//
+//   {NumEq: 5, NumRange: 0, UpperBound: 1}
+//   {NumEq: 0, NumRange: 20, UpperBound: 10}
+//
+// since the second bucket contains 8 integers between 1 and 10 exclusive, and
+// 4 of them are within the span.
+func (h *Histogram) Filter(c *constraint.Constraint) *Histogram {
+	desc := c.Columns.Get(0).Descending()
+	spans := make([]spanBounds, c.Spans.Count())
+	for i := range spans {
+		spans[i] = makeSpanBounds(c.Spans.Get(i), desc)
+	}
+
+	res := &Histogram{evalCtx: h.evalCtx, buckets: make([]histogramBucket, len(h.buckets))}
+	var lower tree.Datum
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		nb := &res.buckets[i]
+		nb.UpperBound = b.UpperBound
+
+		var fraction float64
+		for j := range spans {
+			if spans[j].contains(h.evalCtx, b.UpperBound) {
+				nb.NumEq = b.NumEq
+			}
+			if spans[j].isPoint(h.evalCtx) {
+				// A single value matches approximately 1/DistinctRange of the values
+				// in the range, assuming a uniform distribution.
+				if spans[j].overlapsRange(h.evalCtx, lower, b.UpperBound) && b.DistinctRange > 0 {
+					fraction += 1 / b.DistinctRange
+				}
+				continue
+			}
+			fraction += spans[j].rangeFraction(h.evalCtx, lower, b.UpperBound)
+		}
+		fraction = math.Min(fraction, 1)
+		nb.NumRange = b.NumRange * fraction
+		nb.DistinctRange = b.DistinctRange * fraction
+		lower = b.UpperBound
+	}
+	return res
+}
+
+func (h *Histogram) String() string {
+	var buf bytes.Buffer
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		fmt.Fprintf(&buf, "{%.9g %.9g %.9g %s}", b.NumEq, b.NumRange, b.DistinctRange, b.UpperBound)
+	}
+	return buf.String()
+}
+
+// spanBounds contains the boundaries of a span on the first column of a
+// constraint, ordered so that low <= high. A nil boundary is unbounded.
+type spanBounds struct {
+	low, high                   tree.Datum
+	lowInclusive, highInclusive bool
+}
+
+func makeSpanBounds(sp *constraint.Span, desc bool) spanBounds {
+	var res spanBounds
+	start, startInclusive := keyBound(sp.StartKey(), sp.StartBoundary())
+	end, endInclusive := keyBound(sp.EndKey(), sp.EndBoundary())
+	if desc {
+		res.low, res.lowInclusive = end, endInclusive
+		res.high, res.highInclusive = start, startInclusive
+	} else {
+		res.low, res.lowInclusive = start, startInclusive
+		res.high, res.highInclusive = end, endInclusive
+	}
+	return res
+}
+
+// keyBound returns the value of the first column of the given span key, and
+// whether that value is included in the span. A key with more than one column
+// always includes some rows with the value of its first column.
+func keyBound(key constraint.Key, boundary constraint.SpanBoundary) (tree.Datum, bool) {
+	if key.IsEmpty() {
+		return nil, true
+	}
+	return key.Value(0), boundary == constraint.IncludeBoundary || key.Length() > 1
+}
+
+// isPoint returns true if the span contains a single value.
+func (sb *spanBounds) isPoint(evalCtx *tree.EvalContext) bool {
+	return sb.low != nil && sb.high != nil && sb.lowInclusive && sb.highInclusive &&
+		sb.low.Compare(evalCtx, sb.high) == 0
+}
+
+// contains returns true if the given value is within the span.
+func (sb *spanBounds) contains(evalCtx *tree.EvalContext, val tree.Datum) bool {
+	if sb.low != nil {
+		if cmp := val.Compare(evalCtx, sb.low); cmp < 0 || (cmp == 0 && !sb.lowInclusive) {
+			return false
+		}
+	}
+	if sb.high != nil {
+		if cmp := val.Compare(evalCtx, sb.high); cmp > 0 || (cmp == 0 && !sb.highInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapsRange returns true if the span overlaps the open interval
+// (lower, upper). A nil lower boundary is unbounded.
+func (sb *spanBounds) overlapsRange(evalCtx *tree.EvalContext, lower, upper tree.Datum) bool {
+	if sb.high != nil && lower != nil && sb.high.Compare(evalCtx, lower) <= 0 {
+		return false
+	}
+	if sb.low != nil && sb.low.Compare(evalCtx, upper) >= 0 {
+		return false
+	}
+	return true
+}
+
+// rangeFraction returns the estimated fraction of the values in the open
+// interval (lower, upper) that are within the span. A nil lower boundary is
+// unbounded.
+func (sb *spanBounds) rangeFraction(evalCtx *tree.EvalContext, lower, upper tree.Datum) float64 {
+	if !sb.overlapsRange(evalCtx, lower, upper) {
+		return 0
+	}
+	coversLow := sb.low == nil || (lower != nil && sb.low.Compare(evalCtx, lower) <= 0)
+	coversHigh := sb.high == nil || sb.high.Compare(evalCtx, upper) >= 0
+	if coversLow && coversHigh {
+		return 1
+	}
+	if lower == nil {
+		return unknownRangeFraction
+	}
+
+	// Interpolate between the boundaries of the bucket.
+	lo, loInclusive := lower, false
+	if !coversLow {
+		lo, loInclusive = sb.low, sb.lowInclusive
+	}
+	hi, hiInclusive := upper, false
+	if !coversHigh {
+		hi, hiInclusive = sb.high, sb.highInclusive
+	}
+
+	if _, ok := lower.(*tree.DInt); ok {
+		// Integers are discrete, so count the number of values in the range
+		// which are within the span.
+		total := float64(*upper.(*tree.DInt)) - float64(*lower.(*tree.DInt)) - 1
+		if total <= 0 {
+			return 0
+		}
+		count := float64(*hi.(*tree.DInt)) - float64(*lo.(*tree.DInt)) + 1
+		if !loInclusive {
+			count--
+		}
+		if !hiInclusive {
+			count--
+		}
+		return math.Max(0, math.Min(count/total, 1))
+	}
+
+	lowerVal, ok1 := datumToFloat(lower)
+	upperVal, ok2 := datumToFloat(upper)
+	loVal, ok3 := datumToFloat(lo)
+	hiVal, ok4 := datumToFloat(hi)
+	if !ok1 || !ok2 || !ok3 || !ok4 || upperVal <= lowerVal {
+		return unknownRangeFraction
+	}
+	return math.Max(0, math.Min((hiVal-loVal)/(upperVal-lowerVal), 1))
+}
+
+// datumToFloat converts numeric and temporal datums to a float64 which
+// preserves their ordering, so that they can be used for interpolation.
+func datumToFloat(d tree.Datum) (float64, bool) {
+	switch t := d.(type) {
+	case *tree.DInt:
+		return float64(*t), true
+	case *tree.DFloat:
+		return float64(*t), true
+	case *tree.DDecimal:
+		f, err := t.Float64()
+		return f, err == nil
+	case *tree.DDate:
+		return float64(*t), true
+	case *tree.DTimestamp:
+		return float64(t.UnixNano()), true
+	case *tree.DTimestampTZ:
+		return float64(t.UnixNano()), true
+	}
+	return 0, false
+}
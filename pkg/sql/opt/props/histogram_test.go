@@ -0,0 +1,157 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package props_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func TestHistogram(t *testing.T) {
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+
+	var h props.Histogram
+	h.Init(&evalCtx, []cat.HistogramBucket{
+		{NumEq: 5, NumRange: 0, UpperBound: tree.NewDInt(1)},
+		{NumEq: 10, NumRange: 40, UpperBound: tree.NewDInt(10)},
+		{NumEq: 20, NumRange: 30, UpperBound: tree.NewDInt(20)},
+	}, 15 /* distinctCount */)
+
+	if h.BucketCount() != 3 {
+		t.Fatalf("expected 3 buckets, found %d", h.BucketCount())
+	}
+	if actual := h.ValuesCount(); actual != 105 {
+		t.Errorf("expected 105 values, found %g", actual)
+	}
+	if actual := fmt.Sprintf("%.9g", h.DistinctValuesCount()); actual != "15" {
+		t.Errorf("expected 15 distinct values, found %s", actual)
+	}
+
+	testData := []struct {
+		constraint string
+		canFilter  bool
+		expected   string
+		values     float64
+	}{
+		{
+			constraint: "/1: [/1 - /5]",
+			canFilter:  true,
+			expected:   "{5 0 0 1} {0 20 3.42857143 10} {0 0 0 20}",
+			values:     25,
+		},
+		{
+			constraint: "/1: [/10 - /10]",
+			canFilter:  true,
+			expected:   "{0 0 0 1} {10 0 0 10} {0 0 0 20}",
+			values:     10,
+		},
+		{
+			constraint: "/1: [/15 - /15]",
+			canFilter:  true,
+			expected:   "{0 0 0 1} {0 0 0 10} {0 5.83333333 1 20}",
+			values:     30.0 / (12.0 * 30 / 70),
+		},
+		{
+			constraint: "/-1: [/15 - /5]",
+			canFilter:  true,
+			expected:   "{0 0 0 1} {10 25 4.28571429 10} {0 16.6666667 2.85714286 20}",
+			values:     10 + 25 + 30.0*5/9,
+		},
+		{
+			constraint: "/1: (/NULL - /1] [/20 - ]",
+			canFilter:  true,
+			expected:   "{5 0 0 1} {0 0 0 10} {20 0 0 20}",
+			values:     25,
+		},
+		{
+			constraint: "/1: [/NULL - /NULL]",
+			canFilter:  false,
+		},
+		{
+			constraint: "/1: [/NULL - /5]",
+			canFilter:  false,
+		},
+	}
+
+	for i := range testData {
+		tc := &testData[i]
+		t.Run(tc.constraint, func(t *testing.T) {
+			c := constraint.ParseConstraint(&evalCtx, tc.constraint)
+			if canFilter := h.CanFilter(&c); canFilter != tc.canFilter {
+				t.Fatalf("expected CanFilter=%t, found %t", tc.canFilter, canFilter)
+			}
+			if !tc.canFilter {
+				return
+			}
+			filtered := h.Filter(&c)
+			if actual := filtered.String(); actual != tc.expected {
+				t.Errorf("expected %s, found %s", tc.expected, actual)
+			}
+			if actual, expected := fmt.Sprintf("%.6g", filtered.ValuesCount()),
+				fmt.Sprintf("%.6g", tc.values); actual != expected {
+				t.Errorf("expected %s values, found %s", expected, actual)
+			}
+		})
+	}
+
+	// Filtering and applying a selectivity must not modify the original.
+	scaled := h.ApplySelectivity(0.5)
+	if actual := scaled.ValuesCount(); actual != 52.5 {
+		t.Errorf("expected 52.5 values, found %g", actual)
+	}
+	if actual := h.ValuesCount(); actual != 105 {
+		t.Errorf("expected original histogram to be unchanged, found %g values", actual)
+	}
+}
+
+func TestHistogramNonNumeric(t *testing.T) {
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+
+	var h props.Histogram
+	h.Init(&evalCtx, []cat.HistogramBucket{
+		{NumEq: 10, NumRange: 0, UpperBound: tree.NewDString("a")},
+		{NumEq: 10, NumRange: 20, UpperBound: tree.NewDString("m")},
+	}, 12 /* distinctCount */)
+
+	// Build the constraint /1: [/'c' - ].
+	var cols constraint.Columns
+	cols.InitSingle(opt.MakeOrderingColumn(1, false /* descending */))
+	keyCtx := constraint.MakeKeyContext(&cols, &evalCtx)
+	var sp constraint.Span
+	sp.Init(
+		constraint.MakeKey(tree.NewDString("c")), constraint.IncludeBoundary,
+		constraint.EmptyKey, constraint.IncludeBoundary,
+	)
+	var c constraint.Constraint
+	c.InitSingleSpan(&keyCtx, &sp)
+
+	if !h.CanFilter(&c) {
+		t.Fatalf("expected histogram to be filtered by %s", &c)
+	}
+
+	// The bucket boundaries can't be interpolated, so half of the values in the
+	// range of the second bucket are assumed to satisfy the constraint.
+	expected := "{0 0 0 'a'} {10 10 5 'm'}"
+	if actual := h.Filter(&c).String(); actual != expected {
+		t.Errorf("expected %s, found %s", expected, actual)
+	}
+}
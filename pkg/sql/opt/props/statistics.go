@@ -142,11 +142,23 @@ type ColumnStatistic struct {
 	// count tracks all instances of at least one null value in the
 	// column set.
 	NullCount float64
+
+	// Histogram is only used when the size of Cols is one. It contains the
+	// approximate distribution of the non-NULL values of that column, and is
+	// nil if no histogram is available.
+	Histogram *Histogram
 }
 
-// ApplySelectivity updates the distinct count according to a given selectivity.
+// ApplySelectivity updates the distinct count and histogram according to a
+// given selectivity.
 func (c *ColumnStatistic) ApplySelectivity(selectivity, inputRows float64) {
-	if selectivity == 1 || c.DistinctCount == 0 {
+	if selectivity == 1 {
+		return
+	}
+	if c.Histogram != nil {
+		c.Histogram = c.Histogram.ApplySelectivity(selectivity)
+	}
+	if c.DistinctCount == 0 {
 		return
 	}
 	if selectivity == 0 {
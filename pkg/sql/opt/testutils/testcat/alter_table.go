@@ -20,6 +20,9 @@ import (
 	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -72,6 +75,7 @@ func injectTableStats(tt *Table, statsExpr tree.Expr) {
 	tt.Stats = make([]*TableStat, len(stats))
 	for i := range stats {
 		tt.Stats[i] = &TableStat{js: stats[i], tt: tt}
+		tt.Stats[i].histogram = makeHistogram(&evalCtx, &stats[i])
 	}
 	// Call ColumnOrdinal on all possible columns to assert that
 	// the column names are valid.
@@ -84,3 +88,30 @@ func injectTableStats(tt *Table, statsExpr tree.Expr) {
 	// Finally, sort the stats with most recent first.
 	sort.Sort(tt.Stats)
 }
+
+// makeHistogram converts the histogram buckets in the given JSON statistic to
+// the format used by the cat.TableStatistic interface.
+func makeHistogram(evalCtx *tree.EvalContext, js *stats.JSONStatistic) []cat.HistogramBucket {
+	if len(js.HistogramBuckets) == 0 {
+		return nil
+	}
+	colType, err := parser.ParseType(js.HistogramColumnType)
+	if err != nil {
+		panic(err)
+	}
+	typ := coltypes.CastTargetToDatumType(colType)
+	histogram := make([]cat.HistogramBucket, len(js.HistogramBuckets))
+	for i := range js.HistogramBuckets {
+		b := &js.HistogramBuckets[i]
+		upperBound, err := tree.ParseStringAs(typ, b.UpperBound, evalCtx)
+		if err != nil {
+			panic(err)
+		}
+		histogram[i] = cat.HistogramBucket{
+			NumEq:      float64(b.NumEq),
+			NumRange:   float64(b.NumRange),
+			UpperBound: upperBound,
+		}
+	}
+	return histogram
+}
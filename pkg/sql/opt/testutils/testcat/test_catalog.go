@@ -550,8 +550,9 @@ func (tc *Column) ComputedExprStr() string {
 
 // TableStat implements the cat.TableStatistic interface for testing purposes.
 type TableStat struct {
-	js stats.JSONStatistic
-	tt *Table
+	js        stats.JSONStatistic
+	tt        *Table
+	histogram []cat.HistogramBucket
 }
 
 var _ cat.TableStatistic = &TableStat{}
@@ -590,6 +591,11 @@ func (ts *TableStat) NullCount() uint64 {
 	return ts.js.NullCount
 }
 
+// Histogram is part of the cat.TableStatistic interface.
+func (ts *TableStat) Histogram() []cat.HistogramBucket {
+	return ts.histogram
+}
+
 // TableStats is a slice of TableStat pointers.
 type TableStats []*TableStat
 
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
 
 // optCatalog implements the cat.Catalog interface over the SchemaResolver
@@ -573,6 +574,7 @@ type optTableStat struct {
 	rowCount       uint64
 	distinctCount  uint64
 	nullCount      uint64
+	histogram      []cat.HistogramBucket
 }
 
 var _ cat.TableStatistic = &optTableStat{}
@@ -593,6 +595,27 @@ func (os *optTableStat) init(tab *optTable, stat *stats.TableStatistic) (ok bool
 			return false
 		}
 	}
+
+	if stat.Histogram != nil && len(stat.ColumnIDs) == 1 {
+		typ := stat.Histogram.ColumnType.ToDatumType()
+		var a sqlbase.DatumAlloc
+		os.histogram = make([]cat.HistogramBucket, len(stat.Histogram.Buckets))
+		for i := range stat.Histogram.Buckets {
+			b := &stat.Histogram.Buckets[i]
+			datum, _, err := sqlbase.DecodeTableKey(&a, typ, b.UpperBound, encoding.Ascending)
+			if err != nil {
+				// The histogram is only used to improve estimates, so ignore it rather
+				// than discarding the whole statistic.
+				os.histogram = nil
+				break
+			}
+			os.histogram[i] = cat.HistogramBucket{
+				NumEq:      float64(b.NumEq),
+				NumRange:   float64(b.NumRange),
+				UpperBound: datum,
+			}
+		}
+	}
 	return true
 }
 
@@ -625,3 +648,8 @@ func (os *optTableStat) DistinctCount() uint64 {
 func (os *optTableStat) NullCount() uint64 {
 	return os.nullCount
 }
+
+// Histogram is part of the cat.TableStatistic interface.
+func (os *optTableStat) Histogram() []cat.HistogramBucket {
+	return os.histogram
+}
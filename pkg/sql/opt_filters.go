@@ -354,6 +354,11 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *controlSchedulesNode:
+		if n.rows, err = p.triggerFilterPropagation(ctx, n.rows); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *projectSetNode:
 		// TODO(knz): we can propagate the part of the filter that applies
 		// to the source columns.
@@ -362,6 +367,7 @@ func (p *planner) propagateFilters(
 		}
 
 	case *alterIndexNode:
+	case *alterJobNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
@@ -377,6 +383,7 @@ func (p *planner) propagateFilters(
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
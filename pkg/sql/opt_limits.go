@@ -204,9 +204,13 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *controlJobsNode:
 		p.setUnlimited(n.rows)
 
+	case *controlSchedulesNode:
+		p.setUnlimited(n.rows)
+
 	case *valuesNode:
 	case *virtualTableNode:
 	case *alterIndexNode:
+	case *alterJobNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
@@ -222,6 +226,7 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
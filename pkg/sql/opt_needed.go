@@ -254,7 +254,11 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *controlJobsNode:
 		setNeededColumns(n.rows, allColumns(n.rows))
 
+	case *controlSchedulesNode:
+		setNeededColumns(n.rows, allColumns(n.rows))
+
 	case *alterIndexNode:
+	case *alterJobNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
@@ -270,6 +274,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
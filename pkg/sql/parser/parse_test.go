@@ -363,6 +363,9 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN RESUME JOBS SELECT a`},
 		{`PAUSE JOBS SELECT a`},
 		{`EXPLAIN PAUSE JOBS SELECT a`},
+		{`ALTER JOB 123 SET max_concurrency = '2'`},
+		{`ALTER JOB a SET max_bytes_per_sec = '10MiB', locality = 'region=us-east'`},
+		{`ALTER JOB $1 SET max_bytes_per_sec = $2`},
 
 		{`EXPLAIN SELECT 1`},
 		{`EXPLAIN EXPLAIN SELECT 1`},
@@ -1183,6 +1186,13 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW BACKUP 'bar'`},
 		{`SHOW BACKUP RANGES 'bar'`},
 		{`SHOW BACKUP FILES 'bar'`},
+		{`SHOW BACKUP 'bar' WITH encryption_passphrase = 'secret'`},
+		{`SHOW BACKUP FILES 'bar' WITH encryption_passphrase = $1`},
+		{`SHOW BACKUP 'bar', 'baz'`},
+		{`SHOW BACKUP REVISIONS 'bar'`},
+		{`SHOW BACKUP FILES 'bar', $1 WITH encryption_passphrase = 'secret'`},
+		{`SHOW BACKUP ('bar', 'baz'), 'qux'`},
+		{`SHOW BACKUP FILES ('bar', $1) WITH check_files`},
 
 		{`BACKUP TABLE foo TO 'bar' AS OF SYSTEM TIME '1' INCREMENTAL FROM 'baz'`},
 		{`BACKUP TABLE foo TO $1 INCREMENTAL FROM 'bar', $2, 'baz'`},
@@ -1207,12 +1217,39 @@ func TestParse(t *testing.T) {
 		{`BACKUP TABLE foo TO 'bar' WITH key1, key2 = 'value'`},
 		{`RESTORE TABLE foo FROM 'bar' WITH key1, key2 = 'value'`},
 
+		{`BACKUP TABLE foo TO ('bar', 'baz')`},
+		{`BACKUP TO ($1, $2) INCREMENTAL FROM 'baz'`},
+		{`RESTORE TABLE foo FROM ('bar', 'baz')`},
+		{`RESTORE TABLE foo FROM 'bar', ($1, 'baz')`},
+		{`RESTORE FROM ($1, $2), ('bar', 'baz') AS OF SYSTEM TIME '1'`},
+
+		{`BACKUP TO 'bar'`},
+		{`EXPLAIN BACKUP TO 'bar'`},
+		{`BACKUP TO $1 AS OF SYSTEM TIME '1' INCREMENTAL FROM 'baz' WITH key1`},
+		{`RESTORE FROM 'bar'`},
+		{`EXPLAIN RESTORE FROM 'bar'`},
+		{`RESTORE FROM $1, 'bar' AS OF SYSTEM TIME '1' WITH force_nonempty_cluster`},
+		{`CREATE SCHEDULE FOR BACKUP TO 'bar' RECURRING '@daily'`},
+
+		{`CREATE SCHEDULE FOR BACKUP DATABASE foo TO 'bar' RECURRING '@daily'`},
+		{`CREATE SCHEDULE 'nightly' FOR BACKUP TABLE foo, baz TO 'bar' RECURRING '0 2 * * *'`},
+		{`CREATE SCHEDULE $1 FOR BACKUP DATABASE foo TO $2 RECURRING $3`},
+		{`CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE foo TO 'bar' WITH key1 RECURRING '@daily' WITH SCHEDULE OPTIONS on_previous_running = 'skip', on_execution_failure = 'pause'`},
+		{`SHOW SCHEDULES`},
+		{`EXPLAIN SHOW SCHEDULES`},
+		{`PAUSE SCHEDULES SELECT a`},
+		{`RESUME SCHEDULES SELECT a`},
+		{`DROP SCHEDULES SELECT a`},
+
 		{`IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`EXPLAIN IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' MYSQLOUTFILE DATA ('path/to/some/file', $1)`},
 		{`IMPORT TABLE foo (id INT8 PRIMARY KEY, email STRING, age INT8) CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`IMPORT TABLE foo (id INT8, email STRING, age INT8) CSV DATA ('path/to/some/file', $1) WITH comma = ',', "nullif" = 'n/a', temp = $2`},
 		{`IMPORT TABLE foo FROM PGDUMPCREATE 'nodelocal:///foo/bar' WITH temp = 'path/to/temp'`},
+		{`IMPORT INTO foo CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
+		{`IMPORT INTO foo (id, email, age) CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
+		{`EXPLAIN IMPORT INTO foo (id) AVRO DATA ('path/to/some/file')`},
 
 		{`IMPORT PGDUMP 'nodelocal:///foo/bar' WITH temp = 'path/to/temp'`},
 		{`EXPLAIN IMPORT PGDUMP 'nodelocal:///foo/bar' WITH temp = 'path/to/temp'`},
@@ -1233,6 +1270,9 @@ func TestParse(t *testing.T) {
 		// {`CREATE CHANGEFEED FOR TABLE foo PARTITION bar, baz INTO 'sink'`},
 		// {`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH bar = 'baz'`},
+		{`CREATE CHANGEFEED AS SELECT * FROM foo`},
+		{`CREATE CHANGEFEED INTO 'sink' AS SELECT a, b + 1 AS c FROM foo WHERE a > 1`},
+		{`CREATE CHANGEFEED INTO 'sink' WITH bar = 'baz' AS SELECT a FROM db.foo`},
 
 		// Regression for #15926
 		{`SELECT * FROM ((t1 NATURAL JOIN t2 WITH ORDINALITY AS o1)) WITH ORDINALITY AS o2`},
@@ -1652,6 +1692,11 @@ func TestParse2(t *testing.T) {
 		{`CANCEL JOB a`, `CANCEL JOBS VALUES (a)`},
 		{`RESUME JOB a`, `RESUME JOBS VALUES (a)`},
 		{`PAUSE JOB a`, `PAUSE JOBS VALUES (a)`},
+		{`ALTER JOB a PAUSE`, `PAUSE JOBS VALUES (a)`},
+		{`ALTER JOB a RESUME`, `RESUME JOBS VALUES (a)`},
+		{`PAUSE SCHEDULE a`, `PAUSE SCHEDULES VALUES (a)`},
+		{`RESUME SCHEDULE a`, `RESUME SCHEDULES VALUES (a)`},
+		{`DROP SCHEDULE a`, `DROP SCHEDULES VALUES (a)`},
 		{`CANCEL QUERY a`, `CANCEL QUERIES VALUES (a)`},
 		{`CANCEL QUERY IF EXISTS a`, `CANCEL QUERIES IF EXISTS VALUES (a)`},
 		{`CANCEL SESSION a`, `CANCEL SESSIONS VALUES (a)`},
@@ -1663,6 +1708,10 @@ func TestParse2(t *testing.T) {
 			`BACKUP DATABASE foo TO 'bar.12' INCREMENTAL FROM 'baz.34'`},
 		{`RESTORE DATABASE foo FROM bar`,
 			`RESTORE DATABASE foo FROM 'bar'`},
+		{`BACKUP DATABASE foo TO ('bar')`,
+			`BACKUP DATABASE foo TO 'bar'`},
+		{`RESTORE DATABASE foo FROM ('bar'), ('baz', 'qux')`,
+			`RESTORE DATABASE foo FROM 'bar', ('baz', 'qux')`},
 
 		{`CREATE CHANGEFEED FOR TABLE foo INTO sink`,
 			`CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
@@ -1763,6 +1812,10 @@ func TestParse2(t *testing.T) {
 			`RESTORE TABLE foo FROM 'bar' WITH key1, key2 = 'value'`},
 
 		{`CREATE CHANGEFEED FOR foo INTO 'sink'`, `CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`CREATE CHANGEFEED INTO sink AS SELECT a FROM foo`, `CREATE CHANGEFEED INTO 'sink' AS SELECT a FROM foo`},
+		{`EXPERIMENTAL CHANGEFEED FOR foo`, `CREATE CHANGEFEED FOR TABLE foo`},
+		{`EXPERIMENTAL CHANGEFEED FOR TABLE foo, bar WITH cursor = '1'`,
+			`CREATE CHANGEFEED FOR TABLE foo, bar WITH cursor = '1'`},
 
 		{`GRANT SELECT ON foo TO root`,
 			`GRANT SELECT ON TABLE foo TO root`},
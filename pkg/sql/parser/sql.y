@@ -244,6 +244,12 @@ func (u *sqlSymUnion) expr() tree.Expr {
 func (u *sqlSymUnion) exprs() tree.Exprs {
     return u.val.(tree.Exprs)
 }
+func (u *sqlSymUnion) stringOrPlaceholderOptList() tree.StringOrPlaceholderOptList {
+    return u.val.(tree.StringOrPlaceholderOptList)
+}
+func (u *sqlSymUnion) listOfStringOrPlaceholderOptList() []tree.StringOrPlaceholderOptList {
+    return u.val.([]tree.StringOrPlaceholderOptList)
+}
 func (u *sqlSymUnion) selExpr() tree.SelectExpr {
     return u.val.(tree.SelectExpr)
 }
@@ -524,13 +530,13 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
+%token <str> RANGE RANGES READ REAL RECURRING RECURSIVE REF REFERENCES
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
+%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVISIONS REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str> SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
@@ -583,6 +589,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> alter_database_stmt
 %type <tree.Statement> alter_user_stmt
 %type <tree.Statement> alter_range_stmt
+%type <tree.Statement> alter_job_stmt
 
 // ALTER RANGE
 %type <tree.Statement> alter_zone_range_stmt
@@ -645,6 +652,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_index_stmt
 %type <tree.Statement> create_role_stmt
+%type <tree.Statement> create_schedule_stmt
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
 %type <tree.Statement> create_user_stmt
@@ -660,6 +668,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
 %type <tree.Statement> drop_role_stmt
+%type <tree.Statement> drop_schedule_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_user_stmt
 %type <tree.Statement> drop_view_stmt
@@ -675,9 +684,13 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> insert_stmt
 %type <tree.Statement> import_stmt
 %type <tree.Statement> pause_stmt
+%type <tree.Statement> pause_jobs_stmt
+%type <tree.Statement> pause_schedules_stmt
 %type <tree.Statement> release_stmt
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <tree.Statement> resume_stmt
+%type <tree.Statement> resume_jobs_stmt
+%type <tree.Statement> resume_schedules_stmt
 %type <tree.Statement> restore_stmt
 %type <tree.Statement> revoke_stmt
 %type <*tree.Select> select_stmt
@@ -709,6 +722,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> show_queries_stmt
 %type <tree.Statement> show_ranges_stmt
 %type <tree.Statement> show_roles_stmt
+%type <tree.Statement> show_schedules_stmt
 %type <tree.Statement> show_schemas_stmt
 %type <tree.Statement> show_session_stmt
 %type <tree.Statement> show_sessions_stmt
@@ -732,6 +746,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <[]string> opt_incremental
 %type <tree.KVOption> kv_option
 %type <[]tree.KVOption> kv_option_list opt_with_options var_set_list
+%type <[]tree.KVOption> opt_with_schedule_options
 %type <str> import_format
 
 %type <*tree.Select> select_no_parens
@@ -913,7 +928,10 @@ func newNameFromStr(s string) *tree.Name {
 %type <str> non_reserved_word_or_sconst
 %type <tree.Expr> zone_value
 %type <tree.Expr> string_or_placeholder
+%type <tree.Expr> opt_schedule_label
 %type <tree.Expr> string_or_placeholder_list
+%type <tree.StringOrPlaceholderOptList> string_or_placeholder_opt_list
+%type <[]tree.StringOrPlaceholderOptList> list_of_string_or_placeholder_opt_list
 
 %type <str> unreserved_keyword type_func_name_keyword cockroachdb_extra_type_func_name_keyword
 %type <str> col_name_keyword reserved_keyword cockroachdb_extra_reserved_keyword extra_var_value
@@ -1054,10 +1072,11 @@ stmt:
 
 // %Help: ALTER
 // %Category: Group
-// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER
+// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER, ALTER JOB
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_user_stmt     // EXTEND WITH HELP: ALTER USER
+| alter_job_stmt      // EXTEND WITH HELP: ALTER JOB
 | ALTER error         // SHOW HELP: ALTER
 
 alter_ddl_stmt:
@@ -1175,6 +1194,45 @@ alter_user_stmt:
   alter_user_password_stmt
 | ALTER USER error // SHOW HELP: ALTER USER
 
+// %Help: ALTER JOB - change the resource limits or state of a background job
+// %Category: Misc
+// %Text:
+// ALTER JOB <jobid> SET <option> = <value> [, ...]
+// ALTER JOB <jobid> PAUSE
+// ALTER JOB <jobid> RESUME
+//
+// Options:
+//    max_concurrency = '<n>'      limit the number of concurrent workers
+//    max_bytes_per_sec = '<size>' limit the rate at which data is written
+//    locality = '<tiers>'         only run on nodes with the given locality
+//
+// An empty or zero value removes the limit.
+// %SeeAlso: SHOW JOBS, PAUSE JOBS, RESUME JOBS
+alter_job_stmt:
+  ALTER JOB a_expr SET kv_option_list
+  {
+    $$.val = &tree.AlterJob{Job: $3.expr(), Options: $5.kvOptions()}
+  }
+| ALTER JOB a_expr PAUSE
+  {
+    $$.val = &tree.ControlJobs{
+      Jobs: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.PauseJob,
+    }
+  }
+| ALTER JOB a_expr RESUME
+  {
+    $$.val = &tree.ControlJobs{
+      Jobs: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.ResumeJob,
+    }
+  }
+| ALTER JOB error // SHOW HELP: ALTER JOB
+
 // %Help: ALTER DATABASE - change the definition of a database
 // %Category: DDL
 // %Text:
@@ -1646,7 +1704,7 @@ opt_validate_behavior:
 // %Help: BACKUP - back up data to external storage
 // %Category: CCL
 // %Text:
-// BACKUP <targets...> TO <location...>
+// BACKUP [ <targets...> ] TO <location...>
 //        [ AS OF SYSTEM TIME <expr> ]
 //        [ INCREMENTAL FROM <location...> ]
 //        [ WITH <option> [= <value>] [, ...] ]
@@ -1655,25 +1713,38 @@ opt_validate_behavior:
 //    TABLE <pattern> [, ...]
 //    DATABASE <databasename> [, ...]
 //
+// Without targets, the whole cluster is backed up: all user databases
+// and tables, along with the users, role memberships, zone configurations,
+// cluster settings, comments and jobs stored in the system tables.
+//
 // Location:
 //    "[scheme]://[host]/[path to backup]?[parameters]"
 //
+// A parenthesized list of locations makes a locality-aware backup: each
+// range is written to the location whose COCKROACH_LOCALITY parameter
+// matches a locality tier (e.g. "region=eu") of the node exporting it, or
+// to the one with COCKROACH_LOCALITY=default if none matches.
+//
 // Options:
 //    INTO_DB
 //    SKIP_MISSING_FOREIGN_KEYS
 //
 // %SeeAlso: RESTORE, WEBDOCS/backup.html
 backup_stmt:
-  BACKUP targets TO string_or_placeholder opt_as_of_clause opt_incremental opt_with_options
+  BACKUP targets TO string_or_placeholder_opt_list opt_as_of_clause opt_incremental opt_with_options
   {
-    $$.val = &tree.Backup{Targets: $2.targetList(), To: $4.expr(), IncrementalFrom: $6.exprs(), AsOf: $5.asOfClause(), Options: $7.kvOptions()}
+    $$.val = &tree.Backup{Targets: $2.targetList(), To: $4.stringOrPlaceholderOptList(), IncrementalFrom: $6.exprs(), AsOf: $5.asOfClause(), Options: $7.kvOptions()}
+  }
+| BACKUP TO string_or_placeholder_opt_list opt_as_of_clause opt_incremental opt_with_options
+  {
+    $$.val = &tree.Backup{DescriptorCoverage: tree.AllDescriptors, To: $3.stringOrPlaceholderOptList(), IncrementalFrom: $5.exprs(), AsOf: $4.asOfClause(), Options: $6.kvOptions()}
   }
 | BACKUP error // SHOW HELP: BACKUP
 
 // %Help: RESTORE - restore data from external storage
 // %Category: CCL
 // %Text:
-// RESTORE <targets...> FROM <location...>
+// RESTORE [ <targets...> ] FROM <location...>
 //         [ AS OF SYSTEM TIME <expr> ]
 //         [ WITH <option> [= <value>] [, ...] ]
 //
@@ -1681,22 +1752,43 @@ backup_stmt:
 //    TABLE <pattern> [, ...]
 //    DATABASE <databasename> [, ...]
 //
+// Without targets, a full cluster backup is restored. This requires a
+// cluster with no user databases or tables unless FORCE_NONEMPTY_CLUSTER
+// is given.
+//
 // Locations:
 //    "[scheme]://[host]/[path to backup]?[parameters]"
 //
+// A locality-aware backup is restored from a parenthesized list of all of
+// its locations, with the same COCKROACH_LOCALITY parameters used by the
+// BACKUP.
+//
 // Options:
 //    INTO_DB
 //    SKIP_MISSING_FOREIGN_KEYS
+//    FORCE_NONEMPTY_CLUSTER
+//    VERIFY_ONLY
+//
+// With VERIFY_ONLY, the backups are read and checked, and any problems
+// found are reported, without restoring anything.
 //
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
-  RESTORE targets FROM string_or_placeholder_list opt_with_options
+  RESTORE targets FROM list_of_string_or_placeholder_opt_list opt_with_options
   {
-    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.exprs(), Options: $5.kvOptions()}
+    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.listOfStringOrPlaceholderOptList(), Options: $5.kvOptions()}
   }
-| RESTORE targets FROM string_or_placeholder_list as_of_clause opt_with_options
+| RESTORE targets FROM list_of_string_or_placeholder_opt_list as_of_clause opt_with_options
   {
-    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.exprs(), AsOf: $5.asOfClause(), Options: $6.kvOptions()}
+    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.listOfStringOrPlaceholderOptList(), AsOf: $5.asOfClause(), Options: $6.kvOptions()}
+  }
+| RESTORE FROM list_of_string_or_placeholder_opt_list opt_with_options
+  {
+    $$.val = &tree.Restore{DescriptorCoverage: tree.AllDescriptors, From: $3.listOfStringOrPlaceholderOptList(), Options: $4.kvOptions()}
+  }
+| RESTORE FROM list_of_string_or_placeholder_opt_list as_of_clause opt_with_options
+  {
+    $$.val = &tree.Restore{DescriptorCoverage: tree.AllDescriptors, From: $3.listOfStringOrPlaceholderOptList(), AsOf: $4.asOfClause(), Options: $5.kvOptions()}
   }
 | RESTORE error // SHOW HELP: RESTORE
 
@@ -1721,12 +1813,22 @@ import_format:
 //        DATA ( <datafile> [, ...] )
 //        [ WITH <option> [= <value>] [, ...] ]
 //
+// -- Import table data into an existing table, which is offline until the
+// -- import completes:
+// IMPORT INTO <tablename> [ ( <colnames...> ) ]
+//        <format>
+//        DATA ( <datafile> [, ...] )
+//        [ WITH <option> [= <value>] [, ...] ]
+//
 // Formats:
 //    CSV
 //    MYSQLOUTFILE
 //    MYSQLDUMP (mysqldump's SQL output)
 //    PGCOPY
 //    PGDUMP
+//    AVRO (object container files)
+//    PARQUET
+//    NDJSON (newline-delimited JSON objects)
 //
 // Options:
 //    distributed = '...'
@@ -1735,6 +1837,7 @@ import_format:
 //    delimiter = '...'      [CSV, PGCOPY-specific]
 //    nullif = '...'         [CSV, PGCOPY-specific]
 //    comment = '...'        [CSV-specific]
+//    max_row_size = '...'   [PGCOPY, PGDUMP, NDJSON-specific]
 //
 // %SeeAlso: CREATE TABLE
 import_stmt:
@@ -1784,6 +1887,24 @@ import_stmt:
     }
     $$.val = &tree.Import{Table: &name, CreateDefs: $5.tblDefs(), FileFormat: $7, Files: $10.exprs(), Options: $12.kvOptions()}
   }
+| IMPORT INTO table_name '(' insert_column_list ')' import_format DATA '(' string_or_placeholder_list ')' opt_with_options
+  {
+    name, err := tree.NormalizeTableName($3.unresolvedName())
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = &tree.Import{Table: &name, Into: true, IntoCols: $5.nameList(), FileFormat: $7, Files: $10.exprs(), Options: $12.kvOptions()}
+  }
+| IMPORT INTO table_name import_format DATA '(' string_or_placeholder_list ')' opt_with_options
+  {
+    name, err := tree.NormalizeTableName($3.unresolvedName())
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = &tree.Import{Table: &name, Into: true, FileFormat: $4, Files: $7.exprs(), Options: $9.kvOptions()}
+  }
 | IMPORT error // SHOW HELP: IMPORT
 
 // %Help: EXPORT - export data to file in a distributed manner
@@ -1793,9 +1914,13 @@ import_stmt:
 //
 // Formats:
 //    CSV
+//    PARQUET
+//    AVRO
 //
 // Options:
+//    chunk_rows = '...'
 //    delimiter = '...'   [CSV-specific]
+//    nullas = '...'      [CSV-specific]
 //
 // %SeeAlso: SELECT
 export_stmt:
@@ -1825,6 +1950,26 @@ string_or_placeholder_list:
     $$.val = append($1.exprs(), $3.expr())
   }
 
+string_or_placeholder_opt_list:
+  string_or_placeholder
+  {
+    $$.val = tree.StringOrPlaceholderOptList{$1.expr()}
+  }
+| '(' string_or_placeholder_list ')'
+  {
+    $$.val = tree.StringOrPlaceholderOptList($2.exprs())
+  }
+
+list_of_string_or_placeholder_opt_list:
+  string_or_placeholder_opt_list
+  {
+    $$.val = []tree.StringOrPlaceholderOptList{$1.stringOrPlaceholderOptList()}
+  }
+| list_of_string_or_placeholder_opt_list ',' string_or_placeholder_opt_list
+  {
+    $$.val = append($1.listOfStringOrPlaceholderOptList(), $3.stringOrPlaceholderOptList())
+  }
+
 opt_incremental:
   INCREMENTAL FROM string_or_placeholder_list
   {
@@ -2031,12 +2176,13 @@ comment_text:
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 // CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-// CREATE ROLE
+// CREATE ROLE, CREATE SCHEDULE
 create_stmt:
   create_user_stmt     // EXTEND WITH HELP: CREATE USER
 | create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
+| create_schedule_stmt // EXTEND WITH HELP: CREATE SCHEDULE
 | create_unsupported   {}
 | CREATE error         // SHOW HELP: CREATE
 
@@ -2127,6 +2273,51 @@ create_stats_stmt:
   }
 | CREATE STATISTICS error // SHOW HELP: CREATE STATISTICS
 
+// %Help: CREATE SCHEDULE - run a BACKUP periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [<label>] FOR <backup statement>
+//   RECURRING <cron expression>
+//   [ WITH SCHEDULE OPTIONS <option> [= <value>] [, ...] ]
+//
+// Cron expression:
+//    "<minute> <hour> <day of month> <month> <day of week>"
+//    @yearly, @monthly, @weekly, @daily, @hourly
+//
+// Schedule options:
+//    on_previous_running = 'start' | 'skip' | 'wait'
+//    on_execution_failure = 'retry' | 'reschedule' | 'pause'
+//
+// %SeeAlso: BACKUP, SHOW SCHEDULES, PAUSE SCHEDULES, RESUME SCHEDULES, DROP SCHEDULES
+create_schedule_stmt:
+  CREATE SCHEDULE opt_schedule_label FOR backup_stmt RECURRING string_or_placeholder opt_with_schedule_options
+  {
+    $$.val = &tree.CreateSchedule{
+      Label: $3.expr(),
+      Backup: $5.stmt().(*tree.Backup),
+      Recurrence: $7.expr(),
+      Options: $8.kvOptions(),
+    }
+  }
+| CREATE SCHEDULE error // SHOW HELP: CREATE SCHEDULE
+
+opt_schedule_label:
+  string_or_placeholder
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
+opt_with_schedule_options:
+  WITH SCHEDULE OPTIONS kv_option_list
+  {
+    $$.val = $4.kvOptions()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
 opt_stats_columns:
   ON name_list
   {
@@ -2164,6 +2355,21 @@ create_changefeed_stmt:
       Options: $6.kvOptions(),
     }
   }
+| CREATE CHANGEFEED opt_changefeed_sink opt_with_options AS select_stmt
+  {
+    $$.val = &tree.CreateChangefeed{
+      SinkURI: $3.expr(),
+      Options: $4.kvOptions(),
+      Select:  $6.slct(),
+    }
+  }
+| EXPERIMENTAL CHANGEFEED FOR changefeed_targets opt_with_options
+  {
+    $$.val = &tree.CreateChangefeed{
+      Targets: $4.targetList(),
+      Options: $5.kvOptions(),
+    }
+  }
 
 changefeed_targets:
   single_table_pattern_list
@@ -2236,11 +2442,12 @@ discard_stmt:
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE
+// DROP USER, DROP ROLE, DROP SCHEDULES
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
+| drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULES
 | drop_unsupported   {}
 | DROP error         // SHOW HELP: DROP
 
@@ -2281,6 +2488,29 @@ drop_sequence_stmt:
   }
 | DROP SEQUENCE error // SHOW HELP: DROP VIEW
 
+// %Help: DROP SCHEDULES - remove schedules
+// %Category: Misc
+// %Text:
+// DROP SCHEDULES <selectclause>
+// DROP SCHEDULE <scheduleid>
+// %SeeAlso: SHOW SCHEDULES, PAUSE SCHEDULES, RESUME SCHEDULES
+drop_schedule_stmt:
+  DROP SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.DropSchedule,
+    }
+  }
+| DROP SCHEDULE error // SHOW HELP: DROP SCHEDULES
+| DROP SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.DropSchedule}
+  }
+| DROP SCHEDULES error // SHOW HELP: DROP SCHEDULES
+
 // %Help: DROP TABLE - remove a table
 // %Category: DDL
 // %Text: DROP TABLE [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
@@ -2442,10 +2672,10 @@ preparable_stmt:
 | explain_stmt      // EXTEND WITH HELP: EXPLAIN
 | import_stmt       // EXTEND WITH HELP: IMPORT
 | insert_stmt       // EXTEND WITH HELP: INSERT
-| pause_stmt        // EXTEND WITH HELP: PAUSE JOBS
+| pause_stmt        // help texts in sub-rule
 | reset_stmt        // help texts in sub-rule
 | restore_stmt      // EXTEND WITH HELP: RESTORE
-| resume_stmt       // EXTEND WITH HELP: RESUME JOBS
+| resume_stmt       // help texts in sub-rule
 | scrub_stmt        // help texts in sub-rule
 | select_stmt       // help texts in sub-rule
   {
@@ -3046,8 +3276,9 @@ zone_value:
 // %Text:
 // SHOW BACKUP, SHOW CLUSTER SETTING, SHOW COLUMNS, SHOW CONSTRAINTS,
 // SHOW CREATE, SHOW DATABASES, SHOW HISTOGRAM, SHOW INDEXES, SHOW JOBS,
-// SHOW QUERIES, SHOW ROLES, SHOW SESSION, SHOW SESSIONS, SHOW STATISTICS,
-// SHOW SYNTAX, SHOW TABLES, SHOW TRACE SHOW TRANSACTION, SHOW USERS
+// SHOW QUERIES, SHOW ROLES, SHOW SCHEDULES, SHOW SESSION, SHOW SESSIONS,
+// SHOW STATISTICS, SHOW SYNTAX, SHOW TABLES, SHOW TRACE SHOW TRANSACTION,
+// SHOW USERS
 show_stmt:
   show_backup_stmt          // EXTEND WITH HELP: SHOW BACKUP
 | show_columns_stmt         // EXTEND WITH HELP: SHOW COLUMNS
@@ -3063,6 +3294,7 @@ show_stmt:
 | show_queries_stmt         // EXTEND WITH HELP: SHOW QUERIES
 | show_ranges_stmt          // EXTEND WITH HELP: SHOW RANGES
 | show_roles_stmt           // EXTEND WITH HELP: SHOW ROLES
+| show_schedules_stmt       // EXTEND WITH HELP: SHOW SCHEDULES
 | show_schemas_stmt         // EXTEND WITH HELP: SHOW SCHEMAS
 | show_session_stmt         // EXTEND WITH HELP: SHOW SESSION
 | show_sessions_stmt        // EXTEND WITH HELP: SHOW SESSIONS
@@ -3153,30 +3385,67 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [FILES|RANGES|REVISIONS] <location...> [WITH <option> [= <value>] [, ...]]
+//
+// Locations:
+//    "[scheme]://[host]/[path to backup]?[parameters]"
+//
+// Several locations list each of the layers of an incremental backup, as
+// given to RESTORE. A locality-aware layer is given as a parenthesized list
+// of all of its locations. The layer column of the rows is the position of
+// the layer they came from, the full backup being 0.
+//
+// FILES lists the files of each layer along with the time interval of the
+// data in them. With revision history, any AS OF SYSTEM TIME after
+// start_time and up to end_time can be restored for the span of a file,
+// otherwise only end_time.
+//
+// REVISIONS lists the table descriptors of each layer at the times they
+// changed, along with the statement that would create them.
+//
+// Options:
+//    ENCRYPTION_PASSPHRASE
+//    CHECK_FILES
+//
+// With CHECK_FILES, every file of the backup is read and checked, and any
+// problems found are shown instead of the backup's contents.
+//
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
-  SHOW BACKUP string_or_placeholder
+  SHOW BACKUP list_of_string_or_placeholder_opt_list opt_with_options
   {
     $$.val = &tree.ShowBackup{
       Details: tree.BackupDefaultDetails,
-      Path:    $3.expr(),
+      Paths:   $3.listOfStringOrPlaceholderOptList(),
+      Options: $4.kvOptions(),
     }
   }
-| SHOW BACKUP RANGES string_or_placeholder
+| SHOW BACKUP RANGES list_of_string_or_placeholder_opt_list opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupRangeDetails,
-      Path:    $4.expr(),
+      Paths:   $4.listOfStringOrPlaceholderOptList(),
+      Options: $5.kvOptions(),
     }
   }
-| SHOW BACKUP FILES string_or_placeholder
+| SHOW BACKUP FILES list_of_string_or_placeholder_opt_list opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupFileDetails,
-      Path:    $4.expr(),
+      Paths:   $4.listOfStringOrPlaceholderOptList(),
+      Options: $5.kvOptions(),
+    }
+  }
+| SHOW BACKUP REVISIONS list_of_string_or_placeholder_opt_list opt_with_options
+  {
+    /* SKIP DOC */
+    $$.val = &tree.ShowBackup{
+      Details: tree.BackupRevisionDetails,
+      Paths:   $4.listOfStringOrPlaceholderOptList(),
+      Options: $5.kvOptions(),
     }
   }
 | SHOW BACKUP error // SHOW HELP: SHOW BACKUP
@@ -3343,6 +3612,17 @@ show_jobs_stmt:
   }
 | SHOW JOBS error // SHOW HELP: SHOW JOBS
 
+// %Help: SHOW SCHEDULES - list schedules
+// %Category: Misc
+// %Text: SHOW SCHEDULES
+// %SeeAlso: CREATE SCHEDULE, PAUSE SCHEDULES, RESUME SCHEDULES, DROP SCHEDULES
+show_schedules_stmt:
+  SHOW SCHEDULES
+  {
+    $$.val = &tree.ShowSchedules{}
+  }
+| SHOW SCHEDULES error // SHOW HELP: SHOW SCHEDULES
+
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
 // %Text:
@@ -3819,13 +4099,21 @@ for_grantee_clause:
     $$.val = tree.NameList(nil)
   }
 
+// %Help: PAUSE
+// %Category: Group
+// %Text: PAUSE JOBS, PAUSE SCHEDULES
+pause_stmt:
+  pause_jobs_stmt      // EXTEND WITH HELP: PAUSE JOBS
+| pause_schedules_stmt // EXTEND WITH HELP: PAUSE SCHEDULES
+| PAUSE error          // SHOW HELP: PAUSE
+
 // %Help: PAUSE JOBS - pause background jobs
 // %Category: Misc
 // %Text:
 // PAUSE JOBS <selectclause>
 // PAUSE JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS
-pause_stmt:
+pause_jobs_stmt:
   PAUSE JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -3835,11 +4123,35 @@ pause_stmt:
       Command: tree.PauseJob,
     }
   }
+| PAUSE JOB error // SHOW HELP: PAUSE JOBS
 | PAUSE JOBS select_stmt
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.PauseJob}
   }
-| PAUSE error // SHOW HELP: PAUSE JOBS
+| PAUSE JOBS error // SHOW HELP: PAUSE JOBS
+
+// %Help: PAUSE SCHEDULES - pause schedules
+// %Category: Misc
+// %Text:
+// PAUSE SCHEDULES <selectclause>
+// PAUSE SCHEDULE <scheduleid>
+// %SeeAlso: SHOW SCHEDULES, RESUME SCHEDULES, DROP SCHEDULES
+pause_schedules_stmt:
+  PAUSE SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.PauseSchedule,
+    }
+  }
+| PAUSE SCHEDULE error // SHOW HELP: PAUSE SCHEDULES
+| PAUSE SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.PauseSchedule}
+  }
+| PAUSE SCHEDULES error // SHOW HELP: PAUSE SCHEDULES
 
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
@@ -4986,13 +5298,21 @@ release_stmt:
   }
 | RELEASE error // SHOW HELP: RELEASE
 
+// %Help: RESUME
+// %Category: Group
+// %Text: RESUME JOBS, RESUME SCHEDULES
+resume_stmt:
+  resume_jobs_stmt      // EXTEND WITH HELP: RESUME JOBS
+| resume_schedules_stmt // EXTEND WITH HELP: RESUME SCHEDULES
+| RESUME error          // SHOW HELP: RESUME
+
 // %Help: RESUME JOBS - resume background jobs
 // %Category: Misc
 // %Text:
 // RESUME JOBS <selectclause>
 // RESUME JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS
-resume_stmt:
+resume_jobs_stmt:
   RESUME JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -5002,11 +5322,35 @@ resume_stmt:
       Command: tree.ResumeJob,
     }
   }
+| RESUME JOB error // SHOW HELP: RESUME JOBS
 | RESUME JOBS select_stmt
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.ResumeJob}
   }
-| RESUME error // SHOW HELP: RESUME JOBS
+| RESUME JOBS error // SHOW HELP: RESUME JOBS
+
+// %Help: RESUME SCHEDULES - resume paused schedules
+// %Category: Misc
+// %Text:
+// RESUME SCHEDULES <selectclause>
+// RESUME SCHEDULE <scheduleid>
+// %SeeAlso: SHOW SCHEDULES, PAUSE SCHEDULES, DROP SCHEDULES
+resume_schedules_stmt:
+  RESUME SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.ResumeSchedule,
+    }
+  }
+| RESUME SCHEDULE error // SHOW HELP: RESUME SCHEDULES
+| RESUME SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.ResumeSchedule}
+  }
+| RESUME SCHEDULES error // SHOW HELP: RESUME SCHEDULES
 
 // %Help: SAVEPOINT - start a retryable block
 // %Category: Txn
@@ -8941,6 +9285,7 @@ unreserved_keyword:
 | RANGE
 | RANGES
 | READ
+| RECURRING
 | RECURSIVE
 | REF
 | REGCLASS
@@ -8956,6 +9301,7 @@ unreserved_keyword:
 | RESTORE
 | RESTRICT
 | RESUME
+| REVISIONS
 | REVOKE
 | ROLE
 | ROLES
@@ -8968,6 +9314,8 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEDULE
+| SCHEDULES
 | SCHEMA
 | SCHEMAS
 | SCRUB
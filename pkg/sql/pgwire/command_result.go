@@ -65,6 +65,8 @@ type commandResult struct {
 	// If set, an error will be sent to the client if more rows are produced than
 	// this limit.
 	limit int
+	// If set, every row is flushed to the client as soon as it's added.
+	streaming bool
 
 	stmtType     tree.StatementType
 	descOpt      sql.RowDescOpt
@@ -214,6 +216,9 @@ func (r *commandResult) AddRow(ctx context.Context, row tree.Datums) error {
 	r.rowsAffected++
 
 	r.conn.bufferRow(ctx, row, r.formatCodes, r.conv)
+	if r.streaming {
+		return r.conn.Flush(r.pos)
+	}
 	_ /* flushed */, err := r.conn.maybeFlush(r.pos)
 	return err
 }
@@ -267,6 +272,11 @@ func (r *commandResult) SetLimit(n int) {
 	r.limit = n
 }
 
+// SetStreaming is part of the RestrictedCommandResult interface.
+func (r *commandResult) SetStreaming() {
+	r.streaming = true
+}
+
 // ResetStmtType is part of the CommandResult interface.
 func (r *commandResult) ResetStmtType(stmt tree.Statement) {
 	r.stmtType = stmt.StatementType()
@@ -143,7 +143,8 @@ func (a UncachedPhysicalAccessor) GetObjectDesc(
 	if found {
 		// We have a descriptor. Is it in the right state? We'll keep it if
 		// it is in the ADD state.
-		if err := filterTableState(desc); err == nil || err == errTableAdding {
+		err := filterTableState(desc)
+		if err == nil || err == errTableAdding {
 			// Immediately after a RENAME an old name still points to the
 			// descriptor during the drain phase for the name. Do not
 			// return a descriptor during draining.
@@ -153,6 +154,10 @@ func (a UncachedPhysicalAccessor) GetObjectDesc(
 				}
 				return sqlbase.NewImmutableTableDescriptor(*desc), dbDesc, nil
 			}
+		} else if desc.Offline() && desc.Name == name.Table() {
+			// An offline table exists, so report why it can't be used rather
+			// than reporting that it doesn't exist.
+			return nil, nil, err
 		}
 	}
 
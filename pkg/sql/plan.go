@@ -159,11 +159,13 @@ type planNodeFastPath interface {
 }
 
 var _ planNode = &alterIndexNode{}
+var _ planNode = &alterJobNode{}
 var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
 var _ planNode = &createSequenceNode{}
+var _ planNode = &createScheduleNode{}
 var _ planNode = &createStatsNode{}
 var _ planNode = &createTableNode{}
 var _ planNode = &CreateUserNode{}
@@ -222,6 +224,7 @@ var _ planNodeFastPath = &rowCountNode{}
 var _ planNodeFastPath = &serializeNode{}
 var _ planNodeFastPath = &setZoneConfigNode{}
 var _ planNodeFastPath = &controlJobsNode{}
+var _ planNodeFastPath = &controlSchedulesNode{}
 
 // planNodeRequireSpool serves as marker for nodes whose parent must
 // ensure that the node is fully run to completion (and the results
@@ -287,6 +290,10 @@ type planTop struct {
 	// auditEvents becomes non-nil if any of the descriptors used by
 	// current statement is causing an auditing event. See exec_log.go.
 	auditEvents []auditEvent
+
+	// streamResults is set if the results of the statement are a stream of rows
+	// that might never end. See RestrictedCommandResult.SetStreaming.
+	streamResults bool
 }
 
 // makePlan implements the Planner interface. It populates the
@@ -839,6 +846,8 @@ func (p *planner) newPlan(
 	switch n := stmt.(type) {
 	case *tree.AlterIndex:
 		return p.AlterIndex(ctx, n)
+	case *tree.AlterJob:
+		return p.AlterJob(ctx, n)
 	case *tree.AlterTable:
 		return p.AlterTable(ctx, n)
 	case *tree.AlterSequence:
@@ -853,6 +862,8 @@ func (p *planner) newPlan(
 		return p.CommentOnTable(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
+	case *tree.ControlSchedules:
+		return p.ControlSchedules(ctx, n)
 	case *tree.Scrub:
 		return p.Scrub(ctx, n)
 	case *tree.CreateDatabase:
@@ -867,6 +878,8 @@ func (p *planner) newPlan(
 		return p.CreateView(ctx, n)
 	case *tree.CreateSequence:
 		return p.CreateSequence(ctx, n)
+	case *tree.CreateSchedule:
+		return p.CreateSchedule(ctx, n)
 	case *tree.CreateStats:
 		return p.CreateStatistics(ctx, n)
 	case *tree.Deallocate:
@@ -946,6 +959,8 @@ func (p *planner) newPlan(
 		return p.ShowQueries(ctx, n)
 	case *tree.ShowJobs:
 		return p.ShowJobs(ctx, n)
+	case *tree.ShowSchedules:
+		return p.ShowSchedules(ctx, n)
 	case *tree.ShowRoleGrants:
 		return p.ShowRoleGrants(ctx, n)
 	case *tree.ShowRoles:
@@ -1017,6 +1032,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 	p.isPreparing = true
 
 	switch n := stmt.(type) {
+	case *tree.AlterJob:
+		return p.AlterJob(ctx, n)
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
 	case *tree.CancelQueries:
@@ -1025,6 +1042,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.CancelSessions(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
+	case *tree.ControlSchedules:
+		return p.ControlSchedules(ctx, n)
 	case *tree.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *tree.CreateTable:
@@ -1070,6 +1089,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.ShowQueries(ctx, n)
 	case *tree.ShowJobs:
 		return p.ShowJobs(ctx, n)
+	case *tree.ShowSchedules:
+		return p.ShowSchedules(ctx, n)
 	case *tree.ShowRoleGrants:
 		return p.ShowRoleGrants(ctx, n)
 	case *tree.ShowRoles:
@@ -36,6 +36,11 @@ func planColumns(plan planNode) sqlbase.ResultColumns {
 	return getPlanColumns(plan, false)
 }
 
+// PlanColumns is the exported version of planColumns. Useful for CCL hooks.
+func PlanColumns(plan PlanNode) sqlbase.ResultColumns {
+	return planColumns(plan)
+}
+
 // planMutableColumns is similar to planColumns() but returns a
 // ResultColumns slice that can be modified by the caller.
 func planMutableColumns(plan planNode) sqlbase.ResultColumns {
@@ -117,6 +122,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.getColumns(mut, sqlbase.ShowReplicaTraceColumns)
 	case *sequenceSelectNode:
 		return n.getColumns(mut, sequenceSelectColumns)
+	case *createScheduleNode:
+		return n.getColumns(mut, createScheduleColumns)
 
 	// Nodes that have the same schema as their source or their
 	// valueNode helper.
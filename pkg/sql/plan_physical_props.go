@@ -95,12 +95,15 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *CreateUserNode:
 	case *DropUserNode:
 	case *alterIndexNode:
+	case *alterJobNode:
 	case *alterSequenceNode:
 	case *alterTableNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueriesNode:
 	case *cancelSessionsNode:
 	case *controlJobsNode:
+	case *controlSchedulesNode:
+	case *createScheduleNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *createSequenceNode:
@@ -94,6 +94,11 @@ type PlanHookState interface {
 	EvalAsOfTimestamp(asOf tree.AsOfClause, max hlc.Timestamp) (hlc.Timestamp, error)
 	ResolveUncachedDatabaseByName(
 		ctx context.Context, dbName string, required bool) (*UncachedDatabaseDescriptor, error)
+	// StreamResults marks the results of the statement being planned as a
+	// stream of rows that might never end, such as the changes emitted by a
+	// changefeed without a sink. Each row is delivered to the client as soon as
+	// it's produced and the hook is blocked while the client isn't keeping up.
+	StreamResults()
 }
 
 // AddPlanHook adds a hook used to short-circuit creating a planNode from a
@@ -129,7 +134,6 @@ type hookFnRun struct {
 }
 
 func (f *hookFnNode) startExec(params runParams) error {
-	// TODO(dan): Make sure the resultCollector is set to flush after every row.
 	f.run.resultsCh = make(chan tree.Datums)
 	f.run.errCh = make(chan error)
 	go func() {
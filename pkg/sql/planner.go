@@ -72,6 +72,11 @@ type extendedEvalContext struct {
 
 	SchemaChangers *schemaChangerCollection
 
+	// MutationCounts accumulates the rows modified by the session's current
+	// transaction, for the automatic statistics Refresher. It is nil outside of
+	// a session.
+	MutationCounts *mutationCountCollection
+
 	schemaAccessors *schemaInterface
 }
 
@@ -350,6 +355,18 @@ func (p *planner) ExecCfg() *ExecutorConfig {
 	return p.extendedEvalCtx.ExecCfg
 }
 
+// notifyMutation records that the current transaction modified rowsAffected
+// rows of the given table, for the automatic statistics Refresher. Outside of a
+// session, where the commit of the transaction is not observed, the Refresher
+// is notified right away.
+func (p *planner) notifyMutation(tableID sqlbase.ID, rowsAffected int64) {
+	if mcc := p.extendedEvalCtx.MutationCounts; mcc != nil {
+		mcc.add(tableID, rowsAffected)
+		return
+	}
+	p.ExecCfg().StatsRefresher.NotifyMutation(tableID, rowsAffected)
+}
+
 func (p *planner) LeaseMgr() *LeaseManager {
 	return p.Tables().leaseMgr
 }
@@ -362,6 +379,11 @@ func (p *planner) User() string {
 	return p.SessionData().User
 }
 
+// StreamResults is part of the PlanHookState interface.
+func (p *planner) StreamResults() {
+	p.curPlan.streamResults = true
+}
+
 // DistSQLPlanner returns the DistSQLPlanner
 func (p *planner) DistSQLPlanner() *DistSQLPlanner {
 	return p.extendedEvalCtx.DistSQLPlanner
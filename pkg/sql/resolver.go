@@ -246,6 +246,11 @@ const (
 	requireSequenceDesc
 )
 
+// ResolveRequireTableDesc can be passed to ResolveExistingObject from outside
+// this package, e.g. by plan hooks, to require the returned descriptor to be a
+// table.
+const ResolveRequireTableDesc = requireTableDesc
+
 var requiredTypeNames = [...]string{
 	requireTableDesc:       "table",
 	requireViewDesc:        "view",
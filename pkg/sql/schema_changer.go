@@ -178,7 +178,8 @@ func isPermanentSchemaChangeError(err error) bool {
 		errExpiredSchemaChangeLease,
 		errNotHitGCTTLDeadline,
 		errSchemaChangeDuringDrain,
-		errSchemaChangeNotFirstInLine:
+		errSchemaChangeNotFirstInLine,
+		errSchemaChangePaused:
 		return false
 	}
 	switch err := err.(type) {
@@ -206,12 +207,14 @@ var (
 	errSchemaChangeNotFirstInLine = errors.New("schema change not first in line")
 	errNotHitGCTTLDeadline        = errors.New("not hit gc ttl deadline")
 	errSchemaChangeDuringDrain    = errors.New("a schema change ran during the drain phase, re-increment")
+	errSchemaChangePaused         = errors.New("the schema change job is paused; it will continue once the job is resumed")
 )
 
 func shouldLogSchemaChangeError(err error) bool {
 	return err != errExistingSchemaChangeLease &&
 		err != errSchemaChangeNotFirstInLine &&
-		err != errNotHitGCTTLDeadline
+		err != errNotHitGCTTLDeadline &&
+		err != errSchemaChangePaused
 }
 
 type errTableVersionMismatch struct {
@@ -839,6 +842,9 @@ func (sc *SchemaChanger) exec(
 			log.Infof(ctx, "Failed to mark job %d as started: %v", *sc.job.ID(), err)
 		}
 	}
+	if err := sc.checkJobPaused(ctx); err != nil {
+		return err
+	}
 
 	defer waitToUpdateLeases()
 
@@ -857,6 +863,20 @@ func (sc *SchemaChanger) exec(
 	return err
 }
 
+// checkJobPaused returns errSchemaChangePaused if the schema change's job has
+// been paused, in which case the schema change must not make any further
+// progress until the job is resumed.
+func (sc *SchemaChanger) checkJobPaused(ctx context.Context) error {
+	status, err := sc.job.CurrentStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if status == jobs.StatusPaused {
+		return errSchemaChangePaused
+	}
+	return nil
+}
+
 func (sc *SchemaChanger) rollbackSchemaChange(
 	ctx context.Context,
 	err error,
@@ -1021,6 +1041,9 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 			if err := sc.job.WithTxn(txn).RunningStatus(ctx, func(ctx context.Context, details jobspb.Details) (jobs.RunningStatus, error) {
 				return jobs.RunningStatusWaitingGC, nil
 			}); err != nil {
+				if jobs.IsPausedError(err) {
+					return errSchemaChangePaused
+				}
 				return errors.Wrapf(err, "failed to update running status of job %d", *sc.job.ID())
 			}
 		}
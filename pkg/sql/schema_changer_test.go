@@ -3745,6 +3745,101 @@ func TestSchemaChangeRetryError(t *testing.T) {
 // execution transactions using a canceled context. The schema
 // change will give up and ultimately be executed to completion through
 // the asynchronous schema changer.
+// TestPauseSchemaChange tests that a schema change stops when its job is
+// paused, keeping the progress it has checkpointed, and is completed once the
+// job is resumed.
+func TestPauseSchemaChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const maxValue = 100
+
+	var db *gosql.DB
+	params, _ := tests.CreateTestServerParams()
+	var doPause, enableAsyncSchemaChanges uint32
+	params.Knobs = base.TestingKnobs{
+		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+			AsyncExecNotification: func() error {
+				if enable := atomic.LoadUint32(&enableAsyncSchemaChanges); enable == 0 {
+					return errors.New("async schema changes are disabled")
+				}
+				return nil
+			},
+			AsyncExecQuickly: true,
+			// Checkpoint after every chunk so that the backfill notices the
+			// pause right away.
+			WriteCheckpointInterval: time.Nanosecond,
+			BackfillChunkSize:       10,
+		},
+		DistSQL: &distsqlrun.TestingKnobs{
+			RunBeforeBackfillChunk: func(sp roachpb.Span) error {
+				if !atomic.CompareAndSwapUint32(&doPause, 1, 0) {
+					return nil
+				}
+				if _, err := db.Exec(`ALTER JOB (
+					SELECT job_id FROM [SHOW JOBS]
+					WHERE job_type = 'SCHEMA CHANGE' AND status = $1
+				) PAUSE`, jobs.StatusRunning); err != nil {
+					panic(err)
+				}
+				return nil
+			},
+		},
+	}
+	s, sqlDB, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+	db = sqlDB
+	runner := sqlutils.MakeSQLRunner(sqlDB)
+
+	runner.Exec(t, `
+		CREATE DATABASE t;
+		CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
+	`)
+	if err := bulkInsertIntoTable(sqlDB, maxValue); err != nil {
+		t.Fatal(err)
+	}
+	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "test")
+
+	atomic.StoreUint32(&doPause, 1)
+	const stmt = `CREATE INDEX foo ON t.public.test (v)`
+	if _, err := sqlDB.Exec(stmt); !testutils.IsError(err, "schema change job is paused") {
+		t.Fatalf("unexpected %v", err)
+	}
+	record := jobs.Record{
+		Username:      security.RootUser,
+		Description:   stmt,
+		DescriptorIDs: sqlbase.IDs{tableDesc.ID},
+	}
+	if err := jobutils.VerifySystemJob(
+		t, runner, 0, jobspb.TypeSchemaChange, jobs.StatusPaused, record,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// The backfill checkpointed its progress in the job's progress.
+	jobID := jobutils.GetJobID(t, runner, 0)
+	progress := jobutils.GetJobProgress(t, runner, jobID)
+	if p := progress.GetSchemaChange(); p == nil || len(p.ResumeSpanList) != 1 {
+		t.Fatalf("expected checkpointed resume spans, got %+v", progress.Details)
+	}
+
+	// The paused schema change is left alone until the job is resumed.
+	atomic.StoreUint32(&enableAsyncSchemaChanges, 1)
+	if tableDesc = sqlbase.GetTableDescriptor(kvDB, "t", "test"); len(tableDesc.Mutations) != 1 {
+		t.Fatalf("expected 1 mutation, got %d", len(tableDesc.Mutations))
+	}
+	runner.Exec(t, `ALTER JOB $1 RESUME`, jobID)
+	testutils.SucceedsSoon(t, func() error {
+		return jobutils.VerifySystemJob(t, runner, 0, jobspb.TypeSchemaChange, jobs.StatusSucceeded, record)
+	})
+
+	// Verify that the index foo over v is consistent.
+	var count int
+	runner.QueryRow(t, `SELECT count(*) FROM t.test@foo`).Scan(&count)
+	if count != maxValue+1 {
+		t.Fatalf("expected %d rows in the index, got %d", maxValue+1, count)
+	}
+}
+
 func TestCancelSchemaChangeContext(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
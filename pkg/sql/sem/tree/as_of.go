@@ -26,10 +26,135 @@ import (
 	"github.com/pkg/errors"
 )
 
-// EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME query.
-func EvalAsOfTimestamp(
+const (
+	// WithMaxStalenessFuncName is the name of the function that can be used
+	// in an AS OF SYSTEM TIME clause to request a bounded staleness read no
+	// staler than the given interval.
+	WithMaxStalenessFuncName = "with_max_staleness"
+	// WithMinTimestampFuncName is the name of the function that can be used
+	// in an AS OF SYSTEM TIME clause to request a bounded staleness read at
+	// or above the given timestamp.
+	WithMinTimestampFuncName = "with_min_timestamp"
+)
+
+// BoundedStaleness is the evaluated form of a bounded staleness AS OF SYSTEM
+// TIME clause.
+type BoundedStaleness struct {
+	// MinTimestamp is the lowest timestamp at which the read may be served.
+	MinTimestamp hlc.Timestamp
+	// NearestOnly indicates that the read must fail instead of falling back
+	// to reading at the present time if it cannot be served by the nearest
+	// replicas at or above MinTimestamp.
+	NearestOnly bool
+}
+
+// boundedStalenessFunc returns the bounded staleness function invoked by the
+// AS OF SYSTEM TIME clause, if any.
+func boundedStalenessFunc(asOf AsOfClause) (string, *FuncExpr) {
+	f, ok := asOf.Expr.(*FuncExpr)
+	if !ok {
+		return "", nil
+	}
+	var name string
+	switch t := f.Func.FunctionReference.(type) {
+	case *UnresolvedName:
+		if t.NumParts != 1 {
+			return "", nil
+		}
+		name = strings.ToLower(t.Parts[0])
+	case *FunctionDefinition:
+		name = t.Name
+	default:
+		return "", nil
+	}
+	if name != WithMaxStalenessFuncName && name != WithMinTimestampFuncName {
+		return "", nil
+	}
+	return name, f
+}
+
+// IsBoundedStalenessAsOf returns whether the AS OF SYSTEM TIME clause requests
+// a bounded staleness read through with_max_staleness or with_min_timestamp.
+func IsBoundedStalenessAsOf(asOf AsOfClause) bool {
+	_, f := boundedStalenessFunc(asOf)
+	return f != nil
+}
+
+// EvalBoundedStaleness evaluates a bounded staleness AS OF SYSTEM TIME clause,
+// i.e. one of:
+//
+//	with_max_staleness(interval [, nearest_only])
+//	with_min_timestamp(timestamptz [, nearest_only])
+//
+// The returned bound is never above max.
+func EvalBoundedStaleness(
 	asOf AsOfClause, max hlc.Timestamp, semaCtx *SemaContext, evalCtx *EvalContext,
-) (hlc.Timestamp, error) {
+) (BoundedStaleness, error) {
+	name, f := boundedStalenessFunc(asOf)
+	if f == nil {
+		return BoundedStaleness{}, errors.Errorf("AS OF SYSTEM TIME: expected %s or %s",
+			WithMaxStalenessFuncName, WithMinTimestampFuncName)
+	}
+	if len(f.Exprs) < 1 || len(f.Exprs) > 2 {
+		return BoundedStaleness{}, errors.Errorf("AS OF SYSTEM TIME: %s expects 1 or 2 arguments, got %d",
+			name, len(f.Exprs))
+	}
+
+	var bs BoundedStaleness
+	switch name {
+	case WithMaxStalenessFuncName:
+		d, err := evalAsOfExpr(f.Exprs[0], types.Interval, semaCtx, evalCtx)
+		if err != nil {
+			return bs, err
+		}
+		iv, ok := d.(*DInterval)
+		if !ok {
+			return bs, errors.Errorf("AS OF SYSTEM TIME: %s expects an interval, got %s", name, d.ResolvedType())
+		}
+		if iv.Duration.Compare(duration.Duration{}) <= 0 {
+			return bs, errors.Errorf("AS OF SYSTEM TIME: %s requires a positive interval", name)
+		}
+		bs.MinTimestamp.WallTime = duration.Add(
+			evalCtx, evalCtx.GetStmtTimestamp(), iv.Duration.Mul(-1),
+		).UnixNano()
+	case WithMinTimestampFuncName:
+		d, err := evalAsOfExpr(f.Exprs[0], types.TimestampTZ, semaCtx, evalCtx)
+		if err != nil {
+			return bs, err
+		}
+		switch t := d.(type) {
+		case *DTimestampTZ:
+			bs.MinTimestamp.WallTime = t.UnixNano()
+		case *DTimestamp:
+			bs.MinTimestamp.WallTime = t.UnixNano()
+		default:
+			return bs, errors.Errorf("AS OF SYSTEM TIME: %s expects a timestamp, got %s", name, d.ResolvedType())
+		}
+	}
+	if max.Less(bs.MinTimestamp) {
+		return bs, errors.Errorf("AS OF SYSTEM TIME: cannot specify timestamp in the future")
+	}
+
+	if len(f.Exprs) > 1 {
+		d, err := evalAsOfExpr(f.Exprs[1], types.Bool, semaCtx, evalCtx)
+		if err != nil {
+			return bs, err
+		}
+		b, ok := d.(*DBool)
+		if !ok {
+			return bs, errors.Errorf("AS OF SYSTEM TIME: %s expects a boolean nearest_only argument, got %s",
+				name, d.ResolvedType())
+		}
+		bs.NearestOnly = bool(*b)
+	}
+	return bs, nil
+}
+
+// evalAsOfExpr type checks and evaluates a constant expression used in an AS
+// OF SYSTEM TIME clause.
+func evalAsOfExpr(
+	expr Expr, desired types.T, semaCtx *SemaContext, evalCtx *EvalContext,
+) (Datum, error) {
 	// We need to save and restore the previous value of the field in
 	// semaCtx in case we are recursively called within a subquery
 	// context.
@@ -37,14 +162,34 @@ func EvalAsOfTimestamp(
 	defer scalarProps.Restore(*scalarProps)
 	scalarProps.Require("AS OF SYSTEM TIME", RejectSpecial|RejectSubqueries)
 
-	te, err := asOf.Expr.TypeCheck(semaCtx, types.String)
+	te, err := expr.TypeCheck(semaCtx, desired)
 	if err != nil {
-		return hlc.Timestamp{}, err
+		return nil, err
 	}
 	if !IsConst(evalCtx, te) {
-		return hlc.Timestamp{}, errors.Errorf("AS OF SYSTEM TIME: only constant expressions are allowed")
+		return nil, errors.Errorf("AS OF SYSTEM TIME: only constant expressions are allowed")
+	}
+	return te.Eval(evalCtx)
+}
+
+// EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME query.
+//
+// A bounded staleness clause does not determine the timestamp by itself; it
+// is negotiated with the KV layer when the statement starts executing and
+// recorded in semaCtx.AsOfTimestamp, which is returned here. Bounded
+// staleness clauses are rejected in all other contexts.
+func EvalAsOfTimestamp(
+	asOf AsOfClause, max hlc.Timestamp, semaCtx *SemaContext, evalCtx *EvalContext,
+) (hlc.Timestamp, error) {
+	if name, f := boundedStalenessFunc(asOf); f != nil {
+		if semaCtx.AsOfTimestamp == nil {
+			return hlc.Timestamp{}, errors.Errorf(
+				"AS OF SYSTEM TIME: %s can only be used in a single-statement read-only query", name)
+		}
+		return *semaCtx.AsOfTimestamp, nil
 	}
-	d, err := te.Eval(evalCtx)
+
+	d, err := evalAsOfExpr(asOf.Expr, types.String, semaCtx, evalCtx)
 	if err != nil {
 		return hlc.Timestamp{}, err
 	}
@@ -0,0 +1,118 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestEvalBoundedStaleness(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stmtTS := timeutil.Unix(100, 0)
+	max := hlc.Timestamp{WallTime: stmtTS.UnixNano()}
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(context.Background())
+	evalCtx.SetStmtTimestamp(stmtTS)
+
+	testCases := []struct {
+		expr   string
+		exp    tree.BoundedStaleness
+		expErr string
+	}{
+		{
+			expr: "with_max_staleness('10s')",
+			exp:  tree.BoundedStaleness{MinTimestamp: hlc.Timestamp{WallTime: 90 * time.Second.Nanoseconds()}},
+		},
+		{
+			expr: "with_max_staleness('10s', true)",
+			exp: tree.BoundedStaleness{
+				MinTimestamp: hlc.Timestamp{WallTime: 90 * time.Second.Nanoseconds()},
+				NearestOnly:  true,
+			},
+		},
+		{
+			expr: "with_min_timestamp('1970-01-01 00:01:20+00')",
+			exp:  tree.BoundedStaleness{MinTimestamp: hlc.Timestamp{WallTime: 80 * time.Second.Nanoseconds()}},
+		},
+		{
+			expr:   "with_max_staleness('-10s')",
+			expErr: "requires a positive interval",
+		},
+		{
+			expr:   "with_min_timestamp('1970-01-01 00:10:00+00')",
+			expErr: "cannot specify timestamp in the future",
+		},
+		{
+			expr:   "with_max_staleness()",
+			expErr: "expects 1 or 2 arguments",
+		},
+		{
+			expr:   "with_max_staleness(now() - now())",
+			expErr: "only constant expressions are allowed",
+		},
+		{
+			expr:   "'-10s'",
+			expErr: "expected with_max_staleness or with_min_timestamp",
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(c.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			asOf := tree.AsOfClause{Expr: expr}
+			semaCtx := tree.MakeSemaContext(false /* privileged */)
+			bs, err := tree.EvalBoundedStaleness(asOf, max, &semaCtx, evalCtx)
+			if c.expErr != "" {
+				if !testutils.IsError(err, c.expErr) {
+					t.Fatalf("expected error %q, got %v", c.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bs != c.exp {
+				t.Errorf("expected %+v, got %+v", c.exp, bs)
+			}
+
+			// Without a negotiated timestamp, EvalAsOfTimestamp rejects bounded
+			// staleness clauses. With one, it returns it.
+			if _, err := tree.EvalAsOfTimestamp(asOf, max, &semaCtx, evalCtx); !testutils.IsError(
+				err, "can only be used in a single-statement read-only query",
+			) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			semaCtx.AsOfTimestamp = &bs.MinTimestamp
+			if ts, err := tree.EvalAsOfTimestamp(asOf, max, &semaCtx, evalCtx); err != nil {
+				t.Fatal(err)
+			} else if ts != bs.MinTimestamp {
+				t.Errorf("expected %s, got %s", bs.MinTimestamp, ts)
+			}
+		})
+	}
+}
@@ -14,13 +14,33 @@
 
 package tree
 
+// DescriptorCoverage specifies whether or not a subset of descriptors were
+// requested or if all the descriptors were requested, so all the descriptors
+// are covered in a given backup.
+type DescriptorCoverage int32
+
+const (
+	// RequestedDescriptors table coverage means that the backup is not
+	// guaranteed to have all of the cluster data. This can be accomplished by
+	// backing up a specific subset of tables/databases. Note that even if all
+	// of the tables and databases have been included in the backup manually, a
+	// backup is not said to have complete table coverage unless it was created
+	// by a `BACKUP TO` command.
+	RequestedDescriptors DescriptorCoverage = iota
+	// AllDescriptors table coverage means that backup is guaranteed to have all
+	// the relevant data in the cluster. These can only be created by running a
+	// full cluster backup with `BACKUP TO`.
+	AllDescriptors
+)
+
 // Backup represents a BACKUP statement.
 type Backup struct {
-	Targets         TargetList
-	To              Expr
-	IncrementalFrom Exprs
-	AsOf            AsOfClause
-	Options         KVOptions
+	Targets            TargetList
+	DescriptorCoverage DescriptorCoverage
+	To                 StringOrPlaceholderOptList
+	IncrementalFrom    Exprs
+	AsOf               AsOfClause
+	Options            KVOptions
 }
 
 var _ Statement = &Backup{}
@@ -28,9 +48,12 @@ var _ Statement = &Backup{}
 // Format implements the NodeFormatter interface.
 func (node *Backup) Format(ctx *FmtCtx) {
 	ctx.WriteString("BACKUP ")
-	ctx.FormatNode(&node.Targets)
-	ctx.WriteString(" TO ")
-	ctx.FormatNode(node.To)
+	if node.DescriptorCoverage == RequestedDescriptors {
+		ctx.FormatNode(&node.Targets)
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("TO ")
+	ctx.FormatNode(&node.To)
 	if node.AsOf.Expr != nil {
 		ctx.WriteString(" ")
 		ctx.FormatNode(&node.AsOf)
@@ -47,10 +70,11 @@ func (node *Backup) Format(ctx *FmtCtx) {
 
 // Restore represents a RESTORE statement.
 type Restore struct {
-	Targets TargetList
-	From    Exprs
-	AsOf    AsOfClause
-	Options KVOptions
+	Targets            TargetList
+	DescriptorCoverage DescriptorCoverage
+	From               []StringOrPlaceholderOptList
+	AsOf               AsOfClause
+	Options            KVOptions
 }
 
 var _ Statement = &Restore{}
@@ -58,9 +82,17 @@ var _ Statement = &Restore{}
 // Format implements the NodeFormatter interface.
 func (node *Restore) Format(ctx *FmtCtx) {
 	ctx.WriteString("RESTORE ")
-	ctx.FormatNode(&node.Targets)
-	ctx.WriteString(" FROM ")
-	ctx.FormatNode(&node.From)
+	if node.DescriptorCoverage == RequestedDescriptors {
+		ctx.FormatNode(&node.Targets)
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("FROM ")
+	for i := range node.From {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&node.From[i])
+	}
 	if node.AsOf.Expr != nil {
 		ctx.WriteString(" ")
 		ctx.FormatNode(&node.AsOf)
@@ -71,6 +103,22 @@ func (node *Restore) Format(ctx *FmtCtx) {
 	}
 }
 
+// StringOrPlaceholderOptList is a list of strings or placeholders. It is
+// used for the locations of a BACKUP, which may be split across several
+// locality-specific URIs.
+type StringOrPlaceholderOptList []Expr
+
+// Format implements the NodeFormatter interface.
+func (node *StringOrPlaceholderOptList) Format(ctx *FmtCtx) {
+	if len(*node) > 1 {
+		ctx.WriteString("(")
+	}
+	ctx.FormatNode((*Exprs)(node))
+	if len(*node) > 1 {
+		ctx.WriteString(")")
+	}
+}
+
 // KVOption is a key-value option.
 type KVOption struct {
 	Key   Name
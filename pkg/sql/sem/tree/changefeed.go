@@ -19,14 +19,20 @@ type CreateChangefeed struct {
 	Targets TargetList
 	SinkURI Expr
 	Options KVOptions
+	// Select, if non-nil, is the query of a CREATE CHANGEFEED ... AS SELECT,
+	// in which case Targets is empty.
+	Select *Select
 }
 
 var _ Statement = &CreateChangefeed{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateChangefeed) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE CHANGEFEED FOR ")
-	ctx.FormatNode(&node.Targets)
+	ctx.WriteString("CREATE CHANGEFEED")
+	if node.Select == nil {
+		ctx.WriteString(" FOR ")
+		ctx.FormatNode(&node.Targets)
+	}
 	if node.SinkURI != nil {
 		ctx.WriteString(" INTO ")
 		ctx.FormatNode(node.SinkURI)
@@ -35,4 +41,8 @@ func (node *CreateChangefeed) Format(ctx *FmtCtx) {
 		ctx.WriteString(" WITH ")
 		ctx.FormatNode(&node.Options)
 	}
+	if node.Select != nil {
+		ctx.WriteString(" AS ")
+		ctx.FormatNode(node.Select)
+	}
 }
@@ -17,6 +17,8 @@ package tree
 // Import represents a IMPORT statement.
 type Import struct {
 	Table      *TableName
+	Into       bool
+	IntoCols   NameList
 	CreateFile Expr
 	CreateDefs TableDefs
 	FileFormat string
@@ -41,17 +43,28 @@ func (node *Import) Format(ctx *FmtCtx) {
 		ctx.WriteByte(' ')
 		ctx.FormatNode(&node.Files)
 	} else {
-		ctx.WriteString("TABLE ")
-		ctx.FormatNode(node.Table)
-
-		if node.CreateFile != nil {
-			ctx.WriteString(" CREATE USING ")
-			ctx.FormatNode(node.CreateFile)
+		if node.Into {
+			ctx.WriteString("INTO ")
+			ctx.FormatNode(node.Table)
+			if node.IntoCols != nil {
+				ctx.WriteString(" (")
+				ctx.FormatNode(&node.IntoCols)
+				ctx.WriteString(")")
+			}
 			ctx.WriteString(" ")
 		} else {
-			ctx.WriteString(" (")
-			ctx.FormatNode(&node.CreateDefs)
-			ctx.WriteString(") ")
+			ctx.WriteString("TABLE ")
+			ctx.FormatNode(node.Table)
+
+			if node.CreateFile != nil {
+				ctx.WriteString(" CREATE USING ")
+				ctx.FormatNode(node.CreateFile)
+				ctx.WriteString(" ")
+			} else {
+				ctx.WriteString(" (")
+				ctx.FormatNode(&node.CreateDefs)
+				ctx.WriteString(") ")
+			}
 		}
 		ctx.WriteString(node.FileFormat)
 		ctx.WriteString(" DATA (")
@@ -1431,8 +1431,10 @@ func (node *Backup) doc(p *PrettyCfg) pretty.Doc {
 	items := make([]pretty.RLTableRow, 0, 6)
 
 	items = append(items, p.row("BACKUP", pretty.Nil))
-	items = append(items, node.Targets.docRow(p))
-	items = append(items, p.row("TO", p.Doc(node.To)))
+	if node.DescriptorCoverage == RequestedDescriptors {
+		items = append(items, node.Targets.docRow(p))
+	}
+	items = append(items, p.row("TO", p.Doc(&node.To)))
 
 	if node.AsOf.Expr != nil {
 		items = append(items, node.AsOf.docRow(p))
@@ -1450,8 +1452,14 @@ func (node *Restore) doc(p *PrettyCfg) pretty.Doc {
 	items := make([]pretty.RLTableRow, 0, 5)
 
 	items = append(items, p.row("RESTORE", pretty.Nil))
-	items = append(items, node.Targets.docRow(p))
-	items = append(items, p.row("FROM", p.Doc(&node.From)))
+	if node.DescriptorCoverage == RequestedDescriptors {
+		items = append(items, node.Targets.docRow(p))
+	}
+	from := make([]pretty.Doc, len(node.From))
+	for i := range node.From {
+		from[i] = p.Doc(&node.From[i])
+	}
+	items = append(items, p.row("FROM", pretty.Join(",", from...)))
 
 	if node.AsOf.Expr != nil {
 		items = append(items, node.AsOf.docRow(p))
@@ -1508,7 +1516,13 @@ func (node *Import) doc(p *PrettyCfg) pretty.Doc {
 		}
 		items = append(items, p.row(node.FileFormat, p.Doc(&node.Files)))
 	} else {
-		if node.CreateFile != nil {
+		if node.Into {
+			into := p.Doc(node.Table)
+			if node.IntoCols != nil {
+				into = p.nestUnder(into, pretty.Bracket("(", p.Doc(&node.IntoCols), ")"))
+			}
+			items = append(items, p.row("INTO", into))
+		} else if node.CreateFile != nil {
 			items = append(items, p.row("TABLE", p.Doc(node.Table)))
 			items = append(items, p.row("CREATE USING", p.Doc(node.CreateFile)))
 		} else {
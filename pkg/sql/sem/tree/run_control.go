@@ -44,6 +44,20 @@ func (n *ControlJobs) Format(ctx *FmtCtx) {
 	ctx.FormatNode(n.Jobs)
 }
 
+// AlterJob represents an ALTER JOB ... SET statement.
+type AlterJob struct {
+	Job     Expr
+	Options KVOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (n *AlterJob) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER JOB ")
+	ctx.FormatNode(n.Job)
+	ctx.WriteString(" SET ")
+	ctx.FormatNode(&n.Options)
+}
+
 // CancelQueries represents a CANCEL QUERIES statement.
 type CancelQueries struct {
 	Queries  *Select
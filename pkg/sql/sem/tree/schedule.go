@@ -0,0 +1,83 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+// CreateSchedule represents a CREATE SCHEDULE statement.
+type CreateSchedule struct {
+	// Label is the optional name of the schedule.
+	Label      Expr
+	Backup     *Backup
+	Recurrence Expr
+	Options    KVOptions
+}
+
+var _ Statement = &CreateSchedule{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSchedule) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEDULE ")
+	if node.Label != nil {
+		ctx.FormatNode(node.Label)
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("FOR ")
+	ctx.FormatNode(node.Backup)
+	ctx.WriteString(" RECURRING ")
+	ctx.FormatNode(node.Recurrence)
+	if node.Options != nil {
+		ctx.WriteString(" WITH SCHEDULE OPTIONS ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
+// ShowSchedules represents a SHOW SCHEDULES statement.
+type ShowSchedules struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowSchedules) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW SCHEDULES")
+}
+
+// ControlSchedules represents a PAUSE/RESUME/DROP SCHEDULES statement.
+type ControlSchedules struct {
+	Schedules *Select
+	Command   ScheduleCommand
+}
+
+// ScheduleCommand determines which type of action to effect on the selected
+// schedule(s).
+type ScheduleCommand int
+
+// ScheduleCommand values
+const (
+	PauseSchedule ScheduleCommand = iota
+	ResumeSchedule
+	DropSchedule
+)
+
+// ScheduleCommandToStatement translates a schedule command integer to a
+// statement prefix.
+var ScheduleCommandToStatement = map[ScheduleCommand]string{
+	PauseSchedule:  "PAUSE",
+	ResumeSchedule: "RESUME",
+	DropSchedule:   "DROP",
+}
+
+// Format implements the NodeFormatter interface.
+func (n *ControlSchedules) Format(ctx *FmtCtx) {
+	ctx.WriteString(ScheduleCommandToStatement[n.Command])
+	ctx.WriteString(" SCHEDULES ")
+	ctx.FormatNode(n.Schedules)
+}
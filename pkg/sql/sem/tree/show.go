@@ -66,12 +66,15 @@ const (
 	BackupRangeDetails
 	// BackupFileDetails identifies a SHOW BACKUP FILES statement.
 	BackupFileDetails
+	// BackupRevisionDetails identifies a SHOW BACKUP REVISIONS statement.
+	BackupRevisionDetails
 )
 
 // ShowBackup represents a SHOW BACKUP statement.
 type ShowBackup struct {
-	Path    Expr
+	Paths   []StringOrPlaceholderOptList
 	Details BackupDetails
+	Options KVOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -81,8 +84,19 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 		ctx.WriteString("RANGES ")
 	} else if node.Details == BackupFileDetails {
 		ctx.WriteString("FILES ")
+	} else if node.Details == BackupRevisionDetails {
+		ctx.WriteString("REVISIONS ")
+	}
+	for i := range node.Paths {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&node.Paths[i])
+	}
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
 	}
-	ctx.FormatNode(node.Path)
 }
 
 // ShowColumns represents a SHOW COLUMNS statement.
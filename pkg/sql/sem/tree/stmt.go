@@ -169,6 +169,12 @@ func (*AlterIndex) StatementTag() string { return "ALTER INDEX" }
 
 func (*AlterIndex) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*AlterJob) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterJob) StatementTag() string { return "ALTER JOB" }
+
 // StatementType implements the Statement interface.
 func (*AlterTable) StatementType() StatementType { return DDL }
 
@@ -221,6 +227,14 @@ func (n *ControlJobs) StatementTag() string {
 
 func (*ControlJobs) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ControlSchedules) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *ControlSchedules) StatementTag() string {
+	return fmt.Sprintf("%s SCHEDULES", ScheduleCommandToStatement[n.Command])
+}
+
 // StatementType implements the Statement interface.
 func (*CancelQueries) StatementType() StatementType { return RowsAffected }
 
@@ -314,6 +328,12 @@ func (*CreateSequence) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateSequence) StatementTag() string { return "CREATE SEQUENCE" }
 
+// StatementType implements the Statement interface.
+func (*CreateSchedule) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateSchedule) StatementTag() string { return "CREATE SCHEDULE" }
+
 // StatementType implements the Statement interface.
 func (*CreateStats) StatementType() StatementType { return DDL }
 
@@ -665,6 +685,12 @@ func (*ShowJobs) StatementTag() string { return "SHOW JOBS" }
 
 func (*ShowJobs) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowSchedules) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowSchedules) StatementTag() string { return "SHOW SCHEDULES" }
+
 // StatementType implements the Statement interface.
 func (*ShowRoleGrants) StatementType() StatementType { return Rows }
 
@@ -791,6 +817,7 @@ func (*ValuesClause) StatementType() StatementType { return Rows }
 func (*ValuesClause) StatementTag() string { return "VALUES" }
 
 func (n *AlterIndex) String() string                { return AsString(n) }
+func (n *AlterJob) String() string                  { return AsString(n) }
 func (n *AlterTable) String() string                { return AsString(n) }
 func (n *AlterTableCmds) String() string            { return AsString(n) }
 func (n *AlterTableAddColumn) String() string       { return AsString(n) }
@@ -807,6 +834,7 @@ func (n *AlterSequence) String() string             { return AsString(n) }
 func (n *Backup) String() string                    { return AsString(n) }
 func (n *BeginTransaction) String() string          { return AsString(n) }
 func (n *ControlJobs) String() string               { return AsString(n) }
+func (n *ControlSchedules) String() string          { return AsString(n) }
 func (n *CancelQueries) String() string             { return AsString(n) }
 func (n *CancelSessions) String() string            { return AsString(n) }
 func (n *CommitTransaction) String() string         { return AsString(n) }
@@ -816,6 +844,7 @@ func (n *CreateDatabase) String() string            { return AsString(n) }
 func (n *CreateIndex) String() string               { return AsString(n) }
 func (n *CreateRole) String() string                { return AsString(n) }
 func (n *CreateTable) String() string               { return AsString(n) }
+func (n *CreateSchedule) String() string            { return AsString(n) }
 func (n *CreateSequence) String() string            { return AsString(n) }
 func (n *CreateStats) String() string               { return AsString(n) }
 func (n *CreateUser) String() string                { return AsString(n) }
@@ -870,6 +899,7 @@ func (n *ShowGrants) String() string                { return AsString(n) }
 func (n *ShowHistogram) String() string             { return AsString(n) }
 func (n *ShowIndex) String() string                 { return AsString(n) }
 func (n *ShowJobs) String() string                  { return AsString(n) }
+func (n *ShowSchedules) String() string             { return AsString(n) }
 func (n *ShowQueries) String() string               { return AsString(n) }
 func (n *ShowRanges) String() string                { return AsString(n) }
 func (n *ShowRoleGrants) String() string            { return AsString(n) }
@@ -725,9 +725,33 @@ func walkReturningClause(v Visitor, clause ReturningClause) (ReturningClause, bo
 	}
 }
 
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *AlterJob) copyNode() *AlterJob {
+	stmtCopy := *stmt
+	stmtCopy.Options = append(KVOptions(nil), stmt.Options...)
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *AlterJob) walkStmt(v Visitor) Statement {
+	ret := stmt
+	if e, changed := WalkExpr(v, stmt.Job); changed {
+		ret = stmt.copyNode()
+		ret.Job = e
+	}
+	if opts, changed := walkKVOptions(v, stmt.Options); changed {
+		if ret == stmt {
+			ret = stmt.copyNode()
+		}
+		ret.Options = opts
+	}
+	return ret
+}
+
 // copyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Backup) copyNode() *Backup {
 	stmtCopy := *stmt
+	stmtCopy.To = append(StringOrPlaceholderOptList(nil), stmt.To...)
 	stmtCopy.IncrementalFrom = append(Exprs(nil), stmt.IncrementalFrom...)
 	stmtCopy.Options = append(KVOptions(nil), stmt.Options...)
 	return &stmtCopy
@@ -745,13 +769,13 @@ func (stmt *Backup) walkStmt(v Visitor) Statement {
 			ret.AsOf.Expr = e
 		}
 	}
-	{
-		e, changed := WalkExpr(v, stmt.To)
+	for i, expr := range stmt.To {
+		e, changed := WalkExpr(v, expr)
 		if changed {
 			if ret == stmt {
 				ret = stmt.copyNode()
 			}
-			ret.To = e
+			ret.To[i] = e
 		}
 	}
 	for i, expr := range stmt.IncrementalFrom {
@@ -917,6 +941,22 @@ func (stmt *ControlJobs) walkStmt(v Visitor) Statement {
 	return stmt
 }
 
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *ControlSchedules) copyNode() *ControlSchedules {
+	stmtCopy := *stmt
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *ControlSchedules) walkStmt(v Visitor) Statement {
+	sel, changed := walkStmt(v, stmt.Schedules)
+	if changed {
+		stmt = stmt.copyNode()
+		stmt.Schedules = sel.(*Select)
+	}
+	return stmt
+}
+
 // copyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Import) copyNode() *Import {
 	stmtCopy := *stmt
@@ -970,7 +1010,10 @@ func (stmt *ParenSelect) walkStmt(v Visitor) Statement {
 // copyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Restore) copyNode() *Restore {
 	stmtCopy := *stmt
-	stmtCopy.From = append(Exprs(nil), stmt.From...)
+	stmtCopy.From = make([]StringOrPlaceholderOptList, len(stmt.From))
+	for i := range stmt.From {
+		stmtCopy.From[i] = append(StringOrPlaceholderOptList(nil), stmt.From[i]...)
+	}
 	stmtCopy.Options = append(KVOptions(nil), stmt.Options...)
 	return &stmtCopy
 }
@@ -987,13 +1030,15 @@ func (stmt *Restore) walkStmt(v Visitor) Statement {
 			ret.AsOf.Expr = e
 		}
 	}
-	for i, expr := range stmt.From {
-		e, changed := WalkExpr(v, expr)
-		if changed {
-			if ret == stmt {
-				ret = stmt.copyNode()
+	for i, layer := range stmt.From {
+		for j, expr := range layer {
+			e, changed := WalkExpr(v, expr)
+			if changed {
+				if ret == stmt {
+					ret = stmt.copyNode()
+				}
+				ret.From[i][j] = e
 			}
-			ret.From[i] = e
 		}
 	}
 	{
@@ -1321,6 +1366,7 @@ func (stmt *ValuesClause) walkStmt(v Visitor) Statement {
 }
 
 var _ walkableStmt = &CreateTable{}
+var _ walkableStmt = &AlterJob{}
 var _ walkableStmt = &Backup{}
 var _ walkableStmt = &Delete{}
 var _ walkableStmt = &Explain{}
@@ -1337,6 +1383,7 @@ var _ walkableStmt = &ValuesClause{}
 var _ walkableStmt = &CancelQueries{}
 var _ walkableStmt = &CancelSessions{}
 var _ walkableStmt = &ControlJobs{}
+var _ walkableStmt = &ControlSchedules{}
 
 // walkStmt walks the entire parsed stmt calling WalkExpr on each
 // expression, and replacing each expression with the one returned
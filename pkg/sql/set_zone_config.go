@@ -74,6 +74,16 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 		loadYAML(&c.LeasePreferences, string(tree.MustBeDString(d)))
 		c.InheritedLeasePreferences = false
 	}},
+	"num_voters": {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.NumVoters = proto.Int32(int32(tree.MustBeDInt(d))) }},
+	"voter_constraints": {types.String, func(c *config.ZoneConfig, d tree.Datum) {
+		constraintsList := config.ConstraintsList{
+			Constraints: c.VoterConstraints,
+			Inherited:   c.InheritedVoterConstraints(),
+		}
+		loadYAML(&constraintsList, string(tree.MustBeDString(d)))
+		c.VoterConstraints = constraintsList.Constraints
+		c.NullVoterConstraintsIsEmpty = len(c.VoterConstraints) == 0
+	}},
 }
 
 // zoneOptionKeys contains the keys from suportedZoneConfigOptions in
@@ -538,7 +548,8 @@ type nodeGetter func(context.Context, *serverpb.NodesRequest) (*serverpb.NodesRe
 func validateZoneAttrsAndLocalities(
 	ctx context.Context, getNodes nodeGetter, zone *config.ZoneConfig,
 ) error {
-	if len(zone.Constraints) == 0 && len(zone.LeasePreferences) == 0 {
+	if len(zone.Constraints) == 0 && len(zone.LeasePreferences) == 0 &&
+		len(zone.VoterConstraints) == 0 {
 		return nil
 	}
 
@@ -568,6 +579,11 @@ func validateZoneAttrsAndLocalities(
 			addToValidate(constraint)
 		}
 	}
+	for _, constraints := range zone.VoterConstraints {
+		for _, constraint := range constraints.Constraints {
+			addToValidate(constraint)
+		}
+	}
 	for _, leasePreferences := range zone.LeasePreferences {
 		for _, constraint := range leasePreferences.Constraints {
 			addToValidate(constraint)
@@ -638,6 +654,13 @@ func writeZoneConfig(
 				"cluster version does not support zone configs with lease placement preferences")
 		}
 	}
+	if zone.NumVoters != nil || len(zone.VoterConstraints) > 0 {
+		st := execCfg.Settings
+		if !st.Version.IsMinSupported(cluster.VersionNonVotingReplicas) {
+			return 0, pgerror.NewError(pgerror.CodeCheckViolationError,
+				"cluster version does not support zone configs with non-voting replicas")
+		}
+	}
 
 	if zone.IsSubzonePlaceholder() && len(zone.Subzones) == 0 {
 		return execCfg.InternalExecutor.Exec(ctx, "delete-zone", txn,
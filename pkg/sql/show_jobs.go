@@ -30,7 +30,8 @@ func (p *planner) ShowJobs(ctx context.Context, n *tree.ShowJobs) (planNode, err
 	// running jobs have finished = NULL.
 	return p.delegateQuery(ctx, "SHOW JOBS",
 		`SELECT job_id, job_type, description, user_name, status, running_status, created,
-            started, finished, modified, fraction_completed, error, coordinator_id
+            started, finished, modified, fraction_completed, estimated_finish, error,
+            coordinator_id
        FROM crdb_internal.jobs
    ORDER BY COALESCE(finished, now()) DESC, started DESC`,
 		nil, nil)
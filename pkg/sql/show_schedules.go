@@ -0,0 +1,37 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// ShowSchedules returns all the schedules.
+// Privileges: superuser.
+func (p *planner) ShowSchedules(ctx context.Context, n *tree.ShowSchedules) (planNode, error) {
+	return p.delegateQuery(ctx, "SHOW SCHEDULES",
+		`SELECT schedule_id AS id, schedule_name AS label,
+            IF(next_run IS NULL, 'PAUSED', 'ACTIVE') AS schedule_status,
+            next_run, state, schedule_expr AS recurrence,
+            on_previous_running, on_execution_failure, last_job_id,
+            owner, created, description AS command
+       FROM system.scheduled_jobs
+   ORDER BY created`,
+		func(ctx context.Context) error {
+			return p.RequireSuperUser(ctx, "SHOW SCHEDULES")
+		}, nil)
+}
@@ -2250,6 +2250,11 @@ func (desc *TableDescriptor) Adding() bool {
 	return desc.State == TableDescriptor_ADD
 }
 
+// Offline returns true if the table is offline, e.g. while it is importing.
+func (desc *TableDescriptor) Offline() bool {
+	return desc.State == TableDescriptor_OFFLINE
+}
+
 // IsNewTable returns true if the table was created in the current
 // transaction.
 func (desc *MutableTableDescriptor) IsNewTable() bool {
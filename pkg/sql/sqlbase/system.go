@@ -224,6 +224,27 @@ CREATE TABLE system.comments (
    comment   STRING NOT NULL, -- the comment
    PRIMARY KEY (type, object_id, sub_id)
 );`
+
+	// scheduled_jobs stores schedules that periodically start a job executing
+	// a SQL statement. A schedule is paused when its next_run is NULL. The
+	// description is the statement with any credentials removed, for display.
+	ScheduledJobsTableSchema = `
+CREATE TABLE system.scheduled_jobs (
+	schedule_id          INT8      DEFAULT unique_rowid() PRIMARY KEY,
+	schedule_name        STRING    NOT NULL,
+	created              TIMESTAMP NOT NULL DEFAULT now(),
+	owner                STRING    NOT NULL,
+	next_run             TIMESTAMP,
+	schedule_expr        STRING    NOT NULL,
+	execution_stmt       STRING    NOT NULL,
+	on_previous_running  STRING    NOT NULL,
+	on_execution_failure STRING    NOT NULL,
+	last_job_id          INT8,
+	state                STRING,
+	description          STRING    NOT NULL,
+	INDEX (next_run),
+	FAMILY "primary" (schedule_id, schedule_name, created, owner, next_run, schedule_expr, execution_stmt, on_previous_running, on_execution_failure, last_job_id, state, description)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -264,6 +285,7 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.LocationsTableID:       privilege.ReadWriteData,
 	keys.RoleMembersTableID:     privilege.ReadWriteData,
 	keys.CommentsTableID:        privilege.ReadWriteData,
+	keys.ScheduledJobsTableID:   privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -869,6 +891,67 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// ScheduledJobsTable is the descriptor for the scheduled_jobs table.
+	ScheduledJobsTable = TableDescriptor{
+		Name:     "scheduled_jobs",
+		ID:       keys.ScheduledJobsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "schedule_id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "schedule_name", ID: 2, Type: colTypeString},
+			{Name: "created", ID: 3, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "owner", ID: 4, Type: colTypeString},
+			{Name: "next_run", ID: 5, Type: colTypeTimestamp, Nullable: true},
+			{Name: "schedule_expr", ID: 6, Type: colTypeString},
+			{Name: "execution_stmt", ID: 7, Type: colTypeString},
+			{Name: "on_previous_running", ID: 8, Type: colTypeString},
+			{Name: "on_execution_failure", ID: 9, Type: colTypeString},
+			{Name: "last_job_id", ID: 10, Type: colTypeInt, Nullable: true},
+			{Name: "state", ID: 11, Type: colTypeString, Nullable: true},
+			{Name: "description", ID: 12, Type: colTypeString},
+		},
+		NextColumnID: 13,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"schedule_id",
+					"schedule_name",
+					"created",
+					"owner",
+					"next_run",
+					"schedule_expr",
+					"execution_stmt",
+					"on_previous_running",
+					"on_execution_failure",
+					"last_job_id",
+					"state",
+					"description",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("schedule_id"),
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "scheduled_jobs_next_run_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"next_run"},
+				ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC},
+				ColumnIDs:        []ColumnID{5},
+				ExtraColumnIDs:   []ColumnID{1},
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ScheduledJobsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
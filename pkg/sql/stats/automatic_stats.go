@@ -0,0 +1,351 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+)
+
+// AutomaticStatisticsClusterMode controls the cluster setting for enabling
+// automatic table statistics collection.
+var AutomaticStatisticsClusterMode = settings.RegisterBoolSetting(
+	"sql.stats.automatic_collection.enabled",
+	"automatic statistics collection mode",
+	false,
+)
+
+// AutomaticStatisticsFractionStaleRows controls the cluster setting for
+// the target fraction of rows in a table that should be stale before
+// statistics on that table are refreshed, in addition to the constant value
+// AutomaticStatisticsMinStaleRows.
+var AutomaticStatisticsFractionStaleRows = settings.RegisterNonNegativeFloatSetting(
+	"sql.stats.automatic_collection.fraction_stale_rows",
+	"target fraction of stale rows per table that will trigger a statistics refresh",
+	0.2,
+)
+
+// AutomaticStatisticsMinStaleRows controls the cluster setting for the target
+// number of rows that should be updated before a table is refreshed, in
+// addition to the fraction AutomaticStatisticsFractionStaleRows.
+var AutomaticStatisticsMinStaleRows = settings.RegisterNonNegativeIntSetting(
+	"sql.stats.automatic_collection.min_stale_rows",
+	"target minimum number of stale rows per table that will trigger a statistics refresh",
+	500,
+)
+
+// AutomaticStatisticsMaxRetained controls the cluster setting for the number
+// of automatically collected statistics kept for each column (or group of
+// columns) of a table. Older statistics are deleted when a new one is added.
+var AutomaticStatisticsMaxRetained = settings.RegisterPositiveIntSetting(
+	"sql.stats.automatic_collection.max_retained_per_column",
+	"maximum number of automatically collected statistics retained per column",
+	4,
+)
+
+// AutoStatsName is the name to use for statistics created automatically.
+// The name is chosen to be something that users are unlikely to choose when
+// running CREATE STATISTICS manually.
+const AutoStatsName = "__auto__"
+
+// DefaultRefreshInterval is the frequency at which the Refresher will check
+// whether any tables have accumulated enough mutations to need new
+// statistics.
+const DefaultRefreshInterval = time.Minute
+
+// Refresher is responsible for automatically refreshing the table statistics
+// that are used by the cost-based optimizer. It is necessary to periodically
+// refresh the statistics to prevent them from becoming stale as data in the
+// database changes.
+//
+// The Refresher is designed to schedule a CREATE_STATS job once the number of
+// rows modified in a table since its statistics were last refreshed exceeds
+// a fraction of the table's row count (plus a constant). The number of rows
+// modified is reported by the sql layer through NotifyMutation once the
+// transaction that modified them commits, and the row count is taken from the
+// most recent statistic in the TableStatisticsCache.
+//
+// Mutation counts are tracked per node, so on a multi-node cluster each node
+// decides independently when to refresh a table based on the writes it has
+// coordinated. A node does not start a job for a table that already has a
+// CREATE_STATS job pending or running, whichever node scheduled it.
+type Refresher struct {
+	st          *cluster.Settings
+	cache       *TableStatisticsCache
+	jobRegistry *jobs.Registry
+
+	mu struct {
+		syncutil.Mutex
+
+		// mutationCounts contains the number of rows modified in each table
+		// since its statistics were last refreshed by this node.
+		mutationCounts map[sqlbase.ID]int64
+
+		// refreshing contains the tables which are currently being considered
+		// for a refresh, to avoid scheduling more than one job per table.
+		refreshing map[sqlbase.ID]struct{}
+	}
+}
+
+// MakeRefresher creates a new Refresher.
+func MakeRefresher(
+	st *cluster.Settings, cache *TableStatisticsCache, jobRegistry *jobs.Registry,
+) *Refresher {
+	r := &Refresher{
+		st:          st,
+		cache:       cache,
+		jobRegistry: jobRegistry,
+	}
+	r.mu.mutationCounts = make(map[sqlbase.ID]int64)
+	r.mu.refreshing = make(map[sqlbase.ID]struct{})
+	return r
+}
+
+// Start starts the stats refresher thread, which periodically checks whether
+// any tables need their statistics refreshed.
+func (r *Refresher) Start(
+	ctx context.Context, stopper *stop.Stopper, refreshInterval time.Duration,
+) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !AutomaticStatisticsClusterMode.Get(&r.st.SV) ||
+					!r.st.Version.IsMinSupported(cluster.VersionCreateStatsJob) {
+					continue
+				}
+				for tableID, rowsAffected := range r.pendingTables() {
+					tableID, rowsAffected := tableID, rowsAffected
+					if err := stopper.RunAsyncTask(
+						ctx, "stats.Refresher: maybeRefreshStats", func(ctx context.Context) {
+							defer r.doneRefreshing(tableID)
+							r.maybeRefreshStats(ctx, tableID, rowsAffected)
+						},
+					); err != nil {
+						r.doneRefreshing(tableID)
+						log.Errorf(ctx, "failed to refresh stats for table %d: %v", tableID, err)
+					}
+				}
+
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// NotifyMutation is called when a transaction which modified rowsAffected
+// rows of a table commits, to signal to the Refresher that the table has been
+// mutated. It is safe to call on a nil Refresher, in which case it does
+// nothing.
+func (r *Refresher) NotifyMutation(tableID sqlbase.ID, rowsAffected int64) {
+	if r == nil || rowsAffected <= 0 {
+		return
+	}
+	if sqlbase.IsReservedID(tableID) || tableID == keys.VirtualDescriptorID {
+		// Don't collect statistics for system or virtual tables.
+		return
+	}
+	if !AutomaticStatisticsClusterMode.Get(&r.st.SV) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.mutationCounts[tableID] += rowsAffected
+}
+
+// pendingTables returns the mutation counts of the tables that are not
+// already being considered for a refresh, and marks them as refreshing.
+func (r *Refresher) pendingTables() map[sqlbase.ID]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending := make(map[sqlbase.ID]int64, len(r.mu.mutationCounts))
+	for tableID, rowsAffected := range r.mu.mutationCounts {
+		if _, ok := r.mu.refreshing[tableID]; ok {
+			continue
+		}
+		r.mu.refreshing[tableID] = struct{}{}
+		pending[tableID] = rowsAffected
+	}
+	return pending
+}
+
+func (r *Refresher) doneRefreshing(tableID sqlbase.ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mu.refreshing, tableID)
+}
+
+// maybeRefreshStats schedules a CREATE_STATS job for the given table if
+// rowsAffected exceeds the target number of stale rows, or if the table has no
+// statistics yet. On success, the rows accounted for by the refresh are
+// removed from the table's mutation count.
+func (r *Refresher) maybeRefreshStats(
+	ctx context.Context, tableID sqlbase.ID, rowsAffected int64,
+) {
+	tableStats, err := r.cache.GetTableStats(ctx, tableID)
+	if err != nil {
+		log.Errorf(ctx, "failed to get table statistics for table %d: %v", tableID, err)
+		return
+	}
+
+	// Statistics are sorted by creation time, so the first one has the most
+	// recent row count.
+	if len(tableStats) > 0 {
+		targetRows := staleRowTarget(
+			tableStats[0].RowCount,
+			AutomaticStatisticsFractionStaleRows.Get(&r.st.SV),
+			AutomaticStatisticsMinStaleRows.Get(&r.st.SV),
+		)
+		if rowsAffected < targetRows {
+			return
+		}
+	}
+
+	if err := r.refreshStats(ctx, tableID); err != nil {
+		if err == errCreateStatsJobRunning {
+			// Another job is refreshing the table already; keep the mutation
+			// count so it is considered again on the next tick.
+			log.VEventf(ctx, 1, "not refreshing statistics on table %d: %v", tableID, err)
+			return
+		}
+		log.Errorf(ctx, "failed to create statistics on table %d: %v", tableID, err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.mutationCounts[tableID] -= rowsAffected
+	if r.mu.mutationCounts[tableID] <= 0 {
+		delete(r.mu.mutationCounts, tableID)
+	}
+}
+
+// staleRowTarget returns the number of rows which must be modified in a table
+// with the given row count before its statistics are considered stale.
+func staleRowTarget(rowCount uint64, fractionStale float64, minStale int64) int64 {
+	return int64(float64(rowCount)*fractionStale) + minStale
+}
+
+// refreshStats runs a CREATE_STATS job on the given table and waits for it
+// to finish. If a CREATE_STATS job on the table is already pending or running,
+// it returns errCreateStatsJobRunning instead.
+func (r *Refresher) refreshStats(ctx context.Context, tableID sqlbase.ID) error {
+	if running, err := r.createStatsJobRunning(ctx, tableID); err != nil {
+		return err
+	} else if running {
+		return errCreateStatsJobRunning
+	}
+	_, errCh, err := r.jobRegistry.StartJob(ctx, nil /* resultsCh */, jobs.Record{
+		Description:   fmt.Sprintf("CREATE STATISTICS %s FROM [%d]", AutoStatsName, tableID),
+		Username:      security.RootUser,
+		DescriptorIDs: sqlbase.IDs{tableID},
+		Details: jobspb.CreateStatsDetails{
+			Name:    AutoStatsName,
+			TableID: tableID,
+		},
+		Progress: jobspb.CreateStatsProgress{},
+	})
+	if err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+// errCreateStatsJobRunning is returned by refreshStats when the table already
+// has a CREATE_STATS job pending or running.
+var errCreateStatsJobRunning = errors.New("a CREATE STATISTICS job is already running")
+
+// createStatsJobRunning returns whether a CREATE_STATS job on the given table
+// is pending or running, on this node or any other.
+func (r *Refresher) createStatsJobRunning(ctx context.Context, tableID sqlbase.ID) (bool, error) {
+	const stmt = `SELECT payload FROM system.jobs WHERE status IN ($1, $2)`
+	rows, _ /* cols */, err := r.cache.SQLExecutor.Query(
+		ctx, "find-running-create-stats", nil /* txn */, stmt, jobs.StatusPending, jobs.StatusRunning,
+	)
+	if err != nil {
+		return false, err
+	}
+	for _, row := range rows {
+		payload, err := jobs.UnmarshalPayload(row[0])
+		if err != nil {
+			return false, err
+		}
+		if payload.Type() == jobspb.TypeCreateStats && payload.GetCreateStats().TableID == tableID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteOldStatsForColumns deletes old statistics with the given name from
+// the system.table_statistics table for the given table and columns, keeping
+// only the keepCount most recent ones.
+func DeleteOldStatsForColumns(
+	ctx context.Context,
+	executor sqlutil.InternalExecutor,
+	txn *client.Txn,
+	tableID sqlbase.ID,
+	name string,
+	columnIDs []sqlbase.ColumnID,
+	keepCount int64,
+) error {
+	columnIDsVal := tree.NewDArray(types.Int)
+	for _, c := range columnIDs {
+		if err := columnIDsVal.Append(tree.NewDInt(tree.DInt(int(c)))); err != nil {
+			return err
+		}
+	}
+
+	_, err := executor.Exec(
+		ctx, "delete-statistics", txn,
+		`DELETE FROM system.table_statistics
+               WHERE "tableID" = $1
+               AND name = $2
+               AND "columnIDs" = $3
+               AND "statisticID" NOT IN (
+                   SELECT "statisticID" FROM system.table_statistics
+                   WHERE "tableID" = $1
+                   AND name = $2
+                   AND "columnIDs" = $3
+                   ORDER BY "createdAt" DESC
+                   LIMIT $4
+               )`,
+		tableID,
+		name,
+		columnIDsVal,
+		keepCount,
+	)
+	return err
+}
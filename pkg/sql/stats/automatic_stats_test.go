@@ -0,0 +1,182 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestMaybeRefreshStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	sqlRun := sqlutils.MakeSQLRunner(sqlDB)
+	sqlRun.Exec(t,
+		`CREATE DATABASE t;
+		CREATE TABLE t.a (k INT PRIMARY KEY);
+		INSERT INTO t.a VALUES (1), (2), (3), (4), (5);`)
+
+	st := s.ClusterSettings()
+	AutomaticStatisticsClusterMode.Override(&st.SV, true)
+	AutomaticStatisticsMinStaleRows.Override(&st.SV, 10)
+
+	ex := s.InternalExecutor().(sqlutil.InternalExecutor)
+	cache := NewTableStatisticsCache(10 /* cacheSize */, s.Gossip(), kvDB, ex)
+	refresher := MakeRefresher(st, cache, s.JobRegistry().(*jobs.Registry))
+	tableID := sqlbase.GetTableDescriptor(kvDB, "t", "a").ID
+
+	checkStatsCount := func(expected int) {
+		t.Helper()
+		sqlRun.CheckQueryResults(t,
+			`SELECT count(*) FROM system.table_statistics WHERE name = '__auto__'`,
+			[][]string{{strconv.Itoa(expected)}},
+		)
+	}
+
+	// There are no statistics yet, so the first refresh must collect them.
+	refresher.maybeRefreshStats(ctx, tableID, 0 /* rowsAffected */)
+	checkStatsCount(1)
+
+	// The target is 5 * 0.2 + 10 = 11 stale rows.
+	cache.InvalidateTableStats(ctx, tableID)
+	refresher.maybeRefreshStats(ctx, tableID, 10 /* rowsAffected */)
+	checkStatsCount(1)
+	refresher.maybeRefreshStats(ctx, tableID, 11 /* rowsAffected */)
+	checkStatsCount(2)
+
+	// Only the most recent automatic statistics are retained.
+	AutomaticStatisticsMaxRetained.Override(&st.SV, 2)
+	for i := 0; i < 3; i++ {
+		if err := refresher.refreshStats(ctx, tableID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkStatsCount(2)
+
+	// No job is started while another CREATE_STATS job on the table is
+	// pending, even if it was scheduled by a different node.
+	job := s.JobRegistry().(*jobs.Registry).NewJob(jobs.Record{
+		Description: "pending auto stats",
+		Username:    security.RootUser,
+		Details:     jobspb.CreateStatsDetails{Name: AutoStatsName, TableID: tableID},
+		Progress:    jobspb.CreateStatsProgress{},
+	})
+	if err := job.Created(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := refresher.refreshStats(ctx, tableID); err != errCreateStatsJobRunning {
+		t.Fatalf("expected %v, got %v", errCreateStatsJobRunning, err)
+	}
+	refresher.maybeRefreshStats(ctx, tableID, 100 /* rowsAffected */)
+	checkStatsCount(2)
+
+	// Mutations are only tracked for user tables.
+	refresher.NotifyMutation(tableID, 5)
+	refresher.NotifyMutation(sqlbase.TableStatisticsTable.ID, 5)
+	if pending := refresher.pendingTables(); len(pending) != 1 || pending[tableID] != 5 {
+		t.Fatalf("expected 5 pending mutations on table %d, found %v", tableID, pending)
+	}
+}
+
+// TestRefreshStatsConcurrentWrites checks that a CREATE_STATS job completes
+// while the table is being written to, since its scan doesn't conflict with
+// the writes.
+func TestRefreshStatsConcurrentWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	sqlRun := sqlutils.MakeSQLRunner(sqlDB)
+	sqlRun.Exec(t,
+		`CREATE DATABASE t;
+		CREATE TABLE t.a (k INT PRIMARY KEY, v INT);
+		INSERT INTO t.a SELECT k, 0 FROM generate_series(1, 100) AS g(k);`)
+
+	st := s.ClusterSettings()
+	ex := s.InternalExecutor().(sqlutil.InternalExecutor)
+	cache := NewTableStatisticsCache(10 /* cacheSize */, s.Gossip(), kvDB, ex)
+	refresher := MakeRefresher(st, cache, s.JobRegistry().(*jobs.Registry))
+	tableID := sqlbase.GetTableDescriptor(kvDB, "t", "a").ID
+
+	// Keep updating every row of the table until the job is done.
+	done := make(chan struct{})
+	writerErr := make(chan error, 1)
+	go func() {
+		defer close(writerErr)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := sqlDB.Exec(`UPDATE t.a SET v = v + 1`); err != nil {
+				writerErr <- err
+				return
+			}
+		}
+	}()
+
+	err := refresher.refreshStats(ctx, tableID)
+	close(done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-writerErr; err != nil {
+		t.Fatal(err)
+	}
+	sqlRun.CheckQueryResults(t,
+		`SELECT DISTINCT "rowCount" FROM system.table_statistics WHERE name = '__auto__'`,
+		[][]string{{"100"}},
+	)
+}
+
+func TestStaleRowTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		rowCount      uint64
+		fractionStale float64
+		minStale      int64
+		expected      int64
+	}{
+		{0, 0.2, 500, 500},
+		{1000, 0.2, 500, 700},
+		{1000, 0, 0, 0},
+		{1000000, 0.5, 10, 500010},
+	}
+	for _, tc := range testCases {
+		if actual := staleRowTarget(tc.rowCount, tc.fractionStale, tc.minStale); actual != tc.expected {
+			t.Errorf("rowCount=%d fractionStale=%f minStale=%d: expected %d, got %d",
+				tc.rowCount, tc.fractionStale, tc.minStale, tc.expected, actual)
+		}
+	}
+}
@@ -117,6 +117,8 @@ func filterTableState(tableDesc *sqlbase.TableDescriptor) error {
 		return errTableDropped
 	case tableDesc.Adding():
 		return errTableAdding
+	case tableDesc.Offline():
+		return errors.Errorf("table %q is offline: %s", tableDesc.Name, tableDesc.OfflineReason)
 	case tableDesc.State != sqlbase.TableDescriptor_PUBLIC:
 		return errors.Errorf("table in unknown state: %s", tableDesc.State.String())
 	}
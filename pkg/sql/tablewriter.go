@@ -112,6 +112,10 @@ type extendedTableWriter interface {
 	// batch size because the actual KV batch will be constructed only
 	// during the call to atBatchEnd().
 	curBatchSize() int
+
+	// totalRowsWritten returns the number of rows written by the tableWriter
+	// so far, across all batches that have been flushed or finalized.
+	totalRowsWritten() int64
 }
 
 var _ extendedTableWriter = (*tableUpdater)(nil)
@@ -127,6 +131,9 @@ type tableWriterBase struct {
 	b *client.Batch
 	// batchSize is the current batch size (when known).
 	batchSize int
+	// rowsWritten is the number of rows written by the tableWriter across all
+	// batches, used to report mutations to the automatic statistics refresher.
+	rowsWritten int64
 }
 
 func (tb *tableWriterBase) init(txn *client.Txn) {
@@ -142,11 +149,16 @@ func (tb *tableWriterBase) flushAndStartNewBatch(
 	if err := tb.txn.Run(ctx, tb.b); err != nil {
 		return row.ConvertBatchError(ctx, tableDesc, tb.b)
 	}
+	tb.rowsWritten += int64(tb.batchSize)
 	tb.b = tb.txn.NewBatch()
 	tb.batchSize = 0
 	return nil
 }
 
+// totalRowsWritten shares the common totalRowsWritten() code between
+// extendedTableWriters.
+func (tb *tableWriterBase) totalRowsWritten() int64 { return tb.rowsWritten }
+
 // curBatchSize shares the common curBatchSize() code between extendedTableWriters().
 func (tb *tableWriterBase) curBatchSize() int { return tb.batchSize }
 
@@ -166,6 +178,7 @@ func (tb *tableWriterBase) finalize(
 	if err != nil {
 		return row.ConvertBatchError(ctx, tableDesc, tb.b)
 	}
+	tb.rowsWritten += int64(tb.batchSize)
 	return nil
 }
 
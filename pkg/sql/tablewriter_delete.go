@@ -134,6 +134,7 @@ func (td *tableDeleter) fastDelete(
 		}
 	}
 
+	td.rowsWritten += int64(rowCount)
 	td.b = nil
 	return rowCount, nil
 }
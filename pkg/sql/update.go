@@ -601,6 +601,11 @@ func (u *updateNode) BatchedNext(params runParams) (bool, error) {
 		}
 		// Remember we're done for the next call to BatchedNext().
 		u.run.done = true
+
+		// Let the automatic statistics refresher know about the mutation.
+		params.p.notifyMutation(
+			u.run.tu.tableDesc().ID, u.run.tu.totalRowsWritten(),
+		)
 	}
 
 	return u.run.rowCount > 0, nil
@@ -349,6 +349,11 @@ func (n *upsertNode) BatchedNext(params runParams) (bool, error) {
 		}
 		// Remember we're done for the next call to BatchedNext().
 		n.run.done = true
+
+		// Let the automatic statistics refresher know about the mutation.
+		params.p.notifyMutation(
+			n.run.tw.tableDesc().ID, n.run.tw.totalRowsWritten(),
+		)
 	}
 
 	return n.run.tw.batchedCount() > 0, nil
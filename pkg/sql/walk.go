@@ -562,6 +562,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	case *controlJobsNode:
 		n.rows = v.visit(n.rows)
 
+	case *controlSchedulesNode:
+		n.rows = v.visit(n.rows)
+
 	case *setZoneConfigNode:
 		if v.observer.expr != nil {
 			v.metadataExpr(name, "yaml", -1, n.yamlConfig)
@@ -670,6 +673,7 @@ func colListStr(cols []int) string {
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterIndexNode{}):           "alter index",
+	reflect.TypeOf(&alterJobNode{}):             "alter job",
 	reflect.TypeOf(&alterSequenceNode{}):        "alter sequence",
 	reflect.TypeOf(&alterTableNode{}):           "alter table",
 	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
@@ -677,6 +681,8 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&cancelQueriesNode{}):        "cancel queries",
 	reflect.TypeOf(&cancelSessionsNode{}):       "cancel sessions",
 	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
+	reflect.TypeOf(&controlSchedulesNode{}):     "control schedules",
+	reflect.TypeOf(&createScheduleNode{}):       "create schedule",
 	reflect.TypeOf(&createDatabaseNode{}):       "create database",
 	reflect.TypeOf(&createIndexNode{}):          "create index",
 	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
@@ -196,6 +196,13 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		workFn:           createCommentTable,
 		newDescriptorIDs: staticIDs(keys.CommentsTableID),
 	},
+	{
+		// Introduced in v2.2.
+		// TODO(anyone): bake this migration into v2.3.
+		name:             "create system.scheduled_jobs table",
+		workFn:           createScheduledJobsTable,
+		newDescriptorIDs: staticIDs(keys.ScheduledJobsTableID),
+	},
 }
 
 func staticIDs(ids ...sqlbase.ID) func(ctx context.Context, db db) ([]sqlbase.ID, error) {
@@ -513,6 +520,10 @@ func createCommentTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, sqlbase.CommentsTable)
 }
 
+func createScheduledJobsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ScheduledJobsTable)
+}
+
 var reportingOptOut = envutil.EnvOrDefaultBool("COCKROACH_SKIP_ENABLING_DIAGNOSTIC_REPORTING", false)
 
 func runStmtAsRootWithRetry(
@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/raft"
 )
@@ -56,6 +57,9 @@ const (
 	removeDeadReplicaPriority             float64 = 1000
 	removeDecommissioningReplicaPriority  float64 = 200
 	removeExtraReplicaPriority            float64 = 100
+	addMissingNonVoterPriority            float64 = 500
+	removeDeadNonVoterPriority            float64 = 400
+	removeExtraNonVoterPriority           float64 = 50
 )
 
 // MinLeaseTransferStatsDuration configures the minimum amount of time a
@@ -102,6 +106,10 @@ const (
 	AllocatorRemoveDead
 	AllocatorRemoveDecommissioning
 	AllocatorConsiderRebalance
+	AllocatorPromoteNonVoter
+	AllocatorAddNonVoter
+	AllocatorRemoveNonVoter
+	AllocatorRemoveDeadNonVoter
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
@@ -111,6 +119,10 @@ var allocatorActionNames = map[AllocatorAction]string{
 	AllocatorRemoveDead:            "remove dead",
 	AllocatorRemoveDecommissioning: "remove decommissioning",
 	AllocatorConsiderRebalance:     "consider rebalance",
+	AllocatorPromoteNonVoter:       "promote non-voter",
+	AllocatorAddNonVoter:           "add non-voter",
+	AllocatorRemoveNonVoter:        "remove non-voter",
+	AllocatorRemoveDeadNonVoter:    "remove dead non-voter",
 }
 
 func (a AllocatorAction) String() string {
@@ -275,6 +287,66 @@ func GetNeededReplicas(zoneConfigReplicaCount int32, availableNodes int) int {
 	return need
 }
 
+// GetNeededNonVoters calculates the number of non-voting replicas a range
+// should have given the number of voters it needs, its zone config and the
+// number of nodes available for up-replication. Voters take precedence over
+// non-voters when there aren't enough nodes for both.
+func GetNeededNonVoters(numVoters int, zoneConfigNonVoterCount int32, availableNodes int) int {
+	need := int(zoneConfigNonVoterCount)
+	if numVoters+need > availableNodes {
+		need = availableNodes - numVoters
+	}
+	if need < 0 {
+		need = 0
+	}
+	return need
+}
+
+// voterZone returns the zone config that governs the placement of the voting
+// replicas of a range. If the zone doesn't specify num_voters, all replicas
+// are voters and the zone is returned unchanged. Otherwise NumReplicas is
+// replaced by the number of voters and Constraints by VoterConstraints, or, if
+// those aren't set, by the constraints that apply to all replicas.
+func voterZone(zone *config.ZoneConfig) *config.ZoneConfig {
+	if zone.NumVoters == nil {
+		return zone
+	}
+	z := *zone
+	z.NumReplicas = proto.Int32(zone.GetNumVoters())
+	if len(zone.VoterConstraints) > 0 {
+		z.Constraints = zone.VoterConstraints
+	} else {
+		z.Constraints = nil
+		for _, constraints := range zone.Constraints {
+			if constraints.NumReplicas == 0 {
+				z.Constraints = append(z.Constraints, constraints)
+			}
+		}
+	}
+	return &z
+}
+
+// promotableNonVoters returns the non-voting replicas of the range that are
+// live and not decommissioning, and can therefore be promoted to voters.
+func (a *Allocator) promotableNonVoters(desc *roachpb.RangeDescriptor) []roachpb.ReplicaDescriptor {
+	liveNonVoters, _ := a.storePool.liveAndDeadReplicas(desc.RangeID, desc.NonVoters())
+	decommissioning := a.storePool.decommissioningReplicas(desc.RangeID, liveNonVoters)
+	var promotable []roachpb.ReplicaDescriptor
+	for _, rep := range liveNonVoters {
+		var isDecommissioning bool
+		for _, d := range decommissioning {
+			if d.StoreID == rep.StoreID {
+				isDecommissioning = true
+				break
+			}
+		}
+		if !isDecommissioning {
+			promotable = append(promotable, rep)
+		}
+	}
+	return promotable
+}
+
 // ComputeAction determines the exact operation needed to repair the
 // supplied range, as governed by the supplied zone configuration. It
 // returns the required action that should be taken and a priority.
@@ -287,20 +359,34 @@ func (a *Allocator) ComputeAction(
 	}
 	// TODO(mrtracy): Handle non-homogeneous and mismatched attribute sets.
 
-	have := len(rangeInfo.Desc.Replicas)
-	decommissioningReplicas := a.storePool.decommissioningReplicas(rangeInfo.Desc.RangeID, rangeInfo.Desc.Replicas)
+	// Only voting replicas count towards quorum, so the voters are repaired
+	// first. The non-voting replicas are handled once the voters are in order.
+	voters := rangeInfo.Desc.Voters()
+	have := len(voters)
+	decommissioningReplicas := a.storePool.decommissioningReplicas(rangeInfo.Desc.RangeID, voters)
 	availableNodes := a.storePool.AvailableNodeCount()
-	need := GetNeededReplicas(*zone.NumReplicas, availableNodes)
+	need := GetNeededReplicas(zone.GetNumVoters(), availableNodes)
 	desiredQuorum := computeQuorum(need)
 	quorum := computeQuorum(have)
 
+	// addVoter returns the action that adds a voter to the range. Promoting an
+	// existing non-voting replica is preferred over adding a new replica since
+	// it doesn't require a snapshot.
+	addVoter := func(priority float64) AllocatorAction {
+		if len(a.promotableNonVoters(rangeInfo.Desc)) > 0 {
+			log.VEventf(ctx, 3, "AllocatorPromoteNonVoter - priority=%.2f", priority)
+			return AllocatorPromoteNonVoter
+		}
+		return AllocatorAdd
+	}
+
 	if have < need {
 		// Range is under-replicated, and should add an additional replica.
 		// Priority is adjusted by the difference between the current replica
 		// count and the quorum of the desired replica count.
 		priority := addMissingReplicaPriority + float64(desiredQuorum-have)
 		log.VEventf(ctx, 3, "AllocatorAdd - missing replica need=%d, have=%d, priority=%.2f", need, have, priority)
-		return AllocatorAdd, priority
+		return addVoter(priority), priority
 	}
 
 	if have == need && len(decommissioningReplicas) > 0 {
@@ -310,10 +396,10 @@ func (a *Allocator) ComputeAction(
 		priority := addDecommissioningReplacementPriority
 		log.VEventf(ctx, 3, "AllocatorAdd - replacement for %d decommissioning replicas priority=%.2f",
 			len(decommissioningReplicas), priority)
-		return AllocatorAdd, priority
+		return addVoter(priority), priority
 	}
 
-	liveReplicas, deadReplicas := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, rangeInfo.Desc.Replicas)
+	liveReplicas, deadReplicas := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, voters)
 	if len(liveReplicas) < quorum {
 		// Do not take any removal action if we do not have a quorum of live
 		// replicas.
@@ -331,7 +417,7 @@ func (a *Allocator) ComputeAction(
 		priority := addDeadReplacementPriority
 		log.VEventf(ctx, 3, "AllocatorAdd - replacement for %d dead replicas priority=%.2f",
 			len(deadReplicas), priority)
-		return AllocatorAdd, priority
+		return addVoter(priority), priority
 	}
 
 	// Removal actions follow.
@@ -362,11 +448,51 @@ func (a *Allocator) ComputeAction(
 		// Range is over-replicated, and should remove a replica.
 		// Ranges with an even number of replicas get extra priority because
 		// they have a more fragile quorum.
+		//
+		// Raft can't demote a voter in place, so a voter that should become a
+		// non-voter is removed here and a non-voter is added back below.
 		priority := removeExtraReplicaPriority - float64(have%2)
 		log.VEventf(ctx, 3, "AllocatorRemove - need=%d, have=%d, priority=%.2f", need, have, priority)
 		return AllocatorRemove, priority
 	}
 
+	nonVoters := rangeInfo.Desc.NonVoters()
+	haveNonVoters := len(nonVoters)
+	needNonVoters := GetNeededNonVoters(need, zone.GetNumNonVoters(), availableNodes)
+	_, deadNonVoters := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, nonVoters)
+	decommissioningNonVoters := a.storePool.decommissioningReplicas(rangeInfo.Desc.RangeID, nonVoters)
+
+	if haveNonVoters < needNonVoters ||
+		(haveNonVoters == needNonVoters && len(deadNonVoters)+len(decommissioningNonVoters) > 0) {
+		// Range is missing a non-voting replica, or has one that needs to be
+		// replaced. As with voters, the replacement is added before the dead or
+		// decommissioning non-voter is removed.
+		priority := addMissingNonVoterPriority + float64(needNonVoters-haveNonVoters)
+		log.VEventf(ctx, 3, "AllocatorAddNonVoter - need=%d, have=%d, dead=%d, decommissioning=%d, priority=%.2f",
+			needNonVoters, haveNonVoters, len(deadNonVoters), len(decommissioningNonVoters), priority)
+		return AllocatorAddNonVoter, priority
+	}
+
+	if len(deadNonVoters) > 0 {
+		priority := removeDeadNonVoterPriority
+		log.VEventf(ctx, 3, "AllocatorRemoveDeadNonVoter - dead=%d, priority=%.2f", len(deadNonVoters), priority)
+		return AllocatorRemoveDeadNonVoter, priority
+	}
+
+	if len(decommissioningNonVoters) > 0 {
+		priority := removeDecommissioningReplicaPriority
+		log.VEventf(ctx, 3, "AllocatorRemoveDecommissioning - num_decommissioning_non_voters=%d, priority=%.2f",
+			len(decommissioningNonVoters), priority)
+		return AllocatorRemoveDecommissioning, priority
+	}
+
+	if haveNonVoters > needNonVoters {
+		priority := removeExtraNonVoterPriority
+		log.VEventf(ctx, 3, "AllocatorRemoveNonVoter - need=%d, have=%d, priority=%.2f",
+			needNonVoters, haveNonVoters, priority)
+		return AllocatorRemoveNonVoter, priority
+	}
+
 	// Nothing needs to be done, but we may want to rebalance.
 	return AllocatorConsiderRebalance, 0
 }
@@ -436,6 +562,40 @@ func (a *Allocator) allocateTargetFromList(
 	return nil, ""
 }
 
+// PromoteTarget returns the non-voting replica that is best suited to be
+// promoted to a voter. Only live, non-decommissioning non-voters are
+// considered, and they're ranked against the voter constraints as if a new
+// voter were being allocated.
+func (a *Allocator) PromoteTarget(
+	ctx context.Context, zone *config.ZoneConfig, rangeInfo RangeInfo,
+) (roachpb.ReplicaDescriptor, string, error) {
+	promotable := a.promotableNonVoters(rangeInfo.Desc)
+	if len(promotable) == 0 {
+		return roachpb.ReplicaDescriptor{}, "", errors.Errorf("no promotable non-voting replicas in %s", rangeInfo.Desc)
+	}
+	storeIDs := make(roachpb.StoreIDSlice, len(promotable))
+	for i, rep := range promotable {
+		storeIDs[i] = rep.StoreID
+	}
+	sl, _, _ := a.storePool.getStoreListFromIDs(storeIDs, roachpb.RangeID(0), storeFilterNone)
+
+	target, details := a.allocateTargetFromList(
+		ctx, sl, voterZone(zone), rangeInfo.Desc.Voters(), rangeInfo, a.scorerOptions())
+	if target == nil {
+		return roachpb.ReplicaDescriptor{}, "", &allocatorError{
+			constraints:      voterZone(zone).Constraints,
+			existingReplicas: len(rangeInfo.Desc.Voters()),
+			aliveStores:      len(sl.stores),
+		}
+	}
+	for _, rep := range promotable {
+		if rep.StoreID == target.StoreID {
+			return rep, details, nil
+		}
+	}
+	return roachpb.ReplicaDescriptor{}, "", errors.Errorf("s%d has no non-voting replica to promote", target.StoreID)
+}
+
 func (a Allocator) simulateRemoveTarget(
 	ctx context.Context,
 	targetStore roachpb.StoreID,
@@ -531,6 +691,13 @@ func (a Allocator) RebalanceTarget(
 	rangeInfo RangeInfo,
 	filter storeFilter,
 ) (*roachpb.StoreDescriptor, string) {
+	// Rebalancing adds a voter and then removes the worst replica, which could
+	// be a non-voter. Until that's accounted for, ranges with non-voting
+	// replicas are only repaired, not rebalanced.
+	if len(rangeInfo.Desc.NonVoters()) > 0 {
+		return nil, ""
+	}
+
 	sl, _, _ := a.storePool.getStoreList(rangeInfo.Desc.RangeID, filter)
 
 	// We're going to add another replica to the range which will change the
@@ -5014,6 +5014,141 @@ func TestAllocatorGetNeededReplicas(t *testing.T) {
 	}
 }
 
+func TestAllocatorGetNeededNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		numVoters     int
+		zoneNonVoters int32
+		availNodes    int
+		expected      int
+	}{
+		{3, 0, 5, 0},
+		{3, 2, 5, 2},
+		{3, 2, 4, 1},
+		{3, 2, 3, 0},
+		{3, 2, 1, 0},
+		{5, 4, 12, 4},
+	}
+
+	for _, tc := range testCases {
+		if e, a := tc.expected, GetNeededNonVoters(tc.numVoters, tc.zoneNonVoters, tc.availNodes); e != a {
+			t.Errorf(
+				"GetNeededNonVoters(numVoters=%d, zoneNonVoters=%d, availNodes=%d) got %d; want %d",
+				tc.numVoters, tc.zoneNonVoters, tc.availNodes, a, e)
+		}
+	}
+}
+
+func TestAllocatorComputeActionNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		voters         []roachpb.StoreID
+		nonVoters      []roachpb.StoreID
+		expectedAction AllocatorAction
+		live           []roachpb.StoreID
+		dead           []roachpb.StoreID
+	}{
+		// All replicas are in place.
+		{
+			voters:         []roachpb.StoreID{1, 2, 3},
+			nonVoters:      []roachpb.StoreID{4, 5},
+			expectedAction: AllocatorConsiderRebalance,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+		},
+		// Missing a non-voter.
+		{
+			voters:         []roachpb.StoreID{1, 2, 3},
+			nonVoters:      []roachpb.StoreID{4},
+			expectedAction: AllocatorAddNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+		},
+		// Missing a voter while a non-voter is available to be promoted.
+		{
+			voters:         []roachpb.StoreID{1, 2},
+			nonVoters:      []roachpb.StoreID{4, 5},
+			expectedAction: AllocatorPromoteNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+		},
+		// Missing a voter and the only non-voters are dead.
+		{
+			voters:         []roachpb.StoreID{1, 2},
+			nonVoters:      []roachpb.StoreID{4},
+			expectedAction: AllocatorAdd,
+			live:           []roachpb.StoreID{1, 2, 3, 5},
+			dead:           []roachpb.StoreID{4},
+		},
+		// Too many voters; one is removed and later re-added as a non-voter.
+		{
+			voters:         []roachpb.StoreID{1, 2, 3, 4},
+			nonVoters:      []roachpb.StoreID{5},
+			expectedAction: AllocatorRemove,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+		},
+		// Too many non-voters.
+		{
+			voters:         []roachpb.StoreID{1, 2, 3},
+			nonVoters:      []roachpb.StoreID{4, 5, 6},
+			expectedAction: AllocatorRemoveNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5, 6},
+		},
+		// A dead non-voter is replaced before being removed.
+		{
+			voters:         []roachpb.StoreID{1, 2, 3},
+			nonVoters:      []roachpb.StoreID{4, 5},
+			expectedAction: AllocatorAddNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 6},
+			dead:           []roachpb.StoreID{5},
+		},
+		{
+			voters:         []roachpb.StoreID{1, 2, 3},
+			nonVoters:      []roachpb.StoreID{4, 5, 6},
+			expectedAction: AllocatorRemoveDeadNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 6},
+			dead:           []roachpb.StoreID{5},
+		},
+		// A dead voter is replaced by promoting a non-voter.
+		{
+			voters:         []roachpb.StoreID{1, 2, 3},
+			nonVoters:      []roachpb.StoreID{4, 5},
+			expectedAction: AllocatorPromoteNonVoter,
+			live:           []roachpb.StoreID{1, 2, 4, 5},
+			dead:           []roachpb.StoreID{3},
+		},
+		// Once replaced, the dead voter is removed.
+		{
+			voters:         []roachpb.StoreID{1, 2, 3, 4},
+			nonVoters:      []roachpb.StoreID{5},
+			expectedAction: AllocatorRemoveDead,
+			live:           []roachpb.StoreID{1, 2, 4, 5, 6},
+			dead:           []roachpb.StoreID{3},
+		},
+	}
+
+	stopper, _, sp, a, _ := createTestAllocator( /* deterministic */ false)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+	zone := &config.ZoneConfig{
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+	}
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, tcase.live, nil, tcase.dead, nil, nil, nil)
+		desc := makeDescriptor(append(append([]roachpb.StoreID(nil), tcase.voters...), tcase.nonVoters...))
+		for j := len(tcase.voters); j < len(desc.Replicas); j++ {
+			typ := roachpb.NON_VOTER
+			desc.Replicas[j].Type = &typ
+		}
+		action, _ := a.ComputeAction(ctx, zone, RangeInfo{Desc: &desc})
+		if tcase.expectedAction != action {
+			t.Errorf("test case %d expected action %q, got action %q",
+				i, allocatorActionNames[tcase.expectedAction], allocatorActionNames[action])
+		}
+	}
+}
+
 func makeDescriptor(storeList []roachpb.StoreID) roachpb.RangeDescriptor {
 	desc := roachpb.RangeDescriptor{
 		EndKey: roachpb.RKey(keys.SystemPrefix),
@@ -15,6 +15,7 @@
 package batcheval
 
 import (
+	"bytes"
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -25,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/pkg/errors"
 )
 
@@ -66,6 +68,12 @@ func EvalAddSSTable(
 	}
 	ms.Subtract(existingStats)
 
+	if args.DisallowShadowing {
+		if err := checkForKeyCollisions(existingIter, args.Data); err != nil {
+			return result.Result{}, err
+		}
+	}
+
 	// Verify that the keys in the sstable are within the range specified by the
 	// request header, verify the key-value checksums, and compute the new
 	// MVCCStats.
@@ -86,6 +94,62 @@ func EvalAddSSTable(
 	}, nil
 }
 
+// checkForKeyCollisions returns an error if any key in the sstable shadows a
+// live key in the existing data, or if any existing key has an intent. A key
+// with the same timestamp and value as the existing one doesn't count as a
+// collision, so that a retry of the same request succeeds.
+func checkForKeyCollisions(existingIter engine.Iterator, data []byte) error {
+	dataIter, err := engine.NewMemSSTIterator(data, false)
+	if err != nil {
+		return err
+	}
+	defer dataIter.Close()
+
+	for dataIter.Seek(engine.MVCCKey{Key: keys.MinKey}); ; dataIter.NextKey() {
+		if ok, err := dataIter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+		sstKey := dataIter.UnsafeKey()
+		existingIter.Seek(engine.MakeMVCCMetadataKey(sstKey.Key))
+		if ok, err := existingIter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			// There's no existing data at or after this key, so none of the
+			// remaining keys can collide either.
+			return nil
+		}
+		existingKey := existingIter.UnsafeKey()
+		if !existingKey.Key.Equal(sstKey.Key) {
+			continue
+		}
+		if !existingKey.IsValue() {
+			var meta enginepb.MVCCMetadata
+			if err := protoutil.Unmarshal(existingIter.UnsafeValue(), &meta); err != nil {
+				return err
+			}
+			if meta.Txn != nil {
+				return &roachpb.WriteIntentError{Intents: []roachpb.Intent{{
+					Span:   roachpb.Span{Key: append(roachpb.Key(nil), sstKey.Key...)},
+					Status: roachpb.PENDING,
+					Txn:    *meta.Txn,
+				}}}
+			}
+			if meta.Deleted || len(meta.RawBytes) == 0 {
+				continue
+			}
+		} else if existingKey.Timestamp == sstKey.Timestamp &&
+			bytes.Equal(existingIter.UnsafeValue(), dataIter.UnsafeValue()) {
+			continue
+		} else if len(existingIter.UnsafeValue()) == 0 {
+			// The newest existing revision is a deletion tombstone.
+			continue
+		}
+		return errors.Errorf("ingested key collides with an existing one: %s", sstKey.Key)
+	}
+}
+
 func verifySSTable(
 	existingIter engine.SimpleIterator, data []byte, start, end engine.MVCCKey, nowNanos int64,
 ) (enginepb.MVCCStats, error) {
@@ -96,13 +96,13 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 
 		// Key is before the range in the request span.
 		if err := db.AddSSTable(
-			ctx, "d", "e", data,
+			ctx, "d", "e", data, false, /* disallowShadowing */
 		); !testutils.IsError(err, "not in request range") {
 			t.Fatalf("expected request range error got: %+v", err)
 		}
 		// Key is after the range in the request span.
 		if err := db.AddSSTable(
-			ctx, "a", "b", data,
+			ctx, "a", "b", data, false, /* disallowShadowing */
 		); !testutils.IsError(err, "not in request range") {
 			t.Fatalf("expected request range error got: %+v", err)
 		}
@@ -110,7 +110,7 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 		// Do an initial ingest.
 		ingestCtx, collect, cancel := tracing.ContextWithRecordingSpan(ctx, "test-recording")
 		defer cancel()
-		if err := db.AddSSTable(ingestCtx, "b", "c", data); err != nil {
+		if err := db.AddSSTable(ingestCtx, "b", "c", data, false /* disallowShadowing */); err != nil {
 			t.Fatalf("%+v", err)
 		}
 		formatted := tracing.FormatRecordedSpans(collect())
@@ -151,7 +151,7 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 			t.Fatalf("%+v", err)
 		}
 
-		if err := db.AddSSTable(ctx, "b", "c", data); err != nil {
+		if err := db.AddSSTable(ctx, "b", "c", data, false /* disallowShadowing */); err != nil {
 			t.Fatalf("%+v", err)
 		}
 		if r, err := db.Get(ctx, "bb"); err != nil {
@@ -186,7 +186,7 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 			ingestCtx, collect, cancel := tracing.ContextWithRecordingSpan(ctx, "test-recording")
 			defer cancel()
 
-			if err := db.AddSSTable(ingestCtx, "b", "c", data); err != nil {
+			if err := db.AddSSTable(ingestCtx, "b", "c", data, false /* disallowShadowing */); err != nil {
 				t.Fatalf("%+v", err)
 			}
 			if err := testutils.MatchInOrder(tracing.FormatRecordedSpans(collect()),
@@ -232,7 +232,7 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 			t.Fatalf("%+v", err)
 		}
 
-		if err := db.AddSSTable(ctx, "b", "c", data); !testutils.IsError(err, "invalid checksum") {
+		if err := db.AddSSTable(ctx, "b", "c", data, false /* disallowShadowing */); !testutils.IsError(err, "invalid checksum") {
 			t.Fatalf("expected 'invalid checksum' error got: %+v", err)
 		}
 	}
@@ -392,3 +392,89 @@ func TestAddSSTableMVCCStats(t *testing.T) {
 		}
 	}
 }
+
+func TestAddSSTableDisallowShadowing(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	e := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer e.Close()
+
+	mvccKey := func(key string, ts int64) engine.MVCCKey {
+		return engine.MVCCKey{Key: roachpb.Key(key), Timestamp: hlc.Timestamp{WallTime: ts}}
+	}
+	value := func(v string) []byte {
+		return roachpb.MakeValueFromString(v).RawBytes
+	}
+	// "a" is live, "b" is deleted and "d" has an intent.
+	for _, kv := range []engine.MVCCKeyValue{
+		{Key: mvccKey("a", 2), Value: value("a")},
+		{Key: mvccKey("b", 2), Value: value("b")},
+		{Key: mvccKey("b", 3), Value: nil},
+	} {
+		if err := e.Put(kv.Key, kv.Value); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	txn := roachpb.MakeTransaction("test", nil, roachpb.NormalUserPriority, hlc.Timestamp{WallTime: 4}, 0)
+	if err := engine.MVCCPut(
+		ctx, e, nil, roachpb.Key("d"), txn.Timestamp, roachpb.MakeValueFromString("d"), &txn,
+	); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	addSST := func(disallowShadowing bool, kvs ...engine.MVCCKeyValue) error {
+		sst, err := engine.MakeRocksDBSstFileWriter()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		defer sst.Close()
+		for _, kv := range kvs {
+			if err := sst.Add(kv); err != nil {
+				t.Fatalf("%+v", err)
+			}
+		}
+		data, err := sst.Finish()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		cArgs := batcheval.CommandArgs{
+			Header: roachpb.Header{Timestamp: hlc.Timestamp{WallTime: 10}},
+			Args: &roachpb.AddSSTableRequest{
+				RequestHeader:     roachpb.RequestHeader{Key: keys.MinKey, EndKey: keys.MaxKey},
+				Data:              data,
+				DisallowShadowing: disallowShadowing,
+			},
+			Stats: &enginepb.MVCCStats{},
+		}
+		_, err = batcheval.EvalAddSSTable(ctx, e, cArgs, nil)
+		return err
+	}
+
+	// Keys that are deleted or don't exist, and identical revisions that may
+	// be re-ingested when a request is retried, don't collide.
+	if err := addSST(true,
+		engine.MVCCKeyValue{Key: mvccKey("a", 2), Value: value("a")},
+		engine.MVCCKeyValue{Key: mvccKey("b", 5), Value: value("b")},
+		engine.MVCCKeyValue{Key: mvccKey("c", 5), Value: value("c")},
+	); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// Shadowing a live key is only allowed if requested.
+	shadow := engine.MVCCKeyValue{Key: mvccKey("a", 5), Value: value("x")}
+	if err := addSST(true, shadow); !testutils.IsError(err, "ingested key collides with an existing one") {
+		t.Fatalf("expected collision error, got: %+v", err)
+	}
+	if err := addSST(false, shadow); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// Intents can't be checked for collisions.
+	intent := engine.MVCCKeyValue{Key: mvccKey("d", 5), Value: value("x")}
+	if err := addSST(true, intent); err == nil {
+		t.Fatal("expected an error ingesting over an intent")
+	} else if _, ok := err.(*roachpb.WriteIntentError); !ok {
+		t.Fatalf("expected WriteIntentError, got: %+v", err)
+	}
+}
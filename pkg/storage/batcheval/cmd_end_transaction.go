@@ -233,7 +233,11 @@ func evalEndTransaction(
 		return result.FromEndTxn(reply.Txn, true /* alwaysReturn */, args.Poison),
 			roachpb.NewTransactionAbortedError(roachpb.ABORT_REASON_ABORTED_RECORD_FOUND)
 
-	case roachpb.PENDING:
+	case roachpb.PENDING, roachpb.STAGING:
+		// A STAGING record belongs to a transaction whose parallel commit
+		// attempt either is still in progress or failed. Either way, the
+		// coordinator is free to re-stage, commit, or abort it, subject to the
+		// same regression checks as a PENDING record.
 		if h.Txn.Epoch < reply.Txn.Epoch {
 			// TODO(tschottdorf): this leaves the Txn record (and more
 			// importantly, intents) dangling; we can't currently write on
@@ -287,6 +291,27 @@ func evalEndTransaction(
 				"transaction deadline exceeded")
 		}
 
+		// If the transaction is performing a parallel commit, move its record
+		// to the STAGING state along with the set of writes that must succeed
+		// for it to be implicitly committed. Its intents can't be resolved
+		// until the transaction is explicitly committed, either by its
+		// coordinator or by a transaction recovery process.
+		if len(args.InFlightWrites) > 0 {
+			if args.InternalCommitTrigger != nil {
+				return result.Result{}, roachpb.NewTransactionStatusError(
+					"cannot perform parallel commit with commit trigger")
+			}
+			reply.Txn.Status = roachpb.STAGING
+			reply.Txn.InFlightWrites = args.InFlightWrites
+			reply.Txn.Intents = args.IntentSpans
+			if err := engine.MVCCPutProto(ctx, batch, ms, key, hlc.Timestamp{}, nil /* txn */, reply.Txn); err != nil {
+				return result.Result{}, err
+			}
+			var res result.Result
+			res.Local.UpdatedTxns = &[]*roachpb.Transaction{reply.Txn}
+			return res, nil
+		}
+
 		reply.Txn.Status = roachpb.COMMITTED
 
 		// Merge triggers must run before intent resolution as the merge trigger
@@ -312,6 +337,7 @@ func evalEndTransaction(
 	} else {
 		reply.Txn.Status = roachpb.ABORTED
 	}
+	reply.Txn.InFlightWrites = nil
 
 	desc := cArgs.EvalCtx.Desc()
 	externalIntents, err := resolveLocalIntents(ctx, desc, batch, ms, *args, reply.Txn, cArgs.EvalCtx)
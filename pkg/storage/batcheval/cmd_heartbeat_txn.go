@@ -69,7 +69,7 @@ func HeartbeatTxn(
 		return result.Result{}, roachpb.NewTransactionNotFoundStatusError()
 	}
 
-	if txn.Status == roachpb.PENDING {
+	if !txn.Status.IsFinalized() {
 		txn.LastHeartbeat.Forward(args.Now)
 		if err := engine.MVCCPutProto(ctx, batch, cArgs.Stats, key, hlc.Timestamp{}, nil, &txn); err != nil {
 			return result.Result{}, err
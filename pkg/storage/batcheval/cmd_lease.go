@@ -87,7 +87,8 @@ func evalNewLease(
 
 	// Verify that requesting replica is part of the current replica set.
 	desc := rec.Desc()
-	if _, ok := desc.GetReplicaDescriptor(lease.Replica.StoreID); !ok {
+	repDesc, ok := desc.GetReplicaDescriptor(lease.Replica.StoreID)
+	if !ok {
 		return newFailedLeaseTrigger(isTransfer),
 			&roachpb.LeaseRejectedError{
 				Existing:  prevLease,
@@ -95,6 +96,16 @@ func evalNewLease(
 				Message:   "replica not found",
 			}
 	}
+	// A non-voting replica doesn't participate in quorum, so it can't tell
+	// whether its log is up to date and must never hold the lease.
+	if !repDesc.IsVoter() {
+		return newFailedLeaseTrigger(isTransfer),
+			&roachpb.LeaseRejectedError{
+				Existing:  prevLease,
+				Requested: lease,
+				Message:   "replica is a non-voter",
+			}
+	}
 
 	// Requests should not set the sequence number themselves. Set the sequence
 	// number here based on whether the lease is equivalent to the one it's
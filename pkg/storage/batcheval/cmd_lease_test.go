@@ -0,0 +1,74 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestLeaseNonVoter verifies that neither a lease request nor a lease transfer
+// can hand the lease to a non-voting replica.
+func TestLeaseNonVoter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	db := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer db.Close()
+
+	nonVoter := roachpb.NON_VOTER
+	voterDesc := roachpb.ReplicaDescriptor{NodeID: 1, StoreID: 1, ReplicaID: 1}
+	nonVoterDesc := roachpb.ReplicaDescriptor{NodeID: 2, StoreID: 2, ReplicaID: 2, Type: &nonVoter}
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKeyMin,
+		EndKey:   roachpb.RKeyMax,
+		Replicas: []roachpb.ReplicaDescriptor{voterDesc, nonVoterDesc},
+	}
+	ts := hlc.Timestamp{WallTime: 10}
+	evalCtx := &mockEvalCtx{
+		desc:  &desc,
+		lease: roachpb.Lease{Replica: voterDesc, Start: ts, Epoch: 1, Sequence: 1},
+	}
+	nonVoterLease := roachpb.Lease{Replica: nonVoterDesc, Start: ts.Add(1, 0), Epoch: 1}
+
+	for _, args := range []roachpb.Request{
+		&roachpb.RequestLeaseRequest{Lease: nonVoterLease},
+		&roachpb.TransferLeaseRequest{Lease: nonVoterLease},
+	} {
+		var ms enginepb.MVCCStats
+		cArgs := CommandArgs{EvalCtx: evalCtx, Args: args, Stats: &ms}
+		var err error
+		switch args.(type) {
+		case *roachpb.RequestLeaseRequest:
+			_, err = RequestLease(ctx, db, cArgs, &roachpb.RequestLeaseResponse{})
+		case *roachpb.TransferLeaseRequest:
+			_, err = TransferLease(ctx, db, cArgs, &roachpb.RequestLeaseResponse{})
+		}
+		if _, ok := err.(*roachpb.LeaseRejectedError); !ok {
+			t.Fatalf("%s: expected lease rejection, got %v", args.Method(), err)
+		}
+		if !testutils.IsError(err, "replica is a non-voter") {
+			t.Fatalf("%s: unexpected error %v", args.Method(), err)
+		}
+	}
+}
@@ -56,6 +56,14 @@ func declareKeysPushTransaction(
 // Txn already committed/aborted: If pushee txn is committed or
 // aborted return success.
 //
+// Txn staging: If pushee txn is staging, its record can't be modified since
+// it may already be implicitly committed. If the pushee is expired and the
+// push would otherwise succeed, return an IndeterminateCommitError so that
+// the pusher runs the transaction recovery process, which determines whether
+// all of its in-flight writes succeeded. Otherwise, its coordinator is
+// expected to finish the commit shortly, so return TransactionPushError and
+// let the pusher wait for it.
+//
 // Txn Timeout: If pushee txn entry isn't present or its LastHeartbeat
 // timestamp isn't set, use its as LastHeartbeat. If current time -
 // LastHeartbeat > 2 * DefaultHeartbeatInterval, then the pushee txn
@@ -152,7 +160,7 @@ func PushTxn(
 	reply.PusheeTxn = existTxn.Clone()
 
 	// If already committed or aborted, return success.
-	if reply.PusheeTxn.Status != roachpb.PENDING {
+	if reply.PusheeTxn.Status.IsFinalized() {
 		// Trivial noop.
 		return result.Result{}, nil
 	}
@@ -174,8 +182,9 @@ func PushTxn(
 	var pusherWins bool
 	var reason string
 
+	expired := txnwait.IsExpired(args.Now, &reply.PusheeTxn)
 	switch {
-	case txnwait.IsExpired(args.Now, &reply.PusheeTxn):
+	case expired:
 		reason = "pushee is expired"
 		// When cleaning up, actually clean up (as opposed to simply pushing
 		// the garbage in the path of future writers).
@@ -203,6 +212,14 @@ func PushTxn(
 			reason, reply.PusheeTxn.LastActive())
 	}
 
+	// If the pushee is STAGING, it may be implicitly committed, in which case
+	// neither aborting it nor moving its timestamp is permitted. Unless its
+	// coordinator appears to have died, it is about to resolve the commit, so
+	// the pusher waits for it like for a pushee of higher priority.
+	if reply.PusheeTxn.Status == roachpb.STAGING && !expired {
+		pusherWins = false
+	}
+
 	if !pusherWins {
 		err := roachpb.NewTransactionPushError(reply.PusheeTxn)
 		if log.V(1) {
@@ -211,6 +228,13 @@ func PushTxn(
 		return result.Result{}, err
 	}
 
+	// An expired STAGING pushee's record can't be changed until the
+	// transaction recovery process has determined whether all of its in-flight
+	// writes succeeded.
+	if reply.PusheeTxn.Status == roachpb.STAGING {
+		return result.Result{}, roachpb.NewIndeterminateCommitError(reply.PusheeTxn)
+	}
+
 	// Upgrade priority of pushed transaction to one less than pusher's.
 	reply.PusheeTxn.UpgradePriority(args.PusherTxn.Priority - 1)
 
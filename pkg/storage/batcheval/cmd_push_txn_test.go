@@ -0,0 +1,81 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestPushTxnStaging tests that a push of a STAGING transaction only returns
+// an IndeterminateCommitError, which starts transaction recovery, if the
+// pushee is expired. Otherwise, the push fails so that the pusher waits for
+// the pushee's coordinator to finish the commit.
+func TestPushTxnStaging(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	k := roachpb.Key("a")
+	ts := hlc.Timestamp{WallTime: 1}
+	pushee := roachpb.MakeTransaction("pushee", k, roachpb.MinUserPriority, ts, 0)
+	pushee.Status = roachpb.STAGING
+	pushee.InFlightWrites = []roachpb.SequencedWrite{{Key: k, Sequence: 0}}
+	pusher := roachpb.MakeTransaction("pusher", k, roachpb.MaxUserPriority, ts, 0)
+
+	db := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer db.Close()
+	txnKey := keys.TransactionKey(pushee.Key, pushee.ID)
+	txnRecord := pushee.Clone()
+	if err := engine.MVCCPutProto(ctx, db, nil, txnKey, hlc.Timestamp{}, nil, &txnRecord); err != nil {
+		t.Fatal(err)
+	}
+
+	push := func(now hlc.Timestamp) error {
+		var resp roachpb.PushTxnResponse
+		_, err := PushTxn(ctx, db, CommandArgs{
+			Args: &roachpb.PushTxnRequest{
+				RequestHeader: roachpb.RequestHeader{Key: pushee.Key},
+				PusherTxn:     pusher,
+				PusheeTxn:     pushee.TxnMeta,
+				PushTo:        now,
+				Now:           now,
+				PushType:      roachpb.PUSH_ABORT,
+			},
+		}, &resp)
+		return err
+	}
+
+	// The pusher has priority, but the pushee isn't expired.
+	if err := push(ts.Add(1, 0)); err == nil {
+		t.Fatal("expected push of live STAGING transaction to fail")
+	} else if _, ok := err.(*roachpb.TransactionPushError); !ok {
+		t.Fatalf("expected TransactionPushError, got %T: %v", err, err)
+	}
+
+	// Once the pushee expires, the pusher is told to recover it.
+	expired := txnwait.TxnExpiration(&pushee).Add(1, 0)
+	if err := push(expired); err == nil {
+		t.Fatal("expected push of expired STAGING transaction to fail")
+	} else if _, ok := err.(*roachpb.IndeterminateCommitError); !ok {
+		t.Fatalf("expected IndeterminateCommitError, got %T: %v", err, err)
+	}
+}
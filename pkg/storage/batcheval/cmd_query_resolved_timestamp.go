@@ -0,0 +1,92 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+func init() {
+	RegisterCommand(roachpb.QueryResolvedTimestamp, DefaultDeclareKeys, QueryResolvedTimestamp)
+}
+
+// QueryResolvedTimestamp returns the resolved timestamp of the key span, as
+// seen by the evaluating replica. This is the replica's closed timestamp,
+// lowered to just below the timestamp of the oldest intent in the span, if
+// any. A read of the span at or below the resolved timestamp can be served by
+// the replica without blocking on intents.
+//
+// Finding the oldest intent doesn't require a scan when the range's stats
+// show that it has none. Otherwise, only the parts of the engine that may
+// contain data at or below the closed timestamp are scanned, as intents above
+// it can't lower the resolved timestamp.
+func QueryResolvedTimestamp(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.QueryResolvedTimestampRequest)
+	reply := resp.(*roachpb.QueryResolvedTimestampResponse)
+
+	resolvedTS := cArgs.EvalCtx.GetClosedTimestamp()
+	if resolvedTS == (hlc.Timestamp{}) {
+		// Nothing is closed, so nothing can be resolved.
+		return result.Result{}, nil
+	}
+
+	if ms := cArgs.EvalCtx.GetMVCCStats(); ms.IntentCount == 0 && !ms.ContainsEstimates {
+		reply.ResolvedTS = resolvedTS
+		return result.Result{}, nil
+	}
+
+	// Find the oldest intent in the span. Intents are stored in the metadata
+	// key of each key that has them, which sorts before all of the key's
+	// versions. The provisional value of an intent is written at the intent's
+	// timestamp, so the time bounds let the iterator skip sstables that only
+	// hold newer data.
+	iter := batch.NewIterator(engine.IterOptions{
+		UpperBound:       args.EndKey,
+		MinTimestampHint: hlc.MinTimestamp,
+		MaxTimestampHint: resolvedTS,
+	})
+	defer iter.Close()
+	var meta enginepb.MVCCMetadata
+	for iter.Seek(engine.MakeMVCCMetadataKey(args.Key)); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return result.Result{}, err
+		} else if !ok {
+			break
+		}
+		if iter.UnsafeKey().IsValue() {
+			continue
+		}
+		if err := iter.ValueProto(&meta); err != nil {
+			return result.Result{}, err
+		}
+		if meta.Txn == nil {
+			continue
+		}
+		if intentTS := hlc.Timestamp(meta.Timestamp); !resolvedTS.Less(intentTS) {
+			resolvedTS = intentTS.Prev()
+		}
+	}
+
+	reply.ResolvedTS = resolvedTS
+	return result.Result{}, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryResolvedTimestamp tests that QueryResolvedTimestamp returns the
+// replica's closed timestamp, lowered below the oldest intent in the
+// requested span.
+func TestQueryResolvedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+
+	db := engine.NewInMem(roachpb.Attributes{}, 10<<20)
+	defer db.Close()
+
+	// Write a committed value at "a", an intent at "b" @ 10 and an intent at
+	// "d" @ 20.
+	var ms enginepb.MVCCStats
+	put := func(key string, at hlc.Timestamp, txn *roachpb.Transaction) {
+		if err := engine.MVCCPut(
+			ctx, db, &ms, roachpb.Key(key), at, roachpb.MakeValueFromString("v"), txn,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("a", ts(5), nil)
+	txn1 := roachpb.MakeTransaction("test", roachpb.Key("b"), 0, ts(10), 0)
+	put("b", ts(10), &txn1)
+	txn2 := roachpb.MakeTransaction("test", roachpb.Key("d"), 0, ts(20), 0)
+	put("d", ts(20), &txn2)
+
+	testCases := []struct {
+		name     string
+		span     roachpb.Span
+		closedTS hlc.Timestamp
+		stats    *enginepb.MVCCStats
+		expTS    hlc.Timestamp
+	}{
+		{
+			name:     "nothing closed",
+			span:     roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+			closedTS: hlc.Timestamp{},
+			expTS:    hlc.Timestamp{},
+		},
+		{
+			name:     "no intents",
+			span:     roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")},
+			closedTS: ts(30),
+			expTS:    ts(30),
+		},
+		{
+			name:     "oldest intent",
+			span:     roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+			closedTS: ts(30),
+			expTS:    ts(10).Prev(),
+		},
+		{
+			name:     "newer intent",
+			span:     roachpb.Span{Key: roachpb.Key("c"), EndKey: roachpb.Key("z")},
+			closedTS: ts(30),
+			expTS:    ts(20).Prev(),
+		},
+		{
+			name:     "intent above closed timestamp",
+			span:     roachpb.Span{Key: roachpb.Key("c"), EndKey: roachpb.Key("z")},
+			closedTS: ts(15),
+			expTS:    ts(15),
+		},
+		{
+			// The stats are trusted to skip the scan when the range has no
+			// intents, so the ones written above go unnoticed.
+			name:     "no intents in range stats",
+			span:     roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+			closedTS: ts(30),
+			stats:    &enginepb.MVCCStats{},
+			expTS:    ts(30),
+		},
+		{
+			name:     "estimated range stats",
+			span:     roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+			closedTS: ts(30),
+			stats:    &enginepb.MVCCStats{ContainsEstimates: true},
+			expTS:    ts(10).Prev(),
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			stats := ms
+			if c.stats != nil {
+				stats = *c.stats
+			}
+			var resp roachpb.QueryResolvedTimestampResponse
+			if _, err := QueryResolvedTimestamp(ctx, db, CommandArgs{
+				EvalCtx: &mockEvalCtx{closedTS: c.closedTS, stats: stats},
+				Args: &roachpb.QueryResolvedTimestampRequest{
+					RequestHeader: roachpb.RequestHeaderFromSpan(c.span),
+				},
+			}, &resp); err != nil {
+				t.Fatal(err)
+			}
+			require.Equal(t, c.expTS, resp.ResolvedTS)
+		})
+	}
+}
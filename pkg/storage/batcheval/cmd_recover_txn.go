@@ -0,0 +1,158 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterCommand(roachpb.RecoverTxn, declareKeysRecoverTransaction, RecoverTxn)
+}
+
+func declareKeysRecoverTransaction(
+	_ roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
+) {
+	rr := req.(*roachpb.RecoverTxnRequest)
+	spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: keys.TransactionKey(rr.Txn.Key, rr.Txn.ID)})
+}
+
+// RecoverTxn attempts to recover the specified transaction from an
+// indeterminate commit state. Transactions enter this state when abandoned
+// after updating their transaction record with a STAGING status. The RecoverTxn
+// operation is invoked by a caller who encounters a transaction in this state
+// after they have already queried all of the STAGING transaction's declared
+// in-flight writes. The caller specifies whether all of these in-flight writes
+// were found to have succeeded. If so, the transaction is committed. If not, the
+// transaction is aborted, provided that its record has not been re-staged or
+// moved back to PENDING in the meantime.
+func RecoverTxn(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.RecoverTxnRequest)
+	h := cArgs.Header
+	reply := resp.(*roachpb.RecoverTxnResponse)
+
+	if h.Txn != nil {
+		return result.Result{}, ErrTransactionUnsupported
+	}
+	if !bytes.Equal(args.Key, args.Txn.Key) {
+		return result.Result{}, errors.Errorf("request key %s does not match txn key %s", args.Key, args.Txn.Key)
+	}
+	key := keys.TransactionKey(args.Txn.Key, args.Txn.ID)
+
+	// Fetch transaction record; if missing, the transaction must have been
+	// finalized and its record garbage collected. A record can't be removed
+	// while it is STAGING, so this is only an error if the caller claims that
+	// the transaction is implicitly committed, in which case the record must
+	// have been committed and cleaned up already.
+	ok, err := engine.MVCCGetProto(
+		ctx, batch, key, hlc.Timestamp{}, &reply.RecoveredTxn, engine.MVCCGetOptions{},
+	)
+	if err != nil {
+		return result.Result{}, err
+	} else if !ok {
+		if args.ImplicitlyCommitted {
+			// The transaction was committed and its record was removed after its
+			// intents were resolved. There's nothing left to recover.
+			reply.RecoveredTxn.TxnMeta = args.Txn
+			reply.RecoveredTxn.Status = roachpb.COMMITTED
+			return result.Result{}, nil
+		}
+		// The transaction was finalized and removed, but we can't tell whether
+		// it committed or aborted. Report it as aborted, which is the same
+		// inference a PushTxn would make when encountering a missing record.
+		reply.RecoveredTxn.TxnMeta = args.Txn
+		reply.RecoveredTxn.Status = roachpb.ABORTED
+		return result.Result{}, nil
+	}
+
+	// Determine whether the record has already been finalized or whether it
+	// has moved on from the state that the caller observed.
+	switch reply.RecoveredTxn.Status {
+	case roachpb.COMMITTED, roachpb.ABORTED:
+		// The transaction was already finalized, either by its coordinator or
+		// by a concurrent recovery. Either way, there's nothing left to do.
+		if args.ImplicitlyCommitted && reply.RecoveredTxn.Status == roachpb.ABORTED {
+			return result.Result{}, roachpb.NewTransactionStatusError(fmt.Sprintf(
+				"programming error: found ABORTED record for implicitly committed transaction: %s",
+				reply.RecoveredTxn))
+		}
+		return result.Result{}, nil
+
+	case roachpb.PENDING:
+		// The transaction's coordinator must have restarted or refreshed the
+		// transaction after its parallel commit attempt failed. Since one of
+		// its in-flight writes must have failed for that to happen, it can't
+		// have been implicitly committed at the staging epoch and timestamp.
+		if args.ImplicitlyCommitted {
+			return result.Result{}, roachpb.NewTransactionStatusError(fmt.Sprintf(
+				"programming error: found PENDING record for implicitly committed transaction: %s",
+				reply.RecoveredTxn))
+		}
+		return result.Result{}, nil
+
+	case roachpb.STAGING:
+		if reply.RecoveredTxn.Epoch != args.Txn.Epoch ||
+			reply.RecoveredTxn.Timestamp != args.Txn.Timestamp {
+			// The transaction has been re-staged at a later epoch or timestamp
+			// since the caller queried its in-flight writes. The result of that
+			// query does not apply to the new attempt, so the caller must start
+			// over.
+			if args.ImplicitlyCommitted {
+				return result.Result{}, roachpb.NewTransactionStatusError(fmt.Sprintf(
+					"programming error: found re-staged record for implicitly committed transaction: %s",
+					reply.RecoveredTxn))
+			}
+			return result.Result{}, nil
+		}
+
+	default:
+		return result.Result{}, roachpb.NewTransactionStatusError(
+			fmt.Sprintf("bad txn status: %s", reply.RecoveredTxn),
+		)
+	}
+
+	// Finalize the transaction record according to the outcome of the query
+	// of its in-flight writes.
+	if args.ImplicitlyCommitted {
+		reply.RecoveredTxn.Status = roachpb.COMMITTED
+	} else {
+		reply.RecoveredTxn.Status = roachpb.ABORTED
+	}
+	reply.RecoveredTxn.InFlightWrites = nil
+	log.VEventf(ctx, 2, "recovered txn %s from indeterminate commit", reply.RecoveredTxn)
+
+	if err := engine.MVCCPutProto(ctx, batch, cArgs.Stats, key, hlc.Timestamp{}, nil, &reply.RecoveredTxn); err != nil {
+		return result.Result{}, err
+	}
+
+	// Hand the transaction's intents off for asynchronous resolution. As with
+	// EndTransaction, we only want this to happen if the recovery commits.
+	res := result.FromEndTxn(&reply.RecoveredTxn, false /* alwaysReturn */, false /* poison */)
+	res.Local.UpdatedTxns = &[]*roachpb.Transaction{&reply.RecoveredTxn}
+	return res, nil
+}
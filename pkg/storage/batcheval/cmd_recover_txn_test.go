@@ -0,0 +1,186 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecoverTxn tests that RecoverTxn moves a STAGING transaction record to
+// the COMMITTED or ABORTED status depending on whether all of its in-flight
+// writes were found.
+func TestRecoverTxn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	k, k2 := roachpb.Key("a"), roachpb.Key("b")
+	ts := hlc.Timestamp{WallTime: 1}
+	txn := roachpb.MakeTransaction("test", k, 0, ts, 0)
+	txn.Status = roachpb.STAGING
+	txn.Intents = []roachpb.Span{{Key: k}}
+	txn.InFlightWrites = []roachpb.SequencedWrite{{Key: k2, Sequence: 0}}
+
+	testRecover := func(t *testing.T, implicitlyCommitted bool) {
+		db := engine.NewInMem(roachpb.Attributes{}, 10<<20)
+		defer db.Close()
+
+		// Write the transaction record.
+		txnKey := keys.TransactionKey(txn.Key, txn.ID)
+		txnRecord := txn.Clone()
+		if err := engine.MVCCPutProto(ctx, db, nil, txnKey, hlc.Timestamp{}, nil, &txnRecord); err != nil {
+			t.Fatal(err)
+		}
+
+		// Issue a RecoverTxn request.
+		var resp roachpb.RecoverTxnResponse
+		if _, err := RecoverTxn(ctx, db, CommandArgs{
+			Args: &roachpb.RecoverTxnRequest{
+				RequestHeader:       roachpb.RequestHeader{Key: txn.Key},
+				Txn:                 txn.TxnMeta,
+				ImplicitlyCommitted: implicitlyCommitted,
+			},
+		}, &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		// Assert that the response is correct.
+		expTxnRecord := txn.Clone()
+		expTxnRecord.InFlightWrites = nil
+		if implicitlyCommitted {
+			expTxnRecord.Status = roachpb.COMMITTED
+		} else {
+			expTxnRecord.Status = roachpb.ABORTED
+		}
+		require.Equal(t, expTxnRecord, resp.RecoveredTxn)
+
+		// Assert that the updated txn record was persisted correctly.
+		var resTxnRecord roachpb.Transaction
+		if _, err := engine.MVCCGetProto(
+			ctx, db, txnKey, hlc.Timestamp{}, &resTxnRecord, engine.MVCCGetOptions{},
+		); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, expTxnRecord, resTxnRecord)
+	}
+	for _, implicitlyCommitted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("implicitly_committed=%t", implicitlyCommitted), func(t *testing.T) {
+			testRecover(t, implicitlyCommitted)
+		})
+	}
+}
+
+// TestRecoverTxnRecordChanged tests that RecoverTxn does not finalize a
+// transaction record that has moved on from the state that the recovery
+// process observed.
+func TestRecoverTxnRecordChanged(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	k := roachpb.Key("a")
+	ts := hlc.Timestamp{WallTime: 1}
+	ts2 := hlc.Timestamp{WallTime: 2}
+	txn := roachpb.MakeTransaction("test", k, 0, ts, 0)
+	txn.Status = roachpb.STAGING
+
+	testCases := []struct {
+		name                string
+		implicitlyCommitted bool
+		changedTxn          func(roachpb.Transaction) roachpb.Transaction
+		expError            string
+		expStatus           roachpb.TransactionStatus
+	}{
+		{
+			name: "transaction pending, not implicitly committed",
+			changedTxn: func(txn roachpb.Transaction) roachpb.Transaction {
+				txn.Status = roachpb.PENDING
+				return txn
+			},
+			expStatus: roachpb.PENDING,
+		},
+		{
+			name:                "transaction pending, implicitly committed",
+			implicitlyCommitted: true,
+			changedTxn: func(txn roachpb.Transaction) roachpb.Transaction {
+				txn.Status = roachpb.PENDING
+				return txn
+			},
+			expError: "found PENDING record for implicitly committed transaction",
+		},
+		{
+			name: "transaction restaged, not implicitly committed",
+			changedTxn: func(txn roachpb.Transaction) roachpb.Transaction {
+				txn.Timestamp = ts2
+				return txn
+			},
+			expStatus: roachpb.STAGING,
+		},
+		{
+			name: "transaction committed",
+			changedTxn: func(txn roachpb.Transaction) roachpb.Transaction {
+				txn.Status = roachpb.COMMITTED
+				return txn
+			},
+			expStatus: roachpb.COMMITTED,
+		},
+		{
+			name:                "transaction aborted, implicitly committed",
+			implicitlyCommitted: true,
+			changedTxn: func(txn roachpb.Transaction) roachpb.Transaction {
+				txn.Status = roachpb.ABORTED
+				return txn
+			},
+			expError: "found ABORTED record for implicitly committed transaction",
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			db := engine.NewInMem(roachpb.Attributes{}, 10<<20)
+			defer db.Close()
+
+			// Write the modified transaction record.
+			txnKey := keys.TransactionKey(txn.Key, txn.ID)
+			txnRecord := c.changedTxn(txn.Clone())
+			if err := engine.MVCCPutProto(ctx, db, nil, txnKey, hlc.Timestamp{}, nil, &txnRecord); err != nil {
+				t.Fatal(err)
+			}
+
+			// Issue a RecoverTxn request.
+			var resp roachpb.RecoverTxnResponse
+			_, err := RecoverTxn(ctx, db, CommandArgs{
+				Args: &roachpb.RecoverTxnRequest{
+					RequestHeader:       roachpb.RequestHeader{Key: txn.Key},
+					Txn:                 txn.TxnMeta,
+					ImplicitlyCommitted: c.implicitlyCommitted,
+				},
+			}, &resp)
+			if c.expError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expStatus, resp.RecoveredTxn.Status)
+		})
+	}
+}
@@ -44,6 +44,8 @@ type mockEvalCtx struct {
 	qps             float64
 	abortSpan       *abortspan.AbortSpan
 	gcThreshold     hlc.Timestamp
+	closedTS        hlc.Timestamp
+	lease           roachpb.Lease
 }
 
 func (m *mockEvalCtx) String() string {
@@ -79,6 +81,12 @@ func (m *mockEvalCtx) GetTxnWaitQueue() *txnwait.Queue {
 func (m *mockEvalCtx) NodeID() roachpb.NodeID {
 	panic("unimplemented")
 }
+func (m *mockEvalCtx) NodeLocality() roachpb.Locality {
+	panic("unimplemented")
+}
+func (m *mockEvalCtx) GetNodeLocality(roachpb.NodeID) roachpb.Locality {
+	panic("unimplemented")
+}
 func (m *mockEvalCtx) StoreID() roachpb.StoreID {
 	panic("unimplemented")
 }
@@ -119,7 +127,10 @@ func (m *mockEvalCtx) GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp,
 	panic("unimplemented")
 }
 func (m *mockEvalCtx) GetLease() (roachpb.Lease, roachpb.Lease) {
-	panic("unimplemented")
+	return m.lease, roachpb.Lease{}
+}
+func (m *mockEvalCtx) GetClosedTimestamp() hlc.Timestamp {
+	return m.closedTS
 }
 
 func TestDeclareKeysResolveIntent(t *testing.T) {
@@ -0,0 +1,105 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+func init() {
+	RegisterCommand(roachpb.RevertRange, DefaultDeclareKeys, RevertRange)
+}
+
+// RevertRange wipes all MVCC versions more recent than TargetTime of the keys
+// covered by the specified span, adjusting the MVCC stats accordingly.
+//
+// Note that, as with ClearRange, "correct" use of this command is only
+// possible for key spans consisting of user data that we know is not being
+// written to or queried at timestamps above TargetTime, such as a table taken
+// offline by an IMPORT INTO.
+func RevertRange(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	if cArgs.Header.Txn != nil {
+		return result.Result{}, errors.New("cannot execute RevertRange within a transaction")
+	}
+	log.VEventf(ctx, 2, "RevertRange %+v", cArgs.Args)
+
+	args := cArgs.Args.(*roachpb.RevertRangeRequest)
+	from := engine.MVCCKey{Key: args.Key}
+	to := engine.MVCCKey{Key: args.EndKey}
+	nowNanos := cArgs.Header.Timestamp.WallTime
+
+	// The stats delta is computed by comparing the stats of the span before and
+	// after the revert, which the batch reflects as it reads its own writes.
+	iter := batch.NewIterator(engine.IterOptions{UpperBound: to.Key})
+	before, err := iter.ComputeStats(from, to, nowNanos)
+	iter.Close()
+	if err != nil {
+		return result.Result{}, err
+	}
+
+	var intents []roachpb.Intent
+	if err := batch.Iterate(
+		from, to,
+		func(kv engine.MVCCKeyValue) (bool, error) {
+			if kv.Key.IsValue() {
+				if args.TargetTime.Less(kv.Key.Timestamp) {
+					return false, batch.Clear(kv.Key)
+				}
+				return false, nil
+			}
+			// An intent's provisional value can't be cleared without also
+			// resolving the intent, so intents above the target time fail the
+			// request instead. Inline values have no history and are kept.
+			var meta enginepb.MVCCMetadata
+			if err := protoutil.Unmarshal(kv.Value, &meta); err != nil {
+				return false, err
+			}
+			if meta.Txn != nil && args.TargetTime.Less(hlc.Timestamp(meta.Timestamp)) {
+				intents = append(intents, roachpb.Intent{
+					Span:   roachpb.Span{Key: append(roachpb.Key(nil), kv.Key.Key...)},
+					Status: roachpb.PENDING,
+					Txn:    *meta.Txn,
+				})
+			}
+			return false, nil
+		},
+	); err != nil {
+		return result.Result{}, err
+	}
+	if len(intents) > 0 {
+		return result.Result{}, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	iter = batch.NewIterator(engine.IterOptions{UpperBound: to.Key})
+	after, err := iter.ComputeStats(from, to, nowNanos)
+	iter.Close()
+	if err != nil {
+		return result.Result{}, err
+	}
+	cArgs.Stats.Subtract(before)
+	cArgs.Stats.Add(after)
+	return result.Result{}, nil
+}
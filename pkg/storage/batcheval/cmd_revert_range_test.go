@@ -0,0 +1,132 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestCmdRevertRange verifies that RevertRange clears the revisions above the
+// target time, leaving the span and its stats as they were at that time.
+func TestCmdRevertRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	startKey := roachpb.Key("a")
+	endKey := roachpb.Key("z")
+	desc := roachpb.RangeDescriptor{
+		RangeID:  99,
+		StartKey: roachpb.RKey(startKey),
+		EndKey:   roachpb.RKey(endKey),
+	}
+	ts1, ts2, now := hlc.Timestamp{WallTime: 1}, hlc.Timestamp{WallTime: 2}, hlc.Timestamp{WallTime: 3}
+
+	put := func(eng engine.ReadWriter, ms *enginepb.MVCCStats, key string, ts hlc.Timestamp, v string) {
+		var value roachpb.Value
+		value.SetString(v)
+		if err := engine.MVCCPut(ctx, eng, ms, roachpb.Key(key), ts, value, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The state of the span as of ts1, which it is reverted to.
+	writeTS1 := func(eng engine.ReadWriter, ms *enginepb.MVCCStats) {
+		put(eng, ms, "a", ts1, "a1")
+		put(eng, ms, "b", ts1, "b1")
+	}
+
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer eng.Close()
+	var stats enginepb.MVCCStats
+	writeTS1(eng, &stats)
+	put(eng, &stats, "a", ts2, "a2")
+	put(eng, &stats, "c", ts2, "c2")
+	if err := engine.MVCCDelete(ctx, eng, &stats, roachpb.Key("b"), ts2, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	revert := func(eng engine.Engine, stats enginepb.MVCCStats) (enginepb.MVCCStats, error) {
+		batch := eng.NewBatch()
+		defer batch.Close()
+		cArgs := CommandArgs{Header: roachpb.Header{RangeID: desc.RangeID, Timestamp: now}}
+		cArgs.EvalCtx = &mockEvalCtx{desc: &desc, clock: hlc.NewClock(hlc.UnixNano, time.Nanosecond), stats: stats}
+		cArgs.Args = &roachpb.RevertRangeRequest{
+			RequestHeader: roachpb.RequestHeader{
+				Key:    startKey,
+				EndKey: endKey,
+			},
+			TargetTime: ts1,
+		}
+		cArgs.Stats = &enginepb.MVCCStats{}
+		if _, err := RevertRange(ctx, batch, cArgs, &roachpb.RevertRangeResponse{}); err != nil {
+			return enginepb.MVCCStats{}, err
+		}
+		return *cArgs.Stats, batch.Commit(true /* commit */)
+	}
+
+	delta, err := revert(eng, stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kvs, _, _, err := engine.MVCCScan(ctx, eng, startKey, endKey, math.MaxInt64, now, engine.MVCCScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, kv := range kvs {
+		v, err := kv.Value.GetBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(kv.Key)+"="+string(v))
+	}
+	if expected := []string{"a=a1", "b=b1"}; len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// The stats should match those of the span written only up to ts1.
+	expEng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer expEng.Close()
+	var expStats enginepb.MVCCStats
+	writeTS1(expEng, &expStats)
+	stats.Add(delta)
+	stats.AgeTo(now.WallTime)
+	expStats.AgeTo(now.WallTime)
+	if !stats.Equal(expStats) {
+		t.Errorf("expected stats %+v, got %+v", expStats, stats)
+	}
+
+	// Intents above the target time can't be reverted.
+	txn := roachpb.MakeTransaction("test", roachpb.Key("d"), roachpb.NormalUserPriority, ts2, 0)
+	var value roachpb.Value
+	value.SetString("d2")
+	if err := engine.MVCCPut(ctx, eng, &stats, roachpb.Key("d"), ts2, value, &txn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := revert(eng, stats); err == nil {
+		t.Fatal("expected an error reverting an intent")
+	} else if _, ok := err.(*roachpb.WriteIntentError); !ok {
+		t.Fatalf("expected WriteIntentError, got %v", err)
+	}
+}
@@ -57,6 +57,8 @@ type EvalContext interface {
 	GetLimiters() *Limiters
 
 	NodeID() roachpb.NodeID
+	NodeLocality() roachpb.Locality
+	GetNodeLocality(roachpb.NodeID) roachpb.Locality
 	StoreID() roachpb.StoreID
 	GetRangeID() roachpb.RangeID
 
@@ -84,4 +86,10 @@ type EvalContext interface {
 	GetTxnSpanGCThreshold() hlc.Timestamp
 	GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error)
 	GetLease() (roachpb.Lease, roachpb.Lease)
+
+	// GetClosedTimestamp returns the highest timestamp that this replica
+	// knows to be closed for its range, i.e. the timestamp at or below which
+	// no new writes will be accepted, according to the closed timestamp
+	// subsystem. Returns the zero timestamp if nothing is known to be closed.
+	GetClosedTimestamp() hlc.Timestamp
 }
@@ -0,0 +1,53 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package bulk
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// NewBytesLimiter returns a limiter of the rate at which a bulk operation
+// writes data to bytesPerSec, up to a second's worth of which may be written
+// at once. If bytesPerSec is not positive it returns nil, which WaitForBytes
+// never blocks on.
+func NewBytesLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+}
+
+// WaitForBytes blocks until limiter allows n more bytes to be written or ctx
+// is done. A nil limiter never blocks.
+func WaitForBytes(ctx context.Context, limiter *rate.Limiter, n int64) error {
+	if limiter == nil {
+		return nil
+	}
+	// The limiter refuses to wait for more than its burst at once.
+	burst := int64(limiter.Burst())
+	for n > 0 {
+		chunk := n
+		if chunk > burst {
+			chunk = burst
+		}
+		if err := limiter.WaitN(ctx, int(chunk)); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package bulk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestWaitForBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	if limiter := NewBytesLimiter(0); limiter != nil {
+		t.Fatalf("expected no limiter for 0 bytes/sec, got %v", limiter)
+	}
+	if err := WaitForBytes(ctx, nil, 1<<30); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first second's worth of bytes is written at once, and each of the
+	// rest must wait its turn, including those of a wait longer than the burst.
+	limiter := NewBytesLimiter(100)
+	begin := timeutil.Now()
+	if err := WaitForBytes(ctx, limiter, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := WaitForBytes(ctx, limiter, 150); err != nil {
+		t.Fatal(err)
+	}
+	if d := timeutil.Since(begin); d < time.Second {
+		t.Fatalf("expected writing 250 bytes at 100 bytes/sec to take over 1s, took %s", d)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := WaitForBytes(canceled, limiter, 100); err == nil {
+		t.Fatal("expected error waiting with a canceled context")
+	}
+}
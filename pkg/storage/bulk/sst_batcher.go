@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
 // FixedTimestampSSTBatcher is a wrapper for SSTBatcher that assigns a fixed
@@ -64,6 +65,8 @@ type SSTBatcher struct {
 	db *client.DB
 
 	maxSize int64
+	// limiter, if non-nil, limits the rate at which batches are sent.
+	limiter *rate.Limiter
 	// rows written in the current batch.
 	rowCounter RowCounter
 	totalRows  roachpb.BulkOpSummary
@@ -73,9 +76,12 @@ type SSTBatcher struct {
 	batchEndKey   []byte
 }
 
-// MakeSSTBatcher makes a ready-to-use SSTBatcher.
-func MakeSSTBatcher(ctx context.Context, db *client.DB, flushBytes int64) (*SSTBatcher, error) {
-	b := &SSTBatcher{db: db, maxSize: flushBytes}
+// MakeSSTBatcher makes a ready-to-use SSTBatcher. If bytesPerSec is positive,
+// batches are sent no faster than that.
+func MakeSSTBatcher(
+	ctx context.Context, db *client.DB, flushBytes int64, bytesPerSec int64,
+) (*SSTBatcher, error) {
+	b := &SSTBatcher{db: db, maxSize: flushBytes, limiter: NewBytesLimiter(bytesPerSec)}
 	err := b.reset()
 	return b, err
 }
@@ -141,7 +147,10 @@ func (b *SSTBatcher) Flush(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrapf(err, "finishing constructed sstable")
 	}
-	if err := AddSSTable(ctx, b.db, start, end, sstBytes); err != nil {
+	if err := WaitForBytes(ctx, b.limiter, int64(len(sstBytes))); err != nil {
+		return err
+	}
+	if err := AddSSTable(ctx, b.db, start, end, sstBytes, false /* disallowShadowing */); err != nil {
 		return err
 	}
 	b.totalRows.Add(b.rowCounter.BulkOpSummary)
@@ -161,20 +170,24 @@ func (b *SSTBatcher) GetSummary() roachpb.BulkOpSummary {
 
 // AddSSTable retries db.AddSSTable if retryable errors occur, including if the
 // SST spans a split, in which case it is iterated and split into two SSTs, one
-// for each side of the split in the error, and each are retried.
-func AddSSTable(ctx context.Context, db *client.DB, start, end roachpb.Key, sstBytes []byte) error {
+// for each side of the split in the error, and each are retried. If
+// disallowShadowing is set, the request fails if any key in the SST shadows an
+// existing live key.
+func AddSSTable(
+	ctx context.Context, db *client.DB, start, end roachpb.Key, sstBytes []byte, disallowShadowing bool,
+) error {
 	const maxAddSSTableRetries = 10
 	var err error
 	for i := 0; i < maxAddSSTableRetries; i++ {
 		log.VEventf(ctx, 2, "sending AddSSTable [%s,%s)", start, end)
 		// This will fail if the range has split but we'll check for that below.
-		err = db.AddSSTable(ctx, start, end, sstBytes)
+		err = db.AddSSTable(ctx, start, end, sstBytes, disallowShadowing)
 		if err == nil {
 			return nil
 		}
 		// This range has split -- we need to split the SST to try again.
 		if m, ok := errors.Cause(err).(*roachpb.RangeKeyMismatchError); ok {
-			return addSplitSSTable(ctx, db, sstBytes, start, m.MismatchedRange.EndKey.AsRawKey())
+			return addSplitSSTable(ctx, db, sstBytes, start, m.MismatchedRange.EndKey.AsRawKey(), disallowShadowing)
 		}
 		// Retry on AmbiguousResult.
 		if _, ok := err.(*roachpb.AmbiguousResultError); ok {
@@ -187,7 +200,11 @@ func AddSSTable(ctx context.Context, db *client.DB, start, end roachpb.Key, sstB
 
 // addSplitSSTable is a helper for splitting up and retrying AddSStable calls.
 func addSplitSSTable(
-	ctx context.Context, db *client.DB, sstBytes []byte, start, splitKey roachpb.Key,
+	ctx context.Context,
+	db *client.DB,
+	sstBytes []byte,
+	start, splitKey roachpb.Key,
+	disallowShadowing bool,
 ) error {
 	iter, err := engine.NewMemSSTIterator(sstBytes, false)
 	if err != nil {
@@ -219,7 +236,7 @@ func addSplitSSTable(
 			if err != nil {
 				return err
 			}
-			if err := AddSSTable(ctx, db, first, last.PrefixEnd(), res); err != nil {
+			if err := AddSSTable(ctx, db, first, last.PrefixEnd(), res, disallowShadowing); err != nil {
 				return err
 			}
 			w.Close()
@@ -249,5 +266,5 @@ func addSplitSSTable(
 	if err != nil {
 		return err
 	}
-	return AddSSTable(ctx, db, first, last.PrefixEnd(), res)
+	return AddSSTable(ctx, db, first, last.PrefixEnd(), res, disallowShadowing)
 }
@@ -28,6 +28,16 @@ var TargetDuration = settings.RegisterNonNegativeDurationSetting(
 	30*time.Second,
 )
 
+// FollowerReadsEnabled controls whether replicas attempt to serve follower
+// reads. The closed timestamp machinery is unaffected by this, i.e. the same
+// information is collected and passed around, regardless of the value of this
+// setting.
+var FollowerReadsEnabled = settings.RegisterBoolSetting(
+	"kv.closed_timestamp.follower_reads_enabled",
+	"allow (all) replicas to serve consistent historical reads based on closed timestamp information",
+	false,
+)
+
 // CloseFraction is the fraction of TargetDuration determining how often closed
 // timestamp updates are to be attempted.
 var CloseFraction = settings.RegisterValidatedFloatSetting(
@@ -274,6 +274,55 @@ func TestCheckConsistencyInconsistent(t *testing.T) {
 	}
 }
 
+// TestCheckConsistencyRepair verifies that a consistency check with repairs
+// enabled removes a replica that is inconsistent with the majority instead of
+// terminating the node.
+func TestCheckConsistencyRepair(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := storage.TestStoreConfig(nil)
+	storage.ConsistencyRepairEnabled.Override(&sc.Settings.SV, true)
+	sc.ConsistencyTestingKnobs.BadChecksumPanic = func(s roachpb.StoreIdent) {
+		t.Errorf("BadChecksumPanic called on %s despite repair", s)
+	}
+	mtc := &multiTestContext{storeConfig: &sc}
+	const numStores = 3
+	defer mtc.Stop()
+	mtc.Start(t, numStores)
+	// Setup replication of range 1 on store 0 to stores 1 and 2.
+	mtc.replicateRange(1, 1, 2)
+
+	pArgs := putArgs([]byte("a"), []byte("b"))
+	if _, err := client.SendWrapped(context.Background(), mtc.stores[0].TestSender(), pArgs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write some arbitrary data only to store 1, making it the minority.
+	var val roachpb.Value
+	val.SetInt(42)
+	if err := engine.MVCCPut(
+		context.Background(), mtc.stores[1].Engine(), nil, []byte("e"), mtc.stores[1].Clock().Now(), val, nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	checkArgs := roachpb.CheckConsistencyRequest{
+		RequestHeader: roachpb.RequestHeader{
+			Key:    []byte("a"),
+			EndKey: []byte("z"),
+		},
+	}
+	if _, err := client.SendWrapped(context.Background(), mtc.stores[0].TestSender(), &checkArgs); err != nil {
+		t.Fatal(err)
+	}
+
+	// The inconsistent replica must have been removed from the range.
+	desc := mtc.stores[0].LookupReplica(roachpb.RKey("a")).Desc()
+	if _, ok := desc.GetReplicaDescriptor(mtc.stores[1].StoreID()); ok {
+		t.Fatalf("expected inconsistent replica on s%d to be removed: %s", mtc.stores[1].StoreID(), desc)
+	}
+}
+
 // TestConsistencyQueueRecomputeStats is an end-to-end test of the mechanism CockroachDB
 // employs to adjust incorrect MVCCStats ("incorrect" meaning not an inconsistency of
 // these stats between replicas, but a delta between persisted stats and those one
@@ -2250,7 +2250,10 @@ func mvccResolveWriteIntent(
 	// used for resolving), but that costs latency.
 	// TODO(tschottdorf): various epoch-related scenarios here deserve more
 	// testing.
-	pushed := intent.Status == roachpb.PENDING &&
+	//
+	// A STAGING intent status is treated like PENDING: the transaction has not
+	// been finalized yet, so its intents may only be moved forward in time.
+	pushed := !intent.Status.IsFinalized() &&
 		hlc.Timestamp(meta.Timestamp).Less(intent.Txn.Timestamp) &&
 		meta.Txn.Epoch >= intent.Txn.Epoch
 
@@ -2350,8 +2353,8 @@ func mvccResolveWriteIntent(
 	// - ResolveIntent with epoch 0 aborts intent from epoch 1.
 
 	// There's nothing to do if meta's epoch is greater than or equal txn's epoch
-	// and the state is still PENDING.
-	if intent.Status == roachpb.PENDING && meta.Txn.Epoch >= intent.Txn.Epoch {
+	// and the state is still PENDING or STAGING.
+	if !intent.Status.IsFinalized() && meta.Txn.Epoch >= intent.Txn.Epoch {
 		return false, nil
 	}
 
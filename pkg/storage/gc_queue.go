@@ -365,7 +365,7 @@ func processLocalKeyRange(
 	handleTxnIntents := func(key roachpb.Key, txn *roachpb.Transaction) error {
 		// If the transaction needs to be pushed or there are intents to
 		// resolve, invoke the cleanup function.
-		if txn.Status == roachpb.PENDING || len(txn.Intents) > 0 {
+		if !txn.Status.IsFinalized() || len(txn.Intents) > 0 {
 			return cleanupTxnIntentsAsyncFn(ctx, txn, roachpb.AsIntents(txn.Intents, txn))
 		}
 		gcKeys = append(gcKeys, roachpb.GCRequest_GCKey{Key: key}) // zero timestamp
@@ -384,7 +384,7 @@ func processLocalKeyRange(
 
 		// The transaction record should be considered for removal.
 		switch txn.Status {
-		case roachpb.PENDING:
+		case roachpb.PENDING, roachpb.STAGING:
 			infoMu.TransactionSpanGCPending++
 		case roachpb.ABORTED:
 			infoMu.TransactionSpanGCAborted++
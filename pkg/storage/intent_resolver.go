@@ -814,11 +814,11 @@ func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 			}
 			defer release()
 
-			// If the transaction is still pending, but expired, push it
-			// before resolving the intents.
-			if txn.Status == roachpb.PENDING {
+			// If the transaction is still pending (or staging), but expired, push
+			// it before resolving the intents.
+			if !txn.Status.IsFinalized() {
 				if !txnwait.IsExpired(now, txn) {
-					log.VErrEventf(ctx, 3, "cannot push a PENDING transaction which is not expired: %s", txn)
+					log.VErrEventf(ctx, 3, "cannot push a %s transaction which is not expired: %s", txn.Status, txn)
 					return
 				}
 				b := &client.Batch{}
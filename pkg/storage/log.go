@@ -161,7 +161,7 @@ func (s *Store) logChange(
 	var logType storagepb.RangeLogEventType
 	var info storagepb.RangeLogEvent_Info
 	switch changeType {
-	case roachpb.ADD_REPLICA:
+	case roachpb.ADD_REPLICA, roachpb.ADD_NON_VOTER, roachpb.PROMOTE_NON_VOTER:
 		logType = storagepb.RangeLogEventType_add
 		info = storagepb.RangeLogEvent_Info{
 			AddedReplica: &replica,
@@ -190,6 +190,80 @@ func (s *Store) logChange(
 	})
 }
 
+// eventLogReplicaInconsistency is the system.eventlog event type recorded
+// when a consistency check finds a replica to be inconsistent with the
+// majority of its range. It is defined here rather than alongside the
+// sql.EventLogType constants because the sql package can't be used here.
+const eventLogReplicaInconsistency = "replica_inconsistency"
+
+// eventLogReplicaInconsistencyDetail is the json details for a replica
+// inconsistency event. The diff itself is only recorded in the range log.
+type eventLogReplicaInconsistencyDetail struct {
+	RangeID roachpb.RangeID
+	Replica string
+}
+
+// logInconsistency logs a replica inconsistency event, which records the diff
+// between a replica and the majority of its range found by a consistency
+// check. The event is also recorded in the cluster's event log, which
+// operators are more likely to look at than the range log.
+//
+// Unlike other range events, inconsistencies are recorded even if
+// LogRangeEvents is disabled, since they indicate data loss on a replica
+// that operators must be able to find out about.
+func (s *Store) logInconsistency(
+	ctx context.Context,
+	txn *client.Txn,
+	replica roachpb.ReplicaDescriptor,
+	desc roachpb.RangeDescriptor,
+	diff string,
+) error {
+	if s.cfg.SQLExecutor == nil {
+		// Stores without SQL (in tests) have nowhere to record the event.
+		return nil
+	}
+	if err := s.insertRangeLogEvent(ctx, txn, storagepb.RangeLogEvent{
+		Timestamp: selectEventTimestamp(s, txn.OrigTimestamp()),
+		RangeID:   desc.RangeID,
+		EventType: storagepb.RangeLogEventType_inconsistency,
+		StoreID:   s.StoreID(),
+		Info: &storagepb.RangeLogEvent_Info{
+			InconsistentReplica: &replica,
+			UpdatedDesc:         &desc,
+			Reason:              storagepb.ReasonReplicaInconsistent,
+			Details:             diff,
+		},
+	}); err != nil {
+		return err
+	}
+
+	const insertEventTableStmt = `
+	INSERT INTO system.eventlog (
+		timestamp, "eventType", "targetID", "reportingID", info
+	)
+	VALUES(
+		$1, $2, $3, $4, $5
+	)
+	`
+	info, err := json.Marshal(eventLogReplicaInconsistencyDetail{
+		RangeID: desc.RangeID,
+		Replica: replica.String(),
+	})
+	if err != nil {
+		return err
+	}
+	rows, err := s.cfg.SQLExecutor.Exec(ctx, "log-event", txn, insertEventTableStmt,
+		selectEventTimestamp(s, txn.OrigTimestamp()), eventLogReplicaInconsistency,
+		int32(replica.NodeID), int32(s.Ident.NodeID), string(info))
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.Errorf("%d rows affected by log insertion; expected exactly one row affected.", rows)
+	}
+	return nil
+}
+
 // selectEventTimestamp selects a timestamp for this log message. If the
 // transaction this event is being written in has a non-zero timestamp, then that
 // timestamp should be used; otherwise, the store's physical clock is used.
@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/ctpb"
 	ctstorage "github.com/cockroachdb/cockroach/pkg/storage/closedts/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
@@ -118,16 +119,6 @@ var MaxCommandSize = settings.RegisterValidatedByteSizeSetting(
 	},
 )
 
-// FollowerReadsEnabled controls whether replicas attempt to serve follower
-// reads. The closed timestamp machinery is unaffected by this, i.e. the same
-// information is collected and passed around, regardless of the value of this
-// setting.
-var FollowerReadsEnabled = settings.RegisterBoolSetting(
-	"kv.closed_timestamp.follower_reads_enabled",
-	"allow (all) replicas to serve consistent historical reads based on closed timestamp information",
-	false,
-)
-
 type proposalRetryReason int
 
 const (
@@ -1347,6 +1338,19 @@ func (r *Replica) GetLease() (roachpb.Lease, roachpb.Lease) {
 	return r.getLeaseRLocked()
 }
 
+// GetClosedTimestamp returns the maximum closed timestamp known to the
+// replica for its range, based on the current lease and lease applied index.
+func (r *Replica) GetClosedTimestamp() hlc.Timestamp {
+	r.mu.RLock()
+	lai := r.mu.state.LeaseAppliedIndex
+	lease := *r.mu.state.Lease
+	r.mu.RUnlock()
+
+	return r.store.cfg.ClosedTimestamp.Provider.MaxClosed(
+		lease.Replica.NodeID, r.RangeID, ctpb.Epoch(lease.Epoch), ctpb.LAI(lai),
+	)
+}
+
 func (r *Replica) getLeaseRLocked() (roachpb.Lease, roachpb.Lease) {
 	if nextLease, ok := r.mu.pendingLeaseRequest.RequestPending(); ok {
 		return *r.mu.state.Lease, nextLease
@@ -1701,6 +1705,21 @@ func (r *Replica) NodeID() roachpb.NodeID {
 	return r.store.nodeDesc.NodeID
 }
 
+// NodeLocality returns the locality of the node this replica belongs to.
+func (r *Replica) NodeLocality() roachpb.Locality {
+	return r.store.nodeDesc.Locality
+}
+
+// GetNodeLocality returns the locality of the given node as last gossiped, or
+// an empty locality if it is not known.
+func (r *Replica) GetNodeLocality(nodeID roachpb.NodeID) roachpb.Locality {
+	if nodeID == r.NodeID() {
+		return r.NodeLocality()
+	}
+	replicas := []roachpb.ReplicaDescriptor{{NodeID: nodeID}}
+	return r.store.cfg.StorePool.getLocalities(replicas)[nodeID]
+}
+
 // ClusterSettings returns the node's ClusterSettings.
 func (r *Replica) ClusterSettings() *cluster.Settings {
 	return r.store.cfg.Settings
@@ -2945,7 +2964,7 @@ func (r *Replica) executeReadOnlyBatch(
 	if ba.ReadConsistency.RequiresReadLease() {
 		if status, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
 			if lErr, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); ok &&
-				FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV) &&
+				closedts.FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV) &&
 				lErr.LeaseHolder != nil && lErr.Lease.Type() == roachpb.LeaseEpoch {
 
 				r.mu.RLock()
@@ -831,8 +831,14 @@ func (r *Replica) changeReplicas(
 	updatedDesc := *desc
 	updatedDesc.Replicas = append([]roachpb.ReplicaDescriptor(nil), desc.Replicas...)
 
+	if changeType == roachpb.ADD_NON_VOTER || changeType == roachpb.PROMOTE_NON_VOTER {
+		if !r.ClusterSettings().Version.IsMinSupported(cluster.VersionNonVotingReplicas) {
+			return errors.Errorf("%s: cluster version does not support non-voting replicas", r)
+		}
+	}
+
 	switch changeType {
-	case roachpb.ADD_REPLICA:
+	case roachpb.ADD_REPLICA, roachpb.ADD_NON_VOTER:
 		// If the replica exists on the remote node, no matter in which store,
 		// abort the replica add.
 		if nodeUsed {
@@ -869,9 +875,24 @@ func (r *Replica) changeReplicas(
 		}
 
 		repDesc.ReplicaID = updatedDesc.NextReplicaID
+		if changeType == roachpb.ADD_NON_VOTER {
+			typ := roachpb.NON_VOTER
+			repDesc.Type = &typ
+		}
 		updatedDesc.NextReplicaID++
 		updatedDesc.Replicas = append(updatedDesc.Replicas, repDesc)
 
+	case roachpb.PROMOTE_NON_VOTER:
+		// Only an existing non-voting replica can be promoted. Raft turns the
+		// learner into a voter in place, so no snapshot is needed.
+		if repDescIdx == -1 {
+			return errors.Errorf("%s: unable to promote replica %v which is not present", r, repDesc)
+		}
+		if updatedDesc.Replicas[repDescIdx].IsVoter() {
+			return errors.Errorf("%s: unable to promote replica %v which is already a voter", r, repDesc)
+		}
+		updatedDesc.Replicas[repDescIdx].Type = nil
+
 	case roachpb.REMOVE_REPLICA:
 		// If that exact node-store combination does not have the replica,
 		// abort the removal.
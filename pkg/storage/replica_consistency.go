@@ -57,7 +57,9 @@ const (
 // ComputeChecksum through Raft and then issues CollectChecksum commands to the
 // other replicas. When an inconsistency is detected and no diff was requested,
 // the consistency check will be re-run to collect a diff, which is then printed
-// before calling `log.Fatal`.
+// before calling `log.Fatal`. If the server.consistency_check.repair.enabled
+// setting is set, the inconsistent replicas are instead replaced when a
+// majority of replicas agree on a checksum (see repairInconsistency).
 func (r *Replica) CheckConsistency(
 	ctx context.Context, args roachpb.CheckConsistencyRequest,
 ) (roachpb.CheckConsistencyResponse, *roachpb.Error) {
@@ -136,11 +138,31 @@ func (r *Replica) CheckConsistency(
 		return roachpb.CheckConsistencyResponse{}, roachpb.NewError(err)
 	}
 
+	// If repairs are enabled, try to remove the inconsistent replicas instead
+	// of terminating the node. This requires diffs, which are only available
+	// on the recursive call below.
+	repairEnabled := ConsistencyRepairEnabled.Get(&r.ClusterSettings().SV)
+	if repairEnabled && args.WithDiff {
+		delegated, err := r.repairInconsistency(ctx, results)
+		if err == nil {
+			if delegated {
+				log.Warningf(ctx, "delegated repair of consistency check failure with %d inconsistent "+
+					"replicas to the new leaseholder", inconsistencyCount)
+			} else {
+				log.Warningf(ctx, "repaired consistency check failure with %d inconsistent replicas",
+					inconsistencyCount)
+			}
+			return roachpb.CheckConsistencyResponse{}, nil
+		}
+		log.Errorf(ctx, "unable to repair replica inconsistency: %s", err)
+	}
+
 	logFunc := log.Fatalf
 	if p := r.store.cfg.ConsistencyTestingKnobs.BadChecksumPanic; p != nil {
-		if !args.WithDiff {
-			// We'll call this recursively with WithDiff==true; let's let that call
-			// be the one to trigger the handler.
+		// Trigger the handler on the call that would otherwise terminate the
+		// node: the recursive call with WithDiff==true if it attempted a repair
+		// and this call otherwise.
+		if args.WithDiff == repairEnabled {
 			p(*r.store.Ident)
 		}
 		logFunc = log.Errorf
@@ -0,0 +1,169 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// ConsistencyRepairEnabled controls whether consistency checks repair ranges
+// with inconsistent replicas instead of terminating the node.
+var ConsistencyRepairEnabled = settings.RegisterBoolSetting(
+	"server.consistency_check.repair.enabled",
+	"if enabled, replicas that are inconsistent with the majority of their range are "+
+		"removed and replaced instead of terminating the node",
+	false,
+)
+
+// maxRecordedDiffSize bounds the size of the diff that is recorded in the
+// range log for an inconsistent replica.
+const maxRecordedDiffSize = 64 << 10 // 64 KiB
+
+// findConsistentMajority partitions the results of a consistency check into
+// the replicas that agree on the checksum computed by a strict majority of the
+// range's voting replicas and the replicas whose checksum differs from it.
+// Non-voting replicas don't count towards the majority, but are partitioned
+// like the others. Replicas whose checksum could not be collected belong to
+// neither group. Returns false if no checksum was computed by a majority of
+// the voters.
+func findConsistentMajority(
+	results []ConsistencyCheckResult, numVoters int,
+) (majority, minority []ConsistencyCheckResult, ok bool) {
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		if result.Err == nil && result.Replica.IsVoter() {
+			counts[string(result.Response.Checksum)]++
+		}
+	}
+	var majorityChecksum []byte
+	for checksum, count := range counts {
+		if count > numVoters/2 {
+			majorityChecksum = []byte(checksum)
+			ok = true
+		}
+	}
+	if !ok {
+		return nil, nil, false
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		if bytes.Equal(result.Response.Checksum, majorityChecksum) {
+			majority = append(majority, result)
+		} else {
+			minority = append(minority, result)
+		}
+	}
+	return majority, minority, true
+}
+
+// repairInconsistency attempts to repair the range after a consistency check
+// (run with diffs) found some of its replicas to be inconsistent. The majority
+// of replicas that agree on a checksum is considered authoritative. Each
+// replica in the minority has its diff against the majority recorded in the
+// range log and is then quarantined by removing it from the range through a
+// replica change, which prevents it from serving reads or taking part in
+// quorum. Finally, the range is handed to the replicate queue, which
+// up-replicates it from the majority.
+//
+// The repair must be driven by a leaseholder in the majority. If the local
+// replica is in the minority, the lease is transferred to a majority replica,
+// which is then asked to check the range again (and repair it). In that case
+// delegated is returned as true: the range has not been repaired yet, and the
+// outcome is reported by the new leaseholder's consistency check.
+//
+// An error is returned if no majority could be established or the repair
+// could not be carried out, in which case the inconsistency must be handled
+// as if repairs were disabled.
+func (r *Replica) repairInconsistency(
+	ctx context.Context, results []ConsistencyCheckResult,
+) (delegated bool, _ error) {
+	desc := r.Desc()
+	numVoters := len(desc.Voters())
+	majority, minority, ok := findConsistentMajority(results, numVoters)
+	if !ok {
+		return false, errors.Errorf("no majority of the %d voting replicas agree on a checksum", numVoters)
+	}
+
+	if !bytes.Equal(results[0].Response.Checksum, majority[0].Response.Checksum) {
+		// The majority contains at least one voter, which can take the lease.
+		var target roachpb.ReplicaDescriptor
+		for _, result := range majority {
+			if result.Replica.IsVoter() {
+				target = result.Replica
+				break
+			}
+		}
+		log.Warningf(ctx, "local replica is inconsistent with the majority; transferring lease to %s", target)
+		if err := r.AdminTransferLease(ctx, target.StoreID); err != nil {
+			return false, errors.Wrapf(err, "transferring lease to %s", target)
+		}
+		// Ask the new leaseholder to check the range. Its consistency check
+		// will find the local replica to be in the minority and remove it.
+		span := roachpb.RequestHeader{Key: desc.StartKey.AsRawKey(), EndKey: desc.EndKey.AsRawKey()}
+		return true, r.store.Stopper().RunAsyncTask(
+			r.AnnotateCtx(context.Background()), "storage.Replica: re-checking consistency",
+			func(ctx context.Context) {
+				var b client.Batch
+				b.AddRawRequest(&roachpb.CheckConsistencyRequest{RequestHeader: span})
+				if err := r.store.DB().Run(ctx, &b); err != nil {
+					log.Warningf(ctx, "consistency check after lease transfer failed: %s", err)
+				}
+			})
+	}
+
+	expSnapshot := results[0].Response.Snapshot
+	for _, result := range minority {
+		var buf bytes.Buffer
+		if expSnapshot != nil && result.Response.Snapshot != nil {
+			_, _ = diffRange(expSnapshot, result.Response.Snapshot).WriteTo(&buf)
+		}
+		if buf.Len() > maxRecordedDiffSize {
+			buf.Truncate(maxRecordedDiffSize)
+			buf.WriteString("\n(truncated)")
+		}
+		desc = r.Desc()
+		if err := r.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return r.store.logInconsistency(ctx, txn, result.Replica, *desc, buf.String())
+		}); err != nil {
+			log.Warningf(ctx, "unable to record inconsistency of %s: %s", result.Replica, err)
+		}
+
+		log.Warningf(ctx, "removing inconsistent replica %s", result.Replica)
+		target := roachpb.ReplicationTarget{
+			NodeID:  result.Replica.NodeID,
+			StoreID: result.Replica.StoreID,
+		}
+		if err := r.ChangeReplicas(
+			ctx, roachpb.REMOVE_REPLICA, target, desc, storagepb.ReasonReplicaInconsistent, "",
+		); err != nil {
+			return false, errors.Wrapf(err, "removing inconsistent replica %s", result.Replica)
+		}
+	}
+
+	if r.store.replicateQueue != nil {
+		r.store.replicateQueue.MaybeAdd(r, r.store.Clock().Now())
+	}
+	return false, nil
+}
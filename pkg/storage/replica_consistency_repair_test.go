@@ -0,0 +1,127 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestFindConsistentMajority(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	result := func(storeID roachpb.StoreID, checksum string) ConsistencyCheckResult {
+		return ConsistencyCheckResult{
+			Replica:  roachpb.ReplicaDescriptor{StoreID: storeID},
+			Response: CollectChecksumResponse{Checksum: []byte(checksum)},
+		}
+	}
+	nonVoter := func(storeID roachpb.StoreID, checksum string) ConsistencyCheckResult {
+		res := result(storeID, checksum)
+		typ := roachpb.NON_VOTER
+		res.Replica.Type = &typ
+		return res
+	}
+	failed := func(storeID roachpb.StoreID) ConsistencyCheckResult {
+		res := result(storeID, "")
+		res.Err = errors.New("boom")
+		return res
+	}
+	stores := func(results []ConsistencyCheckResult) []roachpb.StoreID {
+		var ids []roachpb.StoreID
+		for _, res := range results {
+			ids = append(ids, res.Replica.StoreID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name        string
+		results     []ConsistencyCheckResult
+		numVoters   int
+		expOK       bool
+		expMajority []roachpb.StoreID
+		expMinority []roachpb.StoreID
+	}{
+		{
+			name:        "follower inconsistent",
+			results:     []ConsistencyCheckResult{result(1, "a"), result(2, "b"), result(3, "a")},
+			numVoters:   3,
+			expOK:       true,
+			expMajority: []roachpb.StoreID{1, 3},
+			expMinority: []roachpb.StoreID{2},
+		},
+		{
+			name:        "leaseholder inconsistent",
+			results:     []ConsistencyCheckResult{result(1, "a"), result(2, "b"), result(3, "b")},
+			numVoters:   3,
+			expOK:       true,
+			expMajority: []roachpb.StoreID{2, 3},
+			expMinority: []roachpb.StoreID{1},
+		},
+		{
+			name:        "failed checksum collection",
+			results:     []ConsistencyCheckResult{result(1, "a"), failed(2), result(3, "a")},
+			numVoters:   3,
+			expOK:       true,
+			expMajority: []roachpb.StoreID{1, 3},
+		},
+		{
+			name:      "no majority",
+			results:   []ConsistencyCheckResult{result(1, "a"), failed(2), result(3, "b")},
+			numVoters: 3,
+		},
+		{
+			name:      "even split",
+			results:   []ConsistencyCheckResult{result(1, "a"), result(2, "a"), result(3, "b"), result(4, "b")},
+			numVoters: 4,
+		},
+		{
+			name: "non-voters don't count",
+			results: []ConsistencyCheckResult{
+				result(1, "a"), failed(2), result(3, "b"), nonVoter(4, "a"), nonVoter(5, "b"),
+			},
+			numVoters: 3,
+		},
+		{
+			name: "inconsistent non-voter",
+			results: []ConsistencyCheckResult{
+				result(1, "a"), result(2, "a"), result(3, "a"), nonVoter(4, "b"), nonVoter(5, "a"),
+			},
+			numVoters:   3,
+			expOK:       true,
+			expMajority: []roachpb.StoreID{1, 2, 3, 5},
+			expMinority: []roachpb.StoreID{4},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			majority, minority, ok := findConsistentMajority(c.results, c.numVoters)
+			if ok != c.expOK {
+				t.Fatalf("expected ok=%t, got %t", c.expOK, ok)
+			}
+			if a, e := stores(majority), c.expMajority; !reflect.DeepEqual(a, e) {
+				t.Errorf("expected majority %v, got %v", e, a)
+			}
+			if a, e := stores(minority), c.expMinority; !reflect.DeepEqual(a, e) {
+				t.Errorf("expected minority %v, got %v", e, a)
+			}
+		})
+	}
+}
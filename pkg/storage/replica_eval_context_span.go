@@ -87,6 +87,16 @@ func (rec *SpanSetReplicaEvalContext) NodeID() roachpb.NodeID {
 	return rec.i.NodeID()
 }
 
+// NodeLocality returns the node locality.
+func (rec *SpanSetReplicaEvalContext) NodeLocality() roachpb.Locality {
+	return rec.i.NodeLocality()
+}
+
+// GetNodeLocality returns the locality of the given node.
+func (rec *SpanSetReplicaEvalContext) GetNodeLocality(nodeID roachpb.NodeID) roachpb.Locality {
+	return rec.i.GetNodeLocality(nodeID)
+}
+
 // Tracer returns the tracer.
 func (rec *SpanSetReplicaEvalContext) Tracer() opentracing.Tracer {
 	return rec.i.Tracer()
@@ -193,6 +203,12 @@ func (rec SpanSetReplicaEvalContext) GetLease() (roachpb.Lease, roachpb.Lease) {
 	return rec.i.GetLease()
 }
 
+// GetClosedTimestamp returns the maximum closed timestamp known to the
+// replica.
+func (rec SpanSetReplicaEvalContext) GetClosedTimestamp() hlc.Timestamp {
+	return rec.i.GetClosedTimestamp()
+}
+
 // GetLimiters returns the per-store limiters.
 func (rec *SpanSetReplicaEvalContext) GetLimiters() *batcheval.Limiters {
 	return rec.i.GetLimiters()
@@ -69,12 +69,21 @@ func (r *replicaRaftStorage) InitialState() (raftpb.HardState, raftpb.ConfState,
 	if raft.IsEmptyHardState(hs) || err != nil {
 		return raftpb.HardState{}, raftpb.ConfState{}, err
 	}
+	return hs, confStateFromDesc(r.mu.state.Desc), nil
+}
+
+// confStateFromDesc synthesizes a raftpb.ConfState from the replicas in the
+// given descriptor. Non-voting replicas are raft learners.
+func confStateFromDesc(desc *roachpb.RangeDescriptor) raftpb.ConfState {
 	var cs raftpb.ConfState
-	for _, rep := range r.mu.state.Desc.Replicas {
-		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
+	for _, rep := range desc.Replicas {
+		if rep.IsVoter() {
+			cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
+		} else {
+			cs.Learners = append(cs.Learners, uint64(rep.ReplicaID))
+		}
 	}
-
-	return hs, cs, nil
+	return cs
 }
 
 // Entries implements the raft.Storage interface. Note that maxBytes is advisory
@@ -530,10 +539,7 @@ func snapshot(
 	}
 
 	// Synthesize our raftpb.ConfState from desc.
-	var cs raftpb.ConfState
-	for _, rep := range desc.Replicas {
-		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
-	}
+	cs := confStateFromDesc(&desc)
 
 	term, err := term(ctx, rsl, snap, rangeID, eCache, appliedIndex)
 	if err != nil {
@@ -549,6 +549,12 @@ func (r *Replica) requestLeaseLocked(
 		return r.mu.pendingLeaseRequest.newResolvedHandle(roachpb.NewError(
 			newNotLeaseHolderError(nil, r.store.StoreID(), r.mu.state.Desc)))
 	}
+	if !repDesc.IsVoter() {
+		// Non-voting replicas can't hold the lease. Send the client elsewhere
+		// rather than proposing a request that would be rejected.
+		return r.mu.pendingLeaseRequest.newResolvedHandle(roachpb.NewError(
+			newNotLeaseHolderError(nil, r.store.StoreID(), r.mu.state.Desc)))
+	}
 	return r.mu.pendingLeaseRequest.InitOrJoinRequest(
 		ctx, repDesc, status, r.mu.state.Desc.StartKey.AsRawKey(), false /* transfer */)
 }
@@ -594,6 +600,10 @@ func (r *Replica) AdminTransferLease(ctx context.Context, target roachpb.StoreID
 		if nextLeaseHolder, ok = desc.GetReplicaDescriptor(target); !ok {
 			return nil, nil, errors.Errorf("unable to find store %d in range %+v", target, desc)
 		}
+		if !nextLeaseHolder.IsVoter() {
+			return nil, nil, errors.Errorf("cannot transfer lease to non-voting replica %s of range %+v",
+				nextLeaseHolder, desc)
+		}
 
 		if nextLease, ok := r.mu.pendingLeaseRequest.RequestPending(); ok &&
 			nextLease.Replica != nextLeaseHolder {
@@ -72,6 +72,30 @@ var (
 		Measurement: "Replica Removals",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaReplicateQueuePromoteNonVoterCount = metric.Metadata{
+		Name:        "queue.replicate.promotenonvoter",
+		Help:        "Number of non-voting replica promotions attempted by the replicate queue",
+		Measurement: "Replica Promotions",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaReplicateQueueAddNonVoterCount = metric.Metadata{
+		Name:        "queue.replicate.addnonvoter",
+		Help:        "Number of non-voting replica additions attempted by the replicate queue",
+		Measurement: "Replica Additions",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaReplicateQueueRemoveNonVoterCount = metric.Metadata{
+		Name:        "queue.replicate.removenonvoter",
+		Help:        "Number of non-voting replica removals attempted by the replicate queue",
+		Measurement: "Replica Removals",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaReplicateQueueRemoveDeadNonVoterCount = metric.Metadata{
+		Name:        "queue.replicate.removedeadnonvoter",
+		Help:        "Number of dead non-voting replica removals attempted by the replicate queue",
+		Measurement: "Replica Removals",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaReplicateQueueRebalanceReplicaCount = metric.Metadata{
 		Name:        "queue.replicate.rebalancereplica",
 		Help:        "Number of replica rebalancer-initiated additions attempted by the replicate queue",
@@ -107,20 +131,28 @@ func (*quorumError) purgatoryErrorMarker() {}
 
 // ReplicateQueueMetrics is the set of metrics for the replicate queue.
 type ReplicateQueueMetrics struct {
-	AddReplicaCount        *metric.Counter
-	RemoveReplicaCount     *metric.Counter
-	RemoveDeadReplicaCount *metric.Counter
-	RebalanceReplicaCount  *metric.Counter
-	TransferLeaseCount     *metric.Counter
+	AddReplicaCount         *metric.Counter
+	RemoveReplicaCount      *metric.Counter
+	RemoveDeadReplicaCount  *metric.Counter
+	PromoteNonVoterCount    *metric.Counter
+	AddNonVoterCount        *metric.Counter
+	RemoveNonVoterCount     *metric.Counter
+	RemoveDeadNonVoterCount *metric.Counter
+	RebalanceReplicaCount   *metric.Counter
+	TransferLeaseCount      *metric.Counter
 }
 
 func makeReplicateQueueMetrics() ReplicateQueueMetrics {
 	return ReplicateQueueMetrics{
-		AddReplicaCount:        metric.NewCounter(metaReplicateQueueAddReplicaCount),
-		RemoveReplicaCount:     metric.NewCounter(metaReplicateQueueRemoveReplicaCount),
-		RemoveDeadReplicaCount: metric.NewCounter(metaReplicateQueueRemoveDeadReplicaCount),
-		RebalanceReplicaCount:  metric.NewCounter(metaReplicateQueueRebalanceReplicaCount),
-		TransferLeaseCount:     metric.NewCounter(metaReplicateQueueTransferLeaseCount),
+		AddReplicaCount:         metric.NewCounter(metaReplicateQueueAddReplicaCount),
+		RemoveReplicaCount:      metric.NewCounter(metaReplicateQueueRemoveReplicaCount),
+		RemoveDeadReplicaCount:  metric.NewCounter(metaReplicateQueueRemoveDeadReplicaCount),
+		PromoteNonVoterCount:    metric.NewCounter(metaReplicateQueuePromoteNonVoterCount),
+		AddNonVoterCount:        metric.NewCounter(metaReplicateQueueAddNonVoterCount),
+		RemoveNonVoterCount:     metric.NewCounter(metaReplicateQueueRemoveNonVoterCount),
+		RemoveDeadNonVoterCount: metric.NewCounter(metaReplicateQueueRemoveDeadNonVoterCount),
+		RebalanceReplicaCount:   metric.NewCounter(metaReplicateQueueRebalanceReplicaCount),
+		TransferLeaseCount:      metric.NewCounter(metaReplicateQueueTransferLeaseCount),
 	}
 }
 
@@ -230,7 +262,7 @@ func (rq *replicateQueue) shouldQueue(
 	if lease, _ := repl.GetLease(); repl.IsLeaseValid(lease, now) {
 		if rq.canTransferLease() &&
 			rq.allocator.ShouldTransferLease(
-				ctx, zone, desc.Replicas, lease.Replica.StoreID, desc.RangeID, repl.leaseholderStats) {
+				ctx, zone, desc.Voters(), lease.Replica.StoreID, desc.RangeID, repl.leaseholderStats) {
 			log.VEventf(ctx, 2, "lease transfer needed, enqueuing")
 			return true, 0
 		}
@@ -289,11 +321,13 @@ func (rq *replicateQueue) processOneChange(
 	desc, zone := repl.DescAndZone()
 
 	// Avoid taking action if the range has too many dead replicas to make
-	// quorum.
-	liveReplicas, deadReplicas := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, desc.Replicas)
+	// quorum. Only voting replicas count towards quorum.
+	voters := desc.Voters()
+	liveReplicas, _ := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, desc.Replicas)
+	liveVoters, deadVoters := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, voters)
 	{
-		quorum := computeQuorum(len(desc.Replicas))
-		if lr := len(liveReplicas); lr < quorum {
+		quorum := computeQuorum(len(voters))
+		if lr := len(liveVoters); lr < quorum {
 			return false, newQuorumError(
 				"range requires a replication change, but lacks a quorum of live replicas (%d/%d)", lr, quorum)
 		}
@@ -307,7 +341,7 @@ func (rq *replicateQueue) processOneChange(
 		log.VEventf(ctx, 1, "adding a new replica")
 		newStore, details, err := rq.allocator.AllocateTarget(
 			ctx,
-			zone,
+			voterZone(zone),
 			liveReplicas, // only include liveReplicas, since deadReplicas should soon be removed
 			rangeInfo,
 		)
@@ -320,8 +354,8 @@ func (rq *replicateQueue) processOneChange(
 		}
 
 		availableNodes := rq.allocator.storePool.AvailableNodeCount()
-		need := GetNeededReplicas(*zone.NumReplicas, availableNodes)
-		willHave := len(desc.Replicas) + 1
+		need := GetNeededReplicas(zone.GetNumVoters(), availableNodes)
+		willHave := len(voters) + 1
 
 		// Only up-replicate if there are suitable allocation targets such
 		// that, either the replication goal is met, or it is possible to get to the
@@ -342,7 +376,7 @@ func (rq *replicateQueue) processOneChange(
 			})
 			_, _, err := rq.allocator.AllocateTarget(
 				ctx,
-				zone,
+				voterZone(zone),
 				oldPlusNewReplicas,
 				rangeInfo,
 			)
@@ -359,6 +393,7 @@ func (rq *replicateQueue) processOneChange(
 		if err := rq.addReplica(
 			ctx,
 			repl,
+			roachpb.ADD_REPLICA,
 			newReplica,
 			desc,
 			SnapshotRequest_RECOVERY,
@@ -368,22 +403,107 @@ func (rq *replicateQueue) processOneChange(
 		); err != nil {
 			return false, err
 		}
+	case AllocatorPromoteNonVoter:
+		log.VEventf(ctx, 1, "promoting a non-voting replica")
+		promoteReplica, details, err := rq.allocator.PromoteTarget(ctx, zone, rangeInfo)
+		if err != nil {
+			return false, err
+		}
+		rq.metrics.PromoteNonVoterCount.Inc(1)
+		log.VEventf(ctx, 1, "promoting non-voting replica %+v due to under-replication: %s",
+			promoteReplica, rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
+		if !dryRun {
+			target := roachpb.ReplicationTarget{
+				NodeID:  promoteReplica.NodeID,
+				StoreID: promoteReplica.StoreID,
+			}
+			if err := repl.changeReplicas(
+				ctx, roachpb.PROMOTE_NON_VOTER, target, desc, SnapshotRequest_RECOVERY,
+				storagepb.ReasonRangeUnderReplicated, details,
+			); err != nil {
+				return false, err
+			}
+		}
+	case AllocatorAddNonVoter:
+		log.VEventf(ctx, 1, "adding a new non-voting replica")
+		newStore, details, err := rq.allocator.AllocateTarget(ctx, zone, liveReplicas, rangeInfo)
+		if err != nil {
+			return false, err
+		}
+		newReplica := roachpb.ReplicationTarget{
+			NodeID:  newStore.Node.NodeID,
+			StoreID: newStore.StoreID,
+		}
+		rq.metrics.AddNonVoterCount.Inc(1)
+		log.VEventf(ctx, 1, "adding non-voting replica %+v due to under-replication: %s",
+			newReplica, rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
+		if err := rq.addReplica(
+			ctx,
+			repl,
+			roachpb.ADD_NON_VOTER,
+			newReplica,
+			desc,
+			SnapshotRequest_RECOVERY,
+			storagepb.ReasonRangeUnderReplicated,
+			details,
+			dryRun,
+		); err != nil {
+			return false, err
+		}
+	case AllocatorRemoveNonVoter:
+		log.VEventf(ctx, 1, "removing a non-voting replica")
+		removeReplica, details, err := rq.allocator.RemoveTarget(ctx, zone, desc.NonVoters(), rangeInfo)
+		if err != nil {
+			return false, err
+		}
+		rq.metrics.RemoveNonVoterCount.Inc(1)
+		log.VEventf(ctx, 1, "removing non-voting replica %+v due to over-replication: %s",
+			removeReplica, rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
+		target := roachpb.ReplicationTarget{
+			NodeID:  removeReplica.NodeID,
+			StoreID: removeReplica.StoreID,
+		}
+		if err := rq.removeReplica(
+			ctx, repl, target, desc, storagepb.ReasonRangeOverReplicated, details, dryRun,
+		); err != nil {
+			return false, err
+		}
+	case AllocatorRemoveDeadNonVoter:
+		log.VEventf(ctx, 1, "removing a dead non-voting replica")
+		_, deadNonVoters := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, desc.NonVoters())
+		if len(deadNonVoters) == 0 {
+			log.VEventf(ctx, 1, "range of replica %s was identified as having dead non-voting replicas, "+
+				"but no dead non-voting replicas were found", repl)
+			break
+		}
+		deadNonVoter := deadNonVoters[0]
+		rq.metrics.RemoveDeadNonVoterCount.Inc(1)
+		log.VEventf(ctx, 1, "removing dead non-voting replica %+v from store", deadNonVoter)
+		target := roachpb.ReplicationTarget{
+			NodeID:  deadNonVoter.NodeID,
+			StoreID: deadNonVoter.StoreID,
+		}
+		if err := rq.removeReplica(
+			ctx, repl, target, desc, storagepb.ReasonStoreDead, "", dryRun,
+		); err != nil {
+			return false, err
+		}
 	case AllocatorRemove:
 		log.VEventf(ctx, 1, "removing a replica")
 		lastReplAdded, lastAddedTime := repl.LastReplicaAdded()
 		if timeutil.Since(lastAddedTime) > newReplicaGracePeriod {
 			lastReplAdded = 0
 		}
-		candidates := filterUnremovableReplicas(repl.RaftStatus(), desc.Replicas, lastReplAdded)
+		candidates := filterUnremovableReplicas(repl.RaftStatus(), voters, lastReplAdded)
 		log.VEventf(ctx, 3, "filtered unremovable replicas from %v to get %v as candidates for removal",
-			desc.Replicas, candidates)
+			voters, candidates)
 		if len(candidates) == 0 {
 			// After rapid upreplication, the candidates for removal could still be catching up.
 			// Mark this error as benign so it doesn't create confusion in the logs.
 			return false, &benignError{errors.Errorf("no removable replicas from range that needs a removal: %s",
 				rangeRaftProgress(repl.RaftStatus(), desc.Replicas))}
 		}
-		removeReplica, details, err := rq.allocator.RemoveTarget(ctx, zone, candidates, rangeInfo)
+		removeReplica, details, err := rq.allocator.RemoveTarget(ctx, voterZone(zone), candidates, rangeInfo)
 		if err != nil {
 			return false, err
 		}
@@ -474,11 +594,11 @@ func (rq *replicateQueue) processOneChange(
 		}
 	case AllocatorRemoveDead:
 		log.VEventf(ctx, 1, "removing a dead replica")
-		if len(deadReplicas) == 0 {
+		if len(deadVoters) == 0 {
 			log.VEventf(ctx, 1, "range of replica %s was identified as having dead replicas, but no dead replicas were found", repl)
 			break
 		}
-		deadReplica := deadReplicas[0]
+		deadReplica := deadVoters[0]
 		rq.metrics.RemoveDeadReplicaCount.Inc(1)
 		log.VEventf(ctx, 1, "removing dead replica %+v from store", deadReplica)
 		target := roachpb.ReplicationTarget{
@@ -511,6 +631,7 @@ func (rq *replicateQueue) processOneChange(
 				if err := rq.addReplica(
 					ctx,
 					repl,
+					roachpb.ADD_REPLICA,
 					rebalanceReplica,
 					desc,
 					SnapshotRequest_REBALANCE,
@@ -568,7 +689,7 @@ func (rq *replicateQueue) findTargetAndTransferLease(
 	zone *config.ZoneConfig,
 	opts transferLeaseOptions,
 ) (bool, error) {
-	candidates := filterBehindReplicas(repl.RaftStatus(), desc.Replicas, 0 /* brandNewReplicaID */)
+	candidates := filterBehindReplicas(repl.RaftStatus(), desc.Voters(), 0 /* brandNewReplicaID */)
 	target := rq.allocator.TransferLeaseTarget(
 		ctx,
 		zone,
@@ -614,6 +735,7 @@ func (rq *replicateQueue) transferLease(
 func (rq *replicateQueue) addReplica(
 	ctx context.Context,
 	repl *Replica,
+	changeType roachpb.ReplicaChangeType,
 	target roachpb.ReplicationTarget,
 	desc *roachpb.RangeDescriptor,
 	priority SnapshotRequest_Priority,
@@ -624,11 +746,11 @@ func (rq *replicateQueue) addReplica(
 	if dryRun {
 		return nil
 	}
-	if err := repl.changeReplicas(ctx, roachpb.ADD_REPLICA, target, desc, priority, reason, details); err != nil {
+	if err := repl.changeReplicas(ctx, changeType, target, desc, priority, reason, details); err != nil {
 		return err
 	}
 	rangeInfo := rangeInfoForRepl(repl, desc)
-	rq.allocator.storePool.updateLocalStoreAfterRebalance(target.StoreID, rangeInfo, roachpb.ADD_REPLICA)
+	rq.allocator.storePool.updateLocalStoreAfterRebalance(target.StoreID, rangeInfo, changeType)
 	return nil
 }
 
@@ -98,6 +98,10 @@ const (
 var changeTypeInternalToRaft = map[roachpb.ReplicaChangeType]raftpb.ConfChangeType{
 	roachpb.ADD_REPLICA:    raftpb.ConfChangeAddNode,
 	roachpb.REMOVE_REPLICA: raftpb.ConfChangeRemoveNode,
+	// Non-voting replicas are raft learners. Adding an existing learner as a
+	// node promotes it to a voter.
+	roachpb.ADD_NON_VOTER:     raftpb.ConfChangeAddLearnerNode,
+	roachpb.PROMOTE_NON_VOTER: raftpb.ConfChangeAddNode,
 }
 
 var storeSchedulerConcurrency = envutil.EnvOrDefaultInt(
@@ -137,9 +141,9 @@ func TestStoreConfig(clock *hlc.Clock) StoreConfig {
 	}
 	st := cluster.MakeTestingClusterSettings()
 	sc := StoreConfig{
-		Settings:   st,
-		AmbientCtx: log.AmbientContext{Tracer: st.Tracer},
-		Clock:      clock,
+		Settings:                    st,
+		AmbientCtx:                  log.AmbientContext{Tracer: st.Tracer},
+		Clock:                       clock,
 		CoalescedHeartbeatsInterval: 50 * time.Millisecond,
 		RaftHeartbeatIntervalTicks:  1,
 		ScanInterval:                10 * time.Minute,
@@ -2957,8 +2961,9 @@ func (s *Store) Send(
 		if cleanupAfterWriteIntentError != nil {
 			// This request wrote an intent only if there was no error, the request
 			// is transactional, the transaction is still pending, and the request
-			// wasn't read-only.
-			if pErr == nil && ba.Txn != nil && br.Txn.Status == roachpb.PENDING && !ba.IsReadOnly() {
+			// wasn't read-only. A STAGING transaction is still pending for this
+			// purpose.
+			if pErr == nil && ba.Txn != nil && !br.Txn.Status.IsFinalized() && !ba.IsReadOnly() {
 				cleanupAfterWriteIntentError(nil, &br.Txn.TxnMeta)
 			} else {
 				cleanupAfterWriteIntentError(nil, nil)
@@ -3042,6 +3047,16 @@ func (s *Store) Send(
 			repl.txnWaitQueue.Enqueue(&t.PusheeTxn)
 			pErr = nil
 
+		case *roachpb.IndeterminateCommitError:
+			// On an indeterminate commit error, attempt to recover and finalize
+			// the stuck transaction. Retry immediately if successful.
+			if ba.IsSinglePushTxnRequest() {
+				if _, err := repl.txnWaitQueue.RecoverIndeterminateCommit(ctx, &t.StagingTxn); err != nil {
+					return nil, roachpb.NewError(err)
+				}
+				pErr = nil
+			}
+
 		case *roachpb.WriteIntentError:
 			// Process and resolve write intent error. We do this here because
 			// this is the code path with the requesting client waiting.
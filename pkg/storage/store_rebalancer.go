@@ -397,9 +397,9 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 		log.VEventf(ctx, 3, "considering lease transfer for r%d with %.2f qps",
 			desc.RangeID, replWithStats.qps)
 
-		// Check all the other replicas in order of increasing qps.
-		replicas := make([]roachpb.ReplicaDescriptor, len(desc.Replicas))
-		copy(replicas, desc.Replicas)
+		// Check all the other voting replicas in order of increasing qps.
+		// Non-voting replicas can't hold the lease.
+		replicas := desc.Voters()
 		sort.Slice(replicas, func(i, j int) bool {
 			var iQPS, jQPS float64
 			if desc := storeMap[replicas[i].StoreID]; desc != nil {
@@ -503,6 +503,13 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 		log.VEventf(ctx, 3, "considering replica rebalance for r%d with %.2f qps",
 			desc.RangeID, replWithStats.qps)
 
+		// Relocating a range would turn its non-voting replicas into voters, so
+		// such ranges are left to the replicate queue.
+		if len(desc.NonVoters()) > 0 {
+			log.VEventf(ctx, 3, "r%d has non-voting replicas; not rebalancing", desc.RangeID)
+			continue
+		}
+
 		availableNodes := sr.rq.allocator.storePool.AvailableNodeCount()
 		desiredReplicas := GetNeededReplicas(*zone.NumReplicas, availableNodes)
 		targets := make([]roachpb.ReplicationTarget, 0, desiredReplicas)
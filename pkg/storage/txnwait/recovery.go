@@ -0,0 +1,88 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package txnwait
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// RecoverIndeterminateCommit attempts to resolve the status of a transaction
+// that was found in the STAGING state, which indicates that it may have been
+// implicitly committed by a parallel commit whose coordinator has since been
+// abandoned.
+//
+// The recovery process queries each of the transaction's in-flight writes. If
+// all of them are found, the transaction is implicitly committed and its
+// record is moved to COMMITTED. If any of them is missing, the QueryIntent
+// request prevents it from ever being written at the staging timestamp, so the
+// transaction can never become implicitly committed and its record is moved to
+// ABORTED. Either way, the finalized transaction is returned.
+func (q *Queue) RecoverIndeterminateCommit(
+	ctx context.Context, txn *roachpb.Transaction,
+) (*roachpb.Transaction, error) {
+	if txn.Status != roachpb.STAGING {
+		return nil, errors.Errorf("cannot recover txn %s in status %s", txn.ID.Short(), txn.Status)
+	}
+	log.VEventf(ctx, 2, "recovering txn %s from indeterminate commit", txn.ID.Short())
+
+	// Query all of the transaction's in-flight writes in a single batch. Each
+	// query prevents its intent from being written in the future if it is not
+	// found, which guarantees that a missing write stays missing.
+	implicitlyCommitted := true
+	if len(txn.InFlightWrites) > 0 {
+		b := &client.Batch{}
+		for _, w := range txn.InFlightWrites {
+			meta := txn.TxnMeta
+			meta.Sequence = w.Sequence
+			b.AddRawRequest(&roachpb.QueryIntentRequest{
+				RequestHeader: roachpb.RequestHeader{
+					Key: w.Key,
+				},
+				Txn:       meta,
+				IfMissing: roachpb.QueryIntentRequest_PREVENT,
+			})
+		}
+		if err := q.store.DB().Run(ctx, b); err != nil {
+			return nil, err
+		}
+		for _, ru := range b.RawResponse().Responses {
+			if !ru.GetInner().(*roachpb.QueryIntentResponse).FoundIntent {
+				implicitlyCommitted = false
+				break
+			}
+		}
+	}
+
+	// Finalize the transaction record based on the outcome of the queries.
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.RecoverTxnRequest{
+		RequestHeader: roachpb.RequestHeader{
+			Key: txn.Key,
+		},
+		Txn:                 txn.TxnMeta,
+		ImplicitlyCommitted: implicitlyCommitted,
+	})
+	if err := q.store.DB().Run(ctx, b); err != nil {
+		return nil, err
+	}
+	recovered := b.RawResponse().Responses[0].GetInner().(*roachpb.RecoverTxnResponse).RecoveredTxn
+	log.VEventf(ctx, 2, "recovered txn %s with status %s", txn.ID.Short(), recovered.Status)
+	return &recovered, nil
+}
@@ -69,6 +69,8 @@ export const SET_CLUSTER_SETTING = "set_cluster_setting";
 export const SET_ZONE_CONFIG = "set_zone_config";
 // Recorded when a zone config is removed.
 export const REMOVE_ZONE_CONFIG = "remove_zone_config";
+// Recorded when a replica is removed for being inconsistent with its range.
+export const REPLICA_INCONSISTENCY = "replica_inconsistency";
 
 // Node Event Types
 export const nodeEvents = [NODE_JOIN, NODE_RESTART, NODE_DECOMMISSIONED, NODE_RECOMMISSIONED];
@@ -79,7 +81,10 @@ export const tableEvents = [
   FINISH_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE_ROLLBACK,
 ];
 export const settingsEvents = [SET_CLUSTER_SETTING, SET_ZONE_CONFIG, REMOVE_ZONE_CONFIG];
-export const allEvents = [...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents];
+export const rangeEvents = [REPLICA_INCONSISTENCY];
+export const allEvents = [
+  ...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents, ...rangeEvents,
+];
 
 const nodeEventSet = _.invert(nodeEvents);
 const databaseEventSet = _.invert(databaseEvents);
@@ -79,6 +79,8 @@ export function getEventDescription(e: Event$Properties): string {
     return `Zone Config Changed: User ${info.User} set the zone config for ${info.Target} to ${info.Config}`;
     case eventTypes.REMOVE_ZONE_CONFIG:
       return `Zone Config Removed: User ${info.User} removed the zone config for ${info.Target}`;
+    case eventTypes.REPLICA_INCONSISTENCY:
+      return `Replica Inconsistent: Replica ${info.Replica} of range ${info.RangeID} was inconsistent with the majority and removed`;
     default:
       return `Unknown Event Type: ${e.event_type}, content: ${JSON.stringify(info, null, 2)}`;
   }
@@ -98,6 +100,8 @@ export interface EventInfo {
   Value?: string;
   Target?: string;
   Config?: string;
+  RangeID?: number;
+  Replica?: string;
   // The following are three names for the same key (it was renamed twice).
   // All ar included for backwards compatibility.
   DroppedTables?: string[];